@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kubevirtclient provides typed helpers to read the objects of the KubeVirt infrastructure provider of
+// Cluster API with a controller-runtime client, so that external tools can integrate with the provider through its
+// API module only.
+package kubevirtclient
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+// NewScheme returns a scheme with the types of Kubernetes, of Cluster API and of the provider.
+func NewScheme() (*runtime.Scheme, error) {
+	scheme := runtime.NewScheme()
+	for _, addToScheme := range []func(*runtime.Scheme) error{clientgoscheme.AddToScheme, clusterv1.AddToScheme, infrav1.AddToScheme} {
+		if err := addToScheme(scheme); err != nil {
+			return nil, errors.Wrap(err, "failed to build the scheme")
+		}
+	}
+	return scheme, nil
+}
+
+// New creates a client for the management cluster of the REST config, with the scheme of NewScheme.
+func New(config *rest.Config) (client.Client, error) {
+	scheme, err := NewScheme()
+	if err != nil {
+		return nil, err
+	}
+	c, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the client")
+	}
+	return c, nil
+}
+
+// GetKubevirtCluster fetches the KubevirtCluster of the given namespace and name.
+func GetKubevirtCluster(ctx context.Context, reader client.Reader, key client.ObjectKey) (*infrav1.KubevirtCluster, error) {
+	return get[infrav1.KubevirtCluster](ctx, reader, key, "KubevirtCluster")
+}
+
+// GetKubevirtClusterOf fetches the KubevirtCluster the Cluster references as its infrastructure.
+func GetKubevirtClusterOf(ctx context.Context, reader client.Reader, cluster *clusterv1.Cluster) (*infrav1.KubevirtCluster, error) {
+	ref := cluster.Spec.InfrastructureRef
+	if ref == nil || ref.Kind != "KubevirtCluster" {
+		return nil, errors.Errorf("cluster %s/%s does not reference a KubevirtCluster", cluster.Namespace, cluster.Name)
+	}
+	return GetKubevirtCluster(ctx, reader, client.ObjectKey{Namespace: cluster.Namespace, Name: ref.Name})
+}
+
+// GetKubevirtMachine fetches the KubevirtMachine of the given namespace and name.
+func GetKubevirtMachine(ctx context.Context, reader client.Reader, key client.ObjectKey) (*infrav1.KubevirtMachine, error) {
+	return get[infrav1.KubevirtMachine](ctx, reader, key, "KubevirtMachine")
+}
+
+// GetKubevirtMachineOf fetches the KubevirtMachine the Machine references as its infrastructure.
+func GetKubevirtMachineOf(ctx context.Context, reader client.Reader, machine *clusterv1.Machine) (*infrav1.KubevirtMachine, error) {
+	ref := machine.Spec.InfrastructureRef
+	if ref.Kind != "KubevirtMachine" {
+		return nil, errors.Errorf("machine %s/%s does not reference a KubevirtMachine", machine.Namespace, machine.Name)
+	}
+	return GetKubevirtMachine(ctx, reader, client.ObjectKey{Namespace: machine.Namespace, Name: ref.Name})
+}
+
+// ListKubevirtMachines lists the KubevirtMachines of the cluster of the given namespace and name.
+func ListKubevirtMachines(ctx context.Context, reader client.Reader, cluster client.ObjectKey) ([]infrav1.KubevirtMachine, error) {
+	kubevirtMachines := &infrav1.KubevirtMachineList{}
+	if err := reader.List(ctx, kubevirtMachines, client.InNamespace(cluster.Namespace), client.MatchingLabels{clusterv1.ClusterNameLabel: cluster.Name}); err != nil {
+		return nil, errors.Wrapf(err, "failed to list the KubevirtMachines of cluster %s", cluster)
+	}
+	return kubevirtMachines.Items, nil
+}
+
+// GetKubevirtMachineImage fetches the KubevirtMachineImage of the given namespace and name.
+func GetKubevirtMachineImage(ctx context.Context, reader client.Reader, key client.ObjectKey) (*infrav1.KubevirtMachineImage, error) {
+	return get[infrav1.KubevirtMachineImage](ctx, reader, key, "KubevirtMachineImage")
+}
+
+// GetKubevirtMachineImageChannel fetches the KubevirtMachineImageChannel of the given namespace and name.
+func GetKubevirtMachineImageChannel(ctx context.Context, reader client.Reader, key client.ObjectKey) (*infrav1.KubevirtMachineImageChannel, error) {
+	return get[infrav1.KubevirtMachineImageChannel](ctx, reader, key, "KubevirtMachineImageChannel")
+}
+
+// GetKubevirtRemediation fetches the KubevirtRemediation of the given namespace and name.
+func GetKubevirtRemediation(ctx context.Context, reader client.Reader, key client.ObjectKey) (*infrav1.KubevirtRemediation, error) {
+	return get[infrav1.KubevirtRemediation](ctx, reader, key, "KubevirtRemediation")
+}
+
+// GetProviderConfig fetches the cluster-scoped KubevirtProviderConfig holding the defaults of the organization.
+// The error is a NotFound error when it does not exist.
+func GetProviderConfig(ctx context.Context, reader client.Reader) (*infrav1.KubevirtProviderConfig, error) {
+	return get[infrav1.KubevirtProviderConfig](ctx, reader, client.ObjectKey{Name: infrav1.ProviderConfigName}, "KubevirtProviderConfig")
+}
+
+// get fetches the object of the given key, wrapping the error with its kind. The wrapped errors still match the
+// helpers of k8s.io/apimachinery/pkg/api/errors, e.g. IsNotFound.
+func get[T any, PT interface {
+	*T
+	client.Object
+}](ctx context.Context, reader client.Reader, key client.ObjectKey, kind string) (*T, error) {
+	obj := PT(new(T))
+	if err := reader.Get(ctx, key, obj); err != nil {
+		name := key.String()
+		if key.Namespace == "" {
+			name = key.Name
+		}
+		return nil, errors.Wrapf(err, "failed to get %s %s", kind, name)
+	}
+	return obj, nil
+}
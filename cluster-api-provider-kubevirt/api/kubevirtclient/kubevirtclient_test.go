@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirtclient_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/api/kubevirtclient"
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+var _ = Describe("Typed client helpers", func() {
+	var reader client.Reader
+	ctx := context.Background()
+	cluster := client.ObjectKey{Namespace: "capi", Name: "test-cluster"}
+
+	BeforeEach(func() {
+		scheme, err := kubevirtclient.NewScheme()
+		Expect(err).NotTo(HaveOccurred())
+
+		kubevirtCluster := &infrav1.KubevirtCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "capi", Name: "test-cluster"}}
+		machine := func(name, clusterName string) *infrav1.KubevirtMachine {
+			return &infrav1.KubevirtMachine{ObjectMeta: metav1.ObjectMeta{
+				Namespace: "capi",
+				Name:      name,
+				Labels:    map[string]string{clusterv1.ClusterNameLabel: clusterName},
+			}}
+		}
+		providerConfig := &infrav1.KubevirtProviderConfig{ObjectMeta: metav1.ObjectMeta{Name: infrav1.ProviderConfigName}}
+		reader = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			kubevirtCluster, machine("test-machine-1", "test-cluster"), machine("test-machine-2", "test-cluster"),
+			machine("other-machine", "other-cluster"), providerConfig,
+		).Build()
+	})
+
+	It("should fetch the KubevirtCluster of a Cluster", func() {
+		kubevirtCluster, err := kubevirtclient.GetKubevirtClusterOf(ctx, reader, &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "capi", Name: "test-cluster"},
+			Spec:       clusterv1.ClusterSpec{InfrastructureRef: &corev1.ObjectReference{Kind: "KubevirtCluster", Name: "test-cluster"}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kubevirtCluster.Name).To(Equal("test-cluster"))
+
+		_, err = kubevirtclient.GetKubevirtClusterOf(ctx, reader, &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "capi", Name: "test-cluster"}})
+		Expect(err).To(MatchError(ContainSubstring("does not reference a KubevirtCluster")))
+	})
+
+	It("should list the KubevirtMachines of a cluster", func() {
+		kubevirtMachines, err := kubevirtclient.ListKubevirtMachines(ctx, reader, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kubevirtMachines).To(HaveLen(2))
+	})
+
+	It("should fetch the KubevirtMachine of a Machine", func() {
+		kubevirtMachine, err := kubevirtclient.GetKubevirtMachineOf(ctx, reader, &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "capi", Name: "test-machine-1"},
+			Spec:       clusterv1.MachineSpec{InfrastructureRef: corev1.ObjectReference{Kind: "KubevirtMachine", Name: "test-machine-1"}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kubevirtMachine.Labels).To(HaveKeyWithValue(clusterv1.ClusterNameLabel, "test-cluster"))
+	})
+
+	It("should fetch the provider config, and keep the NotFound errors", func() {
+		providerConfig, err := kubevirtclient.GetProviderConfig(ctx, reader)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(providerConfig.Name).To(Equal(infrav1.ProviderConfigName))
+
+		_, err = kubevirtclient.GetKubevirtMachineImage(ctx, reader, client.ObjectKey{Namespace: "capi", Name: "missing"})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("failed to get KubevirtMachineImage capi/missing")))
+	})
+})
@@ -34,14 +34,138 @@ const (
 	// script to be ready before starting to create the VM that provides the KubevirtMachine infrastructure.
 	WaitingForBootstrapDataReason = "WaitingForBootstrapData"
 
+	// WaitingForBootstrapTokenReason (Severity=Info) documents a KubevirtMachine whose VM start is held until the
+	// bootstrap provider refreshes its join token, as it would expire before the VM could join.
+	WaitingForBootstrapTokenReason = "WaitingForBootstrapToken"
+
+	// WaitingForMachineImageReason (Severity=Info) documents a KubevirtMachine waiting for its KubevirtMachineImage
+	// to be imported in the infra cluster before creating its VM.
+	WaitingForMachineImageReason = "WaitingForMachineImage"
+
 	// VMCreateFailed (Severity=Error) documents a KubevirtMachine that is unable to create the
 	// corresponding VM object.
 	VMCreateFailedReason = "VMCreateFailed"
 
+	// DryRunReason (Severity=Info) documents a KubevirtMachine whose VM has not been created because the
+	// KubevirtCluster is annotated for dry-run reconciliation.
+	DryRunReason = "DryRun"
+
+	// HibernatedReason (Severity=Info) documents a KubevirtMachine whose VM is stopped because the KubevirtCluster
+	// is hibernated.
+	HibernatedReason = "Hibernated"
+
+	// ReclaimingReason (Severity=Info) documents a KubevirtMachine whose preemptible VM is being reclaimed: its
+	// Node is drained before the VM is stopped.
+	ReclaimingReason = "Reclaiming"
+
+	// ReclaimedReason (Severity=Info) documents a KubevirtMachine whose preemptible VM is stopped because it was
+	// reclaimed.
+	ReclaimedReason = "Reclaimed"
+
+	// InMaintenanceReason (Severity=Warning) documents a KubevirtMachine whose VM is terminal or missing, and is
+	// not replaced because the KubevirtCluster is in maintenance.
+	InMaintenanceReason = "InMaintenance"
+
+	// VMDeletedReason (Severity=Error) documents a KubevirtMachine whose VM was deleted from the infra cluster
+	// after the machine was provisioned; the machine is failed so that it gets replaced.
+	VMDeletedReason = "VMDeleted"
+
+	// ProvisioningTimedOutReason (Severity=Error) documents a KubevirtMachine failed because it exceeded the
+	// timeout of one of its provisioning phases. It is reported with Severity=Warning while the VM is recreated
+	// instead.
+	ProvisioningTimedOutReason = "ProvisioningTimedOut"
+
+	// InfraFeatureUnavailableReason (Severity=Warning) documents a KubevirtMachine whose VM is not created because
+	// its template uses a feature KubeVirt, or the infra nodes, do not provide in the infra cluster, e.g. nested
+	// virtualization or GPUs. It documents as well a KubevirtCluster whose CSI driver is not deployed because the infra
+	// cluster does not provide hotplug volumes.
+	InfraFeatureUnavailableReason = "InfraFeatureUnavailable"
+
+	// QuotaExceededReason (Severity=Warning) documents a KubevirtMachine whose VM is not created because it would
+	// exceed the quota of its cluster.
+	QuotaExceededReason = "QuotaExceeded"
+
+	// StorageCapabilityUnavailableReason (Severity=Warning) documents a KubevirtMachine whose VM is not created
+	// because the storage class of one of its DataVolumeTemplates lacks a capability the VM requires, e.g.
+	// ReadWriteMany for live migration.
+	StorageCapabilityUnavailableReason = "StorageCapabilityUnavailable"
+
+	// WaitForFirstConsumerNotHonoredReason (Severity=Warning) documents a KubevirtMachine whose VM is not created
+	// because the storage class of one of its DataVolumeTemplates binds its volumes on first consumer, while CDI
+	// does not wait for the VM to be scheduled in the multi-zone infra cluster and would bind them in any zone.
+	WaitForFirstConsumerNotHonoredReason = "WaitForFirstConsumerNotHonored"
+
+	// DiskEncryptionNotHonoredReason (Severity=Warning) documents a KubevirtMachine whose VM is not created because
+	// the disk encryption policy of its cluster has no encrypted storage class for one of its DataVolumeTemplates.
+	DiskEncryptionNotHonoredReason = "DiskEncryptionNotHonored"
+
+	// WaitingForUpgradePreflightReason (Severity=Info) documents a control plane KubevirtMachine of the version the
+	// control plane is upgraded to, whose VM is not created until the preflight checks of the upgrade pass.
+	WaitingForUpgradePreflightReason = "WaitingForUpgradePreflight"
+
+	// MachineIdentityCertificateCondition documents the validity of the client certificate issued to the
+	// KubevirtMachine, when the KubevirtCluster enables machine identities.
+	MachineIdentityCertificateCondition clusterv1.ConditionType = "MachineIdentityCertificate"
+
+	// MachineIdentityIssueFailedReason (Severity=Warning) documents a KubevirtMachine whose certificate could not
+	// be issued, e.g. because the CA secret is missing.
+	MachineIdentityIssueFailedReason = "MachineIdentityIssueFailed"
+
+	// MachineIdentityExpiringReason (Severity=Warning) documents a KubevirtMachine whose certificate is about to
+	// expire; the machine has to be replaced to rotate it.
+	MachineIdentityExpiringReason = "MachineIdentityExpiring"
+
+	// MachineIdentityExpiredReason (Severity=Error) documents a KubevirtMachine whose certificate expired.
+	MachineIdentityExpiredReason = "MachineIdentityExpired"
+
 	// VMLiveMigratableCondition documents whether the VM is live-migratable or not
 	VMLiveMigratableCondition clusterv1.ConditionType = "VMLiveMigratable"
 )
 
+const (
+	// VMInSyncCondition documents whether the VM matches the VM rendered from the KubevirtMachine, when the
+	// KubevirtCluster enables the detection of the drift of its VMs. It is not part of the Ready summary of the
+	// KubevirtMachine.
+	VMInSyncCondition clusterv1.ConditionType = "VMInSync"
+
+	// VMDriftedReason (Severity=Warning) documents a KubevirtMachine whose VM was changed in the infra cluster; the
+	// message lists the drifted fields.
+	VMDriftedReason = "VMDrifted"
+
+	// VMDriftRevertFailedReason (Severity=Warning) documents a KubevirtMachine whose drifted VM could not be
+	// reverted to the VM rendered from the KubevirtMachine.
+	VMDriftRevertFailedReason = "VMDriftRevertFailed"
+)
+
+const (
+	// NodeReadyCondition mirrors the Ready condition of the Node of the KubevirtMachine in the workload cluster.
+	// It is not part of the Ready summary of the KubevirtMachine.
+	NodeReadyCondition clusterv1.ConditionType = "NodeReady"
+
+	// WaitingForNodeReason (Severity=Info) documents a KubevirtMachine whose Node has not registered in the
+	// workload cluster yet.
+	WaitingForNodeReason = "WaitingForNode"
+
+	// NodeNotReadyReason (Severity=Warning) documents a KubevirtMachine whose Node is not ready, or whose kubelet
+	// stopped reporting its status.
+	NodeNotReadyReason = "NodeNotReady"
+)
+
+const (
+	// GuestAgentConnectedCondition documents whether the qemu-guest-agent of the VM is connected, unless the
+	// KubevirtMachine disables the guest agent. It is not part of the Ready summary of the KubevirtMachine.
+	GuestAgentConnectedCondition clusterv1.ConditionType = "GuestAgentConnected"
+
+	// WaitingForGuestAgentReason (Severity=Info) documents a KubevirtMachine whose VMI is not running yet, or whose
+	// required guest agent is still within its connect timeout.
+	WaitingForGuestAgentReason = "WaitingForGuestAgent"
+
+	// AgentNotConnectedReason documents a KubevirtMachine whose guest agent is not connected: with Severity=Error
+	// when the KubevirtMachine requires the guest agent and it did not connect within its connect timeout, e.g.
+	// because the image lacks it, with Severity=Info otherwise.
+	AgentNotConnectedReason = "AgentNotConnected"
+)
+
 const (
 	// BootstrapExecSucceededCondition provides an observation of the KubevirtMachine bootstrap process.
 	// 	It is set based on successful execution of bootstrap commands and on the existence of
@@ -57,6 +181,23 @@ const (
 	// bootstrapping the Kubernetes node on the machine just provisioned; those kind of errors are usually
 	// transient and failed bootstrap are automatically re-tried by the controller.
 	BootstrapFailedReason = "BootstrapFailed"
+
+	// BootstrapNetworkUnreachableReason documents (Severity=Warning) a KubevirtMachine whose bootstrap log shows
+	// that the VM could not reach the network, e.g. the API server or a DNS server.
+	BootstrapNetworkUnreachableReason = "BootstrapNetworkUnreachable"
+
+	// BootstrapImagePullFailedReason documents (Severity=Warning) a KubevirtMachine whose bootstrap log shows that
+	// the VM could not pull a container image.
+	BootstrapImagePullFailedReason = "BootstrapImagePullFailed"
+
+	// BootstrapTokenExpiredReason documents (Severity=Warning) a KubevirtMachine whose bootstrap log shows that
+	// its join token was rejected, usually because it expired before the VM booted.
+	BootstrapTokenExpiredReason = "BootstrapTokenExpired"
+
+	// BootstrapPreflightFailedReason documents (Severity=Warning) a KubevirtMachine whose bootstrap log shows that
+	// the preflight checks of kubeadm failed; those failures are not retried, as they come from the image or the
+	// configuration of the machine.
+	BootstrapPreflightFailedReason = "BootstrapPreflightFailed"
 )
 
 // Conditions and condition Reasons for the KubevirtCluster object
@@ -69,4 +210,203 @@ const (
 	// an error while provisioning the service that provides the cluster load balancer; those kind of
 	// errors are usually transient and failed provisioning are automatically re-tried by the controller.
 	LoadBalancerProvisioningFailedReason = "LoadBalancerProvisioningFailed"
+
+	// WaitingForFloatingIPReason (Severity=Info) documents a KubevirtCluster whose floating IP has not been
+	// allocated from its pool yet.
+	WaitingForFloatingIPReason = "WaitingForFloatingIP"
+
+	// ExternalControlPlaneEndpointAvailableCondition documents whether the external endpoint of the control plane
+	// is published, when it is enabled.
+	ExternalControlPlaneEndpointAvailableCondition clusterv1.ConditionType = "ExternalControlPlaneEndpointAvailable"
+
+	// WaitingForExternalAddressReason (Severity=Info) documents a KubevirtCluster whose external endpoint service
+	// has no external address yet.
+	WaitingForExternalAddressReason = "WaitingForExternalAddress"
+
+	// InfraOwnershipCondition documents whether the management cluster holds the infra ownership lease of the
+	// KubevirtCluster, when it is enabled.
+	InfraOwnershipCondition clusterv1.ConditionType = "InfraOwnership"
+
+	// InfraOwnedElsewhereReason (Severity=Warning) documents a KubevirtCluster whose infra ownership lease is held
+	// by another management cluster; its infra resources are left alone.
+	InfraOwnedElsewhereReason = "InfraOwnedElsewhere"
+
+	// ClusterVerifiedCondition documents the result of the smoke test of the workload cluster.
+	ClusterVerifiedCondition clusterv1.ConditionType = "ClusterVerified"
+
+	// SmokeTestRunningReason (Severity=Info) documents a smoke test in progress in the workload cluster.
+	SmokeTestRunningReason = "SmokeTestRunning"
+
+	// SmokeTestFailedReason (Severity=Error) documents a smoke test that failed, or did not complete in time.
+	SmokeTestFailedReason = "SmokeTestFailed"
+
+	// WorkloadDNSHealthyCondition documents the result of the last probe of the DNS and of the service network of
+	// the workload cluster, when it is enabled.
+	WorkloadDNSHealthyCondition clusterv1.ConditionType = "WorkloadDNSHealthy"
+
+	// WorkloadDNSProbeRunningReason (Severity=Info) documents the first probe in progress in the workload cluster.
+	WorkloadDNSProbeRunningReason = "WorkloadDNSProbeRunning"
+
+	// WorkloadDNSUnhealthyReason (Severity=Warning) documents a probe whose DNS lookups or connection through the
+	// service network failed or timed out, or that did not complete in time. MTU or offload problems of the network
+	// of the workload cluster usually show this way first.
+	WorkloadDNSUnhealthyReason = "WorkloadDNSUnhealthy"
+
+	// CSIDriverAvailableCondition documents whether the KubeVirt CSI driver of the workload cluster is deployed,
+	// when it is enabled.
+	CSIDriverAvailableCondition clusterv1.ConditionType = "CSIDriverAvailable"
+
+	// CSIDriverDeploymentFailedReason (Severity=Warning) documents a KubevirtCluster controller detecting an error
+	// while deploying the CSI driver; the deployment is retried.
+	CSIDriverDeploymentFailedReason = "CSIDriverDeploymentFailed"
+
+	// ImagesPrewarmedCondition documents whether the images of the machines of the cluster are pulled on all the
+	// infra nodes, when image prewarming is enabled.
+	ImagesPrewarmedCondition clusterv1.ConditionType = "ImagesPrewarmed"
+
+	// PrewarmingImagesReason (Severity=Info) documents images still being pulled on some infra nodes.
+	PrewarmingImagesReason = "PrewarmingImages"
+
+	// ImagePrewarmingFailedReason (Severity=Warning) documents a KubevirtCluster controller detecting an error
+	// while deploying the pods pulling the images; the deployment is retried.
+	ImagePrewarmingFailedReason = "ImagePrewarmingFailed"
+
+	// InfraCompatibleCondition documents whether the provider supports the versions of KubeVirt and CDI of the infra
+	// cluster, according to its compatibility matrix.
+	InfraCompatibleCondition clusterv1.ConditionType = "InfraCompatible"
+
+	// UnsupportedInfraVersionsReason (Severity=Warning) documents an infra cluster running versions of KubeVirt and
+	// CDI the provider does not support; the VMs may be rejected, or miss features of their templates.
+	UnsupportedInfraVersionsReason = "UnsupportedInfraVersions"
+
+	// InfraVersionsUnknownReason (Severity=Info) documents an infra cluster whose version of KubeVirt cannot be read
+	// with its credentials.
+	InfraVersionsUnknownReason = "InfraVersionsUnknown"
+
+	// UpgradePreflightPassedCondition documents whether the preflight checks of the pending upgrade of the control
+	// plane passed, when they are enabled.
+	UpgradePreflightPassedCondition clusterv1.ConditionType = "UpgradePreflightPassed"
+
+	// UpgradePreflightFailedReason (Severity=Warning) documents preflight checks that did not pass; the creation
+	// of the upgraded control plane VMs is held, and the checks run again periodically.
+	UpgradePreflightFailedReason = "UpgradePreflightFailed"
+
+	// TenantCNICompatibleCondition documents whether the CNI of the workload cluster is configured for the cluster,
+	// when the workload cluster is checked for a kube-proxy replacement.
+	TenantCNICompatibleCondition clusterv1.ConditionType = "TenantCNICompatible"
+
+	// KubeProxyReplacementMisconfiguredReason (Severity=Warning) documents a kube-proxy replacement that does not
+	// reach the API server through the control plane endpoint; it loses the API server when the machine it reaches
+	// is replaced.
+	KubeProxyReplacementMisconfiguredReason = "KubeProxyReplacementMisconfigured"
+
+	// InfraPermissionsAvailableCondition documents whether the controller holds the permissions it needs in the
+	// infra cluster, according to the check of its permissions at startup. Only the clusters whose infra cluster is
+	// the management cluster are checked.
+	InfraPermissionsAvailableCondition clusterv1.ConditionType = "InfraPermissionsAvailable"
+
+	// MissingInfraPermissionsReason (Severity=Error) documents a controller missing permissions in the infra
+	// cluster; the cluster is not reconciled until the controller restarts with them.
+	MissingInfraPermissionsReason = "MissingInfraPermissions"
+
+	// TenantCNIUnknownReason (Severity=Info) documents a workload cluster whose CNI configuration cannot be read.
+	TenantCNIUnknownReason = "TenantCNIUnknown"
+
+	// HibernationScheduleValidCondition documents whether the hibernation schedules of the cluster can be parsed,
+	// when it has some.
+	HibernationScheduleValidCondition clusterv1.ConditionType = "HibernationScheduleValid"
+
+	// InvalidHibernationScheduleReason (Severity=Error) documents hibernation schedules that cannot be parsed; the
+	// cluster is hibernated only when spec.hibernated is set, until they are fixed.
+	InvalidHibernationScheduleReason = "InvalidHibernationSchedule"
+)
+
+// Conditions and condition Reasons following the v1beta2 conventions of Cluster API, reported in status.v1beta2 of
+// the KubevirtCluster and KubevirtMachine objects. Unlike the v1beta1 conditions above, they always have a reason,
+// and record the generation of the object they were computed for. The other v1beta2 conditions mirror the v1beta1
+// conditions with the same type.
+
+const (
+	// ReadyV1Beta2Condition is true when all the conditions summarized into it are true.
+	ReadyV1Beta2Condition = "Ready"
+
+	// ReadyV1Beta2Reason surfaces when all the conditions summarized into Ready are true.
+	ReadyV1Beta2Reason = "Ready"
+
+	// NotReadyV1Beta2Reason surfaces when a condition summarized into Ready is false.
+	NotReadyV1Beta2Reason = "NotReady"
+
+	// ReadyUnknownV1Beta2Reason surfaces when a condition summarized into Ready is unknown, or not reported yet.
+	ReadyUnknownV1Beta2Reason = "ReadyUnknown"
+
+	// DeletingV1Beta2Condition is true while the object is being deleted. Its polarity is negative.
+	DeletingV1Beta2Condition = "Deleting"
+
+	// DeletingV1Beta2Reason surfaces when the object is being deleted.
+	DeletingV1Beta2Reason = "Deleting"
+
+	// NotDeletingV1Beta2Reason surfaces when the object is not being deleted.
+	NotDeletingV1Beta2Reason = "NotDeleting"
+
+	// NotYetReportedV1Beta2Reason surfaces when the controller did not report a condition yet.
+	NotYetReportedV1Beta2Reason = "NotYetReported"
+
+	// VMProvisionedV1Beta2Reason surfaces when the VM of the KubevirtMachine is provisioned.
+	VMProvisionedV1Beta2Reason = "Provisioned"
+
+	// BootstrapSucceededV1Beta2Reason surfaces when the bootstrap of the KubevirtMachine succeeded.
+	BootstrapSucceededV1Beta2Reason = "Succeeded"
+
+	// NodeReadyV1Beta2Reason surfaces when the Node of the KubevirtMachine is ready.
+	NodeReadyV1Beta2Reason = "NodeReady"
+
+	// VMLiveMigratableV1Beta2Reason surfaces when the VM of the KubevirtMachine can be live-migrated.
+	VMLiveMigratableV1Beta2Reason = "LiveMigratable"
+
+	// VMInSyncV1Beta2Reason surfaces when the VM of the KubevirtMachine matches the VM rendered from it.
+	VMInSyncV1Beta2Reason = "InSync"
+
+	// GuestAgentConnectedV1Beta2Reason surfaces when the guest agent of the VM of the KubevirtMachine is connected.
+	GuestAgentConnectedV1Beta2Reason = "AgentConnected"
+
+	// MachineIdentityValidV1Beta2Reason surfaces when the certificate of the KubevirtMachine is valid.
+	MachineIdentityValidV1Beta2Reason = "Valid"
+
+	// LoadBalancerAvailableV1Beta2Reason surfaces when the control plane endpoint of the KubevirtCluster is
+	// published.
+	LoadBalancerAvailableV1Beta2Reason = "Available"
+
+	// InfraOwnedV1Beta2Reason surfaces when the management cluster holds the infra ownership lease of the
+	// KubevirtCluster.
+	InfraOwnedV1Beta2Reason = "Owned"
+
+	// ClusterVerifiedV1Beta2Reason surfaces when the smoke test of the workload cluster succeeded.
+	ClusterVerifiedV1Beta2Reason = "Verified"
+
+	// WorkloadDNSHealthyV1Beta2Reason surfaces when the DNS and the service network of the workload cluster are
+	// healthy.
+	WorkloadDNSHealthyV1Beta2Reason = "Healthy"
+
+	// CSIDriverDeployedV1Beta2Reason surfaces when the CSI driver of the workload cluster is deployed.
+	CSIDriverDeployedV1Beta2Reason = "Deployed"
+
+	// ImagesPrewarmedV1Beta2Reason surfaces when the images of the machines are pulled on all the infra nodes.
+	ImagesPrewarmedV1Beta2Reason = "Prewarmed"
+
+	// InfraCompatibleV1Beta2Reason surfaces when the provider supports the versions of KubeVirt and CDI of the
+	// infra cluster.
+	InfraCompatibleV1Beta2Reason = "Compatible"
+
+	// UpgradePreflightPassedV1Beta2Reason surfaces when the preflight checks of the control plane upgrade passed.
+	UpgradePreflightPassedV1Beta2Reason = "Passed"
+
+	// TenantCNICompatibleV1Beta2Reason surfaces when the CNI of the workload cluster is configured for the cluster.
+	TenantCNICompatibleV1Beta2Reason = "Compatible"
+
+	// InfraPermissionsAvailableV1Beta2Reason surfaces when the controller holds the permissions it needs in the
+	// infra cluster.
+	InfraPermissionsAvailableV1Beta2Reason = "Available"
+
+	// HibernationScheduleValidV1Beta2Reason surfaces when the hibernation schedules of the cluster can be parsed.
+	HibernationScheduleValidV1Beta2Reason = "Valid"
 )
@@ -17,8 +17,12 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
@@ -33,14 +37,166 @@ const ( //labels
 	KubevirtMachineNamespaceLabel = "capk.cluster.x-k8s.io/kubevirt-machine-namespace"
 
 	KubevirtMachineVMTerminalLabel = "capk.cluster.x-k8s.io/vm-is-terminal"
+
+	// HypervisorLabel is set on the workload cluster Nodes to the name of the infra cluster node their VM runs on,
+	// so that workloads can be spread across physical hosts.
+	HypervisorLabel = "capk.cluster.x-k8s.io/hypervisor"
+
+	// TenantServiceLabel is set on the tenant load balancer objects of the infra cluster to "true", to tell them
+	// apart from the object publishing the control plane endpoint.
+	TenantServiceLabel = "capk.cluster.x-k8s.io/tenant-service"
+
+	// OrphanedFromMachineLabel is set on the volumes of a deleted machine left in the infra cluster, to the name
+	// of the KubevirtMachine, so that they are reported in the status of the KubevirtCluster.
+	OrphanedFromMachineLabel = "capk.cluster.x-k8s.io/orphaned-from-machine"
+
+	// PreemptibleLabel is set on the VMs of the preemptible machines, and their VMIs, to "true".
+	PreemptibleLabel = "capk.cluster.x-k8s.io/preemptible"
+
+	// MigrationPolicyLabel is set on the VMIs of the clusters and machines with a migration policy, to the name of
+	// the MigrationPolicy of the infra cluster selecting them.
+	MigrationPolicyLabel = "capk.cluster.x-k8s.io/migration-policy"
 )
 
 const ( // annotations
 	VmiDeletionGraceTime       = "capk.cluster.x-k8s.io/vmi-deletion-grace-time"
 	VmiDeletionGraceTimeEscape = "capk.cluster.x-k8s.io~1vmi-deletion-grace-time"
+
+	// DryRunAnnotation can be set to "true" on a KubevirtCluster to have the controllers only compute and publish,
+	// as events, the infra objects they would create for the cluster and its machines, without acting on them.
+	DryRunAnnotation = "capk.cluster.x-k8s.io/dry-run"
+
+	// HibernatedRunStrategyAnnotation is set on the VMs stopped by the hibernation of their cluster, and records
+	// the run strategy to restore when the cluster is resumed.
+	HibernatedRunStrategyAnnotation = "capk.cluster.x-k8s.io/hibernated-run-strategy"
+
+	// SkipRemediationReasonAnnotation is set on the Machines the controller sets the skip-remediation annotation of
+	// Cluster API on, e.g. while their cluster is hibernated, and records why. Both annotations are removed once
+	// that reason is over; the skip-remediation annotations set by the users are left alone.
+	SkipRemediationReasonAnnotation = "capk.cluster.x-k8s.io/skip-remediation-reason"
+
+	// SkipHibernationAnnotation can be set on a KubevirtCluster to skip the current, or else the next, window of
+	// its hibernation schedules. The controller replaces the value with the end time of the skipped window, and
+	// removes the annotation once that window is over.
+	SkipHibernationAnnotation = "capk.cluster.x-k8s.io/skip-hibernation"
+
+	// InPlaceResizeAnnotation can be set to "true" on a KubevirtMachineTemplate to allow changes to the CPU and
+	// memory of its VMs. Such changes are applied in place to the control plane machines cloned from the
+	// template, one machine at a time.
+	InPlaceResizeAnnotation = "capk.cluster.x-k8s.io/in-place-resize"
+
+	// ResizedVMIAnnotation is set on a control plane KubevirtMachine while its VM restarts with a new size, and
+	// records the UID of the VMI that was stopped.
+	ResizedVMIAnnotation = "capk.cluster.x-k8s.io/resized-vmi"
+
+	// RunCommandAnnotation can be set on a KubevirtMachine to the name of an allowed command to run once inside
+	// its VM through the qemu-guest-agent, e.g. "kubelet-logs". The result is stored in the
+	// "<machine name>-<command name>" ConfigMap, and the annotation is removed once the command ran.
+	RunCommandAnnotation = "capk.cluster.x-k8s.io/run-command"
+
+	// InjectFailuresAnnotation can be set on a KubevirtCluster to a comma-separated list of failures to simulate
+	// for the cluster and its machines, e.g. "kubeconfig-secret-missing,vm-start-failure". It is ignored unless
+	// the controller runs with --enable-failure-injection.
+	InjectFailuresAnnotation = "capk.cluster.x-k8s.io/inject-failures"
+
+	// MaintenanceAnnotation can be set on a KubevirtCluster to "true", or to a duration such as "2h", to pause the
+	// replacement of its failed machines and VMs during an infra maintenance. The controller replaces the value
+	// with the end time of the maintenance, which is capped, and removes the annotation once that time is past.
+	MaintenanceAnnotation = "capk.cluster.x-k8s.io/maintenance"
+
+	// RollingRebootAnnotation can be set to "true" on a KubevirtCluster to have the controller reboot the VMs
+	// whose guest reports a pending reboot, e.g. after a kernel update, one machine at a time.
+	RollingRebootAnnotation = "capk.cluster.x-k8s.io/rolling-reboot"
+
+	// RebootingVMIAnnotation is set on a KubevirtMachine while its Node is drained and its VM rebooted by the
+	// rolling reboot of its cluster, and records the UID of the VMI to stop.
+	RebootingVMIAnnotation = "capk.cluster.x-k8s.io/rebooting-vmi"
+
+	// BootstrapDataHashAnnotation is set on the VMI template of the VMs to the hash of the bootstrap data they boot
+	// with, so that the VMIs started before the bootstrap data changed are detected and restarted with it.
+	BootstrapDataHashAnnotation = "capk.cluster.x-k8s.io/bootstrap-data-hash"
+
+	// BootstrapTokenRefreshRequestedAnnotation is set on the bootstrap config of a machine still pending after half
+	// the TTL of the join tokens, to the time of the request, asking the bootstrap provider for bootstrap data with a
+	// fresh token.
+	BootstrapTokenRefreshRequestedAnnotation = "capk.cluster.x-k8s.io/bootstrap-token-refresh-requested"
+
+	// BootstrapTokenRefreshedAnnotation is set on the bootstrap config by the bootstrap provider, to the value of
+	// the request it answered, once it regenerated the bootstrap data with a fresh token.
+	BootstrapTokenRefreshedAnnotation = "capk.cluster.x-k8s.io/bootstrap-token-refreshed"
+
+	// BootstrapTokenHoldRunStrategyAnnotation is set on the VMs whose start is held because their join token would
+	// expire before they could join, and records the run strategy to restore once the token is refreshed.
+	BootstrapTokenHoldRunStrategyAnnotation = "capk.cluster.x-k8s.io/bootstrap-token-hold-run-strategy"
+
+	// ReclaimAnnotation is set to "true" on the VM of a preemptible machine in the infra cluster to reclaim it. It
+	// is also set by the controller when the infra cluster evicts the VM. The VM is started again once the
+	// annotation is removed.
+	ReclaimAnnotation = "capk.cluster.x-k8s.io/reclaim"
+
+	// ReclaimedTaint is the key of the NoSchedule taint set on the Nodes of the reclaimed machines.
+	ReclaimedTaint = "capk.cluster.x-k8s.io/reclaimed"
+
+	// TakeOverInfraOwnershipAnnotation can be set to "true" on a KubevirtCluster to take its infra ownership lease
+	// over from the management cluster holding it. It is removed once the lease is taken over.
+	TakeOverInfraOwnershipAnnotation = "capk.cluster.x-k8s.io/take-over-infra-ownership"
+
+	// ProviderVersionAnnotation is set on the KubevirtClusters and KubevirtMachines to the version and the commit
+	// of the provider build that last reconciled them.
+	ProviderVersionAnnotation = "capk.cluster.x-k8s.io/provider-version"
+
+	// ExportDisksAnnotation can be set on a failed KubevirtMachine to export the disks of its VM for offline
+	// analysis. Its value is the lifetime of the download URLs, e.g. "4h", or empty for the KubeVirt default.
+	ExportDisksAnnotation = "capk.cluster.x-k8s.io/export-disks"
+
+	// DiskExportRunStrategyAnnotation is set on the VMs stopped to export their disks, and records the run strategy
+	// to restore once the export is removed.
+	DiskExportRunStrategyAnnotation = "capk.cluster.x-k8s.io/disk-export-run-strategy"
+
+	// TenantServiceAnnotation is set on the tenant load balancer objects of the infra cluster to the
+	// "<namespace>/<name>" of the workload cluster Service they implement.
+	TenantServiceAnnotation = "capk.cluster.x-k8s.io/tenant-service-name"
+
+	// DiskEncryptionAnnotation is set on the VMs created with a disk encryption policy to its mode.
+	DiskEncryptionAnnotation = "capk.cluster.x-k8s.io/disk-encryption"
+
+	// InfraOwnerAnnotation is set on the objects the provider creates in the infra clusters to the
+	// "<kind>/<namespace>/<name>" of the KubevirtCluster or the KubevirtMachine they are created for. The objects
+	// are only deleted once it is verified.
+	InfraOwnerAnnotation = "capk.cluster.x-k8s.io/owner"
+)
+
+const (
+	// DefaultMachineIdentityValidity is the default validity of the machine certificates.
+	DefaultMachineIdentityValidity = 365 * 24 * time.Hour
+
+	// DefaultMachineIdentityRenewBefore is the default time before its expiry a machine certificate is rotated.
+	DefaultMachineIdentityRenewBefore = 30 * 24 * time.Hour
+
+	// DefaultInfraOwnershipLeaseDuration is the default duration of the infra ownership lease of a cluster.
+	DefaultInfraOwnershipLeaseDuration = 5 * time.Minute
+)
+
+// NestedVirtualizationAttribute is the attribute of the failure domains of a KubevirtCluster set to "true" when
+// the failure domain has infra nodes supporting nested virtualization.
+const NestedVirtualizationAttribute = "nestedVirtualization"
+
+// HibernationState describes the hibernation progress of a KubevirtCluster.
+type HibernationState string
+
+const (
+	// HibernationStateHibernating means the VMs of the cluster are being stopped.
+	HibernationStateHibernating HibernationState = "Hibernating"
+
+	// HibernationStateHibernated means all the VMs of the cluster are stopped.
+	HibernationStateHibernated HibernationState = "Hibernated"
+
+	// HibernationStateResuming means the VMs of the cluster are being started again.
+	HibernationStateResuming HibernationState = "Resuming"
 )
 
 // KubevirtClusterSpec defines the desired state of KubevirtCluster.
+// +kubebuilder:validation:XValidation:rule="has(self.namingPrefix) == has(oldSelf.namingPrefix) && (!has(self.namingPrefix) || self.namingPrefix == oldSelf.namingPrefix)", message="namingPrefix is immutable"
 type KubevirtClusterSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
@@ -57,6 +213,17 @@ type KubevirtClusterSpec struct {
 	// +optional
 	ControlPlaneServiceTemplate ControlPlaneServiceTemplate `json:"controlPlaneServiceTemplate,omitempty"`
 
+	// ControlPlaneEndpointPublisher selects how the control plane endpoint is published. By default, it is
+	// published with the service configured by controlPlaneServiceTemplate.
+	// +optional
+	ControlPlaneEndpointPublisher *EndpointPublisherSpec `json:"controlPlaneEndpointPublisher,omitempty"`
+
+	// ControlPlaneFloatingIP allocates a floating IP from a pool of the infra SDN, e.g. an ms-sdn floating IP
+	// pool, as the host of the control plane endpoint when the cluster is created, and releases it when the
+	// cluster is deleted. It is ignored once controlPlaneEndpoint.host is set.
+	// +optional
+	ControlPlaneFloatingIP *FloatingIPSpec `json:"controlPlaneFloatingIP,omitempty"`
+
 	// SSHKeys is a reference to a local struct for SSH keys persistence.
 	// +optional
 	SshKeys SSHKeys `json:"sshKeys,omitempty"`
@@ -64,6 +231,784 @@ type KubevirtClusterSpec struct {
 	// InfraClusterSecretRef is a reference to a secret with a kubeconfig for external cluster used for infra.
 	// +optional
 	InfraClusterSecretRef *corev1.ObjectReference `json:"infraClusterSecretRef,omitempty"`
+
+	// Hibernated stops all the VMs of the cluster when set to true, keeping their disks, and starts them
+	// again when set back to false.
+	// +optional
+	Hibernated bool `json:"hibernated,omitempty"`
+
+	// HibernationSchedules defines recurring windows during which the cluster is hibernated, e.g. nights or
+	// weekends. Setting hibernated to true hibernates the cluster regardless of the schedules.
+	// +optional
+	HibernationSchedules []HibernationSchedule `json:"hibernationSchedules,omitempty"`
+
+	// SmokeTest enables a smoke test of the workload cluster once its control plane is ready. The result is
+	// reported in the ClusterVerified condition.
+	// +optional
+	SmokeTest *SmokeTestSpec `json:"smokeTest,omitempty"`
+
+	// WorkloadDNSProbe enables a periodic probe of the DNS and of the service network of the workload cluster, once
+	// its addons are applied. The result is reported in the WorkloadDNSHealthy condition.
+	// +optional
+	WorkloadDNSProbe *WorkloadDNSProbeSpec `json:"workloadDNSProbe,omitempty"`
+
+	// Proxy configures the HTTP proxy used by the nodes of the workload cluster, and by the controller to reach
+	// the workload cluster.
+	// +optional
+	Proxy *ProxySpec `json:"proxy,omitempty"`
+
+	// RegistryMirrors are the mirrors containerd pulls the images of the registries from on the nodes of the
+	// workload cluster, before falling back to the registries themselves.
+	// +optional
+	RegistryMirrors []RegistryMirror `json:"registryMirrors,omitempty"`
+
+	// MachineIdentity enables the issuance of a client certificate to each machine of the cluster, delivered with
+	// its bootstrap data.
+	// +optional
+	MachineIdentity *MachineIdentitySpec `json:"machineIdentity,omitempty"`
+
+	// InfraOwnershipLease makes the controller hold a lease in the infra cluster before mutating the infra
+	// resources of the cluster, so that two management clusters never reconcile them at the same time, e.g.
+	// during a migration.
+	// +optional
+	InfraOwnershipLease *InfraOwnershipLeaseSpec `json:"infraOwnershipLease,omitempty"`
+
+	// ExternalControlPlaneEndpoint publishes a second endpoint of the control plane, for access from outside the
+	// infra cluster, e.g. by humans. The nodes keep using the internal endpoint set in controlPlaneEndpoint.
+	// +optional
+	ExternalControlPlaneEndpoint *ExternalControlPlaneEndpointSpec `json:"externalControlPlaneEndpoint,omitempty"`
+
+	// TenantLoadBalancer implements the Services of type LoadBalancer of the workload cluster with objects of a
+	// load balancer API of the infra cluster, e.g. the ms-sdn load balancer CRD, each with a virtual IP of the
+	// tenant subnet written back to the status of its Service.
+	// +optional
+	TenantLoadBalancer *TenantLoadBalancerSpec `json:"tenantLoadBalancer,omitempty"`
+
+	// CloudControllerManager deploys the KubeVirt cloud controller manager of the workload cluster in the
+	// namespace of the cluster, so that the Services of type LoadBalancer and the Nodes of the workload cluster
+	// are backed by the infra cluster as soon as it is created.
+	// +optional
+	CloudControllerManager *CloudControllerManagerSpec `json:"cloudControllerManager,omitempty"`
+
+	// TenantNetwork allocates a subnet of the tenant supernet configured on the controller to the cluster, and
+	// declares it with an object of a network API of the infra cluster, e.g. an ms-sdn tenant network.
+	// +optional
+	TenantNetwork *TenantNetworkSpec `json:"tenantNetwork,omitempty"`
+
+	// CSIDriver deploys the KubeVirt CSI driver of the workload cluster, which provisions the volumes of the
+	// workload cluster as DataVolumes of the infra cluster hotplugged to the VMs.
+	// +optional
+	CSIDriver *CSIDriverSpec `json:"csiDriver,omitempty"`
+
+	// ImagePrewarming pulls the containerDisk images of the machines of the cluster on every schedulable node of
+	// the infra cluster ahead of time, so that the VMs created on scale events do not wait for their pull.
+	// +optional
+	ImagePrewarming *ImagePrewarmingSpec `json:"imagePrewarming,omitempty"`
+
+	// DiskRetentionPolicy defines what happens to the disks created for the VMs of the cluster when their machines
+	// are deleted, including when the cluster is deleted. Defaults to Delete.
+	// +kubebuilder:validation:Enum=Delete;Retain;SnapshotThenDelete
+	// +optional
+	DiskRetentionPolicy DiskRetentionPolicy `json:"diskRetentionPolicy,omitempty"`
+
+	// UpgradePreflight runs preflight checks once the version of the control plane changes, and holds the
+	// creation of the control plane VMs of the new version until they pass. The result is reported in the
+	// UpgradePreflightPassed condition.
+	// +optional
+	UpgradePreflight *UpgradePreflightSpec `json:"upgradePreflight,omitempty"`
+
+	// MemoryOvercommit defines how the memory of the VMs of the cluster is overcommitted on the infra cluster,
+	// trading the performance of the VMs for their density. It applies to the VMs created after it is set, and
+	// overrides the VMI templates of the machines.
+	// +optional
+	MemoryOvercommit *MemoryOvercommitSpec `json:"memoryOvercommit,omitempty"`
+
+	// MigrationPolicy tunes the live migrations of the VMs of the cluster, e.g. when their infra nodes are
+	// evacuated, through a MigrationPolicy of the infra cluster selecting them. The machines can override its
+	// fields. It applies to the VMs created after it is set.
+	// +optional
+	MigrationPolicy *MigrationPolicySpec `json:"migrationPolicy,omitempty"`
+
+	// DeschedulerEviction marks the virt-launcher pods of the VMs of the cluster as eligible for, or protected from,
+	// the evictions of the descheduler of the infra cluster, which live migrates the VMs with the LiveMigrate
+	// eviction strategy. The control plane VMs are only eligible one migration at a time, while the control plane is
+	// healthy and has other replicas to serve the API server traffic. Unset leaves the pods untouched.
+	// +kubebuilder:validation:Enum=Allow;Prevent
+	// +optional
+	DeschedulerEviction DeschedulerEvictionPolicy `json:"deschedulerEviction,omitempty"`
+
+	// ServiceMesh makes the pods and Services the provider creates for the cluster bypass the service mesh of the
+	// clusters running them: the virt-launcher pods of the VMs, the control plane Services, and the cloud controller
+	// manager and CSI controller pods. Sidecars intercepting the traffic of the virt-launcher pods break the
+	// networking of the VMs. It applies to the objects created after it is set.
+	// +optional
+	ServiceMesh *ServiceMeshSpec `json:"serviceMesh,omitempty"`
+
+	// TenantKubeProxyReplacement makes the provider account for a workload cluster whose Services are implemented by
+	// its CNI rather than kube-proxy, e.g. Cilium with its kube-proxy replacement. Detect checks the workload
+	// cluster for kube-proxy and the configuration of Cilium, Enabled assumes a replacement. With a replacement, the
+	// configuration of Cilium is checked against the control plane endpoint in the TenantCNICompatible condition,
+	// and the tenant load balancers only forward the traffic of the Services with a Local external traffic policy to
+	// the Nodes running their ready endpoints. Unset leaves the workload cluster unchecked.
+	// +kubebuilder:validation:Enum=Detect;Enabled
+	// +optional
+	TenantKubeProxyReplacement KubeProxyReplacementMode `json:"tenantKubeProxyReplacement,omitempty"`
+
+	// WorkloadKubeconfig overrides the kubeconfig the controllers reach the workload cluster with, for the clusters
+	// whose kubeconfig is not managed by Cluster API. When nil, the kubeconfig is read from the <cluster>-kubeconfig
+	// secret.
+	// +optional
+	WorkloadKubeconfig *WorkloadKubeconfigSource `json:"workloadKubeconfig,omitempty"`
+
+	// OIDCKubeconfig generates a kubeconfig for the end users of the workload cluster, authenticating them with an
+	// OIDC identity provider instead of the client certificate of the admin kubeconfig. It is stored in the
+	// <cluster>-oidc-kubeconfig secret, which can be shared with the users without granting them the admin
+	// kubeconfig. The API server of the workload cluster must be configured to trust the identity provider.
+	// +optional
+	OIDCKubeconfig *OIDCKubeconfigSpec `json:"oidcKubeconfig,omitempty"`
+
+	// DiskEncryption requires the disks of the VMs of the cluster to be encrypted at rest, either by the storage
+	// classes of the infra cluster or by the guests. It applies to the VMs created after it is set; the disks of
+	// the existing VMs not honoring it are reported in status.diskEncryption.
+	// +optional
+	DiskEncryption *DiskEncryptionSpec `json:"diskEncryption,omitempty"`
+
+	// NamingPrefix prefixes the names of the Services publishing the control plane endpoint and of the bootstrap
+	// data Secrets of the machines in the infra cluster, e.g. "acme-". The VMs and their disks keep the names of
+	// the machines, which are also the names of the Nodes of the workload cluster. It cannot be changed.
+	// +kubebuilder:validation:MaxLength=20
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*)?$`
+	// +optional
+	NamingPrefix string `json:"namingPrefix,omitempty"`
+
+	// MetadataPropagation copies labels and annotations of the Cluster onto the VMs, Services and Secrets the
+	// provider creates in the infra cluster for it, e.g. the tenant or cost-center tags required by the infra
+	// cluster.
+	// +optional
+	MetadataPropagation *MetadataPropagationSpec `json:"metadataPropagation,omitempty"`
+
+	// VMDrift periodically compares the VMs of the cluster with the VMs rendered from their KubevirtMachines, to
+	// detect the changes made to their CPU, memory, disks or networks in the infra cluster. The drift is reported in
+	// the VMInSync condition of the KubevirtMachines, and reverted if requested. Disabled when not set.
+	// +optional
+	VMDrift *VMDriftSpec `json:"vmDrift,omitempty"`
+
+	// BatchedVMCreation creates the VMs of the machines of a MachineSet together: the first machine of a scale-up
+	// reaching the creation of its VM also creates the VMs of its pending siblings with the same spec, rather than
+	// leaving them to be created one per reconcile. Disabled when not set.
+	// +optional
+	BatchedVMCreation *BatchedVMCreationSpec `json:"batchedVMCreation,omitempty"`
+
+	// Quota caps the number of machines of the cluster and the resources of their VMs, so that a runaway scale-up
+	// cannot exhaust the capacity of the infra cluster shared with other tenants. The KubevirtMachines exceeding it
+	// are rejected on creation, and their VMs are not created. Unlimited when not set.
+	// +optional
+	Quota *ClusterQuota `json:"quota,omitempty"`
+}
+
+// ClusterQuota caps the machines of a cluster. The fields not set are unlimited.
+type ClusterQuota struct {
+	// MaxMachines is the maximum number of KubevirtMachines of the cluster, leaving out the ones being deleted. It
+	// has to leave room for the machines surging during the rolling updates.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxMachines *int32 `json:"maxMachines,omitempty"`
+
+	// MaxTotalCPU is the maximum number of vCPUs of the VMs of the machines of the cluster, from the CPU topology of
+	// their templates or, if not set, their CPU resources.
+	// +optional
+	MaxTotalCPU *resource.Quantity `json:"maxTotalCPU,omitempty"`
+
+	// MaxTotalMemory is the maximum guest memory of the VMs of the machines of the cluster, from the memory of their
+	// templates or, if not set, their memory requests.
+	// +optional
+	MaxTotalMemory *resource.Quantity `json:"maxTotalMemory,omitempty"`
+}
+
+// BatchedVMCreationSpec defines how the VMs of the machines of a MachineSet are created together.
+type BatchedVMCreationSpec struct {
+	// MaxBatchSize is the maximum number of VMs created in a batch, including the VM of the machine creating it.
+	// Defaults to 50.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxBatchSize int32 `json:"maxBatchSize,omitempty"`
+
+	// Parallelism is the number of VMs of a batch created concurrently. Defaults to 10.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Parallelism int32 `json:"parallelism,omitempty"`
+
+	// SharedDataVolumeSource makes the disks of the VMs of a batch imported from an http or registry source clone
+	// the disk of the VM of the machine creating the batch, rather than each importing the source again.
+	// +optional
+	SharedDataVolumeSource bool `json:"sharedDataVolumeSource,omitempty"`
+}
+
+// VMDriftRemediation defines what happens to the VMs changed in the infra cluster.
+type VMDriftRemediation string
+
+const (
+	// ReportVMDriftRemediation only reports the drifted VMs.
+	ReportVMDriftRemediation VMDriftRemediation = "Report"
+
+	// RevertVMDriftRemediation also patches the drifted fields of the VMs back to their rendered values. KubeVirt
+	// applies them to the running VMs on their next restart, unless it can hotplug them.
+	RevertVMDriftRemediation VMDriftRemediation = "Revert"
+)
+
+// VMDriftSpec defines how the drift of the VMs of a cluster is detected and remediated.
+type VMDriftSpec struct {
+	// Remediation defines what happens to the drifted VMs. Defaults to Report.
+	// +kubebuilder:validation:Enum=Report;Revert
+	// +kubebuilder:default=Report
+	// +optional
+	Remediation VMDriftRemediation `json:"remediation,omitempty"`
+
+	// CheckInterval is the interval between two checks of a VM. Defaults to 5m.
+	// +optional
+	CheckInterval *metav1.Duration `json:"checkInterval,omitempty"`
+}
+
+// MetadataPropagationSpec defines the labels and annotations of a Cluster propagated onto its infra objects.
+type MetadataPropagationSpec struct {
+	// Labels are the keys of the labels propagated, e.g. "example.com/cost-center". A key ending with "/"
+	// propagates all the labels of its prefix, e.g. "tags.example.com/". The keys of the cluster.x-k8s.io and
+	// kubevirt.io domains, and of their subdomains, are never propagated.
+	// +optional
+	Labels []string `json:"labels,omitempty"`
+
+	// Annotations are the keys of the annotations propagated, matched as the labels.
+	// +optional
+	Annotations []string `json:"annotations,omitempty"`
+}
+
+// OIDCKubeconfigSpec defines the OIDC client of the kubeconfig of the end users of a workload cluster. The
+// kubeconfig runs the kubelogin exec credential plugin, as kubectl oidc-login, to log the users in.
+type OIDCKubeconfigSpec struct {
+	// IssuerURL is the URL of the OIDC identity provider.
+	// +kubebuilder:validation:Pattern=`^https://`
+	IssuerURL string `json:"issuerURL"`
+
+	// ClientID is the ID of the OIDC client the users log in with.
+	// +kubebuilder:validation:MinLength=1
+	ClientID string `json:"clientID"`
+
+	// ExtraScopes are the scopes requested in addition to openid, e.g. email or groups.
+	// +optional
+	ExtraScopes []string `json:"extraScopes,omitempty"`
+}
+
+// WorkloadKubeconfigSource defines where the kubeconfig of a workload cluster comes from.
+type WorkloadKubeconfigSource struct {
+	// SecretRef selects the key of a secret of the namespace of the KubevirtCluster holding the kubeconfig. It is
+	// also mounted in the cloud controller manager and CSI controller pods. When nil, the <cluster>-kubeconfig secret
+	// is used.
+	// +optional
+	SecretRef *corev1.SecretKeySelector `json:"secretRef,omitempty"`
+
+	// Exec authenticates the controllers to the workload cluster with an exec credential plugin, instead of the
+	// credentials of the kubeconfig, which still supplies the server and its certificate authority. The plugin
+	// runs in the controller manager pod; the cloud controller manager and CSI controller pods keep the credentials
+	// of the kubeconfig. Kubeconfigs may also use an exec credential plugin or the oidc auth provider themselves.
+	// +optional
+	Exec *ExecCredentialPlugin `json:"exec,omitempty"`
+}
+
+// ExecCredentialPlugin defines a client-go exec credential plugin.
+type ExecCredentialPlugin struct {
+	// Command is the executable of the plugin, run from the credential plugin directory of the controller manager
+	// whatever its path.
+	Command string `json:"command"`
+
+	// Args are the arguments of the command.
+	// +optional
+	Args []string `json:"args,omitempty"`
+
+	// Env are the environment variables set for the command, in addition to the ones of the controller manager.
+	// +optional
+	Env []ExecEnvVar `json:"env,omitempty"`
+
+	// APIVersion is the version of the ExecCredential the plugin prints.
+	// +kubebuilder:default:=client.authentication.k8s.io/v1
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+}
+
+// ExecEnvVar is an environment variable of an exec credential plugin.
+type ExecEnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// KubeProxyReplacementMode defines how the provider finds whether a workload cluster replaces kube-proxy.
+type KubeProxyReplacementMode string
+
+const (
+	// DetectKubeProxyReplacement checks the workload cluster for a kube-proxy replacement.
+	DetectKubeProxyReplacement KubeProxyReplacementMode = "Detect"
+
+	// EnabledKubeProxyReplacement assumes the workload cluster replaces kube-proxy.
+	EnabledKubeProxyReplacement KubeProxyReplacementMode = "Enabled"
+)
+
+// ServiceMeshType is the type of a service mesh.
+type ServiceMeshType string
+
+const (
+	// IstioServiceMesh is the Istio service mesh.
+	IstioServiceMesh ServiceMeshType = "Istio"
+
+	// LinkerdServiceMesh is the Linkerd service mesh.
+	LinkerdServiceMesh ServiceMeshType = "Linkerd"
+)
+
+// ServiceMeshSpec defines how the pods and Services the provider creates bypass a service mesh.
+type ServiceMeshSpec struct {
+	// Type is the service mesh to bypass. The pods are excluded from its sidecar injection. With Linkerd, the ports of
+	// the Services are also marked opaque, so that the proxies of meshed clients forward their traffic as plain TCP.
+	// +kubebuilder:validation:Enum=Istio;Linkerd
+	Type ServiceMeshType `json:"type"`
+
+	// PodAnnotations are added to the pods, e.g. to exclude ports from the interception of the sidecars of the
+	// mesh when it is still injected. They override the annotations set for the type of the mesh.
+	// +optional
+	PodAnnotations map[string]string `json:"podAnnotations,omitempty"`
+
+	// ServiceAnnotations are added to the Services. They override the annotations set for the type of the mesh.
+	// +optional
+	ServiceAnnotations map[string]string `json:"serviceAnnotations,omitempty"`
+}
+
+// DeschedulerEvictionPolicy defines whether the descheduler of the infra cluster may evict the VMs of a cluster.
+type DeschedulerEvictionPolicy string
+
+const (
+	// AllowDeschedulerEviction marks the VMs as eligible for the evictions of the descheduler.
+	AllowDeschedulerEviction DeschedulerEvictionPolicy = "Allow"
+
+	// PreventDeschedulerEviction protects the VMs from the evictions of the descheduler.
+	PreventDeschedulerEviction DeschedulerEvictionPolicy = "Prevent"
+)
+
+// MigrationPolicySpec defines the live migration settings of VMs. The unset fields keep the settings of the
+// infra cluster.
+type MigrationPolicySpec struct {
+	// BandwidthPerMigration limits the bandwidth of each migration, per second, e.g. 64Mi.
+	// +optional
+	BandwidthPerMigration *resource.Quantity `json:"bandwidthPerMigration,omitempty"`
+
+	// CompletionTimeoutPerGiB is the time, in seconds per GiB of guest memory, after which a migration not
+	// completed is canceled, or switched to post-copy when allowed.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	CompletionTimeoutPerGiB *int64 `json:"completionTimeoutPerGiB,omitempty"`
+
+	// AllowAutoConverge allows to throttle the vCPUs of the VMs whose memory changes faster than it is
+	// migrated, so that their migration completes.
+	// +optional
+	AllowAutoConverge *bool `json:"allowAutoConverge,omitempty"`
+
+	// AllowPostCopy allows to switch the migrations not completed in time to post-copy, where the VM runs on the
+	// target node and fetches its remaining memory from the source node. A network failure during post-copy
+	// loses the VM.
+	// +optional
+	AllowPostCopy *bool `json:"allowPostCopy,omitempty"`
+}
+
+// MemoryOvercommitSpec defines the memory overcommit policy of the VMs of a cluster.
+type MemoryOvercommitSpec struct {
+	// Ballooning attaches the memory balloon device to the VMs, through which the guests report their memory
+	// usage and the infra cluster reclaims their unused memory. Defaults to the VMI template, where KubeVirt
+	// attaches it unless disabled.
+	// +optional
+	Ballooning *bool `json:"ballooning,omitempty"`
+
+	// FreePageReporting has the guests report their freed memory pages through the memory balloon device, so that
+	// the infra node reclaims them. Setting it to false disables it; it has no effect without ballooning, and
+	// KubeVirt never enables it for dedicated CPU VMs.
+	// +optional
+	FreePageReporting *bool `json:"freePageReporting,omitempty"`
+
+	// MemoryRequestPercent is the memory request of the VMs, as a percentage of their guest memory. The guest
+	// memory is kept, and defaults to the memory request of the VMI template. 100 reserves all the guest memory
+	// on the infra nodes; lower values pack more VMs on them. Unset keeps the requests of the VMI templates.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	MemoryRequestPercent int32 `json:"memoryRequestPercent,omitempty"`
+}
+
+// UpgradePreflightSpec defines the thresholds of the preflight checks of the control plane upgrades.
+type UpgradePreflightSpec struct {
+	// MinEtcdFreeSpace is the space that must be available on the etcd disk of every control plane VM.
+	// +optional
+	// +kubebuilder:default:="2Gi"
+	MinEtcdFreeSpace resource.Quantity `json:"minEtcdFreeSpace,omitempty"`
+
+	// MinKubeVirtVersion is the oldest version of KubeVirt of the infra cluster the upgraded control plane VMs
+	// may run on.
+	// +optional
+	// +kubebuilder:default:="v1.0.0"
+	MinKubeVirtVersion string `json:"minKubeVirtVersion,omitempty"`
+}
+
+// DiskEncryptionMode defines what encrypts the disks of the VMs at rest.
+type DiskEncryptionMode string
+
+const (
+	// StorageClassDiskEncryptionMode creates the disks with storage classes of the infra cluster encrypting them.
+	StorageClassDiskEncryptionMode DiskEncryptionMode = "StorageClass"
+
+	// GuestDiskEncryptionMode has cloud-init encrypt the data disks with LUKS in the guests. The boot disk is left
+	// unencrypted.
+	GuestDiskEncryptionMode DiskEncryptionMode = "Guest"
+)
+
+// DiskEncryptionSpec defines how the disks of the VMs of a cluster are encrypted at rest.
+// +kubebuilder:validation:XValidation:rule="self.mode != 'StorageClass' || (has(self.storageClasses) && size(self.storageClasses) > 0)", message="storageClasses is required by the StorageClass mode"
+// +kubebuilder:validation:XValidation:rule="self.mode == 'StorageClass' || !has(self.storageClasses)", message="storageClasses is only allowed with the StorageClass mode"
+type DiskEncryptionSpec struct {
+	// Mode defines what encrypts the disks: the storage classes of the infra cluster, or LUKS in the guests.
+	// +kubebuilder:validation:Enum=StorageClass;Guest
+	Mode DiskEncryptionMode `json:"mode"`
+
+	// StorageClasses maps the storage classes of the DataVolumeTemplates to the encrypted storage classes of the
+	// infra cluster their disks are created with, in the StorageClass mode. The empty key maps the
+	// DataVolumeTemplates without storage class. The DataVolumeTemplates of a storage class among the values are
+	// kept; the VMs with other DataVolumeTemplates are not created.
+	// +optional
+	StorageClasses map[string]string `json:"storageClasses,omitempty"`
+}
+
+// DiskRetentionPolicy defines what happens to the disks of a VM when its machine is deleted.
+type DiskRetentionPolicy string
+
+const (
+	// DeleteDiskRetentionPolicy deletes the disks with the VM.
+	DeleteDiskRetentionPolicy DiskRetentionPolicy = "Delete"
+
+	// RetainDiskRetentionPolicy keeps the disks in the infra cluster after the VM is deleted.
+	RetainDiskRetentionPolicy DiskRetentionPolicy = "Retain"
+
+	// SnapshotThenDeleteDiskRetentionPolicy takes a snapshot of the VM before deleting it with its disks.
+	SnapshotThenDeleteDiskRetentionPolicy DiskRetentionPolicy = "SnapshotThenDelete"
+)
+
+// OrphanedVolume is a volume of a deleted machine left in the infra cluster.
+type OrphanedVolume struct {
+	// Name is the name of the PVC of the volume, in the infra namespace of the cluster.
+	Name string `json:"name"`
+
+	// Machine is the name of the deleted KubevirtMachine the volume belonged to.
+	Machine string `json:"machine"`
+
+	// Capacity is the capacity of the volume, if it is bound.
+	// +optional
+	Capacity string `json:"capacity,omitempty"`
+}
+
+// TenantNetworkSpec defines the network object declaring the subnet of a cluster in the infra SDN.
+type TenantNetworkSpec struct {
+	// APIVersion is the API version of the network object.
+	APIVersion string `json:"apiVersion"`
+
+	// Kind is the kind of the network object.
+	Kind string `json:"kind"`
+
+	// Spec is merged into the spec of the network object. The cidr field is always set by the controller to the
+	// subnet of the cluster.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Spec runtime.RawExtension `json:"spec,omitempty"`
+}
+
+// CloudControllerManagerSpec defines the KubeVirt cloud controller manager deployed for a workload cluster.
+type CloudControllerManagerSpec struct {
+	// Image is the image of the cloud controller manager. Defaults to
+	// quay.io/kubevirt/kubevirt-cloud-controller-manager:v0.5.1.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// LoadBalancer enables the implementation of the Services of type LoadBalancer of the workload cluster with
+	// Services of the infra cluster. Defaults to true.
+	// +optional
+	LoadBalancer *bool `json:"loadBalancer,omitempty"`
+}
+
+// CSIDriverSpec defines the KubeVirt CSI driver deployed for a workload cluster.
+type CSIDriverSpec struct {
+	// Image is the image of the CSI driver. Defaults to quay.io/kubevirt/kubevirt-csi-driver:latest.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// StorageClasses maps the storage classes created in the workload cluster to the storage classes of the infra
+	// cluster their volumes are provisioned with.
+	// +kubebuilder:validation:MinItems=1
+	StorageClasses []StorageClassMapping `json:"storageClasses"`
+}
+
+// ImagePrewarmingSpec defines the images pulled ahead of time on the infra nodes for a cluster.
+type ImagePrewarmingSpec struct {
+	// Images are pulled in addition to the containerDisk images of the KubevirtMachines of the cluster and of the
+	// KubevirtMachineTemplates of its MachineDeployments.
+	// +optional
+	Images []string `json:"images,omitempty"`
+
+	// HelperImage is the image of the pods pulling the images, which must provide a static busybox binary at
+	// /bin/busybox. Defaults to docker.io/library/busybox:1.36-musl.
+	// +optional
+	HelperImage string `json:"helperImage,omitempty"`
+
+	// NodeSelector restricts the pull to the infra nodes with these labels, e.g. the nodes the VMs of the cluster
+	// are scheduled on. Defaults to all the schedulable nodes.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+}
+
+// StorageClassMapping maps a storage class of the workload cluster to a storage class of the infra cluster.
+type StorageClassMapping struct {
+	// Name is the name of the storage class in the workload cluster.
+	Name string `json:"name"`
+
+	// InfraStorageClassName is the name of the storage class of the infra cluster.
+	InfraStorageClassName string `json:"infraStorageClassName"`
+
+	// Default makes the storage class the default storage class of the workload cluster.
+	// +optional
+	Default bool `json:"default,omitempty"`
+}
+
+// TenantLoadBalancerSpec defines the load balancer objects implementing the Services of type LoadBalancer of a
+// workload cluster.
+type TenantLoadBalancerSpec struct {
+	// APIVersion is the API version of the load balancer objects.
+	APIVersion string `json:"apiVersion"`
+
+	// Kind is the kind of the load balancer objects.
+	Kind string `json:"kind"`
+
+	// VIPCIDR is the range of the tenant subnet the virtual IPs of the Services are assigned from, e.g.
+	// "10.10.0.192/26". It must not overlap the addresses assigned to the VMs.
+	VIPCIDR string `json:"vipCIDR"`
+
+	// Spec is merged into the spec of each load balancer object, e.g. to request the NAT of its virtual IP. The
+	// vip, ports and backends fields are always set by the controller.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Spec runtime.RawExtension `json:"spec,omitempty"`
+}
+
+// ExternalControlPlaneEndpointSpec defines the external endpoint of the control plane.
+type ExternalControlPlaneEndpointSpec struct {
+	// Host is the hostname or the IP address of the external endpoint, when it is published by other means, e.g.
+	// a floating IP. When it is empty, the controller publishes the endpoint with a dedicated service and uses
+	// its external address.
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// Port is the port of the external endpoint. Defaults to 6443.
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// ServiceTemplate can be used to modify the service publishing the external endpoint. The service is of
+	// type LoadBalancer unless the template sets another type. It is ignored when host is set.
+	// +optional
+	ServiceTemplate ControlPlaneServiceTemplate `json:"serviceTemplate,omitempty"`
+
+	// CertSANs are extra subject alternative names of the API server certificate, for the names the external
+	// endpoint is reached with. The host of the external endpoint is always added.
+	// +optional
+	CertSANs []string `json:"certSANs,omitempty"`
+}
+
+// EndpointPublisherType is the way the control plane endpoint of a cluster is published.
+// +kubebuilder:validation:Enum=Service;KubeVIP;ExternalLoadBalancer;Static
+type EndpointPublisherType string
+
+const (
+	// ServiceEndpointPublisher publishes the endpoint with a service of the infra cluster, configured by
+	// controlPlaneServiceTemplate.
+	ServiceEndpointPublisher EndpointPublisherType = "Service"
+
+	// KubeVIPEndpointPublisher publishes controlPlaneEndpoint.host as a virtual IP announced by a kube-vip
+	// static pod of the control plane machines.
+	KubeVIPEndpointPublisher EndpointPublisherType = "KubeVIP"
+
+	// ExternalLoadBalancerEndpointPublisher publishes the endpoint with an object of a load balancer API of the
+	// infra cluster, e.g. the ms-sdn load balancer CRD.
+	ExternalLoadBalancerEndpointPublisher EndpointPublisherType = "ExternalLoadBalancer"
+
+	// StaticEndpointPublisher uses controlPlaneEndpoint as-is, and leaves its publication to the user.
+	StaticEndpointPublisher EndpointPublisherType = "Static"
+)
+
+// EndpointPublisherSpec defines how the control plane endpoint of a cluster is published.
+type EndpointPublisherSpec struct {
+	// Type is the way the control plane endpoint is published. Defaults to Service.
+	// +optional
+	Type EndpointPublisherType `json:"type,omitempty"`
+
+	// KubeVIP configures the kube-vip static pod of the control plane machines, for the KubeVIP publisher.
+	// +optional
+	KubeVIP *KubeVIPPublisherSpec `json:"kubeVIP,omitempty"`
+
+	// ExternalLoadBalancer defines the load balancer object, for the ExternalLoadBalancer publisher.
+	// +optional
+	ExternalLoadBalancer *ExternalLoadBalancerPublisherSpec `json:"externalLoadBalancer,omitempty"`
+}
+
+// KubeVIPPublisherSpec defines the kube-vip static pod announcing the control plane endpoint.
+type KubeVIPPublisherSpec struct {
+	// Interface is the network interface of the control plane machines the virtual IP is announced on.
+	// Defaults to eth0.
+	// +optional
+	Interface string `json:"interface,omitempty"`
+
+	// Image is the kube-vip image. Defaults to ghcr.io/kube-vip/kube-vip:v0.8.0.
+	// +optional
+	Image string `json:"image,omitempty"`
+}
+
+// ExternalLoadBalancerPublisherSpec defines the object of a load balancer API publishing the control plane
+// endpoint.
+type ExternalLoadBalancerPublisherSpec struct {
+	// APIVersion is the API version of the load balancer object.
+	APIVersion string `json:"apiVersion"`
+
+	// Kind is the kind of the load balancer object.
+	Kind string `json:"kind"`
+
+	// Spec is the spec of the load balancer object. It is set once, when the object is created.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Spec runtime.RawExtension `json:"spec,omitempty"`
+
+	// AddressField is the dot-separated path of the field of the load balancer object holding the address
+	// assigned to it. Defaults to status.address.
+	// +optional
+	AddressField string `json:"addressField,omitempty"`
+}
+
+// FloatingIPSpec defines the claim of a floating IP from a pool of the infra SDN.
+type FloatingIPSpec struct {
+	// APIVersion is the API version of the floating IP claim.
+	APIVersion string `json:"apiVersion"`
+
+	// Kind is the kind of the floating IP claim.
+	Kind string `json:"kind"`
+
+	// Pool is the name of the pool the floating IP is allocated from, set in the pool field of the claim spec.
+	Pool string `json:"pool"`
+
+	// Spec is merged into the spec of the claim. The pool and, when the endpoint is published by a service or a
+	// load balancer object, the target of the NAT rule of the floating IP, are always set by the controller.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Spec runtime.RawExtension `json:"spec,omitempty"`
+
+	// AddressField is the dot-separated path of the field of the claim holding the allocated floating IP.
+	// Defaults to status.address.
+	// +optional
+	AddressField string `json:"addressField,omitempty"`
+}
+
+// InfraOwnershipLeaseSpec defines the lease a management cluster holds on the infra resources of a cluster.
+type InfraOwnershipLeaseSpec struct {
+	// Duration is how long the lease stays valid without being renewed. Another management cluster acquires
+	// the lease once it expired, or earlier by taking it over explicitly. Defaults to 5m.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+}
+
+// MachineIdentitySpec defines the client certificates identifying the machines of a cluster to its internal
+// services.
+type MachineIdentitySpec struct {
+	// CASecretName is the name of the secret, in the namespace of the KubevirtCluster, holding the certificate and
+	// the key of the CA signing the machine certificates in its tls.crt and tls.key entries.
+	CASecretName string `json:"caSecretName"`
+
+	// Validity is the validity of the machine certificates. Defaults to 8760h.
+	// +optional
+	Validity *metav1.Duration `json:"validity,omitempty"`
+
+	// RenewBefore is how long before its expiry a machine certificate is reported to need a rotation, and is
+	// renewed if the machine is not provisioned yet. Defaults to 720h.
+	// +optional
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+}
+
+// ProxySpec defines the HTTP proxy of a workload cluster.
+type ProxySpec struct {
+	// HTTPProxy is the proxy of the HTTP requests, e.g. "http://proxy.example.com:3128".
+	// +optional
+	HTTPProxy string `json:"httpProxy,omitempty"`
+
+	// HTTPSProxy is the proxy of the HTTPS requests.
+	// +optional
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+
+	// NoProxy lists additional hosts, domains and CIDRs reached without the proxy. The pod and service CIDRs,
+	// the service domain and the control plane endpoint of the cluster are always reached directly.
+	// +optional
+	NoProxy []string `json:"noProxy,omitempty"`
+}
+
+// RegistryMirror defines the mirrors of a container registry.
+type RegistryMirror struct {
+	// Registry is the host, and optional port, of the mirrored registry, e.g. "docker.io", or "_default" to
+	// mirror all the registries without mirrors of their own.
+	// +kubebuilder:validation:Pattern=`^(_default|[a-zA-Z0-9.-]+(:[0-9]+)?)$`
+	Registry string `json:"registry"`
+
+	// Endpoints are the URLs of the mirrors, tried in order, e.g. "https://mirror.example.com".
+	// +kubebuilder:validation:MinItems=1
+	Endpoints []string `json:"endpoints"`
+}
+
+// SmokeTestSpec defines the workload deployed in the workload cluster by the smoke test.
+type SmokeTestSpec struct {
+	// ServerImage is the image of the web server reached by the test, it must serve HTTP on port 80.
+	// +optional
+	// +kubebuilder:default:="nginx:stable"
+	ServerImage string `json:"serverImage,omitempty"`
+
+	// ClientImage is the image of the test job, it must provide nslookup and wget.
+	// +optional
+	// +kubebuilder:default:="busybox:stable"
+	ClientImage string `json:"clientImage,omitempty"`
+
+	// LoadBalancer additionally verifies that a LoadBalancer Service of the workload cluster gets an address.
+	// +optional
+	LoadBalancer bool `json:"loadBalancer,omitempty"`
+}
+
+// WorkloadDNSProbeSpec defines the probe of the DNS and of the service network of the workload cluster.
+type WorkloadDNSProbeSpec struct {
+	// Image is the image of the probe job, it must provide nslookup, wget and timeout.
+	// +optional
+	// +kubebuilder:default:="busybox:stable"
+	Image string `json:"image,omitempty"`
+
+	// Interval is the interval between the probes.
+	// +optional
+	// +kubebuilder:default:="10m"
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// HibernationSchedule defines a recurring window during which the cluster is hibernated.
+type HibernationSchedule struct {
+	// Hibernate is the cron expression, in the "minute hour day-of-month month day-of-week" format, of the
+	// start of the window.
+	Hibernate string `json:"hibernate"`
+
+	// Resume is the cron expression, in the "minute hour day-of-month month day-of-week" format, of the end
+	// of the window.
+	Resume string `json:"resume"`
+
+	// TimeZone is the name of the time zone the cron expressions are evaluated in, e.g. "Europe/Paris".
+	// Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
 }
 
 // KubevirtClusterStatus defines the observed state of KubevirtCluster.
@@ -79,6 +1024,250 @@ type KubevirtClusterStatus struct {
 	// Conditions defines current service state of the KubevirtCluster.
 	// +optional
 	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+
+	// HibernationState reports the progress of stopping or starting the VMs of the cluster when
+	// spec.hibernated changes. It is empty when the cluster is running.
+	// +optional
+	// +kubebuilder:validation:Enum=Hibernating;Hibernated;Resuming
+	HibernationState HibernationState `json:"hibernationState,omitempty"`
+
+	// NextHibernationTransition is the next time a window of the hibernation schedules starts or ends.
+	// +optional
+	NextHibernationTransition *metav1.Time `json:"nextHibernationTransition,omitempty"`
+
+	// MaintenanceEndTime is the time the maintenance requested with the maintenance annotation expires.
+	// +optional
+	MaintenanceEndTime *metav1.Time `json:"maintenanceEndTime,omitempty"`
+
+	// PendingReboots lists the KubevirtMachines waiting to be rebooted by the rolling reboot of the cluster, in
+	// the order they are rebooted.
+	// +optional
+	PendingReboots []string `json:"pendingReboots,omitempty"`
+
+	// LastRebootCheckTime is the last time the VMs of the cluster were checked for a pending reboot.
+	// +optional
+	LastRebootCheckTime *metav1.Time `json:"lastRebootCheckTime,omitempty"`
+
+	// NestedVirtualization reports the infra nodes supporting nested virtualization. It is not set when the
+	// credentials of the infra cluster do not allow to list its nodes.
+	// +optional
+	NestedVirtualization *NestedVirtualizationStatus `json:"nestedVirtualization,omitempty"`
+
+	// Infra reports the versions and the feature gates of KubeVirt and CDI in the infra cluster.
+	// +optional
+	Infra *InfraStatus `json:"infra,omitempty"`
+
+	// ProviderInfo reports the provider build that last reconciled the cluster.
+	// +optional
+	ProviderInfo *ProviderInfo `json:"providerInfo,omitempty"`
+
+	// InternalControlPlaneEndpoint is the endpoint of the control plane used by the nodes.
+	// +optional
+	InternalControlPlaneEndpoint *APIEndpoint `json:"internalControlPlaneEndpoint,omitempty"`
+
+	// ExternalControlPlaneEndpoint is the external endpoint of the control plane, once it is published.
+	// +optional
+	ExternalControlPlaneEndpoint *APIEndpoint `json:"externalControlPlaneEndpoint,omitempty"`
+
+	// InfraNodePorts are the node ports of the infra cluster exposing the Services of the cluster.
+	// +optional
+	InfraNodePorts []InfraNodePort `json:"infraNodePorts,omitempty"`
+
+	// TenantSubnet is the subnet allocated to the cluster from the tenant supernet, when tenantNetwork is set. It
+	// is kept for the lifetime of the cluster.
+	// +optional
+	TenantSubnet string `json:"tenantSubnet,omitempty"`
+
+	// OrphanedVolumes lists the volumes of the deleted machines of the cluster left in the infra cluster: the disks
+	// kept by the Retain disk retention policy, and the PVCs the VMs used without owning them.
+	// +optional
+	OrphanedVolumes []OrphanedVolume `json:"orphanedVolumes,omitempty"`
+
+	// ResourceUsage reports the resources of the infra cluster consumed by the VMs of the cluster.
+	// +optional
+	ResourceUsage *ResourceUsage `json:"resourceUsage,omitempty"`
+
+	// UpgradePreflight reports the preflight checks of the pending upgrade of the control plane, if any.
+	// +optional
+	UpgradePreflight *UpgradePreflightStatus `json:"upgradePreflight,omitempty"`
+
+	// MigrationPolicy is the name of the MigrationPolicy of the infra cluster created for the migration policy of
+	// the cluster, deleted with the cluster.
+	// +optional
+	MigrationPolicy string `json:"migrationPolicy,omitempty"`
+
+	// DiskEncryption reports whether the disks of the VMs of the cluster honor its disk encryption policy, if any.
+	// +optional
+	DiskEncryption *DiskEncryptionStatus `json:"diskEncryption,omitempty"`
+
+	// WorkloadDNSProbeTime is the last time the probe of the DNS and of the service network of the workload cluster
+	// completed, when it is enabled.
+	// +optional
+	WorkloadDNSProbeTime *metav1.Time `json:"workloadDNSProbeTime,omitempty"`
+
+	// V1Beta2 groups the fields following the v1beta2 conventions of Cluster API.
+	// +optional
+	V1Beta2 *KubevirtClusterV1Beta2Status `json:"v1beta2,omitempty"`
+}
+
+// DiskEncryptionStatus reports whether the disks of the VMs of a cluster honor its disk encryption policy.
+type DiskEncryptionStatus struct {
+	// Honored is true when all the disks of the VMs of the cluster are encrypted as the policy requires.
+	Honored bool `json:"honored"`
+
+	// UnencryptedDisks lists the disks of the VMs not encrypted as the policy requires, as <vm>/<volume>.
+	// +optional
+	UnencryptedDisks []string `json:"unencryptedDisks,omitempty"`
+
+	// LastCheckTime is the last time the disks of the VMs were checked.
+	LastCheckTime metav1.Time `json:"lastCheckTime"`
+}
+
+// KubevirtClusterV1Beta2Status groups the fields of the KubevirtCluster status following the v1beta2 conventions
+// of Cluster API.
+type KubevirtClusterV1Beta2Status struct {
+	// Conditions represents the observations of the KubevirtCluster. They have a positive polarity, except
+	// Deleting. Ready summarizes LoadBalancerAvailable and, when the infra ownership lease is enabled,
+	// InfraOwnership.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +kubebuilder:validation:MaxItems=32
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ProviderInfo describes a build of the provider.
+type ProviderInfo struct {
+	// Version is the version of the provider.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Commit is the git commit the provider was built from.
+	// +optional
+	Commit string `json:"commit,omitempty"`
+
+	// BuildDate is the date the provider was built.
+	// +optional
+	BuildDate string `json:"buildDate,omitempty"`
+}
+
+// NestedVirtualizationStatus reports the infra nodes able to host the VMs of the machines requiring nested
+// virtualization.
+type NestedVirtualizationStatus struct {
+	// Nodes lists the infra nodes exposing /dev/kvm, with a CPU passing its virtualization extensions to VMs.
+	// +optional
+	Nodes []string `json:"nodes,omitempty"`
+
+	// LastCheckTime is the last time the infra nodes were checked.
+	LastCheckTime metav1.Time `json:"lastCheckTime"`
+}
+
+// InfraStatus reports the versions and the enabled feature gates of KubeVirt and CDI in the infra cluster, and the
+// architectures of its nodes. The fields of a component are empty when the credentials of the infra cluster do not
+// allow to read its resource.
+type InfraStatus struct {
+	// KubeVirtVersion is the version of KubeVirt deployed in the infra cluster.
+	// +optional
+	KubeVirtVersion string `json:"kubevirtVersion,omitempty"`
+
+	// KubeVirtFeatureGates lists the feature gates enabled in the configuration of KubeVirt.
+	// +optional
+	KubeVirtFeatureGates []string `json:"kubevirtFeatureGates,omitempty"`
+
+	// VMRolloutStrategy is the strategy KubeVirt propagates the changes of the VMs to their running VMIs with,
+	// Stage or LiveUpdate.
+	// +optional
+	VMRolloutStrategy string `json:"vmRolloutStrategy,omitempty"`
+
+	// CDIVersion is the version of CDI deployed in the infra cluster.
+	// +optional
+	CDIVersion string `json:"cdiVersion,omitempty"`
+
+	// CDIFeatureGates lists the feature gates enabled in the configuration of CDI.
+	// +optional
+	CDIFeatureGates []string `json:"cdiFeatureGates,omitempty"`
+
+	// NodeArchitectures lists the CPU architectures of the infra nodes. It is empty when the credentials of the
+	// infra cluster do not allow to list its nodes.
+	// +optional
+	NodeArchitectures []string `json:"nodeArchitectures,omitempty"`
+
+	// NodeZones lists the zones of the infra nodes, from their topology.kubernetes.io/zone label. It is empty when
+	// the credentials of the infra cluster do not allow to list its nodes.
+	// +optional
+	NodeZones []string `json:"nodeZones,omitempty"`
+
+	// HostDevices lists the host devices permitted in the configuration of KubeVirt, e.g. the GPUs passed through
+	// to the VMs as a whole or as mediated devices.
+	// +optional
+	// +listType=map
+	// +listMapKey=resourceName
+	HostDevices []InfraHostDevice `json:"hostDevices,omitempty"`
+
+	// LastCheckTime is the last time the infra cluster was checked.
+	LastCheckTime metav1.Time `json:"lastCheckTime"`
+}
+
+// InfraHostDevice is a host device the VMs of the infra cluster can use.
+type InfraHostDevice struct {
+	// ResourceName is the resource name the device plugins of the infra nodes expose the device with.
+	ResourceName string `json:"resourceName"`
+
+	// MediatedDeviceType is the type of the mediated devices of the resource, empty for the PCI devices passed
+	// through as a whole.
+	// +optional
+	MediatedDeviceType string `json:"mediatedDeviceType,omitempty"`
+
+	// Nodes is the number of infra nodes with allocatable devices of the resource. It is not set when the
+	// credentials of the infra cluster do not allow to list its nodes.
+	// +optional
+	Nodes *int32 `json:"nodes,omitempty"`
+}
+
+// ResourceUsage reports the resources of the infra cluster consumed by the VMs of a cluster.
+type ResourceUsage struct {
+	// VirtualMachines is the number of VMs of the cluster, running or not.
+	VirtualMachines int32 `json:"virtualMachines"`
+
+	// CPU is the number of vCPUs of the running VMs.
+	CPU resource.Quantity `json:"cpu"`
+
+	// Memory is the guest memory of the running VMs.
+	Memory resource.Quantity `json:"memory"`
+
+	// Storage is the capacity of the PVCs of the disks of the VMs, running or not.
+	Storage resource.Quantity `json:"storage"`
+
+	// LastUpdateTime is the last time the usage was computed.
+	LastUpdateTime metav1.Time `json:"lastUpdateTime"`
+}
+
+// UpgradePreflightStatus reports the preflight checks of an upgrade of the control plane.
+type UpgradePreflightStatus struct {
+	// TargetVersion is the Kubernetes version the control plane is upgraded to.
+	TargetVersion string `json:"targetVersion"`
+
+	// Checks lists the result of each preflight check.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Checks []UpgradePreflightCheck `json:"checks,omitempty"`
+
+	// LastCheckTime is the last time the checks ran.
+	LastCheckTime metav1.Time `json:"lastCheckTime"`
+}
+
+// UpgradePreflightCheck is the result of a preflight check of an upgrade of the control plane.
+type UpgradePreflightCheck struct {
+	// Name is the name of the check: EtcdFreeSpace, KubeVirtVersion, LiveMigration or PodDisruptionBudgets.
+	Name string `json:"name"`
+
+	// Passed is true when the check passed.
+	Passed bool `json:"passed"`
+
+	// Message describes why the check failed, or why it was skipped.
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // APIEndpoint represents a reachable Kubernetes API endpoint.
@@ -124,6 +1313,24 @@ type ServiceSpecTemplate struct {
 	// More info: https://kubernetes.io/docs/concepts/services-networking/service/#publishing-services-service-types
 	// +optional
 	Type corev1.ServiceType `json:"type,omitempty"`
+
+	// NodePort is the node port the Service requests in the infra cluster, when it is of type NodePort or
+	// LoadBalancer. It is allocated by the infra cluster if unset. The KubevirtClusters requesting a node port out of
+	// the node port range of the infra cluster, or already used or requested by another Service, are rejected at
+	// creation.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	NodePort int32 `json:"nodePort,omitempty"`
+}
+
+// InfraNodePort is a node port of the infra cluster exposing a Service of a cluster.
+type InfraNodePort struct {
+	// Service is the namespace and the name of the Service in the infra cluster.
+	Service string `json:"service"`
+
+	// NodePort is the node port of the Service.
+	NodePort int32 `json:"nodePort"`
 }
 
 // +kubebuilder:resource:path=kubevirtclusters,scope=Namespaced,categories=cluster-api
@@ -148,6 +1355,22 @@ func (c *KubevirtCluster) SetConditions(conditions clusterv1.Conditions) {
 	c.Status.Conditions = conditions
 }
 
+// GetV1Beta2Conditions returns the v1beta2 conditions of the KubevirtCluster.
+func (c *KubevirtCluster) GetV1Beta2Conditions() []metav1.Condition {
+	if c.Status.V1Beta2 == nil {
+		return nil
+	}
+	return c.Status.V1Beta2.Conditions
+}
+
+// SetV1Beta2Conditions sets the v1beta2 conditions of the KubevirtCluster.
+func (c *KubevirtCluster) SetV1Beta2Conditions(conditions []metav1.Condition) {
+	if c.Status.V1Beta2 == nil {
+		c.Status.V1Beta2 = &KubevirtClusterV1Beta2Status{}
+	}
+	c.Status.V1Beta2.Conditions = conditions
+}
+
 // +kubebuilder:object:root=true
 
 // KubevirtClusterList contains a list of KubevirtCluster.
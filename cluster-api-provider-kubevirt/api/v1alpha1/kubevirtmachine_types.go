@@ -55,6 +55,290 @@ type KubevirtMachineSpec struct {
 	// When nil, this defaults to the value present in the KubevirtCluster object's spec associated with this machine.
 	// +optional
 	InfraClusterSecretRef *corev1.ObjectReference `json:"infraClusterSecretRef,omitempty"`
+
+	// InternalAddress selects the address of the VM reported as the InternalIP of the machine, which kubelet
+	// registers the node with. When nil, the first address of the first interface of the VM is used.
+	// +optional
+	InternalAddress *NetworkAddressRule `json:"internalAddress,omitempty"`
+
+	// ExternalAddress selects the address of the VM reported as the ExternalIP of the machine. When nil, the
+	// InternalIP is also used as ExternalIP.
+	// +optional
+	ExternalAddress *NetworkAddressRule `json:"externalAddress,omitempty"`
+
+	// ProvisioningTimeouts bounds the time the machine can spend in each phase of its provisioning. When nil, the
+	// machine waits for each phase indefinitely.
+	// +optional
+	ProvisioningTimeouts *ProvisioningTimeouts `json:"provisioningTimeouts,omitempty"`
+
+	// BootstrapFailures diagnoses the failures of the bootstrap of the VM from its cloud-init log, read through the
+	// guest agent, and retries the bootstrap after the failures known to be transient. When nil, the failures are
+	// not diagnosed.
+	// +optional
+	BootstrapFailures *BootstrapFailuresSpec `json:"bootstrapFailures,omitempty"`
+
+	// RequiresNestedVirtualization schedules the VM only on the infra nodes supporting nested virtualization, so
+	// that the machine can run VMs itself, e.g. to host KubeVirt in the workload cluster. The VM is not created
+	// while the KubevirtCluster reports no such node.
+	// +optional
+	RequiresNestedVirtualization bool `json:"requiresNestedVirtualization,omitempty"`
+
+	// Preemptible marks the VM of the machine as preemptible: it gives way to the other VMs of the infra cluster
+	// under pressure, and can be reclaimed on request. A reclaimed machine has its Node tainted and drained in the
+	// workload cluster, and its VM stopped until the reclaim request is withdrawn.
+	// +optional
+	Preemptible *PreemptibleSpec `json:"preemptible,omitempty"`
+
+	// Image references a KubevirtMachineImage, whose imported disk is cloned into a disk of the VM instead of
+	// embedding the source of the image in the VM template.
+	// +optional
+	Image *MachineImageReference `json:"image,omitempty"`
+
+	// Architecture is the CPU architecture of the guest. The VM is scheduled on the infra nodes of this architecture,
+	// and boots the variant of its image built for it. Defaults to the architecture of the image of the machine, if
+	// any, or to the default architecture of KubeVirt.
+	// +kubebuilder:validation:Enum=amd64;arm64;s390x
+	// +optional
+	Architecture string `json:"architecture,omitempty"`
+
+	// MachineType is the machine type emulated for the guest, e.g. q35 on amd64 or virt on arm64. Defaults to q35,
+	// virt or s390-ccw-virtio for the architecture of the guest when set, or else to the default machine type of
+	// KubeVirt. The arm64 guests also boot with UEFI, without secure boot, unless the VM template sets a firmware.
+	// +optional
+	MachineType string `json:"machineType,omitempty"`
+
+	// IOThreadsPolicy is the IOThreads policy of the VM: shared, for a single IOThread shared by the disks without a
+	// dedicated one, or auto, for a pool of IOThreads sized after the vCPUs. It takes precedence over the one of the
+	// VMI template.
+	// +kubebuilder:validation:Enum=shared;auto
+	// +optional
+	IOThreadsPolicy *kubevirtv1.IOThreadsPolicy `json:"ioThreadsPolicy,omitempty"`
+
+	// Disks tunes the I/O of the disks of the VMI template, by name. The settings take precedence over the ones of
+	// the VMI template; the disks the template does not have are ignored.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Disks []DiskTuning `json:"disks,omitempty"`
+
+	// MigrationPolicy overrides the fields of the migration policy of the cluster for the VM of the machine.
+	// +optional
+	MigrationPolicy *MigrationPolicySpec `json:"migrationPolicy,omitempty"`
+
+	// GPUs attaches GPUs to the VM, passed through as a whole or as mediated devices, e.g. vGPU profiles. They are
+	// added to the GPUs of the VMI template. The VM is not created while the infra cluster is known not to provide
+	// them.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	GPUs []MachineGPU `json:"gpus,omitempty"`
+
+	// GuestAgent configures the devices of the VM to communicate with its guest, and whether its image runs the
+	// qemu-guest-agent.
+	// +optional
+	GuestAgent *GuestAgentSpec `json:"guestAgent,omitempty"`
+}
+
+// GuestAgentMode tells whether the image of a VM runs the qemu-guest-agent.
+// +kubebuilder:validation:Enum=Optional;Required;Disabled
+type GuestAgentMode string
+
+const (
+	// GuestAgentOptional reports whether the guest agent is connected, and uses it when it is.
+	GuestAgentOptional GuestAgentMode = "Optional"
+
+	// GuestAgentRequired makes the VM ready only once its guest agent responds, and fails fast the machines whose
+	// guest agent does not connect.
+	GuestAgentRequired GuestAgentMode = "Required"
+
+	// GuestAgentDisabled neither reports nor uses the guest agent, for the images without it.
+	GuestAgentDisabled GuestAgentMode = "Disabled"
+)
+
+// GuestAgentSpec configures the devices of a VM to communicate with its guest, and the use of its qemu-guest-agent.
+type GuestAgentSpec struct {
+	// Mode tells whether the image of the VM runs the qemu-guest-agent. Optional, the default, reports the
+	// connection of the agent in the GuestAgentConnected condition. Required adds a guest agent ping readiness probe
+	// to the VMI, unless its template defines a readiness probe, and reports the machines whose agent did not
+	// connect within the ConnectTimeout with Severity=Error. Disabled does not report the agent, nor use it to run
+	// commands, read the bootstrap log or check for pending reboots.
+	// +kubebuilder:default=Optional
+	// +optional
+	Mode GuestAgentMode `json:"mode,omitempty"`
+
+	// ConnectTimeout is how long the guest agent of a running VMI may take to connect, in the Required mode.
+	// Defaults to 5m.
+	// +optional
+	ConnectTimeout *metav1.Duration `json:"connectTimeout,omitempty"`
+
+	// SerialConsole attaches a serial console to the VM. KubeVirt attaches one by default; the qemu-guest-agent
+	// uses its own virtio-serial channel, always attached.
+	// +optional
+	SerialConsole *bool `json:"serialConsole,omitempty"`
+
+	// VSOCK attaches a virtio-vsock device to the VM, for the guest agents and services of the guest communicating
+	// with the host over VSOCK. It requires the VSOCK feature gate of KubeVirt.
+	// +optional
+	VSOCK *bool `json:"vsock,omitempty"`
+}
+
+// MachineGPU is a GPU of the VM of a machine, either a physical GPU passed through as a whole, or a mediated device
+// of a physical GPU.
+// +kubebuilder:validation:XValidation:rule="has(self.deviceName) || has(self.mediatedDeviceType)", message="deviceName or mediatedDeviceType is required"
+type MachineGPU struct {
+	// Name is the name of the GPU in the VM.
+	Name string `json:"name"`
+
+	// DeviceName is the resource name the device plugins of the infra nodes expose the GPU with, e.g.
+	// nvidia.com/TU104GL_Tesla_T4 for a GPU passed through as a whole, or nvidia.com/GRID_T4-1Q for a vGPU
+	// profile. It is resolved from the mediated device type when not set.
+	// +optional
+	DeviceName string `json:"deviceName,omitempty"`
+
+	// MediatedDeviceType is the type of the mediated device, e.g. the vGPU profile "GRID T4-1Q", as selected by the
+	// mdevNameSelector of the mediated devices permitted in the configuration of KubeVirt.
+	// +optional
+	MediatedDeviceType string `json:"mediatedDeviceType,omitempty"`
+}
+
+// DiskTuning tunes the I/O of a disk of the VM.
+type DiskTuning struct {
+	// Name is the name of the disk in the VMI template.
+	Name string `json:"name"`
+
+	// Bus is the bus the disk is attached to: virtio for the best performance, scsi for the guests relying on SCSI
+	// commands, or sata for the guests without virtio drivers.
+	// +kubebuilder:validation:Enum=virtio;scsi;sata
+	// +optional
+	Bus kubevirtv1.DiskBus `json:"bus,omitempty"`
+
+	// Cache is the host cache mode of the disk: none, writethrough or writeback.
+	// +kubebuilder:validation:Enum=none;writethrough;writeback
+	// +optional
+	Cache kubevirtv1.DriverCache `json:"cache,omitempty"`
+
+	// DedicatedIOThread gives the disk its own IOThread, enabling the IOThreads of the VM.
+	// +optional
+	DedicatedIOThread *bool `json:"dedicatedIOThread,omitempty"`
+
+	// Discard passes the discard (TRIM) requests of the guest to the storage when true, by leaving the DataVolume
+	// of the disk thin provisioned, or preallocates it when false, KubeVirt ignoring the discard requests on
+	// preallocated volumes. It only applies to the disks of DataVolumeTemplates.
+	// +optional
+	Discard *bool `json:"discard,omitempty"`
+}
+
+// MachineImageReference references the KubevirtMachineImage booted by a machine. Exactly one of name and channel
+// is set.
+type MachineImageReference struct {
+	// Name is the name of the KubevirtMachineImage, in the namespace of the KubevirtMachine.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Namespace is the namespace of the KubevirtMachineImage, which must be shared with the namespace of the
+	// KubevirtMachine. Defaults to the namespace of the KubevirtMachine. It does not apply to channels.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Channel is the name of a KubevirtMachineImageChannel, in the namespace of the KubevirtMachine. The machine
+	// boots the image published in the channel when it is created.
+	// +optional
+	Channel string `json:"channel,omitempty"`
+
+	// DataVolumeTemplate is the name of the DataVolumeTemplate of the VM template the image is cloned into, as
+	// named in the template. Defaults to the first DataVolumeTemplate.
+	// +optional
+	DataVolumeTemplate string `json:"dataVolumeTemplate,omitempty"`
+}
+
+// PreemptibleSpec defines how the VM of a preemptible machine gives way to the other VMs of the infra cluster.
+type PreemptibleSpec struct {
+	// PriorityClassName is the priority class of the VM in the infra cluster. It should be lower than the priority
+	// of the other VMs, so that the infra scheduler preempts the preemptible VMs first.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+}
+
+// ProvisioningTimeouts defines how long each phase of the provisioning of a machine may take. A nil timeout
+// disables the check of its phase.
+type ProvisioningTimeouts struct {
+	// DataVolumeImport bounds the import of the DataVolumes of the VM, from the creation of the VM.
+	// +optional
+	DataVolumeImport *metav1.Duration `json:"dataVolumeImport,omitempty"`
+
+	// VMStart bounds the time the VMI takes to be scheduled and become ready, once its DataVolumes are imported.
+	// +optional
+	VMStart *metav1.Duration `json:"vmStart,omitempty"`
+
+	// Bootstrap bounds the execution of the bootstrap data, once the VMI is ready.
+	// +optional
+	Bootstrap *metav1.Duration `json:"bootstrap,omitempty"`
+
+	// NodeRegistration bounds the time the Node of the machine takes to appear in the workload cluster, once
+	// the machine is bootstrapped.
+	// +optional
+	NodeRegistration *metav1.Duration `json:"nodeRegistration,omitempty"`
+
+	// MaxVMRecreations is the number of times the VM is deleted and created again after a timeout expired,
+	// before the machine is failed. A node registration timeout always fails the machine, as its providerID is
+	// already set.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxVMRecreations int32 `json:"maxVMRecreations,omitempty"`
+}
+
+// BootstrapFailuresSpec defines how the failures of the bootstrap of a machine are retried.
+type BootstrapFailuresSpec struct {
+	// MaxRetries is the number of times the bootstrap is retried after a transient failure: the VM is created again
+	// after a network or image pull failure, and with a fresh join token after a join token failure. Zero only
+	// reports the failures.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxRetries int32 `json:"maxRetries,omitempty"`
+}
+
+// BootstrapDiagnosis is the outcome of the last read of the bootstrap log of a VM.
+type BootstrapDiagnosis struct {
+	// Reason classifies the failure shown by the bootstrap log: BootstrapNetworkUnreachable,
+	// BootstrapImagePullFailed, BootstrapTokenExpired or BootstrapPreflightFailed. Empty when the log shows no known
+	// failure.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message is the line of the bootstrap log showing the failure.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// CheckTime is the time the bootstrap log was read.
+	CheckTime metav1.Time `json:"checkTime"`
+}
+
+// ProvisioningPhase is a phase of the provisioning of a machine that can time out.
+type ProvisioningPhase string
+
+const (
+	// DataVolumeImportPhase is the import of the DataVolumes of the VM.
+	DataVolumeImportPhase ProvisioningPhase = "DataVolumeImport"
+	// VMStartPhase is the scheduling and boot of the VMI.
+	VMStartPhase ProvisioningPhase = "VMStart"
+	// BootstrapPhase is the execution of the bootstrap data in the VM.
+	BootstrapPhase ProvisioningPhase = "Bootstrap"
+	// NodeRegistrationPhase is the registration of the Node in the workload cluster.
+	NodeRegistrationPhase ProvisioningPhase = "NodeRegistration"
+)
+
+// NetworkAddressRule selects an address among the addresses the VMI reports for its interfaces.
+type NetworkAddressRule struct {
+	// Network is the name of the network of the VM, in spec.template.spec.networks, whose interface supplies
+	// the address. When empty, all the interfaces are considered, in order.
+	// +optional
+	Network string `json:"network,omitempty"`
+
+	// CIDR restricts the address to the given range, e.g. to choose between the IPv4 and IPv6 addresses of an
+	// interface.
+	// +optional
+	// +kubebuilder:validation:Format=cidr
+	CIDR string `json:"cidr,omitempty"`
 }
 
 // VirtualMachineBootstrapCheckSpec defines how the controller will remotely check CAPI Sentinel file content.
@@ -128,6 +412,267 @@ type KubevirtMachineStatus struct {
 	// controller's output.
 	// +optional
 	FailureMessage *string `json:"failureMessage,omitempty"`
+
+	// VirtualMachineInstance reflects the state of the VMI backing the machine in the infra cluster.
+	// +optional
+	VirtualMachineInstance *VirtualMachineInstanceInfo `json:"virtualMachineInstance,omitempty"`
+
+	// Node reflects the state of the Node of the machine in the workload cluster.
+	// +optional
+	Node *NodeInfo `json:"node,omitempty"`
+
+	// ProvisioningPhase is the phase of the provisioning the machine is waiting on, if any.
+	// +optional
+	ProvisioningPhase ProvisioningPhase `json:"provisioningPhase,omitempty"`
+
+	// ProvisioningPhaseStartTime is the time the machine entered its current provisioning phase.
+	// +optional
+	ProvisioningPhaseStartTime *metav1.Time `json:"provisioningPhaseStartTime,omitempty"`
+
+	// VMRecreations counts the VMs of the machine deleted because a provisioning timeout expired.
+	// +optional
+	VMRecreations int32 `json:"vmRecreations,omitempty"`
+
+	// BootstrapDiagnosis is the outcome of the last read of the bootstrap log of the VM, while the machine is not
+	// bootstrapped.
+	// +optional
+	BootstrapDiagnosis *BootstrapDiagnosis `json:"bootstrapDiagnosis,omitempty"`
+
+	// BootstrapRetries counts the retries of the bootstrap of the machine after a transient failure.
+	// +optional
+	BootstrapRetries int32 `json:"bootstrapRetries,omitempty"`
+
+	// BootstrapTokenExpiryTime is the estimated expiry of the join token of the bootstrap data of the machine, from
+	// the TTL of the tokens and the last refresh, while the machine is not provisioned.
+	// +optional
+	BootstrapTokenExpiryTime *metav1.Time `json:"bootstrapTokenExpiryTime,omitempty"`
+
+	// MigrationPolicy is the name of the MigrationPolicy of the infra cluster created for the migration policy of
+	// the machine, deleted with the machine.
+	// +optional
+	MigrationPolicy string `json:"migrationPolicy,omitempty"`
+
+	// DiskExport is the state of the export of the disks of the VM, requested with the export-disks annotation.
+	// +optional
+	DiskExport *DiskExportStatus `json:"diskExport,omitempty"`
+
+	// VMDriftCheckTime is the last time the VM was compared with the VM rendered from the machine, when the
+	// KubevirtCluster enables the detection of the drift of its VMs.
+	// +optional
+	VMDriftCheckTime *metav1.Time `json:"vmDriftCheckTime,omitempty"`
+
+	// Operations are the last long operations run in the background for the machine, e.g. the drains of its Node
+	// and the commands run in its VM, one per type.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Operations []MachineOperation `json:"operations,omitempty"`
+
+	// GPUs reports the GPUs assigned to the VMI, with the UUIDs of its mediated devices, read from the virt-launcher
+	// pod of the VMI.
+	// +optional
+	GPUs *GPUStatus `json:"gpus,omitempty"`
+
+	// V1Beta2 groups the fields following the v1beta2 conventions of Cluster API.
+	// +optional
+	V1Beta2 *KubevirtMachineV1Beta2Status `json:"v1beta2,omitempty"`
+}
+
+// GPUStatus reports the GPUs assigned to the VMI of a machine.
+type GPUStatus struct {
+	// NodeName is the infra node the VMI ran on when its GPUs were read.
+	// +optional
+	NodeName string `json:"nodeName,omitempty"`
+
+	// Devices are the GPUs of the VMI.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Devices []AssignedGPU `json:"devices,omitempty"`
+
+	// CheckTime is the last time the GPUs of the VMI were read.
+	CheckTime metav1.Time `json:"checkTime"`
+}
+
+// AssignedGPU is a GPU assigned to a VMI.
+type AssignedGPU struct {
+	// Name is the name of the GPU in the VM.
+	Name string `json:"name"`
+
+	// DeviceName is the resource name of the GPU.
+	DeviceName string `json:"deviceName"`
+
+	// MediatedDeviceUUID is the UUID of the mediated device assigned to the VMI, for the mediated devices.
+	// +optional
+	MediatedDeviceUUID string `json:"mediatedDeviceUUID,omitempty"`
+}
+
+// DiskExportStatus is the state of the export of the disks of a failed machine.
+type DiskExportStatus struct {
+	// Phase is the phase of the VirtualMachineExport of the infra cluster: Pending, Ready, Terminated or Skipped,
+	// or Expired once it was removed at the end of its lifetime.
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// Message explains why the disks are not exported yet, if known.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Volumes are the download URLs of the gzipped raw images of the disks of the VM.
+	// +optional
+	Volumes []ExportedVolume `json:"volumes,omitempty"`
+
+	// TokenSecretName is the name of the secret of the namespace of the machine holding the token to pass in the
+	// x-kubevirt-export-token header of the downloads, and the CA certificate of the export proxy.
+	// +optional
+	TokenSecretName string `json:"tokenSecretName,omitempty"`
+
+	// ExpirationTime is the time the export, and its URLs, expire.
+	// +optional
+	ExpirationTime *metav1.Time `json:"expirationTime,omitempty"`
+}
+
+// ExportedVolume is a disk of an exported VM.
+type ExportedVolume struct {
+	// Name is the name of the volume of the VM.
+	Name string `json:"name"`
+
+	// URL is the download URL of the gzipped raw image of the volume.
+	URL string `json:"url"`
+}
+
+// MachineOperationPhase is the phase of a long operation of a machine.
+type MachineOperationPhase string
+
+const (
+	// RunningMachineOperationPhase is an operation still running in the background.
+	RunningMachineOperationPhase MachineOperationPhase = "Running"
+	// SucceededMachineOperationPhase is an operation that completed.
+	SucceededMachineOperationPhase MachineOperationPhase = "Succeeded"
+	// FailedMachineOperationPhase is an operation that failed, or did not complete within its timeout.
+	FailedMachineOperationPhase MachineOperationPhase = "Failed"
+)
+
+// MachineOperation is the record of a long operation run in the background for a machine, so that the reconciles
+// of the machine poll it rather than wait for it.
+type MachineOperation struct {
+	// Type is the type of the operation, e.g. Drain or Command/kubelet-logs.
+	Type string `json:"type"`
+
+	// Phase is the phase of the operation: Running, Succeeded or Failed.
+	Phase MachineOperationPhase `json:"phase"`
+
+	// StartTime is the time the operation started.
+	StartTime metav1.Time `json:"startTime"`
+
+	// CompletionTime is the time the operation completed, once it did.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Message is the error the operation failed with, if any.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// KubevirtMachineV1Beta2Status groups the fields of the KubevirtMachine status following the v1beta2 conventions
+// of Cluster API.
+type KubevirtMachineV1Beta2Status struct {
+	// Conditions represents the observations of the KubevirtMachine. They have a positive polarity, except
+	// Deleting. Ready summarizes VMProvisioned and BootstrapExecSucceeded.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +kubebuilder:validation:MaxItems=32
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// NodeInfo is the state of a workload cluster Node, as reported by its kubelet.
+type NodeInfo struct {
+	// Ready is the status of the Ready condition of the Node.
+	// +optional
+	Ready corev1.ConditionStatus `json:"ready,omitempty"`
+
+	// KubeletVersion is the version of the kubelet running on the Node.
+	// +optional
+	KubeletVersion string `json:"kubeletVersion,omitempty"`
+}
+
+// VirtualMachineInstanceInfo is the state of a VMI, as reported by KubeVirt.
+type VirtualMachineInstanceInfo struct {
+	// Phase is the phase of the VMI, e.g. Scheduling, Running or Failed.
+	// +optional
+	Phase kubevirtv1.VirtualMachineInstancePhase `json:"phase,omitempty"`
+
+	// NodeName is the name of the infra cluster node the VMI runs on.
+	// +optional
+	NodeName string `json:"nodeName,omitempty"`
+
+	// Zone is the topology.kubernetes.io/zone label of the infra cluster node the VMI runs on.
+	// +optional
+	Zone string `json:"zone,omitempty"`
+
+	// MigrationTargetNode is the name of the infra cluster node the VMI is being live migrated to, if a
+	// migration is in progress.
+	// +optional
+	MigrationTargetNode string `json:"migrationTargetNode,omitempty"`
+
+	// Migration reports the live migration of the VMI in progress, if any.
+	// +optional
+	Migration *MigrationInfo `json:"migration,omitempty"`
+
+	// AgentConnected denotes that the guest agent of the VM is connected.
+	// +optional
+	AgentConnected bool `json:"agentConnected,omitempty"`
+
+	// RunningTime is the time the VMI entered the Running phase.
+	// +optional
+	RunningTime *metav1.Time `json:"runningTime,omitempty"`
+
+	// GuestOS is the operating system of the guest, as reported by the guest agent.
+	// +optional
+	GuestOS *GuestOSInfo `json:"guestOS,omitempty"`
+}
+
+// MigrationInfo is the state of a live migration of a VMI, as reported by KubeVirt.
+type MigrationInfo struct {
+	// Name is the name of the VirtualMachineInstanceMigration in the infra cluster.
+	Name string `json:"name"`
+
+	// Phase is the phase of the migration, e.g. Scheduling or Running.
+	// +optional
+	Phase kubevirtv1.VirtualMachineInstanceMigrationPhase `json:"phase,omitempty"`
+
+	// ProgressPercent estimates the progress of the migration from its phase, KubeVirt not reporting the memory
+	// transferred.
+	// +optional
+	ProgressPercent int32 `json:"progressPercent,omitempty"`
+
+	// Mode is the mode of the migration, PreCopy or PostCopy, once it started.
+	// +optional
+	Mode kubevirtv1.MigrationMode `json:"mode,omitempty"`
+
+	// SourceNode is the name of the infra cluster node the VMI is migrated from.
+	// +optional
+	SourceNode string `json:"sourceNode,omitempty"`
+
+	// StartTime is the time the memory of the VMI started being migrated.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+}
+
+// GuestOSInfo describes the operating system running in a VM.
+type GuestOSInfo struct {
+	// Name is the name of the operating system, e.g. Ubuntu.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Version is the version of the operating system, e.g. 22.04.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// KernelRelease is the release of the kernel running in the guest.
+	// +optional
+	KernelRelease string `json:"kernelRelease,omitempty"`
 }
 
 // +kubebuilder:resource:path=kubevirtmachines,scope=Namespaced,categories=cluster-api
@@ -136,6 +681,10 @@ type KubevirtMachineStatus struct {
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:printcolumn:name="Ready",type="boolean",JSONPath=".status.ready",description="Is machine ready"
+// +kubebuilder:printcolumn:name="VMI",type="string",JSONPath=".status.virtualMachineInstance.phase",description="Phase of the VMI"
+// +kubebuilder:printcolumn:name="Node",type="string",JSONPath=".status.virtualMachineInstance.nodeName",description="Infra node hosting the VMI",priority=1
+// +kubebuilder:printcolumn:name="Node Ready",type="string",JSONPath=".status.node.ready",description="Readiness of the workload cluster Node",priority=1
+// +kubebuilder:printcolumn:name="Kubelet",type="string",JSONPath=".status.node.kubeletVersion",description="Kubelet version of the workload cluster Node",priority=1
 
 // KubevirtMachine is the Schema for the kubevirtmachines API.
 type KubevirtMachine struct {
@@ -154,6 +703,22 @@ func (c *KubevirtMachine) SetConditions(conditions clusterv1.Conditions) {
 	c.Status.Conditions = conditions
 }
 
+// GetV1Beta2Conditions returns the v1beta2 conditions of the KubevirtMachine.
+func (c *KubevirtMachine) GetV1Beta2Conditions() []metav1.Condition {
+	if c.Status.V1Beta2 == nil {
+		return nil
+	}
+	return c.Status.V1Beta2.Conditions
+}
+
+// SetV1Beta2Conditions sets the v1beta2 conditions of the KubevirtMachine.
+func (c *KubevirtMachine) SetV1Beta2Conditions(conditions []metav1.Condition) {
+	if c.Status.V1Beta2 == nil {
+		c.Status.V1Beta2 = &KubevirtMachineV1Beta2Status{}
+	}
+	c.Status.V1Beta2.Conditions = conditions
+}
+
 // +kubebuilder:object:root=true
 
 // KubevirtMachineList contains a list of KubevirtMachine.
@@ -0,0 +1,175 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// MachineImageFinalizer allows the KubevirtMachineImage controller to delete the disks imported in the infra
+	// clusters before the KubevirtMachineImage is removed.
+	MachineImageFinalizer = "kubevirtmachineimage.infrastructure.cluster.x-k8s.io"
+
+	// MachineImageSourceAnnotation is set on the DataVolume importing an image to the source it was imported from,
+	// including its checksum. The image is imported again when its source changes.
+	MachineImageSourceAnnotation = "infrastructure.cluster.x-k8s.io/machine-image-source"
+)
+
+// MachineImageSource is where the disk image is imported from. Exactly one of its fields is set.
+type MachineImageSource struct {
+	// URL is the http(s) URL of the disk image, e.g. a qcow2 file.
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// Registry is the container image holding the disk image, e.g. docker://quay.io/containerdisks/ubuntu:22.04.
+	// +optional
+	Registry string `json:"registry,omitempty"`
+}
+
+// MachineImageVariant is a build of the image for another CPU architecture.
+type MachineImageVariant struct {
+	// Architecture is the CPU architecture of the variant.
+	// +kubebuilder:validation:Enum=amd64;arm64;s390x
+	Architecture string `json:"architecture"`
+
+	// Source is where the disk image of the variant is imported from.
+	Source MachineImageSource `json:"source"`
+
+	// Checksum is the digest of the disk image of the variant, as sha256:<hex>.
+	// +kubebuilder:validation:Pattern=`^sha256:[a-f0-9]{64}$`
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// KubevirtMachineImageSpec defines the desired state of KubevirtMachineImage.
+type KubevirtMachineImageSpec struct {
+	// Source is where the disk image is imported from.
+	Source MachineImageSource `json:"source"`
+
+	// Checksum is the digest of the image, as sha256:<hex>. Registry images are pulled by this digest. Changing it
+	// imports the image again.
+	// +kubebuilder:validation:Pattern=`^sha256:[a-f0-9]{64}$`
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
+
+	// Architecture is the CPU architecture of the image. The VMs booting it are scheduled on the infra nodes of
+	// this architecture. Defaults to amd64.
+	// +kubebuilder:validation:Enum=amd64;arm64;s390x
+	// +optional
+	Architecture string `json:"architecture,omitempty"`
+
+	// Variants are the builds of the image for other CPU architectures, so that the machines of mixed
+	// architecture clusters boot the same image. Each variant is imported besides the image in the infra clusters.
+	// +listType=map
+	// +listMapKey=architecture
+	// +optional
+	Variants []MachineImageVariant `json:"variants,omitempty"`
+
+	// Size is the size of the disk the image is imported into.
+	Size resource.Quantity `json:"size"`
+
+	// StorageClassName is the storage class of the disk the image is imported into, and the default one of the
+	// disks cloned from it. Defaults to the default storage class of the infra cluster.
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// SharedWith are the namespaces whose KubevirtMachines may boot the image besides its own, so that golden
+	// images are imported once in a shared namespace rather than in every tenant namespace. When the management
+	// cluster is the infra cluster, the VMs of these namespaces clone the image through a CDI clone grant.
+	// +optional
+	SharedWith []string `json:"sharedWith,omitempty"`
+}
+
+// MachineImageImport is the import of an image in an infra cluster.
+type MachineImageImport struct {
+	// InfraClusterSecretRef is the reference to the kubeconfig of the infra cluster, as set on the KubevirtClusters.
+	// It is nil for the management cluster.
+	// +optional
+	InfraClusterSecretRef *corev1.ObjectReference `json:"infraClusterSecretRef,omitempty"`
+
+	// Namespace is the namespace of the DataVolume importing the image in the infra cluster.
+	Namespace string `json:"namespace"`
+
+	// DataVolumeName is the name of the DataVolume importing the image in the infra cluster.
+	DataVolumeName string `json:"dataVolumeName"`
+
+	// Architecture is the architecture of the variant imported. It is empty for the image itself.
+	// +optional
+	Architecture string `json:"architecture,omitempty"`
+
+	// Phase is the phase of the DataVolume.
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// Progress is the progress of the import, e.g. 42.00%.
+	// +optional
+	Progress string `json:"progress,omitempty"`
+
+	// Ready is true once the image is imported, and can be cloned into the disks of the VMs.
+	// +optional
+	Ready bool `json:"ready,omitempty"`
+
+	// CloneGrants are the namespaces of the infra cluster whose VMs are granted to clone the imported image, besides
+	// the namespace of the import.
+	// +optional
+	CloneGrants []string `json:"cloneGrants,omitempty"`
+}
+
+// KubevirtMachineImageStatus defines the observed state of KubevirtMachineImage.
+type KubevirtMachineImageStatus struct {
+	// Ready is true once the image is imported in all the infra clusters of the KubevirtClusters of its namespace.
+	// +optional
+	Ready bool `json:"ready,omitempty"`
+
+	// Imports are the imports of the image, one per infra cluster and variant.
+	// +optional
+	Imports []MachineImageImport `json:"imports,omitempty"`
+}
+
+// +kubebuilder:resource:path=kubevirtmachineimages,scope=Namespaced,categories=cluster-api
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="Ready",type="boolean",JSONPath=".status.ready",description="Is the image imported in all infra clusters"
+// +kubebuilder:printcolumn:name="Architecture",type="string",JSONPath=".spec.architecture",description="CPU architecture of the image"
+
+// KubevirtMachineImage is the Schema for the kubevirtmachineimages API. It imports a disk image once in each infra
+// cluster, so that KubevirtMachines reference it by name and clone it into the disks of their VMs.
+type KubevirtMachineImage struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KubevirtMachineImageSpec   `json:"spec,omitempty"`
+	Status KubevirtMachineImageStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KubevirtMachineImageList contains a list of KubevirtMachineImage.
+type KubevirtMachineImageList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KubevirtMachineImage `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KubevirtMachineImage{}, &KubevirtMachineImageList{})
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MaintenanceWindow is a recurring window, starting and ending at the times matched by cron expressions.
+type MaintenanceWindow struct {
+	// Start is the cron expression, in the "minute hour day-of-month month day-of-week" format, of the start of
+	// the window.
+	Start string `json:"start"`
+
+	// End is the cron expression, in the "minute hour day-of-month month day-of-week" format, of the end of the
+	// window.
+	End string `json:"end"`
+
+	// TimeZone is the name of the time zone the cron expressions are evaluated in, e.g. "Europe/Paris".
+	// Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// ImageChannelRollout defines how the machines booting a channel are replaced when a new image is published.
+type ImageChannelRollout struct {
+	// MaintenanceWindows are the windows new images are published and rolled out in. Defaults to publishing and
+	// rolling out new images as soon as they are imported.
+	// +optional
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+}
+
+// KubevirtMachineImageChannelSpec defines the desired state of KubevirtMachineImageChannel.
+type KubevirtMachineImageChannelSpec struct {
+	// Image is the name of the KubevirtMachineImage to publish in the channel, in its namespace. A new image is
+	// published once it is imported in all the infra clusters.
+	Image string `json:"image"`
+
+	// Rollout, if set, rolls out the MachineDeployments whose KubevirtMachineTemplate boots the channel when a
+	// new image is published. Otherwise, only the machines created afterwards boot the new image.
+	// +optional
+	Rollout *ImageChannelRollout `json:"rollout,omitempty"`
+}
+
+// KubevirtMachineImageChannelStatus defines the observed state of KubevirtMachineImageChannel.
+type KubevirtMachineImageChannelStatus struct {
+	// Image is the name of the KubevirtMachineImage published in the channel, booted by the machines created
+	// from now on.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// PublishedTime is the time the image was published.
+	// +optional
+	PublishedTime *metav1.Time `json:"publishedTime,omitempty"`
+
+	// RolledOutMachineDeployments are the names of the MachineDeployments rolled out to boot the published image.
+	// +optional
+	RolledOutMachineDeployments []string `json:"rolledOutMachineDeployments,omitempty"`
+}
+
+// +kubebuilder:resource:path=kubevirtmachineimagechannels,scope=Namespaced,categories=cluster-api
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="Image",type="string",JSONPath=".status.image",description="Image published in the channel"
+// +kubebuilder:printcolumn:name="Published",type="date",JSONPath=".status.publishedTime",description="Time the image was published"
+
+// KubevirtMachineImageChannel is the Schema for the kubevirtmachineimagechannels API. It publishes a sequence of
+// KubevirtMachineImages to the KubevirtMachines referencing it, and optionally rolls out their MachineDeployments
+// within maintenance windows, so that the OS of the nodes stays patched without editing the machine templates.
+type KubevirtMachineImageChannel struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KubevirtMachineImageChannelSpec   `json:"spec,omitempty"`
+	Status KubevirtMachineImageChannelStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KubevirtMachineImageChannelList contains a list of KubevirtMachineImageChannel.
+type KubevirtMachineImageChannelList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KubevirtMachineImageChannel `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KubevirtMachineImageChannel{}, &KubevirtMachineImageChannelList{})
+}
@@ -25,16 +25,37 @@ type KubevirtMachineTemplateSpec struct {
 	Template KubevirtMachineTemplateResource `json:"template"`
 }
 
+// KubevirtMachineTemplateStatus defines the observed state of KubevirtMachineTemplate.
+type KubevirtMachineTemplateStatus struct {
+	// NodeInfo describes the Nodes of the machines created from the template, for the cluster autoscaler to label
+	// the Nodes it expects when scaling their MachineDeployments from zero.
+	// +optional
+	NodeInfo *TemplateNodeInfo `json:"nodeInfo,omitempty"`
+}
+
+// TemplateNodeInfo describes the Nodes of the machines created from a template.
+type TemplateNodeInfo struct {
+	// Architecture is the CPU architecture of the Nodes, i.e. their kubernetes.io/arch label.
+	// +optional
+	Architecture string `json:"architecture,omitempty"`
+
+	// OperatingSystem is the operating system of the Nodes, i.e. their kubernetes.io/os label.
+	// +optional
+	OperatingSystem string `json:"operatingSystem,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:path=kubevirtmachinetemplates,scope=Namespaced,categories=cluster-api
 // +kubebuilder:storageversion
+// +kubebuilder:subresource:status
 
 // KubevirtMachineTemplate is the Schema for the kubevirtmachinetemplates API.
 type KubevirtMachineTemplate struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec KubevirtMachineTemplateSpec `json:"spec,omitempty"`
+	Spec   KubevirtMachineTemplateSpec   `json:"spec,omitempty"`
+	Status KubevirtMachineTemplateStatus `json:"status,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -0,0 +1,141 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+// ProviderConfigName is the name of the KubevirtProviderConfig whose defaults are merged into the new
+// KubevirtClusters and KubevirtMachines.
+const ProviderConfigName = "default"
+
+// OffloadProfile defines how the I/O of the disks of the VMs is offloaded to IOThreads and tuned.
+type OffloadProfile struct {
+	// IOThreadsPolicy is the IOThreads policy of the VMs, see KubevirtMachineSpec.IOThreadsPolicy.
+	// +kubebuilder:validation:Enum=shared;auto
+	// +optional
+	IOThreadsPolicy *kubevirtv1.IOThreadsPolicy `json:"ioThreadsPolicy,omitempty"`
+
+	// Disks tune the I/O of the disks of the VMs, see KubevirtMachineSpec.Disks. They are added to the tunings of
+	// the machines for the disks the machines do not tune.
+	// +optional
+	Disks []DiskTuning `json:"disks,omitempty"`
+}
+
+// PlacementPolicy defines the infra nodes the VMs are scheduled on.
+type PlacementPolicy struct {
+	// NodeSelector selects the infra nodes of the VMs.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Affinity is the affinity of the VMs to the infra nodes and to the other pods.
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// Tolerations let the VMs be scheduled on the tainted infra nodes.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+}
+
+// AddonSet defines the addons deployed for the workload clusters.
+type AddonSet struct {
+	// CloudControllerManager deploys the KubeVirt cloud controller manager, see
+	// KubevirtClusterSpec.CloudControllerManager.
+	// +optional
+	CloudControllerManager *CloudControllerManagerSpec `json:"cloudControllerManager,omitempty"`
+
+	// CSIDriver deploys the KubeVirt CSI driver, see KubevirtClusterSpec.CSIDriver.
+	// +optional
+	CSIDriver *CSIDriverSpec `json:"csiDriver,omitempty"`
+}
+
+// ProviderDefaults are the defaults of the organization, each only applied to the new objects not setting it.
+type ProviderDefaults struct {
+	// StorageClassName is the storage class of the DataVolumeTemplates of the KubevirtMachines without one.
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// Offload is the I/O offload profile of the KubevirtMachines.
+	// +optional
+	Offload *OffloadProfile `json:"offload,omitempty"`
+
+	// Placement is the placement of the VMs of the KubevirtMachines. Each of its fields is only applied when the
+	// VMI template of a machine does not set it.
+	// +optional
+	Placement *PlacementPolicy `json:"placement,omitempty"`
+
+	// RegistryMirrors are the registry mirrors of the KubevirtClusters without any.
+	// +optional
+	RegistryMirrors []RegistryMirror `json:"registryMirrors,omitempty"`
+
+	// Addons are the addons of the KubevirtClusters, each only applied when the cluster does not configure it.
+	// +optional
+	Addons *AddonSet `json:"addons,omitempty"`
+}
+
+// ProviderConfigOverride overrides the defaults in the namespaces it selects.
+type ProviderConfigOverride struct {
+	// NamespaceSelector selects the namespaces of the override by their labels.
+	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector"`
+
+	// Defaults replace, field by field, the defaults of the KubevirtProviderConfig and of the previous overrides.
+	Defaults ProviderDefaults `json:"defaults"`
+}
+
+// KubevirtProviderConfigSpec defines the desired state of KubevirtProviderConfig.
+type KubevirtProviderConfigSpec struct {
+	// Defaults are merged into the new KubevirtClusters and KubevirtMachines of all the namespaces.
+	// +optional
+	Defaults ProviderDefaults `json:"defaults,omitempty"`
+
+	// Overrides override the defaults in some namespaces, applied in order, so that the last matching override
+	// wins.
+	// +optional
+	Overrides []ProviderConfigOverride `json:"overrides,omitempty"`
+}
+
+// +kubebuilder:resource:path=kubevirtproviderconfigs,scope=Cluster,categories=cluster-api
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:validation:XValidation:rule="self.metadata.name == 'default'", message="the KubevirtProviderConfig must be named default"
+
+// KubevirtProviderConfig is the Schema for the kubevirtproviderconfigs API. It holds the defaults of the
+// organization, e.g. storage class, placement or addons, merged into every new KubevirtCluster and KubevirtMachine,
+// so that the tenants get compliant clusters without repeating them.
+type KubevirtProviderConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec KubevirtProviderConfigSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KubevirtProviderConfigList contains a list of KubevirtProviderConfig.
+type KubevirtProviderConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KubevirtProviderConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KubevirtProviderConfig{}, &KubevirtProviderConfigList{})
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RemediationAction is an action taken on the VM of an unhealthy machine.
+type RemediationAction string
+
+const (
+	// RestartRemediationAction restarts the VM gracefully.
+	RestartRemediationAction RemediationAction = "Restart"
+	// ResetRemediationAction stops the VM immediately, without shutting down its guest, and starts it again.
+	ResetRemediationAction RemediationAction = "Reset"
+	// MigrateThenRestartRemediationAction live migrates the VM away from its infra node, then restarts it.
+	MigrateThenRestartRemediationAction RemediationAction = "MigrateThenRestart"
+)
+
+// RemediationPhase is the progress of the current step of a remediation.
+type RemediationPhase string
+
+const (
+	// MigratingRemediationPhase is a step waiting for the migration of the VM to complete.
+	MigratingRemediationPhase RemediationPhase = "Migrating"
+	// WaitingRemediationPhase is a step waiting for the machine to become healthy after its action.
+	WaitingRemediationPhase RemediationPhase = "Waiting"
+	// ReplacingRemediationPhase is a remediation that ran out of steps and requested the replacement of the machine.
+	ReplacingRemediationPhase RemediationPhase = "Replacing"
+)
+
+// DefaultRemediationStepTimeout is the time given to a machine to become healthy after a remediation action.
+const DefaultRemediationStepTimeout = 5 * time.Minute
+
+// RemediationStep is an action of the remediation, and the time given to the machine to recover from it.
+type RemediationStep struct {
+	// Action is the action taken on the VM of the machine.
+	// +kubebuilder:validation:Enum=Restart;Reset;MigrateThenRestart
+	Action RemediationAction `json:"action"`
+
+	// Timeout is the time given to the machine to become healthy after the action, before the next step.
+	// Defaults to 5m.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// KubevirtRemediationSpec defines the desired state of KubevirtRemediation.
+type KubevirtRemediationSpec struct {
+	// Steps are the actions tried in order on the VM of the unhealthy machine. When the machine is still unhealthy
+	// after the last one, it is replaced. Defaults to a single Restart.
+	// +optional
+	Steps []RemediationStep `json:"steps,omitempty"`
+}
+
+// KubevirtRemediationStatus defines the observed state of KubevirtRemediation.
+type KubevirtRemediationStatus struct {
+	// Step is the index of the current step.
+	// +optional
+	Step int32 `json:"step,omitempty"`
+
+	// Phase is the progress of the current step. It is empty before the action of the step is taken.
+	// +optional
+	Phase RemediationPhase `json:"phase,omitempty"`
+
+	// StepStartTime is the time the action of the current step was taken.
+	// +optional
+	StepStartTime *metav1.Time `json:"stepStartTime,omitempty"`
+
+	// MigrationName is the name of the VirtualMachineInstanceMigration of the current step, if any.
+	// +optional
+	MigrationName string `json:"migrationName,omitempty"`
+}
+
+// +kubebuilder:resource:path=kubevirtremediations,scope=Namespaced,categories=cluster-api
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="Step",type="integer",JSONPath=".status.step",description="Index of the current step"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="Progress of the current step"
+
+// KubevirtRemediation is the Schema for the kubevirtremediations API. It is created by a MachineHealthCheck from
+// a KubevirtRemediationTemplate, named after the unhealthy Machine.
+type KubevirtRemediation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KubevirtRemediationSpec   `json:"spec,omitempty"`
+	Status KubevirtRemediationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KubevirtRemediationList contains a list of KubevirtRemediation.
+type KubevirtRemediationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KubevirtRemediation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KubevirtRemediation{}, &KubevirtRemediationList{})
+}
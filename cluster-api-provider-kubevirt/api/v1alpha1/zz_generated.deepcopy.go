@@ -22,7 +22,9 @@ package v1alpha1
 
 import (
 	"k8s.io/api/core/v1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	corev1 "kubevirt.io/api/core/v1"
 	"sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/errors"
 )
@@ -42,6 +44,162 @@ func (in *APIEndpoint) DeepCopy() *APIEndpoint {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonSet) DeepCopyInto(out *AddonSet) {
+	*out = *in
+	if in.CloudControllerManager != nil {
+		in, out := &in.CloudControllerManager, &out.CloudControllerManager
+		*out = new(CloudControllerManagerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CSIDriver != nil {
+		in, out := &in.CSIDriver, &out.CSIDriver
+		*out = new(CSIDriverSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonSet.
+func (in *AddonSet) DeepCopy() *AddonSet {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AssignedGPU) DeepCopyInto(out *AssignedGPU) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AssignedGPU.
+func (in *AssignedGPU) DeepCopy() *AssignedGPU {
+	if in == nil {
+		return nil
+	}
+	out := new(AssignedGPU)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BatchedVMCreationSpec) DeepCopyInto(out *BatchedVMCreationSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BatchedVMCreationSpec.
+func (in *BatchedVMCreationSpec) DeepCopy() *BatchedVMCreationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BatchedVMCreationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootstrapDiagnosis) DeepCopyInto(out *BootstrapDiagnosis) {
+	*out = *in
+	in.CheckTime.DeepCopyInto(&out.CheckTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootstrapDiagnosis.
+func (in *BootstrapDiagnosis) DeepCopy() *BootstrapDiagnosis {
+	if in == nil {
+		return nil
+	}
+	out := new(BootstrapDiagnosis)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootstrapFailuresSpec) DeepCopyInto(out *BootstrapFailuresSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootstrapFailuresSpec.
+func (in *BootstrapFailuresSpec) DeepCopy() *BootstrapFailuresSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BootstrapFailuresSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CSIDriverSpec) DeepCopyInto(out *CSIDriverSpec) {
+	*out = *in
+	if in.StorageClasses != nil {
+		in, out := &in.StorageClasses, &out.StorageClasses
+		*out = make([]StorageClassMapping, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CSIDriverSpec.
+func (in *CSIDriverSpec) DeepCopy() *CSIDriverSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CSIDriverSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudControllerManagerSpec) DeepCopyInto(out *CloudControllerManagerSpec) {
+	*out = *in
+	if in.LoadBalancer != nil {
+		in, out := &in.LoadBalancer, &out.LoadBalancer
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudControllerManagerSpec.
+func (in *CloudControllerManagerSpec) DeepCopy() *CloudControllerManagerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudControllerManagerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterQuota) DeepCopyInto(out *ClusterQuota) {
+	*out = *in
+	if in.MaxMachines != nil {
+		in, out := &in.MaxMachines, &out.MaxMachines
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxTotalCPU != nil {
+		in, out := &in.MaxTotalCPU, &out.MaxTotalCPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MaxTotalMemory != nil {
+		in, out := &in.MaxTotalMemory, &out.MaxTotalMemory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterQuota.
+func (in *ClusterQuota) DeepCopy() *ClusterQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ControlPlaneServiceTemplate) DeepCopyInto(out *ControlPlaneServiceTemplate) {
 	*out = *in
@@ -60,488 +218,2672 @@ func (in *ControlPlaneServiceTemplate) DeepCopy() *ControlPlaneServiceTemplate {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KubevirtCluster) DeepCopyInto(out *KubevirtCluster) {
+func (in *DiskEncryptionSpec) DeepCopyInto(out *DiskEncryptionSpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.StorageClasses != nil {
+		in, out := &in.StorageClasses, &out.StorageClasses
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtCluster.
-func (in *KubevirtCluster) DeepCopy() *KubevirtCluster {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskEncryptionSpec.
+func (in *DiskEncryptionSpec) DeepCopy() *DiskEncryptionSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(KubevirtCluster)
+	out := new(DiskEncryptionSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *KubevirtCluster) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskEncryptionStatus) DeepCopyInto(out *DiskEncryptionStatus) {
+	*out = *in
+	if in.UnencryptedDisks != nil {
+		in, out := &in.UnencryptedDisks, &out.UnencryptedDisks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	return nil
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskEncryptionStatus.
+func (in *DiskEncryptionStatus) DeepCopy() *DiskEncryptionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskEncryptionStatus)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KubevirtClusterList) DeepCopyInto(out *KubevirtClusterList) {
+func (in *DiskExportStatus) DeepCopyInto(out *DiskExportStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]KubevirtCluster, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]ExportedVolume, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExpirationTime != nil {
+		in, out := &in.ExpirationTime, &out.ExpirationTime
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtClusterList.
-func (in *KubevirtClusterList) DeepCopy() *KubevirtClusterList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskExportStatus.
+func (in *DiskExportStatus) DeepCopy() *DiskExportStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(KubevirtClusterList)
+	out := new(DiskExportStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *KubevirtClusterList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskTuning) DeepCopyInto(out *DiskTuning) {
+	*out = *in
+	if in.DedicatedIOThread != nil {
+		in, out := &in.DedicatedIOThread, &out.DedicatedIOThread
+		*out = new(bool)
+		**out = **in
 	}
-	return nil
+	if in.Discard != nil {
+		in, out := &in.Discard, &out.Discard
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskTuning.
+func (in *DiskTuning) DeepCopy() *DiskTuning {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskTuning)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KubevirtClusterSpec) DeepCopyInto(out *KubevirtClusterSpec) {
+func (in *EndpointPublisherSpec) DeepCopyInto(out *EndpointPublisherSpec) {
 	*out = *in
-	out.ControlPlaneEndpoint = in.ControlPlaneEndpoint
-	in.ControlPlaneServiceTemplate.DeepCopyInto(&out.ControlPlaneServiceTemplate)
-	in.SshKeys.DeepCopyInto(&out.SshKeys)
-	if in.InfraClusterSecretRef != nil {
-		in, out := &in.InfraClusterSecretRef, &out.InfraClusterSecretRef
-		*out = new(v1.ObjectReference)
+	if in.KubeVIP != nil {
+		in, out := &in.KubeVIP, &out.KubeVIP
+		*out = new(KubeVIPPublisherSpec)
 		**out = **in
 	}
+	if in.ExternalLoadBalancer != nil {
+		in, out := &in.ExternalLoadBalancer, &out.ExternalLoadBalancer
+		*out = new(ExternalLoadBalancerPublisherSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtClusterSpec.
-func (in *KubevirtClusterSpec) DeepCopy() *KubevirtClusterSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointPublisherSpec.
+func (in *EndpointPublisherSpec) DeepCopy() *EndpointPublisherSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointPublisherSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecCredentialPlugin) DeepCopyInto(out *ExecCredentialPlugin) {
+	*out = *in
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]ExecEnvVar, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecCredentialPlugin.
+func (in *ExecCredentialPlugin) DeepCopy() *ExecCredentialPlugin {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecCredentialPlugin)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecEnvVar) DeepCopyInto(out *ExecEnvVar) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecEnvVar.
+func (in *ExecEnvVar) DeepCopy() *ExecEnvVar {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecEnvVar)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExportedVolume) DeepCopyInto(out *ExportedVolume) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExportedVolume.
+func (in *ExportedVolume) DeepCopy() *ExportedVolume {
+	if in == nil {
+		return nil
+	}
+	out := new(ExportedVolume)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalControlPlaneEndpointSpec) DeepCopyInto(out *ExternalControlPlaneEndpointSpec) {
+	*out = *in
+	in.ServiceTemplate.DeepCopyInto(&out.ServiceTemplate)
+	if in.CertSANs != nil {
+		in, out := &in.CertSANs, &out.CertSANs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalControlPlaneEndpointSpec.
+func (in *ExternalControlPlaneEndpointSpec) DeepCopy() *ExternalControlPlaneEndpointSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalControlPlaneEndpointSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalLoadBalancerPublisherSpec) DeepCopyInto(out *ExternalLoadBalancerPublisherSpec) {
+	*out = *in
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalLoadBalancerPublisherSpec.
+func (in *ExternalLoadBalancerPublisherSpec) DeepCopy() *ExternalLoadBalancerPublisherSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalLoadBalancerPublisherSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FloatingIPSpec) DeepCopyInto(out *FloatingIPSpec) {
+	*out = *in
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FloatingIPSpec.
+func (in *FloatingIPSpec) DeepCopy() *FloatingIPSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FloatingIPSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GPUStatus) DeepCopyInto(out *GPUStatus) {
+	*out = *in
+	if in.Devices != nil {
+		in, out := &in.Devices, &out.Devices
+		*out = make([]AssignedGPU, len(*in))
+		copy(*out, *in)
+	}
+	in.CheckTime.DeepCopyInto(&out.CheckTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GPUStatus.
+func (in *GPUStatus) DeepCopy() *GPUStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GPUStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GuestAgentSpec) DeepCopyInto(out *GuestAgentSpec) {
+	*out = *in
+	if in.ConnectTimeout != nil {
+		in, out := &in.ConnectTimeout, &out.ConnectTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.SerialConsole != nil {
+		in, out := &in.SerialConsole, &out.SerialConsole
+		*out = new(bool)
+		**out = **in
+	}
+	if in.VSOCK != nil {
+		in, out := &in.VSOCK, &out.VSOCK
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GuestAgentSpec.
+func (in *GuestAgentSpec) DeepCopy() *GuestAgentSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GuestAgentSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GuestOSInfo) DeepCopyInto(out *GuestOSInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GuestOSInfo.
+func (in *GuestOSInfo) DeepCopy() *GuestOSInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(GuestOSInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HibernationSchedule) DeepCopyInto(out *HibernationSchedule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HibernationSchedule.
+func (in *HibernationSchedule) DeepCopy() *HibernationSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(HibernationSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageChannelRollout) DeepCopyInto(out *ImageChannelRollout) {
+	*out = *in
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]MaintenanceWindow, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageChannelRollout.
+func (in *ImageChannelRollout) DeepCopy() *ImageChannelRollout {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageChannelRollout)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImagePrewarmingSpec) DeepCopyInto(out *ImagePrewarmingSpec) {
+	*out = *in
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePrewarmingSpec.
+func (in *ImagePrewarmingSpec) DeepCopy() *ImagePrewarmingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ImagePrewarmingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfraHostDevice) DeepCopyInto(out *InfraHostDevice) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfraHostDevice.
+func (in *InfraHostDevice) DeepCopy() *InfraHostDevice {
+	if in == nil {
+		return nil
+	}
+	out := new(InfraHostDevice)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfraNodePort) DeepCopyInto(out *InfraNodePort) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfraNodePort.
+func (in *InfraNodePort) DeepCopy() *InfraNodePort {
+	if in == nil {
+		return nil
+	}
+	out := new(InfraNodePort)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfraOwnershipLeaseSpec) DeepCopyInto(out *InfraOwnershipLeaseSpec) {
+	*out = *in
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfraOwnershipLeaseSpec.
+func (in *InfraOwnershipLeaseSpec) DeepCopy() *InfraOwnershipLeaseSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InfraOwnershipLeaseSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfraStatus) DeepCopyInto(out *InfraStatus) {
+	*out = *in
+	if in.KubeVirtFeatureGates != nil {
+		in, out := &in.KubeVirtFeatureGates, &out.KubeVirtFeatureGates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CDIFeatureGates != nil {
+		in, out := &in.CDIFeatureGates, &out.CDIFeatureGates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeArchitectures != nil {
+		in, out := &in.NodeArchitectures, &out.NodeArchitectures
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeZones != nil {
+		in, out := &in.NodeZones, &out.NodeZones
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.HostDevices != nil {
+		in, out := &in.HostDevices, &out.HostDevices
+		*out = make([]InfraHostDevice, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfraStatus.
+func (in *InfraStatus) DeepCopy() *InfraStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InfraStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeVIPPublisherSpec) DeepCopyInto(out *KubeVIPPublisherSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeVIPPublisherSpec.
+func (in *KubeVIPPublisherSpec) DeepCopy() *KubeVIPPublisherSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeVIPPublisherSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtCluster) DeepCopyInto(out *KubevirtCluster) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtCluster.
+func (in *KubevirtCluster) DeepCopy() *KubevirtCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KubevirtCluster) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtClusterList) DeepCopyInto(out *KubevirtClusterList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KubevirtCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtClusterList.
+func (in *KubevirtClusterList) DeepCopy() *KubevirtClusterList {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtClusterList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KubevirtClusterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtClusterSpec) DeepCopyInto(out *KubevirtClusterSpec) {
+	*out = *in
+	out.ControlPlaneEndpoint = in.ControlPlaneEndpoint
+	in.ControlPlaneServiceTemplate.DeepCopyInto(&out.ControlPlaneServiceTemplate)
+	if in.ControlPlaneEndpointPublisher != nil {
+		in, out := &in.ControlPlaneEndpointPublisher, &out.ControlPlaneEndpointPublisher
+		*out = new(EndpointPublisherSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ControlPlaneFloatingIP != nil {
+		in, out := &in.ControlPlaneFloatingIP, &out.ControlPlaneFloatingIP
+		*out = new(FloatingIPSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	in.SshKeys.DeepCopyInto(&out.SshKeys)
+	if in.InfraClusterSecretRef != nil {
+		in, out := &in.InfraClusterSecretRef, &out.InfraClusterSecretRef
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.HibernationSchedules != nil {
+		in, out := &in.HibernationSchedules, &out.HibernationSchedules
+		*out = make([]HibernationSchedule, len(*in))
+		copy(*out, *in)
+	}
+	if in.SmokeTest != nil {
+		in, out := &in.SmokeTest, &out.SmokeTest
+		*out = new(SmokeTestSpec)
+		**out = **in
+	}
+	if in.WorkloadDNSProbe != nil {
+		in, out := &in.WorkloadDNSProbe, &out.WorkloadDNSProbe
+		*out = new(WorkloadDNSProbeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(ProxySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RegistryMirrors != nil {
+		in, out := &in.RegistryMirrors, &out.RegistryMirrors
+		*out = make([]RegistryMirror, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MachineIdentity != nil {
+		in, out := &in.MachineIdentity, &out.MachineIdentity
+		*out = new(MachineIdentitySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InfraOwnershipLease != nil {
+		in, out := &in.InfraOwnershipLease, &out.InfraOwnershipLease
+		*out = new(InfraOwnershipLeaseSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExternalControlPlaneEndpoint != nil {
+		in, out := &in.ExternalControlPlaneEndpoint, &out.ExternalControlPlaneEndpoint
+		*out = new(ExternalControlPlaneEndpointSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TenantLoadBalancer != nil {
+		in, out := &in.TenantLoadBalancer, &out.TenantLoadBalancer
+		*out = new(TenantLoadBalancerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CloudControllerManager != nil {
+		in, out := &in.CloudControllerManager, &out.CloudControllerManager
+		*out = new(CloudControllerManagerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TenantNetwork != nil {
+		in, out := &in.TenantNetwork, &out.TenantNetwork
+		*out = new(TenantNetworkSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CSIDriver != nil {
+		in, out := &in.CSIDriver, &out.CSIDriver
+		*out = new(CSIDriverSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePrewarming != nil {
+		in, out := &in.ImagePrewarming, &out.ImagePrewarming
+		*out = new(ImagePrewarmingSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UpgradePreflight != nil {
+		in, out := &in.UpgradePreflight, &out.UpgradePreflight
+		*out = new(UpgradePreflightSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MemoryOvercommit != nil {
+		in, out := &in.MemoryOvercommit, &out.MemoryOvercommit
+		*out = new(MemoryOvercommitSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MigrationPolicy != nil {
+		in, out := &in.MigrationPolicy, &out.MigrationPolicy
+		*out = new(MigrationPolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServiceMesh != nil {
+		in, out := &in.ServiceMesh, &out.ServiceMesh
+		*out = new(ServiceMeshSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WorkloadKubeconfig != nil {
+		in, out := &in.WorkloadKubeconfig, &out.WorkloadKubeconfig
+		*out = new(WorkloadKubeconfigSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OIDCKubeconfig != nil {
+		in, out := &in.OIDCKubeconfig, &out.OIDCKubeconfig
+		*out = new(OIDCKubeconfigSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DiskEncryption != nil {
+		in, out := &in.DiskEncryption, &out.DiskEncryption
+		*out = new(DiskEncryptionSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MetadataPropagation != nil {
+		in, out := &in.MetadataPropagation, &out.MetadataPropagation
+		*out = new(MetadataPropagationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VMDrift != nil {
+		in, out := &in.VMDrift, &out.VMDrift
+		*out = new(VMDriftSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BatchedVMCreation != nil {
+		in, out := &in.BatchedVMCreation, &out.BatchedVMCreation
+		*out = new(BatchedVMCreationSpec)
+		**out = **in
+	}
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = new(ClusterQuota)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtClusterSpec.
+func (in *KubevirtClusterSpec) DeepCopy() *KubevirtClusterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtClusterStatus) DeepCopyInto(out *KubevirtClusterStatus) {
+	*out = *in
+	if in.FailureDomains != nil {
+		in, out := &in.FailureDomains, &out.FailureDomains
+		*out = make(v1beta1.FailureDomains, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(v1beta1.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NextHibernationTransition != nil {
+		in, out := &in.NextHibernationTransition, &out.NextHibernationTransition
+		*out = (*in).DeepCopy()
+	}
+	if in.MaintenanceEndTime != nil {
+		in, out := &in.MaintenanceEndTime, &out.MaintenanceEndTime
+		*out = (*in).DeepCopy()
+	}
+	if in.PendingReboots != nil {
+		in, out := &in.PendingReboots, &out.PendingReboots
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastRebootCheckTime != nil {
+		in, out := &in.LastRebootCheckTime, &out.LastRebootCheckTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NestedVirtualization != nil {
+		in, out := &in.NestedVirtualization, &out.NestedVirtualization
+		*out = new(NestedVirtualizationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Infra != nil {
+		in, out := &in.Infra, &out.Infra
+		*out = new(InfraStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProviderInfo != nil {
+		in, out := &in.ProviderInfo, &out.ProviderInfo
+		*out = new(ProviderInfo)
+		**out = **in
+	}
+	if in.InternalControlPlaneEndpoint != nil {
+		in, out := &in.InternalControlPlaneEndpoint, &out.InternalControlPlaneEndpoint
+		*out = new(APIEndpoint)
+		**out = **in
+	}
+	if in.ExternalControlPlaneEndpoint != nil {
+		in, out := &in.ExternalControlPlaneEndpoint, &out.ExternalControlPlaneEndpoint
+		*out = new(APIEndpoint)
+		**out = **in
+	}
+	if in.InfraNodePorts != nil {
+		in, out := &in.InfraNodePorts, &out.InfraNodePorts
+		*out = make([]InfraNodePort, len(*in))
+		copy(*out, *in)
+	}
+	if in.OrphanedVolumes != nil {
+		in, out := &in.OrphanedVolumes, &out.OrphanedVolumes
+		*out = make([]OrphanedVolume, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResourceUsage != nil {
+		in, out := &in.ResourceUsage, &out.ResourceUsage
+		*out = new(ResourceUsage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UpgradePreflight != nil {
+		in, out := &in.UpgradePreflight, &out.UpgradePreflight
+		*out = new(UpgradePreflightStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DiskEncryption != nil {
+		in, out := &in.DiskEncryption, &out.DiskEncryption
+		*out = new(DiskEncryptionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WorkloadDNSProbeTime != nil {
+		in, out := &in.WorkloadDNSProbeTime, &out.WorkloadDNSProbeTime
+		*out = (*in).DeepCopy()
+	}
+	if in.V1Beta2 != nil {
+		in, out := &in.V1Beta2, &out.V1Beta2
+		*out = new(KubevirtClusterV1Beta2Status)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtClusterStatus.
+func (in *KubevirtClusterStatus) DeepCopy() *KubevirtClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtClusterTemplate) DeepCopyInto(out *KubevirtClusterTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtClusterTemplate.
+func (in *KubevirtClusterTemplate) DeepCopy() *KubevirtClusterTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtClusterTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KubevirtClusterTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtClusterTemplateList) DeepCopyInto(out *KubevirtClusterTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KubevirtClusterTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtClusterTemplateList.
+func (in *KubevirtClusterTemplateList) DeepCopy() *KubevirtClusterTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtClusterTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KubevirtClusterTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtClusterTemplateResource) DeepCopyInto(out *KubevirtClusterTemplateResource) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtClusterTemplateResource.
+func (in *KubevirtClusterTemplateResource) DeepCopy() *KubevirtClusterTemplateResource {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtClusterTemplateResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtClusterTemplateSpec) DeepCopyInto(out *KubevirtClusterTemplateSpec) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtClusterTemplateSpec.
+func (in *KubevirtClusterTemplateSpec) DeepCopy() *KubevirtClusterTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtClusterTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtClusterV1Beta2Status) DeepCopyInto(out *KubevirtClusterV1Beta2Status) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtClusterV1Beta2Status.
+func (in *KubevirtClusterV1Beta2Status) DeepCopy() *KubevirtClusterV1Beta2Status {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtClusterV1Beta2Status)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtMachine) DeepCopyInto(out *KubevirtMachine) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtMachine.
+func (in *KubevirtMachine) DeepCopy() *KubevirtMachine {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtMachine)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KubevirtMachine) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtMachineImage) DeepCopyInto(out *KubevirtMachineImage) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtMachineImage.
+func (in *KubevirtMachineImage) DeepCopy() *KubevirtMachineImage {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtMachineImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KubevirtMachineImage) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtMachineImageChannel) DeepCopyInto(out *KubevirtMachineImageChannel) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtMachineImageChannel.
+func (in *KubevirtMachineImageChannel) DeepCopy() *KubevirtMachineImageChannel {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtMachineImageChannel)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KubevirtMachineImageChannel) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtMachineImageChannelList) DeepCopyInto(out *KubevirtMachineImageChannelList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KubevirtMachineImageChannel, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtMachineImageChannelList.
+func (in *KubevirtMachineImageChannelList) DeepCopy() *KubevirtMachineImageChannelList {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtMachineImageChannelList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KubevirtMachineImageChannelList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtMachineImageChannelSpec) DeepCopyInto(out *KubevirtMachineImageChannelSpec) {
+	*out = *in
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(ImageChannelRollout)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtMachineImageChannelSpec.
+func (in *KubevirtMachineImageChannelSpec) DeepCopy() *KubevirtMachineImageChannelSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtMachineImageChannelSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtMachineImageChannelStatus) DeepCopyInto(out *KubevirtMachineImageChannelStatus) {
+	*out = *in
+	if in.PublishedTime != nil {
+		in, out := &in.PublishedTime, &out.PublishedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.RolledOutMachineDeployments != nil {
+		in, out := &in.RolledOutMachineDeployments, &out.RolledOutMachineDeployments
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtMachineImageChannelStatus.
+func (in *KubevirtMachineImageChannelStatus) DeepCopy() *KubevirtMachineImageChannelStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtMachineImageChannelStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtMachineImageList) DeepCopyInto(out *KubevirtMachineImageList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KubevirtMachineImage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtMachineImageList.
+func (in *KubevirtMachineImageList) DeepCopy() *KubevirtMachineImageList {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtMachineImageList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KubevirtMachineImageList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtMachineImageSpec) DeepCopyInto(out *KubevirtMachineImageSpec) {
+	*out = *in
+	out.Source = in.Source
+	if in.Variants != nil {
+		in, out := &in.Variants, &out.Variants
+		*out = make([]MachineImageVariant, len(*in))
+		copy(*out, *in)
+	}
+	out.Size = in.Size.DeepCopy()
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.SharedWith != nil {
+		in, out := &in.SharedWith, &out.SharedWith
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtMachineImageSpec.
+func (in *KubevirtMachineImageSpec) DeepCopy() *KubevirtMachineImageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtMachineImageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtMachineImageStatus) DeepCopyInto(out *KubevirtMachineImageStatus) {
+	*out = *in
+	if in.Imports != nil {
+		in, out := &in.Imports, &out.Imports
+		*out = make([]MachineImageImport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtMachineImageStatus.
+func (in *KubevirtMachineImageStatus) DeepCopy() *KubevirtMachineImageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtMachineImageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtMachineList) DeepCopyInto(out *KubevirtMachineList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KubevirtMachine, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtMachineList.
+func (in *KubevirtMachineList) DeepCopy() *KubevirtMachineList {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtMachineList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KubevirtMachineList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtMachineSpec) DeepCopyInto(out *KubevirtMachineSpec) {
+	*out = *in
+	in.VirtualMachineTemplate.DeepCopyInto(&out.VirtualMachineTemplate)
+	if in.ProviderID != nil {
+		in, out := &in.ProviderID, &out.ProviderID
+		*out = new(string)
+		**out = **in
+	}
+	out.BootstrapCheckSpec = in.BootstrapCheckSpec
+	if in.InfraClusterSecretRef != nil {
+		in, out := &in.InfraClusterSecretRef, &out.InfraClusterSecretRef
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.InternalAddress != nil {
+		in, out := &in.InternalAddress, &out.InternalAddress
+		*out = new(NetworkAddressRule)
+		**out = **in
+	}
+	if in.ExternalAddress != nil {
+		in, out := &in.ExternalAddress, &out.ExternalAddress
+		*out = new(NetworkAddressRule)
+		**out = **in
+	}
+	if in.ProvisioningTimeouts != nil {
+		in, out := &in.ProvisioningTimeouts, &out.ProvisioningTimeouts
+		*out = new(ProvisioningTimeouts)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BootstrapFailures != nil {
+		in, out := &in.BootstrapFailures, &out.BootstrapFailures
+		*out = new(BootstrapFailuresSpec)
+		**out = **in
+	}
+	if in.Preemptible != nil {
+		in, out := &in.Preemptible, &out.Preemptible
+		*out = new(PreemptibleSpec)
+		**out = **in
+	}
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(MachineImageReference)
+		**out = **in
+	}
+	if in.IOThreadsPolicy != nil {
+		in, out := &in.IOThreadsPolicy, &out.IOThreadsPolicy
+		*out = new(corev1.IOThreadsPolicy)
+		**out = **in
+	}
+	if in.Disks != nil {
+		in, out := &in.Disks, &out.Disks
+		*out = make([]DiskTuning, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MigrationPolicy != nil {
+		in, out := &in.MigrationPolicy, &out.MigrationPolicy
+		*out = new(MigrationPolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GPUs != nil {
+		in, out := &in.GPUs, &out.GPUs
+		*out = make([]MachineGPU, len(*in))
+		copy(*out, *in)
+	}
+	if in.GuestAgent != nil {
+		in, out := &in.GuestAgent, &out.GuestAgent
+		*out = new(GuestAgentSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtMachineSpec.
+func (in *KubevirtMachineSpec) DeepCopy() *KubevirtMachineSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtMachineSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtMachineStatus) DeepCopyInto(out *KubevirtMachineStatus) {
+	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]v1beta1.MachineAddress, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(v1beta1.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.FailureReason != nil {
+		in, out := &in.FailureReason, &out.FailureReason
+		*out = new(errors.MachineStatusError)
+		**out = **in
+	}
+	if in.FailureMessage != nil {
+		in, out := &in.FailureMessage, &out.FailureMessage
+		*out = new(string)
+		**out = **in
+	}
+	if in.VirtualMachineInstance != nil {
+		in, out := &in.VirtualMachineInstance, &out.VirtualMachineInstance
+		*out = new(VirtualMachineInstanceInfo)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Node != nil {
+		in, out := &in.Node, &out.Node
+		*out = new(NodeInfo)
+		**out = **in
+	}
+	if in.ProvisioningPhaseStartTime != nil {
+		in, out := &in.ProvisioningPhaseStartTime, &out.ProvisioningPhaseStartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.BootstrapDiagnosis != nil {
+		in, out := &in.BootstrapDiagnosis, &out.BootstrapDiagnosis
+		*out = new(BootstrapDiagnosis)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BootstrapTokenExpiryTime != nil {
+		in, out := &in.BootstrapTokenExpiryTime, &out.BootstrapTokenExpiryTime
+		*out = (*in).DeepCopy()
+	}
+	if in.DiskExport != nil {
+		in, out := &in.DiskExport, &out.DiskExport
+		*out = new(DiskExportStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VMDriftCheckTime != nil {
+		in, out := &in.VMDriftCheckTime, &out.VMDriftCheckTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Operations != nil {
+		in, out := &in.Operations, &out.Operations
+		*out = make([]MachineOperation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.GPUs != nil {
+		in, out := &in.GPUs, &out.GPUs
+		*out = new(GPUStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.V1Beta2 != nil {
+		in, out := &in.V1Beta2, &out.V1Beta2
+		*out = new(KubevirtMachineV1Beta2Status)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtMachineStatus.
+func (in *KubevirtMachineStatus) DeepCopy() *KubevirtMachineStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtMachineStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtMachineTemplate) DeepCopyInto(out *KubevirtMachineTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtMachineTemplate.
+func (in *KubevirtMachineTemplate) DeepCopy() *KubevirtMachineTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtMachineTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KubevirtMachineTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtMachineTemplateList) DeepCopyInto(out *KubevirtMachineTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KubevirtMachineTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtMachineTemplateList.
+func (in *KubevirtMachineTemplateList) DeepCopy() *KubevirtMachineTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtMachineTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KubevirtMachineTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtMachineTemplateResource) DeepCopyInto(out *KubevirtMachineTemplateResource) {
+	*out = *in
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtMachineTemplateResource.
+func (in *KubevirtMachineTemplateResource) DeepCopy() *KubevirtMachineTemplateResource {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtMachineTemplateResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtMachineTemplateSpec) DeepCopyInto(out *KubevirtMachineTemplateSpec) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtMachineTemplateSpec.
+func (in *KubevirtMachineTemplateSpec) DeepCopy() *KubevirtMachineTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtMachineTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtMachineTemplateStatus) DeepCopyInto(out *KubevirtMachineTemplateStatus) {
+	*out = *in
+	if in.NodeInfo != nil {
+		in, out := &in.NodeInfo, &out.NodeInfo
+		*out = new(TemplateNodeInfo)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtMachineTemplateStatus.
+func (in *KubevirtMachineTemplateStatus) DeepCopy() *KubevirtMachineTemplateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtMachineTemplateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtMachineV1Beta2Status) DeepCopyInto(out *KubevirtMachineV1Beta2Status) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtMachineV1Beta2Status.
+func (in *KubevirtMachineV1Beta2Status) DeepCopy() *KubevirtMachineV1Beta2Status {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtMachineV1Beta2Status)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtProviderConfig) DeepCopyInto(out *KubevirtProviderConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtProviderConfig.
+func (in *KubevirtProviderConfig) DeepCopy() *KubevirtProviderConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtProviderConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KubevirtProviderConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtProviderConfigList) DeepCopyInto(out *KubevirtProviderConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KubevirtProviderConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtProviderConfigList.
+func (in *KubevirtProviderConfigList) DeepCopy() *KubevirtProviderConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtProviderConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KubevirtProviderConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtProviderConfigSpec) DeepCopyInto(out *KubevirtProviderConfigSpec) {
+	*out = *in
+	in.Defaults.DeepCopyInto(&out.Defaults)
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = make([]ProviderConfigOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtProviderConfigSpec.
+func (in *KubevirtProviderConfigSpec) DeepCopy() *KubevirtProviderConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtProviderConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtRemediation) DeepCopyInto(out *KubevirtRemediation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtRemediation.
+func (in *KubevirtRemediation) DeepCopy() *KubevirtRemediation {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtRemediation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KubevirtRemediation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtRemediationList) DeepCopyInto(out *KubevirtRemediationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KubevirtRemediation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtRemediationList.
+func (in *KubevirtRemediationList) DeepCopy() *KubevirtRemediationList {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtRemediationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KubevirtRemediationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtRemediationSpec) DeepCopyInto(out *KubevirtRemediationSpec) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]RemediationStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtRemediationSpec.
+func (in *KubevirtRemediationSpec) DeepCopy() *KubevirtRemediationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtRemediationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtRemediationStatus) DeepCopyInto(out *KubevirtRemediationStatus) {
+	*out = *in
+	if in.StepStartTime != nil {
+		in, out := &in.StepStartTime, &out.StepStartTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtRemediationStatus.
+func (in *KubevirtRemediationStatus) DeepCopy() *KubevirtRemediationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtRemediationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtRemediationTemplate) DeepCopyInto(out *KubevirtRemediationTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtRemediationTemplate.
+func (in *KubevirtRemediationTemplate) DeepCopy() *KubevirtRemediationTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtRemediationTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KubevirtRemediationTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtRemediationTemplateList) DeepCopyInto(out *KubevirtRemediationTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KubevirtRemediationTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtRemediationTemplateList.
+func (in *KubevirtRemediationTemplateList) DeepCopy() *KubevirtRemediationTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtRemediationTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KubevirtRemediationTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtRemediationTemplateResource) DeepCopyInto(out *KubevirtRemediationTemplateResource) {
+	*out = *in
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtRemediationTemplateResource.
+func (in *KubevirtRemediationTemplateResource) DeepCopy() *KubevirtRemediationTemplateResource {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtRemediationTemplateResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubevirtRemediationTemplateSpec) DeepCopyInto(out *KubevirtRemediationTemplateSpec) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtRemediationTemplateSpec.
+func (in *KubevirtRemediationTemplateSpec) DeepCopy() *KubevirtRemediationTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KubevirtRemediationTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineGPU) DeepCopyInto(out *MachineGPU) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineGPU.
+func (in *MachineGPU) DeepCopy() *MachineGPU {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineGPU)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineIdentitySpec) DeepCopyInto(out *MachineIdentitySpec) {
+	*out = *in
+	if in.Validity != nil {
+		in, out := &in.Validity, &out.Validity
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineIdentitySpec.
+func (in *MachineIdentitySpec) DeepCopy() *MachineIdentitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineIdentitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineImageImport) DeepCopyInto(out *MachineImageImport) {
+	*out = *in
+	if in.InfraClusterSecretRef != nil {
+		in, out := &in.InfraClusterSecretRef, &out.InfraClusterSecretRef
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.CloneGrants != nil {
+		in, out := &in.CloneGrants, &out.CloneGrants
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineImageImport.
+func (in *MachineImageImport) DeepCopy() *MachineImageImport {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineImageImport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineImageReference) DeepCopyInto(out *MachineImageReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineImageReference.
+func (in *MachineImageReference) DeepCopy() *MachineImageReference {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineImageReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineImageSource) DeepCopyInto(out *MachineImageSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineImageSource.
+func (in *MachineImageSource) DeepCopy() *MachineImageSource {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineImageSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineImageVariant) DeepCopyInto(out *MachineImageVariant) {
+	*out = *in
+	out.Source = in.Source
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineImageVariant.
+func (in *MachineImageVariant) DeepCopy() *MachineImageVariant {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineImageVariant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineOperation) DeepCopyInto(out *MachineOperation) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineOperation.
+func (in *MachineOperation) DeepCopy() *MachineOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MemoryOvercommitSpec) DeepCopyInto(out *MemoryOvercommitSpec) {
+	*out = *in
+	if in.Ballooning != nil {
+		in, out := &in.Ballooning, &out.Ballooning
+		*out = new(bool)
+		**out = **in
+	}
+	if in.FreePageReporting != nil {
+		in, out := &in.FreePageReporting, &out.FreePageReporting
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MemoryOvercommitSpec.
+func (in *MemoryOvercommitSpec) DeepCopy() *MemoryOvercommitSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MemoryOvercommitSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetadataPropagationSpec) DeepCopyInto(out *MetadataPropagationSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetadataPropagationSpec.
+func (in *MetadataPropagationSpec) DeepCopy() *MetadataPropagationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MetadataPropagationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationInfo) DeepCopyInto(out *MigrationInfo) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationInfo.
+func (in *MigrationInfo) DeepCopy() *MigrationInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationPolicySpec) DeepCopyInto(out *MigrationPolicySpec) {
+	*out = *in
+	if in.BandwidthPerMigration != nil {
+		in, out := &in.BandwidthPerMigration, &out.BandwidthPerMigration
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.CompletionTimeoutPerGiB != nil {
+		in, out := &in.CompletionTimeoutPerGiB, &out.CompletionTimeoutPerGiB
+		*out = new(int64)
+		**out = **in
+	}
+	if in.AllowAutoConverge != nil {
+		in, out := &in.AllowAutoConverge, &out.AllowAutoConverge
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AllowPostCopy != nil {
+		in, out := &in.AllowPostCopy, &out.AllowPostCopy
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationPolicySpec.
+func (in *MigrationPolicySpec) DeepCopy() *MigrationPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NestedVirtualizationStatus) DeepCopyInto(out *NestedVirtualizationStatus) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NestedVirtualizationStatus.
+func (in *NestedVirtualizationStatus) DeepCopy() *NestedVirtualizationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NestedVirtualizationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkAddressRule) DeepCopyInto(out *NetworkAddressRule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkAddressRule.
+func (in *NetworkAddressRule) DeepCopy() *NetworkAddressRule {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkAddressRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeInfo) DeepCopyInto(out *NodeInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeInfo.
+func (in *NodeInfo) DeepCopy() *NodeInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDCKubeconfigSpec) DeepCopyInto(out *OIDCKubeconfigSpec) {
+	*out = *in
+	if in.ExtraScopes != nil {
+		in, out := &in.ExtraScopes, &out.ExtraScopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDCKubeconfigSpec.
+func (in *OIDCKubeconfigSpec) DeepCopy() *OIDCKubeconfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDCKubeconfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OffloadProfile) DeepCopyInto(out *OffloadProfile) {
+	*out = *in
+	if in.IOThreadsPolicy != nil {
+		in, out := &in.IOThreadsPolicy, &out.IOThreadsPolicy
+		*out = new(corev1.IOThreadsPolicy)
+		**out = **in
+	}
+	if in.Disks != nil {
+		in, out := &in.Disks, &out.Disks
+		*out = make([]DiskTuning, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OffloadProfile.
+func (in *OffloadProfile) DeepCopy() *OffloadProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(OffloadProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrphanedVolume) DeepCopyInto(out *OrphanedVolume) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrphanedVolume.
+func (in *OrphanedVolume) DeepCopy() *OrphanedVolume {
+	if in == nil {
+		return nil
+	}
+	out := new(OrphanedVolume)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlacementPolicy) DeepCopyInto(out *PlacementPolicy) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlacementPolicy.
+func (in *PlacementPolicy) DeepCopy() *PlacementPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PlacementPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreemptibleSpec) DeepCopyInto(out *PreemptibleSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreemptibleSpec.
+func (in *PreemptibleSpec) DeepCopy() *PreemptibleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PreemptibleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderConfigOverride) DeepCopyInto(out *ProviderConfigOverride) {
+	*out = *in
+	in.NamespaceSelector.DeepCopyInto(&out.NamespaceSelector)
+	in.Defaults.DeepCopyInto(&out.Defaults)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderConfigOverride.
+func (in *ProviderConfigOverride) DeepCopy() *ProviderConfigOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderConfigOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderDefaults) DeepCopyInto(out *ProviderDefaults) {
+	*out = *in
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Offload != nil {
+		in, out := &in.Offload, &out.Offload
+		*out = new(OffloadProfile)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Placement != nil {
+		in, out := &in.Placement, &out.Placement
+		*out = new(PlacementPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RegistryMirrors != nil {
+		in, out := &in.RegistryMirrors, &out.RegistryMirrors
+		*out = make([]RegistryMirror, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Addons != nil {
+		in, out := &in.Addons, &out.Addons
+		*out = new(AddonSet)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderDefaults.
+func (in *ProviderDefaults) DeepCopy() *ProviderDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderInfo) DeepCopyInto(out *ProviderInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderInfo.
+func (in *ProviderInfo) DeepCopy() *ProviderInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProvisioningTimeouts) DeepCopyInto(out *ProvisioningTimeouts) {
+	*out = *in
+	if in.DataVolumeImport != nil {
+		in, out := &in.DataVolumeImport, &out.DataVolumeImport
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.VMStart != nil {
+		in, out := &in.VMStart, &out.VMStart
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Bootstrap != nil {
+		in, out := &in.Bootstrap, &out.Bootstrap
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.NodeRegistration != nil {
+		in, out := &in.NodeRegistration, &out.NodeRegistration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvisioningTimeouts.
+func (in *ProvisioningTimeouts) DeepCopy() *ProvisioningTimeouts {
+	if in == nil {
+		return nil
+	}
+	out := new(ProvisioningTimeouts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProxySpec) DeepCopyInto(out *ProxySpec) {
+	*out = *in
+	if in.NoProxy != nil {
+		in, out := &in.NoProxy, &out.NoProxy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxySpec.
+func (in *ProxySpec) DeepCopy() *ProxySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProxySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistryMirror) DeepCopyInto(out *RegistryMirror) {
+	*out = *in
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistryMirror.
+func (in *RegistryMirror) DeepCopy() *RegistryMirror {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistryMirror)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationStep) DeepCopyInto(out *RemediationStep) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationStep.
+func (in *RemediationStep) DeepCopy() *RemediationStep {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceUsage) DeepCopyInto(out *ResourceUsage) {
+	*out = *in
+	out.CPU = in.CPU.DeepCopy()
+	out.Memory = in.Memory.DeepCopy()
+	out.Storage = in.Storage.DeepCopy()
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceUsage.
+func (in *ResourceUsage) DeepCopy() *ResourceUsage {
 	if in == nil {
 		return nil
 	}
-	out := new(KubevirtClusterSpec)
+	out := new(ResourceUsage)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KubevirtClusterStatus) DeepCopyInto(out *KubevirtClusterStatus) {
+func (in *SSHKeys) DeepCopyInto(out *SSHKeys) {
 	*out = *in
-	if in.FailureDomains != nil {
-		in, out := &in.FailureDomains, &out.FailureDomains
-		*out = make(v1beta1.FailureDomains, len(*in))
-		for key, val := range *in {
-			(*out)[key] = *val.DeepCopy()
-		}
+	if in.ConfigRef != nil {
+		in, out := &in.ConfigRef, &out.ConfigRef
+		*out = new(v1.ObjectReference)
+		**out = **in
 	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make(v1beta1.Conditions, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.DataSecretName != nil {
+		in, out := &in.DataSecretName, &out.DataSecretName
+		*out = new(string)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtClusterStatus.
-func (in *KubevirtClusterStatus) DeepCopy() *KubevirtClusterStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SSHKeys.
+func (in *SSHKeys) DeepCopy() *SSHKeys {
 	if in == nil {
 		return nil
 	}
-	out := new(KubevirtClusterStatus)
+	out := new(SSHKeys)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KubevirtClusterTemplate) DeepCopyInto(out *KubevirtClusterTemplate) {
+func (in *ServiceMeshSpec) DeepCopyInto(out *ServiceMeshSpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
+	if in.PodAnnotations != nil {
+		in, out := &in.PodAnnotations, &out.PodAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ServiceAnnotations != nil {
+		in, out := &in.ServiceAnnotations, &out.ServiceAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtClusterTemplate.
-func (in *KubevirtClusterTemplate) DeepCopy() *KubevirtClusterTemplate {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceMeshSpec.
+func (in *ServiceMeshSpec) DeepCopy() *ServiceMeshSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(KubevirtClusterTemplate)
+	out := new(ServiceMeshSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *KubevirtClusterTemplate) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KubevirtClusterTemplateList) DeepCopyInto(out *KubevirtClusterTemplateList) {
+func (in *ServiceSpecTemplate) DeepCopyInto(out *ServiceSpecTemplate) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]KubevirtClusterTemplate, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtClusterTemplateList.
-func (in *KubevirtClusterTemplateList) DeepCopy() *KubevirtClusterTemplateList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceSpecTemplate.
+func (in *ServiceSpecTemplate) DeepCopy() *ServiceSpecTemplate {
 	if in == nil {
 		return nil
 	}
-	out := new(KubevirtClusterTemplateList)
+	out := new(ServiceSpecTemplate)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *KubevirtClusterTemplateList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KubevirtClusterTemplateResource) DeepCopyInto(out *KubevirtClusterTemplateResource) {
+func (in *SmokeTestSpec) DeepCopyInto(out *SmokeTestSpec) {
 	*out = *in
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtClusterTemplateResource.
-func (in *KubevirtClusterTemplateResource) DeepCopy() *KubevirtClusterTemplateResource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SmokeTestSpec.
+func (in *SmokeTestSpec) DeepCopy() *SmokeTestSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(KubevirtClusterTemplateResource)
+	out := new(SmokeTestSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KubevirtClusterTemplateSpec) DeepCopyInto(out *KubevirtClusterTemplateSpec) {
+func (in *StorageClassMapping) DeepCopyInto(out *StorageClassMapping) {
 	*out = *in
-	in.Template.DeepCopyInto(&out.Template)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtClusterTemplateSpec.
-func (in *KubevirtClusterTemplateSpec) DeepCopy() *KubevirtClusterTemplateSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageClassMapping.
+func (in *StorageClassMapping) DeepCopy() *StorageClassMapping {
 	if in == nil {
 		return nil
 	}
-	out := new(KubevirtClusterTemplateSpec)
+	out := new(StorageClassMapping)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KubevirtMachine) DeepCopyInto(out *KubevirtMachine) {
+func (in *TemplateNodeInfo) DeepCopyInto(out *TemplateNodeInfo) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtMachine.
-func (in *KubevirtMachine) DeepCopy() *KubevirtMachine {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateNodeInfo.
+func (in *TemplateNodeInfo) DeepCopy() *TemplateNodeInfo {
 	if in == nil {
 		return nil
 	}
-	out := new(KubevirtMachine)
+	out := new(TemplateNodeInfo)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *KubevirtMachine) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KubevirtMachineList) DeepCopyInto(out *KubevirtMachineList) {
+func (in *TenantLoadBalancerSpec) DeepCopyInto(out *TenantLoadBalancerSpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]KubevirtMachine, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
+	in.Spec.DeepCopyInto(&out.Spec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtMachineList.
-func (in *KubevirtMachineList) DeepCopy() *KubevirtMachineList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantLoadBalancerSpec.
+func (in *TenantLoadBalancerSpec) DeepCopy() *TenantLoadBalancerSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(KubevirtMachineList)
+	out := new(TenantLoadBalancerSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *KubevirtMachineList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KubevirtMachineSpec) DeepCopyInto(out *KubevirtMachineSpec) {
+func (in *TenantNetworkSpec) DeepCopyInto(out *TenantNetworkSpec) {
 	*out = *in
-	in.VirtualMachineTemplate.DeepCopyInto(&out.VirtualMachineTemplate)
-	if in.ProviderID != nil {
-		in, out := &in.ProviderID, &out.ProviderID
-		*out = new(string)
-		**out = **in
-	}
-	out.BootstrapCheckSpec = in.BootstrapCheckSpec
-	if in.InfraClusterSecretRef != nil {
-		in, out := &in.InfraClusterSecretRef, &out.InfraClusterSecretRef
-		*out = new(v1.ObjectReference)
-		**out = **in
-	}
+	in.Spec.DeepCopyInto(&out.Spec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtMachineSpec.
-func (in *KubevirtMachineSpec) DeepCopy() *KubevirtMachineSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantNetworkSpec.
+func (in *TenantNetworkSpec) DeepCopy() *TenantNetworkSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(KubevirtMachineSpec)
+	out := new(TenantNetworkSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KubevirtMachineStatus) DeepCopyInto(out *KubevirtMachineStatus) {
+func (in *UpgradePreflightCheck) DeepCopyInto(out *UpgradePreflightCheck) {
 	*out = *in
-	if in.Addresses != nil {
-		in, out := &in.Addresses, &out.Addresses
-		*out = make([]v1beta1.MachineAddress, len(*in))
-		copy(*out, *in)
-	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make(v1beta1.Conditions, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.FailureReason != nil {
-		in, out := &in.FailureReason, &out.FailureReason
-		*out = new(errors.MachineStatusError)
-		**out = **in
-	}
-	if in.FailureMessage != nil {
-		in, out := &in.FailureMessage, &out.FailureMessage
-		*out = new(string)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtMachineStatus.
-func (in *KubevirtMachineStatus) DeepCopy() *KubevirtMachineStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradePreflightCheck.
+func (in *UpgradePreflightCheck) DeepCopy() *UpgradePreflightCheck {
 	if in == nil {
 		return nil
 	}
-	out := new(KubevirtMachineStatus)
+	out := new(UpgradePreflightCheck)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KubevirtMachineTemplate) DeepCopyInto(out *KubevirtMachineTemplate) {
+func (in *UpgradePreflightSpec) DeepCopyInto(out *UpgradePreflightSpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
+	out.MinEtcdFreeSpace = in.MinEtcdFreeSpace.DeepCopy()
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtMachineTemplate.
-func (in *KubevirtMachineTemplate) DeepCopy() *KubevirtMachineTemplate {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradePreflightSpec.
+func (in *UpgradePreflightSpec) DeepCopy() *UpgradePreflightSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(KubevirtMachineTemplate)
+	out := new(UpgradePreflightSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *KubevirtMachineTemplate) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KubevirtMachineTemplateList) DeepCopyInto(out *KubevirtMachineTemplateList) {
+func (in *UpgradePreflightStatus) DeepCopyInto(out *UpgradePreflightStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]KubevirtMachineTemplate, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Checks != nil {
+		in, out := &in.Checks, &out.Checks
+		*out = make([]UpgradePreflightCheck, len(*in))
+		copy(*out, *in)
 	}
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtMachineTemplateList.
-func (in *KubevirtMachineTemplateList) DeepCopy() *KubevirtMachineTemplateList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradePreflightStatus.
+func (in *UpgradePreflightStatus) DeepCopy() *UpgradePreflightStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(KubevirtMachineTemplateList)
+	out := new(UpgradePreflightStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *KubevirtMachineTemplateList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KubevirtMachineTemplateResource) DeepCopyInto(out *KubevirtMachineTemplateResource) {
+func (in *VMDriftSpec) DeepCopyInto(out *VMDriftSpec) {
 	*out = *in
-	in.Spec.DeepCopyInto(&out.Spec)
+	if in.CheckInterval != nil {
+		in, out := &in.CheckInterval, &out.CheckInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtMachineTemplateResource.
-func (in *KubevirtMachineTemplateResource) DeepCopy() *KubevirtMachineTemplateResource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VMDriftSpec.
+func (in *VMDriftSpec) DeepCopy() *VMDriftSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(KubevirtMachineTemplateResource)
+	out := new(VMDriftSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KubevirtMachineTemplateSpec) DeepCopyInto(out *KubevirtMachineTemplateSpec) {
+func (in *VirtualMachineBootstrapCheckSpec) DeepCopyInto(out *VirtualMachineBootstrapCheckSpec) {
 	*out = *in
-	in.Template.DeepCopyInto(&out.Template)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubevirtMachineTemplateSpec.
-func (in *KubevirtMachineTemplateSpec) DeepCopy() *KubevirtMachineTemplateSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineBootstrapCheckSpec.
+func (in *VirtualMachineBootstrapCheckSpec) DeepCopy() *VirtualMachineBootstrapCheckSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(KubevirtMachineTemplateSpec)
+	out := new(VirtualMachineBootstrapCheckSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SSHKeys) DeepCopyInto(out *SSHKeys) {
+func (in *VirtualMachineInstanceInfo) DeepCopyInto(out *VirtualMachineInstanceInfo) {
 	*out = *in
-	if in.ConfigRef != nil {
-		in, out := &in.ConfigRef, &out.ConfigRef
-		*out = new(v1.ObjectReference)
-		**out = **in
+	if in.Migration != nil {
+		in, out := &in.Migration, &out.Migration
+		*out = new(MigrationInfo)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.DataSecretName != nil {
-		in, out := &in.DataSecretName, &out.DataSecretName
-		*out = new(string)
+	if in.RunningTime != nil {
+		in, out := &in.RunningTime, &out.RunningTime
+		*out = (*in).DeepCopy()
+	}
+	if in.GuestOS != nil {
+		in, out := &in.GuestOS, &out.GuestOS
+		*out = new(GuestOSInfo)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SSHKeys.
-func (in *SSHKeys) DeepCopy() *SSHKeys {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineInstanceInfo.
+func (in *VirtualMachineInstanceInfo) DeepCopy() *VirtualMachineInstanceInfo {
 	if in == nil {
 		return nil
 	}
-	out := new(SSHKeys)
+	out := new(VirtualMachineInstanceInfo)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ServiceSpecTemplate) DeepCopyInto(out *ServiceSpecTemplate) {
+func (in *VirtualMachineTemplateSpec) DeepCopyInto(out *VirtualMachineTemplateSpec) {
 	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceSpecTemplate.
-func (in *ServiceSpecTemplate) DeepCopy() *ServiceSpecTemplate {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineTemplateSpec.
+func (in *VirtualMachineTemplateSpec) DeepCopy() *VirtualMachineTemplateSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ServiceSpecTemplate)
+	out := new(VirtualMachineTemplateSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineBootstrapCheckSpec) DeepCopyInto(out *VirtualMachineBootstrapCheckSpec) {
+func (in *WorkloadDNSProbeSpec) DeepCopyInto(out *WorkloadDNSProbeSpec) {
 	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineBootstrapCheckSpec.
-func (in *VirtualMachineBootstrapCheckSpec) DeepCopy() *VirtualMachineBootstrapCheckSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadDNSProbeSpec.
+func (in *WorkloadDNSProbeSpec) DeepCopy() *WorkloadDNSProbeSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineBootstrapCheckSpec)
+	out := new(WorkloadDNSProbeSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VirtualMachineTemplateSpec) DeepCopyInto(out *VirtualMachineTemplateSpec) {
+func (in *WorkloadKubeconfigSource) DeepCopyInto(out *WorkloadKubeconfigSource) {
 	*out = *in
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Exec != nil {
+		in, out := &in.Exec, &out.Exec
+		*out = new(ExecCredentialPlugin)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualMachineTemplateSpec.
-func (in *VirtualMachineTemplateSpec) DeepCopy() *VirtualMachineTemplateSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadKubeconfigSource.
+func (in *WorkloadKubeconfigSource) DeepCopy() *WorkloadKubeconfigSource {
 	if in == nil {
 		return nil
 	}
-	out := new(VirtualMachineTemplateSpec)
+	out := new(WorkloadKubeconfigSource)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -29,10 +29,12 @@ func NewApplyCommand() *cobra.Command {
 	applyCredentialsCmd := &cobra.Command{
 		Use:     "credentials",
 		Aliases: []string{"cred", "creds"},
-		Short:   "apply a namespace, serviceAccount, role and roleBinding.",
-		Long: `apply a namespace, serviceAccount, role and roleBinding to be used by the cluster-api to manage KubeVirt virtual machines.
+		Short:   "apply a namespace, serviceAccount, roles and roleBindings.",
+		Long: `apply a namespace, serviceAccount, roles and roleBindings to be used by the cluster-api to manage KubeVirt virtual machines.
 
 Run the command against the infra-cluster - the cluster where KubeVirt is running.
+
+The capk-user-role created by previous versions is replaced by the split roles.
 `,
 		SilenceUsage: true,
 	}
@@ -48,6 +50,7 @@ Run the command against the infra-cluster - the cluster where KubeVirt is runnin
 	}
 
 	common.SetNamespaceFlag(applyCredentialsCmd, &cmdCtx.Namespace)
+	common.SetSDNAPIGroupFlag(applyCredentialsCmd, &cmdCtx.SDNAPIGroups)
 
 	return applyCredentialsCmd
 }
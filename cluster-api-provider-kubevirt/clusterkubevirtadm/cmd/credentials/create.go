@@ -29,8 +29,8 @@ func NewCreateCommand() *cobra.Command {
 	createCredentialsCmd := &cobra.Command{
 		Use:     "credentials",
 		Aliases: []string{"cred", "creds"},
-		Short:   "creates a namespace, serviceAccount, role and roleBinding.",
-		Long: `creates a namespace, serviceAccount, role and roleBinding to be used by the cluster-api to manage KubeVirt virtual machines.
+		Short:   "creates a namespace, serviceAccount, roles and roleBindings.",
+		Long: `creates a namespace, serviceAccount, roles and roleBindings to be used by the cluster-api to manage KubeVirt virtual machines.
 
 Run the command against the infra-cluster - the cluster where KubeVirt is running.		
 
@@ -51,6 +51,7 @@ Exists with error if the namespace is already exist.
 	}
 
 	common.SetNamespaceFlag(createCredentialsCmd, &cmdCtx.Namespace)
+	common.SetSDNAPIGroupFlag(createCredentialsCmd, &cmdCtx.SDNAPIGroups)
 
 	return createCredentialsCmd
 }
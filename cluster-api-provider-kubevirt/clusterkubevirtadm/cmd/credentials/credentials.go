@@ -26,20 +26,23 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sclient "k8s.io/client-go/kubernetes"
-	kubevirtcore "kubevirt.io/api/core"
-	cdicore "kubevirt.io/containerized-data-importer-api/pkg/apis/core"
 
 	"sigs.k8s.io/cluster-api-provider-kubevirt/clusterkubevirtadm/common"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/permissions"
 )
 
 const (
-	roleName        = "capk-user-role"
-	roleBindingName = roleName + "-binding"
+	// legacyRoleName is the name of the single role the previous versions created, replaced by the roles of the
+	// permissions package.
+	legacyRoleName = "capk-user-role"
+
+	roleBindingSuffix = "-binding"
 )
 
 type cmdContext struct {
-	Client    k8sclient.Interface
-	Namespace string
+	Client       k8sclient.Interface
+	Namespace    string
+	SDNAPIGroups []string
 }
 
 type clientOperation string
@@ -110,8 +113,7 @@ func createServiceAccount(ctx context.Context, cmdCtx cmdContext) error {
 	return nil
 }
 
-func createOrUpdateRole(ctx context.Context, cmdCtx cmdContext, co clientOperation) error {
-	requiredRole := generateRole(cmdCtx)
+func createOrUpdateRole(ctx context.Context, cmdCtx cmdContext, requiredRole *rbacv1.Role, co clientOperation) error {
 	existingRole, err := cmdCtx.Client.RbacV1().Roles(cmdCtx.Namespace).Get(ctx, requiredRole.Name, metav1.GetOptions{})
 	if err != nil {
 		if errors.IsNotFound(err) {
@@ -122,15 +124,15 @@ func createOrUpdateRole(ctx context.Context, cmdCtx cmdContext, co clientOperati
 			}
 			return nil
 		} else {
-			return fmt.Errorf("can't read existingRole %s; %w", roleName, err)
+			return fmt.Errorf("can't read existingRole %s; %w", requiredRole.Name, err)
 		}
 	}
 
 	if co == clientOperationApply {
-		common.CmdLog("Found Role", roleName)
+		common.CmdLog("Found Role", requiredRole.Name)
 		if !reflect.DeepEqual(existingRole.Rules, requiredRole.Rules) {
 			existingRole.Rules = requiredRole.Rules
-			common.CmdLog("Updating Role", roleName)
+			common.CmdLog("Updating Role", requiredRole.Name)
 			_, err = cmdCtx.Client.RbacV1().Roles(cmdCtx.Namespace).Update(ctx, existingRole, metav1.UpdateOptions{})
 			return err
 		}
@@ -139,37 +141,27 @@ func createOrUpdateRole(ctx context.Context, cmdCtx cmdContext, co clientOperati
 
 	// should never get here: if the NS is already exist in create, we'll exit much earlier. On apply we'll get into the
 	// previous condition
-	return fmt.Errorf("role %s is already exist", roleName)
+	return fmt.Errorf("role %s is already exist", requiredRole.Name)
 }
 
-func generateRole(cmdCtx cmdContext) *rbacv1.Role {
-	return &rbacv1.Role{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      roleName,
-			Namespace: cmdCtx.Namespace,
-		},
-		Rules: []rbacv1.PolicyRule{
-			{
-				APIGroups: []string{kubevirtcore.GroupName},
-				Resources: []string{"virtualmachines", "virtualmachineinstances"},
-				Verbs:     []string{rbacv1.VerbAll},
-			},
-			{
-				APIGroups: []string{cdicore.GroupName},
-				Resources: []string{"datavolumes"},
-				Verbs:     []string{"get", "list", "watch"},
-			},
-			{
-				APIGroups: []string{""},
-				Resources: []string{"secrets", "services"},
-				Verbs:     []string{rbacv1.VerbAll},
+// generateRoles returns a role per concern of the provider: the management of the VMs, the reading of the secrets
+// and, when its API groups are given, the management of the objects of the infra SDN.
+func generateRoles(cmdCtx cmdContext) []*rbacv1.Role {
+	var roles []*rbacv1.Role
+	for _, role := range permissions.Roles(cmdCtx.SDNAPIGroups) {
+		roles = append(roles, &rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      role.Name,
+				Namespace: cmdCtx.Namespace,
 			},
-		},
+			Rules: role.Rules,
+		})
 	}
+	return roles
 }
 
-func ensureRoleBinding(ctx context.Context, cmdCtx cmdContext) error {
-	rb := generateRoleBinding(cmdCtx)
+func ensureRoleBinding(ctx context.Context, cmdCtx cmdContext, roleName string) error {
+	rb := generateRoleBinding(cmdCtx, roleName)
 	_, err := cmdCtx.Client.RbacV1().RoleBindings(cmdCtx.Namespace).Get(ctx, rb.Name, metav1.GetOptions{})
 	if err != nil {
 		if errors.IsNotFound(err) {
@@ -189,10 +181,10 @@ func ensureRoleBinding(ctx context.Context, cmdCtx cmdContext) error {
 	return nil
 }
 
-func generateRoleBinding(cmdCtx cmdContext) *rbacv1.RoleBinding {
+func generateRoleBinding(cmdCtx cmdContext, roleName string) *rbacv1.RoleBinding {
 	return &rbacv1.RoleBinding{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      roleBindingName,
+			Name:      roleName + roleBindingSuffix,
 			Namespace: cmdCtx.Namespace,
 		},
 		Subjects: []rbacv1.Subject{
@@ -210,6 +202,23 @@ func generateRoleBinding(cmdCtx cmdContext) *rbacv1.RoleBinding {
 	}
 }
 
+// deleteLegacyRole deletes the single role of the previous versions and its binding, replaced by the roles of
+// generateRoles.
+func deleteLegacyRole(ctx context.Context, cmdCtx cmdContext) error {
+	err := cmdCtx.Client.RbacV1().RoleBindings(cmdCtx.Namespace).Delete(ctx, legacyRoleName+roleBindingSuffix, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("can't delete roleBinding %s; %w", legacyRoleName+roleBindingSuffix, err)
+	}
+	err = cmdCtx.Client.RbacV1().Roles(cmdCtx.Namespace).Delete(ctx, legacyRoleName, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("can't delete role %s; %w", legacyRoleName, err)
+	}
+	if err == nil {
+		common.CmdLog("Deleted legacy Role", legacyRoleName)
+	}
+	return nil
+}
+
 func createOrUpdateResources(ctx context.Context, cmdCtx cmdContext, co clientOperation) error {
 	err := ensureNamespace(ctx, cmdCtx, co)
 	if err != nil {
@@ -221,15 +230,20 @@ func createOrUpdateResources(ctx context.Context, cmdCtx cmdContext, co clientOp
 		return err
 	}
 
-	err = createOrUpdateRole(ctx, cmdCtx, co)
-	if err != nil {
-		return err
-	}
+	for _, role := range generateRoles(cmdCtx) {
+		err = createOrUpdateRole(ctx, cmdCtx, role, co)
+		if err != nil {
+			return err
+		}
 
-	err = ensureRoleBinding(ctx, cmdCtx)
-	if err != nil {
-		return err
+		err = ensureRoleBinding(ctx, cmdCtx, role.Name)
+		if err != nil {
+			return err
+		}
 	}
 
+	if co == clientOperationApply {
+		return deleteLegacyRole(ctx, cmdCtx)
+	}
 	return nil
 }
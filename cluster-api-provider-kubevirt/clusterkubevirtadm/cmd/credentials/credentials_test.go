@@ -11,13 +11,14 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
 	kubevirtcore "kubevirt.io/api/core"
-	cdicore "kubevirt.io/containerized-data-importer-api/pkg/apis/core"
 
 	"sigs.k8s.io/cluster-api-provider-kubevirt/clusterkubevirtadm/common"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/permissions"
 )
 
 const (
 	namespaceName = "ns-name"
+	roleName      = permissions.InfraVMRoleName
 )
 
 var _ = Describe("test credentials common function", func() {
@@ -105,13 +106,37 @@ var _ = Describe("test credentials common function", func() {
 			}
 		})
 
+		It("should generate a role per concern", func() {
+			roles := generateRoles(cmdCtx)
+			Expect(roles).To(HaveLen(2))
+			Expect(roles[0].Name).Should(Equal(permissions.InfraVMRoleName))
+			Expect(roles[0].Rules[0].APIGroups).Should(And(HaveLen(1), ContainElements(kubevirtcore.GroupName)))
+			Expect(roles[0].Rules[0].Resources).Should(And(HaveLen(1), ContainElements("virtualmachines")))
+			Expect(roles[0].Rules).Should(ContainElements(
+				rbacv1.PolicyRule{APIGroups: []string{"apps"}, Resources: []string{"daemonsets"}, Verbs: []string{"create", "delete", "get", "update"}},
+				rbacv1.PolicyRule{APIGroups: []string{"coordination.k8s.io"}, Resources: []string{"leases"}, Verbs: []string{"create", "delete", "get", "update"}},
+			))
+			Expect(roles[1].Name).Should(Equal(permissions.SecretReaderRoleName))
+			Expect(roles[1].Rules).Should(HaveLen(1))
+			Expect(roles[1].Rules[0].Resources).Should(And(HaveLen(1), ContainElements("secrets")))
+			Expect(roles[1].Rules[0].Verbs).Should(And(HaveLen(3), ContainElements("get", "list", "watch")))
+
+			cmdCtx.SDNAPIGroups = []string{"sdn.example.com"}
+			roles = generateRoles(cmdCtx)
+			Expect(roles).To(HaveLen(3))
+			Expect(roles[2].Name).Should(Equal(permissions.SDNRoleName))
+			Expect(roles[2].Rules[0].APIGroups).Should(And(HaveLen(1), ContainElements("sdn.example.com")))
+			Expect(roles[2].Rules[0].Resources).Should(And(HaveLen(1), ContainElements(rbacv1.ResourceAll)))
+		})
+
 		It("should create Role if missing", func() {
 			client := fake.NewSimpleClientset(
 				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespaceName}},
 			)
 			cmdCtx.Client = client
+			expectedRole := generateRoles(cmdCtx)[0]
 
-			Expect(createOrUpdateRole(context.Background(), cmdCtx, clientOperationCreate)).To(Succeed())
+			Expect(createOrUpdateRole(context.Background(), cmdCtx, expectedRole, clientOperationCreate)).To(Succeed())
 
 			roles, err := client.RbacV1().Roles(namespaceName).List(context.Background(), metav1.ListOptions{})
 			Expect(err).ToNot(HaveOccurred())
@@ -119,35 +144,24 @@ var _ = Describe("test credentials common function", func() {
 			Expect(roles).ToNot(BeNil())
 			Expect(roles.Items).To(HaveLen(1))
 
-			Expect(roles.Items[0].Name).Should(Equal(roleName))
-			Expect(roles.Items[0].Rules).Should(HaveLen(3))
-			Expect(roles.Items[0].Rules[0].APIGroups).Should(And(HaveLen(1), ContainElements(kubevirtcore.GroupName)))
-			Expect(roles.Items[0].Rules[0].Resources).Should(And(HaveLen(2), ContainElements("virtualmachines", "virtualmachineinstances")))
-			Expect(roles.Items[0].Rules[0].Verbs).Should(And(HaveLen(1), ContainElements(rbacv1.VerbAll)))
-
-			Expect(roles.Items[0].Rules[1].APIGroups).Should(And(HaveLen(1), ContainElements(cdicore.GroupName)))
-			Expect(roles.Items[0].Rules[1].Resources).Should(And(HaveLen(1), ContainElements("datavolumes")))
-			Expect(roles.Items[0].Rules[1].Verbs).Should(And(HaveLen(3), ContainElements("get", "list", "watch")))
-
-			Expect(roles.Items[0].Rules[2].APIGroups).Should(And(HaveLen(1), ContainElements("")))
-			Expect(roles.Items[0].Rules[2].Resources).Should(And(HaveLen(2), ContainElements("secrets", "services")))
-			Expect(roles.Items[0].Rules[2].Verbs).Should(And(HaveLen(1), ContainElements(rbacv1.VerbAll)))
+			Expect(roles.Items[0].Name).Should(Equal(permissions.InfraVMRoleName))
+			Expect(roles.Items[0].Rules).Should(Equal(expectedRole.Rules))
 		})
 
 		It("create should return error if the Role is already exist", func() {
-			expectedRole := generateRole(cmdCtx)
+			expectedRole := generateRoles(cmdCtx)[0]
 			client := fake.NewSimpleClientset(
 				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespaceName}},
-				expectedRole,
+				expectedRole.DeepCopy(),
 			)
 			cmdCtx.Client = client
 
-			Expect(createOrUpdateRole(context.Background(), cmdCtx, clientOperationCreate)).ToNot(Succeed())
+			Expect(createOrUpdateRole(context.Background(), cmdCtx, expectedRole, clientOperationCreate)).ToNot(Succeed())
 		})
 
 		It("should update the role if it is already exist, with different values", func() {
-			expectedRole := generateRole(cmdCtx)
-			existingRole := generateRole(cmdCtx)
+			expectedRole := generateRoles(cmdCtx)[0]
+			existingRole := generateRoles(cmdCtx)[0]
 
 			existingRole.Rules = []rbacv1.PolicyRule{
 				{
@@ -163,7 +177,7 @@ var _ = Describe("test credentials common function", func() {
 			)
 			cmdCtx.Client = client
 
-			Expect(createOrUpdateRole(context.Background(), cmdCtx, clientOperationApply)).To(Succeed())
+			Expect(createOrUpdateRole(context.Background(), cmdCtx, expectedRole, clientOperationApply)).To(Succeed())
 
 			roles, err := client.RbacV1().Roles(namespaceName).List(context.Background(), metav1.ListOptions{})
 			Expect(err).ToNot(HaveOccurred())
@@ -171,7 +185,7 @@ var _ = Describe("test credentials common function", func() {
 			Expect(roles).ToNot(BeNil())
 			Expect(roles.Items).To(HaveLen(1))
 
-			Expect(roles.Items[0].Name).Should(Equal(roleName))
+			Expect(roles.Items[0].Name).Should(Equal(permissions.InfraVMRoleName))
 			Expect(roles.Items[0].Rules).Should(Equal(expectedRole.Rules))
 		})
 	})
@@ -196,7 +210,7 @@ var _ = Describe("test credentials common function", func() {
 			)
 			cmdCtx.Client = client
 
-			Expect(ensureRoleBinding(context.Background(), cmdCtx)).To(Succeed())
+			Expect(ensureRoleBinding(context.Background(), cmdCtx, roleName)).To(Succeed())
 
 			roleBindings, err := client.RbacV1().RoleBindings(namespaceName).List(context.Background(), metav1.ListOptions{})
 			Expect(err).ToNot(HaveOccurred())
@@ -234,4 +248,35 @@ var _ = Describe("test credentials common function", func() {
 			Expect(roleBindings.Items[0].Name).Should(Equal(roleName + "-binding"))
 		})
 	})
+
+	Context("test createOrUpdateResources", func() {
+		It("should replace the legacy role by the split roles on apply", func() {
+			client := fake.NewSimpleClientset(
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespaceName}},
+				&rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: legacyRoleName, Namespace: namespaceName}},
+				&rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: legacyRoleName + "-binding", Namespace: namespaceName}},
+			)
+			cmdCtx := cmdContext{Client: client, Namespace: namespaceName, SDNAPIGroups: []string{"sdn.example.com"}}
+
+			Expect(createOrUpdateResources(context.Background(), cmdCtx, clientOperationApply)).To(Succeed())
+
+			roles, err := client.RbacV1().Roles(namespaceName).List(context.Background(), metav1.ListOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			var roleNames []string
+			for _, role := range roles.Items {
+				roleNames = append(roleNames, role.Name)
+			}
+			Expect(roleNames).To(ConsistOf(permissions.InfraVMRoleName, permissions.SecretReaderRoleName, permissions.SDNRoleName))
+
+			roleBindings, err := client.RbacV1().RoleBindings(namespaceName).List(context.Background(), metav1.ListOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			var roleRefs []string
+			for _, roleBinding := range roleBindings.Items {
+				Expect(roleBinding.Name).To(Equal(roleBinding.RoleRef.Name + "-binding"))
+				Expect(roleBinding.Subjects).To(ConsistOf(rbacv1.Subject{Kind: "ServiceAccount", Namespace: namespaceName, Name: common.ServiceAccountName}))
+				roleRefs = append(roleRefs, roleBinding.RoleRef.Name)
+			}
+			Expect(roleRefs).To(ConsistOf(permissions.InfraVMRoleName, permissions.SecretReaderRoleName, permissions.SDNRoleName))
+		})
+	})
 })
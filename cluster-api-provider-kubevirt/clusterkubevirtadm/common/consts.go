@@ -2,5 +2,6 @@ package common
 
 const (
 	CmdParamServiceNamespace = "namespace"
+	CmdParamSDNAPIGroup      = "sdn-api-group"
 	ServiceAccountName       = "capk-service-account"
 )
@@ -19,6 +19,11 @@ func SetNamespaceFlag(cmd *cobra.Command, namespace *string) {
 
 }
 
+// SetSDNAPIGroupFlag adds the flag of the API groups of the infra SDN the provider manages objects of.
+func SetSDNAPIGroupFlag(cmd *cobra.Command, apiGroups *[]string) {
+	cmd.Flags().StringSliceVar(apiGroups, CmdParamSDNAPIGroup, nil, "API group of the infra SDN objects managed by the provider, e.g. the tenant networks, load balancers and floating IP claims (repeatable). No SDN role is created if unset.")
+}
+
 // CmdLog writes logs and errors to stderr. stdout is for the command output
 func CmdLog(output ...interface{}) {
 	fmt.Fprintln(os.Stderr, output...)
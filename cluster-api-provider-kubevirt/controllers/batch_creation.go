@@ -0,0 +1,190 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/ssh"
+)
+
+const (
+	// batchedVMCreatedReason is the reason of the events of the machines whose VM was created in the batch of a sibling.
+	batchedVMCreatedReason = "BatchedVMCreated"
+	// batchedVMCreateFailedReason is the reason of the events of the machines whose VM failed to be created in the
+	// batch of a sibling; they create it in their own reconcile instead.
+	batchedVMCreateFailedReason = "BatchedVMCreateFailed"
+)
+
+// batchedMachine is a sibling machine whose VM is created in a batch.
+type batchedMachine struct {
+	machine         *clusterv1.Machine
+	kubevirtMachine *infrav1.KubevirtMachine
+}
+
+// reconcileBatchedVMCreation creates the VMs of the pending machines of the MachineSet of the machine, once its own
+// VM is created. The gates the machine passed before the creation of its VM hold for the siblings with the same
+// spec, so only those are part of the batch, but for the quota of the cluster: the clusters with a quota create
+// their VMs one per reconcile. The failures are partitioned per sibling: they are reported in events
+// of the siblings, which retry the creation in their own reconcile, and do not fail the machine or the batch.
+func (r *KubevirtMachineReconciler) reconcileBatchedVMCreation(ctx *context.MachineContext, infraClusterClient client.Client, vmNamespace string, sshKeys *ssh.ClusterNodeSshKeys) {
+	spec := ctx.KubevirtCluster.Spec.BatchedVMCreation
+	machineSet := ctx.KubevirtMachine.Labels[clusterv1.MachineSetNameLabel]
+	if spec == nil || machineSet == "" || ctx.KubevirtCluster.Spec.Quota != nil {
+		return
+	}
+
+	siblings, err := r.batchedMachines(ctx, machineSet, kubevirt.BatchSize(spec)-1)
+	if err != nil {
+		ctx.Logger.Error(err, "Failed to list the machines of the MachineSet, leaving them to create their VMs", "machineSet", machineSet)
+		return
+	}
+	if len(siblings) == 0 {
+		return
+	}
+
+	created := make([]bool, len(siblings))
+	errs := make([]error, len(siblings))
+	slots := make(chan struct{}, kubevirt.BatchParallelism(spec))
+	var wg sync.WaitGroup
+	for i, sibling := range siblings {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			created[i], errs[i] = r.createBatchedVM(ctx, sibling, infraClusterClient, vmNamespace, sshKeys)
+		}()
+	}
+	wg.Wait()
+
+	var createdCount, failedCount int
+	for i, sibling := range siblings {
+		switch {
+		case errs[i] != nil:
+			failedCount++
+			ctx.Logger.Error(errs[i], "Failed to create the VM of a machine of the batch", "kubevirtMachine", sibling.kubevirtMachine.Name)
+			if r.Recorder != nil {
+				r.Recorder.Eventf(sibling.kubevirtMachine, corev1.EventTypeWarning, batchedVMCreateFailedReason,
+					"Failed to create the VM in the batch of KubevirtMachine %s: %v", ctx.KubevirtMachine.Name, errs[i])
+			}
+		case created[i]:
+			createdCount++
+			if r.Recorder != nil {
+				r.Recorder.Eventf(sibling.kubevirtMachine, corev1.EventTypeNormal, batchedVMCreatedReason,
+					"VM created in the batch of KubevirtMachine %s", ctx.KubevirtMachine.Name)
+			}
+		}
+	}
+	ctx.Logger.Info("Created the VMs of the machines of the MachineSet in a batch", "machineSet", machineSet, "created", createdCount, "failed", failedCount)
+}
+
+// batchedMachines returns up to limit machines of the MachineSet waiting for the creation of their VM, with the same
+// spec as the machine, sorted by name.
+func (r *KubevirtMachineReconciler) batchedMachines(ctx *context.MachineContext, machineSet string, limit int) ([]batchedMachine, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	kubevirtMachines := &infrav1.KubevirtMachineList{}
+	if err := r.Client.List(ctx, kubevirtMachines, client.InNamespace(ctx.KubevirtMachine.Namespace), client.MatchingLabels{clusterv1.MachineSetNameLabel: machineSet}); err != nil {
+		return nil, errors.Wrapf(err, "failed to list the KubevirtMachines of MachineSet %s", machineSet)
+	}
+	sort.Slice(kubevirtMachines.Items, func(i, j int) bool {
+		return kubevirtMachines.Items[i].Name < kubevirtMachines.Items[j].Name
+	})
+
+	var siblings []batchedMachine
+	for i := range kubevirtMachines.Items {
+		kubevirtMachine := kubevirtMachines.Items[i].DeepCopy()
+		if kubevirtMachine.Name == ctx.KubevirtMachine.Name || !kubevirtMachine.DeletionTimestamp.IsZero() ||
+			!controllerutil.ContainsFinalizer(kubevirtMachine, infrav1.MachineFinalizer) || annotations.HasPaused(kubevirtMachine) ||
+			kubevirtMachine.Spec.ProviderID != nil || kubevirtMachine.Status.FailureReason != nil {
+			continue
+		}
+		if kubevirtMachine.Spec.InfraClusterSecretRef == nil {
+			kubevirtMachine.Spec.InfraClusterSecretRef = ctx.KubevirtCluster.Spec.InfraClusterSecretRef
+		}
+		if !equality.Semantic.DeepEqual(kubevirtMachine.Spec, ctx.KubevirtMachine.Spec) {
+			continue
+		}
+
+		machine, err := util.GetOwnerMachine(ctx, r.Client, kubevirtMachine.ObjectMeta)
+		if err != nil || machine == nil || !machine.DeletionTimestamp.IsZero() || machine.Spec.Bootstrap.DataSecretName == nil {
+			continue
+		}
+
+		siblings = append(siblings, batchedMachine{machine: machine, kubevirtMachine: kubevirtMachine})
+		if len(siblings) == limit {
+			break
+		}
+	}
+	return siblings, nil
+}
+
+// createBatchedVM creates the VM of a sibling machine of the batch, unless it already exists. It returns whether the
+// VM was created.
+func (r *KubevirtMachineReconciler) createBatchedVM(ctx *context.MachineContext, sibling batchedMachine, infraClusterClient client.Client, vmNamespace string, sshKeys *ssh.ClusterNodeSshKeys) (bool, error) {
+	siblingCtx := &context.MachineContext{
+		Context:         ctx.Context,
+		Cluster:         ctx.Cluster,
+		KubevirtCluster: ctx.KubevirtCluster,
+		Machine:         sibling.machine,
+		KubevirtMachine: sibling.kubevirtMachine,
+		MachineImage:    ctx.MachineImage,
+		Operations:      ctx.Operations,
+		Logger:          ctx.Logger.WithValues("kubevirtMachine", sibling.kubevirtMachine.Name),
+	}
+	if ctx.KubevirtCluster.Spec.BatchedVMCreation.SharedDataVolumeSource {
+		siblingCtx.DataVolumeSourceMachine = ctx.KubevirtMachine.Name
+	}
+
+	if err := r.reconcileKubevirtBootstrapSecret(siblingCtx, infraClusterClient, vmNamespace, sshKeys); err != nil {
+		return false, errors.Wrap(err, "failed to fetch kubevirt bootstrap secret")
+	}
+	if err := r.reconcileMachineMigrationPolicy(siblingCtx, infraClusterClient); err != nil {
+		return false, errors.Wrap(err, "failed to reconcile the migration policy")
+	}
+
+	externalMachine, err := r.MachineFactory.NewMachine(siblingCtx, infraClusterClient, vmNamespace, sshKeys)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to create helper for managing the externalMachine")
+	}
+	// Leave the existing VMs, e.g. created by the reconcile of the sibling or left behind, to the sibling to adopt
+	if externalMachine.Exists() {
+		return false, nil
+	}
+	if err := externalMachine.Create(siblingCtx.Context); err != nil {
+		return false, errors.Wrap(err, "failed to create VM instance")
+	}
+	return true, nil
+}
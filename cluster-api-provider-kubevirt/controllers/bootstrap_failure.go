@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/guestagent"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
+)
+
+const (
+	// bootstrapDiagnosisGracePeriod leaves cloud-init the time to run the bootstrap data before its log is read.
+	bootstrapDiagnosisGracePeriod = 2 * time.Minute
+	// bootstrapDiagnosisInterval is the interval between two reads of the bootstrap log of a VM.
+	bootstrapDiagnosisInterval = time.Minute
+
+	// bootstrapRetriedReason is the reason of the events of the machines whose bootstrap is retried.
+	bootstrapRetriedReason = "BootstrapRetried"
+)
+
+// reconcileBootstrapFailure reads the bootstrap log of a VM not bootstrapped yet through the guest agent, records
+// the failure it shows in the status of the machine and, for the failures known to be transient, retries the
+// bootstrap: the VM is deleted to be created again after a network or image pull failure, and a fresh join token
+// is requested before the VM is created again after a join token failure. It returns true if the bootstrap was
+// retried.
+func (r *KubevirtMachineReconciler) reconcileBootstrapFailure(ctx *context.MachineContext, externalMachine kubevirt.MachineInterface, vmNamespace string, now time.Time) (bool, error) {
+	spec := ctx.KubevirtMachine.Spec.BootstrapFailures
+	status := &ctx.KubevirtMachine.Status
+	if spec == nil || r.GuestAgent == nil || !kubevirt.GuestAgentEnabled(ctx.KubevirtMachine) || status.ProvisioningPhaseStartTime == nil ||
+		now.Sub(status.ProvisioningPhaseStartTime.Time) < bootstrapDiagnosisGracePeriod {
+		return false, nil
+	}
+	if status.BootstrapDiagnosis != nil && now.Sub(status.BootstrapDiagnosis.CheckTime.Time) < bootstrapDiagnosisInterval {
+		return false, nil
+	}
+
+	restConfig, _, err := r.InfraCluster.GenerateInfraClusterRestConfig(ctx.KubevirtMachine.Spec.InfraClusterSecretRef, ctx.KubevirtMachine.Namespace, ctx.Context)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to generate infra cluster config")
+	}
+
+	diagnosis := &infrav1.BootstrapDiagnosis{CheckTime: metav1.Time{Time: now}}
+	if previous := status.BootstrapDiagnosis; previous != nil {
+		diagnosis.Reason, diagnosis.Message = previous.Reason, previous.Message
+	}
+	status.BootstrapDiagnosis = diagnosis
+
+	// The guest agent may not be up yet, the log is read again at the next interval
+	result, err := r.GuestAgent.Run(ctx, restConfig, vmNamespace, ctx.KubevirtMachine.Name, guestagent.BootstrapLogCommand)
+	if err != nil {
+		ctx.Logger.V(4).Info("Failed to read the bootstrap log of the VM", "error", err.Error())
+		return false, nil
+	}
+	if result.ExitCode != 0 {
+		ctx.Logger.V(4).Info("Failed to read the bootstrap log of the VM", "exitCode", result.ExitCode, "stderr", result.Stderr)
+		return false, nil
+	}
+
+	diagnosis.Reason, diagnosis.Message = kubevirt.ClassifyBootstrapLog(result.Stdout)
+	if !kubevirt.IsTransientBootstrapFailure(diagnosis.Reason) || status.BootstrapRetries >= spec.MaxRetries {
+		return false, nil
+	}
+
+	action := "recreating the VM"
+	if diagnosis.Reason == infrav1.BootstrapTokenExpiredReason {
+		refreshed, err := r.refreshBootstrapTokenSince(ctx, status.ProvisioningPhaseStartTime.Time)
+		if err != nil || !refreshed {
+			return false, err
+		}
+		action = "recreating the VM with a fresh join token"
+	}
+	if err := externalMachine.Delete(); err != nil {
+		return false, errors.Wrap(err, "failed to delete the VM to retry its bootstrap")
+	}
+
+	status.BootstrapRetries++
+	clearProvisioningPhase(ctx.KubevirtMachine)
+	message := fmt.Sprintf("Bootstrap failed with %s: %s, %s (%d/%d)", diagnosis.Reason, diagnosis.Message, action, status.BootstrapRetries, spec.MaxRetries)
+	ctx.Logger.Info("Retrying the bootstrap of the machine", "reason", diagnosis.Reason, "action", action)
+	if r.Recorder != nil {
+		r.Recorder.Event(ctx.KubevirtMachine, corev1.EventTypeWarning, bootstrapRetriedReason, message)
+	}
+	return true, nil
+}
+
+// refreshBootstrapTokenSince requests a fresh join token from the bootstrap provider, unless the provider refreshed
+// it after the given time, and returns true once it did. The guest of a running VM does not run the refreshed
+// bootstrap data, so the VM has to be created again with it.
+func (r *KubevirtMachineReconciler) refreshBootstrapTokenSince(ctx *context.MachineContext, since time.Time) (bool, error) {
+	config, err := r.getBootstrapConfig(ctx)
+	if err != nil || config == nil || bootstrapTokenRefreshPending(config) {
+		return false, err
+	}
+	if refreshed, err := time.Parse(time.RFC3339, config.GetAnnotations()[infrav1.BootstrapTokenRefreshedAnnotation]); err == nil && refreshed.After(since) {
+		return true, nil
+	}
+
+	if err := r.requestBootstrapTokenRefresh(ctx, config); err != nil {
+		return false, err
+	}
+	ctx.Logger.Info("Join token rejected, requested a fresh one from the bootstrap provider")
+	if r.Recorder != nil {
+		r.Recorder.Eventf(ctx.KubevirtMachine, corev1.EventTypeNormal, bootstrapTokenRefreshRequestedReason,
+			"Join token rejected, requested a fresh join token from %s %s", config.GetKind(), config.GetName())
+	}
+	return false, nil
+}
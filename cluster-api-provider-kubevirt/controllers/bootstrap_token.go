@@ -0,0 +1,247 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
+)
+
+const (
+	// bootstrapTokenRefreshRequestedReason is the reason of the events of the machines requesting a fresh join
+	// token.
+	bootstrapTokenRefreshRequestedReason = "BootstrapTokenRefreshRequested"
+	// bootstrapTokenHoldReleasedReason is the reason of the events of the machines whose VM start is released.
+	bootstrapTokenHoldReleasedReason = "BootstrapTokenHoldReleased"
+
+	// bootstrapTokenRefreshTimeout is the time the bootstrap provider has to answer a join token refresh request.
+	bootstrapTokenRefreshTimeout = 5 * time.Minute
+)
+
+// bootstrapConfigKind is the kind of the bootstrap configs the join token refresh is requested from, the only ones
+// the controller is allowed to patch.
+var bootstrapConfigKind = schema.GroupKind{Group: "bootstrap.cluster.x-k8s.io", Kind: "KubeadmConfig"}
+
+// reconcileBootstrapTokenRefresh asks the bootstrap provider for bootstrap data with a fresh join token once the
+// machine is pending for half the TTL of the tokens, since its creation or the last refresh. The request is an
+// annotation of the bootstrap config, answered by the bootstrap provider with another one; the new bootstrap data
+// is then delivered to the VM if it did not boot yet. The estimated expiry of the current token is recorded in the
+// status of the machine.
+func (r *KubevirtMachineReconciler) reconcileBootstrapTokenRefresh(ctx *context.MachineContext) error {
+	if r.BootstrapTokenTTL == 0 {
+		return nil
+	}
+	config, err := r.getBootstrapConfig(ctx)
+	if err != nil || config == nil {
+		return err
+	}
+
+	since := ctx.Machine.CreationTimestamp.Time
+	if refreshedTime, err := time.Parse(time.RFC3339, config.GetAnnotations()[infrav1.BootstrapTokenRefreshedAnnotation]); err == nil && refreshedTime.After(since) {
+		since = refreshedTime
+	}
+	ctx.KubevirtMachine.Status.BootstrapTokenExpiryTime = &metav1.Time{Time: since.Add(r.BootstrapTokenTTL)}
+
+	if bootstrapTokenRefreshUnanswered(config) {
+		ctx.Logger.V(4).Info("The bootstrap provider did not answer the join token refresh request",
+			"requested", config.GetAnnotations()[infrav1.BootstrapTokenRefreshRequestedAnnotation])
+		return nil
+	}
+	if bootstrapTokenRefreshPending(config) {
+		ctx.Logger.V(4).Info("Waiting for the bootstrap provider to refresh the join token",
+			"requested", config.GetAnnotations()[infrav1.BootstrapTokenRefreshRequestedAnnotation])
+		return nil
+	}
+	if time.Since(since) < r.BootstrapTokenTTL/2 {
+		return nil
+	}
+
+	if err := r.requestBootstrapTokenRefresh(ctx, config); err != nil {
+		return err
+	}
+
+	configRef := ctx.Machine.Spec.Bootstrap.ConfigRef
+	ctx.Logger.Info("Machine pending for long, requested a fresh join token from the bootstrap provider", "since", since)
+	if r.Recorder != nil {
+		r.Recorder.Eventf(ctx.KubevirtMachine, corev1.EventTypeNormal, bootstrapTokenRefreshRequestedReason,
+			"Machine pending since %s, requested a fresh join token from %s %s", since.UTC().Format(time.RFC3339), configRef.Kind, configRef.Name)
+	}
+	return nil
+}
+
+// getBootstrapConfig returns the bootstrap config of the machine, or nil if it has none or it is not a KubeadmConfig.
+func (r *KubevirtMachineReconciler) getBootstrapConfig(ctx *context.MachineContext) (*unstructured.Unstructured, error) {
+	configRef := ctx.Machine.Spec.Bootstrap.ConfigRef
+	if configRef == nil || configRef.GroupVersionKind().GroupKind() != bootstrapConfigKind {
+		return nil, nil
+	}
+
+	namespace := configRef.Namespace
+	if namespace == "" {
+		namespace = ctx.Machine.Namespace
+	}
+	config := &unstructured.Unstructured{}
+	config.SetGroupVersionKind(configRef.GroupVersionKind())
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: configRef.Name}, config); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to fetch bootstrap config %s/%s", namespace, configRef.Name)
+	}
+	return config, nil
+}
+
+// bootstrapTokenRefreshPending returns true if the bootstrap provider did not answer the last join token refresh
+// requested from the bootstrap config yet.
+func bootstrapTokenRefreshPending(config *unstructured.Unstructured) bool {
+	requested := config.GetAnnotations()[infrav1.BootstrapTokenRefreshRequestedAnnotation]
+	return requested != "" && requested != config.GetAnnotations()[infrav1.BootstrapTokenRefreshedAnnotation]
+}
+
+// bootstrapTokenRefreshUnanswered returns true if the bootstrap provider did not answer the last join token refresh
+// requested from the bootstrap config within the timeout, e.g. because it does not implement the handshake.
+func bootstrapTokenRefreshUnanswered(config *unstructured.Unstructured) bool {
+	if !bootstrapTokenRefreshPending(config) {
+		return false
+	}
+	requested, err := time.Parse(time.RFC3339, config.GetAnnotations()[infrav1.BootstrapTokenRefreshRequestedAnnotation])
+	return err != nil || time.Since(requested) > bootstrapTokenRefreshTimeout
+}
+
+// requestBootstrapTokenRefresh annotates the bootstrap config to request bootstrap data with a fresh join token.
+func (r *KubevirtMachineReconciler) requestBootstrapTokenRefresh(ctx *context.MachineContext, config *unstructured.Unstructured) error {
+	patch := client.MergeFrom(config.DeepCopy())
+	annotations := config.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[infrav1.BootstrapTokenRefreshRequestedAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	config.SetAnnotations(annotations)
+	if err := r.Client.Patch(ctx, config, patch); err != nil {
+		return errors.Wrapf(err, "failed to request a join token refresh from bootstrap config %s/%s", config.GetNamespace(), config.GetName())
+	}
+	return nil
+}
+
+// reconcileBootstrapTokenHold holds the start of a VM whose guest did not boot yet, e.g. while its disks import,
+// as long as its join token would expire within the minimal validity: the VM is stopped and a fresh join token is
+// requested. The VM is started again once the token is refreshed, after the new bootstrap data is delivered to it.
+// Only the machines whose bootstrap provider implements the refresh handshake are held. It returns true while the
+// VM is held.
+func (r *KubevirtMachineReconciler) reconcileBootstrapTokenHold(ctx *context.MachineContext, infraClusterClient client.Client, vmNamespace string) (ctrl.Result, bool, error) {
+	vmKey := client.ObjectKey{Namespace: vmNamespace, Name: ctx.KubevirtMachine.Name}
+	vm := &kubevirtv1.VirtualMachine{}
+	if err := infraClusterClient.Get(ctx, vmKey, vm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, false, nil
+		}
+		return ctrl.Result{}, false, errors.Wrapf(err, "failed to fetch VM %s", vmKey)
+	}
+	vmi := &kubevirtv1.VirtualMachineInstance{}
+	if err := infraClusterClient.Get(ctx, vmKey, vmi); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, false, errors.Wrapf(err, "failed to fetch VMI %s", vmKey)
+		}
+		vmi = nil
+	}
+
+	expiry := ctx.KubevirtMachine.Status.BootstrapTokenExpiryTime
+	if r.BootstrapTokenTTL == 0 || r.BootstrapTokenMinValidity == 0 || expiry == nil || kubevirt.IsBooted(vmi) ||
+		time.Until(expiry.Time) >= r.BootstrapTokenMinValidity {
+		return ctrl.Result{}, false, r.releaseBootstrapTokenHold(ctx, infraClusterClient, vm)
+	}
+
+	config, err := r.getBootstrapConfig(ctx)
+	if err != nil {
+		return ctrl.Result{}, false, err
+	}
+	// Holding the VM is pointless when the bootstrap provider never answered a refresh request
+	if config == nil || config.GetAnnotations()[infrav1.BootstrapTokenRefreshedAnnotation] == "" {
+		return ctrl.Result{}, false, r.releaseBootstrapTokenHold(ctx, infraClusterClient, vm)
+	}
+	// The VM is started with the token it has when the bootstrap provider does not answer the last request
+	if bootstrapTokenRefreshUnanswered(config) {
+		return ctrl.Result{}, false, r.releaseUnansweredBootstrapTokenHold(ctx, infraClusterClient, vm)
+	}
+	if !bootstrapTokenRefreshPending(config) {
+		if err := r.requestBootstrapTokenRefresh(ctx, config); err != nil {
+			return ctrl.Result{}, false, err
+		}
+		if r.Recorder != nil {
+			r.Recorder.Eventf(ctx.KubevirtMachine, corev1.EventTypeNormal, bootstrapTokenRefreshRequestedReason,
+				"Join token expires at %s, requested a fresh join token from %s %s", expiry.UTC().Format(time.RFC3339), config.GetKind(), config.GetName())
+		}
+	}
+
+	if !kubevirt.IsHeld(vm) {
+		ctx.Logger.Info("Join token expires before the VM could join, holding the VM until it is refreshed", "expiry", expiry.Time)
+	}
+	if _, err := kubevirt.HoldVirtualMachine(ctx, infraClusterClient, vm); err != nil {
+		return ctrl.Result{}, true, err
+	}
+	ctx.KubevirtMachine.Status.Ready = false
+	conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.WaitingForBootstrapTokenReason, clusterv1.ConditionSeverityInfo,
+		"Join token expires at %s, holding the VM until the bootstrap provider refreshes it", expiry.UTC().Format(time.RFC3339))
+	return ctrl.Result{RequeueAfter: 20 * time.Second}, true, nil
+}
+
+// releaseBootstrapTokenHold starts the VM again if its start was held.
+func (r *KubevirtMachineReconciler) releaseBootstrapTokenHold(ctx *context.MachineContext, infraClusterClient client.Client, vm *kubevirtv1.VirtualMachine) error {
+	if !kubevirt.IsHeld(vm) {
+		return nil
+	}
+	if err := kubevirt.ReleaseVirtualMachine(ctx, infraClusterClient, vm); err != nil {
+		return err
+	}
+	ctx.Logger.Info("Join token refreshed, starting the held VM")
+	if r.Recorder != nil {
+		r.Recorder.Event(ctx.KubevirtMachine, corev1.EventTypeNormal, bootstrapTokenHoldReleasedReason, "Join token refreshed, starting the VM")
+	}
+	return nil
+}
+
+// releaseUnansweredBootstrapTokenHold starts the VM again if its start was held, when the bootstrap provider did not
+// answer the refresh request.
+func (r *KubevirtMachineReconciler) releaseUnansweredBootstrapTokenHold(ctx *context.MachineContext, infraClusterClient client.Client, vm *kubevirtv1.VirtualMachine) error {
+	if !kubevirt.IsHeld(vm) {
+		return nil
+	}
+	if err := kubevirt.ReleaseVirtualMachine(ctx, infraClusterClient, vm); err != nil {
+		return err
+	}
+	ctx.Logger.Info("Join token not refreshed in time, starting the held VM with its current token")
+	if r.Recorder != nil {
+		r.Recorder.Eventf(ctx.KubevirtMachine, corev1.EventTypeWarning, bootstrapTokenHoldReleasedReason,
+			"Join token not refreshed within %s, starting the VM with its current token", bootstrapTokenRefreshTimeout)
+	}
+	return nil
+}
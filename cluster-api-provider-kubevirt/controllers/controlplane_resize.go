@@ -0,0 +1,325 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
+)
+
+const (
+	// etcdClusterHealthyCondition is the condition KubeadmControlPlane reports the health of etcd with.
+	etcdClusterHealthyCondition clusterv1.ConditionType = "EtcdClusterHealthy"
+	// controlPlaneComponentsHealthyCondition is the condition KubeadmControlPlane reports the health of the static
+	// pods of the control plane with.
+	controlPlaneComponentsHealthyCondition clusterv1.ConditionType = "ControlPlaneComponentsHealthy"
+	// etcdMemberHealthyCondition and apiServerPodHealthyCondition are the conditions KubeadmControlPlane reports
+	// the health of the etcd member and the API server of a control plane Machine with.
+	etcdMemberHealthyCondition   clusterv1.ConditionType = "EtcdMemberHealthy"
+	apiServerPodHealthyCondition clusterv1.ConditionType = "APIServerPodHealthy"
+
+	controlPlaneResizeReason        = "ControlPlaneResize"
+	controlPlaneResizeBlockedReason = "ControlPlaneResizeBlocked"
+)
+
+// reconcileControlPlaneResize applies the CPU and memory of the templates the control plane machines were
+// cloned from to their VMs, one VM at a time. The new size is hotplugged into the running VM when the infra cluster
+// supports it and only the CPU sockets and the guest memory grow; otherwise, or when KubeVirt cannot apply it live,
+// the VM is restarted. A VM is only resized once the previous one is back, all the control plane machines are
+// ready, and etcd and the API servers are healthy. A VM is only restarted if etcd keeps its quorum without it.
+func (r *KubevirtClusterReconciler) reconcileControlPlaneResize(ctx *context.ClusterContext, infraClusterClient client.Client, infraClusterNamespace string) (ctrl.Result, error) {
+	kubevirtMachines := &infrav1.KubevirtMachineList{}
+	if err := r.Client.List(ctx, kubevirtMachines,
+		client.InNamespace(ctx.KubevirtCluster.Namespace),
+		client.MatchingLabels{clusterv1.ClusterNameLabel: ctx.Cluster.Name},
+		client.HasLabels{clusterv1.MachineControlPlaneLabel},
+	); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to list control plane KubevirtMachines")
+	}
+
+	vms := make([]*kubevirtv1.VirtualMachine, len(kubevirtMachines.Items))
+	for i := range kubevirtMachines.Items {
+		kubevirtMachine := &kubevirtMachines.Items[i]
+		vmNamespace := kubevirtMachine.Spec.VirtualMachineTemplate.ObjectMeta.Namespace
+		if vmNamespace == "" {
+			vmNamespace = infraClusterNamespace
+		}
+
+		vm := &kubevirtv1.VirtualMachine{}
+		if err := infraClusterClient.Get(ctx, client.ObjectKey{Namespace: vmNamespace, Name: kubevirtMachine.Name}, vm); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return ctrl.Result{}, errors.Wrapf(err, "failed to fetch VM %s/%s", vmNamespace, kubevirtMachine.Name)
+		}
+		vms[i] = vm
+
+		// Wait for the VM resized by a previous reconciliation to be back
+		previousVMIUID, resizing := kubevirtMachine.Annotations[infrav1.ResizedVMIAnnotation]
+		if !resizing {
+			continue
+		}
+		hotplugged, restartRequired, err := kubevirt.HotplugStatus(ctx, infraClusterClient, vm, previousVMIUID)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if restartRequired {
+			return r.restartResizedControlPlaneVM(ctx, infraClusterClient, kubevirtMachines.Items, kubevirtMachine, vm)
+		}
+		if !hotplugged {
+			restarted, err := kubevirt.IsRestarted(ctx, infraClusterClient, vm, previousVMIUID)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if !restarted {
+				ctx.Logger.Info("Waiting for the resized control plane VM to be back...", "machine", kubevirtMachine.Name)
+				return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+			}
+		}
+
+		patchBase := client.MergeFrom(kubevirtMachine.DeepCopy())
+		delete(kubevirtMachine.Annotations, infrav1.ResizedVMIAnnotation)
+		if err := r.Client.Patch(ctx, kubevirtMachine, patchBase); err != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to patch KubevirtMachine %s", kubevirtMachine.Name)
+		}
+		ctx.Logger.Info("Control plane VM resized", "machine", kubevirtMachine.Name, "hotplugged", hotplugged)
+	}
+
+	for i := range kubevirtMachines.Items {
+		kubevirtMachine := &kubevirtMachines.Items[i]
+		if vms[i] == nil {
+			continue
+		}
+
+		template, err := r.getClonedFromTemplate(ctx, kubevirtMachine)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if template == nil {
+			continue
+		}
+
+		compute := kubevirt.GetCompute(&template.Spec.Template.Spec.VirtualMachineTemplate.Spec)
+		currentCompute := kubevirt.GetCompute(&kubevirtMachine.Spec.VirtualMachineTemplate.Spec)
+		if compute.Equal(currentCompute) {
+			continue
+		}
+
+		if healthy, reason, err := r.isControlPlaneHealthy(ctx, kubevirtMachines.Items); err != nil {
+			return ctrl.Result{}, err
+		} else if !healthy {
+			ctx.Logger.Info("Waiting for the control plane to be healthy before resizing the next VM...", "reason", reason)
+			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		}
+
+		hotplug := kubevirt.IsHotpluggable(currentCompute, compute) && canHotplug(ctx.KubevirtCluster.Status.Infra)
+		if !hotplug {
+			if keeps, reason, err := r.keepsEtcdQuorum(ctx, kubevirtMachine.Name); err != nil {
+				return ctrl.Result{}, err
+			} else if !keeps {
+				return r.blockControlPlaneResize(ctx, kubevirtMachine, reason), nil
+			}
+		}
+
+		var previousVMIUID string
+		vmCompute := kubevirt.OvercommitCompute(compute, ctx.KubevirtCluster.Spec.MemoryOvercommit)
+		if hotplug {
+			ctx.Logger.Info("Hotplugging the new size into the control plane VM", "machine", kubevirtMachine.Name)
+			if r.Recorder != nil {
+				r.Recorder.Event(ctx.KubevirtCluster, corev1.EventTypeNormal, controlPlaneResizeReason, fmt.Sprintf("Hotplugging the new size into control plane machine %s", kubevirtMachine.Name))
+			}
+			previousVMIUID, err = kubevirt.HotplugVirtualMachine(ctx, infraClusterClient, vms[i], vmCompute)
+		} else {
+			ctx.Logger.Info("Resizing control plane VM", "machine", kubevirtMachine.Name)
+			if r.Recorder != nil {
+				r.Recorder.Event(ctx.KubevirtCluster, corev1.EventTypeNormal, controlPlaneResizeReason, fmt.Sprintf("Resizing control plane machine %s", kubevirtMachine.Name))
+			}
+			previousVMIUID, err = kubevirt.ResizeVirtualMachine(ctx, infraClusterClient, vms[i], vmCompute)
+		}
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
+		patchBase := client.MergeFrom(kubevirtMachine.DeepCopy())
+		kubevirt.SetCompute(&kubevirtMachine.Spec.VirtualMachineTemplate.Spec, compute)
+		if previousVMIUID != "" {
+			if kubevirtMachine.Annotations == nil {
+				kubevirtMachine.Annotations = map[string]string{}
+			}
+			kubevirtMachine.Annotations[infrav1.ResizedVMIAnnotation] = previousVMIUID
+		}
+		if err := r.Client.Patch(ctx, kubevirtMachine, patchBase); err != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to patch KubevirtMachine %s", kubevirtMachine.Name)
+		}
+
+		// Only one VM is resized at a time
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// restartResizedControlPlaneVM restarts a control plane VM whose new size KubeVirt could not hotplug, once the
+// control plane is healthy and etcd keeps its quorum without it.
+func (r *KubevirtClusterReconciler) restartResizedControlPlaneVM(ctx *context.ClusterContext, infraClusterClient client.Client, kubevirtMachines []infrav1.KubevirtMachine, kubevirtMachine *infrav1.KubevirtMachine, vm *kubevirtv1.VirtualMachine) (ctrl.Result, error) {
+	if healthy, reason, err := r.isControlPlaneHealthy(ctx, kubevirtMachines); err != nil {
+		return ctrl.Result{}, err
+	} else if !healthy {
+		ctx.Logger.Info("Waiting for the control plane to be healthy before restarting the resized VM...", "reason", reason)
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+	if keeps, reason, err := r.keepsEtcdQuorum(ctx, kubevirtMachine.Name); err != nil {
+		return ctrl.Result{}, err
+	} else if !keeps {
+		return r.blockControlPlaneResize(ctx, kubevirtMachine, reason), nil
+	}
+
+	ctx.Logger.Info("The new size cannot be hotplugged, restarting the control plane VM", "machine", kubevirtMachine.Name)
+	if r.Recorder != nil {
+		r.Recorder.Event(ctx.KubevirtCluster, corev1.EventTypeNormal, controlPlaneResizeReason, fmt.Sprintf("Restarting control plane machine %s, its new size cannot be hotplugged", kubevirtMachine.Name))
+	}
+	if _, err := kubevirt.RestartVirtualMachine(ctx, infraClusterClient, vm); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+}
+
+// blockControlPlaneResize reports a resize that would lose the etcd quorum, and waits for it to be safe.
+func (r *KubevirtClusterReconciler) blockControlPlaneResize(ctx *context.ClusterContext, kubevirtMachine *infrav1.KubevirtMachine, reason string) ctrl.Result {
+	ctx.Logger.Info("Not restarting the control plane VM to resize it, it would lose the etcd quorum", "machine", kubevirtMachine.Name, "reason", reason)
+	if r.Recorder != nil {
+		r.Recorder.Event(ctx.KubevirtCluster, corev1.EventTypeWarning, controlPlaneResizeBlockedReason,
+			fmt.Sprintf("Not restarting control plane machine %s to resize it: %s", kubevirtMachine.Name, reason))
+	}
+	return ctrl.Result{RequeueAfter: time.Minute}
+}
+
+// canHotplug returns true if the infra cluster is known to apply the new sizes of the VMs to their running VMIs.
+func canHotplug(infra *infrav1.InfraStatus) bool {
+	return infra != nil && infra.KubeVirtVersion != "" && kubevirt.IsInfraFeatureAvailable(infra, kubevirt.VMLiveUpdateFeature)
+}
+
+// keepsEtcdQuorum returns true if etcd keeps its quorum while the machine is restarted, i.e. if a majority of the
+// etcd members of the control plane Machines are healthy without it. Otherwise, it also returns the reason.
+// Control planes whose Machines do not report the health of their etcd member, e.g. with an external etcd, are
+// not checked.
+func (r *KubevirtClusterReconciler) keepsEtcdQuorum(ctx *context.ClusterContext, name string) (bool, string, error) {
+	machines, err := r.getMachinesByInfraName(ctx)
+	if err != nil {
+		return false, "", err
+	}
+
+	members, healthy := 0, 0
+	for infraName, machine := range machines {
+		if !util.IsControlPlaneMachine(machine) || conditions.Get(machine, etcdMemberHealthyCondition) == nil {
+			continue
+		}
+		members++
+		if infraName != name && conditions.IsTrue(machine, etcdMemberHealthyCondition) {
+			healthy++
+		}
+	}
+	if members == 0 || healthy >= members/2+1 {
+		return true, "", nil
+	}
+	return false, fmt.Sprintf("%d of the %d etcd members would be healthy without it, %d are needed for the quorum", healthy, members, members/2+1), nil
+}
+
+// getClonedFromTemplate returns the KubevirtMachineTemplate the KubevirtMachine was cloned from, or nil if it
+// was not cloned from a template or if the template does not allow in-place resize.
+func (r *KubevirtClusterReconciler) getClonedFromTemplate(ctx *context.ClusterContext, kubevirtMachine *infrav1.KubevirtMachine) (*infrav1.KubevirtMachineTemplate, error) {
+	templateName, found := kubevirtMachine.Annotations[clusterv1.TemplateClonedFromNameAnnotation]
+	if !found {
+		return nil, nil
+	}
+
+	template := &infrav1.KubevirtMachineTemplate{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: kubevirtMachine.Namespace, Name: templateName}, template); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to fetch KubevirtMachineTemplate %s/%s", kubevirtMachine.Namespace, templateName)
+	}
+
+	if template.Annotations[infrav1.InPlaceResizeAnnotation] != "true" {
+		return nil, nil
+	}
+
+	return template, nil
+}
+
+// isControlPlaneHealthy returns true if all the control plane machines are ready, and neither the control plane
+// nor its Machines report etcd or the API servers as unhealthy. Otherwise, it also returns the reason.
+func (r *KubevirtClusterReconciler) isControlPlaneHealthy(ctx *context.ClusterContext, kubevirtMachines []infrav1.KubevirtMachine) (bool, string, error) {
+	for _, kubevirtMachine := range kubevirtMachines {
+		if !kubevirtMachine.Status.Ready {
+			return false, fmt.Sprintf("machine %s is not ready", kubevirtMachine.Name), nil
+		}
+	}
+
+	machines, err := r.getMachinesByInfraName(ctx)
+	if err != nil {
+		return false, "", err
+	}
+	for _, kubevirtMachine := range kubevirtMachines {
+		machine := machines[kubevirtMachine.Name]
+		if machine == nil {
+			continue
+		}
+		if conditions.IsFalse(machine, etcdMemberHealthyCondition) {
+			return false, fmt.Sprintf("the etcd member of machine %s is not healthy", kubevirtMachine.Name), nil
+		}
+		if conditions.IsFalse(machine, apiServerPodHealthyCondition) {
+			return false, fmt.Sprintf("the API server of machine %s is not healthy", kubevirtMachine.Name), nil
+		}
+	}
+
+	controlPlaneRef := ctx.Cluster.Spec.ControlPlaneRef
+	if controlPlaneRef == nil {
+		return true, "", nil
+	}
+
+	controlPlane := &unstructured.Unstructured{}
+	controlPlane.SetGroupVersionKind(controlPlaneRef.GroupVersionKind())
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: controlPlaneRef.Namespace, Name: controlPlaneRef.Name}, controlPlane); err != nil {
+		return false, "", errors.Wrapf(err, "failed to fetch control plane %s/%s", controlPlaneRef.Namespace, controlPlaneRef.Name)
+	}
+
+	if conditions.IsFalse(conditions.UnstructuredGetter(controlPlane), etcdClusterHealthyCondition) {
+		return false, "etcd is not healthy", nil
+	}
+	if conditions.IsFalse(conditions.UnstructuredGetter(controlPlane), controlPlaneComponentsHealthyCondition) {
+		return false, "the control plane components are not healthy", nil
+	}
+
+	return true, "", nil
+}
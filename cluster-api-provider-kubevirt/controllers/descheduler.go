@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
+)
+
+// +kubebuilder:rbac:groups="",resources=pods,verbs=patch
+
+// deschedulerEvictionCheckInterval is the interval between two checks of whether the control plane can spare a VM
+// to the descheduler.
+const deschedulerEvictionCheckInterval = time.Minute
+
+// reconcileDeschedulerEviction annotates the virt-launcher pods of the VMs of the cluster according to its
+// descheduler eviction policy. The control plane VMs are only eligible while the control plane has other replicas,
+// all its machines are ready, etcd is healthy and none of its VMs is being migrated, so that the migrations the
+// descheduler triggers do not interrupt the API server traffic.
+func (r *KubevirtClusterReconciler) reconcileDeschedulerEviction(ctx *context.ClusterContext, infraClusterClient client.Client, infraClusterNamespace string) (ctrl.Result, error) {
+	policy := ctx.KubevirtCluster.Spec.DeschedulerEviction
+	if policy == "" {
+		return ctrl.Result{}, nil
+	}
+
+	kubevirtMachines := &infrav1.KubevirtMachineList{}
+	if err := r.Client.List(ctx, kubevirtMachines, client.InNamespace(ctx.KubevirtCluster.Namespace), client.MatchingLabels{clusterv1.ClusterNameLabel: ctx.Cluster.Name}); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to list KubevirtMachines")
+	}
+	machines, err := r.getMachinesByInfraName(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	var controlPlaneMachines []infrav1.KubevirtMachine
+	for _, kubevirtMachine := range kubevirtMachines.Items {
+		if machine := machines[kubevirtMachine.Name]; machine != nil && util.IsControlPlaneMachine(machine) {
+			controlPlaneMachines = append(controlPlaneMachines, kubevirtMachine)
+		}
+	}
+
+	controlPlaneAllowed := false
+	if policy == infrav1.AllowDeschedulerEviction && len(controlPlaneMachines) > 1 {
+		controlPlaneAllowed, err = r.canSpareControlPlaneVM(ctx, controlPlaneMachines)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	for i := range kubevirtMachines.Items {
+		kubevirtMachine := &kubevirtMachines.Items[i]
+		allowed := policy == infrav1.AllowDeschedulerEviction
+		if machine := machines[kubevirtMachine.Name]; machine != nil && util.IsControlPlaneMachine(machine) {
+			allowed = controlPlaneAllowed
+		}
+		if err := setLauncherPodsEviction(ctx, infraClusterClient, machineVMNamespace(kubevirtMachine, infraClusterNamespace), kubevirtMachine.Name, allowed); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if policy == infrav1.AllowDeschedulerEviction && len(controlPlaneMachines) > 1 {
+		return ctrl.Result{RequeueAfter: deschedulerEvictionCheckInterval}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// canSpareControlPlaneVM returns true if a control plane VM can be migrated without interrupting the API server
+// traffic: the control plane is healthy and none of its VMs is being migrated.
+func (r *KubevirtClusterReconciler) canSpareControlPlaneVM(ctx *context.ClusterContext, controlPlaneMachines []infrav1.KubevirtMachine) (bool, error) {
+	for _, kubevirtMachine := range controlPlaneMachines {
+		if vmi := kubevirtMachine.Status.VirtualMachineInstance; vmi != nil && (vmi.MigrationTargetNode != "" || vmi.Migration != nil) {
+			return false, nil
+		}
+	}
+	healthy, _, err := r.isControlPlaneHealthy(ctx, controlPlaneMachines)
+	return healthy, err
+}
+
+// setLauncherPodsEviction annotates the virt-launcher pods of the VM as eligible for, or protected from, the
+// evictions of the descheduler.
+func setLauncherPodsEviction(ctx *context.ClusterContext, infraClusterClient client.Client, namespace, name string, allowed bool) error {
+	pods := &corev1.PodList{}
+	if err := infraClusterClient.List(ctx, pods, client.InNamespace(namespace), client.MatchingLabels{
+		kubevirtv1.AppLabel:                "virt-launcher",
+		kubevirtv1.VirtualMachineNameLabel: name,
+	}); err != nil {
+		return errors.Wrapf(err, "failed to list the virt-launcher pods of VM %s/%s", namespace, name)
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		patchBase := client.MergeFrom(pod.DeepCopy())
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		if !kubevirt.SetDeschedulerEviction(pod.Annotations, allowed) {
+			continue
+		}
+		if err := infraClusterClient.Patch(ctx, pod, patchBase); err != nil {
+			return errors.Wrapf(err, "failed to annotate pod %s/%s", pod.Namespace, pod.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
+)
+
+const (
+	// diskEncryptionKeySecretKey is the key of the disk encryption secret of a cluster holding the LUKS key.
+	diskEncryptionKeySecretKey = "key"
+
+	// diskEncryptionKeyDir is the directory of the guests the LUKS key is written to at boot. It is in memory, so
+	// that the key is not stored on the unencrypted boot disk.
+	diskEncryptionKeyDir = "/run/capk"
+
+	// diskEncryptionCommand formats the disk of a serial number with LUKS unless already formatted, and opens it as
+	// /dev/mapper/<disk>.
+	diskEncryptionCommand = `dev=$(ls /dev/disk/by-id/*[-_]%[1]s | head -n 1) && [ -n "$dev" ] && ` +
+		`{ cryptsetup isLuks "$dev" || cryptsetup luksFormat --batch-mode "$dev" %[2]s; } && ` +
+		`{ [ -e /dev/mapper/%[3]s ] || cryptsetup open --key-file %[2]s "$dev" %[3]s; }`
+
+	// diskEncryptionCheckInterval is the interval between two checks of the disks of the VMs of a cluster against
+	// its disk encryption policy.
+	diskEncryptionCheckInterval = 5 * time.Minute
+)
+
+// diskEncryptionKeySecretName returns the name of the secret holding the key the guests of the cluster encrypt
+// their data disks with.
+func diskEncryptionKeySecretName(clusterName string) string {
+	return clusterName + "-disk-encryption"
+}
+
+// reconcileDiskEncryptionKey returns the key the guests of the cluster encrypt their data disks with. It is
+// generated once, in a secret owned by the KubevirtCluster.
+func (r *KubevirtMachineReconciler) reconcileDiskEncryptionKey(ctx *context.MachineContext) ([]byte, error) {
+	secret := &corev1.Secret{}
+	key := client.ObjectKey{Namespace: ctx.KubevirtCluster.Namespace, Name: diskEncryptionKeySecretName(ctx.Cluster.Name)}
+	if err := r.Client.Get(ctx, key, secret); err == nil {
+		if value := secret.Data[diskEncryptionKeySecretKey]; len(value) > 0 {
+			return value, nil
+		}
+		return nil, errors.Errorf("the disk encryption secret %s has no %s key", key.Name, diskEncryptionKeySecretKey)
+	} else if !apierrors.IsNotFound(err) {
+		return nil, errors.Wrapf(err, "failed to get the disk encryption secret %s", key.Name)
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return nil, errors.Wrap(err, "failed to generate the disk encryption key")
+	}
+	value := []byte(base64.StdEncoding.EncodeToString(random))
+
+	secret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: key.Namespace,
+			Name:      key.Name,
+			Labels:    map[string]string{clusterv1.ClusterNameLabel: ctx.Cluster.Name},
+		},
+		Data: map[string][]byte{diskEncryptionKeySecretKey: value},
+	}
+	if err := controllerutil.SetControllerReference(ctx.KubevirtCluster, secret, r.Client.Scheme()); err != nil {
+		return nil, err
+	}
+	// Another machine of the cluster may have generated it meanwhile; the next reconciliation reads it
+	if err := r.Client.Create(ctx, secret); err != nil {
+		return nil, errors.Wrapf(err, "failed to create the disk encryption secret %s", key.Name)
+	}
+	ctx.Logger.Info("Generated the disk encryption key of the cluster", "secret", key.Name)
+	return value, nil
+}
+
+// addDiskEncryptionToCloudInitConfig adds the commands opening the data disks encrypted by the guest to the machine
+// cloud-init bootstrap user-data. They run on every boot before the other modules, format the blank disks with
+// LUKS on the first one, and map the disks to /dev/mapper/<disk>. If the user-data is not the expected cloud-init
+// config, then returns the latter content as-is. The returned boolean indicates whether the userdata was modified.
+func addDiskEncryptionToCloudInitConfig(userdata, key []byte, disks []kubevirt.GuestEncryptedDisk) ([]byte, bool, error) {
+	root, data, err := parseCloudConfig(userdata)
+	if err != nil || data == nil || len(disks) == 0 {
+		return userdata, false, err
+	}
+
+	keyFile := diskEncryptionKeyDir + "/disk-encryption.key"
+	commands := []string{fmt.Sprintf("mkdir -p %s && (umask 077 && printf '%%s' '%s' > %s)", diskEncryptionKeyDir, key, keyFile)}
+	for _, disk := range disks {
+		commands = append(commands, fmt.Sprintf(diskEncryptionCommand, disk.Serial, keyFile, disk.Name))
+	}
+	commandsNode, err := yamlNode(commands)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to render disk encryption commands as valid yaml: %w", err)
+	}
+
+	if bootCmd := cloudConfigSequence(data, "bootcmd"); bootCmd != nil {
+		bootCmd.Content = append(commandsNode.Content, bootCmd.Content...)
+	} else {
+		data.Content = append(data.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "bootcmd"}, commandsNode)
+	}
+
+	ud, err := yaml.Marshal(root)
+	return ud, true, err
+}
+
+// reconcileDiskEncryptionStatus reports in the status of the cluster the disks of its VMs not encrypted as its disk
+// encryption policy requires. The disks are checked again after diskEncryptionCheckInterval.
+func (r *KubevirtClusterReconciler) reconcileDiskEncryptionStatus(ctx *context.ClusterContext, infraClusterClient client.Client, infraClusterNamespace string) error {
+	policy := ctx.KubevirtCluster.Spec.DiskEncryption
+	status := &ctx.KubevirtCluster.Status
+	if policy == nil {
+		status.DiskEncryption = nil
+		return nil
+	}
+	now := time.Now()
+	if status.DiskEncryption != nil && now.Sub(status.DiskEncryption.LastCheckTime.Time) < diskEncryptionCheckInterval {
+		return nil
+	}
+
+	kubevirtMachines := &infrav1.KubevirtMachineList{}
+	if err := r.Client.List(ctx, kubevirtMachines, client.InNamespace(ctx.KubevirtCluster.Namespace), client.MatchingLabels{clusterv1.ClusterNameLabel: ctx.Cluster.Name}); err != nil {
+		return errors.Wrap(err, "failed to list KubevirtMachines")
+	}
+
+	var unencrypted []string
+	for _, kubevirtMachine := range kubevirtMachines.Items {
+		vmNamespace := kubevirtMachine.Spec.VirtualMachineTemplate.ObjectMeta.Namespace
+		if vmNamespace == "" {
+			vmNamespace = infraClusterNamespace
+		}
+
+		vm := &kubevirtv1.VirtualMachine{}
+		if err := infraClusterClient.Get(ctx, client.ObjectKey{Namespace: vmNamespace, Name: kubevirtMachine.Name}, vm); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return errors.Wrapf(err, "failed to fetch VM %s/%s", vmNamespace, kubevirtMachine.Name)
+		}
+
+		volumes, err := kubevirt.UnencryptedDisks(ctx, infraClusterClient, vm, policy)
+		if err != nil {
+			return err
+		}
+		for _, volume := range volumes {
+			unencrypted = append(unencrypted, vm.Name+"/"+volume)
+		}
+	}
+
+	status.DiskEncryption = &infrav1.DiskEncryptionStatus{
+		Honored:          len(unencrypted) == 0,
+		UnencryptedDisks: unencrypted,
+		LastCheckTime:    metav1.Time{Time: now},
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	exportv1 "kubevirt.io/api/export/v1alpha1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
+)
+
+const (
+	// diskExportExpiredPhase is the phase of the disk exports removed at the end of their lifetime.
+	diskExportExpiredPhase = "Expired"
+
+	// The keys of the secret holding the token and the CA certificate of the downloads of a disk export.
+	diskExportTokenKey = "token"
+	diskExportCAKey    = "ca.crt"
+)
+
+// diskExportName returns the name of the VirtualMachineExport of the disks of the machine, and of the secret
+// holding its token in the namespace of the machine.
+func diskExportName(ctx *context.MachineContext) string {
+	return ctx.KubevirtMachine.Name + "-export"
+}
+
+// reconcileDiskExport exports the disks of the VM of a failed machine annotated with the export-disks annotation,
+// so that users can download them for offline analysis without access to the infra cluster. The VM is stopped,
+// a VirtualMachineExport of the infra cluster is created, and its external URLs, and the token they require, are
+// published in the status of the machine and in a secret of its namespace. An expired export is not recreated.
+// The export is removed, and the VM restored, once the annotation is removed. It returns true while the disks of
+// the machine are exported.
+func (r *KubevirtMachineReconciler) reconcileDiskExport(ctx *context.MachineContext, infraClusterClient client.Client, vmNamespace string) (ctrl.Result, bool, error) {
+	ttl, requested := ctx.KubevirtMachine.Annotations[infrav1.ExportDisksAnnotation]
+	if !requested {
+		if ctx.KubevirtMachine.Status.DiskExport == nil {
+			return ctrl.Result{}, false, nil
+		}
+		return ctrl.Result{}, false, r.removeDiskExport(ctx, infraClusterClient, vmNamespace)
+	}
+
+	status := ctx.KubevirtMachine.Status.DiskExport
+	if status == nil {
+		status = &infrav1.DiskExportStatus{}
+		ctx.KubevirtMachine.Status.DiskExport = status
+	}
+	if ctx.KubevirtMachine.Status.FailureReason == nil {
+		status.Message = "The disks are only exported once the machine failed"
+		return ctrl.Result{}, false, nil
+	}
+	if status.Phase == diskExportExpiredPhase {
+		return ctrl.Result{}, true, nil
+	}
+	var ttlDuration *metav1.Duration
+	if ttl != "" {
+		duration, err := time.ParseDuration(ttl)
+		if err != nil || duration <= 0 {
+			status.Message = "Invalid lifetime " + ttl + " of the export, expected a positive duration, e.g. 4h"
+			return ctrl.Result{}, false, nil
+		}
+		ttlDuration = &metav1.Duration{Duration: duration}
+	}
+
+	vm := &kubevirtv1.VirtualMachine{}
+	if err := infraClusterClient.Get(ctx, client.ObjectKey{Namespace: vmNamespace, Name: ctx.KubevirtMachine.Name}, vm); err != nil {
+		if apierrors.IsNotFound(err) {
+			status.Message = "The VM of the machine no longer exists"
+			return ctrl.Result{}, false, nil
+		}
+		return ctrl.Result{}, false, errors.Wrap(err, "failed to get the VM")
+	}
+	ctx.KubevirtMachine.Status.Ready = false
+	stopped, err := kubevirt.StopForDiskExport(ctx, infraClusterClient, vm)
+	if err != nil {
+		return ctrl.Result{}, true, err
+	}
+	if !stopped {
+		status.Message = "Stopping the VM to export its disks"
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, true, nil
+	}
+
+	export := &exportv1.VirtualMachineExport{}
+	key := client.ObjectKey{Namespace: vmNamespace, Name: diskExportName(ctx)}
+	if err := infraClusterClient.Get(ctx, key, export); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, true, errors.Wrap(err, "failed to get the export of the VM disks")
+		}
+		if status.ExpirationTime != nil && !status.ExpirationTime.After(time.Now()) {
+			ctx.Logger.Info("Export of the VM disks expired", "expiration", status.ExpirationTime.Time)
+			*status = infrav1.DiskExportStatus{Phase: diskExportExpiredPhase, Message: "The export expired, remove and set the annotation again to export the disks again"}
+			return ctrl.Result{}, true, r.deleteDiskExportTokenSecret(ctx)
+		}
+
+		export = &exportv1.VirtualMachineExport{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: key.Namespace,
+				Name:      key.Name,
+				Labels: map[string]string{
+					clusterv1.ClusterNameLabel:            ctx.Machine.Labels[clusterv1.ClusterNameLabel],
+					infrav1.KubevirtMachineNameLabel:      ctx.KubevirtMachine.Name,
+					infrav1.KubevirtMachineNamespaceLabel: ctx.KubevirtMachine.Namespace,
+				},
+			},
+			Spec: exportv1.VirtualMachineExportSpec{
+				Source:      corev1.TypedLocalObjectReference{APIGroup: &kubevirtv1.SchemeGroupVersion.Group, Kind: "VirtualMachine", Name: vm.Name},
+				TTLDuration: ttlDuration,
+			},
+		}
+		ctx.Logger.Info("Exporting the disks of the failed VM...")
+		if err := infraClusterClient.Create(ctx, export); err != nil {
+			return ctrl.Result{}, true, errors.Wrap(err, "failed to create the export of the VM disks")
+		}
+		*status = infrav1.DiskExportStatus{Phase: string(exportv1.Pending)}
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, true, nil
+	}
+
+	if export.Status == nil {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, true, nil
+	}
+	*status = infrav1.DiskExportStatus{
+		Phase:          string(export.Status.Phase),
+		ExpirationTime: export.Status.TTLExpirationTime,
+	}
+	if export.Status.Phase != exportv1.Ready {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, true, nil
+	}
+
+	status.Volumes = kubevirt.ExportedVolumes(export)
+	if len(status.Volumes) == 0 {
+		status.Message = "The export has no external link, the export proxy of KubeVirt has to be exposed outside the infra cluster"
+	} else if err := r.reconcileDiskExportTokenSecret(ctx, infraClusterClient, export); err != nil {
+		return ctrl.Result{}, true, err
+	} else {
+		status.TokenSecretName = diskExportName(ctx)
+	}
+
+	// Come back once the export expired, to report it
+	if status.ExpirationTime != nil {
+		return ctrl.Result{RequeueAfter: time.Until(status.ExpirationTime.Time) + time.Second}, true, nil
+	}
+	return ctrl.Result{}, true, nil
+}
+
+// reconcileDiskExportTokenSecret copies the token of the export, and the CA certificate of its external links, to
+// the secret of the export in the namespace of the machine.
+func (r *KubevirtMachineReconciler) reconcileDiskExportTokenSecret(ctx *context.MachineContext, infraClusterClient client.Client, export *exportv1.VirtualMachineExport) error {
+	if export.Status.TokenSecretRef == nil {
+		return errors.Errorf("export %s has no token secret", export.Name)
+	}
+	token := &corev1.Secret{}
+	if err := infraClusterClient.Get(ctx, client.ObjectKey{Namespace: export.Namespace, Name: *export.Status.TokenSecretRef}, token); err != nil {
+		return errors.Wrapf(err, "failed to get the token secret of export %s", export.Name)
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: ctx.KubevirtMachine.Namespace,
+			Name:      diskExportName(ctx),
+		},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		if secret.Labels == nil {
+			secret.Labels = map[string]string{}
+		}
+		secret.Labels[clusterv1.ClusterNameLabel] = ctx.Machine.Labels[clusterv1.ClusterNameLabel]
+		secret.Data = map[string][]byte{
+			diskExportTokenKey: token.Data[diskExportTokenKey],
+			diskExportCAKey:    []byte(export.Status.Links.External.Cert),
+		}
+		return controllerutil.SetControllerReference(ctx.KubevirtMachine, secret, r.Client.Scheme())
+	}); err != nil {
+		return errors.Wrapf(err, "failed to store the token of the export in secret %s", secret.Name)
+	}
+	return nil
+}
+
+// removeDiskExport deletes the export of the disks of the machine and its token secret, restores the run strategy of
+// the VM, and clears the state of the export.
+func (r *KubevirtMachineReconciler) removeDiskExport(ctx *context.MachineContext, infraClusterClient client.Client, vmNamespace string) error {
+	if err := deleteDiskExport(ctx, infraClusterClient, vmNamespace); err != nil {
+		return err
+	}
+	if err := r.deleteDiskExportTokenSecret(ctx); err != nil {
+		return err
+	}
+
+	vm := &kubevirtv1.VirtualMachine{}
+	if err := infraClusterClient.Get(ctx, client.ObjectKey{Namespace: vmNamespace, Name: ctx.KubevirtMachine.Name}, vm); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrap(err, "failed to get the VM")
+		}
+	} else if err := kubevirt.RestoreAfterDiskExport(ctx, infraClusterClient, vm); err != nil {
+		return err
+	}
+
+	ctx.Logger.Info("Removed the export of the VM disks")
+	ctx.KubevirtMachine.Status.DiskExport = nil
+	return nil
+}
+
+// deleteDiskExport deletes the export of the disks of the machine, if any.
+func deleteDiskExport(ctx *context.MachineContext, infraClusterClient client.Client, vmNamespace string) error {
+	export := &exportv1.VirtualMachineExport{
+		ObjectMeta: metav1.ObjectMeta{Namespace: vmNamespace, Name: diskExportName(ctx)},
+	}
+	if err := infraClusterClient.Delete(ctx, export); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed to delete the export of the VM disks")
+	}
+	return nil
+}
+
+// deleteDiskExportTokenSecret deletes the secret holding the token of the export of the disks of the machine.
+func (r *KubevirtMachineReconciler) deleteDiskExportTokenSecret(ctx *context.MachineContext) error {
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: ctx.KubevirtMachine.Namespace, Name: diskExportName(ctx)}, secret); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(secret, ctx.KubevirtMachine) {
+		return nil
+	}
+	if err := r.Client.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete the token secret %s of the export", secret.Name)
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	snapshotv1 "kubevirt.io/api/snapshot/v1alpha1"
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+// diskRetentionPolicy returns the disk retention policy of the cluster of the machine, or the Delete policy if the
+// cluster is already gone.
+func (r *KubevirtMachineReconciler) diskRetentionPolicy(ctx *context.MachineContext) (infrav1.DiskRetentionPolicy, error) {
+	cluster, err := util.GetClusterFromMetadata(ctx, r.Client, ctx.Machine.ObjectMeta)
+	if err != nil {
+		if errors.Is(err, util.ErrNoCluster) || apierrors.IsNotFound(errors.Cause(err)) {
+			return infrav1.DeleteDiskRetentionPolicy, nil
+		}
+		return "", errors.Wrap(err, "failed to get the cluster of the machine")
+	}
+	if cluster.Spec.InfrastructureRef == nil {
+		return infrav1.DeleteDiskRetentionPolicy, nil
+	}
+
+	kubevirtCluster := &infrav1.KubevirtCluster{}
+	key := client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Spec.InfrastructureRef.Name}
+	if err := r.Client.Get(ctx, key, kubevirtCluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return infrav1.DeleteDiskRetentionPolicy, nil
+		}
+		return "", errors.Wrap(err, "failed to get the KubevirtCluster of the machine")
+	}
+	if kubevirtCluster.Spec.DiskRetentionPolicy == "" {
+		return infrav1.DeleteDiskRetentionPolicy, nil
+	}
+	return kubevirtCluster.Spec.DiskRetentionPolicy, nil
+}
+
+// applyDiskRetentionPolicy prepares the disks of the VM of a deleted machine to its deletion according to the disk
+// retention policy of the cluster, and returns whether the VM can be deleted. The disks outliving the VM, i.e. the
+// PVCs it uses without owning them, and the retained DataVolumes, are labeled to be reported as orphaned.
+func (r *KubevirtMachineReconciler) applyDiskRetentionPolicy(ctx *context.MachineContext, infraClusterClient client.Client, vmNamespace string) (bool, error) {
+	vm := &kubevirtv1.VirtualMachine{}
+	if err := infraClusterClient.Get(ctx, client.ObjectKey{Namespace: vmNamespace, Name: ctx.KubevirtMachine.Name}, vm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, errors.Wrap(err, "failed to get the VM")
+	}
+
+	policy, err := r.diskRetentionPolicy(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if policy == infrav1.SnapshotThenDeleteDiskRetentionPolicy {
+		snapshotted, err := snapshotVM(ctx, infraClusterClient, vm)
+		if err != nil || !snapshotted {
+			return false, err
+		}
+	}
+
+	if vm.Spec.Template != nil {
+		for _, volume := range vm.Spec.Template.Spec.Volumes {
+			if volume.PersistentVolumeClaim != nil {
+				if err := labelOrphanedVolume(ctx, infraClusterClient, vmNamespace, volume.PersistentVolumeClaim.ClaimName); err != nil {
+					return false, err
+				}
+			}
+		}
+	}
+
+	if policy == infrav1.RetainDiskRetentionPolicy {
+		for _, template := range vm.Spec.DataVolumeTemplates {
+			if err := retainDataVolume(ctx, infraClusterClient, vm, template.Name); err != nil {
+				return false, err
+			}
+			if err := labelOrphanedVolume(ctx, infraClusterClient, vmNamespace, template.Name); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	return true, nil
+}
+
+// snapshotVM takes a snapshot of the VM named after it, and returns whether it completed.
+func snapshotVM(ctx *context.MachineContext, infraClusterClient client.Client, vm *kubevirtv1.VirtualMachine) (bool, error) {
+	snapshot := &snapshotv1.VirtualMachineSnapshot{}
+	key := client.ObjectKey{Namespace: vm.Namespace, Name: vm.Name + "-final"}
+	if err := infraClusterClient.Get(ctx, key, snapshot); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, errors.Wrap(err, "failed to get the snapshot of the VM")
+		}
+		snapshot = &snapshotv1.VirtualMachineSnapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: key.Namespace,
+				Name:      key.Name,
+				Labels: map[string]string{
+					clusterv1.ClusterNameLabel:            ctx.Machine.Labels[clusterv1.ClusterNameLabel],
+					infrav1.KubevirtMachineNameLabel:      ctx.KubevirtMachine.Name,
+					infrav1.KubevirtMachineNamespaceLabel: ctx.KubevirtMachine.Namespace,
+				},
+			},
+			Spec: snapshotv1.VirtualMachineSnapshotSpec{
+				Source: corev1.TypedLocalObjectReference{APIGroup: &kubevirtv1.SchemeGroupVersion.Group, Kind: "VirtualMachine", Name: vm.Name},
+			},
+		}
+		ctx.Logger.Info("Taking a snapshot of the VM before deleting it...")
+		if err := infraClusterClient.Create(ctx, snapshot); err != nil {
+			return false, errors.Wrap(err, "failed to create the snapshot of the VM")
+		}
+		return false, nil
+	}
+
+	if snapshot.Status == nil {
+		return false, nil
+	}
+	if snapshot.Status.Phase == snapshotv1.Failed {
+		message := "unknown error"
+		if snapshot.Status.Error != nil && snapshot.Status.Error.Message != nil {
+			message = *snapshot.Status.Error.Message
+		}
+		return false, errors.Errorf("snapshot %s of the VM failed: %s, delete it to retry", snapshot.Name, message)
+	}
+	return snapshot.Status.ReadyToUse != nil && *snapshot.Status.ReadyToUse, nil
+}
+
+// retainDataVolume removes the owner reference of the DataVolume to the VM, so that it is not deleted with the VM.
+func retainDataVolume(ctx *context.MachineContext, infraClusterClient client.Client, vm *kubevirtv1.VirtualMachine, name string) error {
+	dataVolume := &cdiv1.DataVolume{}
+	if err := infraClusterClient.Get(ctx, client.ObjectKey{Namespace: vm.Namespace, Name: name}, dataVolume); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get DataVolume %s", name)
+	}
+
+	var ownerReferences []metav1.OwnerReference
+	for _, ownerReference := range dataVolume.OwnerReferences {
+		if ownerReference.UID != vm.UID {
+			ownerReferences = append(ownerReferences, ownerReference)
+		}
+	}
+	if len(ownerReferences) == len(dataVolume.OwnerReferences) {
+		return nil
+	}
+	dataVolume.OwnerReferences = ownerReferences
+	if err := infraClusterClient.Update(ctx, dataVolume); err != nil {
+		return errors.Wrapf(err, "failed to retain DataVolume %s", name)
+	}
+	return nil
+}
+
+// labelOrphanedVolume labels a PVC outliving the VM of the machine with the names of the cluster and the machine.
+func labelOrphanedVolume(ctx *context.MachineContext, infraClusterClient client.Client, namespace, name string) error {
+	volume := &corev1.PersistentVolumeClaim{}
+	if err := infraClusterClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, volume); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get PVC %s", name)
+	}
+
+	labels := volume.GetLabels()
+	if labels[infrav1.OrphanedFromMachineLabel] == ctx.KubevirtMachine.Name {
+		return nil
+	}
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[clusterv1.ClusterNameLabel] = ctx.Machine.Labels[clusterv1.ClusterNameLabel]
+	labels[infrav1.OrphanedFromMachineLabel] = ctx.KubevirtMachine.Name
+	volume.SetLabels(labels)
+	if err := infraClusterClient.Update(ctx, volume); err != nil {
+		return errors.Wrapf(err, "failed to label PVC %s", name)
+	}
+	return nil
+}
+
+// reconcileOrphanedVolumes reports the volumes of the deleted machines of the cluster left in the infra cluster.
+func (r *KubevirtClusterReconciler) reconcileOrphanedVolumes(ctx *context.ClusterContext, infraClusterClient client.Client, infraClusterNamespace string) error {
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	if err := infraClusterClient.List(ctx, pvcs,
+		client.InNamespace(infraClusterNamespace),
+		client.MatchingLabels{clusterv1.ClusterNameLabel: ctx.Cluster.Name},
+		client.HasLabels{infrav1.OrphanedFromMachineLabel},
+	); err != nil {
+		return errors.Wrap(err, "failed to list the orphaned volumes")
+	}
+
+	var orphanedVolumes []infrav1.OrphanedVolume
+	for _, pvc := range pvcs.Items {
+		orphanedVolume := infrav1.OrphanedVolume{Name: pvc.Name, Machine: pvc.Labels[infrav1.OrphanedFromMachineLabel]}
+		if capacity, found := pvc.Status.Capacity[corev1.ResourceStorage]; found {
+			orphanedVolume.Capacity = capacity.String()
+		}
+		orphanedVolumes = append(orphanedVolumes, orphanedVolume)
+	}
+	ctx.KubevirtCluster.Status.OrphanedVolumes = orphanedVolumes
+	return nil
+}
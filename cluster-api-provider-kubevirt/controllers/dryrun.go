@@ -0,0 +1,50 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+// isDryRun returns true if the KubevirtCluster is annotated for plan-only reconciliation.
+func isDryRun(kubevirtCluster *infrav1.KubevirtCluster) bool {
+	if kubevirtCluster == nil {
+		return false
+	}
+	value, found := kubevirtCluster.Annotations[infrav1.DryRunAnnotation]
+	if !found {
+		return false
+	}
+	dryRun, err := strconv.ParseBool(value)
+	return err == nil && dryRun
+}
+
+// recordPlannedChange publishes an event on obj describing a change to an infra object that was skipped
+// because of dry-run.
+func recordPlannedChange(recorder record.EventRecorder, obj runtime.Object, verb, kind, namespace, name string) {
+	if recorder == nil {
+		return
+	}
+	recorder.Event(obj, corev1.EventTypeNormal, infrav1.DryRunReason, fmt.Sprintf("Would %s %s %s/%s", verb, kind, namespace, name))
+}
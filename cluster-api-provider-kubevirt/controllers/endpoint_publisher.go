@@ -0,0 +1,46 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/loadbalancer"
+)
+
+// addKubeVIPToCloudInitConfig adds the kube-vip static pod manifest to the cloud-init bootstrap user-data of a
+// control plane machine, so that kubelet starts it along with the control plane. If the user-data is not the
+// expected cloud-init config, then returns the latter content as-is. The returned boolean indicates whether the
+// userdata was modified or not.
+func addKubeVIPToCloudInitConfig(userdata, manifest []byte) ([]byte, bool, error) {
+	root, data, err := parseCloudConfig(userdata)
+	if err != nil || data == nil {
+		return userdata, false, err
+	}
+
+	files := []cloudInitFile{
+		{Path: loadbalancer.KubeVIPManifestPath, Owner: "root:root", Permissions: "0644", Content: string(manifest)},
+	}
+	if err := appendCloudInitFiles(data, files); err != nil {
+		return nil, false, fmt.Errorf("failed to render kube-vip manifest as valid yaml: %w", err)
+	}
+
+	ud, err := yaml.Marshal(root)
+	return ud, true, err
+}
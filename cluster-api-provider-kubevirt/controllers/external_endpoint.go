@@ -0,0 +1,260 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/loadbalancer"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/ownership"
+)
+
+// kubeadmConfigPath is the path of the kubeadm configuration in the cloud-init bootstrap user-data of the
+// control plane machines.
+const kubeadmConfigPath = "/run/kubeadm/kubeadm.yaml"
+
+// externalControlPlaneEndpointNamespace returns the namespace of the service publishing the external endpoint of
+// the control plane.
+func externalControlPlaneEndpointNamespace(kc *infrav1.KubevirtCluster, loadBalancerNamespace string) string {
+	if external := kc.Spec.ExternalControlPlaneEndpoint; external != nil && external.ServiceTemplate.ObjectMeta.Namespace != "" {
+		return external.ServiceTemplate.ObjectMeta.Namespace
+	}
+	return loadBalancerNamespace
+}
+
+// reconcileExternalControlPlaneEndpoint publishes the external endpoint of the control plane, and reports it in
+// status.externalControlPlaneEndpoint. The service publishing it is deleted when the endpoint is disabled, or set
+// manually.
+func (r *KubevirtClusterReconciler) reconcileExternalControlPlaneEndpoint(ctx *context.ClusterContext, infraClusterClient client.Client, loadBalancerNamespace string) (ctrl.Result, error) {
+	spec := ctx.KubevirtCluster.Spec.ExternalControlPlaneEndpoint
+	if spec == nil || spec.Host != "" {
+		if err := r.deleteExternalControlPlaneEndpoint(ctx, infraClusterClient, loadBalancerNamespace); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if spec == nil {
+		ctx.KubevirtCluster.Status.ExternalControlPlaneEndpoint = nil
+		conditions.Delete(ctx.KubevirtCluster, infrav1.ExternalControlPlaneEndpointAvailableCondition)
+		return ctrl.Result{}, nil
+	}
+
+	port := spec.Port
+	if port == 0 {
+		port = 6443
+	}
+
+	host := spec.Host
+	if host == "" {
+		externalLoadBalancer, err := loadbalancer.NewExternalLoadBalancer(ctx, infraClusterClient, externalControlPlaneEndpointNamespace(ctx.KubevirtCluster, loadBalancerNamespace))
+		if err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to create helper for managing the external endpoint service")
+		}
+		if !externalLoadBalancer.IsFound() {
+			if err := externalLoadBalancer.Create(ctx); err != nil {
+				conditions.MarkFalse(ctx.KubevirtCluster, infrav1.ExternalControlPlaneEndpointAvailableCondition, infrav1.LoadBalancerProvisioningFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+				return ctrl.Result{}, errors.Wrap(err, "failed to create the external endpoint service")
+			}
+		}
+		_, name := externalLoadBalancer.Object()
+		if err := r.reconcileServiceMetadata(ctx, infraClusterClient, externalControlPlaneEndpointNamespace(ctx.KubevirtCluster, loadBalancerNamespace), name); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		if spec.ServiceTemplate.Spec.Type == corev1.ServiceTypeClusterIP {
+			host, err = externalLoadBalancer.IP(ctx)
+		} else {
+			host, err = externalLoadBalancer.ExternalIP(ctx)
+		}
+		if err != nil {
+			// Cloud load balancers usually take a while to get an address
+			conditions.MarkFalse(ctx.KubevirtCluster, infrav1.ExternalControlPlaneEndpointAvailableCondition, infrav1.WaitingForExternalAddressReason, clusterv1.ConditionSeverityInfo, err.Error())
+			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		}
+	}
+
+	ctx.KubevirtCluster.Status.ExternalControlPlaneEndpoint = &infrav1.APIEndpoint{Host: host, Port: int(port)}
+	conditions.MarkTrue(ctx.KubevirtCluster, infrav1.ExternalControlPlaneEndpointAvailableCondition)
+	return ctrl.Result{}, nil
+}
+
+// deleteExternalControlPlaneEndpoint deletes the service publishing the external endpoint of the control plane,
+// if any.
+func (r *KubevirtClusterReconciler) deleteExternalControlPlaneEndpoint(ctx *context.ClusterContext, infraClusterClient client.Client, loadBalancerNamespace string) error {
+	externalLoadBalancer, err := loadbalancer.NewExternalLoadBalancer(ctx, infraClusterClient, externalControlPlaneEndpointNamespace(ctx.KubevirtCluster, loadBalancerNamespace))
+	if err != nil {
+		return errors.Wrap(err, "failed to create helper for managing the external endpoint service")
+	}
+	if err := externalLoadBalancer.Delete(ctx); ownership.IsNotOwned(err) {
+		// A service of the user merely named like the external endpoint service is none of ours
+		ctx.Logger.V(4).Info("Leaving the external endpoint service in place", "reason", err.Error())
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// externalCertSANs returns the subject alternative names the API server certificate needs for the external
+// endpoint of the control plane, or nil if it is disabled. It fails while the external endpoint is not published,
+// as the certificate of the first control plane machine would miss it.
+func externalCertSANs(kc *infrav1.KubevirtCluster) ([]string, error) {
+	spec := kc.Spec.ExternalControlPlaneEndpoint
+	if spec == nil {
+		return nil, nil
+	}
+
+	host := spec.Host
+	if host == "" {
+		if kc.Status.ExternalControlPlaneEndpoint == nil {
+			return nil, errors.New("waiting for the external control plane endpoint to be published")
+		}
+		host = kc.Status.ExternalControlPlaneEndpoint.Host
+	}
+	return append([]string{host}, spec.CertSANs...), nil
+}
+
+// addCertSANsToCloudInitConfig adds the subject alternative names to the API server certificate configured in the
+// ClusterConfiguration of the kubeadm configuration written by the cloud-init bootstrap user-data.
+// If the user-data is not the expected cloud-init config, or does not write a kubeadm configuration, then returns
+// the latter content as-is. The returned boolean indicates whether the userdata was modified or not.
+func addCertSANsToCloudInitConfig(userdata []byte, sans []string) ([]byte, bool, error) {
+	root, data, err := parseCloudConfig(userdata)
+	if err != nil || data == nil || len(sans) == 0 {
+		return userdata, false, err
+	}
+
+	writeFiles := cloudConfigSequence(data, "write_files")
+	if writeFiles == nil {
+		return userdata, false, nil
+	}
+
+	modified := false
+	for _, file := range writeFiles.Content {
+		path, content := mappingValue(file, "path"), mappingValue(file, "content")
+		if path == nil || content == nil || path.Value != kubeadmConfigPath {
+			continue
+		}
+		config, configModified, err := addCertSANsToKubeadmConfig(content.Value, sans)
+		if err != nil {
+			return nil, false, err
+		}
+		if configModified {
+			content.Value = config
+			content.Style = yaml.LiteralStyle
+			modified = true
+		}
+	}
+	if !modified {
+		return userdata, false, nil
+	}
+
+	ud, err := yaml.Marshal(root)
+	return ud, true, err
+}
+
+// addCertSANsToKubeadmConfig adds the subject alternative names missing from apiServer.certSANs of the
+// ClusterConfiguration document of a kubeadm configuration.
+func addCertSANsToKubeadmConfig(config string, sans []string) (string, bool, error) {
+	var documents []*yaml.Node
+	decoder := yaml.NewDecoder(strings.NewReader(config))
+	for {
+		document := &yaml.Node{}
+		if err := decoder.Decode(document); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return "", false, fmt.Errorf("failed to parse the kubeadm configuration: %w", err)
+		}
+		documents = append(documents, document)
+	}
+
+	modified := false
+	for _, document := range documents {
+		if len(document.Content) != 1 {
+			continue
+		}
+		clusterConfiguration := document.Content[0]
+		if kind := mappingValue(clusterConfiguration, "kind"); kind == nil || kind.Value != "ClusterConfiguration" {
+			continue
+		}
+
+		apiServer := mappingValue(clusterConfiguration, "apiServer")
+		if apiServer == nil {
+			apiServer = &yaml.Node{Kind: yaml.MappingNode}
+			clusterConfiguration.Content = append(clusterConfiguration.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "apiServer"}, apiServer)
+		}
+		certSANs := mappingValue(apiServer, "certSANs")
+		if certSANs == nil {
+			certSANs = &yaml.Node{Kind: yaml.SequenceNode}
+			apiServer.Content = append(apiServer.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "certSANs"}, certSANs)
+		}
+
+		existing := map[string]bool{}
+		for _, san := range certSANs.Content {
+			existing[san.Value] = true
+		}
+		for _, san := range sans {
+			if !existing[san] {
+				certSANs.Content = append(certSANs.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: san})
+				existing[san] = true
+				modified = true
+			}
+		}
+	}
+	if !modified {
+		return config, false, nil
+	}
+
+	var b strings.Builder
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	for _, document := range documents {
+		if err := encoder.Encode(document); err != nil {
+			return "", false, fmt.Errorf("failed to render the kubeadm configuration: %w", err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return "", false, fmt.Errorf("failed to render the kubeadm configuration: %w", err)
+	}
+	return b.String(), true, nil
+}
+
+// mappingValue returns the value of the given key of a yaml mapping, or nil if the node is not a mapping or the key
+// is not defined.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
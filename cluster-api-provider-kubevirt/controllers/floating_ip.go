@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/loadbalancer"
+)
+
+// reconcileControlPlaneFloatingIP claims the floating IP of the control plane endpoint, and sets it as the host of
+// the endpoint once it is allocated. The floating IP is NATed to the endpoint published by the service or the load
+// balancer object of the cluster, if any.
+func (r *KubevirtClusterReconciler) reconcileControlPlaneFloatingIP(ctx *context.ClusterContext, publisher loadbalancer.Publisher, infraClusterClient client.Client, loadBalancerNamespace string) (ctrl.Result, error) {
+	target := ""
+	if kind, _ := publisher.Object(); kind != "" {
+		endpoint, err := publisher.Endpoint(ctx)
+		if err != nil {
+			conditions.MarkFalse(ctx.KubevirtCluster, infrav1.LoadBalancerAvailableCondition, infrav1.LoadBalancerProvisioningFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return ctrl.Result{}, errors.Wrap(err, "failed to get the address of the load balancer")
+		}
+		target = endpoint.Host
+	}
+
+	floatingIP, err := loadbalancer.NewFloatingIP(ctx, infraClusterClient, loadBalancerNamespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	address, err := floatingIP.Address(ctx, target)
+	if err != nil {
+		conditions.MarkFalse(ctx.KubevirtCluster, infrav1.LoadBalancerAvailableCondition, infrav1.LoadBalancerProvisioningFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+		return ctrl.Result{}, err
+	}
+	if address == "" {
+		ctx.Logger.Info("Waiting for the floating IP of the control plane endpoint to be allocated...")
+		conditions.MarkFalse(ctx.KubevirtCluster, infrav1.LoadBalancerAvailableCondition, infrav1.WaitingForFloatingIPReason, clusterv1.ConditionSeverityInfo, "")
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+
+	ctx.KubevirtCluster.Spec.ControlPlaneEndpoint = infrav1.APIEndpoint{Host: address, Port: 6443}
+	return ctrl.Result{}, nil
+}
+
+// releaseControlPlaneFloatingIP releases the floating IP of the control plane endpoint of a deleted cluster, if
+// any, so that no NAT rule is left to its former target.
+func (r *KubevirtClusterReconciler) releaseControlPlaneFloatingIP(ctx *context.ClusterContext, infraClusterClient client.Client, loadBalancerNamespace string) error {
+	if ctx.KubevirtCluster.Spec.ControlPlaneFloatingIP == nil {
+		return nil
+	}
+
+	floatingIP, err := loadbalancer.NewFloatingIP(ctx, infraClusterClient, loadBalancerNamespace)
+	if err != nil {
+		return err
+	}
+	return floatingIP.Release(ctx)
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
+)
+
+// reconcileGuestAgentCondition reports whether the guest agent of the VMI of the machine is connected, from the
+// mirrored state of the VMI, once the VMI exists. The machines requiring the guest agent are reported with Severity=Error once their
+// running VMI did not connect it within the connect timeout, e.g. because the image lacks it.
+func reconcileGuestAgentCondition(ctx *context.MachineContext, now time.Time) {
+	kubevirtMachine := ctx.KubevirtMachine
+	mode := kubevirt.GuestAgentMode(kubevirtMachine)
+	vmi := kubevirtMachine.Status.VirtualMachineInstance
+	if mode == infrav1.GuestAgentDisabled || vmi == nil {
+		conditions.Delete(kubevirtMachine, infrav1.GuestAgentConnectedCondition)
+		return
+	}
+
+	switch {
+	case vmi.AgentConnected:
+		conditions.MarkTrue(kubevirtMachine, infrav1.GuestAgentConnectedCondition)
+	case vmi.Phase != kubevirtv1.Running || vmi.RunningTime == nil:
+		conditions.MarkFalse(kubevirtMachine, infrav1.GuestAgentConnectedCondition, infrav1.WaitingForGuestAgentReason, clusterv1.ConditionSeverityInfo, "VMI is not running")
+	case mode != infrav1.GuestAgentRequired:
+		conditions.MarkFalse(kubevirtMachine, infrav1.GuestAgentConnectedCondition, infrav1.AgentNotConnectedReason, clusterv1.ConditionSeverityInfo, "")
+	case now.Sub(vmi.RunningTime.Time) <= kubevirt.GuestAgentConnectTimeout(kubevirtMachine):
+		conditions.MarkFalse(kubevirtMachine, infrav1.GuestAgentConnectedCondition, infrav1.WaitingForGuestAgentReason, clusterv1.ConditionSeverityInfo, "")
+	default:
+		conditions.MarkFalse(kubevirtMachine, infrav1.GuestAgentConnectedCondition, infrav1.AgentNotConnectedReason, clusterv1.ConditionSeverityError,
+			fmt.Sprintf("guest agent did not connect within %s of the start of the VMI, check that the image runs the qemu-guest-agent", kubevirt.GuestAgentConnectTimeout(kubevirtMachine)))
+	}
+}
+
+// guestAgentNotConnected returns true if the machine requires the guest agent and it did not connect within its
+// connect timeout.
+func guestAgentNotConnected(kubevirtMachine *infrav1.KubevirtMachine) bool {
+	condition := conditions.Get(kubevirtMachine, infrav1.GuestAgentConnectedCondition)
+	return condition != nil && condition.Reason == infrav1.AgentNotConnectedReason && condition.Severity == clusterv1.ConditionSeverityError
+}
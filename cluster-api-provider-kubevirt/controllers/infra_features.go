@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
+)
+
+const (
+	// infraStatusCheckInterval is the minimum interval between two checks of the versions and the feature gates of
+	// the infra cluster.
+	infraStatusCheckInterval = 10 * time.Minute
+
+	unsupportedInfraVersionsReason = "UnsupportedInfraVersions"
+)
+
+var (
+	// startTime makes the first reconciliation of each cluster after a restart of the controller check the infra
+	// cluster again, since it may have been upgraded meanwhile. It is truncated like the serialized check times.
+	startTime = time.Now().Truncate(time.Second)
+
+	infraInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "capk_infra_info",
+		Help: "Versions of KubeVirt and CDI in the infra cluster of a KubevirtCluster, empty when unknown.",
+	}, []string{"namespace", "cluster", "kubevirt_version", "cdi_version"})
+
+	infraFeatureAvailable = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "capk_infra_feature_available",
+		Help: "Whether a feature of the provider depending on the infra cluster of a KubevirtCluster is available.",
+	}, []string{"namespace", "cluster", "feature"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(infraInfo, infraFeatureAvailable)
+}
+
+// reconcileInfraStatus reports the versions and the feature gates of KubeVirt and CDI in the infra cluster in the
+// status and the metrics of the cluster, and whether the provider supports these versions in the InfraCompatible
+// condition. The check is refreshed by the reconciliations of the cluster.
+func (r *KubevirtClusterReconciler) reconcileInfraStatus(ctx *context.ClusterContext, infraClusterClient client.Client) error {
+	status := &ctx.KubevirtCluster.Status
+	now := time.Now()
+	if status.Infra != nil && !status.Infra.LastCheckTime.Time.Before(startTime) && now.Sub(status.Infra.LastCheckTime.Time) < infraStatusCheckInterval {
+		r.reconcileInfraCompatibility(ctx)
+		return nil
+	}
+
+	infra, err := kubevirt.DetectInfraStatus(ctx, infraClusterClient)
+	if err != nil {
+		return err
+	}
+	infra.LastCheckTime = metav1.Time{Time: now}
+	status.Infra = infra
+
+	deleteInfraMetrics(ctx.KubevirtCluster)
+	infraInfo.WithLabelValues(ctx.KubevirtCluster.Namespace, ctx.KubevirtCluster.Name, infra.KubeVirtVersion, infra.CDIVersion).Set(1)
+	for _, feature := range kubevirt.InfraFeatures {
+		value := 0.0
+		if kubevirt.IsInfraFeatureAvailable(infra, feature) {
+			value = 1
+		}
+		infraFeatureAvailable.WithLabelValues(ctx.KubevirtCluster.Namespace, ctx.KubevirtCluster.Name, string(feature)).Set(value)
+	}
+
+	r.reconcileInfraCompatibility(ctx)
+	return nil
+}
+
+// reconcileInfraCompatibility checks the versions of KubeVirt and CDI of the infra cluster against the
+// compatibility matrix of the provider, and records a warning event when they become unsupported.
+func (r *KubevirtClusterReconciler) reconcileInfraCompatibility(ctx *context.ClusterContext) {
+	infra := ctx.KubevirtCluster.Status.Infra
+	if !kubevirt.IsInfraCompatibilityKnown(infra) {
+		conditions.MarkUnknown(ctx.KubevirtCluster, infrav1.InfraCompatibleCondition, infrav1.InfraVersionsUnknownReason,
+			"the version of KubeVirt of the infra cluster is unknown")
+		return
+	}
+
+	message := kubevirt.UnsupportedInfraVersions(infra)
+	if message == "" {
+		conditions.MarkTrue(ctx.KubevirtCluster, infrav1.InfraCompatibleCondition)
+		return
+	}
+
+	if conditions.GetReason(ctx.KubevirtCluster, infrav1.InfraCompatibleCondition) != infrav1.UnsupportedInfraVersionsReason {
+		ctx.Logger.Info("Infra cluster runs versions of KubeVirt and CDI the provider does not support", "reason", message)
+		if r.Recorder != nil {
+			r.Recorder.Event(ctx.KubevirtCluster, corev1.EventTypeWarning, unsupportedInfraVersionsReason, message)
+		}
+	}
+	conditions.MarkFalse(ctx.KubevirtCluster, infrav1.InfraCompatibleCondition, infrav1.UnsupportedInfraVersionsReason,
+		clusterv1.ConditionSeverityWarning, message)
+}
+
+// deleteInfraMetrics removes the metrics reporting the infra cluster of the KubevirtCluster.
+func deleteInfraMetrics(kubevirtCluster *infrav1.KubevirtCluster) {
+	labels := prometheus.Labels{"namespace": kubevirtCluster.Namespace, "cluster": kubevirtCluster.Name}
+	infraInfo.DeletePartialMatch(labels)
+	infraFeatureAvailable.DeletePartialMatch(labels)
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/loadbalancer"
+)
+
+// reconcileInfraNodePorts reports in status.infraNodePorts the node ports of the infra cluster exposing the services
+// publishing the control plane endpoints of the cluster. The services created by this reconcile are already reported.
+func (r *KubevirtClusterReconciler) reconcileInfraNodePorts(ctx *context.ClusterContext, infraClusterClient client.Client, loadBalancerNamespace string) error {
+	var nodePorts []infrav1.InfraNodePort
+	if loadbalancer.PublisherType(ctx.KubevirtCluster) == infrav1.ServiceEndpointPublisher {
+		loadBalancer, err := loadbalancer.NewLoadBalancer(ctx, infraClusterClient, loadBalancerNamespace)
+		if err != nil {
+			return errors.Wrap(err, "failed to get the load balancer service")
+		}
+		nodePorts = append(nodePorts, loadBalancer.NodePorts()...)
+	}
+	if spec := ctx.KubevirtCluster.Spec.ExternalControlPlaneEndpoint; spec != nil && spec.Host == "" {
+		externalLoadBalancer, err := loadbalancer.NewExternalLoadBalancer(ctx, infraClusterClient, externalControlPlaneEndpointNamespace(ctx.KubevirtCluster, loadBalancerNamespace))
+		if err != nil {
+			return errors.Wrap(err, "failed to get the external endpoint service")
+		}
+		nodePorts = append(nodePorts, externalLoadBalancer.NodePorts()...)
+	}
+	ctx.KubevirtCluster.Status.InfraNodePorts = nodePorts
+	return nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	gocontext "context"
+	"fmt"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/ownership"
+)
+
+const (
+	infraOwnershipAcquiredReason  = "InfraOwnershipAcquired"
+	infraOwnershipTakenOverReason = "InfraOwnershipTakenOver"
+)
+
+// infraOwnershipLeaseDuration returns the duration of the infra ownership lease of the cluster.
+func infraOwnershipLeaseDuration(kubevirtCluster *infrav1.KubevirtCluster) time.Duration {
+	if spec := kubevirtCluster.Spec.InfraOwnershipLease; spec != nil && spec.Duration != nil {
+		return spec.Duration.Duration
+	}
+	return infrav1.DefaultInfraOwnershipLeaseDuration
+}
+
+// reconcileInfraOwnership acquires or renews the infra ownership lease of the cluster, and returns true if the
+// management cluster holds it. The lease held by another management cluster is only taken over before it expires
+// when the cluster is annotated for it. Acquisitions from another holder are recorded as events.
+func (r *KubevirtClusterReconciler) reconcileInfraOwnership(ctx *context.ClusterContext, infraClusterClient client.Client, infraClusterNamespace string) (bool, error) {
+	takeOver, _ := strconv.ParseBool(ctx.KubevirtCluster.Annotations[infrav1.TakeOverInfraOwnershipAnnotation])
+
+	result, err := ownership.Acquire(ctx, infraClusterClient, infraClusterNamespace, ctx.KubevirtCluster, infraOwnershipLeaseDuration(ctx.KubevirtCluster), takeOver)
+	if err != nil {
+		return false, err
+	}
+
+	if !result.Held {
+		ctx.Logger.Info("Another management cluster holds the infra ownership lease, leaving the infra resources alone", "holder", result.Holder)
+		conditions.MarkFalse(ctx.KubevirtCluster, infrav1.InfraOwnershipCondition, infrav1.InfraOwnedElsewhereReason, clusterv1.ConditionSeverityWarning,
+			"The infra ownership lease %s is held by %s", ownership.LeaseName(ctx.KubevirtCluster), result.Holder)
+		return false, nil
+	}
+
+	if takeOver {
+		delete(ctx.KubevirtCluster.Annotations, infrav1.TakeOverInfraOwnershipAnnotation)
+	}
+	switch {
+	case result.TakenOver:
+		ctx.Logger.Info("Took the infra ownership lease over", "previousHolder", result.PreviousHolder)
+		r.recordInfraOwnershipEvent(ctx, corev1.EventTypeWarning, infraOwnershipTakenOverReason,
+			fmt.Sprintf("Took the infra ownership lease over from %s", result.PreviousHolder))
+	case result.PreviousHolder != "":
+		ctx.Logger.Info("Acquired the expired infra ownership lease", "previousHolder", result.PreviousHolder)
+		r.recordInfraOwnershipEvent(ctx, corev1.EventTypeNormal, infraOwnershipAcquiredReason,
+			fmt.Sprintf("Acquired the infra ownership lease expired for %s", result.PreviousHolder))
+	}
+	conditions.MarkTrue(ctx.KubevirtCluster, infrav1.InfraOwnershipCondition)
+
+	return true, nil
+}
+
+func (r *KubevirtClusterReconciler) recordInfraOwnershipEvent(ctx *context.ClusterContext, eventType, reason, message string) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Event(ctx.KubevirtCluster, eventType, reason, message)
+}
+
+// isInfraOwnedElsewhere returns true if the KubevirtCluster reports its infra ownership lease held by another
+// management cluster, whose controllers then manage its infra resources. The controllers writing to the infra
+// cluster leave it alone until the lease is acquired by the KubevirtCluster controller.
+func isInfraOwnedElsewhere(kubevirtCluster *infrav1.KubevirtCluster) bool {
+	return conditions.IsFalse(kubevirtCluster, infrav1.InfraOwnershipCondition)
+}
+
+// isMachineInfraOwnedElsewhere returns true if the KubevirtCluster of the machine reports its infra ownership lease
+// held by another management cluster. It returns false if the KubevirtCluster cannot be found anymore.
+func (r *KubevirtMachineReconciler) isMachineInfraOwnedElsewhere(ctx gocontext.Context, machine *clusterv1.Machine) bool {
+	cluster, err := util.GetClusterFromMetadata(ctx, r.Client, machine.ObjectMeta)
+	if err != nil || cluster == nil || cluster.Spec.InfrastructureRef == nil {
+		return false
+	}
+	kubevirtCluster := &infrav1.KubevirtCluster{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: machine.Namespace, Name: cluster.Spec.InfrastructureRef.Name}, kubevirtCluster); err != nil {
+		return false
+	}
+	return isInfraOwnedElsewhere(kubevirtCluster)
+}
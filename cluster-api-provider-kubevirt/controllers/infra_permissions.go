@@ -0,0 +1,57 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+// missingInfraPermissionsReason is the reason of the events of the clusters not reconciled for lack of permissions.
+const missingInfraPermissionsReason = "MissingInfraPermissions"
+
+// reconcileInfraPermissions reports in the InfraPermissionsAvailable condition whether the controller holds the
+// permissions it needs in the management cluster, as checked at startup, for the clusters whose infra cluster is the
+// management cluster. It returns false when some are missing; the cluster is then not reconciled, not to fail in
+// the middle of its reconciliation.
+func (r *KubevirtClusterReconciler) reconcileInfraPermissions(ctx *context.ClusterContext) bool {
+	if r.InfraPermissions == nil || ctx.KubevirtCluster.Spec.InfraClusterSecretRef != nil {
+		conditions.Delete(ctx.KubevirtCluster, infrav1.InfraPermissionsAvailableCondition)
+		return true
+	}
+	if len(r.InfraPermissions.Missing) == 0 {
+		conditions.MarkTrue(ctx.KubevirtCluster, infrav1.InfraPermissionsAvailableCondition)
+		return true
+	}
+
+	message := "the controller is missing permissions in the management cluster, grant them and restart it: " + strings.Join(r.InfraPermissions.Missing, ", ")
+	if conditions.GetReason(ctx.KubevirtCluster, infrav1.InfraPermissionsAvailableCondition) != infrav1.MissingInfraPermissionsReason {
+		ctx.Logger.Info("Not reconciling the cluster, the controller is missing permissions in the infra cluster", "missing", r.InfraPermissions.Missing)
+		if r.Recorder != nil {
+			r.Recorder.Event(ctx.KubevirtCluster, corev1.EventTypeWarning, missingInfraPermissionsReason, message)
+		}
+	}
+	conditions.MarkFalse(ctx.KubevirtCluster, infrav1.InfraPermissionsAvailableCondition, infrav1.MissingInfraPermissionsReason,
+		clusterv1.ConditionSeverityError, "%s", message)
+	return false
+}
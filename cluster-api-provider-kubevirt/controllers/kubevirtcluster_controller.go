@@ -30,6 +30,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
+	kubevirtv1 "kubevirt.io/api/core/v1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
@@ -43,9 +45,22 @@ import (
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/guestagent"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/hibernation"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/infracluster"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kccm"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirtcsi"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/loadbalancer"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/maintenance"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/oidckubeconfig"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/operations"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/ownership"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/permissions"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/prewarm"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/ssh"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/tenantnetwork"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/workloadcluster"
 )
 
 // KubevirtClusterReconciler reconciles a KubevirtCluster object.
@@ -56,7 +71,24 @@ type KubevirtClusterReconciler struct {
 	// decreasing memory consumption and avoiding granting further RBAC verbs.
 	APIReader    client.Reader
 	InfraCluster infracluster.InfraCluster
+	Recorder     record.EventRecorder
 	Log          logr.Logger
+	// WorkloadCluster and GuestAgent are needed by the rolling reboot of the clusters; when nil, it is disabled,
+	// and the upgrade preflight checks depending on them are skipped.
+	WorkloadCluster workloadcluster.WorkloadCluster
+	GuestAgent      guestagent.Runner
+	// SubnetAllocator allocates the subnets of the clusters requesting a tenant network; when nil, they are refused.
+	SubnetAllocator *tenantnetwork.Allocator
+	// InfraPermissions reports the permissions of the controller in the management cluster checked at startup; when
+	// nil, they are not checked.
+	InfraPermissions *permissions.Report
+	// Operations runs the drains of the rolling reboots in the background, polled at every reconcile of the
+	// cluster; they hold the reconciles until they complete when nil.
+	Operations *operations.Tracker
+	// ReadOnly keeps the finalizer of the deleted clusters, sends the drains as dry-runs and only reports the
+	// Deployment of the cloud controller manager, for the controller deployed with read-only infra and workload
+	// cluster clients.
+	ReadOnly bool
 }
 
 func GetLoadBalancerNamespace(kc *infrav1.KubevirtCluster, infraClusterNamespace string) string {
@@ -70,9 +102,26 @@ func GetLoadBalancerNamespace(kc *infrav1.KubevirtCluster, infraClusterNamespace
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtclusters,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtclusters/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups="",resources=services;,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups="",resources=serviceaccounts;configmaps,verbs=delete;list
-// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=delete;list
-// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=delete;list
+// +kubebuilder:rbac:groups="",resources=serviceaccounts;configmaps,verbs=get;create;update;delete;list
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;create;update;delete;list
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;create;update;delete;list
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtmachines,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtmachinetemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=controlplane.cluster.x-k8s.io,resources=*,verbs=get
+// +kubebuilder:rbac:groups=kubevirt.io,resources=virtualmachines,verbs=get;patch
+// +kubebuilder:rbac:groups=kubevirt.io,resources=virtualmachineinstances,verbs=get;delete
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines;machinedeployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines,verbs=patch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=kubevirt.io,resources=virtualmachines,verbs=list;watch
+// +kubebuilder:rbac:groups=kubevirt.io,resources=virtualmachineinstances,verbs=list;watch;update
+// +kubebuilder:rbac:groups="",resources=pods/exec,verbs=create
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;create;update;delete
+// +kubebuilder:rbac:groups=kubevirt.io,resources=kubevirts,verbs=list
+// +kubebuilder:rbac:groups=cdi.kubevirt.io,resources=cdis,verbs=list
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list
 
 // Reconcile reads that state of the cluster for a KubevirtCluster object and makes changes based on the state read
 // and what is in the KubevirtCluster.Spec.
@@ -122,10 +171,10 @@ func (r *KubevirtClusterReconciler) Reconcile(goctx gocontext.Context, req ctrl.
 
 	loadBalancerNamespace := GetLoadBalancerNamespace(kubevirtCluster, infraClusterNamespace)
 
-	// Create a helper for managing a service hosting the load-balancer.
-	externalLoadBalancer, err := loadbalancer.NewLoadBalancer(clusterContext, infraClusterClient, loadBalancerNamespace)
+	// Create a helper for managing the infra object publishing the control plane endpoint.
+	publisher, err := loadbalancer.NewPublisher(clusterContext, infraClusterClient, loadBalancerNamespace)
 	if err != nil {
-		return ctrl.Result{}, errors.Wrapf(err, "failed to create helper for managing the externalLoadBalancer")
+		return ctrl.Result{}, errors.Wrapf(err, "failed to create helper for managing the control plane endpoint publisher")
 	}
 
 	// Initialize the patch helper
@@ -152,56 +201,157 @@ func (r *KubevirtClusterReconciler) Reconcile(goctx gocontext.Context, req ctrl.
 		return ctrl.Result{}, nil
 	}
 
+	// Only mutate the infra resources while holding the infra ownership lease of the cluster
+	if kubevirtCluster.Spec.InfraOwnershipLease == nil {
+		conditions.Delete(kubevirtCluster, infrav1.InfraOwnershipCondition)
+	} else if !isDryRun(kubevirtCluster) {
+		held, err := r.reconcileInfraOwnership(clusterContext, infraClusterClient, infraClusterNamespace)
+		if err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to acquire the infra ownership lease")
+		}
+		if !held {
+			if !kubevirtCluster.DeletionTimestamp.IsZero() {
+				// The infra resources belong to the management cluster holding the lease
+				r.removeFinalizer(clusterContext)
+				return ctrl.Result{}, nil
+			}
+			return ctrl.Result{RequeueAfter: infraOwnershipLeaseDuration(kubevirtCluster)}, nil
+		}
+	}
+
 	// Handle deleted clusters
 	if !kubevirtCluster.DeletionTimestamp.IsZero() {
-		return r.reconcileDelete(clusterContext, externalLoadBalancer)
+		if err := r.deleteExternalControlPlaneEndpoint(clusterContext, infraClusterClient, loadBalancerNamespace); err != nil {
+			clusterContext.Logger.Error(err, "Failed to delete the external endpoint service.")
+		}
+		if err := deleteTenantLoadBalancers(clusterContext, infraClusterClient, loadBalancerNamespace); err != nil {
+			clusterContext.Logger.Error(err, "Failed to delete the tenant load balancers.")
+		}
+		if kubevirtCluster.Spec.ImagePrewarming != nil {
+			if err := prewarm.Delete(clusterContext, infraClusterClient, infraClusterNamespace); err != nil {
+				clusterContext.Logger.Error(err, "Failed to delete the pods pulling the images.")
+			}
+		}
+		if err := r.deleteMigrationPolicy(clusterContext, infraClusterClient); err != nil {
+			clusterContext.Logger.Error(err, "Failed to delete the migration policy.")
+		}
+		// Keep the cluster until its floating IP is released, not to leak it
+		if err := r.releaseControlPlaneFloatingIP(clusterContext, infraClusterClient, loadBalancerNamespace); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to release the floating IP of the control plane endpoint")
+		}
+		// Keep the cluster until its network is deleted, not to allocate its subnet again before
+		if err := r.deleteTenantNetwork(clusterContext, infraClusterClient, infraClusterNamespace); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to delete the tenant network")
+		}
+		res, err := r.reconcileDelete(clusterContext, publisher)
+		if err == nil && kubevirtCluster.Spec.InfraOwnershipLease != nil && !controllerutil.ContainsFinalizer(kubevirtCluster, infrav1.ClusterFinalizer) {
+			if err := ownership.Release(goctx, infraClusterClient, infraClusterNamespace, kubevirtCluster); err != nil {
+				return ctrl.Result{}, errors.Wrap(err, "failed to release the infra ownership lease")
+			}
+		}
+		return res, err
+	}
+
+	// Only report what would be done for clusters annotated for dry-run
+	if isDryRun(kubevirtCluster) {
+		return r.reconcileDryRun(clusterContext, publisher, infraClusterNamespace, loadBalancerNamespace)
+	}
+
+	// Report the permissions missing in the infra cluster instead of failing in the middle of the reconciliation
+	if !r.reconcileInfraPermissions(clusterContext) {
+		return ctrl.Result{}, nil
 	}
 
 	// Handle non-deleted clusters
-	return r.reconcileNormal(clusterContext, externalLoadBalancer)
+	res, err := r.reconcileNormal(clusterContext, publisher, infraClusterClient, infraClusterNamespace)
+	if kubevirtCluster.Spec.InfraOwnershipLease != nil {
+		// Renew the lease well before it expires
+		res = util.LowestNonZeroResult(res, ctrl.Result{RequeueAfter: infraOwnershipLeaseDuration(kubevirtCluster) / 3})
+	}
+	return res, err
 }
 
-func (r *KubevirtClusterReconciler) reconcileNormal(ctx *context.ClusterContext, externalLoadBalancer *loadbalancer.LoadBalancer) (ctrl.Result, error) {
-	// Create the service serving as load balancer, if not existing
-	if !externalLoadBalancer.IsFound() {
-		if err := externalLoadBalancer.Create(ctx); err != nil {
-			conditions.MarkFalse(ctx.KubevirtCluster, infrav1.LoadBalancerAvailableCondition, infrav1.LoadBalancerProvisioningFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
-			return ctrl.Result{}, errors.Wrap(err, "failed to create load balancer")
-		}
+// reconcileDryRun publishes the infra objects reconcileNormal would create for the cluster, without creating them.
+func (r *KubevirtClusterReconciler) reconcileDryRun(ctx *context.ClusterContext, publisher loadbalancer.Publisher, infraClusterNamespace, loadBalancerNamespace string) (ctrl.Result, error) {
+	ctx.Logger.Info("KubevirtCluster is annotated for dry-run, no infra object will be modified")
+
+	if !publisher.IsFound() {
+		kind, name := publisher.Object()
+		recordPlannedChange(r.Recorder, ctx.KubevirtCluster, "create", kind, loadBalancerNamespace, name)
 	}
 
-	// Get the ControlPlane Host and Port manually set by the user if existing
-	if ctx.KubevirtCluster.Spec.ControlPlaneEndpoint.Host != "" {
-		ctx.KubevirtCluster.Spec.ControlPlaneEndpoint = infrav1.APIEndpoint{
-			Host: ctx.KubevirtCluster.Spec.ControlPlaneEndpoint.Host,
-			Port: ctx.KubevirtCluster.Spec.ControlPlaneEndpoint.Port,
-		}
-		// Get LoadBalancer ExternalIP if cluster Service Type is LoadBalancer
-	} else if ctx.KubevirtCluster.Spec.ControlPlaneServiceTemplate.Spec.Type == "LoadBalancer" {
-		lbip4, err := externalLoadBalancer.ExternalIP(ctx)
-		if err != nil {
+	if ctx.KubevirtCluster.Spec.ControlPlaneEndpoint.Host == "" && ctx.KubevirtCluster.Spec.ControlPlaneFloatingIP != nil {
+		spec := ctx.KubevirtCluster.Spec.ControlPlaneFloatingIP
+		recordPlannedChange(r.Recorder, ctx.KubevirtCluster, "create", spec.Kind, loadBalancerNamespace, ctx.Cluster.Name+"-fip")
+	}
+
+	if ctx.KubevirtCluster.Spec.TenantNetwork != nil {
+		recordPlannedChange(r.Recorder, ctx.KubevirtCluster, "create or update", ctx.KubevirtCluster.Spec.TenantNetwork.Kind, infraClusterNamespace, tenantnetwork.Name(ctx.Cluster))
+	}
+
+	if ctx.KubevirtCluster.Spec.CloudControllerManager != nil {
+		recordPlannedChange(r.Recorder, ctx.KubevirtCluster, "create or update", "Deployment", ctx.Cluster.Namespace, kccm.Name(ctx.Cluster))
+	}
+
+	if ctx.KubevirtCluster.Spec.CSIDriver != nil {
+		recordPlannedChange(r.Recorder, ctx.KubevirtCluster, "create or update", "Deployment", ctx.Cluster.Namespace, kubevirtcsi.Name(ctx.Cluster))
+	}
+
+	if ctx.KubevirtCluster.Spec.OIDCKubeconfig != nil {
+		recordPlannedChange(r.Recorder, ctx.KubevirtCluster, "create or update", "Secret", ctx.KubevirtCluster.Namespace, oidckubeconfig.SecretName(ctx.Cluster.Name))
+	}
+
+	clusterNodeSSHKeys := ssh.NewClusterNodeSshKeys(ctx, r.Client)
+	if !clusterNodeSSHKeys.IsPersistedToSecret() {
+		recordPlannedChange(r.Recorder, ctx.KubevirtCluster, "create", "Secret", ctx.KubevirtCluster.Namespace, ctx.KubevirtCluster.Name+"-ssh-keys")
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *KubevirtClusterReconciler) reconcileNormal(ctx *context.ClusterContext, publisher loadbalancer.Publisher, infraClusterClient client.Client, infraClusterNamespace string) (ctrl.Result, error) {
+	// Create the infra object publishing the control plane endpoint, if not existing
+	if !publisher.IsFound() {
+		if err := publisher.Create(ctx); err != nil {
 			conditions.MarkFalse(ctx.KubevirtCluster, infrav1.LoadBalancerAvailableCondition, infrav1.LoadBalancerProvisioningFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
-			return ctrl.Result{}, errors.Wrap(err, "failed to get ExternalIP for the load balancer")
+			return ctrl.Result{}, errors.Wrap(err, "failed to create load balancer")
 		}
-		ctx.KubevirtCluster.Spec.ControlPlaneEndpoint = infrav1.APIEndpoint{
-			Host: lbip4,
-			Port: 6443,
+	}
+	if kind, name := publisher.Object(); kind == "Service" {
+		if err := r.reconcileServiceMetadata(ctx, infraClusterClient, GetLoadBalancerNamespace(ctx.KubevirtCluster, infraClusterNamespace), name); err != nil {
+			return ctrl.Result{}, err
 		}
+	}
 
-		// Get Cluster IP if cluster Service Type is CusterIP
-	} else {
-		lbip4, err := externalLoadBalancer.IP(ctx)
+	// Use the ControlPlane Host and Port manually set by the user if existing, otherwise the allocated floating IP
+	// or the published ones
+	if ctx.KubevirtCluster.Spec.ControlPlaneEndpoint.Host == "" && ctx.KubevirtCluster.Spec.ControlPlaneFloatingIP != nil {
+		res, err := r.reconcileControlPlaneFloatingIP(ctx, publisher, infraClusterClient, GetLoadBalancerNamespace(ctx.KubevirtCluster, infraClusterNamespace))
+		if err != nil || !res.IsZero() {
+			return res, err
+		}
+	}
+	if ctx.KubevirtCluster.Spec.ControlPlaneEndpoint.Host == "" {
+		endpoint, err := publisher.Endpoint(ctx)
 		if err != nil {
 			conditions.MarkFalse(ctx.KubevirtCluster, infrav1.LoadBalancerAvailableCondition, infrav1.LoadBalancerProvisioningFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
-			return ctrl.Result{}, errors.Wrap(err, "failed to get ClusterIP for the load balancer")
-		}
-		ctx.KubevirtCluster.Spec.ControlPlaneEndpoint = infrav1.APIEndpoint{
-			Host: lbip4,
-			Port: 6443,
+			return ctrl.Result{}, errors.Wrap(err, "failed to get the address of the load balancer")
 		}
+		ctx.KubevirtCluster.Spec.ControlPlaneEndpoint = endpoint
 	}
 
 	conditions.MarkTrue(ctx.KubevirtCluster, infrav1.LoadBalancerAvailableCondition)
+	internalEndpoint := ctx.KubevirtCluster.Spec.ControlPlaneEndpoint
+	ctx.KubevirtCluster.Status.InternalControlPlaneEndpoint = &internalEndpoint
+
+	// Publish the external endpoint of the control plane, if enabled. The cluster does not wait for it.
+	externalEndpointRes, err := r.reconcileExternalControlPlaneEndpoint(ctx, infraClusterClient, GetLoadBalancerNamespace(ctx.KubevirtCluster, infraClusterNamespace))
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to publish the external control plane endpoint")
+	}
+	if err := r.reconcileInfraNodePorts(ctx, infraClusterClient, GetLoadBalancerNamespace(ctx.KubevirtCluster, infraClusterNamespace)); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to report the node ports of the infra cluster")
+	}
 
 	// Generate ssh keys for cluster nodes, and persist them to a secret
 	clusterNodeSSHKeys := ssh.NewClusterNodeSshKeys(ctx, r.Client)
@@ -225,15 +375,209 @@ func (r *KubevirtClusterReconciler) reconcileNormal(ctx *context.ClusterContext,
 		}
 	}
 
+	// Distribute a kubeconfig logging the end users in with OIDC, if requested
+	oidcKubeconfigRes, err := r.reconcileOIDCKubeconfig(ctx)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to reconcile the OIDC kubeconfig")
+	}
+
+	// Allocate the subnet of the cluster and declare it in the infra SDN, if requested
+	if ctx.KubevirtCluster.Spec.TenantNetwork != nil {
+		if err := r.reconcileTenantNetwork(ctx, infraClusterClient, infraClusterNamespace); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to reconcile the tenant network")
+		}
+	}
+
+	// Tune the live migrations of the cluster VMs, if requested
+	if err := r.reconcileMigrationPolicy(ctx, infraClusterClient); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to reconcile the migration policy")
+	}
+
+	// Stop or start the cluster VMs according to the hibernation request
+	res, err := r.reconcileHibernation(ctx, infraClusterClient, infraClusterNamespace)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to reconcile cluster hibernation")
+	}
+
+	res = util.LowestNonZeroResult(res, externalEndpointRes)
+	res = util.LowestNonZeroResult(res, oidcKubeconfigRes)
+
+	// Expire the maintenance of the cluster, if any
+	maintenanceEnd := maintenance.Resolve(ctx.KubevirtCluster, time.Now())
+	ctx.KubevirtCluster.Status.MaintenanceEndTime = nil
+	if !maintenanceEnd.IsZero() {
+		ctx.KubevirtCluster.Status.MaintenanceEndTime = &metav1.Time{Time: maintenanceEnd}
+		res = util.LowestNonZeroResult(res, ctrl.Result{RequeueAfter: time.Until(maintenanceEnd)})
+	}
+
+	// Keep the MachineHealthChecks from remediating the machines whose VMs are stopped, or starting again, and the
+	// machines whose Nodes turn not ready during the maintenance of the infra
+	skipRemediationReason := ""
+	switch {
+	case ctx.KubevirtCluster.Status.HibernationState != "":
+		skipRemediationReason = infrav1.HibernatedReason
+	case !maintenanceEnd.IsZero():
+		skipRemediationReason = infrav1.InMaintenanceReason
+	}
+	if err := r.reconcileSkipRemediation(ctx, skipRemediationReason); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to reconcile the remediation of the machines")
+	}
+
+	// Apply control plane size changes, unless the cluster VMs are stopped or in maintenance
+	if ctx.KubevirtCluster.Status.HibernationState == "" && maintenanceEnd.IsZero() {
+		resizeRes, err := r.reconcileControlPlaneResize(ctx, infraClusterClient, infraClusterNamespace)
+		if err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to resize the control plane")
+		}
+		res = util.LowestNonZeroResult(res, resizeRes)
+
+		rebootRes, err := r.reconcileRollingReboot(ctx, infraClusterClient, infraClusterNamespace)
+		if err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to reconcile the rolling reboot")
+		}
+		res = util.LowestNonZeroResult(res, rebootRes)
+	}
+
+	// Let the descheduler of the infra cluster evict the cluster VMs, or protect them, if requested
+	deschedulerRes, err := r.reconcileDeschedulerEviction(ctx, infraClusterClient, infraClusterNamespace)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to reconcile the descheduler eviction of the VMs")
+	}
+	res = util.LowestNonZeroResult(res, deschedulerRes)
+
+	// Check the cluster before the VMs of a new control plane version are created, if requested
+	preflightRes, err := r.reconcileUpgradePreflight(ctx, infraClusterNamespace)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to run the preflight checks of the control plane upgrade")
+	}
+	res = util.LowestNonZeroResult(res, preflightRes)
+
+	if err := r.reconcileNestedVirtualization(ctx, infraClusterClient); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to check the infra nodes for nested virtualization")
+	}
+
+	if err := r.reconcileInfraStatus(ctx, infraClusterClient); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to check the versions and the feature gates of the infra cluster")
+	}
+
+	if err := r.reconcileOrphanedVolumes(ctx, infraClusterClient, infraClusterNamespace); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to report the orphaned volumes")
+	}
+
+	if err := r.reconcileResourceUsage(ctx, infraClusterClient, infraClusterNamespace); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to report the resources consumed by the cluster VMs")
+	}
+
+	if err := r.reconcileDiskEncryptionStatus(ctx, infraClusterClient, infraClusterNamespace); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to check the encryption of the disks of the cluster VMs")
+	}
+
+	// Deploy the cloud controller manager of the workload cluster, if requested. Its Deployment runs in the
+	// management cluster, whose writes are not sent as dry-runs when read-only.
+	switch {
+	case ctx.KubevirtCluster.Spec.CloudControllerManager == nil:
+	case r.ReadOnly:
+		recordPlannedChange(r.Recorder, ctx.KubevirtCluster, "create or update", "Deployment", ctx.Cluster.Namespace, kccm.Name(ctx.Cluster))
+	default:
+		if err := kccm.Reconcile(ctx, r.APIReader, r.Client, infraClusterNamespace); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to deploy the cloud controller manager")
+		}
+	}
+
+	stampProviderVersion(ctx.KubevirtCluster)
+	ctx.KubevirtCluster.Status.ProviderInfo = providerInfo()
+
 	// Mark the KubevirtCluster ready
 	ctx.KubevirtCluster.Status.Ready = true
 
-	return ctrl.Result{}, nil
+	return res, nil
+}
+
+// reconcileHibernation stops or starts the VMs of the cluster according to spec.hibernated and
+// spec.hibernationSchedules, and reports the progress in status.hibernationState. Schedules that cannot be parsed,
+// e.g. set before the webhook validated them, are reported in the HibernationScheduleValid condition and ignored,
+// rather than failing the reconciliation of the whole cluster.
+func (r *KubevirtClusterReconciler) reconcileHibernation(ctx *context.ClusterContext, infraClusterClient client.Client, infraClusterNamespace string) (ctrl.Result, error) {
+	hibernated, nextTransition, err := hibernation.Requested(ctx.KubevirtCluster, time.Now())
+	switch {
+	case err != nil:
+		ctx.Logger.Info("Ignoring invalid hibernation schedules", "reason", err.Error())
+		conditions.MarkFalse(ctx.KubevirtCluster, infrav1.HibernationScheduleValidCondition, infrav1.InvalidHibernationScheduleReason, clusterv1.ConditionSeverityError, "%s", err.Error())
+		hibernated = ctx.KubevirtCluster.Spec.Hibernated
+	case len(ctx.KubevirtCluster.Spec.HibernationSchedules) > 0:
+		conditions.MarkTrue(ctx.KubevirtCluster, infrav1.HibernationScheduleValidCondition)
+	default:
+		conditions.Delete(ctx.KubevirtCluster, infrav1.HibernationScheduleValidCondition)
+	}
+
+	// Come back when the next hibernation window starts or ends.
+	scheduled := ctrl.Result{}
+	ctx.KubevirtCluster.Status.NextHibernationTransition = nil
+	if !nextTransition.IsZero() {
+		ctx.KubevirtCluster.Status.NextHibernationTransition = &metav1.Time{Time: nextTransition}
+		scheduled.RequeueAfter = time.Until(nextTransition)
+	}
+
+	if !hibernated && ctx.KubevirtCluster.Status.HibernationState == "" {
+		return scheduled, nil
+	}
+
+	kubevirtMachines := &infrav1.KubevirtMachineList{}
+	if err := r.Client.List(ctx, kubevirtMachines, client.InNamespace(ctx.KubevirtCluster.Namespace), client.MatchingLabels{clusterv1.ClusterNameLabel: ctx.Cluster.Name}); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to list KubevirtMachines")
+	}
+
+	done := true
+	for _, kubevirtMachine := range kubevirtMachines.Items {
+		vmNamespace := kubevirtMachine.Spec.VirtualMachineTemplate.ObjectMeta.Namespace
+		if vmNamespace == "" {
+			vmNamespace = infraClusterNamespace
+		}
+
+		vm := &kubevirtv1.VirtualMachine{}
+		if err := infraClusterClient.Get(ctx, client.ObjectKey{Namespace: vmNamespace, Name: kubevirtMachine.Name}, vm); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return ctrl.Result{}, errors.Wrapf(err, "failed to fetch VM %s/%s", vmNamespace, kubevirtMachine.Name)
+		}
+
+		if hibernated {
+			stopped, err := kubevirt.HibernateVirtualMachine(ctx, infraClusterClient, vm)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			done = done && stopped
+		} else {
+			if err := kubevirt.ResumeVirtualMachine(ctx, infraClusterClient, vm); err != nil {
+				return ctrl.Result{}, err
+			}
+			done = done && vm.Status.Ready
+		}
+	}
+
+	switch {
+	case hibernated && done:
+		ctx.KubevirtCluster.Status.HibernationState = infrav1.HibernationStateHibernated
+	case hibernated:
+		ctx.KubevirtCluster.Status.HibernationState = infrav1.HibernationStateHibernating
+	case done:
+		ctx.KubevirtCluster.Status.HibernationState = ""
+	default:
+		ctx.KubevirtCluster.Status.HibernationState = infrav1.HibernationStateResuming
+	}
+
+	if !done {
+		ctx.Logger.Info("Waiting for the cluster VMs to change state...", "hibernationState", ctx.KubevirtCluster.Status.HibernationState)
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+
+	return scheduled, nil
 }
 
-func (r *KubevirtClusterReconciler) reconcileDelete(ctx *context.ClusterContext, externalLoadBalancer *loadbalancer.LoadBalancer) (ctrl.Result, error) {
+func (r *KubevirtClusterReconciler) reconcileDelete(ctx *context.ClusterContext, publisher loadbalancer.Publisher) (ctrl.Result, error) {
 	ctx.Logger.Info("Deleting load balancer service...")
-	if err := externalLoadBalancer.Delete(ctx); err != nil {
+	if err := publisher.Delete(ctx); err != nil {
 		ctx.Logger.Error(err, "Failed to delete load balancer service.")
 	}
 
@@ -259,14 +603,32 @@ func (r *KubevirtClusterReconciler) reconcileDelete(ctx *context.ClusterContext,
 		}
 	}
 
+	deleteInfraMetrics(ctx.KubevirtCluster)
+	deleteResourceUsageMetrics(ctx.KubevirtCluster)
+
 	// Cluster is deleted so remove the finalizer.
-	controllerutil.RemoveFinalizer(ctx.KubevirtCluster, infrav1.ClusterFinalizer)
+	r.removeFinalizer(ctx)
 
 	return ctrl.Result{}, nil
 }
 
+// removeFinalizer removes the finalizer of a deleted cluster, unless read-only: the deletion of the infra resources
+// was only a dry-run then, and the cluster is left to the controller managing it.
+func (r *KubevirtClusterReconciler) removeFinalizer(ctx *context.ClusterContext) {
+	if r.ReadOnly {
+		ctx.Logger.Info("Read-only, keeping the finalizer of the deleted cluster")
+		return
+	}
+	controllerutil.RemoveFinalizer(ctx.KubevirtCluster, infrav1.ClusterFinalizer)
+}
+
 // SetupWithManager will add watches for this controller.
 func (r *KubevirtClusterReconciler) SetupWithManager(ctx gocontext.Context, mgr ctrl.Manager) error {
+	if r.Operations != nil {
+		if err := mgr.Add(r.Operations); err != nil {
+			return err
+		}
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&infrav1.KubevirtCluster{}).
 		WithEventFilter(predicates.ResourceNotPaused(r.Log)).
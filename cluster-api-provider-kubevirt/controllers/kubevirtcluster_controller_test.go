@@ -7,17 +7,46 @@ import (
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	migrationsv1alpha1 "kubevirt.io/api/migrations/v1alpha1"
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	. "sigs.k8s.io/controller-runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/controllers"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/guestagent"
+	guestagentmock "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/guestagent/mock"
 	infraclustermock "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/infracluster/mock"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kccm"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/ownership"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/permissions"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/tenantnetwork"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/upgradepreflight"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/version"
+	workloadclustermock "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/workloadcluster/mock"
 )
 
 var (
@@ -144,6 +173,1552 @@ var _ = Describe("Reconcile", func() {
 		})
 	})
 
+	Context("reconcile a cluster annotated for dry-run", func() {
+		BeforeEach(func() {
+			clusterName = "test-cluster"
+			kubevirtClusterName = "test-kubevirt-cluster"
+			kubevirtCluster = testing.NewKubevirtCluster(kubevirtClusterName, kubevirtClusterName)
+			kubevirtCluster.Finalizers = []string{infrav1.ClusterFinalizer}
+			kubevirtCluster.Annotations = map[string]string{infrav1.DryRunAnnotation: "true"}
+			cluster = testing.NewCluster(kubevirtClusterName, kubevirtCluster)
+		})
+
+		AfterEach(func() {})
+
+		It("should only publish the load balancer service it would create", func() {
+			objects := []client.Object{
+				cluster,
+				kubevirtCluster,
+			}
+			setupClient(objects)
+			recorder := record.NewFakeRecorder(10)
+			kubevirtClusterReconciler.Recorder = recorder
+			infraClusterMock.EXPECT().GenerateInfraClusterClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeClient, kubevirtCluster.Namespace, nil)
+
+			result, err := kubevirtClusterReconciler.Reconcile(fakeContext, Request{
+				NamespacedName: client.ObjectKey{
+					Namespace: kubevirtCluster.Namespace,
+					Name:      kubevirtCluster.Name,
+				},
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result).To(Equal(ctrl.Result{}))
+
+			lbService := &corev1.Service{}
+			lbServiceKey := client.ObjectKey{Namespace: kubevirtCluster.Namespace, Name: kubevirtClusterName + "-lb"}
+			Expect(apierrors.IsNotFound(fakeClient.Get(fakeContext, lbServiceKey, lbService))).To(BeTrue())
+
+			Expect(recorder.Events).To(Receive(ContainSubstring("Would create Service")))
+			Expect(recorder.Events).To(Receive(ContainSubstring("Would create Secret")))
+
+			kvc := &infrav1.KubevirtCluster{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(kubevirtCluster), kvc)).To(Succeed())
+			Expect(kvc.Status.Ready).To(BeFalse())
+		})
+	})
+
+	Context("reconcile a hibernated cluster", func() {
+		var (
+			kubevirtMachine *infrav1.KubevirtMachine
+			machine         *clusterv1.Machine
+			userMachine     *clusterv1.Machine
+			vm              *kubevirtv1.VirtualMachine
+		)
+
+		BeforeEach(func() {
+			clusterName = "test-cluster"
+			kubevirtClusterName = "test-kubevirt-cluster"
+			kubevirtCluster = testing.NewKubevirtCluster(kubevirtClusterName, kubevirtClusterName)
+			kubevirtCluster.Finalizers = []string{infrav1.ClusterFinalizer}
+			kubevirtCluster.Spec.ControlPlaneEndpoint = infrav1.APIEndpoint{Host: "10.0.0.1", Port: 6443}
+			kubevirtCluster.Spec.Hibernated = true
+			cluster = testing.NewCluster(kubevirtClusterName, kubevirtCluster)
+
+			kubevirtMachine = testing.NewKubevirtMachine("test-kubevirt-machine", "test-machine")
+			kubevirtMachine.Labels = map[string]string{clusterv1.ClusterNameLabel: cluster.Name}
+			vm = testing.NewVirtualMachine(testing.NewVirtualMachineInstance(kubevirtMachine))
+			vm.Spec.RunStrategy = ptr.To(kubevirtv1.RunStrategyAlways)
+			machine = testing.NewMachine(cluster.Name, "test-machine", kubevirtMachine)
+			userMachine = testing.NewMachine(cluster.Name, "user-machine", nil)
+			userMachine.Annotations = map[string]string{clusterv1.MachineSkipRemediationAnnotation: ""}
+		})
+
+		AfterEach(func() {})
+
+		expectSkipRemediation := func(expected bool) {
+			updated := &clusterv1.Machine{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(machine), updated)).To(Succeed())
+			if expected {
+				Expect(updated.Annotations).To(HaveKey(clusterv1.MachineSkipRemediationAnnotation))
+				Expect(updated.Annotations).To(HaveKeyWithValue(infrav1.SkipRemediationReasonAnnotation, infrav1.HibernatedReason))
+			} else {
+				Expect(updated.Annotations).ToNot(HaveKey(clusterv1.MachineSkipRemediationAnnotation))
+				Expect(updated.Annotations).ToNot(HaveKey(infrav1.SkipRemediationReasonAnnotation))
+			}
+
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(userMachine), updated)).To(Succeed())
+			Expect(updated.Annotations).To(Equal(map[string]string{clusterv1.MachineSkipRemediationAnnotation: ""}))
+		}
+
+		reconcileHibernation := func() ctrl.Result {
+			infraClusterMock.EXPECT().GenerateInfraClusterClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeClient, kubevirtCluster.Namespace, nil)
+			result, err := kubevirtClusterReconciler.Reconcile(fakeContext, Request{
+				NamespacedName: client.ObjectKeyFromObject(kubevirtCluster),
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+			return result
+		}
+
+		It("should stop the cluster VMs and report the hibernation progress", func() {
+			setupClient([]client.Object{cluster, kubevirtCluster, kubevirtMachine, vm, machine, userMachine})
+
+			Expect(reconcileHibernation()).To(Equal(ctrl.Result{RequeueAfter: 10 * time.Second}))
+			expectSkipRemediation(true)
+
+			updatedVM := &kubevirtv1.VirtualMachine{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(vm), updatedVM)).To(Succeed())
+			Expect(updatedVM.Spec.RunStrategy).To(HaveValue(Equal(kubevirtv1.RunStrategyHalted)))
+			Expect(updatedVM.Annotations).To(HaveKeyWithValue(infrav1.HibernatedRunStrategyAnnotation, string(kubevirtv1.RunStrategyAlways)))
+
+			kvc := &infrav1.KubevirtCluster{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(kubevirtCluster), kvc)).To(Succeed())
+			Expect(kvc.Status.HibernationState).To(Equal(infrav1.HibernationStateHibernating))
+
+			updatedVM.Status.PrintableStatus = kubevirtv1.VirtualMachineStatusStopped
+			Expect(fakeClient.Status().Update(fakeContext, updatedVM)).To(Succeed())
+
+			Expect(reconcileHibernation()).To(Equal(ctrl.Result{}))
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(kubevirtCluster), kvc)).To(Succeed())
+			Expect(kvc.Status.HibernationState).To(Equal(infrav1.HibernationStateHibernated))
+			Expect(kvc.Status.Ready).To(BeTrue())
+		})
+
+		It("should start the cluster VMs again once the cluster is resumed", func() {
+			kubevirtCluster.Spec.Hibernated = false
+			kubevirtCluster.Status.HibernationState = infrav1.HibernationStateHibernated
+			vm.Annotations = map[string]string{infrav1.HibernatedRunStrategyAnnotation: string(kubevirtv1.RunStrategyAlways)}
+			vm.Spec.RunStrategy = ptr.To(kubevirtv1.RunStrategyHalted)
+			machine.Annotations = map[string]string{
+				clusterv1.MachineSkipRemediationAnnotation: "",
+				infrav1.SkipRemediationReasonAnnotation:    infrav1.HibernatedReason,
+			}
+			setupClient([]client.Object{cluster, kubevirtCluster, kubevirtMachine, vm, machine, userMachine})
+
+			Expect(reconcileHibernation()).To(Equal(ctrl.Result{RequeueAfter: 10 * time.Second}))
+			expectSkipRemediation(true)
+
+			updatedVM := &kubevirtv1.VirtualMachine{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(vm), updatedVM)).To(Succeed())
+			Expect(updatedVM.Spec.RunStrategy).To(HaveValue(Equal(kubevirtv1.RunStrategyAlways)))
+			Expect(updatedVM.Annotations).ToNot(HaveKey(infrav1.HibernatedRunStrategyAnnotation))
+
+			kvc := &infrav1.KubevirtCluster{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(kubevirtCluster), kvc)).To(Succeed())
+			Expect(kvc.Status.HibernationState).To(Equal(infrav1.HibernationStateResuming))
+
+			updatedVM.Status.Ready = true
+			Expect(fakeClient.Status().Update(fakeContext, updatedVM)).To(Succeed())
+
+			Expect(reconcileHibernation()).To(Equal(ctrl.Result{}))
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(kubevirtCluster), kvc)).To(Succeed())
+			Expect(kvc.Status.HibernationState).To(BeEmpty())
+			expectSkipRemediation(false)
+		})
+
+		It("should report invalid hibernation schedules and keep reconciling the cluster", func() {
+			kubevirtCluster.Spec.Hibernated = false
+			kubevirtCluster.Spec.HibernationSchedules = []infrav1.HibernationSchedule{{Hibernate: "0 20 * * *", Resume: "0 8 * * *", TimeZone: "Mars/Olympus"}}
+			setupClient([]client.Object{cluster, kubevirtCluster, kubevirtMachine, vm})
+
+			Expect(reconcileHibernation()).To(Equal(ctrl.Result{}))
+
+			updatedVM := &kubevirtv1.VirtualMachine{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(vm), updatedVM)).To(Succeed())
+			Expect(updatedVM.Spec.RunStrategy).To(HaveValue(Equal(kubevirtv1.RunStrategyAlways)))
+
+			kvc := &infrav1.KubevirtCluster{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(kubevirtCluster), kvc)).To(Succeed())
+			Expect(kvc.Status.Ready).To(BeTrue())
+			Expect(conditions.IsFalse(kvc, infrav1.HibernationScheduleValidCondition)).To(BeTrue())
+			Expect(conditions.GetReason(kvc, infrav1.HibernationScheduleValidCondition)).To(Equal(infrav1.InvalidHibernationScheduleReason))
+			Expect(conditions.GetMessage(kvc, infrav1.HibernationScheduleValidCondition)).To(ContainSubstring("invalid time zone in hibernation schedule 0"))
+		})
+	})
+
+	Context("reconcile a control plane resize", func() {
+		var (
+			kubevirtMachine *infrav1.KubevirtMachine
+			template        *infrav1.KubevirtMachineTemplate
+			vm              *kubevirtv1.VirtualMachine
+			vmi             *kubevirtv1.VirtualMachineInstance
+		)
+
+		BeforeEach(func() {
+			clusterName = "test-cluster"
+			kubevirtClusterName = "test-kubevirt-cluster"
+			kubevirtCluster = testing.NewKubevirtCluster(kubevirtClusterName, kubevirtClusterName)
+			kubevirtCluster.Finalizers = []string{infrav1.ClusterFinalizer}
+			kubevirtCluster.Spec.ControlPlaneEndpoint = infrav1.APIEndpoint{Host: "10.0.0.1", Port: 6443}
+			cluster = testing.NewCluster(kubevirtClusterName, kubevirtCluster)
+
+			kubevirtMachine = testing.NewKubevirtMachine("test-kubevirt-machine", "test-machine")
+			kubevirtMachine.Labels = map[string]string{
+				clusterv1.ClusterNameLabel:         cluster.Name,
+				clusterv1.MachineControlPlaneLabel: "",
+			}
+			kubevirtMachine.Annotations = map[string]string{clusterv1.TemplateClonedFromNameAnnotation: "test-template"}
+			kubevirtMachine.Spec.VirtualMachineTemplate.Spec.Template.Spec.Domain.Memory = &kubevirtv1.Memory{Guest: ptr.To(resource.MustParse("2Gi"))}
+			kubevirtMachine.Status.Ready = true
+
+			template = &infrav1.KubevirtMachineTemplate{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-template",
+					Annotations: map[string]string{infrav1.InPlaceResizeAnnotation: "true"},
+				},
+			}
+			template.Spec.Template.Spec = *kubevirtMachine.Spec.DeepCopy()
+			template.Spec.Template.Spec.VirtualMachineTemplate.Spec.Template.Spec.Domain.Memory = &kubevirtv1.Memory{Guest: ptr.To(resource.MustParse("4Gi"))}
+
+			vmi = testing.NewVirtualMachineInstance(kubevirtMachine)
+			vmi.UID = "old-vmi"
+			vm = testing.NewVirtualMachine(vmi)
+			vm.Spec.Template = kubevirtMachine.Spec.VirtualMachineTemplate.Spec.Template.DeepCopy()
+			vm.Status.Ready = true
+		})
+
+		AfterEach(func() {})
+
+		reconcileResize := func() ctrl.Result {
+			infraClusterMock.EXPECT().GenerateInfraClusterClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeClient, kubevirtCluster.Namespace, nil)
+			result, err := kubevirtClusterReconciler.Reconcile(fakeContext, Request{
+				NamespacedName: client.ObjectKeyFromObject(kubevirtCluster),
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+			return result
+		}
+
+		It("should restart the VM with its new size and wait for it to be back", func() {
+			setupClient([]client.Object{cluster, kubevirtCluster, kubevirtMachine, template, vm, vmi})
+
+			Expect(reconcileResize()).To(Equal(ctrl.Result{RequeueAfter: 10 * time.Second}))
+
+			updatedVM := &kubevirtv1.VirtualMachine{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(vm), updatedVM)).To(Succeed())
+			Expect(updatedVM.Spec.Template.Spec.Domain.Memory.Guest.String()).To(Equal("4Gi"))
+			err := fakeClient.Get(fakeContext, client.ObjectKeyFromObject(vmi), &kubevirtv1.VirtualMachineInstance{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+			updatedMachine := &infrav1.KubevirtMachine{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(kubevirtMachine), updatedMachine)).To(Succeed())
+			Expect(updatedMachine.Annotations).To(HaveKeyWithValue(infrav1.ResizedVMIAnnotation, "old-vmi"))
+			Expect(updatedMachine.Spec.VirtualMachineTemplate.Spec.Template.Spec.Domain.Memory.Guest.String()).To(Equal("4Gi"))
+
+			// the VM is not back yet
+			Expect(reconcileResize()).To(Equal(ctrl.Result{RequeueAfter: 10 * time.Second}))
+
+			newVMI := testing.NewVirtualMachineInstance(kubevirtMachine)
+			newVMI.UID = "new-vmi"
+			Expect(fakeClient.Create(fakeContext, newVMI)).To(Succeed())
+
+			Expect(reconcileResize()).To(Equal(ctrl.Result{}))
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(kubevirtMachine), updatedMachine)).To(Succeed())
+			Expect(updatedMachine.Annotations).ToNot(HaveKey(infrav1.ResizedVMIAnnotation))
+		})
+
+		Context("with a new size that can be hotplugged", func() {
+			BeforeEach(func() {
+				kubevirtMachine.Spec.VirtualMachineTemplate.Spec.Template.Spec.Domain.CPU = &kubevirtv1.CPU{Sockets: 1}
+				template.Spec.Template.Spec.VirtualMachineTemplate.Spec.Template.Spec.Domain.CPU = &kubevirtv1.CPU{Sockets: 2}
+				vm.Spec.Template = kubevirtMachine.Spec.VirtualMachineTemplate.Spec.Template.DeepCopy()
+				kubevirtCluster.Status.Infra = &infrav1.InfraStatus{
+					KubeVirtVersion:      "v1.2.1",
+					KubeVirtFeatureGates: []string{"VMLiveUpdateFeatures"},
+					VMRolloutStrategy:    "LiveUpdate",
+					LastCheckTime:        metav1.Now(),
+				}
+			})
+
+			It("should hotplug the new size into the running VM and wait for it to be applied", func() {
+				setupClient([]client.Object{cluster, kubevirtCluster, kubevirtMachine, template, vm, vmi})
+
+				Expect(reconcileResize()).To(Equal(ctrl.Result{RequeueAfter: 10 * time.Second}))
+
+				updatedVM := &kubevirtv1.VirtualMachine{}
+				Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(vm), updatedVM)).To(Succeed())
+				Expect(updatedVM.Spec.Template.Spec.Domain.CPU.Sockets).To(Equal(uint32(2)))
+				Expect(updatedVM.Spec.Template.Spec.Domain.Memory.Guest.String()).To(Equal("4Gi"))
+				Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(vmi), &kubevirtv1.VirtualMachineInstance{})).To(Succeed())
+
+				updatedMachine := &infrav1.KubevirtMachine{}
+				Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(kubevirtMachine), updatedMachine)).To(Succeed())
+				Expect(updatedMachine.Annotations).To(HaveKeyWithValue(infrav1.ResizedVMIAnnotation, "old-vmi"))
+
+				// KubeVirt did not apply the new size yet
+				Expect(reconcileResize()).To(Equal(ctrl.Result{RequeueAfter: 10 * time.Second}))
+
+				updatedVMI := &kubevirtv1.VirtualMachineInstance{}
+				Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(vmi), updatedVMI)).To(Succeed())
+				updatedVMI.Status.CurrentCPUTopology = &kubevirtv1.CPUTopology{Sockets: 2}
+				updatedVMI.Status.Memory = &kubevirtv1.MemoryStatus{GuestCurrent: ptr.To(resource.MustParse("4Gi"))}
+				Expect(fakeClient.Status().Update(fakeContext, updatedVMI)).To(Succeed())
+
+				Expect(reconcileResize()).To(Equal(ctrl.Result{}))
+				Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(kubevirtMachine), updatedMachine)).To(Succeed())
+				Expect(updatedMachine.Annotations).ToNot(HaveKey(infrav1.ResizedVMIAnnotation))
+				Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(vmi), &kubevirtv1.VirtualMachineInstance{})).To(Succeed())
+			})
+
+			It("should restart the VM when KubeVirt cannot hotplug the new size", func() {
+				setupClient([]client.Object{cluster, kubevirtCluster, kubevirtMachine, template, vm, vmi})
+				Expect(reconcileResize()).To(Equal(ctrl.Result{RequeueAfter: 10 * time.Second}))
+
+				updatedVM := &kubevirtv1.VirtualMachine{}
+				Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(vm), updatedVM)).To(Succeed())
+				updatedVM.Status.Conditions = []kubevirtv1.VirtualMachineCondition{{Type: kubevirtv1.VirtualMachineRestartRequired, Status: corev1.ConditionTrue}}
+				Expect(fakeClient.Status().Update(fakeContext, updatedVM)).To(Succeed())
+
+				Expect(reconcileResize()).To(Equal(ctrl.Result{RequeueAfter: 10 * time.Second}))
+				err := fakeClient.Get(fakeContext, client.ObjectKeyFromObject(vmi), &kubevirtv1.VirtualMachineInstance{})
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			})
+		})
+
+		It("should not restart a VM whose etcd member is needed for the quorum", func() {
+			machine := testing.NewMachine(cluster.Name, "test-machine", kubevirtMachine)
+			machine.Namespace = kubevirtMachine.Namespace
+			machine.Labels[clusterv1.MachineControlPlaneLabel] = ""
+			machine.Status.Conditions = clusterv1.Conditions{{Type: "EtcdMemberHealthy", Status: corev1.ConditionTrue}}
+			setupClient([]client.Object{cluster, kubevirtCluster, kubevirtMachine, template, vm, vmi, machine})
+
+			Expect(reconcileResize()).To(Equal(ctrl.Result{RequeueAfter: time.Minute}))
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(vmi), &kubevirtv1.VirtualMachineInstance{})).To(Succeed())
+			updatedVM := &kubevirtv1.VirtualMachine{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(vm), updatedVM)).To(Succeed())
+			Expect(updatedVM.Spec.Template.Spec.Domain.Memory.Guest.String()).To(Equal("2Gi"))
+		})
+
+		It("should wait for the etcd members of the control plane to be healthy before resizing", func() {
+			machine := testing.NewMachine(cluster.Name, "test-machine", kubevirtMachine)
+			machine.Namespace = kubevirtMachine.Namespace
+			machine.Labels[clusterv1.MachineControlPlaneLabel] = ""
+			machine.Status.Conditions = clusterv1.Conditions{{Type: "EtcdMemberHealthy", Status: corev1.ConditionFalse}}
+			setupClient([]client.Object{cluster, kubevirtCluster, kubevirtMachine, template, vm, vmi, machine})
+
+			Expect(reconcileResize()).To(Equal(ctrl.Result{RequeueAfter: 10 * time.Second}))
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(vmi), &kubevirtv1.VirtualMachineInstance{})).To(Succeed())
+		})
+
+		It("should wait for the control plane machines to be ready before resizing", func() {
+			kubevirtMachine.Status.Ready = false
+			setupClient([]client.Object{cluster, kubevirtCluster, kubevirtMachine, template, vm, vmi})
+
+			Expect(reconcileResize()).To(Equal(ctrl.Result{RequeueAfter: 10 * time.Second}))
+
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(vmi), &kubevirtv1.VirtualMachineInstance{})).To(Succeed())
+			updatedVM := &kubevirtv1.VirtualMachine{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(vm), updatedVM)).To(Succeed())
+			Expect(updatedVM.Spec.Template.Spec.Domain.Memory.Guest.String()).To(Equal("2Gi"))
+		})
+
+		It("should not resize the VMs of templates not allowing in-place resize", func() {
+			template.Annotations = nil
+			setupClient([]client.Object{cluster, kubevirtCluster, kubevirtMachine, template, vm, vmi})
+
+			Expect(reconcileResize()).To(Equal(ctrl.Result{}))
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(vmi), &kubevirtv1.VirtualMachineInstance{})).To(Succeed())
+		})
+
+		It("should not resize the VMs while the cluster is in maintenance", func() {
+			kubevirtCluster.Annotations = map[string]string{infrav1.MaintenanceAnnotation: "1h"}
+			setupClient([]client.Object{cluster, kubevirtCluster, kubevirtMachine, template, vm, vmi})
+
+			result := reconcileResize()
+			Expect(result.RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(vmi), &kubevirtv1.VirtualMachineInstance{})).To(Succeed())
+
+			updated := &infrav1.KubevirtCluster{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(kubevirtCluster), updated)).To(Succeed())
+			Expect(updated.Status.MaintenanceEndTime).ToNot(BeNil())
+			Expect(updated.Annotations).To(HaveKeyWithValue(infrav1.MaintenanceAnnotation, updated.Status.MaintenanceEndTime.UTC().Format(time.RFC3339)))
+		})
+
+		It("should skip the remediation of the machines until the maintenance is over", func() {
+			kubevirtCluster.Annotations = map[string]string{infrav1.MaintenanceAnnotation: "1h"}
+			machine := testing.NewMachine(cluster.Name, "test-machine", kubevirtMachine)
+			setupClient([]client.Object{cluster, kubevirtCluster, kubevirtMachine, template, vm, vmi, machine})
+
+			reconcileResize()
+			updated := &clusterv1.Machine{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(machine), updated)).To(Succeed())
+			Expect(updated.Annotations).To(HaveKey(clusterv1.MachineSkipRemediationAnnotation))
+			Expect(updated.Annotations).To(HaveKeyWithValue(infrav1.SkipRemediationReasonAnnotation, infrav1.InMaintenanceReason))
+
+			kvc := &infrav1.KubevirtCluster{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(kubevirtCluster), kvc)).To(Succeed())
+			kvc.Annotations[infrav1.MaintenanceAnnotation] = time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+			Expect(fakeClient.Update(fakeContext, kvc)).To(Succeed())
+
+			reconcileResize()
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(machine), updated)).To(Succeed())
+			Expect(updated.Annotations).ToNot(HaveKey(clusterv1.MachineSkipRemediationAnnotation))
+			Expect(updated.Annotations).ToNot(HaveKey(infrav1.SkipRemediationReasonAnnotation))
+		})
+	})
+
+	Context("reconcile a rolling reboot", func() {
+		var (
+			kubevirtMachines    []*infrav1.KubevirtMachine
+			machines            []*clusterv1.Machine
+			vm                  *kubevirtv1.VirtualMachine
+			vmi                 *kubevirtv1.VirtualMachineInstance
+			node                *corev1.Node
+			guestAgentMock      *guestagentmock.MockRunner
+			workloadClusterMock *workloadclustermock.MockWorkloadCluster
+			kubeClient          *k8sfake.Clientset
+		)
+
+		newMachine := func(name, failureDomain string) (*infrav1.KubevirtMachine, *clusterv1.Machine) {
+			kubevirtMachine := testing.NewKubevirtMachine(name, name+"-machine")
+			kubevirtMachine.Labels = map[string]string{clusterv1.ClusterNameLabel: cluster.Name}
+			kubevirtMachine.Status.Ready = true
+			machine := testing.NewMachine(cluster.Name, name+"-machine", kubevirtMachine)
+			machine.Spec.FailureDomain = ptr.To(failureDomain)
+			return kubevirtMachine, machine
+		}
+
+		BeforeEach(func() {
+			clusterName = "test-cluster"
+			kubevirtClusterName = "test-kubevirt-cluster"
+			kubevirtCluster = testing.NewKubevirtCluster(kubevirtClusterName, kubevirtClusterName)
+			kubevirtCluster.Finalizers = []string{infrav1.ClusterFinalizer}
+			kubevirtCluster.Spec.ControlPlaneEndpoint = infrav1.APIEndpoint{Host: "10.0.0.1", Port: 6443}
+			kubevirtCluster.Annotations = map[string]string{infrav1.RollingRebootAnnotation: "true"}
+			cluster = testing.NewCluster(kubevirtClusterName, kubevirtCluster)
+
+			kubevirtMachines, machines = nil, nil
+			for _, m := range []struct{ name, failureDomain string }{{"machine-a", "zone-b"}, {"machine-b", "zone-a"}, {"machine-c", "zone-a"}} {
+				kubevirtMachine, machine := newMachine(m.name, m.failureDomain)
+				kubevirtMachines = append(kubevirtMachines, kubevirtMachine)
+				machines = append(machines, machine)
+			}
+
+			vmi = testing.NewVirtualMachineInstance(kubevirtMachines[0])
+			vmi.UID = "old-vmi"
+			vm = testing.NewVirtualMachine(vmi)
+			vm.Status.Ready = true
+
+			node = &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: kubevirtMachines[0].Name},
+				Status: corev1.NodeStatus{
+					Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+				},
+			}
+
+			guestAgentMock = guestagentmock.NewMockRunner(mockCtrl)
+			workloadClusterMock = workloadclustermock.NewMockWorkloadCluster(mockCtrl)
+		})
+
+		setupRebootClient := func(objects ...client.Object) {
+			objects = append(objects, cluster, kubevirtCluster)
+			for i := range kubevirtMachines {
+				objects = append(objects, kubevirtMachines[i], machines[i])
+			}
+			setupClient(objects)
+			kubevirtClusterReconciler.GuestAgent = guestAgentMock
+			kubevirtClusterReconciler.WorkloadCluster = workloadClusterMock
+
+			kubeClient = k8sfake.NewSimpleClientset(node)
+			workloadClusterMock.EXPECT().GenerateClusterK8sClient(gomock.Any()).Return(kubeClient, nil).AnyTimes()
+		}
+
+		reconcileReboot := func() ctrl.Result {
+			infraClusterMock.EXPECT().GenerateInfraClusterClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeClient, kubevirtCluster.Namespace, nil)
+			result, err := kubevirtClusterReconciler.Reconcile(fakeContext, Request{
+				NamespacedName: client.ObjectKeyFromObject(kubevirtCluster),
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+			return result
+		}
+
+		getKubevirtMachine := func(name string) *infrav1.KubevirtMachine {
+			updated := &infrav1.KubevirtMachine{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKey{Namespace: kubevirtCluster.Namespace, Name: name}, updated)).To(Succeed())
+			return updated
+		}
+
+		It("should find the machines pending a reboot and start rebooting the first one", func() {
+			firstVMI := testing.NewVirtualMachineInstance(kubevirtMachines[1])
+			firstVMI.UID = "first-vmi"
+			setupRebootClient(vm, vmi, firstVMI)
+
+			infraClusterMock.EXPECT().GenerateInfraClusterRestConfig(gomock.Any(), gomock.Any(), gomock.Any()).Return(&rest.Config{}, kubevirtCluster.Namespace, nil)
+			for _, kubevirtMachine := range kubevirtMachines {
+				guestAgentMock.EXPECT().Run(gomock.Any(), gomock.Any(), kubevirtCluster.Namespace, kubevirtMachine.Name, guestagent.RebootRequiredCommand).
+					Return(&guestagent.Result{}, nil)
+			}
+
+			Expect(reconcileReboot()).To(Equal(ctrl.Result{RequeueAfter: time.Second}))
+
+			// machines are rebooted by failure domain
+			updated := &infrav1.KubevirtCluster{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(kubevirtCluster), updated)).To(Succeed())
+			Expect(updated.Status.PendingReboots).To(Equal([]string{"machine-c", "machine-a"}))
+			Expect(updated.Status.LastRebootCheckTime).ToNot(BeNil())
+			Expect(getKubevirtMachine("machine-b").Annotations).To(HaveKeyWithValue(infrav1.RebootingVMIAnnotation, "first-vmi"))
+		})
+
+		It("should not reboot the machines not pending a reboot", func() {
+			setupRebootClient(vm, vmi)
+
+			infraClusterMock.EXPECT().GenerateInfraClusterRestConfig(gomock.Any(), gomock.Any(), gomock.Any()).Return(&rest.Config{}, kubevirtCluster.Namespace, nil)
+			guestAgentMock.EXPECT().Run(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), guestagent.RebootRequiredCommand).
+				Return(&guestagent.Result{ExitCode: 1}, nil).Times(len(kubevirtMachines))
+
+			Expect(reconcileReboot().RequeueAfter).To(BeNumerically("~", 10*time.Minute, time.Minute))
+			for _, kubevirtMachine := range kubevirtMachines {
+				Expect(getKubevirtMachine(kubevirtMachine.Name).Annotations).ToNot(HaveKey(infrav1.RebootingVMIAnnotation))
+			}
+		})
+
+		It("should drain the node, restart the VM and uncordon the node once it is back", func() {
+			kubevirtMachines[0].Annotations = map[string]string{infrav1.RebootingVMIAnnotation: "old-vmi"}
+			setupRebootClient(vm, vmi)
+
+			Expect(reconcileReboot()).To(Equal(ctrl.Result{RequeueAfter: 10 * time.Second}))
+
+			err := fakeClient.Get(fakeContext, client.ObjectKeyFromObject(vmi), &kubevirtv1.VirtualMachineInstance{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			drainedNode, err := kubeClient.CoreV1().Nodes().Get(fakeContext, node.Name, metav1.GetOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(drainedNode.Spec.Unschedulable).To(BeTrue())
+
+			// the VM is not back yet
+			Expect(reconcileReboot()).To(Equal(ctrl.Result{RequeueAfter: 10 * time.Second}))
+			Expect(getKubevirtMachine(kubevirtMachines[0].Name).Annotations).To(HaveKey(infrav1.RebootingVMIAnnotation))
+
+			newVMI := testing.NewVirtualMachineInstance(kubevirtMachines[0])
+			newVMI.UID = "new-vmi"
+			Expect(fakeClient.Create(fakeContext, newVMI)).To(Succeed())
+
+			Expect(reconcileReboot()).To(Equal(ctrl.Result{RequeueAfter: time.Second}))
+			Expect(getKubevirtMachine(kubevirtMachines[0].Name).Annotations).ToNot(HaveKey(infrav1.RebootingVMIAnnotation))
+			uncordonedNode, err := kubeClient.CoreV1().Nodes().Get(fakeContext, node.Name, metav1.GetOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(uncordonedNode.Spec.Unschedulable).To(BeFalse())
+		})
+
+		It("should not reboot the machines of clusters not opted in", func() {
+			kubevirtCluster.Annotations = nil
+			kubevirtCluster.Status.PendingReboots = []string{"machine-a"}
+			setupRebootClient(vm, vmi)
+
+			Expect(reconcileReboot()).To(Equal(ctrl.Result{}))
+
+			updated := &infrav1.KubevirtCluster{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(kubevirtCluster), updated)).To(Succeed())
+			Expect(updated.Status.PendingReboots).To(BeEmpty())
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(vmi), &kubevirtv1.VirtualMachineInstance{})).To(Succeed())
+		})
+	})
+
+	Context("reconcile the upgrade preflight checks", func() {
+		var (
+			controlPlane              *unstructured.Unstructured
+			kubevirtMachine           *infrav1.KubevirtMachine
+			machine                   *clusterv1.Machine
+			pdb                       *policyv1.PodDisruptionBudget
+			guestAgentMock            *guestagentmock.MockRunner
+			workloadClusterMock       *workloadclustermock.MockWorkloadCluster
+			fakeWorkloadClusterClient client.Client
+		)
+
+		BeforeEach(func() {
+			clusterName = "test-cluster"
+			kubevirtClusterName = "test-kubevirt-cluster"
+			kubevirtCluster = testing.NewKubevirtCluster(kubevirtClusterName, kubevirtClusterName)
+			kubevirtCluster.Finalizers = []string{infrav1.ClusterFinalizer}
+			kubevirtCluster.Spec.ControlPlaneEndpoint = infrav1.APIEndpoint{Host: "10.0.0.1", Port: 6443}
+			kubevirtCluster.Spec.UpgradePreflight = &infrav1.UpgradePreflightSpec{
+				MinEtcdFreeSpace:   resource.MustParse("2Gi"),
+				MinKubeVirtVersion: "v1.0.0",
+			}
+			cluster = testing.NewCluster(kubevirtClusterName, kubevirtCluster)
+			cluster.Spec.ControlPlaneRef = &corev1.ObjectReference{
+				APIVersion: "controlplane.cluster.x-k8s.io/v1beta1",
+				Kind:       "KubeadmControlPlane",
+				Namespace:  cluster.Namespace,
+				Name:       "control-plane",
+			}
+
+			controlPlane = &unstructured.Unstructured{}
+			controlPlane.SetAPIVersion("controlplane.cluster.x-k8s.io/v1beta1")
+			controlPlane.SetKind("KubeadmControlPlane")
+			controlPlane.SetNamespace(cluster.Namespace)
+			controlPlane.SetName("control-plane")
+			Expect(unstructured.SetNestedField(controlPlane.Object, "v1.30.2", "spec", "version")).To(Succeed())
+			Expect(unstructured.SetNestedField(controlPlane.Object, "v1.29.6", "status", "version")).To(Succeed())
+
+			kubevirtMachine = testing.NewKubevirtMachine("control-plane-a", "control-plane-a-machine")
+			kubevirtMachine.Labels = map[string]string{clusterv1.ClusterNameLabel: cluster.Name}
+			kubevirtMachine.Status.Ready = true
+			kubevirtMachine.Spec.VirtualMachineTemplate.Spec.Template.Spec.EvictionStrategy = ptr.To(kubevirtv1.EvictionStrategyLiveMigrate)
+			conditions.MarkTrue(kubevirtMachine, infrav1.VMLiveMigratableCondition)
+			machine = testing.NewMachine(cluster.Name, "control-plane-a-machine", kubevirtMachine)
+			machine.Labels[clusterv1.MachineControlPlaneLabel] = ""
+
+			pdb = &policyv1.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+				Status:     policyv1.PodDisruptionBudgetStatus{ExpectedPods: 2, DisruptionsAllowed: 1},
+			}
+
+			guestAgentMock = guestagentmock.NewMockRunner(mockCtrl)
+			workloadClusterMock = workloadclustermock.NewMockWorkloadCluster(mockCtrl)
+		})
+
+		setupPreflightClient := func() {
+			setupClient([]client.Object{cluster, kubevirtCluster, controlPlane, kubevirtMachine, machine})
+			kubevirtClusterReconciler.GuestAgent = guestAgentMock
+			kubevirtClusterReconciler.WorkloadCluster = workloadClusterMock
+			fakeWorkloadClusterClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(pdb).Build()
+		}
+
+		expectChecks := func(availableBytes string) {
+			infraClusterMock.EXPECT().GenerateInfraClusterRestConfig(gomock.Any(), gomock.Any(), gomock.Any()).Return(&rest.Config{}, kubevirtCluster.Namespace, nil)
+			guestAgentMock.EXPECT().Run(gomock.Any(), gomock.Any(), kubevirtCluster.Namespace, kubevirtMachine.Name, upgradepreflight.EtcdFreeSpaceCommand).
+				Return(&guestagent.Result{Stdout: "Avail\n" + availableBytes + "\n"}, nil)
+			workloadClusterMock.EXPECT().GenerateClusterClient(gomock.Any()).Return(fakeWorkloadClusterClient, nil)
+		}
+
+		reconcilePreflight := func() (ctrl.Result, *infrav1.KubevirtCluster) {
+			infraClusterMock.EXPECT().GenerateInfraClusterClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeClient, kubevirtCluster.Namespace, nil)
+			result, err := kubevirtClusterReconciler.Reconcile(fakeContext, Request{
+				NamespacedName: client.ObjectKeyFromObject(kubevirtCluster),
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			updated := &infrav1.KubevirtCluster{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(kubevirtCluster), updated)).To(Succeed())
+			return result, updated
+		}
+
+		It("should pass the checks of the upgrade once, and not run them again", func() {
+			setupPreflightClient()
+			expectChecks("10737418240")
+
+			result, updated := reconcilePreflight()
+			Expect(result).To(Equal(ctrl.Result{}))
+			Expect(conditions.IsTrue(updated, infrav1.UpgradePreflightPassedCondition)).To(BeTrue())
+			Expect(updated.Status.UpgradePreflight).ToNot(BeNil())
+			Expect(updated.Status.UpgradePreflight.TargetVersion).To(Equal("v1.30.2"))
+			Expect(updated.Status.UpgradePreflight.Checks).To(HaveLen(4))
+
+			// no further run of the checks is expected by the mocks
+			_, updated = reconcilePreflight()
+			Expect(conditions.IsTrue(updated, infrav1.UpgradePreflightPassedCondition)).To(BeTrue())
+		})
+
+		It("should report the failed checks and run them again later", func() {
+			conditions.MarkFalse(kubevirtMachine, infrav1.VMLiveMigratableCondition, "DisksNotLiveMigratable", clusterv1.ConditionSeverityInfo, "disk root is not shared")
+			pdb.Status.DisruptionsAllowed = 0
+			setupPreflightClient()
+			expectChecks("1073741824")
+
+			result, updated := reconcilePreflight()
+			Expect(result.RequeueAfter).To(Equal(time.Minute))
+			passed := conditions.Get(updated, infrav1.UpgradePreflightPassedCondition)
+			Expect(passed).ToNot(BeNil())
+			Expect(passed.Status).To(Equal(corev1.ConditionFalse))
+			Expect(passed.Reason).To(Equal(infrav1.UpgradePreflightFailedReason))
+			Expect(passed.Message).To(Equal("EtcdFreeSpace: control-plane-a has 1Gi available for etcd, 2Gi required; " +
+				"LiveMigration: control-plane-a is not live migratable: disk root is not shared; " +
+				"PodDisruptionBudgets: default/web allow no disruption"))
+
+			// the checks do not run again before the retry interval
+			result, _ = reconcilePreflight()
+			Expect(result.RequeueAfter).To(BeNumerically("~", time.Minute, 5*time.Second))
+		})
+
+		It("should clear the checks when no upgrade is pending", func() {
+			Expect(unstructured.SetNestedField(controlPlane.Object, "v1.30.2", "status", "version")).To(Succeed())
+			kubevirtCluster.Status.UpgradePreflight = &infrav1.UpgradePreflightStatus{TargetVersion: "v1.30.2"}
+			conditions.MarkTrue(kubevirtCluster, infrav1.UpgradePreflightPassedCondition)
+			setupPreflightClient()
+
+			_, updated := reconcilePreflight()
+			Expect(updated.Status.UpgradePreflight).To(BeNil())
+			Expect(conditions.Has(updated, infrav1.UpgradePreflightPassedCondition)).To(BeFalse())
+		})
+	})
+
+	Context("reconcile the nested virtualization check", func() {
+		newNode := func(name, zone string, labels map[string]string) *corev1.Node {
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{corev1.LabelTopologyZone: zone}},
+				Status: corev1.NodeStatus{
+					Allocatable: corev1.ResourceList{kubevirt.KVMDeviceResource: resource.MustParse("110")},
+				},
+			}
+			for key, value := range labels {
+				node.Labels[key] = value
+			}
+			return node
+		}
+
+		BeforeEach(func() {
+			clusterName = "test-cluster"
+			kubevirtClusterName = "test-kubevirt-cluster"
+			kubevirtCluster = testing.NewKubevirtCluster(kubevirtClusterName, kubevirtClusterName)
+			kubevirtCluster.Finalizers = []string{infrav1.ClusterFinalizer}
+			kubevirtCluster.Spec.ControlPlaneEndpoint = infrav1.APIEndpoint{Host: "10.0.0.1", Port: 6443}
+			kubevirtCluster.Status.FailureDomains = clusterv1.FailureDomains{
+				"zone-a": clusterv1.FailureDomainSpec{ControlPlane: true},
+				"zone-b": clusterv1.FailureDomainSpec{ControlPlane: true, Attributes: map[string]string{infrav1.NestedVirtualizationAttribute: "true"}},
+			}
+			cluster = testing.NewCluster(kubevirtClusterName, kubevirtCluster)
+		})
+
+		reconcileCluster := func() *infrav1.KubevirtCluster {
+			infraClusterMock.EXPECT().GenerateInfraClusterClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeClient, kubevirtCluster.Namespace, nil)
+			_, err := kubevirtClusterReconciler.Reconcile(fakeContext, Request{
+				NamespacedName: client.ObjectKeyFromObject(kubevirtCluster),
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			updated := &infrav1.KubevirtCluster{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(kubevirtCluster), updated)).To(Succeed())
+			return updated
+		}
+
+		It("should report the infra nodes supporting nested virtualization", func() {
+			setupClient([]client.Object{cluster, kubevirtCluster,
+				newNode("node-1", "zone-a", map[string]string{"cpu-feature.node.kubevirt.io/vmx": "true"}),
+				newNode("node-2", "zone-b", nil),
+			})
+
+			updated := reconcileCluster()
+			Expect(updated.Status.NestedVirtualization).ToNot(BeNil())
+			Expect(updated.Status.NestedVirtualization.Nodes).To(Equal([]string{"node-1"}))
+			Expect(updated.Status.FailureDomains["zone-a"].Attributes).To(HaveKeyWithValue(infrav1.NestedVirtualizationAttribute, "true"))
+			Expect(updated.Status.FailureDomains["zone-b"].Attributes).ToNot(HaveKey(infrav1.NestedVirtualizationAttribute))
+		})
+
+		It("should not check the infra nodes again before the check interval", func() {
+			kubevirtCluster.Status.NestedVirtualization = &infrav1.NestedVirtualizationStatus{
+				Nodes:         []string{"node-2"},
+				LastCheckTime: metav1.Now(),
+			}
+			setupClient([]client.Object{cluster, kubevirtCluster,
+				newNode("node-1", "zone-a", map[string]string{"cpu-feature.node.kubevirt.io/vmx": "true"}),
+			})
+
+			Expect(reconcileCluster().Status.NestedVirtualization.Nodes).To(Equal([]string{"node-2"}))
+		})
+	})
+
+	Context("reconcile the infra status", func() {
+		var kv *kubevirtv1.KubeVirt
+
+		BeforeEach(func() {
+			clusterName = "test-cluster"
+			kubevirtClusterName = "test-kubevirt-cluster"
+			kubevirtCluster = testing.NewKubevirtCluster(kubevirtClusterName, kubevirtClusterName)
+			kubevirtCluster.Finalizers = []string{infrav1.ClusterFinalizer}
+			kubevirtCluster.Spec.ControlPlaneEndpoint = infrav1.APIEndpoint{Host: "10.0.0.1", Port: 6443}
+			cluster = testing.NewCluster(kubevirtClusterName, kubevirtCluster)
+			kv = &kubevirtv1.KubeVirt{
+				ObjectMeta: metav1.ObjectMeta{Name: "kubevirt", Namespace: "kubevirt"},
+				Spec: kubevirtv1.KubeVirtSpec{
+					Configuration: kubevirtv1.KubeVirtConfiguration{
+						DeveloperConfiguration: &kubevirtv1.DeveloperConfiguration{FeatureGates: []string{"HotplugVolumes"}},
+					},
+				},
+				Status: kubevirtv1.KubeVirtStatus{ObservedKubeVirtVersion: "v1.2.1"},
+			}
+		})
+
+		reconcileCluster := func() *infrav1.KubevirtCluster {
+			infraClusterMock.EXPECT().GenerateInfraClusterClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeClient, kubevirtCluster.Namespace, nil)
+			_, err := kubevirtClusterReconciler.Reconcile(fakeContext, Request{
+				NamespacedName: client.ObjectKeyFromObject(kubevirtCluster),
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			updated := &infrav1.KubevirtCluster{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(kubevirtCluster), updated)).To(Succeed())
+			return updated
+		}
+
+		It("should report the version and the feature gates of KubeVirt", func() {
+			setupClient([]client.Object{cluster, kubevirtCluster, kv})
+
+			updated := reconcileCluster()
+			Expect(updated.Status.Infra).ToNot(BeNil())
+			Expect(updated.Status.Infra.KubeVirtVersion).To(Equal("v1.2.1"))
+			Expect(updated.Status.Infra.KubeVirtFeatureGates).To(Equal([]string{"HotplugVolumes"}))
+			Expect(updated.Status.Infra.CDIVersion).To(BeEmpty())
+		})
+
+		It("should report whether the provider supports the versions of the infra cluster", func() {
+			cdi := &cdiv1.CDI{ObjectMeta: metav1.ObjectMeta{Name: "cdi"}, Status: cdiv1.CDIStatus{}}
+			cdi.Status.ObservedVersion = "v1.57.1"
+			setupClient([]client.Object{cluster, kubevirtCluster, kv, cdi})
+
+			compatible := conditions.Get(reconcileCluster(), infrav1.InfraCompatibleCondition)
+			Expect(compatible).ToNot(BeNil())
+			Expect(compatible.Status).To(Equal(corev1.ConditionFalse))
+			Expect(compatible.Reason).To(Equal(infrav1.UnsupportedInfraVersionsReason))
+			Expect(compatible.Message).To(Equal("CDI v1.57.1 is not supported with KubeVirt v1.2.1, CDI v1.58 to v1.59 required"))
+		})
+
+		It("should report unknown compatibility when the version of KubeVirt cannot be read", func() {
+			setupClient([]client.Object{cluster, kubevirtCluster})
+
+			compatible := conditions.Get(reconcileCluster(), infrav1.InfraCompatibleCondition)
+			Expect(compatible).ToNot(BeNil())
+			Expect(compatible.Status).To(Equal(corev1.ConditionUnknown))
+			Expect(compatible.Reason).To(Equal(infrav1.InfraVersionsUnknownReason))
+		})
+
+		It("should record the provider build reconciling the cluster", func() {
+			setupClient([]client.Object{cluster, kubevirtCluster})
+
+			updated := reconcileCluster()
+			Expect(updated.Annotations).To(HaveKeyWithValue(infrav1.ProviderVersionAnnotation, version.Get().String()))
+			Expect(updated.Status.ProviderInfo).ToNot(BeNil())
+			Expect(updated.Status.ProviderInfo.Version).To(Equal(version.Get().GitVersion))
+		})
+
+		It("should not check the infra cluster again before the check interval", func() {
+			kubevirtCluster.Status.Infra = &infrav1.InfraStatus{
+				KubeVirtVersion: "v1.1.0",
+				LastCheckTime:   metav1.Now(),
+			}
+			setupClient([]client.Object{cluster, kubevirtCluster, kv})
+
+			Expect(reconcileCluster().Status.Infra.KubeVirtVersion).To(Equal("v1.1.0"))
+		})
+
+		It("should check the infra cluster again after the check interval", func() {
+			kubevirtCluster.Status.Infra = &infrav1.InfraStatus{
+				KubeVirtVersion: "v1.1.0",
+				LastCheckTime:   metav1.NewTime(time.Now().Add(-time.Hour)),
+			}
+			setupClient([]client.Object{cluster, kubevirtCluster, kv})
+
+			Expect(reconcileCluster().Status.Infra.KubeVirtVersion).To(Equal("v1.2.1"))
+		})
+	})
+
+	Context("report the permissions of the controller in the infra cluster", func() {
+		var recorder *record.FakeRecorder
+
+		BeforeEach(func() {
+			clusterName = "test-cluster"
+			kubevirtClusterName = "test-kubevirt-cluster"
+			kubevirtCluster = testing.NewKubevirtCluster(kubevirtClusterName, kubevirtClusterName)
+			kubevirtCluster.Finalizers = []string{infrav1.ClusterFinalizer}
+			kubevirtCluster.Spec.ControlPlaneEndpoint = infrav1.APIEndpoint{Host: "10.0.0.1", Port: 6443}
+			cluster = testing.NewCluster(kubevirtClusterName, kubevirtCluster)
+			recorder = record.NewFakeRecorder(10)
+		})
+
+		reconcileCluster := func(report *permissions.Report) *infrav1.KubevirtCluster {
+			setupClient([]client.Object{cluster, kubevirtCluster})
+			kubevirtClusterReconciler.InfraPermissions = report
+			kubevirtClusterReconciler.Recorder = recorder
+			infraClusterMock.EXPECT().GenerateInfraClusterClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeClient, kubevirtCluster.Namespace, nil)
+			_, err := kubevirtClusterReconciler.Reconcile(fakeContext, Request{
+				NamespacedName: client.ObjectKeyFromObject(kubevirtCluster),
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			updated := &infrav1.KubevirtCluster{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(kubevirtCluster), updated)).To(Succeed())
+			return updated
+		}
+
+		It("should not reconcile the cluster while the controller is missing permissions", func() {
+			updated := reconcileCluster(&permissions.Report{Missing: []string{"get secrets", "create virtualmachines.kubevirt.io"}})
+
+			available := conditions.Get(updated, infrav1.InfraPermissionsAvailableCondition)
+			Expect(available).ToNot(BeNil())
+			Expect(available.Status).To(Equal(corev1.ConditionFalse))
+			Expect(available.Reason).To(Equal(infrav1.MissingInfraPermissionsReason))
+			Expect(available.Severity).To(Equal(clusterv1.ConditionSeverityError))
+			Expect(available.Message).To(ContainSubstring("get secrets, create virtualmachines.kubevirt.io"))
+			Expect(conditions.Has(updated, infrav1.LoadBalancerAvailableCondition)).To(BeFalse())
+			Expect(updated.Status.Ready).To(BeFalse())
+			Expect(recorder.Events).To(Receive(ContainSubstring("MissingInfraPermissions")))
+		})
+
+		It("should reconcile the cluster when the controller holds the permissions", func() {
+			updated := reconcileCluster(&permissions.Report{})
+
+			Expect(conditions.IsTrue(updated, infrav1.InfraPermissionsAvailableCondition)).To(BeTrue())
+			Expect(conditions.IsTrue(updated, infrav1.LoadBalancerAvailableCondition)).To(BeTrue())
+		})
+
+		It("should not report the permissions of the clusters with their own infra credentials", func() {
+			kubevirtCluster.Spec.InfraClusterSecretRef = &corev1.ObjectReference{Name: "infra-kubeconfig"}
+
+			updated := reconcileCluster(&permissions.Report{Missing: []string{"get secrets"}})
+
+			Expect(conditions.Has(updated, infrav1.InfraPermissionsAvailableCondition)).To(BeFalse())
+			Expect(conditions.IsTrue(updated, infrav1.LoadBalancerAvailableCondition)).To(BeTrue())
+		})
+	})
+
+	Context("distribute the OIDC kubeconfig of the cluster", func() {
+		var adminKubeconfigSecret *corev1.Secret
+
+		BeforeEach(func() {
+			clusterName = "test-cluster"
+			kubevirtClusterName = "test-kubevirt-cluster"
+			kubevirtCluster = testing.NewKubevirtCluster(kubevirtClusterName, kubevirtClusterName)
+			kubevirtCluster.Finalizers = []string{infrav1.ClusterFinalizer}
+			kubevirtCluster.Spec.ControlPlaneEndpoint = infrav1.APIEndpoint{Host: "10.0.0.1", Port: 6443}
+			kubevirtCluster.Spec.OIDCKubeconfig = &infrav1.OIDCKubeconfigSpec{IssuerURL: "https://issuer.example.com", ClientID: "kubernetes"}
+			cluster = testing.NewCluster(kubevirtClusterName, kubevirtCluster)
+
+			admin := clientcmdapi.NewConfig()
+			admin.Clusters[cluster.Name] = &clientcmdapi.Cluster{Server: "https://10.0.0.1:6443", CertificateAuthorityData: []byte("ca")}
+			admin.AuthInfos["admin"] = &clientcmdapi.AuthInfo{ClientCertificateData: []byte("cert"), ClientKeyData: []byte("key")}
+			admin.Contexts["admin"] = &clientcmdapi.Context{Cluster: cluster.Name, AuthInfo: "admin"}
+			admin.CurrentContext = "admin"
+			value, err := clientcmd.Write(*admin)
+			Expect(err).ToNot(HaveOccurred())
+			adminKubeconfigSecret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: cluster.Namespace, Name: cluster.Name + "-kubeconfig"},
+				Data:       map[string][]byte{"value": value},
+			}
+		})
+
+		reconcileCluster := func(objects ...client.Object) ctrl.Result {
+			setupClient(append([]client.Object{cluster, kubevirtCluster}, objects...))
+			infraClusterMock.EXPECT().GenerateInfraClusterClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeClient, kubevirtCluster.Namespace, nil)
+			result, err := kubevirtClusterReconciler.Reconcile(fakeContext, Request{
+				NamespacedName: client.ObjectKeyFromObject(kubevirtCluster),
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+			return result
+		}
+
+		It("should store the OIDC kubeconfig in its secret", func() {
+			reconcileCluster(adminKubeconfigSecret)
+
+			secret := &corev1.Secret{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Name + "-oidc-kubeconfig"}, secret)).To(Succeed())
+			Expect(metav1.IsControlledBy(secret, kubevirtCluster)).To(BeTrue())
+			config, err := clientcmd.Load(secret.Data["value"])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.Clusters[cluster.Name].Server).To(Equal("https://10.0.0.1:6443"))
+			for _, user := range config.AuthInfos {
+				Expect(user.ClientKeyData).To(BeEmpty())
+				Expect(user.Exec).ToNot(BeNil())
+				Expect(user.Exec.Args).To(ContainElement("--oidc-issuer-url=https://issuer.example.com"))
+			}
+		})
+
+		It("should wait for the admin kubeconfig", func() {
+			result := reconcileCluster()
+
+			Expect(result.RequeueAfter).ToNot(BeZero())
+			secret := &corev1.Secret{}
+			err := fakeClient.Get(fakeContext, client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Name + "-oidc-kubeconfig"}, secret)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should delete the OIDC kubeconfig when no longer requested", func() {
+			kubevirtCluster.Spec.OIDCKubeconfig = nil
+			oidcKubeconfigSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: cluster.Namespace, Name: cluster.Name + "-oidc-kubeconfig"},
+			}
+			Expect(controllerutil.SetControllerReference(kubevirtCluster, oidcKubeconfigSecret, testing.SetupScheme())).To(Succeed())
+
+			reconcileCluster(adminKubeconfigSecret, oidcKubeconfigSecret)
+
+			err := fakeClient.Get(fakeContext, client.ObjectKeyFromObject(oidcKubeconfigSecret), &corev1.Secret{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Context("reconcile the cloud controller manager in read-only mode", func() {
+		BeforeEach(func() {
+			clusterName = "test-cluster"
+			kubevirtClusterName = "test-kubevirt-cluster"
+			kubevirtCluster = testing.NewKubevirtCluster(kubevirtClusterName, kubevirtClusterName)
+			kubevirtCluster.Finalizers = []string{infrav1.ClusterFinalizer}
+			kubevirtCluster.Spec.ControlPlaneEndpoint = infrav1.APIEndpoint{Host: "10.0.0.1", Port: 6443}
+			kubevirtCluster.Spec.CloudControllerManager = &infrav1.CloudControllerManagerSpec{}
+			cluster = testing.NewCluster(kubevirtClusterName, kubevirtCluster)
+		})
+
+		It("should only report the Deployment of the cloud controller manager", func() {
+			setupClient([]client.Object{cluster, kubevirtCluster})
+			recorder := record.NewFakeRecorder(10)
+			kubevirtClusterReconciler.Recorder = recorder
+			kubevirtClusterReconciler.ReadOnly = true
+			infraClusterMock.EXPECT().GenerateInfraClusterClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeClient, kubevirtCluster.Namespace, nil)
+
+			_, err := kubevirtClusterReconciler.Reconcile(fakeContext, Request{
+				NamespacedName: client.ObjectKeyFromObject(kubevirtCluster),
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(fakeClient.Get(fakeContext, client.ObjectKey{Namespace: cluster.Namespace, Name: kccm.Name(cluster)}, &appsv1.Deployment{})).ToNot(Succeed())
+			Expect(recorder.Events).To(Receive(ContainSubstring("Would create or update Deployment " + cluster.Namespace + "/" + kccm.Name(cluster))))
+		})
+	})
+
+	Context("reconcile the infra ownership lease", func() {
+		var otherHolderLease *coordinationv1.Lease
+
+		BeforeEach(func() {
+			clusterName = "test-cluster"
+			kubevirtClusterName = "test-kubevirt-cluster"
+			kubevirtCluster = testing.NewKubevirtCluster(kubevirtClusterName, kubevirtClusterName)
+			kubevirtCluster.Finalizers = []string{infrav1.ClusterFinalizer}
+			kubevirtCluster.Spec.ControlPlaneEndpoint = infrav1.APIEndpoint{Host: "10.0.0.1", Port: 6443}
+			kubevirtCluster.Spec.InfraOwnershipLease = &infrav1.InfraOwnershipLeaseSpec{}
+			cluster = testing.NewCluster(kubevirtClusterName, kubevirtCluster)
+			otherHolderLease = &coordinationv1.Lease{
+				ObjectMeta: metav1.ObjectMeta{Namespace: kubevirtCluster.Namespace, Name: ownership.LeaseName(kubevirtCluster)},
+				Spec: coordinationv1.LeaseSpec{
+					HolderIdentity:       ptr.To("other-management-cluster"),
+					LeaseDurationSeconds: ptr.To(int32(300)),
+					RenewTime:            &metav1.MicroTime{Time: time.Now()},
+				},
+			}
+		})
+
+		reconcileCluster := func() (ctrl.Result, *infrav1.KubevirtCluster) {
+			infraClusterMock.EXPECT().GenerateInfraClusterClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeClient, kubevirtCluster.Namespace, nil)
+			result, err := kubevirtClusterReconciler.Reconcile(fakeContext, Request{
+				NamespacedName: client.ObjectKeyFromObject(kubevirtCluster),
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			updated := &infrav1.KubevirtCluster{}
+			if err := fakeClient.Get(fakeContext, client.ObjectKeyFromObject(kubevirtCluster), updated); err != nil {
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+				return result, nil
+			}
+			return result, updated
+		}
+
+		It("should acquire the lease and renew it before it expires", func() {
+			setupClient([]client.Object{cluster, kubevirtCluster})
+
+			result, updated := reconcileCluster()
+			Expect(result.RequeueAfter).To(Equal(infrav1.DefaultInfraOwnershipLeaseDuration / 3))
+			Expect(conditions.IsTrue(updated, infrav1.InfraOwnershipCondition)).To(BeTrue())
+			Expect(updated.Status.Ready).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(updated.GetV1Beta2Conditions(), infrav1.ReadyV1Beta2Condition)).To(BeTrue())
+			Expect(meta.FindStatusCondition(updated.GetV1Beta2Conditions(), string(infrav1.InfraOwnershipCondition)).Reason).To(Equal(infrav1.InfraOwnedV1Beta2Reason))
+
+			lease := &coordinationv1.Lease{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKey{Namespace: kubevirtCluster.Namespace, Name: ownership.LeaseName(kubevirtCluster)}, lease)).To(Succeed())
+			Expect(*lease.Spec.HolderIdentity).To(Equal(ownership.HolderIdentity(kubevirtCluster)))
+		})
+
+		It("should leave the infra resources alone while another management cluster holds the lease", func() {
+			setupClient([]client.Object{cluster, kubevirtCluster, otherHolderLease})
+
+			result, updated := reconcileCluster()
+			Expect(result.RequeueAfter).To(Equal(infrav1.DefaultInfraOwnershipLeaseDuration))
+			Expect(conditions.GetReason(updated, infrav1.InfraOwnershipCondition)).To(Equal(infrav1.InfraOwnedElsewhereReason))
+			Expect(meta.FindStatusCondition(updated.GetV1Beta2Conditions(), infrav1.ReadyV1Beta2Condition).Reason).To(Equal(infrav1.NotReadyV1Beta2Reason))
+			Expect(conditions.GetMessage(updated, infrav1.InfraOwnershipCondition)).To(ContainSubstring("other-management-cluster"))
+			Expect(updated.Status.Ready).To(BeFalse())
+		})
+
+		It("should take the lease over when annotated for it", func() {
+			kubevirtCluster.Annotations = map[string]string{infrav1.TakeOverInfraOwnershipAnnotation: "true"}
+			setupClient([]client.Object{cluster, kubevirtCluster, otherHolderLease})
+
+			_, updated := reconcileCluster()
+			Expect(conditions.IsTrue(updated, infrav1.InfraOwnershipCondition)).To(BeTrue())
+			Expect(updated.Annotations).ToNot(HaveKey(infrav1.TakeOverInfraOwnershipAnnotation))
+
+			lease := &coordinationv1.Lease{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(otherHolderLease), lease)).To(Succeed())
+			Expect(lease.Annotations).To(HaveKeyWithValue(ownership.PreviousHolderAnnotation, "other-management-cluster"))
+		})
+
+		It("should only remove the finalizer of a deleted cluster whose lease is held elsewhere", func() {
+			kubevirtCluster.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+			setupClient([]client.Object{cluster, kubevirtCluster, otherHolderLease})
+
+			_, updated := reconcileCluster()
+			Expect(updated).To(BeNil())
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(otherHolderLease), &coordinationv1.Lease{})).To(Succeed())
+		})
+	})
+
+	Context("reconcile the external control plane endpoint", func() {
+		BeforeEach(func() {
+			clusterName = "test-cluster"
+			kubevirtClusterName = "test-kubevirt-cluster"
+			kubevirtCluster = testing.NewKubevirtCluster(kubevirtClusterName, kubevirtClusterName)
+			kubevirtCluster.Finalizers = []string{infrav1.ClusterFinalizer}
+			kubevirtCluster.Spec.ControlPlaneEndpoint = infrav1.APIEndpoint{Host: "10.0.0.1", Port: 6443}
+			kubevirtCluster.Spec.ExternalControlPlaneEndpoint = &infrav1.ExternalControlPlaneEndpointSpec{}
+			cluster = testing.NewCluster(kubevirtClusterName, kubevirtCluster)
+		})
+
+		reconcileCluster := func() (ctrl.Result, *infrav1.KubevirtCluster) {
+			infraClusterMock.EXPECT().GenerateInfraClusterClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeClient, kubevirtCluster.Namespace, nil)
+			result, err := kubevirtClusterReconciler.Reconcile(fakeContext, Request{
+				NamespacedName: client.ObjectKeyFromObject(kubevirtCluster),
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			updated := &infrav1.KubevirtCluster{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(kubevirtCluster), updated)).To(Succeed())
+			return result, updated
+		}
+
+		It("should publish a LoadBalancer service and wait for its address without blocking the cluster", func() {
+			setupClient([]client.Object{cluster, kubevirtCluster})
+
+			result, updated := reconcileCluster()
+			Expect(result.RequeueAfter).To(Equal(10 * time.Second))
+			Expect(updated.Status.Ready).To(BeTrue())
+			Expect(updated.Status.InternalControlPlaneEndpoint).To(Equal(&infrav1.APIEndpoint{Host: "10.0.0.1", Port: 6443}))
+			Expect(updated.Status.ExternalControlPlaneEndpoint).To(BeNil())
+			Expect(conditions.GetReason(updated, infrav1.ExternalControlPlaneEndpointAvailableCondition)).To(Equal(infrav1.WaitingForExternalAddressReason))
+
+			service := &corev1.Service{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKey{Namespace: kubevirtCluster.Namespace, Name: cluster.Name + "-lb-external"}, service)).To(Succeed())
+			Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeLoadBalancer))
+
+			service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "203.0.113.10"}}
+			Expect(fakeClient.Status().Update(fakeContext, service)).To(Succeed())
+
+			_, updated = reconcileCluster()
+			Expect(updated.Status.ExternalControlPlaneEndpoint).To(Equal(&infrav1.APIEndpoint{Host: "203.0.113.10", Port: 6443}))
+			Expect(conditions.IsTrue(updated, infrav1.ExternalControlPlaneEndpointAvailableCondition)).To(BeTrue())
+			Expect(updated.Spec.ControlPlaneEndpoint.Host).To(Equal("10.0.0.1"))
+		})
+
+		It("should report the node ports of the infra cluster exposing the services of the cluster", func() {
+			kubevirtCluster.Spec.ExternalControlPlaneEndpoint.ServiceTemplate.Spec = infrav1.ServiceSpecTemplate{Type: corev1.ServiceTypeNodePort, NodePort: 30443}
+			setupClient([]client.Object{cluster, kubevirtCluster})
+
+			_, updated := reconcileCluster()
+			Expect(updated.Status.InfraNodePorts).To(Equal([]infrav1.InfraNodePort{
+				{Service: kubevirtCluster.Namespace + "/" + cluster.Name + "-lb-external", NodePort: 30443},
+			}))
+
+			service := &corev1.Service{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKey{Namespace: kubevirtCluster.Namespace, Name: cluster.Name + "-lb-external"}, service)).To(Succeed())
+			Expect(service.Spec.Ports).To(ConsistOf(HaveField("NodePort", int32(30443))))
+		})
+
+		It("should report the external endpoint set manually without publishing a service", func() {
+			kubevirtCluster.Spec.ExternalControlPlaneEndpoint = &infrav1.ExternalControlPlaneEndpointSpec{Host: "api.example.com", Port: 443}
+			setupClient([]client.Object{cluster, kubevirtCluster})
+
+			_, updated := reconcileCluster()
+			Expect(updated.Status.ExternalControlPlaneEndpoint).To(Equal(&infrav1.APIEndpoint{Host: "api.example.com", Port: 443}))
+			Expect(conditions.IsTrue(updated, infrav1.ExternalControlPlaneEndpointAvailableCondition)).To(BeTrue())
+
+			service := &corev1.Service{}
+			err := fakeClient.Get(fakeContext, client.ObjectKey{Namespace: kubevirtCluster.Namespace, Name: cluster.Name + "-lb-external"}, service)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Context("reconcile the floating IP of the control plane endpoint", func() {
+		BeforeEach(func() {
+			clusterName = "test-cluster"
+			kubevirtClusterName = "test-kubevirt-cluster"
+			kubevirtCluster = testing.NewKubevirtCluster(kubevirtClusterName, kubevirtClusterName)
+			kubevirtCluster.Finalizers = []string{infrav1.ClusterFinalizer}
+			kubevirtCluster.Spec.ControlPlaneEndpointPublisher = &infrav1.EndpointPublisherSpec{Type: infrav1.StaticEndpointPublisher}
+			kubevirtCluster.Spec.ControlPlaneFloatingIP = &infrav1.FloatingIPSpec{
+				APIVersion: "sdn.example.com/v1",
+				Kind:       "FloatingIPClaim",
+				Pool:       "public",
+			}
+			cluster = testing.NewCluster(kubevirtClusterName, kubevirtCluster)
+		})
+
+		getClaim := func() (*unstructured.Unstructured, error) {
+			claim := &unstructured.Unstructured{}
+			claim.SetAPIVersion("sdn.example.com/v1")
+			claim.SetKind("FloatingIPClaim")
+			err := fakeClient.Get(fakeContext, client.ObjectKey{Namespace: kubevirtCluster.Namespace, Name: cluster.Name + "-fip"}, claim)
+			return claim, err
+		}
+
+		It("should set the allocated floating IP as the control plane endpoint, and release it with the cluster", func() {
+			setupClient([]client.Object{cluster, kubevirtCluster})
+			infraClusterMock.EXPECT().GenerateInfraClusterClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeClient, kubevirtCluster.Namespace, nil).Times(3)
+			request := Request{NamespacedName: client.ObjectKeyFromObject(kubevirtCluster)}
+
+			result, err := kubevirtClusterReconciler.Reconcile(fakeContext, request)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(10 * time.Second))
+
+			updated := &infrav1.KubevirtCluster{}
+			Expect(fakeClient.Get(fakeContext, request.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.Ready).To(BeFalse())
+			Expect(conditions.GetReason(updated, infrav1.LoadBalancerAvailableCondition)).To(Equal(infrav1.WaitingForFloatingIPReason))
+
+			claim, err := getClaim()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(claim.Object["spec"]).To(Equal(map[string]interface{}{"pool": "public"}))
+			Expect(unstructured.SetNestedField(claim.Object, "203.0.113.20", "status", "address")).To(Succeed())
+			Expect(fakeClient.Update(fakeContext, claim)).To(Succeed())
+
+			_, err = kubevirtClusterReconciler.Reconcile(fakeContext, request)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(fakeClient.Get(fakeContext, request.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.Ready).To(BeTrue())
+			Expect(updated.Spec.ControlPlaneEndpoint).To(Equal(infrav1.APIEndpoint{Host: "203.0.113.20", Port: 6443}))
+
+			Expect(fakeClient.Delete(fakeContext, updated)).To(Succeed())
+			_, err = kubevirtClusterReconciler.Reconcile(fakeContext, request)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, err = getClaim()
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Context("reconcile the tenant network of the cluster", func() {
+		BeforeEach(func() {
+			clusterName = "test-cluster"
+			kubevirtClusterName = "test-kubevirt-cluster"
+			kubevirtCluster = testing.NewKubevirtCluster(kubevirtClusterName, kubevirtClusterName)
+			kubevirtCluster.Finalizers = []string{infrav1.ClusterFinalizer}
+			kubevirtCluster.Spec.ControlPlaneEndpointPublisher = &infrav1.EndpointPublisherSpec{Type: infrav1.StaticEndpointPublisher}
+			kubevirtCluster.Spec.ControlPlaneEndpoint = infrav1.APIEndpoint{Host: "10.0.0.10", Port: 6443}
+			kubevirtCluster.Spec.TenantNetwork = &infrav1.TenantNetworkSpec{
+				APIVersion: "sdn.example.com/v1",
+				Kind:       "TenantNetwork",
+			}
+			cluster = testing.NewCluster(kubevirtClusterName, kubevirtCluster)
+		})
+
+		getNetwork := func() (*unstructured.Unstructured, error) {
+			network := &unstructured.Unstructured{}
+			network.SetAPIVersion("sdn.example.com/v1")
+			network.SetKind("TenantNetwork")
+			err := fakeClient.Get(fakeContext, client.ObjectKey{Namespace: kubevirtCluster.Namespace, Name: cluster.Name + "-net"}, network)
+			return network, err
+		}
+
+		It("should allocate the subnet of the cluster, and delete its network with the cluster", func() {
+			setupClient([]client.Object{cluster, kubevirtCluster})
+			allocator, err := tenantnetwork.NewAllocator("10.128.0.0/16", 24)
+			Expect(err).ToNot(HaveOccurred())
+			kubevirtClusterReconciler.SubnetAllocator = allocator
+			infraClusterMock.EXPECT().GenerateInfraClusterClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeClient, kubevirtCluster.Namespace, nil).Times(2)
+			request := Request{NamespacedName: client.ObjectKeyFromObject(kubevirtCluster)}
+
+			_, err = kubevirtClusterReconciler.Reconcile(fakeContext, request)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			updated := &infrav1.KubevirtCluster{}
+			Expect(fakeClient.Get(fakeContext, request.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.Ready).To(BeTrue())
+			Expect(updated.Status.TenantSubnet).To(Equal("10.128.0.0/24"))
+			network, err := getNetwork()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(network.Object["spec"]).To(Equal(map[string]interface{}{"cidr": "10.128.0.0/24"}))
+
+			Expect(fakeClient.Delete(fakeContext, updated)).To(Succeed())
+			_, err = kubevirtClusterReconciler.Reconcile(fakeContext, request)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, err = getNetwork()
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should fail when the controller has no tenant supernet", func() {
+			setupClient([]client.Object{cluster, kubevirtCluster})
+			infraClusterMock.EXPECT().GenerateInfraClusterClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeClient, kubevirtCluster.Namespace, nil)
+			request := Request{NamespacedName: client.ObjectKeyFromObject(kubevirtCluster)}
+
+			_, err := kubevirtClusterReconciler.Reconcile(fakeContext, request)
+			Expect(err).To(MatchError(ContainSubstring("requires the --tenant-supernet flag")))
+		})
+	})
+
+	Context("reconcile the migration policy of the cluster", func() {
+		BeforeEach(func() {
+			clusterName = "test-cluster"
+			kubevirtClusterName = "test-kubevirt-cluster"
+			kubevirtCluster = testing.NewKubevirtCluster(kubevirtClusterName, kubevirtClusterName)
+			kubevirtCluster.Finalizers = []string{infrav1.ClusterFinalizer}
+			kubevirtCluster.Spec.ControlPlaneEndpointPublisher = &infrav1.EndpointPublisherSpec{Type: infrav1.StaticEndpointPublisher}
+			kubevirtCluster.Spec.ControlPlaneEndpoint = infrav1.APIEndpoint{Host: "10.0.0.10", Port: 6443}
+			kubevirtCluster.Spec.MigrationPolicy = &infrav1.MigrationPolicySpec{BandwidthPerMigration: ptr.To(resource.MustParse("64Mi"))}
+			cluster = testing.NewCluster(kubevirtClusterName, kubevirtCluster)
+		})
+
+		It("should create the MigrationPolicy of the cluster, and delete it once the policy is removed", func() {
+			setupClient([]client.Object{cluster, kubevirtCluster})
+			infraClusterMock.EXPECT().GenerateInfraClusterClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeClient, kubevirtCluster.Namespace, nil).Times(2)
+			request := Request{NamespacedName: client.ObjectKeyFromObject(kubevirtCluster)}
+			name := kubevirt.MigrationPolicyName("KubevirtCluster", kubevirtCluster.Namespace, kubevirtCluster.Name)
+
+			_, err := kubevirtClusterReconciler.Reconcile(fakeContext, request)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			updated := &infrav1.KubevirtCluster{}
+			Expect(fakeClient.Get(fakeContext, request.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.MigrationPolicy).To(Equal(name))
+			policy := &migrationsv1alpha1.MigrationPolicy{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKey{Name: name}, policy)).To(Succeed())
+			Expect(policy.Labels).To(HaveKeyWithValue(clusterv1.ClusterNameLabel, cluster.Name))
+			Expect(policy.Spec.BandwidthPerMigration.String()).To(Equal("64Mi"))
+
+			updated.Spec.MigrationPolicy = nil
+			Expect(fakeClient.Update(fakeContext, updated)).To(Succeed())
+			_, err = kubevirtClusterReconciler.Reconcile(fakeContext, request)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(fakeClient.Get(fakeContext, request.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.MigrationPolicy).To(BeEmpty())
+			Expect(apierrors.IsNotFound(fakeClient.Get(fakeContext, client.ObjectKey{Name: name}, policy))).To(BeTrue())
+		})
+	})
+
+	Context("reconcile the descheduler eviction of the VMs", func() {
+		var kubevirtMachines []*infrav1.KubevirtMachine
+		var machines []*clusterv1.Machine
+
+		BeforeEach(func() {
+			clusterName = "test-cluster"
+			kubevirtClusterName = "test-kubevirt-cluster"
+			kubevirtCluster = testing.NewKubevirtCluster(kubevirtClusterName, kubevirtClusterName)
+			kubevirtCluster.Finalizers = []string{infrav1.ClusterFinalizer}
+			kubevirtCluster.Spec.ControlPlaneEndpointPublisher = &infrav1.EndpointPublisherSpec{Type: infrav1.StaticEndpointPublisher}
+			kubevirtCluster.Spec.ControlPlaneEndpoint = infrav1.APIEndpoint{Host: "10.0.0.10", Port: 6443}
+			kubevirtCluster.Spec.DeschedulerEviction = infrav1.AllowDeschedulerEviction
+			cluster = testing.NewCluster(kubevirtClusterName, kubevirtCluster)
+
+			kubevirtMachines, machines = nil, nil
+			for _, name := range []string{"cp-a", "cp-b", "worker"} {
+				kubevirtMachine := testing.NewKubevirtMachine(name, name+"-machine")
+				kubevirtMachine.Labels = map[string]string{clusterv1.ClusterNameLabel: cluster.Name}
+				kubevirtMachine.Status.Ready = true
+				machine := testing.NewMachine(cluster.Name, name+"-machine", kubevirtMachine)
+				if name != "worker" {
+					machine.Labels[clusterv1.MachineControlPlaneLabel] = ""
+				}
+				kubevirtMachines = append(kubevirtMachines, kubevirtMachine)
+				machines = append(machines, machine)
+			}
+		})
+
+		reconcilePods := func() (ctrl.Result, map[string]map[string]string) {
+			objects := []client.Object{cluster, kubevirtCluster}
+			for i := range kubevirtMachines {
+				objects = append(objects, kubevirtMachines[i], machines[i], &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace:   kubevirtCluster.Namespace,
+						Name:        "virt-launcher-" + kubevirtMachines[i].Name,
+						Labels:      map[string]string{kubevirtv1.AppLabel: "virt-launcher", kubevirtv1.VirtualMachineNameLabel: kubevirtMachines[i].Name},
+						Annotations: map[string]string{kubevirt.DeschedulerPreferNoEvictionAnnotation: "true"},
+					},
+				})
+			}
+			setupClient(objects)
+			infraClusterMock.EXPECT().GenerateInfraClusterClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeClient, kubevirtCluster.Namespace, nil)
+
+			res, err := kubevirtClusterReconciler.Reconcile(fakeContext, Request{NamespacedName: client.ObjectKeyFromObject(kubevirtCluster)})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			annotations := map[string]map[string]string{}
+			for _, kubevirtMachine := range kubevirtMachines {
+				pod := &corev1.Pod{}
+				Expect(fakeClient.Get(fakeContext, client.ObjectKey{Namespace: kubevirtCluster.Namespace, Name: "virt-launcher-" + kubevirtMachine.Name}, pod)).To(Succeed())
+				annotations[kubevirtMachine.Name] = pod.Annotations
+			}
+			return res, annotations
+		}
+
+		evictable := map[string]string{kubevirt.DeschedulerEvictAnnotation: "true"}
+		protected := map[string]string{kubevirt.DeschedulerPreferNoEvictionAnnotation: "true"}
+
+		It("should make the VMs eligible while the control plane can spare one", func() {
+			res, annotations := reconcilePods()
+			Expect(res.RequeueAfter).To(BeNumerically(">", 0))
+			Expect(annotations).To(Equal(map[string]map[string]string{"cp-a": evictable, "cp-b": evictable, "worker": evictable}))
+		})
+
+		It("should protect the control plane VMs while one of them is migrated", func() {
+			kubevirtMachines[1].Status.VirtualMachineInstance = &infrav1.VirtualMachineInstanceInfo{NodeName: "node1", MigrationTargetNode: "node2"}
+
+			_, annotations := reconcilePods()
+			Expect(annotations).To(Equal(map[string]map[string]string{"cp-a": protected, "cp-b": protected, "worker": evictable}))
+		})
+
+		It("should protect all the VMs when the descheduler eviction is prevented", func() {
+			kubevirtCluster.Spec.DeschedulerEviction = infrav1.PreventDeschedulerEviction
+
+			res, annotations := reconcilePods()
+			Expect(res.RequeueAfter).To(BeZero())
+			Expect(annotations).To(Equal(map[string]map[string]string{"cp-a": protected, "cp-b": protected, "worker": protected}))
+		})
+	})
+
+	Context("report the orphaned volumes of the cluster", func() {
+		BeforeEach(func() {
+			clusterName = "test-cluster"
+			kubevirtClusterName = "test-kubevirt-cluster"
+			kubevirtCluster = testing.NewKubevirtCluster(kubevirtClusterName, kubevirtClusterName)
+			kubevirtCluster.Finalizers = []string{infrav1.ClusterFinalizer}
+			kubevirtCluster.Spec.ControlPlaneEndpointPublisher = &infrav1.EndpointPublisherSpec{Type: infrav1.StaticEndpointPublisher}
+			kubevirtCluster.Spec.ControlPlaneEndpoint = infrav1.APIEndpoint{Host: "10.0.0.10", Port: 6443}
+			cluster = testing.NewCluster(kubevirtClusterName, kubevirtCluster)
+		})
+
+		It("should report the PVCs left by the deleted machines of the cluster", func() {
+			orphaned := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: kubevirtCluster.Namespace,
+					Name:      "root",
+					Labels:    map[string]string{clusterv1.ClusterNameLabel: cluster.Name, infrav1.OrphanedFromMachineLabel: "test-machine"},
+				},
+				Status: corev1.PersistentVolumeClaimStatus{
+					Phase:    corev1.ClaimBound,
+					Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+				},
+			}
+			inUse := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: kubevirtCluster.Namespace,
+					Name:      "data",
+					Labels:    map[string]string{clusterv1.ClusterNameLabel: cluster.Name},
+				},
+			}
+			setupClient([]client.Object{cluster, kubevirtCluster, orphaned, inUse})
+			infraClusterMock.EXPECT().GenerateInfraClusterClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeClient, kubevirtCluster.Namespace, nil)
+			request := Request{NamespacedName: client.ObjectKeyFromObject(kubevirtCluster)}
+
+			_, err := kubevirtClusterReconciler.Reconcile(fakeContext, request)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			updated := &infrav1.KubevirtCluster{}
+			Expect(fakeClient.Get(fakeContext, request.NamespacedName, updated)).To(Succeed())
+			Expect(updated.Status.OrphanedVolumes).To(Equal([]infrav1.OrphanedVolume{{Name: "root", Machine: "test-machine", Capacity: "10Gi"}}))
+		})
+	})
+
+	Context("report the resources consumed by the cluster VMs", func() {
+		BeforeEach(func() {
+			clusterName = "test-cluster"
+			kubevirtClusterName = "test-kubevirt-cluster"
+			kubevirtCluster = testing.NewKubevirtCluster(kubevirtClusterName, kubevirtClusterName)
+			kubevirtCluster.Finalizers = []string{infrav1.ClusterFinalizer}
+			kubevirtCluster.Spec.ControlPlaneEndpoint = infrav1.APIEndpoint{Host: "10.0.0.1", Port: 6443}
+			cluster = testing.NewCluster(kubevirtClusterName, kubevirtCluster)
+
+			kubevirtMachine := testing.NewKubevirtMachine("test-kubevirt-machine", "test-machine")
+			kubevirtMachine.Labels = map[string]string{clusterv1.ClusterNameLabel: cluster.Name}
+			vmi := testing.NewVirtualMachineInstance(kubevirtMachine)
+			vmi.Spec.Domain.CPU = &kubevirtv1.CPU{Cores: 2}
+			vmi.Spec.Domain.Memory = &kubevirtv1.Memory{Guest: ptr.To(resource.MustParse("4Gi"))}
+			vm := testing.NewVirtualMachine(vmi)
+			vm.Status.Created = true
+			vm.Spec.DataVolumeTemplates = []kubevirtv1.DataVolumeTemplateSpec{{ObjectMeta: metav1.ObjectMeta{Name: "root"}}}
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Namespace: vm.Namespace, Name: "root"},
+				Status: corev1.PersistentVolumeClaimStatus{
+					Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("20Gi")},
+				},
+			}
+			setupClient([]client.Object{cluster, kubevirtCluster, kubevirtMachine, vm, vmi, pvc})
+		})
+
+		It("should sum the vCPUs, the memory and the storage of the cluster VMs", func() {
+			infraClusterMock.EXPECT().GenerateInfraClusterClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeClient, kubevirtCluster.Namespace, nil)
+			_, err := kubevirtClusterReconciler.Reconcile(fakeContext, Request{NamespacedName: client.ObjectKeyFromObject(kubevirtCluster)})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			kvc := &infrav1.KubevirtCluster{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(kubevirtCluster), kvc)).To(Succeed())
+			Expect(kvc.Status.ResourceUsage).ToNot(BeNil())
+			Expect(kvc.Status.ResourceUsage.VirtualMachines).To(Equal(int32(1)))
+			Expect(kvc.Status.ResourceUsage.CPU.Value()).To(Equal(int64(2)))
+			Expect(kvc.Status.ResourceUsage.Memory.Equal(resource.MustParse("4Gi"))).To(BeTrue())
+			Expect(kvc.Status.ResourceUsage.Storage.Equal(resource.MustParse("20Gi"))).To(BeTrue())
+		})
+	})
+
+	Context("report the encryption of the disks of the cluster VMs", func() {
+		var (
+			kubevirtMachine *infrav1.KubevirtMachine
+			vm              *kubevirtv1.VirtualMachine
+		)
+
+		BeforeEach(func() {
+			clusterName = "test-cluster"
+			kubevirtClusterName = "test-kubevirt-cluster"
+			kubevirtCluster = testing.NewKubevirtCluster(kubevirtClusterName, kubevirtClusterName)
+			kubevirtCluster.Finalizers = []string{infrav1.ClusterFinalizer}
+			kubevirtCluster.Spec.ControlPlaneEndpoint = infrav1.APIEndpoint{Host: "10.0.0.1", Port: 6443}
+			kubevirtCluster.Spec.DiskEncryption = &infrav1.DiskEncryptionSpec{
+				Mode:           infrav1.StorageClassDiskEncryptionMode,
+				StorageClasses: map[string]string{"fast": "fast-encrypted"},
+			}
+			cluster = testing.NewCluster(kubevirtClusterName, kubevirtCluster)
+
+			kubevirtMachine = testing.NewKubevirtMachine("test-kubevirt-machine", "test-machine")
+			kubevirtMachine.Labels = map[string]string{clusterv1.ClusterNameLabel: cluster.Name}
+			vm = testing.NewVirtualMachine(testing.NewVirtualMachineInstance(kubevirtMachine))
+			vm.Spec.Template = &kubevirtv1.VirtualMachineInstanceTemplateSpec{}
+			vm.Spec.Template.Spec.Volumes = []kubevirtv1.Volume{
+				{Name: "root", VolumeSource: kubevirtv1.VolumeSource{DataVolume: &kubevirtv1.DataVolumeSource{Name: "test-kubevirt-machine-root"}}},
+				{Name: "data", VolumeSource: kubevirtv1.VolumeSource{PersistentVolumeClaim: &kubevirtv1.PersistentVolumeClaimVolumeSource{
+					PersistentVolumeClaimVolumeSource: corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"},
+				}}},
+			}
+		})
+
+		reconcileDiskEncryption := func(objects ...client.Object) *infrav1.DiskEncryptionStatus {
+			setupClient(append([]client.Object{cluster, kubevirtCluster, kubevirtMachine, vm}, objects...))
+			infraClusterMock.EXPECT().GenerateInfraClusterClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeClient, kubevirtCluster.Namespace, nil)
+			_, err := kubevirtClusterReconciler.Reconcile(fakeContext, Request{NamespacedName: client.ObjectKeyFromObject(kubevirtCluster)})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			kvc := &infrav1.KubevirtCluster{}
+			Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(kubevirtCluster), kvc)).To(Succeed())
+			return kvc.Status.DiskEncryption
+		}
+
+		newPVC := func(name, storageClassName string) *corev1.PersistentVolumeClaim {
+			return &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Namespace: vm.Namespace, Name: name},
+				Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: ptr.To(storageClassName)},
+			}
+		}
+
+		It("should report the disks of the VMs not of an encrypted storage class", func() {
+			status := reconcileDiskEncryption(newPVC("test-kubevirt-machine-root", "fast-encrypted"), newPVC("data", "fast"))
+			Expect(status).ToNot(BeNil())
+			Expect(status.Honored).To(BeFalse())
+			Expect(status.UnencryptedDisks).To(Equal([]string{vm.Name + "/data"}))
+		})
+
+		It("should report the policy honored when all the disks are encrypted", func() {
+			status := reconcileDiskEncryption(newPVC("test-kubevirt-machine-root", "fast-encrypted"), newPVC("data", "fast-encrypted"))
+			Expect(status).ToNot(BeNil())
+			Expect(status.Honored).To(BeTrue())
+			Expect(status.UnencryptedDisks).To(BeEmpty())
+		})
+
+		It("should not report anything without a disk encryption policy", func() {
+			kubevirtCluster.Spec.DiskEncryption = nil
+			kubevirtCluster.Status.DiskEncryption = &infrav1.DiskEncryptionStatus{Honored: true}
+
+			Expect(reconcileDiskEncryption()).To(BeNil())
+		})
+	})
+
 	Context("reconcile cluster with finalizer and deletion time stamp", func() {
 		BeforeEach(func() {
 			clusterName = "test-cluster"
@@ -180,6 +1755,26 @@ var _ = Describe("Reconcile", func() {
 			err = fakeClient.Get(fakeContext, namespacedName, kvc)
 			Expect(err).Should(HaveOccurred())
 		})
+
+		It("should keep the finalizer of the kubevirt cluster being deleted in read-only mode", func() {
+			objects := []client.Object{
+				cluster,
+				kubevirtCluster,
+			}
+			setupClient(objects)
+			kubevirtClusterReconciler.ReadOnly = true
+			infraClusterMock.EXPECT().GenerateInfraClusterClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(client.NewDryRunClient(fakeClient), kubevirtCluster.Namespace, nil)
+
+			namespacedName := client.ObjectKeyFromObject(kubevirtCluster)
+			_, err := kubevirtClusterReconciler.Reconcile(fakeContext, Request{
+				NamespacedName: namespacedName,
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			kvc := &infrav1.KubevirtCluster{}
+			Expect(fakeClient.Get(fakeContext, namespacedName, kvc)).To(Succeed())
+			Expect(kvc.Finalizers).To(ContainElement(infrav1.ClusterFinalizer))
+		})
 	})
 
 	Context("Compute Control Plane LB service namespace values precedence before it's created", func() {
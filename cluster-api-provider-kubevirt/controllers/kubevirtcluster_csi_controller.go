@@ -0,0 +1,182 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	gocontext "context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/hibernation"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/infracluster"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirtcsi"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/workloadcluster"
+)
+
+// KubevirtClusterCSIReconciler deploys the KubeVirt CSI driver of the workload clusters of the KubevirtClusters
+// requesting it, and reports it in their CSIDriverAvailable condition.
+type KubevirtClusterCSIReconciler struct {
+	client.Client
+	// APIReader reads the objects of the controller service of the CSI driver, which are not cached.
+	APIReader       client.Reader
+	InfraCluster    infracluster.InfraCluster
+	WorkloadCluster workloadcluster.WorkloadCluster
+	Recorder        record.EventRecorder
+	Log             logr.Logger
+	// ReadOnly only reports the Deployment of the controller service, which runs in the management cluster, for the
+	// controller deployed with read-only infra and workload cluster clients.
+	ReadOnly bool
+}
+
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtclusters,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtclusters/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cdi.kubevirt.io,resources=datavolumes,verbs=get;create;delete
+// +kubebuilder:rbac:groups=subresources.kubevirt.io,resources=virtualmachineinstances/addvolume;virtualmachineinstances/removevolume,verbs=update
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get
+
+// Reconcile deploys the controller service of the CSI driver once the infra cluster is known to provide hotplug
+// volumes, and its node service and storage classes once the control plane of the workload cluster is
+// initialized. The controller service is deleted with the cluster by the KubevirtCluster controller.
+func (r *KubevirtClusterCSIReconciler) Reconcile(goctx gocontext.Context, req ctrl.Request) (_ ctrl.Result, rerr error) {
+	log := ctrl.LoggerFrom(goctx)
+
+	kubevirtCluster := &infrav1.KubevirtCluster{}
+	if err := r.Client.Get(goctx, req.NamespacedName, kubevirtCluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if kubevirtCluster.Spec.CSIDriver == nil || !kubevirtCluster.DeletionTimestamp.IsZero() || isDryRun(kubevirtCluster) || isInfraOwnedElsewhere(kubevirtCluster) {
+		return ctrl.Result{}, nil
+	}
+
+	cluster, err := util.GetOwnerCluster(goctx, r.Client, kubevirtCluster.ObjectMeta)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if cluster == nil {
+		log.Info("Waiting for Cluster Controller to set OwnerRef on KubevirtCluster")
+		return ctrl.Result{}, nil
+	}
+
+	clusterContext := &context.ClusterContext{
+		Context:         goctx,
+		Cluster:         cluster,
+		KubevirtCluster: kubevirtCluster,
+		Logger:          ctrl.LoggerFrom(goctx).WithName(req.Namespace).WithName(req.Name),
+	}
+
+	patchHelper, err := patch.NewHelper(kubevirtCluster, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	defer func() {
+		if err := patchHelper.Patch(goctx, kubevirtCluster, patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{
+			infrav1.CSIDriverAvailableCondition,
+		}}); err != nil && rerr == nil {
+			rerr = errors.Wrap(err, "failed to patch KubevirtCluster")
+		}
+	}()
+
+	res, err := r.reconcileCSIDriver(clusterContext)
+	if err != nil {
+		conditions.MarkFalse(kubevirtCluster, infrav1.CSIDriverAvailableCondition, infrav1.CSIDriverDeploymentFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+	}
+	return res, err
+}
+
+func (r *KubevirtClusterCSIReconciler) reconcileCSIDriver(ctx *context.ClusterContext) (ctrl.Result, error) {
+	// The volumes of the CSI driver are hotplugged to the VMs
+	if !kubevirt.IsInfraFeatureAvailable(ctx.KubevirtCluster.Status.Infra, kubevirt.HotplugVolumesFeature) {
+		conditions.MarkFalse(ctx.KubevirtCluster, infrav1.CSIDriverAvailableCondition, infrav1.InfraFeatureUnavailableReason, clusterv1.ConditionSeverityWarning,
+			"the HotplugVolumes feature gate of KubeVirt is not enabled in the infra cluster")
+		return ctrl.Result{}, nil
+	}
+
+	_, infraClusterNamespace, err := r.InfraCluster.GenerateInfraClusterClient(ctx.KubevirtCluster.Spec.InfraClusterSecretRef, ctx.KubevirtCluster.Namespace, ctx.Context)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, errors.Wrap(err, "failed to generate infra cluster client")
+	}
+
+	if r.ReadOnly {
+		recordPlannedChange(r.Recorder, ctx.KubevirtCluster, "create or update", "Deployment", ctx.Cluster.Namespace, kubevirtcsi.Name(ctx.Cluster))
+	} else if err := kubevirtcsi.ReconcileController(ctx, r.APIReader, r.Client, infraClusterNamespace); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to deploy the controller service of the CSI driver")
+	}
+
+	// The controller service is scaled down, and the node service left as is, while the cluster is hibernated
+	if hibernation.IsClusterHibernated(ctx.KubevirtCluster) {
+		return ctrl.Result{}, nil
+	}
+
+	// The apiserver of the workload cluster is not available before the first control plane node is up
+	if !conditions.IsTrue(ctx.Cluster, clusterv1.ControlPlaneInitializedCondition) {
+		conditions.MarkFalse(ctx.KubevirtCluster, infrav1.CSIDriverAvailableCondition, clusterv1.WaitingForControlPlaneAvailableReason, clusterv1.ConditionSeverityInfo, "")
+		return ctrl.Result{}, nil
+	}
+
+	workloadClusterClient, err := r.WorkloadCluster.GenerateClusterClient(ctx)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, errors.Wrap(err, "failed to create workload cluster client")
+	}
+
+	if err := kubevirtcsi.ReconcileNode(ctx, workloadClusterClient); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to deploy the node service of the CSI driver")
+	}
+
+	conditions.MarkTrue(ctx.KubevirtCluster, infrav1.CSIDriverAvailableCondition)
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager will add watches for this controller.
+func (r *KubevirtClusterCSIReconciler) SetupWithManager(ctx gocontext.Context, mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("kubevirtcluster-csi").
+		For(&infrav1.KubevirtCluster{}).
+		WithEventFilter(predicates.ResourceNotPaused(r.Log)).
+		WithEventFilter(predicates.ResourceIsNotExternallyManaged(r.Log)).
+		Watches(
+			&clusterv1.Cluster{},
+			handler.EnqueueRequestsFromMapFunc(util.ClusterToInfrastructureMapFunc(
+				ctx,
+				infrav1.GroupVersion.WithKind("KubevirtCluster"),
+				mgr.GetClient(),
+				&infrav1.KubevirtCluster{},
+			)),
+			builder.WithPredicates(predicates.ClusterUnpaused(r.Log)),
+		).
+		Complete(r)
+}
@@ -0,0 +1,141 @@
+package controllers_test
+
+import (
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/controllers"
+	infraclustermock "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/infracluster/mock"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirtcsi"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+	workloadclustermock "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/workloadcluster/mock"
+)
+
+var _ = Describe("Reconcile the CSI driver", func() {
+	var (
+		infraClusterMock          *infraclustermock.MockInfraCluster
+		workloadClusterMock       *workloadclustermock.MockWorkloadCluster
+		fakeWorkloadClusterClient client.Client
+		reconciler                controllers.KubevirtClusterCSIReconciler
+		request                   ctrl.Request
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		infraClusterMock = infraclustermock.NewMockInfraCluster(mockCtrl)
+		workloadClusterMock = workloadclustermock.NewMockWorkloadCluster(mockCtrl)
+
+		kubevirtCluster = testing.NewKubevirtCluster("test-cluster", "test-kubevirt-cluster")
+		kubevirtCluster.Spec.CSIDriver = &infrav1.CSIDriverSpec{
+			StorageClasses: []infrav1.StorageClassMapping{{Name: "kubevirt", InfraStorageClassName: "ceph-block", Default: true}},
+		}
+		cluster = testing.NewCluster("test-cluster", kubevirtCluster)
+		conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
+		request = ctrl.Request{NamespacedName: client.ObjectKeyFromObject(kubevirtCluster)}
+
+		fakeWorkloadClusterClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).Build()
+	})
+
+	setupClient := func() {
+		objects := []client.Object{cluster, kubevirtCluster}
+		fakeClient = fake.NewClientBuilder().
+			WithScheme(testing.SetupScheme()).
+			WithObjects(objects...).
+			WithStatusSubresource(objects...).
+			Build()
+		reconciler = controllers.KubevirtClusterCSIReconciler{
+			Client:          fakeClient,
+			APIReader:       fakeClient,
+			InfraCluster:    infraClusterMock,
+			WorkloadCluster: workloadClusterMock,
+			Log:             testLogger,
+		}
+	}
+
+	getAvailableCondition := func() *clusterv1.Condition {
+		updated := &infrav1.KubevirtCluster{}
+		Expect(fakeClient.Get(fakeContext, request.NamespacedName, updated)).To(Succeed())
+		return conditions.Get(updated, infrav1.CSIDriverAvailableCondition)
+	}
+
+	It("should deploy the controller and the node services of the CSI driver", func() {
+		setupClient()
+		infraClusterMock.EXPECT().GenerateInfraClusterClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeClient, kubevirtCluster.Namespace, nil)
+		workloadClusterMock.EXPECT().GenerateClusterClient(gomock.Any()).Return(fakeWorkloadClusterClient, nil)
+
+		_, err := reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fakeClient.Get(fakeContext, client.ObjectKey{Namespace: cluster.Namespace, Name: kubevirtcsi.Name(cluster)}, &appsv1.Deployment{})).To(Succeed())
+		Expect(fakeWorkloadClusterClient.Get(fakeContext, client.ObjectKey{Namespace: kubevirtcsi.NodeNamespace, Name: "kubevirt-csi-node"}, &appsv1.DaemonSet{})).To(Succeed())
+		Expect(fakeWorkloadClusterClient.Get(fakeContext, client.ObjectKey{Name: "kubevirt"}, &storagev1.StorageClass{})).To(Succeed())
+
+		available := getAvailableCondition()
+		Expect(available).ToNot(BeNil())
+		Expect(available.Status).To(Equal(corev1.ConditionTrue))
+	})
+
+	It("should only deploy the controller service until the control plane is initialized", func() {
+		conditions.MarkFalse(cluster, clusterv1.ControlPlaneInitializedCondition, "Provisioning", clusterv1.ConditionSeverityInfo, "")
+		setupClient()
+		infraClusterMock.EXPECT().GenerateInfraClusterClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeClient, kubevirtCluster.Namespace, nil)
+
+		_, err := reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fakeClient.Get(fakeContext, client.ObjectKey{Namespace: cluster.Namespace, Name: kubevirtcsi.Name(cluster)}, &appsv1.Deployment{})).To(Succeed())
+		available := getAvailableCondition()
+		Expect(available).ToNot(BeNil())
+		Expect(available.Reason).To(Equal(clusterv1.WaitingForControlPlaneAvailableReason))
+	})
+
+	It("should only report the controller service of the CSI driver when read-only", func() {
+		conditions.MarkFalse(cluster, clusterv1.ControlPlaneInitializedCondition, "Provisioning", clusterv1.ConditionSeverityInfo, "")
+		setupClient()
+		recorder := record.NewFakeRecorder(10)
+		reconciler.Recorder = recorder
+		reconciler.ReadOnly = true
+		infraClusterMock.EXPECT().GenerateInfraClusterClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeClient, kubevirtCluster.Namespace, nil)
+
+		_, err := reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fakeClient.Get(fakeContext, client.ObjectKey{Namespace: cluster.Namespace, Name: kubevirtcsi.Name(cluster)}, &appsv1.Deployment{})).ToNot(Succeed())
+		Expect(recorder.Events).To(Receive(ContainSubstring("Would create or update Deployment " + cluster.Namespace + "/" + kubevirtcsi.Name(cluster))))
+	})
+
+	It("should not deploy the CSI driver while another management cluster owns the infra resources", func() {
+		conditions.MarkFalse(kubevirtCluster, infrav1.InfraOwnershipCondition, infrav1.InfraOwnedElsewhereReason, clusterv1.ConditionSeverityWarning, "")
+		setupClient()
+
+		_, err := reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fakeClient.Get(fakeContext, client.ObjectKey{Namespace: cluster.Namespace, Name: kubevirtcsi.Name(cluster)}, &appsv1.Deployment{})).ToNot(Succeed())
+	})
+
+	It("should not deploy the CSI driver when the infra cluster does not provide hotplug volumes", func() {
+		kubevirtCluster.Status.Infra = &infrav1.InfraStatus{KubeVirtVersion: "v1.2.1"}
+		setupClient()
+
+		_, err := reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fakeClient.Get(fakeContext, client.ObjectKey{Namespace: cluster.Namespace, Name: kubevirtcsi.Name(cluster)}, &appsv1.Deployment{})).ToNot(Succeed())
+		available := getAvailableCondition()
+		Expect(available).ToNot(BeNil())
+		Expect(available.Status).To(Equal(corev1.ConditionFalse))
+		Expect(available.Reason).To(Equal(infrav1.InfraFeatureUnavailableReason))
+	})
+})
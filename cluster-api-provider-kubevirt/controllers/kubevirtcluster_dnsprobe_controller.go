@@ -0,0 +1,244 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	gocontext "context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/dnsprobe"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/workloadcluster"
+)
+
+const (
+	// defaultWorkloadDNSProbeInterval is the interval between the probes of the DNS of a workload cluster, when the
+	// KubevirtCluster does not set it.
+	defaultWorkloadDNSProbeInterval = 10 * time.Minute
+
+	// workloadDNSProbeTimeout is the time given to a probe to complete, from its start, e.g. to pull its image.
+	workloadDNSProbeTimeout = 5 * time.Minute
+)
+
+// KubevirtClusterDNSProbeReconciler probes the DNS and the service network of the workload clusters of the
+// KubevirtClusters requesting it.
+type KubevirtClusterDNSProbeReconciler struct {
+	client.Client
+	WorkloadCluster workloadcluster.WorkloadCluster
+	Log             logr.Logger
+}
+
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtclusters,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtclusters/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// Reconcile probes the DNS and the service network of the workload cluster periodically, once its addons are
+// applied, and reports the result of the last probe in the WorkloadDNSHealthy condition of the KubevirtCluster.
+func (r *KubevirtClusterDNSProbeReconciler) Reconcile(goctx gocontext.Context, req ctrl.Request) (_ ctrl.Result, rerr error) {
+	log := ctrl.LoggerFrom(goctx)
+
+	kubevirtCluster := &infrav1.KubevirtCluster{}
+	if err := r.Client.Get(goctx, req.NamespacedName, kubevirtCluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !kubevirtCluster.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+	probed := conditions.Has(kubevirtCluster, infrav1.WorkloadDNSHealthyCondition) || kubevirtCluster.Status.WorkloadDNSProbeTime != nil
+	if kubevirtCluster.Spec.WorkloadDNSProbe == nil && !probed {
+		return ctrl.Result{}, nil
+	}
+
+	cluster, err := util.GetOwnerCluster(goctx, r.Client, kubevirtCluster.ObjectMeta)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if cluster == nil {
+		log.Info("Waiting for Cluster Controller to set OwnerRef on KubevirtCluster")
+		return ctrl.Result{}, nil
+	}
+
+	clusterContext := &context.ClusterContext{
+		Context:         goctx,
+		Cluster:         cluster,
+		KubevirtCluster: kubevirtCluster,
+		Logger:          ctrl.LoggerFrom(goctx).WithName(req.Namespace).WithName(req.Name),
+	}
+
+	patchHelper, err := patch.NewHelper(kubevirtCluster, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	defer func() {
+		if err := patchHelper.Patch(goctx, kubevirtCluster, patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{
+			infrav1.WorkloadDNSHealthyCondition,
+		}}); err != nil && rerr == nil {
+			rerr = errors.Wrap(err, "failed to patch KubevirtCluster")
+		}
+	}()
+
+	if kubevirtCluster.Spec.WorkloadDNSProbe == nil {
+		r.removeDNSProbe(clusterContext)
+		return ctrl.Result{}, nil
+	}
+
+	if !conditions.IsTrue(cluster, clusterv1.ControlPlaneReadyCondition) {
+		log.Info("Waiting for the control plane to be ready before probing the DNS")
+		return ctrl.Result{}, nil
+	}
+	if kubevirtCluster.Spec.CSIDriver != nil && !conditions.IsTrue(kubevirtCluster, infrav1.CSIDriverAvailableCondition) {
+		log.Info("Waiting for the CSI driver to be deployed before probing the DNS")
+		return ctrl.Result{}, nil
+	}
+
+	return r.reconcileDNSProbe(clusterContext, time.Now())
+}
+
+func (r *KubevirtClusterDNSProbeReconciler) reconcileDNSProbe(ctx *context.ClusterContext, now time.Time) (ctrl.Result, error) {
+	spec := ctx.KubevirtCluster.Spec.WorkloadDNSProbe
+
+	interval := defaultWorkloadDNSProbeInterval
+	if spec.Interval != nil && spec.Interval.Duration > 0 {
+		interval = spec.Interval.Duration
+	}
+	if last := ctx.KubevirtCluster.Status.WorkloadDNSProbeTime; last != nil && now.Before(last.Add(interval)) {
+		return ctrl.Result{RequeueAfter: last.Add(interval).Sub(now)}, nil
+	}
+
+	workloadClusterClient, err := r.WorkloadCluster.GenerateClusterClient(ctx)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, errors.Wrap(err, "failed to create workload cluster client")
+	}
+
+	// The nodes are only ready once the CNI of the cluster runs
+	ready, err := hasReadyNode(ctx, workloadClusterClient)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !ready {
+		ctx.Logger.Info("Waiting for a ready node before probing the DNS")
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	if !conditions.Has(ctx.KubevirtCluster, infrav1.WorkloadDNSHealthyCondition) {
+		ctx.Logger.Info("Starting the first DNS probe of the workload cluster")
+		conditions.MarkFalse(ctx.KubevirtCluster, infrav1.WorkloadDNSHealthyCondition, infrav1.WorkloadDNSProbeRunningReason, clusterv1.ConditionSeverityInfo, "")
+	}
+
+	if err := dnsprobe.Start(ctx, workloadClusterClient, spec); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	status, err := dnsprobe.Check(ctx, workloadClusterClient)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	timedOut := !status.StartTime.IsZero() && now.Sub(status.StartTime) > workloadDNSProbeTimeout
+	if !status.Completed && !timedOut {
+		ctx.Logger.Info("Waiting for the DNS probe to complete...")
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+
+	if err := dnsprobe.Cleanup(ctx, workloadClusterClient); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	switch {
+	case !status.Completed:
+		conditions.MarkFalse(ctx.KubevirtCluster, infrav1.WorkloadDNSHealthyCondition, infrav1.WorkloadDNSUnhealthyReason, clusterv1.ConditionSeverityWarning,
+			fmt.Sprintf("DNS probe did not complete within %s", workloadDNSProbeTimeout))
+	case status.Failure != "":
+		ctx.Logger.Info("DNS probe of the workload cluster failed", "failure", status.Failure)
+		conditions.MarkFalse(ctx.KubevirtCluster, infrav1.WorkloadDNSHealthyCondition, infrav1.WorkloadDNSUnhealthyReason, clusterv1.ConditionSeverityWarning, status.Failure)
+	default:
+		conditions.MarkTrue(ctx.KubevirtCluster, infrav1.WorkloadDNSHealthyCondition)
+	}
+	ctx.KubevirtCluster.Status.WorkloadDNSProbeTime = &metav1.Time{Time: now}
+
+	return ctrl.Result{RequeueAfter: interval}, nil
+}
+
+// removeDNSProbe removes the result of the probe once it is disabled, and its namespace from the workload cluster
+// when it can be reached.
+func (r *KubevirtClusterDNSProbeReconciler) removeDNSProbe(ctx *context.ClusterContext) {
+	workloadClusterClient, err := r.WorkloadCluster.GenerateClusterClient(ctx)
+	if err == nil {
+		err = dnsprobe.Remove(ctx, workloadClusterClient)
+	}
+	if err != nil {
+		ctx.Logger.Error(err, "Failed to remove the DNS probe from the workload cluster")
+	}
+
+	conditions.Delete(ctx.KubevirtCluster, infrav1.WorkloadDNSHealthyCondition)
+	ctx.KubevirtCluster.Status.WorkloadDNSProbeTime = nil
+}
+
+func hasReadyNode(ctx gocontext.Context, c client.Client) (bool, error) {
+	nodes := &corev1.NodeList{}
+	if err := c.List(ctx, nodes); err != nil {
+		return false, errors.Wrap(err, "failed to list workload cluster nodes")
+	}
+	for i := range nodes.Items {
+		if nodeReadyStatus(&nodes.Items[i]) == corev1.ConditionTrue {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SetupWithManager will add watches for this controller.
+func (r *KubevirtClusterDNSProbeReconciler) SetupWithManager(ctx gocontext.Context, mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("kubevirtcluster-dnsprobe").
+		For(&infrav1.KubevirtCluster{}).
+		WithEventFilter(predicates.ResourceNotPaused(r.Log)).
+		WithEventFilter(predicates.ResourceIsNotExternallyManaged(r.Log)).
+		Watches(
+			&clusterv1.Cluster{},
+			handler.EnqueueRequestsFromMapFunc(util.ClusterToInfrastructureMapFunc(
+				ctx,
+				infrav1.GroupVersion.WithKind("KubevirtCluster"),
+				mgr.GetClient(),
+				&infrav1.KubevirtCluster{},
+			)),
+			builder.WithPredicates(predicates.ClusterUnpaused(r.Log)),
+		).
+		Complete(r)
+}
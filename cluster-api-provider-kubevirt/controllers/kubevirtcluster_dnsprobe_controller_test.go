@@ -0,0 +1,190 @@
+package controllers_test
+
+import (
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/controllers"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/dnsprobe"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+	workloadclustermock "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/workloadcluster/mock"
+)
+
+var _ = Describe("Reconcile the DNS probe", func() {
+	var (
+		workloadClusterMock       *workloadclustermock.MockWorkloadCluster
+		fakeWorkloadClusterClient client.Client
+		reconciler                controllers.KubevirtClusterDNSProbeReconciler
+		request                   ctrl.Request
+		readyNode                 *corev1.Node
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		workloadClusterMock = workloadclustermock.NewMockWorkloadCluster(mockCtrl)
+
+		kubevirtCluster = testing.NewKubevirtCluster("test-cluster", "test-kubevirt-cluster")
+		kubevirtCluster.Spec.WorkloadDNSProbe = &infrav1.WorkloadDNSProbeSpec{}
+		cluster = testing.NewCluster("test-cluster", kubevirtCluster)
+		conditions.MarkTrue(cluster, clusterv1.ControlPlaneReadyCondition)
+		request = ctrl.Request{NamespacedName: client.ObjectKeyFromObject(kubevirtCluster)}
+
+		readyNode = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		}
+	})
+
+	setupClient := func(workloadObjects ...client.Object) {
+		objects := []client.Object{cluster, kubevirtCluster}
+		fakeClient = fake.NewClientBuilder().
+			WithScheme(testing.SetupScheme()).
+			WithObjects(objects...).
+			WithStatusSubresource(objects...).
+			Build()
+		fakeWorkloadClusterClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(workloadObjects...).Build()
+		reconciler = controllers.KubevirtClusterDNSProbeReconciler{
+			Client:          fakeClient,
+			WorkloadCluster: workloadClusterMock,
+			Log:             testLogger,
+		}
+	}
+
+	getUpdatedCluster := func() *infrav1.KubevirtCluster {
+		updated := &infrav1.KubevirtCluster{}
+		Expect(fakeClient.Get(fakeContext, request.NamespacedName, updated)).To(Succeed())
+		return updated
+	}
+
+	completeJob := func(conditionType batchv1.JobConditionType, podMessage string) {
+		job := &batchv1.Job{}
+		Expect(fakeWorkloadClusterClient.Get(fakeContext, client.ObjectKey{Namespace: dnsprobe.Namespace, Name: "capk-dns-probe"}, job)).To(Succeed())
+		job.Status.Conditions = append(job.Status.Conditions, batchv1.JobCondition{
+			Type:    conditionType,
+			Status:  corev1.ConditionTrue,
+			Message: "BackoffLimitExceeded",
+		})
+		Expect(fakeWorkloadClusterClient.Status().Update(fakeContext, job)).To(Succeed())
+
+		if podMessage != "" {
+			Expect(fakeWorkloadClusterClient.Create(fakeContext, &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: dnsprobe.Namespace, Name: "capk-dns-probe-x1", Labels: map[string]string{"app": "capk-dns-probe"}},
+				Status: corev1.PodStatus{
+					ContainerStatuses: []corev1.ContainerStatus{{
+						Name:  "probe",
+						State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Message: podMessage + "\n"}},
+					}},
+				},
+			})).To(Succeed())
+		}
+	}
+
+	It("should not probe the DNS when it is not requested", func() {
+		kubevirtCluster.Spec.WorkloadDNSProbe = nil
+		setupClient()
+
+		result, err := reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{}))
+		Expect(conditions.Get(getUpdatedCluster(), infrav1.WorkloadDNSHealthyCondition)).To(BeNil())
+	})
+
+	It("should wait for a ready node before probing the DNS", func() {
+		setupClient()
+		workloadClusterMock.EXPECT().GenerateClusterClient(gomock.Any()).Return(fakeWorkloadClusterClient, nil)
+
+		result, err := reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(30 * time.Second))
+		Expect(conditions.Get(getUpdatedCluster(), infrav1.WorkloadDNSHealthyCondition)).To(BeNil())
+	})
+
+	It("should wait for the CSI driver to be deployed", func() {
+		kubevirtCluster.Spec.CSIDriver = &infrav1.CSIDriverSpec{}
+		setupClient(readyNode)
+
+		result, err := reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{}))
+		Expect(conditions.Get(getUpdatedCluster(), infrav1.WorkloadDNSHealthyCondition)).To(BeNil())
+	})
+
+	It("should probe the DNS, and mark it healthy until the next probe", func() {
+		setupClient(readyNode)
+		workloadClusterMock.EXPECT().GenerateClusterClient(gomock.Any()).Return(fakeWorkloadClusterClient, nil).Times(2)
+
+		result, err := reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(10 * time.Second))
+		Expect(conditions.GetReason(getUpdatedCluster(), infrav1.WorkloadDNSHealthyCondition)).To(Equal(infrav1.WorkloadDNSProbeRunningReason))
+
+		completeJob(batchv1.JobComplete, "")
+
+		result, err = reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(10 * time.Minute))
+		updated := getUpdatedCluster()
+		Expect(conditions.IsTrue(updated, infrav1.WorkloadDNSHealthyCondition)).To(BeTrue())
+		Expect(updated.Status.WorkloadDNSProbeTime).ToNot(BeNil())
+
+		err = fakeWorkloadClusterClient.Get(fakeContext, client.ObjectKey{Namespace: dnsprobe.Namespace, Name: "capk-dns-probe"}, &batchv1.Job{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		// The next probe waits for the interval
+		result, err = reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically("~", 10*time.Minute, time.Minute))
+	})
+
+	It("should report the step of the probe that failed", func() {
+		setupClient(readyNode)
+		workloadClusterMock.EXPECT().GenerateClusterClient(gomock.Any()).Return(fakeWorkloadClusterClient, nil).Times(2)
+
+		_, err := reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+
+		completeJob(batchv1.JobFailed, "lookup 3/5 of kubernetes.default.svc failed or timed out")
+
+		_, err = reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+
+		healthy := conditions.Get(getUpdatedCluster(), infrav1.WorkloadDNSHealthyCondition)
+		Expect(healthy).ToNot(BeNil())
+		Expect(healthy.Status).To(Equal(corev1.ConditionFalse))
+		Expect(healthy.Reason).To(Equal(infrav1.WorkloadDNSUnhealthyReason))
+		Expect(healthy.Severity).To(Equal(clusterv1.ConditionSeverityWarning))
+		Expect(healthy.Message).To(Equal("DNS probe failed: lookup 3/5 of kubernetes.default.svc failed or timed out"))
+	})
+
+	It("should remove the result of the probe once it is disabled", func() {
+		kubevirtCluster.Spec.WorkloadDNSProbe = nil
+		conditions.MarkTrue(kubevirtCluster, infrav1.WorkloadDNSHealthyCondition)
+		kubevirtCluster.Status.WorkloadDNSProbeTime = &metav1.Time{Time: time.Now()}
+		setupClient(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: dnsprobe.Namespace}})
+		workloadClusterMock.EXPECT().GenerateClusterClient(gomock.Any()).Return(fakeWorkloadClusterClient, nil)
+
+		_, err := reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+
+		updated := getUpdatedCluster()
+		Expect(conditions.Has(updated, infrav1.WorkloadDNSHealthyCondition)).To(BeFalse())
+		Expect(updated.Status.WorkloadDNSProbeTime).To(BeNil())
+		err = fakeWorkloadClusterClient.Get(fakeContext, client.ObjectKey{Name: dnsprobe.Namespace}, &corev1.Namespace{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,176 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	gocontext "context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/infracluster"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/prewarm"
+)
+
+// imagePrewarmingCheckInterval is the interval between two checks of the pull of the images in progress. The
+// DaemonSets of the infra clusters are not watched.
+const imagePrewarmingCheckInterval = 30 * time.Second
+
+// KubevirtClusterPrewarmReconciler pulls the containerDisk images of the machines of the KubevirtClusters requesting
+// it on the infra nodes ahead of time, and reports it in their ImagesPrewarmed condition.
+type KubevirtClusterPrewarmReconciler struct {
+	client.Client
+	InfraCluster infracluster.InfraCluster
+	Log          logr.Logger
+}
+
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtclusters,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtclusters/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtmachines;kubevirtmachinetemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;machinedeployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;create;update;delete
+
+// Reconcile deploys the DaemonSet pulling the images of a KubevirtCluster in its infra namespace, and deletes it
+// once image prewarming is disabled. The DaemonSet of a deleted cluster is deleted by the KubevirtCluster
+// controller.
+func (r *KubevirtClusterPrewarmReconciler) Reconcile(goctx gocontext.Context, req ctrl.Request) (_ ctrl.Result, rerr error) {
+	log := ctrl.LoggerFrom(goctx)
+
+	kubevirtCluster := &infrav1.KubevirtCluster{}
+	if err := r.Client.Get(goctx, req.NamespacedName, kubevirtCluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !kubevirtCluster.DeletionTimestamp.IsZero() || isDryRun(kubevirtCluster) || isInfraOwnedElsewhere(kubevirtCluster) {
+		return ctrl.Result{}, nil
+	}
+	if kubevirtCluster.Spec.ImagePrewarming == nil && !conditions.Has(kubevirtCluster, infrav1.ImagesPrewarmedCondition) {
+		return ctrl.Result{}, nil
+	}
+
+	cluster, err := util.GetOwnerCluster(goctx, r.Client, kubevirtCluster.ObjectMeta)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if cluster == nil {
+		log.Info("Waiting for Cluster Controller to set OwnerRef on KubevirtCluster")
+		return ctrl.Result{}, nil
+	}
+
+	clusterContext := &context.ClusterContext{
+		Context:         goctx,
+		Cluster:         cluster,
+		KubevirtCluster: kubevirtCluster,
+		Logger:          ctrl.LoggerFrom(goctx).WithName(req.Namespace).WithName(req.Name),
+	}
+
+	patchHelper, err := patch.NewHelper(kubevirtCluster, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	defer func() {
+		if err := patchHelper.Patch(goctx, kubevirtCluster, patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{
+			infrav1.ImagesPrewarmedCondition,
+		}}); err != nil && rerr == nil {
+			rerr = errors.Wrap(err, "failed to patch KubevirtCluster")
+		}
+	}()
+
+	res, err := r.reconcileImagePrewarming(clusterContext)
+	if err != nil {
+		conditions.MarkFalse(kubevirtCluster, infrav1.ImagesPrewarmedCondition, infrav1.ImagePrewarmingFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+	}
+	return res, err
+}
+
+func (r *KubevirtClusterPrewarmReconciler) reconcileImagePrewarming(ctx *context.ClusterContext) (ctrl.Result, error) {
+	infraClusterClient, infraClusterNamespace, err := r.InfraCluster.GenerateInfraClusterClient(ctx.KubevirtCluster.Spec.InfraClusterSecretRef, ctx.KubevirtCluster.Namespace, ctx.Context)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, errors.Wrap(err, "failed to generate infra cluster client")
+	}
+
+	if ctx.KubevirtCluster.Spec.ImagePrewarming == nil {
+		if err := prewarm.Delete(ctx, infraClusterClient, infraClusterNamespace); err != nil {
+			return ctrl.Result{}, err
+		}
+		conditions.Delete(ctx.KubevirtCluster, infrav1.ImagesPrewarmedCondition)
+		return ctrl.Result{}, nil
+	}
+
+	images, err := prewarm.Images(ctx, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	daemonSet, err := prewarm.Reconcile(ctx, infraClusterClient, infraClusterNamespace, images)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to deploy the pods pulling the images")
+	}
+
+	if ready, nodes, desired := prewarm.IsReady(daemonSet); !ready {
+		conditions.MarkFalse(ctx.KubevirtCluster, infrav1.ImagesPrewarmedCondition, infrav1.PrewarmingImagesReason, clusterv1.ConditionSeverityInfo,
+			"%d images pulled on %d of %d infra nodes", len(images), nodes, desired)
+		return ctrl.Result{RequeueAfter: imagePrewarmingCheckInterval}, nil
+	}
+
+	conditions.MarkTrue(ctx.KubevirtCluster, infrav1.ImagesPrewarmedCondition)
+	return ctrl.Result{}, nil
+}
+
+// clusterObjectToKubevirtCluster maps a KubevirtMachine or a MachineDeployment to the KubevirtCluster of its
+// cluster, to pull the images of new machine templates.
+func (r *KubevirtClusterPrewarmReconciler) clusterObjectToKubevirtCluster(ctx gocontext.Context, o client.Object) []ctrl.Request {
+	clusterName, ok := o.GetLabels()[clusterv1.ClusterNameLabel]
+	if !ok {
+		return nil
+	}
+	cluster := &clusterv1.Cluster{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: o.GetNamespace(), Name: clusterName}, cluster); err != nil {
+		return nil
+	}
+	infrastructureRef := cluster.Spec.InfrastructureRef
+	if infrastructureRef == nil || infrastructureRef.Kind != "KubevirtCluster" {
+		return nil
+	}
+	return []ctrl.Request{{NamespacedName: client.ObjectKey{Namespace: cluster.Namespace, Name: infrastructureRef.Name}}}
+}
+
+// SetupWithManager will add watches for this controller.
+func (r *KubevirtClusterPrewarmReconciler) SetupWithManager(_ gocontext.Context, mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("kubevirtcluster-prewarm").
+		For(&infrav1.KubevirtCluster{}).
+		WithEventFilter(predicates.ResourceNotPaused(r.Log)).
+		WithEventFilter(predicates.ResourceIsNotExternallyManaged(r.Log)).
+		Watches(&infrav1.KubevirtMachine{}, handler.EnqueueRequestsFromMapFunc(r.clusterObjectToKubevirtCluster)).
+		Watches(&clusterv1.MachineDeployment{}, handler.EnqueueRequestsFromMapFunc(r.clusterObjectToKubevirtCluster)).
+		Complete(r)
+}
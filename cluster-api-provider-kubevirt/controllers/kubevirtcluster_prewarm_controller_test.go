@@ -0,0 +1,116 @@
+package controllers_test
+
+import (
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/controllers"
+	infraclustermock "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/infracluster/mock"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/prewarm"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("Reconcile the image prewarming", func() {
+	var (
+		infraClusterMock *infraclustermock.MockInfraCluster
+		reconciler       controllers.KubevirtClusterPrewarmReconciler
+		request          ctrl.Request
+		daemonSetKey     client.ObjectKey
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		infraClusterMock = infraclustermock.NewMockInfraCluster(mockCtrl)
+
+		kubevirtCluster = testing.NewKubevirtCluster("test-cluster", "test-kubevirt-cluster")
+		kubevirtCluster.Spec.ImagePrewarming = &infrav1.ImagePrewarmingSpec{Images: []string{"quay.io/containerdisks/fedora:40"}}
+		cluster = testing.NewCluster("test-cluster", kubevirtCluster)
+		request = ctrl.Request{NamespacedName: client.ObjectKeyFromObject(kubevirtCluster)}
+		daemonSetKey = client.ObjectKey{Namespace: "infra", Name: prewarm.Name(cluster)}
+	})
+
+	setupClient := func() {
+		objects := []client.Object{cluster, kubevirtCluster}
+		fakeClient = fake.NewClientBuilder().
+			WithScheme(testing.SetupScheme()).
+			WithObjects(objects...).
+			WithStatusSubresource(objects...).
+			Build()
+		reconciler = controllers.KubevirtClusterPrewarmReconciler{
+			Client:       fakeClient,
+			InfraCluster: infraClusterMock,
+			Log:          testLogger,
+		}
+		infraClusterMock.EXPECT().GenerateInfraClusterClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeClient, "infra", nil).AnyTimes()
+	}
+
+	reconcile := func() ctrl.Result {
+		result, err := reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+		return result
+	}
+
+	getPrewarmedCondition := func() *clusterv1.Condition {
+		updated := &infrav1.KubevirtCluster{}
+		Expect(fakeClient.Get(fakeContext, request.NamespacedName, updated)).To(Succeed())
+		return conditions.Get(updated, infrav1.ImagesPrewarmedCondition)
+	}
+
+	It("should report the images prewarmed once they are pulled on all the infra nodes", func() {
+		setupClient()
+
+		Expect(reconcile()).To(Equal(ctrl.Result{RequeueAfter: 30 * time.Second}))
+		daemonSet := &appsv1.DaemonSet{}
+		Expect(fakeClient.Get(fakeContext, daemonSetKey, daemonSet)).To(Succeed())
+		Expect(daemonSet.Spec.Template.Spec.InitContainers).To(ContainElement(HaveField("Image", "quay.io/containerdisks/fedora:40")))
+		daemonSet.Status = appsv1.DaemonSetStatus{DesiredNumberScheduled: 2, UpdatedNumberScheduled: 2, NumberReady: 1}
+		daemonSet.Status.ObservedGeneration = 1
+		Expect(fakeClient.Status().Update(fakeContext, daemonSet)).To(Succeed())
+
+		reconcile()
+		Expect(getPrewarmedCondition().Status).To(Equal(corev1.ConditionFalse))
+		Expect(getPrewarmedCondition().Reason).To(Equal(infrav1.PrewarmingImagesReason))
+		Expect(getPrewarmedCondition().Message).To(Equal("1 images pulled on 1 of 2 infra nodes"))
+
+		Expect(fakeClient.Get(fakeContext, daemonSetKey, daemonSet)).To(Succeed())
+		daemonSet.Status.NumberReady = 2
+		Expect(fakeClient.Status().Update(fakeContext, daemonSet)).To(Succeed())
+
+		Expect(reconcile()).To(Equal(ctrl.Result{}))
+		Expect(getPrewarmedCondition().Status).To(Equal(corev1.ConditionTrue))
+	})
+
+	It("should delete the pods pulling the images once image prewarming is disabled", func() {
+		setupClient()
+		reconcile()
+
+		updated := &infrav1.KubevirtCluster{}
+		Expect(fakeClient.Get(fakeContext, request.NamespacedName, updated)).To(Succeed())
+		updated.Spec.ImagePrewarming = nil
+		Expect(fakeClient.Update(fakeContext, updated)).To(Succeed())
+
+		reconcile()
+		Expect(apierrors.IsNotFound(fakeClient.Get(fakeContext, daemonSetKey, &appsv1.DaemonSet{}))).To(BeTrue())
+		Expect(getPrewarmedCondition()).To(BeNil())
+	})
+
+	It("should not deploy the pods pulling the images while another management cluster owns the infra resources", func() {
+		conditions.MarkFalse(kubevirtCluster, infrav1.InfraOwnershipCondition, infrav1.InfraOwnedElsewhereReason, clusterv1.ConditionSeverityWarning, "")
+		setupClient()
+
+		Expect(reconcile()).To(Equal(ctrl.Result{}))
+		Expect(apierrors.IsNotFound(fakeClient.Get(fakeContext, daemonSetKey, &appsv1.DaemonSet{}))).To(BeTrue())
+	})
+})
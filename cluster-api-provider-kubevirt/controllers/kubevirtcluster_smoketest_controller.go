@@ -0,0 +1,183 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	gocontext "context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/smoketest"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/workloadcluster"
+)
+
+// smokeTestTimeout is the time given to the smoke test to pass, from its start.
+const smokeTestTimeout = 15 * time.Minute
+
+// KubevirtClusterSmokeTestReconciler runs the smoke test of the workload clusters of the KubevirtClusters
+// requesting one.
+type KubevirtClusterSmokeTestReconciler struct {
+	client.Client
+	WorkloadCluster workloadcluster.WorkloadCluster
+	Log             logr.Logger
+}
+
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtclusters,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtclusters/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// Reconcile runs the smoke test of the workload cluster once its control plane is ready, and reports the result
+// in the ClusterVerified condition of the KubevirtCluster. The test only runs once.
+func (r *KubevirtClusterSmokeTestReconciler) Reconcile(goctx gocontext.Context, req ctrl.Request) (_ ctrl.Result, rerr error) {
+	log := ctrl.LoggerFrom(goctx)
+
+	kubevirtCluster := &infrav1.KubevirtCluster{}
+	if err := r.Client.Get(goctx, req.NamespacedName, kubevirtCluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if kubevirtCluster.Spec.SmokeTest == nil || !kubevirtCluster.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	if verified := conditions.Get(kubevirtCluster, infrav1.ClusterVerifiedCondition); verified != nil &&
+		(verified.Status == "True" || verified.Reason == infrav1.SmokeTestFailedReason) {
+		return ctrl.Result{}, nil
+	}
+
+	cluster, err := util.GetOwnerCluster(goctx, r.Client, kubevirtCluster.ObjectMeta)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if cluster == nil {
+		log.Info("Waiting for Cluster Controller to set OwnerRef on KubevirtCluster")
+		return ctrl.Result{}, nil
+	}
+
+	if !conditions.IsTrue(cluster, clusterv1.ControlPlaneReadyCondition) {
+		log.Info("Waiting for the control plane to be ready before running the smoke test")
+		return ctrl.Result{}, nil
+	}
+
+	clusterContext := &context.ClusterContext{
+		Context:         goctx,
+		Cluster:         cluster,
+		KubevirtCluster: kubevirtCluster,
+		Logger:          ctrl.LoggerFrom(goctx).WithName(req.Namespace).WithName(req.Name),
+	}
+
+	patchHelper, err := patch.NewHelper(kubevirtCluster, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	defer func() {
+		if err := patchHelper.Patch(goctx, kubevirtCluster, patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{
+			infrav1.ClusterVerifiedCondition,
+		}}); err != nil && rerr == nil {
+			rerr = errors.Wrap(err, "failed to patch KubevirtCluster")
+		}
+	}()
+
+	return r.reconcileSmokeTest(clusterContext)
+}
+
+func (r *KubevirtClusterSmokeTestReconciler) reconcileSmokeTest(ctx *context.ClusterContext) (ctrl.Result, error) {
+	spec := ctx.KubevirtCluster.Spec.SmokeTest
+
+	// The transition time of the condition records the start of the test
+	if !conditions.Has(ctx.KubevirtCluster, infrav1.ClusterVerifiedCondition) {
+		ctx.Logger.Info("Starting the smoke test of the workload cluster")
+		conditions.MarkFalse(ctx.KubevirtCluster, infrav1.ClusterVerifiedCondition, infrav1.SmokeTestRunningReason, clusterv1.ConditionSeverityInfo, "")
+	}
+
+	workloadClusterClient, err := r.WorkloadCluster.GenerateClusterClient(ctx)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, errors.Wrap(err, "failed to create workload cluster client")
+	}
+
+	if err := smoketest.Start(ctx, workloadClusterClient, spec); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	status, err := smoketest.Check(ctx, workloadClusterClient, spec)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	started := conditions.GetLastTransitionTime(ctx.KubevirtCluster, infrav1.ClusterVerifiedCondition)
+	timedOut := started != nil && time.Since(started.Time) > smokeTestTimeout
+	if !status.Completed && !timedOut {
+		ctx.Logger.Info("Waiting for the smoke test to complete...")
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+
+	if err := smoketest.Cleanup(ctx, workloadClusterClient); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	switch {
+	case !status.Completed:
+		conditions.MarkFalse(ctx.KubevirtCluster, infrav1.ClusterVerifiedCondition, infrav1.SmokeTestFailedReason, clusterv1.ConditionSeverityError,
+			fmt.Sprintf("smoke test did not complete within %s", smokeTestTimeout))
+	case status.Failure != "":
+		conditions.MarkFalse(ctx.KubevirtCluster, infrav1.ClusterVerifiedCondition, infrav1.SmokeTestFailedReason, clusterv1.ConditionSeverityError, status.Failure)
+	default:
+		ctx.Logger.Info("Smoke test of the workload cluster passed")
+		conditions.MarkTrue(ctx.KubevirtCluster, infrav1.ClusterVerifiedCondition)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager will add watches for this controller.
+func (r *KubevirtClusterSmokeTestReconciler) SetupWithManager(ctx gocontext.Context, mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("kubevirtcluster-smoketest").
+		For(&infrav1.KubevirtCluster{}).
+		WithEventFilter(predicates.ResourceNotPaused(r.Log)).
+		WithEventFilter(predicates.ResourceIsNotExternallyManaged(r.Log)).
+		Watches(
+			&clusterv1.Cluster{},
+			handler.EnqueueRequestsFromMapFunc(util.ClusterToInfrastructureMapFunc(
+				ctx,
+				infrav1.GroupVersion.WithKind("KubevirtCluster"),
+				mgr.GetClient(),
+				&infrav1.KubevirtCluster{},
+			)),
+			builder.WithPredicates(predicates.ClusterUnpaused(r.Log)),
+		).
+		Complete(r)
+}
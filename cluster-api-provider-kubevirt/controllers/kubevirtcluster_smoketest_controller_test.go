@@ -0,0 +1,172 @@
+package controllers_test
+
+import (
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/controllers"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/smoketest"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+	workloadclustermock "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/workloadcluster/mock"
+)
+
+var _ = Describe("Reconcile the smoke test", func() {
+	var (
+		workloadClusterMock       *workloadclustermock.MockWorkloadCluster
+		fakeWorkloadClusterClient client.Client
+		reconciler                controllers.KubevirtClusterSmokeTestReconciler
+		request                   ctrl.Request
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		workloadClusterMock = workloadclustermock.NewMockWorkloadCluster(mockCtrl)
+
+		kubevirtCluster = testing.NewKubevirtCluster("test-cluster", "test-kubevirt-cluster")
+		kubevirtCluster.Spec.SmokeTest = &infrav1.SmokeTestSpec{}
+		cluster = testing.NewCluster("test-cluster", kubevirtCluster)
+		conditions.MarkTrue(cluster, clusterv1.ControlPlaneReadyCondition)
+		request = ctrl.Request{NamespacedName: client.ObjectKeyFromObject(kubevirtCluster)}
+
+		fakeWorkloadClusterClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).Build()
+	})
+
+	setupClient := func() {
+		objects := []client.Object{cluster, kubevirtCluster}
+		fakeClient = fake.NewClientBuilder().
+			WithScheme(testing.SetupScheme()).
+			WithObjects(objects...).
+			WithStatusSubresource(objects...).
+			Build()
+		reconciler = controllers.KubevirtClusterSmokeTestReconciler{
+			Client:          fakeClient,
+			WorkloadCluster: workloadClusterMock,
+			Log:             testLogger,
+		}
+	}
+
+	getVerifiedCondition := func() *clusterv1.Condition {
+		updated := &infrav1.KubevirtCluster{}
+		Expect(fakeClient.Get(fakeContext, request.NamespacedName, updated)).To(Succeed())
+		return conditions.Get(updated, infrav1.ClusterVerifiedCondition)
+	}
+
+	setJobCondition := func(conditionType batchv1.JobConditionType, message string) {
+		job := &batchv1.Job{}
+		Expect(fakeWorkloadClusterClient.Get(fakeContext, client.ObjectKey{Namespace: smoketest.Namespace, Name: "capk-smoke-test"}, job)).To(Succeed())
+		job.Status.Conditions = append(job.Status.Conditions, batchv1.JobCondition{
+			Type:    conditionType,
+			Status:  corev1.ConditionTrue,
+			Message: message,
+		})
+		Expect(fakeWorkloadClusterClient.Status().Update(fakeContext, job)).To(Succeed())
+	}
+
+	It("should not run the smoke test when it is not requested", func() {
+		kubevirtCluster.Spec.SmokeTest = nil
+		setupClient()
+
+		result, err := reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{}))
+		Expect(getVerifiedCondition()).To(BeNil())
+	})
+
+	It("should wait for the control plane to be ready", func() {
+		conditions.MarkFalse(cluster, clusterv1.ControlPlaneReadyCondition, "Provisioning", clusterv1.ConditionSeverityInfo, "")
+		setupClient()
+
+		result, err := reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{}))
+		Expect(getVerifiedCondition()).To(BeNil())
+	})
+
+	It("should start the smoke test and mark the cluster as verified once it passed", func() {
+		setupClient()
+		workloadClusterMock.EXPECT().GenerateClusterClient(gomock.Any()).Return(fakeWorkloadClusterClient, nil).Times(2)
+
+		result, err := reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(10 * time.Second))
+
+		verified := getVerifiedCondition()
+		Expect(verified).ToNot(BeNil())
+		Expect(verified.Status).To(Equal(corev1.ConditionFalse))
+		Expect(verified.Reason).To(Equal(infrav1.SmokeTestRunningReason))
+
+		setJobCondition(batchv1.JobComplete, "")
+
+		result, err = reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{}))
+
+		verified = getVerifiedCondition()
+		Expect(verified).ToNot(BeNil())
+		Expect(verified.Status).To(Equal(corev1.ConditionTrue))
+
+		namespace := &corev1.Namespace{}
+		err = fakeWorkloadClusterClient.Get(fakeContext, client.ObjectKey{Name: smoketest.Namespace}, namespace)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should report the failure of the smoke test", func() {
+		setupClient()
+		workloadClusterMock.EXPECT().GenerateClusterClient(gomock.Any()).Return(fakeWorkloadClusterClient, nil).Times(2)
+
+		_, err := reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+
+		setJobCondition(batchv1.JobFailed, "BackoffLimitExceeded")
+
+		result, err := reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{}))
+
+		verified := getVerifiedCondition()
+		Expect(verified).ToNot(BeNil())
+		Expect(verified.Status).To(Equal(corev1.ConditionFalse))
+		Expect(verified.Reason).To(Equal(infrav1.SmokeTestFailedReason))
+		Expect(verified.Severity).To(Equal(clusterv1.ConditionSeverityError))
+		Expect(verified.Message).To(ContainSubstring("BackoffLimitExceeded"))
+
+		// The smoke test does not run again
+		result, err = reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{}))
+	})
+
+	It("should fail the smoke test when it does not complete in time", func() {
+		kubevirtCluster.Status.Conditions = clusterv1.Conditions{{
+			Type:               infrav1.ClusterVerifiedCondition,
+			Status:             corev1.ConditionFalse,
+			Reason:             infrav1.SmokeTestRunningReason,
+			Severity:           clusterv1.ConditionSeverityInfo,
+			LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Hour)),
+		}}
+		setupClient()
+		workloadClusterMock.EXPECT().GenerateClusterClient(gomock.Any()).Return(fakeWorkloadClusterClient, nil)
+
+		result, err := reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{}))
+
+		verified := getVerifiedCondition()
+		Expect(verified).ToNot(BeNil())
+		Expect(verified.Reason).To(Equal(infrav1.SmokeTestFailedReason))
+		Expect(verified.Message).To(ContainSubstring("did not complete"))
+	})
+})
@@ -0,0 +1,162 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	gocontext "context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/tenantcni"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/workloadcluster"
+)
+
+// tenantCNIResyncPeriod is the period the CNI configuration of the workload clusters is checked at.
+const tenantCNIResyncPeriod = 5 * time.Minute
+
+// KubevirtClusterTenantCNIReconciler checks the kube-proxy replacement of the workload clusters of the
+// KubevirtClusters requesting it against their control plane endpoint.
+type KubevirtClusterTenantCNIReconciler struct {
+	client.Client
+	WorkloadCluster workloadcluster.WorkloadCluster
+	Log             logr.Logger
+}
+
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtclusters,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtclusters/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// Reconcile detects the kube-proxy replacement of the workload cluster once its control plane is initialized, and
+// reports whether it reaches the API server through the control plane endpoint in the TenantCNICompatible
+// condition of the KubevirtCluster.
+func (r *KubevirtClusterTenantCNIReconciler) Reconcile(goctx gocontext.Context, req ctrl.Request) (_ ctrl.Result, rerr error) {
+	log := ctrl.LoggerFrom(goctx)
+
+	kubevirtCluster := &infrav1.KubevirtCluster{}
+	if err := r.Client.Get(goctx, req.NamespacedName, kubevirtCluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !kubevirtCluster.DeletionTimestamp.IsZero() || isDryRun(kubevirtCluster) {
+		return ctrl.Result{}, nil
+	}
+	if kubevirtCluster.Spec.TenantKubeProxyReplacement == "" && !conditions.Has(kubevirtCluster, infrav1.TenantCNICompatibleCondition) {
+		return ctrl.Result{}, nil
+	}
+
+	cluster, err := util.GetOwnerCluster(goctx, r.Client, kubevirtCluster.ObjectMeta)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if cluster == nil {
+		log.Info("Waiting for Cluster Controller to set OwnerRef on KubevirtCluster")
+		return ctrl.Result{}, nil
+	}
+
+	clusterContext := &context.ClusterContext{
+		Context:         goctx,
+		Cluster:         cluster,
+		KubevirtCluster: kubevirtCluster,
+		Logger:          ctrl.LoggerFrom(goctx).WithName(req.Namespace).WithName(req.Name),
+	}
+
+	patchHelper, err := patch.NewHelper(kubevirtCluster, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	defer func() {
+		if err := patchHelper.Patch(goctx, kubevirtCluster, patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{
+			infrav1.TenantCNICompatibleCondition,
+		}}); err != nil && rerr == nil {
+			rerr = errors.Wrap(err, "failed to patch KubevirtCluster")
+		}
+	}()
+
+	return r.reconcileTenantCNI(clusterContext)
+}
+
+func (r *KubevirtClusterTenantCNIReconciler) reconcileTenantCNI(ctx *context.ClusterContext) (ctrl.Result, error) {
+	mode := ctx.KubevirtCluster.Spec.TenantKubeProxyReplacement
+	if mode == "" {
+		conditions.Delete(ctx.KubevirtCluster, infrav1.TenantCNICompatibleCondition)
+		return ctrl.Result{}, nil
+	}
+
+	// The apiserver of the workload cluster is not available before the first control plane node is up
+	if !conditions.IsTrue(ctx.Cluster, clusterv1.ControlPlaneInitializedCondition) {
+		return ctrl.Result{}, nil
+	}
+
+	workloadClusterClient, err := r.WorkloadCluster.GenerateClusterClient(ctx)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, errors.Wrap(err, "failed to create workload cluster client")
+	}
+
+	config, err := tenantcni.Detect(ctx, workloadClusterClient, mode)
+	if err != nil {
+		conditions.MarkUnknown(ctx.KubevirtCluster, infrav1.TenantCNICompatibleCondition, infrav1.TenantCNIUnknownReason, "%s", err.Error())
+		return ctrl.Result{RequeueAfter: tenantCNIResyncPeriod}, nil
+	}
+
+	if failure := tenantcni.Validate(config, ctx.KubevirtCluster); failure != "" {
+		ctx.Logger.Info("The kube-proxy replacement of the workload cluster is misconfigured", "reason", failure)
+		conditions.MarkFalse(ctx.KubevirtCluster, infrav1.TenantCNICompatibleCondition, infrav1.KubeProxyReplacementMisconfiguredReason,
+			clusterv1.ConditionSeverityWarning, "%s", failure)
+	} else {
+		conditions.MarkTrue(ctx.KubevirtCluster, infrav1.TenantCNICompatibleCondition)
+	}
+
+	return ctrl.Result{RequeueAfter: tenantCNIResyncPeriod}, nil
+}
+
+// SetupWithManager will add watches for this controller.
+func (r *KubevirtClusterTenantCNIReconciler) SetupWithManager(ctx gocontext.Context, mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("kubevirtcluster-tenantcni").
+		For(&infrav1.KubevirtCluster{}).
+		WithEventFilter(predicates.ResourceNotPaused(r.Log)).
+		WithEventFilter(predicates.ResourceIsNotExternallyManaged(r.Log)).
+		Watches(
+			&clusterv1.Cluster{},
+			handler.EnqueueRequestsFromMapFunc(util.ClusterToInfrastructureMapFunc(
+				ctx,
+				infrav1.GroupVersion.WithKind("KubevirtCluster"),
+				mgr.GetClient(),
+				&infrav1.KubevirtCluster{},
+			)),
+			builder.WithPredicates(predicates.ClusterUnpaused(r.Log)),
+		).
+		Complete(r)
+}
@@ -0,0 +1,123 @@
+package controllers_test
+
+import (
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/controllers"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/tenantcni"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+	workloadclustermock "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/workloadcluster/mock"
+)
+
+var _ = Describe("Reconcile the CNI of the workload cluster", func() {
+	var (
+		workloadClusterMock *workloadclustermock.MockWorkloadCluster
+		reconciler          controllers.KubevirtClusterTenantCNIReconciler
+		request             ctrl.Request
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		workloadClusterMock = workloadclustermock.NewMockWorkloadCluster(mockCtrl)
+
+		kubevirtCluster = testing.NewKubevirtCluster("test-cluster", "test-kubevirt-cluster")
+		kubevirtCluster.Spec.ControlPlaneEndpoint = infrav1.APIEndpoint{Host: "10.0.0.10", Port: 6443}
+		kubevirtCluster.Spec.TenantKubeProxyReplacement = infrav1.DetectKubeProxyReplacement
+		cluster = testing.NewCluster("test-cluster", kubevirtCluster)
+		conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
+		request = ctrl.Request{NamespacedName: client.ObjectKeyFromObject(kubevirtCluster)}
+	})
+
+	setupClient := func() {
+		objects := []client.Object{cluster, kubevirtCluster}
+		fakeClient = fake.NewClientBuilder().
+			WithScheme(testing.SetupScheme()).
+			WithObjects(objects...).
+			WithStatusSubresource(objects...).
+			Build()
+		reconciler = controllers.KubevirtClusterTenantCNIReconciler{
+			Client:          fakeClient,
+			WorkloadCluster: workloadClusterMock,
+			Log:             testLogger,
+		}
+	}
+
+	reconcileWorkloadCluster := func(objects ...client.Object) *clusterv1.Condition {
+		setupClient()
+		workloadClusterClient := fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(objects...).Build()
+		workloadClusterMock.EXPECT().GenerateClusterClient(gomock.Any()).Return(workloadClusterClient, nil)
+
+		result, err := reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+		updated := &infrav1.KubevirtCluster{}
+		Expect(fakeClient.Get(fakeContext, request.NamespacedName, updated)).To(Succeed())
+		return conditions.Get(updated, infrav1.TenantCNICompatibleCondition)
+	}
+
+	ciliumConfig := func(data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceSystem, Name: tenantcni.CiliumConfigName},
+			Data:       data,
+		}
+	}
+
+	It("should not check the workload cluster without kube-proxy replacement mode", func() {
+		kubevirtCluster.Spec.TenantKubeProxyReplacement = ""
+		setupClient()
+
+		result, err := reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{}))
+	})
+
+	It("should accept a workload cluster running kube-proxy", func() {
+		condition := reconcileWorkloadCluster(
+			&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceSystem, Name: tenantcni.KubeProxyName}},
+			ciliumConfig(map[string]string{"kube-proxy-replacement": "false"}),
+		)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+	})
+
+	It("should accept a kube-proxy replacement reaching the API server through the control plane endpoint", func() {
+		condition := reconcileWorkloadCluster(ciliumConfig(map[string]string{
+			"kube-proxy-replacement": "true",
+			"k8s-service-host":       "10.0.0.10",
+			"k8s-service-port":       "6443",
+		}))
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(corev1.ConditionTrue))
+	})
+
+	It("should report a kube-proxy replacement reaching the API server through another address", func() {
+		condition := reconcileWorkloadCluster(ciliumConfig(map[string]string{
+			"kube-proxy-replacement": "true",
+			"k8s-service-host":       "10.0.0.21",
+			"k8s-service-port":       "6443",
+		}))
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(infrav1.KubeProxyReplacementMisconfiguredReason))
+		Expect(condition.Message).To(ContainSubstring("10.0.0.21:6443"))
+	})
+
+	It("should report a workload cluster without kube-proxy whose Cilium does not reach the API server directly", func() {
+		condition := reconcileWorkloadCluster(ciliumConfig(map[string]string{}))
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(corev1.ConditionFalse))
+		Expect(condition.Message).To(ContainSubstring("without k8s-service-host"))
+	})
+})
@@ -0,0 +1,188 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	gocontext "context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/infracluster"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/loadbalancer"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/workloadcluster"
+)
+
+// tenantLoadBalancerResyncPeriod is the period the tenant load balancers are reconciled at, to follow the changes
+// of the Nodes of the workload cluster.
+const tenantLoadBalancerResyncPeriod = time.Minute
+
+// KubevirtClusterTenantLoadBalancerReconciler implements the Services of type LoadBalancer of the workload
+// clusters of the KubevirtClusters requesting it with objects of a load balancer API of the infra cluster.
+type KubevirtClusterTenantLoadBalancerReconciler struct {
+	client.Client
+	InfraCluster    infracluster.InfraCluster
+	WorkloadCluster workloadcluster.WorkloadCluster
+	Log             logr.Logger
+	// WorkloadClusterWatcher watches the Services of the workload clusters; when nil, their changes are only
+	// noticed on resync.
+	WorkloadClusterWatcher *workloadcluster.Watcher
+
+	controller controller.Controller
+}
+
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtclusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+
+// Reconcile creates, updates and deletes the tenant load balancers of the workload cluster once its control
+// plane is initialized. The tenant load balancers are deleted with the cluster by the KubevirtCluster controller.
+func (r *KubevirtClusterTenantLoadBalancerReconciler) Reconcile(goctx gocontext.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(goctx)
+
+	kubevirtCluster := &infrav1.KubevirtCluster{}
+	if err := r.Client.Get(goctx, req.NamespacedName, kubevirtCluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if kubevirtCluster.Spec.TenantLoadBalancer == nil || !kubevirtCluster.DeletionTimestamp.IsZero() || isDryRun(kubevirtCluster) || isInfraOwnedElsewhere(kubevirtCluster) {
+		return ctrl.Result{}, nil
+	}
+
+	cluster, err := util.GetOwnerCluster(goctx, r.Client, kubevirtCluster.ObjectMeta)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if cluster == nil {
+		log.Info("Waiting for Cluster Controller to set OwnerRef on KubevirtCluster")
+		return ctrl.Result{}, nil
+	}
+
+	// The apiserver of the workload cluster is not available before the first control plane node is up
+	if !conditions.IsTrue(cluster, clusterv1.ControlPlaneInitializedCondition) {
+		return ctrl.Result{}, nil
+	}
+
+	clusterContext := &context.ClusterContext{
+		Context:         goctx,
+		Cluster:         cluster,
+		KubevirtCluster: kubevirtCluster,
+		Logger:          ctrl.LoggerFrom(goctx).WithName(req.Namespace).WithName(req.Name),
+	}
+
+	infraClusterClient, infraClusterNamespace, err := r.InfraCluster.GenerateInfraClusterClient(kubevirtCluster.Spec.InfraClusterSecretRef, kubevirtCluster.Namespace, goctx)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, errors.Wrap(err, "failed to generate infra cluster client")
+	}
+	if infraClusterClient == nil {
+		clusterContext.Logger.Info("Waiting for infra cluster client...")
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+
+	tenantLoadBalancers, err := loadbalancer.NewTenantLoadBalancers(clusterContext, infraClusterClient, GetLoadBalancerNamespace(kubevirtCluster, infraClusterNamespace))
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	workloadClusterClient, err := r.WorkloadCluster.GenerateClusterClient(clusterContext)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, errors.Wrap(err, "failed to create workload cluster client")
+	}
+
+	if err := r.watchWorkloadClusterServices(clusterContext); err != nil {
+		clusterContext.Logger.Error(err, "Failed to watch the services of the workload cluster")
+	}
+
+	if err := tenantLoadBalancers.Reconcile(clusterContext, workloadClusterClient); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{RequeueAfter: tenantLoadBalancerResyncPeriod}, nil
+}
+
+// deleteTenantLoadBalancers deletes the tenant load balancers of a deleted cluster, if it has any.
+func deleteTenantLoadBalancers(ctx *context.ClusterContext, infraClusterClient client.Client, namespace string) error {
+	if ctx.KubevirtCluster.Spec.TenantLoadBalancer == nil {
+		return nil
+	}
+
+	tenantLoadBalancers, err := loadbalancer.NewTenantLoadBalancers(ctx, infraClusterClient, namespace)
+	if err != nil {
+		return err
+	}
+	return tenantLoadBalancers.Delete(ctx)
+}
+
+// watchWorkloadClusterServices makes sure the Services of the workload cluster are watched, so that the tenant
+// load balancers are reconciled as soon as a Service changes.
+func (r *KubevirtClusterTenantLoadBalancerReconciler) watchWorkloadClusterServices(ctx *context.ClusterContext) error {
+	if r.WorkloadClusterWatcher == nil || r.controller == nil {
+		return nil
+	}
+
+	request := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(ctx.KubevirtCluster)}
+	return r.WorkloadClusterWatcher.Watch(ctx, workloadcluster.WatchInput{
+		Name:       "kubevirtcluster-watchServices",
+		Controller: r.controller,
+		Kind:       &corev1.Service{},
+		EventHandler: handler.EnqueueRequestsFromMapFunc(func(_ gocontext.Context, _ client.Object) []ctrl.Request {
+			return []ctrl.Request{request}
+		}),
+	})
+}
+
+// SetupWithManager will add watches for this controller.
+func (r *KubevirtClusterTenantLoadBalancerReconciler) SetupWithManager(ctx gocontext.Context, mgr ctrl.Manager) error {
+	c, err := ctrl.NewControllerManagedBy(mgr).
+		Named("kubevirtcluster-tenantloadbalancer").
+		For(&infrav1.KubevirtCluster{}).
+		WithEventFilter(predicates.ResourceNotPaused(r.Log)).
+		WithEventFilter(predicates.ResourceIsNotExternallyManaged(r.Log)).
+		Watches(
+			&clusterv1.Cluster{},
+			handler.EnqueueRequestsFromMapFunc(util.ClusterToInfrastructureMapFunc(
+				ctx,
+				infrav1.GroupVersion.WithKind("KubevirtCluster"),
+				mgr.GetClient(),
+				&infrav1.KubevirtCluster{},
+			)),
+			builder.WithPredicates(predicates.ClusterUnpaused(r.Log)),
+		).
+		Build(r)
+	if err != nil {
+		return err
+	}
+	r.controller = c
+	return nil
+}
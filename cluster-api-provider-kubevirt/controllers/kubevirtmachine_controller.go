@@ -18,8 +18,11 @@ package controllers
 
 import (
 	gocontext "context"
+	"encoding/json"
 	"fmt"
+	"path"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -29,6 +32,9 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
+	kubevirtv1 "kubevirt.io/api/core/v1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util"
@@ -45,28 +51,76 @@ import (
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/guestagent"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/hibernation"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/infracluster"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
 	kubevirthandler "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/loadbalancer"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/maintenance"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/operations"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/ownership"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/proxy"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/resources"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/ssh"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/workloadcluster"
 )
 
+// bootstrapDataRedeliveredReason is the reason of the events of the VMs restarted with new bootstrap data.
+const bootstrapDataRedeliveredReason = "BootstrapDataRedelivered"
+
+// infraObjectNotOwnedReason is the reason of the events of the infra objects left in place on deletion, because
+// the provider did not create them for the machine.
+const infraObjectNotOwnedReason = "InfraObjectNotOwned"
+
 // KubevirtMachineReconciler reconciles a KubevirtMachine object.
 type KubevirtMachineReconciler struct {
 	client.Client
 	InfraCluster    infracluster.InfraCluster
 	WorkloadCluster workloadcluster.WorkloadCluster
 	MachineFactory  kubevirt.MachineFactory
+	Recorder        record.EventRecorder
+	GuestAgent      guestagent.Runner
+	// WorkloadClusterWatcher watches the Nodes of the workload clusters; when nil, their changes are only noticed
+	// on resync.
+	WorkloadClusterWatcher *workloadcluster.Watcher
+	// BootstrapTokenTTL is the TTL of the join tokens of the bootstrap provider. The machines still pending after
+	// half of it request bootstrap data with a fresh token; when zero, no refresh is requested.
+	BootstrapTokenTTL time.Duration
+	// BootstrapTokenMinValidity is the validity the join token of a VM must have left when the VM starts. The start
+	// of the VMs whose token would expire sooner is held until the token is refreshed; when zero, no VM is held.
+	BootstrapTokenMinValidity time.Duration
+	// ReadOnly leaves the commands requested on the machines pending, as they may change the guests, keeps the
+	// finalizer of the deleted machines and sends the drains as dry-runs, for the controller deployed with read-only
+	// infra and workload cluster clients.
+	ReadOnly bool
+	// Operations runs the drains of the Nodes and the commands requested on the machines in the background; they
+	// hold the reconciles until they complete when nil.
+	Operations *operations.Tracker
+
+	controller controller.Controller
 }
 
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtmachines,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtmachines/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtmachineimages;kubevirtmachineimagechannels,verbs=get;list;watch
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;machines,verbs=get;list;watch
+// +kubebuilder:rbac:groups=bootstrap.cluster.x-k8s.io,resources=kubeadmconfigs,verbs=get;patch
 // +kubebuilder:rbac:groups="",resources=secrets;,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=kubevirt.io,resources=virtualmachines;,verbs=get;create;update;patch;delete
-// +kubebuilder:rbac:groups=kubevirt.io,resources=virtualmachineinstances;,verbs=get;delete
-// +kubebuilder:rbac:groups=cdi.kubevirt.io,resources=datavolumes;,verbs=get;list;watch
+// +kubebuilder:rbac:groups=kubevirt.io,resources=virtualmachineinstances;,verbs=get;list;watch;delete
+// +kubebuilder:rbac:groups=cdi.kubevirt.io,resources=datavolumes;,verbs=get;list;watch;update
+// +kubebuilder:rbac:groups=snapshot.kubevirt.io,resources=virtualmachinesnapshots,verbs=get;create
+// +kubebuilder:rbac:groups=export.kubevirt.io,resources=virtualmachineexports,verbs=get;create;delete
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;update
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=create;update
+// +kubebuilder:rbac:groups="",resources=pods,verbs=list
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get
+// +kubebuilder:rbac:groups="",resources=pods/exec,verbs=create
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=list
+// +kubebuilder:rbac:groups=cdi.kubevirt.io,resources=storageprofiles,verbs=get
+// +kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshotclasses,verbs=list
 
 // Reconcile handles KubevirtMachine events.
 func (r *KubevirtMachineReconciler) Reconcile(goctx gocontext.Context, req ctrl.Request) (_ ctrl.Result, rerr error) {
@@ -103,8 +157,19 @@ func (r *KubevirtMachineReconciler) Reconcile(goctx gocontext.Context, req ctrl.
 			Context:         goctx,
 			Machine:         machine,
 			KubevirtMachine: kubevirtMachine,
+			ReadOnly:        r.ReadOnly,
 			Logger:          ctrl.LoggerFrom(goctx).WithName(req.Namespace).WithName(req.Name),
 		}
+		// The VM belongs to the management cluster holding the infra ownership lease
+		if r.isMachineInfraOwnedElsewhere(goctx, machine) {
+			log.Info("Another management cluster owns the infra resources, leaving the VM alone")
+			patchHelper, err := patch.NewHelper(kubevirtMachine, r.Client)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			r.removeFinalizer(machineContext)
+			return ctrl.Result{}, patchHelper.Patch(goctx, kubevirtMachine)
+		}
 		return r.reconcileDelete(machineContext)
 	}
 
@@ -141,6 +206,8 @@ func (r *KubevirtMachineReconciler) Reconcile(goctx gocontext.Context, req ctrl.
 		KubevirtCluster: kubevirtCluster,
 		Machine:         machine,
 		KubevirtMachine: kubevirtMachine,
+		Operations:      r.Operations,
+		ReadOnly:        r.ReadOnly,
 		Logger:          ctrl.LoggerFrom(goctx).WithName(req.Namespace).WithName(req.Name),
 	}
 
@@ -173,6 +240,31 @@ func (r *KubevirtMachineReconciler) Reconcile(goctx gocontext.Context, req ctrl.
 		return ctrl.Result{}, nil
 	}
 
+	// Only report what would be done for machines of clusters annotated for dry-run
+	if isDryRun(kubevirtCluster) {
+		return r.reconcileDryRun(machineContext)
+	}
+
+	// Leave the VM stopped by the KubevirtCluster controller while the cluster is hibernated
+	if hibernation.IsClusterHibernated(kubevirtCluster) {
+		log.Info("KubevirtCluster is hibernated, waiting for it to be resumed")
+		conditions.MarkFalse(kubevirtMachine, infrav1.VMProvisionedCondition, infrav1.HibernatedReason, clusterv1.ConditionSeverityInfo, "")
+		kubevirtMachine.Status.Ready = false
+		return ctrl.Result{}, nil
+	}
+
+	// Leave the infra resources alone while another management cluster holds the infra ownership lease
+	if isInfraOwnedElsewhere(kubevirtCluster) {
+		log.Info("Another management cluster owns the infra resources, waiting for the infra ownership lease")
+		return ctrl.Result{RequeueAfter: time.Minute}, nil
+	}
+
+	if err := r.watchWorkloadClusterNodes(machineContext); err != nil {
+		log.Error(err, "Failed to watch the nodes of the workload cluster, relying on resync")
+	}
+
+	stampProviderVersion(kubevirtMachine)
+
 	// Handle non-deleted machines
 	res, err := r.reconcileNormal(machineContext)
 
@@ -180,7 +272,11 @@ func (r *KubevirtMachineReconciler) Reconcile(goctx gocontext.Context, req ctrl.
 		// Update the providerID on the Node
 		// The ProviderID on the Node and the providerID on  the KubevirtMachine are used to set the NodeRef
 		// This code is needed here as long as there is no Kubevirt cloud provider setting the providerID in the node
-		return r.updateNodeProviderID(machineContext)
+		res, err = r.updateNodeProviderID(machineContext)
+		if res.IsZero() && err == nil {
+			// Check the VM for drift again once the interval elapsed
+			res.RequeueAfter = vmDriftCheckInterval(kubevirtCluster)
+		}
 	}
 
 	return res, err
@@ -201,6 +297,15 @@ func (r *KubevirtMachineReconciler) reconcileNormal(ctx *context.MachineContext)
 		return ctrl.Result{}, nil
 	}
 
+	// Ask for a fresh join token before the one of a delayed machine expires
+	if ctx.KubevirtMachine.Spec.ProviderID == nil {
+		if err := r.reconcileBootstrapTokenRefresh(ctx); err != nil {
+			return ctrl.Result{}, err
+		}
+	} else {
+		ctx.KubevirtMachine.Status.BootstrapTokenExpiryTime = nil
+	}
+
 	// Fetch SSH keys to be used for cluster nodes, and update bootstrap script cloud-init with public key
 	var clusterNodeSshKeys *ssh.ClusterNodeSshKeys
 
@@ -246,40 +351,224 @@ func (r *KubevirtMachineReconciler) reconcileNormal(ctx *context.MachineContext)
 		return ctrl.Result{RequeueAfter: 10 * time.Second}, errors.Wrap(err, "failed to fetch kubevirt bootstrap secret")
 	}
 
+	if err := r.reconcileMachineMigrationPolicy(ctx, infraClusterClient); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to reconcile the migration policy")
+	}
+
 	// Create a helper for managing the KubeVirt VM hosting the machine.
 	externalMachine, err := r.MachineFactory.NewMachine(ctx, infraClusterClient, vmNamespace, clusterNodeSshKeys)
 	if err != nil {
 		return ctrl.Result{}, errors.Wrapf(err, "failed to create helper for managing the externalMachine")
 	}
 
+	// Take over a VM left behind by a previous incarnation of this KubevirtMachine, rather than failing to create it
+	adopted, err := externalMachine.Adopt(ctx.Context)
+	if err != nil {
+		conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.VMCreateFailedReason, clusterv1.ConditionSeverityError, err.Error())
+		return ctrl.Result{}, errors.Wrap(err, "failed to adopt VM")
+	}
+	if adopted {
+		ctx.Logger.Info("Adopted existing VM", "namespace", vmNamespace, "name", ctx.KubevirtMachine.Name)
+	}
+
+	if err := r.reconcileVMMetadata(ctx, infraClusterClient, vmNamespace); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Mirror the state of the VMI, so that users can follow their VMs without access to the infra cluster
+	previousVMI := ctx.KubevirtMachine.Status.VirtualMachineInstance
+	currentVMI := externalMachine.VMIStatus()
+	if currentVMI != nil && currentVMI.NodeName != "" {
+		currentVMI.Zone = infraNodeZone(ctx, infraClusterClient, currentVMI.NodeName)
+		// The VMI started on, or migrated to, another infra node: update the topology labels of the workload Node
+		if previousVMI == nil || previousVMI.NodeName != currentVMI.NodeName || previousVMI.Zone != currentVMI.Zone {
+			ctx.KubevirtMachine.Status.NodeUpdated = false
+		}
+	}
+	ctx.KubevirtMachine.Status.VirtualMachineInstance = currentVMI
+	reconcileGuestAgentCondition(ctx, time.Now())
+
 	isTerminal, terminalReason, err := externalMachine.IsTerminal()
 	if err != nil {
 		return ctrl.Result{}, errors.Wrapf(err, "failed checking VM for terminal state")
 	}
+	// Leave the failed or missing VMs of clusters in maintenance alone, to avoid replacing them for a transient issue
+	if maintenance.InMaintenance(ctx.KubevirtCluster, time.Now()) && (isTerminal || (!externalMachine.Exists() && ctx.KubevirtMachine.Spec.ProviderID != nil)) {
+		ctx.Logger.Info("KubevirtCluster is in maintenance, not replacing the VM")
+		conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.InMaintenanceReason, clusterv1.ConditionSeverityWarning, "")
+		ctx.KubevirtMachine.Status.Ready = false
+		return ctrl.Result{RequeueAfter: time.Minute}, nil
+	}
+
 	if isTerminal {
-		failureErr := capierrors.UpdateMachineError
+		failureErr := externalMachine.FailureReason()
 		ctx.KubevirtMachine.Status.FailureReason = &failureErr
 		ctx.KubevirtMachine.Status.FailureMessage = &terminalReason
 	}
 
+	// Export the disks of a failed VM on request, for offline analysis
+	if res, exporting, err := r.reconcileDiskExport(ctx, infraClusterClient, vmNamespace); err != nil || exporting {
+		return res, err
+	}
+
+	// Deliver the bootstrap data regenerated before the VM booted, e.g. with a new bootstrap token
+	exists := externalMachine.Exists()
+	if !isTerminal && exists && ctx.KubevirtMachine.Spec.ProviderID == nil {
+		redelivered, err := externalMachine.RedeliverBootstrapData(ctx.Context)
+		if err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to redeliver bootstrap data")
+		}
+		if redelivered {
+			ctx.Logger.Info("Bootstrap data changed before the VM booted, restarting it with the new data")
+			if r.Recorder != nil {
+				r.Recorder.Event(ctx.KubevirtMachine, corev1.EventTypeNormal, bootstrapDataRedeliveredReason, "Bootstrap data changed before the VM booted")
+			}
+			conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.WaitingForBootstrapDataReason, clusterv1.ConditionSeverityInfo,
+				"Restarting the VM with the new bootstrap data")
+			return ctrl.Result{RequeueAfter: 20 * time.Second}, nil
+		}
+		// Hold the start of the VM while its join token would expire before it could join
+		if res, held, err := r.reconcileBootstrapTokenHold(ctx, infraClusterClient, vmNamespace); err != nil || held {
+			return res, err
+		}
+	}
+
 	// Provision the underlying VM if not existing
-	if !isTerminal && !externalMachine.Exists() {
+	if !isTerminal && !exists {
+		// The VM of a provisioned machine was deleted out-of-band; the machine is replaced rather than recreating the VM
+		if ctx.KubevirtMachine.Spec.ProviderID != nil && *ctx.KubevirtMachine.Spec.ProviderID != "" {
+			return r.reconcileDeletedVM(ctx, vmNamespace)
+		}
 		ctx.KubevirtMachine.Status.Ready = false
+		// A previous creation failed permanently; the Machine has to be replaced rather than retried
+		if ctx.KubevirtMachine.Status.FailureReason != nil {
+			ctx.Logger.Info("VM creation failed permanently, not retrying", "reason", *ctx.KubevirtMachine.Status.FailureReason)
+			return ctrl.Result{}, nil
+		}
+		// Hold the VMs of the control plane version being upgraded to until the preflight checks of the upgrade pass
+		if message := heldByUpgradePreflight(ctx); message != "" {
+			ctx.Logger.Info("Waiting for the preflight checks of the control plane upgrade to pass", "reason", message)
+			conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.WaitingForUpgradePreflightReason, clusterv1.ConditionSeverityInfo, message)
+			return ctrl.Result{RequeueAfter: time.Minute}, nil
+		}
+		// Hold the VMs that would exceed the quota of the cluster
+		message, err := r.quotaExceeded(ctx)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if message != "" {
+			ctx.Logger.Info("VM would exceed the quota of the cluster", "reason", message)
+			conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.QuotaExceededReason, clusterv1.ConditionSeverityWarning, message)
+			return ctrl.Result{RequeueAfter: time.Minute}, nil
+		}
+		// Report the features of KubeVirt the template depends on, rather than the errors of the infra cluster
+		if message := kubevirt.UnavailableInfraFeatures(ctx.KubevirtCluster.Status.Infra, &ctx.KubevirtMachine.Spec.VirtualMachineTemplate); message != "" {
+			ctx.Logger.Info("VM template uses features the infra cluster does not provide", "reason", message)
+			conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.InfraFeatureUnavailableReason, clusterv1.ConditionSeverityWarning, message)
+			return ctrl.Result{RequeueAfter: time.Minute}, nil
+		}
+		// Clone the disk of the VM from its image, once imported in the infra cluster
+		if imported, err := r.resolveMachineImage(ctx); err != nil || !imported {
+			return ctrl.Result{RequeueAfter: machineImageImportCheckInterval}, err
+		}
+		// Report the architectures the infra nodes do not run, rather than leaving the VM unschedulable
+		if message := kubevirt.UnavailableArchitecture(ctx.KubevirtCluster.Status.Infra, kubevirt.MachineArchitecture(ctx)); message != "" {
+			ctx.Logger.Info("VM architecture is not provided by the infra cluster", "reason", message)
+			conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.InfraFeatureUnavailableReason, clusterv1.ConditionSeverityWarning, message)
+			return ctrl.Result{RequeueAfter: time.Minute}, nil
+		}
+		// Report the machines requiring nested virtualization no infra node provides, rather than leaving the VM unschedulable
+		if message := kubevirt.UnavailableNestedVirtualization(ctx); message != "" {
+			ctx.Logger.Info("VM requires nested virtualization the infra cluster does not provide", "reason", message)
+			conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.InfraFeatureUnavailableReason, clusterv1.ConditionSeverityWarning, message)
+			return ctrl.Result{RequeueAfter: time.Minute}, nil
+		}
+		// Hold the VM while the GPUs it attaches are not permitted or provided by the infra cluster
+		if message := kubevirt.UnavailableGPUs(ctx); message != "" {
+			ctx.Logger.Info("VM attaches GPUs the infra cluster does not provide", "reason", message)
+			conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.InfraFeatureUnavailableReason, clusterv1.ConditionSeverityWarning, message)
+			return ctrl.Result{RequeueAfter: time.Minute}, nil
+		}
+		// Refuse the disks the disk encryption policy of the cluster has no encrypted storage class for
+		if message := kubevirt.UnencryptedDataVolumeTemplates(ctx); message != "" {
+			ctx.Logger.Info("Disks of the VM would not be encrypted", "reason", message)
+			conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.DiskEncryptionNotHonoredReason, clusterv1.ConditionSeverityWarning, message)
+			return ctrl.Result{RequeueAfter: time.Minute}, nil
+		}
+		// Fail fast when the storage classes of the disks lack a capability the VM requires, rather than importing them
+		message, err = kubevirt.UnavailableStorageCapabilities(ctx, infraClusterClient)
+		if err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to probe the storage capabilities of the infra cluster")
+		}
+		if message != "" {
+			ctx.Logger.Info("Storage classes of the VM lack capabilities it requires", "reason", message)
+			conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.StorageCapabilityUnavailableReason, clusterv1.ConditionSeverityWarning, message)
+			return ctrl.Result{RequeueAfter: time.Minute}, nil
+		}
+		// Keep the volumes binding on first consumer in the zone of the VM, rather than letting CDI bind them in any
+		message, err = kubevirt.UnhonoredWaitForFirstConsumer(ctx, infraClusterClient)
+		if err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to probe the volume binding modes of the infra cluster")
+		}
+		if message != "" {
+			ctx.Logger.Info("Volumes of the VM would not be bound in its zone", "reason", message)
+			conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.WaitForFirstConsumerNotHonoredReason, clusterv1.ConditionSeverityWarning, message)
+			return ctrl.Result{RequeueAfter: time.Minute}, nil
+		}
 		if err := externalMachine.Create(ctx.Context); err != nil {
-			conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.VMCreateFailedReason, clusterv1.ConditionSeverityError, fmt.Sprintf("Failed vm creation: %v", err))
+			// Point at the unsupported versions of the infra cluster, the likely cause of the rejected fields
+			if message := kubevirt.UnsupportedInfraVersions(ctx.KubevirtCluster.Status.Infra); message != "" {
+				err = errors.Wrapf(err, "infra cluster not supported (%s)", message)
+			}
+			if failureErr, terminal := kubevirt.CreateFailureReason(err); terminal {
+				failureMessage := fmt.Sprintf("Failed vm creation: %v", err)
+				ctx.KubevirtMachine.Status.FailureReason = &failureErr
+				ctx.KubevirtMachine.Status.FailureMessage = &failureMessage
+				conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.VMCreateFailedReason, clusterv1.ConditionSeverityError, failureMessage)
+				return ctrl.Result{}, nil
+			}
+			conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.VMCreateFailedReason, clusterv1.ConditionSeverityWarning, fmt.Sprintf("Failed vm creation: %v", err))
 			return ctrl.Result{}, errors.Wrap(err, "failed to create VM instance")
 		}
 		ctx.Logger.Info("VM Created, waiting on vm to be provisioned.")
+		// Create the VMs of the pending machines of the same MachineSet along, rather than one per reconcile
+		r.reconcileBatchedVMCreation(ctx, infraClusterClient, vmNamespace, clusterNodeSshKeys)
 		return ctrl.Result{RequeueAfter: 20 * time.Second}, nil
 	}
 
+	// Drain and stop the reclaimed preemptible machines
+	if res, reclaimed, err := r.reconcileReclaim(ctx, infraClusterClient, vmNamespace); err != nil || reclaimed {
+		return res, err
+	}
+
+	// Report the changes made to the VM in the infra cluster, and revert them if the cluster requests it
+	if !isTerminal {
+		if err := r.reconcileVMDrift(ctx, externalMachine, time.Now()); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to reconcile the drift of the VM")
+		}
+	}
+
 	// Checks to see if a VM's active VMI is ready or not
 	if externalMachine.IsReady() {
 		// Mark VMProvisionedCondition to indicate that the VM has successfully started
 		conditions.MarkTrue(ctx.KubevirtMachine, infrav1.VMProvisionedCondition)
 	} else {
 		reason, message := externalMachine.GetVMNotReadyReason()
-		conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, reason, clusterv1.ConditionSeverityInfo, message)
+		// The volumes binding on first consumer wait for the VM to be scheduled, rather than for their import
+		phase := infrav1.VMStartPhase
+		if strings.HasPrefix(reason, "DV") && reason != kubevirt.DVWaitingForFirstConsumerReason {
+			phase = infrav1.DataVolumeImportPhase
+		}
+		if provisioningPhaseExpired(ctx, phase, time.Now()) {
+			return r.reconcileProvisioningTimeout(ctx, externalMachine)
+		}
+		// Fail fast the VMs whose readiness waits on a guest agent their image lacks
+		if guestAgentNotConnected(ctx.KubevirtMachine) {
+			conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.AgentNotConnectedReason, clusterv1.ConditionSeverityError,
+				conditions.GetMessage(ctx.KubevirtMachine, infrav1.GuestAgentConnectedCondition))
+		} else {
+			conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, reason, clusterv1.ConditionSeverityInfo, message)
+		}
 
 		// Waiting for VM to boot
 		ctx.KubevirtMachine.Status.Ready = false
@@ -314,13 +603,25 @@ func (r *KubevirtMachineReconciler) reconcileNormal(ctx *context.MachineContext)
 
 	if externalMachine.SupportsCheckingIsBootstrapped() && !conditions.IsTrue(ctx.KubevirtMachine, infrav1.BootstrapExecSucceededCondition) {
 		if !externalMachine.IsBootstrapped() {
+			if provisioningPhaseExpired(ctx, infrav1.BootstrapPhase, time.Now()) {
+				return r.reconcileProvisioningTimeout(ctx, externalMachine)
+			}
+			if retried, err := r.reconcileBootstrapFailure(ctx, externalMachine, vmNamespace, time.Now()); err != nil || retried {
+				ctx.KubevirtMachine.Status.Ready = false
+				return ctrl.Result{RequeueAfter: 10 * time.Second}, err
+			}
 			ctx.Logger.Info("Waiting for underlying VM to bootstrap...")
-			conditions.MarkFalse(ctx.KubevirtMachine, infrav1.BootstrapExecSucceededCondition, infrav1.BootstrapFailedReason, clusterv1.ConditionSeverityWarning, "VM not bootstrapped yet")
+			reason, message := infrav1.BootstrapFailedReason, "VM not bootstrapped yet"
+			if diagnosis := ctx.KubevirtMachine.Status.BootstrapDiagnosis; diagnosis != nil && diagnosis.Reason != "" {
+				reason, message = diagnosis.Reason, diagnosis.Message
+			}
+			conditions.MarkFalse(ctx.KubevirtMachine, infrav1.BootstrapExecSucceededCondition, reason, clusterv1.ConditionSeverityWarning, "%s", message)
 			ctx.KubevirtMachine.Status.Ready = false
 			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
 		}
 		// Update the condition BootstrapExecSucceededCondition
 		conditions.MarkTrue(ctx.KubevirtMachine, infrav1.BootstrapExecSucceededCondition)
+		ctx.KubevirtMachine.Status.BootstrapDiagnosis = nil
 		ctx.Logger.Info("Underlying VM has boostrapped.")
 	}
 
@@ -335,7 +636,7 @@ func (r *KubevirtMachineReconciler) reconcileNormal(ctx *context.MachineContext)
 		},
 		{
 			Type:    clusterv1.MachineExternalIP,
-			Address: ipAddress,
+			Address: externalMachine.ExternalAddress(),
 		},
 		{
 			Type:    clusterv1.MachineInternalDNS,
@@ -361,6 +662,16 @@ func (r *KubevirtMachineReconciler) reconcileNormal(ctx *context.MachineContext)
 		ctx.KubevirtMachine.Status.Ready = false
 	}
 
+	// Report the GPUs assigned to the VMI
+	if err := r.reconcileGPUStatus(ctx, vmNamespace, time.Now()); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Run the command requested on the machine, if any
+	if err := r.reconcileCommand(ctx, vmNamespace); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to run command")
+	}
+
 	liveMigratable, reason, message, err := externalMachine.IsLiveMigratable()
 	if err != nil {
 		ctx.Logger.Error(err, fmt.Sprintf("failed to get the %s condition of %s machine",
@@ -378,6 +689,97 @@ func (r *KubevirtMachineReconciler) reconcileNormal(ctx *context.MachineContext)
 	return ctrl.Result{}, nil
 }
 
+// reconcileDeletedVM fails a provisioned machine whose VM no longer exists, and deletes its Node from the
+// workload cluster, where it would otherwise stay NotReady until the machine is replaced.
+func (r *KubevirtMachineReconciler) reconcileDeletedVM(ctx *context.MachineContext, vmNamespace string) (ctrl.Result, error) {
+	ctx.KubevirtMachine.Status.Ready = false
+	if ctx.KubevirtMachine.Status.FailureReason == nil {
+		ctx.Logger.Info("VM of the provisioned machine was deleted", "namespace", vmNamespace, "name", ctx.KubevirtMachine.Name)
+		failureErr := capierrors.UpdateMachineError
+		failureMessage := fmt.Sprintf("VM %s/%s was deleted", vmNamespace, ctx.KubevirtMachine.Name)
+		ctx.KubevirtMachine.Status.FailureReason = &failureErr
+		ctx.KubevirtMachine.Status.FailureMessage = &failureMessage
+		conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.VMDeletedReason, clusterv1.ConditionSeverityError, failureMessage)
+	}
+
+	workloadClusterClient, err := r.WorkloadCluster.GenerateWorkloadClusterClient(ctx)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, errors.Wrap(err, "failed to generate workload cluster client")
+	}
+
+	nodeName := ctx.KubevirtMachine.Name
+	if ctx.Machine.Status.NodeRef != nil {
+		nodeName = ctx.Machine.Status.NodeRef.Name
+	}
+	node := &corev1.Node{}
+	if err := workloadClusterClient.Get(ctx, client.ObjectKey{Name: nodeName}, node); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, errors.Wrapf(err, "failed to fetch workload cluster node")
+	}
+
+	// Leave alone a Node registered by another VM since
+	if node.Spec.ProviderID != "" && node.Spec.ProviderID != *ctx.KubevirtMachine.Spec.ProviderID {
+		return ctrl.Result{}, nil
+	}
+
+	ctx.Logger.Info("Deleting the stale workload cluster node", "node", nodeName)
+	if err := workloadClusterClient.Delete(ctx, node); err != nil && !apierrors.IsNotFound(err) {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, errors.Wrapf(err, "failed to delete workload cluster node")
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// reconcileDryRun publishes the infra objects reconcileNormal would create for the machine, without creating them.
+func (r *KubevirtMachineReconciler) reconcileDryRun(ctx *context.MachineContext) (ctrl.Result, error) {
+	ctx.Logger.Info("KubevirtCluster is annotated for dry-run, no infra object will be modified")
+
+	if ctx.Machine.Spec.Bootstrap.DataSecretName == nil {
+		ctx.Logger.Info("Waiting for Machine.Spec.Bootstrap.DataSecretName...")
+		return ctrl.Result{}, nil
+	}
+
+	infraClusterSecretRef := ctx.KubevirtMachine.Spec.InfraClusterSecretRef
+	if infraClusterSecretRef == nil {
+		infraClusterSecretRef = ctx.KubevirtCluster.Spec.InfraClusterSecretRef
+	}
+
+	infraClusterClient, infraClusterNamespace, err := r.InfraCluster.GenerateInfraClusterClient(infraClusterSecretRef, ctx.KubevirtMachine.Namespace, ctx.Context)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, errors.Wrap(err, "failed to generate infra cluster client")
+	}
+	if infraClusterClient == nil {
+		ctx.Logger.Info("Waiting for infra cluster client...")
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+
+	vmNamespace := ctx.KubevirtMachine.Spec.VirtualMachineTemplate.ObjectMeta.Namespace
+	if vmNamespace == "" {
+		vmNamespace = infraClusterNamespace
+	}
+
+	bootstrapDataSecretKey := client.ObjectKey{Namespace: vmNamespace, Name: kubevirt.UserDataSecretName(ctx)}
+	if err := infraClusterClient.Get(ctx, bootstrapDataSecretKey, &corev1.Secret{}); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, errors.Wrap(err, "failed to fetch kubevirt bootstrap secret")
+		}
+		recordPlannedChange(r.Recorder, ctx.KubevirtMachine, "create", "Secret", bootstrapDataSecretKey.Namespace, bootstrapDataSecretKey.Name)
+	}
+
+	externalMachine, err := r.MachineFactory.NewMachine(ctx, infraClusterClient, vmNamespace, nil)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to create helper for managing the externalMachine")
+	}
+	if !externalMachine.Exists() {
+		recordPlannedChange(r.Recorder, ctx.KubevirtMachine, "create", "VirtualMachine", vmNamespace, ctx.KubevirtMachine.Name)
+		conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.DryRunReason, clusterv1.ConditionSeverityInfo, "")
+	}
+
+	return ctrl.Result{}, nil
+}
+
 func machineHasKnownInternalIP(kubevirtMachine *infrav1.KubevirtMachine) bool {
 	for _, addr := range kubevirtMachine.Status.Addresses {
 		if addr.Type == clusterv1.MachineInternalIP && addr.Address != "" {
@@ -387,12 +789,9 @@ func machineHasKnownInternalIP(kubevirtMachine *infrav1.KubevirtMachine) bool {
 	return false
 }
 
+// updateNodeProviderID sets the providerID and the topology labels on the Node of the machine, and mirrors the
+// health of the Node into the KubevirtMachine.
 func (r *KubevirtMachineReconciler) updateNodeProviderID(ctx *context.MachineContext) (ctrl.Result, error) {
-	// If the provider ID is already updated on the Node, return
-	if ctx.KubevirtMachine.Status.NodeUpdated {
-		return ctrl.Result{}, nil
-	}
-
 	workloadClusterClient, err := r.WorkloadCluster.GenerateWorkloadClusterClient(ctx)
 	if err != nil {
 		ctx.Logger.Error(err, "Workload cluster client is not available")
@@ -408,24 +807,47 @@ func (r *KubevirtMachineReconciler) updateNodeProviderID(ctx *context.MachineCon
 	if err := workloadClusterClient.Get(ctx, workloadClusterNodeKey, workloadClusterNode); err != nil {
 		if apierrors.IsNotFound(err) {
 			ctx.Logger.Info(fmt.Sprintf("Waiting for workload cluster node to appear for machine %s/%s...", ctx.KubevirtMachine.Namespace, ctx.KubevirtMachine.Name))
+			conditions.MarkFalse(ctx.KubevirtMachine, infrav1.NodeReadyCondition, infrav1.WaitingForNodeReason, clusterv1.ConditionSeverityInfo, "")
+			ctx.KubevirtMachine.Status.Node = nil
+			if provisioningPhaseExpired(ctx, infrav1.NodeRegistrationPhase, time.Now()) {
+				return r.reconcileProvisioningTimeout(ctx, nil)
+			}
 			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
 		} else {
 			return ctrl.Result{RequeueAfter: 5 * time.Second}, errors.Wrapf(err, "failed to fetch workload cluster node")
 		}
 	}
 
-	if workloadClusterNode.Spec.ProviderID == *ctx.KubevirtMachine.Spec.ProviderID {
+	clearProvisioningPhase(ctx.KubevirtMachine)
+	updateNodeStatus(ctx.KubevirtMachine, workloadClusterNode)
+
+	// If the provider ID is already updated on the Node, return
+	if ctx.KubevirtMachine.Status.NodeUpdated {
+		return ctrl.Result{}, nil
+	}
+
+	topologyLabels := nodeTopologyLabels(ctx.KubevirtMachine)
+	if workloadClusterNode.Spec.ProviderID == *ctx.KubevirtMachine.Spec.ProviderID && hasLabels(workloadClusterNode, topologyLabels) {
 		// Node is already updated, return
 		return ctrl.Result{}, nil
 	}
 
-	// Patch node with provider id.
+	// Patch node with provider id and the topology of its VM.
 	// Usually a cloud provider will do this, but there is no cloud provider for KubeVirt.
 	ctx.Logger.Info("Patching node with provider id...")
 
 	// using workload cluster client, patch cluster node
-	patchStr := fmt.Sprintf(`{"spec": {"providerID": "%s"}}`, *ctx.KubevirtMachine.Spec.ProviderID)
-	mergePatch := client.RawPatch(types.MergePatchType, []byte(patchStr))
+	nodePatch := map[string]interface{}{
+		"spec": map[string]interface{}{"providerID": *ctx.KubevirtMachine.Spec.ProviderID},
+	}
+	if len(topologyLabels) > 0 {
+		nodePatch["metadata"] = map[string]interface{}{"labels": topologyLabels}
+	}
+	patchBytes, err := json.Marshal(nodePatch)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to marshal workload cluster node patch")
+	}
+	mergePatch := client.RawPatch(types.MergePatchType, patchBytes)
 	if err := workloadClusterClient.Patch(ctx, workloadClusterNode, mergePatch); err != nil {
 		return ctrl.Result{RequeueAfter: 5 * time.Second}, errors.Wrapf(err, "failed to patch workload cluster node")
 	}
@@ -434,6 +856,72 @@ func (r *KubevirtMachineReconciler) updateNodeProviderID(ctx *context.MachineCon
 	return ctrl.Result{}, nil
 }
 
+// updateNodeStatus mirrors the readiness and the kubelet version of the Node into the KubevirtMachine.
+func updateNodeStatus(kubevirtMachine *infrav1.KubevirtMachine, node *corev1.Node) {
+	kubevirtMachine.Status.Node = &infrav1.NodeInfo{
+		Ready:          corev1.ConditionUnknown,
+		KubeletVersion: node.Status.NodeInfo.KubeletVersion,
+	}
+
+	var readyCondition *corev1.NodeCondition
+	for i := range node.Status.Conditions {
+		if node.Status.Conditions[i].Type == corev1.NodeReady {
+			readyCondition = &node.Status.Conditions[i]
+		}
+	}
+	if readyCondition == nil {
+		conditions.MarkFalse(kubevirtMachine, infrav1.NodeReadyCondition, infrav1.NodeNotReadyReason, clusterv1.ConditionSeverityWarning, "Node has not reported its readiness yet")
+		return
+	}
+
+	kubevirtMachine.Status.Node.Ready = readyCondition.Status
+	if readyCondition.Status == corev1.ConditionTrue {
+		conditions.MarkTrue(kubevirtMachine, infrav1.NodeReadyCondition)
+		return
+	}
+	message := readyCondition.Message
+	if readyCondition.Status == corev1.ConditionUnknown {
+		message = fmt.Sprintf("Node status is unknown: %s", readyCondition.Message)
+	}
+	conditions.MarkFalse(kubevirtMachine, infrav1.NodeReadyCondition, infrav1.NodeNotReadyReason, clusterv1.ConditionSeverityWarning, message)
+}
+
+// infraNodeZone returns the zone of an infra cluster node, or an empty string if it cannot be read, e.g. because the
+// infra cluster credentials do not allow reading nodes.
+func infraNodeZone(ctx *context.MachineContext, infraClusterClient client.Client, nodeName string) string {
+	node := &corev1.Node{}
+	if err := infraClusterClient.Get(ctx, client.ObjectKey{Name: nodeName}, node); err != nil {
+		ctx.Logger.V(4).Info("Failed to read the zone of the infra node", "node", nodeName, "error", err.Error())
+		return ""
+	}
+	return node.Labels[corev1.LabelTopologyZone]
+}
+
+// nodeTopologyLabels returns the labels describing where the VM of the machine runs, to be set on its Node.
+func nodeTopologyLabels(kubevirtMachine *infrav1.KubevirtMachine) map[string]string {
+	labels := map[string]string{}
+	vmi := kubevirtMachine.Status.VirtualMachineInstance
+	if vmi == nil {
+		return labels
+	}
+	if vmi.NodeName != "" && len(validation.IsValidLabelValue(vmi.NodeName)) == 0 {
+		labels[infrav1.HypervisorLabel] = vmi.NodeName
+	}
+	if vmi.Zone != "" {
+		labels[corev1.LabelTopologyZone] = vmi.Zone
+	}
+	return labels
+}
+
+func hasLabels(obj metav1.Object, labels map[string]string) bool {
+	for key, value := range labels {
+		if obj.GetLabels()[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
 func (r *KubevirtMachineReconciler) reconcileDelete(ctx *context.MachineContext) (ctrl.Result, error) {
 
 	patchHelper, err := patch.NewHelper(ctx.KubevirtMachine, r.Client)
@@ -471,14 +959,35 @@ func (r *KubevirtMachineReconciler) reconcileDelete(ctx *context.MachineContext)
 		return ctrl.Result{RequeueAfter: 10 * time.Second}, errors.Wrap(err, "failed to create helper for externalMachine access")
 	}
 
+	if err := deleteDiskExport(ctx, infraClusterClient, vmNamespace); err != nil {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, err
+	}
+
 	if externalMachine.Exists() {
-		if err := externalMachine.Delete(); err != nil {
+		deletable, err := r.applyDiskRetentionPolicy(ctx, infraClusterClient, vmNamespace)
+		if err != nil {
+			return ctrl.Result{RequeueAfter: 10 * time.Second}, errors.Wrap(err, "failed to apply the disk retention policy")
+		}
+		if !deletable {
+			ctx.Logger.Info("Waiting for the snapshot of the VM disks...")
+			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		}
+
+		if err := externalMachine.Delete(); ownership.IsNotOwned(err) {
+			// Never delete a VM of the user merely named like the machine
+			ctx.Logger.Info("Leaving the VM in place", "reason", err.Error())
+			r.recordNotOwned(ctx, err)
+		} else if err != nil {
 			return ctrl.Result{RequeueAfter: 10 * time.Second}, errors.Wrap(err, "failed to delete VM")
 		}
 	}
 
+	if err := r.deleteMachineMigrationPolicy(ctx, infraClusterClient); err != nil {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, errors.Wrap(err, "failed to delete the migration policy")
+	}
+
 	// Machine is deleted so remove the finalizer.
-	controllerutil.RemoveFinalizer(ctx.KubevirtMachine, infrav1.MachineFinalizer)
+	r.removeFinalizer(ctx)
 
 	// Set the VMProvisionedCondition reporting delete is started, and attempt to issue a patch in
 	// order to make this visible to the users.
@@ -492,6 +1001,16 @@ func (r *KubevirtMachineReconciler) reconcileDelete(ctx *context.MachineContext)
 	return ctrl.Result{}, nil
 }
 
+// removeFinalizer removes the finalizer of a deleted machine, unless read-only: the deletion of the VM was only a
+// dry-run then, and the machine is left to the controller managing it.
+func (r *KubevirtMachineReconciler) removeFinalizer(ctx *context.MachineContext) {
+	if r.ReadOnly {
+		ctx.Logger.Info("Read-only, keeping the finalizer of the deleted machine")
+		return
+	}
+	controllerutil.RemoveFinalizer(ctx.KubevirtMachine, infrav1.MachineFinalizer)
+}
+
 // SetupWithManager will add watches for this controller.
 func (r *KubevirtMachineReconciler) SetupWithManager(goctx gocontext.Context, mgr ctrl.Manager, options controller.Options) error {
 	clusterToKubevirtMachines, err := util.ClusterToTypedObjectsMapper(mgr.GetClient(), &infrav1.KubevirtMachineList{}, mgr.GetScheme())
@@ -499,7 +1018,7 @@ func (r *KubevirtMachineReconciler) SetupWithManager(goctx gocontext.Context, mg
 		return err
 	}
 
-	return ctrl.NewControllerManagedBy(mgr).
+	controllerBuilder := ctrl.NewControllerManagedBy(mgr).
 		For(&infrav1.KubevirtMachine{}).
 		WithOptions(options).
 		WithEventFilter(predicates.ResourceNotPaused(ctrl.LoggerFrom(goctx))).
@@ -516,7 +1035,36 @@ func (r *KubevirtMachineReconciler) SetupWithManager(goctx gocontext.Context, mg
 			handler.EnqueueRequestsFromMapFunc(clusterToKubevirtMachines),
 			builder.WithPredicates(predicates.ClusterUnpausedAndInfrastructureReady(ctrl.LoggerFrom(goctx))),
 		).
-		Complete(r)
+		Watches(
+			&kubevirtv1.VirtualMachineInstance{},
+			handler.EnqueueRequestsFromMapFunc(VirtualMachineInstanceToKubevirtMachine),
+		)
+	if r.Operations != nil {
+		if err := mgr.Add(r.Operations); err != nil {
+			return err
+		}
+		controllerBuilder = controllerBuilder.WatchesRawSource(r.Operations.Source())
+	}
+
+	c, err := controllerBuilder.Build(r)
+	if err != nil {
+		return err
+	}
+
+	r.controller = c
+	return nil
+}
+
+// VirtualMachineInstanceToKubevirtMachine is a handler.ToRequestsFunc to be used to enqueue the KubevirtMachine
+// of a VMI, when the VMI runs in the management cluster.
+func VirtualMachineInstanceToKubevirtMachine(_ gocontext.Context, o client.Object) []ctrl.Request {
+	name, hasName := o.GetLabels()[infrav1.KubevirtMachineNameLabel]
+	namespace, hasNamespace := o.GetLabels()[infrav1.KubevirtMachineNamespaceLabel]
+	if !hasName || !hasNamespace {
+		return nil
+	}
+
+	return []ctrl.Request{{NamespacedName: client.ObjectKey{Namespace: namespace, Name: name}}}
 }
 
 // KubevirtClusterToKubevirtMachines is a handler.ToRequestsFunc to be used to enqueue
@@ -579,16 +1127,89 @@ func (r *KubevirtMachineReconciler) reconcileKubevirtBootstrapSecret(ctx *contex
 		}
 	}
 
+	if env := proxy.Environment(ctx.Cluster, ctx.KubevirtCluster); env != nil {
+		var err error
+		var modified bool
+		if value, modified, err = addProxyToCloudInitConfig(value, env); err != nil {
+			return errors.Wrapf(err, "failed to add proxy to KubevirtMachine %s/%s userdata", ctx.Machine.GetNamespace(), ctx.Machine.GetName())
+		} else if modified {
+			ctx.Logger.Info("Add proxy environment to bootstrap userdata")
+		}
+	}
+
+	if mirrors := ctx.KubevirtCluster.Spec.RegistryMirrors; len(mirrors) > 0 {
+		var err error
+		var modified bool
+		if value, modified, err = addRegistryMirrorsToCloudInitConfig(value, mirrors); err != nil {
+			return errors.Wrapf(err, "failed to add registry mirrors to KubevirtMachine %s/%s userdata", ctx.Machine.GetNamespace(), ctx.Machine.GetName())
+		} else if modified {
+			ctx.Logger.Info("Add registry mirrors to bootstrap userdata")
+		}
+	}
+
+	if disks := kubevirt.GuestEncryptedDisks(ctx); len(disks) > 0 {
+		key, err := r.reconcileDiskEncryptionKey(ctx)
+		if err != nil {
+			return err
+		}
+		var modified bool
+		if value, modified, err = addDiskEncryptionToCloudInitConfig(value, key, disks); err != nil {
+			return errors.Wrapf(err, "failed to add disk encryption to KubevirtMachine %s/%s userdata", ctx.Machine.GetNamespace(), ctx.Machine.GetName())
+		} else if modified {
+			ctx.Logger.Info("Add disk encryption commands to bootstrap userdata")
+		}
+	}
+
+	if util.IsControlPlaneMachine(ctx.Machine) {
+		sans, err := externalCertSANs(ctx.KubevirtCluster)
+		if err != nil {
+			return err
+		}
+		var modified bool
+		if value, modified, err = addCertSANsToCloudInitConfig(value, sans); err != nil {
+			return errors.Wrapf(err, "failed to add external endpoint certSANs to KubevirtMachine %s/%s userdata", ctx.Machine.GetNamespace(), ctx.Machine.GetName())
+		} else if modified {
+			ctx.Logger.Info("Add external endpoint certSANs to bootstrap userdata")
+		}
+
+		if loadbalancer.PublisherType(ctx.KubevirtCluster) == infrav1.KubeVIPEndpointPublisher {
+			manifest, err := loadbalancer.KubeVIPManifest(ctx.KubevirtCluster)
+			if err != nil {
+				return err
+			}
+			if value, modified, err = addKubeVIPToCloudInitConfig(value, manifest); err != nil {
+				return errors.Wrapf(err, "failed to add kube-vip to KubevirtMachine %s/%s userdata", ctx.Machine.GetNamespace(), ctx.Machine.GetName())
+			} else if modified {
+				ctx.Logger.Info("Add kube-vip static pod to bootstrap userdata")
+			}
+		}
+	}
+
+	identity, err := r.reconcileMachineIdentity(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to issue the certificate of KubevirtMachine %s/%s", ctx.KubevirtMachine.Namespace, ctx.KubevirtMachine.Name)
+	}
+	if identity != nil {
+		var modified bool
+		if value, modified, err = addMachineIdentityToCloudInitConfig(value, identity.Data); err != nil {
+			return errors.Wrapf(err, "failed to add machine identity to KubevirtMachine %s/%s userdata", ctx.Machine.GetNamespace(), ctx.Machine.GetName())
+		} else if modified {
+			ctx.Logger.Info("Add machine identity certificate to bootstrap userdata")
+		}
+	}
+
 	newBootstrapDataSecret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      s.Name + "-userdata",
+			Name:      kubevirt.UserDataSecretName(ctx),
 			Namespace: vmNamespace,
 			Labels:    s.Labels,
 		},
 	}
 	ctx.BootstrapDataSecret = newBootstrapDataSecret
 
-	_, err := controllerutil.CreateOrUpdate(ctx, infraClusterClient, newBootstrapDataSecret, func() error {
+	_, err = controllerutil.CreateOrUpdate(ctx, infraClusterClient, newBootstrapDataSecret, func() error {
+		resources.PropagateMetadata(&newBootstrapDataSecret.ObjectMeta, ctx.Cluster, ctx.KubevirtCluster)
+		ownership.SetOwner(newBootstrapDataSecret, ownership.MachineOwner(ctx.KubevirtMachine))
 		newBootstrapDataSecret.Type = clusterv1.ClusterSecretType
 		newBootstrapDataSecret.Data = map[string][]byte{
 			"userdata": value,
@@ -613,19 +1234,51 @@ func (r *KubevirtMachineReconciler) deleteKubevirtBootstrapSecret(ctx *context.M
 	}
 
 	bootstrapDataSecret := &corev1.Secret{}
-	bootstrapDataSecretKey := client.ObjectKey{Namespace: vmNamespace, Name: *ctx.Machine.Spec.Bootstrap.DataSecretName + "-userdata"}
+	bootstrapDataSecretKey := client.ObjectKey{Namespace: vmNamespace, Name: kubevirt.UserDataSecretName(ctx)}
 	if err := infraClusterClient.Get(ctx, bootstrapDataSecretKey, bootstrapDataSecret); err != nil {
 		// the secret does not exist, exit without error
 		return nil
 	}
+	// The secrets of the machines are annotated with their owner whenever they are reconciled. The secrets created
+	// before, which hold a join token too, are recognized by their name, derived from the bootstrap data secret of the
+	// machine, and by the cluster name label copied from it.
+	var legacyLabels map[string]string
+	if _, annotated := bootstrapDataSecret.Annotations[infrav1.InfraOwnerAnnotation]; !annotated {
+		legacyLabels = bootstrapSecretLabels(ctx)
+	}
+	if err := ownership.VerifyOwner(bootstrapDataSecret, ownership.MachineOwner(ctx.KubevirtMachine), legacyLabels); err != nil {
+		ctx.Logger.Info("Leaving the bootstrap secret in place", "reason", err.Error())
+		r.recordNotOwned(ctx, err)
+		return nil
+	}
 
-	if err := infraClusterClient.Delete(ctx, bootstrapDataSecret); err != nil {
+	if err := infraClusterClient.Delete(ctx, bootstrapDataSecret, client.Preconditions{UID: &bootstrapDataSecret.UID}); err != nil {
 		return errors.Wrapf(err, "failed to delete kubevirt bootstrap secret for cluster")
 	}
 
 	return nil
 }
 
+// bootstrapSecretLabels returns the labels the bootstrap secrets of the machines created before the owner annotation
+// have, copied from the bootstrap data secret of the machine, or nil when the cluster of the machine is unknown.
+func bootstrapSecretLabels(ctx *context.MachineContext) map[string]string {
+	clusterName := ctx.Machine.Spec.ClusterName
+	if clusterName == "" {
+		clusterName = ctx.Machine.Labels[clusterv1.ClusterNameLabel]
+	}
+	if clusterName == "" {
+		return nil
+	}
+	return map[string]string{clusterv1.ClusterNameLabel: clusterName}
+}
+
+// recordNotOwned reports an infra object left in place on deletion.
+func (r *KubevirtMachineReconciler) recordNotOwned(ctx *context.MachineContext, err error) {
+	if r.Recorder != nil {
+		r.Recorder.Event(ctx.KubevirtMachine, corev1.EventTypeWarning, infraObjectNotOwnedReason, err.Error())
+	}
+}
+
 // addCapkUserToCloudInitConfig adds the 'capk' user with the provided ssh authorized key to the
 // machine cloud-init bootstrap user-data.
 // If the user-data is not the expected cloud-init config, then returns the latter content as-is.
@@ -633,46 +1286,17 @@ func (r *KubevirtMachineReconciler) deleteKubevirtBootstrapSecret(ctx *context.M
 // The returned boolean indicates whether the userdata was modified or not.
 func addCapkUserToCloudInitConfig(userdata, sshAuthorizedKey []byte) ([]byte, bool, error) {
 
-	// This uses yaml.Node and not an interface{} to preserve the comments, ordering, etc. of the
-	// cloud-init user-data (the indentation might be modified and aligned).
+	root, data, err := parseCloudConfig(userdata)
+	if err != nil || data == nil {
+		return userdata, false, err
+	}
+
+	users := cloudConfigSequence(data, "users")
+
 	// Note that go yaml nodes are not a direct representation of the logic structure of the content;
 	// e.g.
 	//  - the 'users' key and the list (aka sequence) of actual users are sibling nodes
 	//  - the 'name' key and the name value (like 'capk') are sibling nodes
-
-	root := &yaml.Node{}
-	if err := yaml.Unmarshal(userdata, root); err != nil {
-		return nil, false, fmt.Errorf("failed to parse userdata yaml: %w", err)
-	}
-
-	if root.Kind != yaml.DocumentNode || len(root.Content) != 1 {
-		return userdata, false, nil
-	}
-	data := root.Content[0]
-	if data.Kind != yaml.MappingNode || len(data.Content) == 0 {
-		return userdata, false, nil
-	}
-
-	// This resolves the first comment in the document; which can be associated with different nodes
-	// based on how it is written.
-	var headerComment string
-	for _, headerComment = range []string{root.HeadComment, data.HeadComment, data.Content[0].HeadComment} {
-		if headerComment != "" {
-			break
-		}
-	}
-	if !regexp.MustCompile(`(?m)^#cloud-config`).MatchString(headerComment) {
-		return userdata, false, nil
-	}
-
-	var users *yaml.Node
-	for i, section := range data.Content {
-		if i%2 == 1 && section.Kind == yaml.SequenceNode && data.Content[i-1].Value == "users" {
-			users = section
-			break
-		}
-	}
-
 	usersKey, usersWithCapk, err := usersYamlNodes(sshAuthorizedKey)
 	if err != nil {
 		return nil, false, err
@@ -705,6 +1329,162 @@ func addCapkUserToCloudInitConfig(userdata, sshAuthorizedKey []byte) ([]byte, bo
 	return ud, true, err
 }
 
+// proxyDropInPaths are the systemd drop-ins setting the proxy environment of the services of the nodes pulling
+// images or reaching the workload cluster.
+var proxyDropInPaths = []string{
+	"/etc/systemd/system/containerd.service.d/http-proxy.conf",
+	"/etc/systemd/system/kubelet.service.d/http-proxy.conf",
+}
+
+// proxyReloadCommand loads the proxy drop-ins, before kubeadm starts kubelet. containerd is already running.
+const proxyReloadCommand = "systemctl daemon-reload && systemctl try-restart containerd"
+
+// addProxyToCloudInitConfig adds the systemd drop-ins setting the given proxy environment of kubelet and containerd
+// to the machine cloud-init bootstrap user-data, and reloads them before the bootstrap commands.
+// If the user-data is not the expected cloud-init config, then returns the latter content as-is.
+// The returned boolean indicates whether the userdata was modified or not.
+func addProxyToCloudInitConfig(userdata []byte, env []string) ([]byte, bool, error) {
+	root, data, err := parseCloudConfig(userdata)
+	if err != nil || data == nil || len(env) == 0 {
+		return userdata, false, err
+	}
+
+	dropIn := "[Service]\n"
+	for _, variable := range env {
+		dropIn += fmt.Sprintf("Environment=%q\n", variable)
+	}
+
+	var files []cloudInitFile
+	for _, path := range proxyDropInPaths {
+		files = append(files, cloudInitFile{Path: path, Owner: "root:root", Permissions: "0644", Content: dropIn})
+	}
+	if err := appendCloudInitFiles(data, files); err != nil {
+		return nil, false, fmt.Errorf("failed to render proxy drop-ins as valid yaml: %w", err)
+	}
+	commandsNode, err := yamlNode([]string{proxyReloadCommand})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to render proxy reload command as valid yaml: %w", err)
+	}
+
+	if runCmd := cloudConfigSequence(data, "runcmd"); runCmd != nil {
+		runCmd.Content = append(commandsNode.Content, runCmd.Content...)
+	} else {
+		data.Content = append(data.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "runcmd"}, commandsNode)
+	}
+
+	ud, err := yaml.Marshal(root)
+	return ud, true, err
+}
+
+// registryHostsDir is the directory of the hosts files of the registries, read by containerd on each pull.
+const registryHostsDir = "/etc/containerd/certs.d"
+
+// addRegistryMirrorsToCloudInitConfig adds the containerd hosts files of the given registry mirrors to the machine
+// cloud-init bootstrap user-data.
+// If the user-data is not the expected cloud-init config, then returns the latter content as-is.
+// The returned boolean indicates whether the userdata was modified or not.
+func addRegistryMirrorsToCloudInitConfig(userdata []byte, mirrors []infrav1.RegistryMirror) ([]byte, bool, error) {
+	root, data, err := parseCloudConfig(userdata)
+	if err != nil || data == nil || len(mirrors) == 0 {
+		return userdata, false, err
+	}
+
+	var files []cloudInitFile
+	for _, mirror := range mirrors {
+		hosts := ""
+		for _, endpoint := range mirror.Endpoints {
+			hosts += fmt.Sprintf("[host.%q]\n  capabilities = [\"pull\", \"resolve\"]\n", endpoint)
+		}
+		files = append(files, cloudInitFile{
+			Path:        path.Join(registryHostsDir, mirror.Registry, "hosts.toml"),
+			Owner:       "root:root",
+			Permissions: "0644",
+			Content:     hosts,
+		})
+	}
+	if err := appendCloudInitFiles(data, files); err != nil {
+		return nil, false, fmt.Errorf("failed to render registry hosts files as valid yaml: %w", err)
+	}
+
+	ud, err := yaml.Marshal(root)
+	return ud, true, err
+}
+
+// cloudInitFile is an entry of the write_files module of cloud-init.
+type cloudInitFile struct {
+	Path        string `yaml:"path"`
+	Owner       string `yaml:"owner"`
+	Permissions string `yaml:"permissions"`
+	Content     string `yaml:"content"`
+}
+
+// appendCloudInitFiles adds the files to the write_files module of the top-level mapping of a cloud-init config.
+func appendCloudInitFiles(data *yaml.Node, files []cloudInitFile) error {
+	filesNode, err := yamlNode(files)
+	if err != nil {
+		return err
+	}
+	if writeFiles := cloudConfigSequence(data, "write_files"); writeFiles != nil {
+		writeFiles.Content = append(writeFiles.Content, filesNode.Content...)
+	} else {
+		data.Content = append(data.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "write_files"}, filesNode)
+	}
+	return nil
+}
+
+// yamlNode returns the yaml.Node representing the given value.
+func yamlNode(value interface{}) (*yaml.Node, error) {
+	node := &yaml.Node{}
+	if err := node.Encode(value); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// parseCloudConfig parses the cloud-init user-data, and returns its document node and its top-level mapping, or nil
+// nodes if the user-data is not a cloud-init config.
+func parseCloudConfig(userdata []byte) (*yaml.Node, *yaml.Node, error) {
+	// This uses yaml.Node and not an interface{} to preserve the comments, ordering, etc. of the
+	// cloud-init user-data (the indentation might be modified and aligned).
+	root := &yaml.Node{}
+	if err := yaml.Unmarshal(userdata, root); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse userdata yaml: %w", err)
+	}
+
+	if root.Kind != yaml.DocumentNode || len(root.Content) != 1 {
+		return nil, nil, nil
+	}
+	data := root.Content[0]
+	if data.Kind != yaml.MappingNode || len(data.Content) == 0 {
+		return nil, nil, nil
+	}
+
+	// This resolves the first comment in the document; which can be associated with different nodes
+	// based on how it is written.
+	var headerComment string
+	for _, headerComment = range []string{root.HeadComment, data.HeadComment, data.Content[0].HeadComment} {
+		if headerComment != "" {
+			break
+		}
+	}
+	if !regexp.MustCompile(`(?m)^#cloud-config`).MatchString(headerComment) {
+		return nil, nil, nil
+	}
+
+	return root, data, nil
+}
+
+// cloudConfigSequence returns the sequence of the given top-level key of a cloud-init config, or nil if the key
+// is not defined.
+func cloudConfigSequence(data *yaml.Node, key string) *yaml.Node {
+	for i, section := range data.Content {
+		if i%2 == 1 && section.Kind == yaml.SequenceNode && data.Content[i-1].Value == key {
+			return section
+		}
+	}
+	return nil
+}
+
 // usersYamlNodes generates the yaml.Nodes representing the 'users' key and the sequence of users
 // with the capk user and the specified ssh authorized key.
 func usersYamlNodes(sshAuthorizedKey []byte) (*yaml.Node, *yaml.Node, error) {
@@ -18,6 +18,11 @@ package controllers
 
 import (
 	gocontext "context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"time"
 
@@ -28,25 +33,41 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/utils/ptr"
 	kubevirtv1 "kubevirt.io/api/core/v1"
+	exportv1 "kubevirt.io/api/export/v1alpha1"
+	snapshotv1 "kubevirt.io/api/snapshot/v1alpha1"
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
 
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 
 	machinemocks "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt/mock"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/guestagent"
+	guestagentmock "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/guestagent/mock"
 	infraclustermock "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/infracluster/mock"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/operations"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/ownership"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
 	workloadclustermock "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/workloadcluster/mock"
 )
@@ -141,8 +162,274 @@ var _ = Describe("KubevirtClusterToKubevirtMachines", func() {
 	})
 })
 
+var _ = Describe("VirtualMachineInstanceToKubevirtMachine", func() {
+	It("should generate a request for the KubevirtMachine of the VMI", func() {
+		vmi := testing.NewVirtualMachineInstance(testing.NewKubevirtMachine("test-kubevirt-machine", "test-machine"))
+		vmi.Labels = map[string]string{
+			infrav1.KubevirtMachineNameLabel:      "test-kubevirt-machine",
+			infrav1.KubevirtMachineNamespaceLabel: "test-namespace",
+		}
+
+		out := VirtualMachineInstanceToKubevirtMachine(gocontext.Background(), vmi)
+		Expect(out).To(ConsistOf(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "test-namespace", Name: "test-kubevirt-machine"}}))
+	})
+
+	It("should ignore the VMIs not created for a KubevirtMachine", func() {
+		vmi := &kubevirtv1.VirtualMachineInstance{ObjectMeta: metav1.ObjectMeta{Name: "test-vmi", Namespace: "test-namespace"}}
+		Expect(VirtualMachineInstanceToKubevirtMachine(gocontext.Background(), vmi)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Workload cluster Node watch", func() {
+	newNode := func(ready corev1.ConditionStatus, labels map[string]string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-kubevirt-machine", Labels: labels},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+			},
+		}
+	}
+
+	It("should generate a request for the KubevirtMachine of the Node", func() {
+		out := NodeToKubevirtMachine("test-namespace")(gocontext.Background(), newNode(corev1.ConditionTrue, nil))
+		Expect(out).To(ConsistOf(ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "test-namespace", Name: "test-kubevirt-machine"}}))
+	})
+
+	DescribeTable("should only pass the Node updates changing readiness or labels",
+		func(newNodeObj *corev1.Node, expected bool) {
+			oldNode := newNode(corev1.ConditionTrue, map[string]string{"role": "worker"})
+			Expect(nodeReadinessChanged().Update(event.UpdateEvent{ObjectOld: oldNode, ObjectNew: newNodeObj})).To(Equal(expected))
+		},
+		Entry("unchanged", newNode(corev1.ConditionTrue, map[string]string{"role": "worker"}), false),
+		Entry("not ready", newNode(corev1.ConditionFalse, map[string]string{"role": "worker"}), true),
+		Entry("label changed", newNode(corev1.ConditionTrue, map[string]string{"role": "infra"}), true),
+		Entry("label added", newNode(corev1.ConditionTrue, map[string]string{"role": "worker", "zone": "a"}), true),
+	)
+})
+
 var _ = Describe("utility functions", func() {
 
+	DescribeTable("proxy",
+		func(userData []byte, expectedOrNil []byte) {
+			env := []string{"HTTP_PROXY=http://proxy.example.com:3128", "NO_PROXY=localhost,.svc"}
+			actual, modified, err := addProxyToCloudInitConfig(userData, env)
+			Expect(err).ShouldNot(HaveOccurred())
+			if expectedOrNil == nil {
+				Expect(modified).To(BeFalse())
+				Expect(string(actual)).To(Equal(string(userData)))
+			} else {
+				Expect(modified).To(BeTrue())
+				Expect(string(actual)).To(Equal(string(expectedOrNil)))
+			}
+		},
+		Entry(
+			"should be added to cloud-init config",
+			[]byte(`## template: jinja
+#cloud-config
+
+write_files:
+-   path: /etc/kubernetes/pki/ca.crt
+    owner: root:root
+    permissions: '0640'
+runcmd:
+  - 'kubeadm init --config /run/kubeadm/kubeadm.yaml  && echo success > /run/cluster-api/bootstrap-success.complete'
+`),
+			[]byte(`## template: jinja
+#cloud-config
+
+write_files:
+    - path: /etc/kubernetes/pki/ca.crt
+      owner: root:root
+      permissions: '0640'
+    - path: /etc/systemd/system/containerd.service.d/http-proxy.conf
+      owner: root:root
+      permissions: "0644"
+      content: |
+        [Service]
+        Environment="HTTP_PROXY=http://proxy.example.com:3128"
+        Environment="NO_PROXY=localhost,.svc"
+    - path: /etc/systemd/system/kubelet.service.d/http-proxy.conf
+      owner: root:root
+      permissions: "0644"
+      content: |
+        [Service]
+        Environment="HTTP_PROXY=http://proxy.example.com:3128"
+        Environment="NO_PROXY=localhost,.svc"
+runcmd:
+    - systemctl daemon-reload && systemctl try-restart containerd
+    - 'kubeadm init --config /run/kubeadm/kubeadm.yaml  && echo success > /run/cluster-api/bootstrap-success.complete'
+`),
+		),
+		Entry(
+			"should add the sections missing from cloud-init config",
+			[]byte(`#cloud-config
+hostname: test
+`),
+			[]byte(`#cloud-config
+hostname: test
+write_files:
+    - path: /etc/systemd/system/containerd.service.d/http-proxy.conf
+      owner: root:root
+      permissions: "0644"
+      content: |
+        [Service]
+        Environment="HTTP_PROXY=http://proxy.example.com:3128"
+        Environment="NO_PROXY=localhost,.svc"
+    - path: /etc/systemd/system/kubelet.service.d/http-proxy.conf
+      owner: root:root
+      permissions: "0644"
+      content: |
+        [Service]
+        Environment="HTTP_PROXY=http://proxy.example.com:3128"
+        Environment="NO_PROXY=localhost,.svc"
+runcmd:
+    - systemctl daemon-reload && systemctl try-restart containerd
+`),
+		),
+		Entry(
+			"should not be added to ignition config",
+			[]byte(`{"ignition":{"version":"3.3.0"}}`),
+			nil,
+		),
+	)
+
+	DescribeTable("registry mirrors",
+		func(userData []byte, expectedOrNil []byte) {
+			mirrors := []infrav1.RegistryMirror{
+				{Registry: "docker.io", Endpoints: []string{"https://mirror.example.com", "https://mirror2.example.com"}},
+				{Registry: "registry.example.com:5000", Endpoints: []string{"https://mirror.example.com"}},
+			}
+			actual, modified, err := addRegistryMirrorsToCloudInitConfig(userData, mirrors)
+			Expect(err).ShouldNot(HaveOccurred())
+			if expectedOrNil == nil {
+				Expect(modified).To(BeFalse())
+				Expect(string(actual)).To(Equal(string(userData)))
+			} else {
+				Expect(modified).To(BeTrue())
+				Expect(string(actual)).To(Equal(string(expectedOrNil)))
+			}
+		},
+		Entry(
+			"should be added to cloud-init config",
+			[]byte(`#cloud-config
+write_files:
+-   path: /etc/kubernetes/pki/ca.crt
+    owner: root:root
+    permissions: '0640'
+`),
+			[]byte(`#cloud-config
+write_files:
+    - path: /etc/kubernetes/pki/ca.crt
+      owner: root:root
+      permissions: '0640'
+    - path: /etc/containerd/certs.d/docker.io/hosts.toml
+      owner: root:root
+      permissions: "0644"
+      content: |
+        [host."https://mirror.example.com"]
+          capabilities = ["pull", "resolve"]
+        [host."https://mirror2.example.com"]
+          capabilities = ["pull", "resolve"]
+    - path: /etc/containerd/certs.d/registry.example.com:5000/hosts.toml
+      owner: root:root
+      permissions: "0644"
+      content: |
+        [host."https://mirror.example.com"]
+          capabilities = ["pull", "resolve"]
+`),
+		),
+		Entry(
+			"should not be added to ignition config",
+			[]byte(`{"ignition":{"version":"3.3.0"}}`),
+			nil,
+		),
+	)
+
+	DescribeTable("external endpoint certSANs",
+		func(userData []byte, expectedOrNil []byte) {
+			actual, modified, err := addCertSANsToCloudInitConfig(userData, []string{"203.0.113.10", "api.example.com"})
+			Expect(err).ShouldNot(HaveOccurred())
+			if expectedOrNil == nil {
+				Expect(modified).To(BeFalse())
+				Expect(string(actual)).To(Equal(string(userData)))
+			} else {
+				Expect(modified).To(BeTrue())
+				Expect(string(actual)).To(Equal(string(expectedOrNil)))
+			}
+		},
+		Entry(
+			"should be added to the kubeadm ClusterConfiguration",
+			[]byte(`#cloud-config
+write_files:
+-   path: /run/kubeadm/kubeadm.yaml
+    owner: root:root
+    permissions: '0640'
+    content: |
+      ---
+      apiServer:
+        certSANs:
+        - api.example.com
+      apiVersion: kubeadm.k8s.io/v1beta3
+      kind: ClusterConfiguration
+      ---
+      apiVersion: kubeadm.k8s.io/v1beta3
+      kind: InitConfiguration
+`),
+			[]byte(`#cloud-config
+write_files:
+    - path: /run/kubeadm/kubeadm.yaml
+      owner: root:root
+      permissions: '0640'
+      content: |
+        apiServer:
+          certSANs:
+            - api.example.com
+            - 203.0.113.10
+        apiVersion: kubeadm.k8s.io/v1beta3
+        kind: ClusterConfiguration
+        ---
+        apiVersion: kubeadm.k8s.io/v1beta3
+        kind: InitConfiguration
+`),
+		),
+		Entry(
+			"should not be added to cloud-init config without kubeadm configuration",
+			[]byte(`#cloud-config
+hostname: test
+`),
+			nil,
+		),
+		Entry(
+			"should not be added to ignition config",
+			[]byte(`{"ignition":{"version":"3.3.0"}}`),
+			nil,
+		),
+	)
+
+	It("should add the kube-vip static pod to cloud-init config", func() {
+		userData := []byte(`#cloud-config
+hostname: test
+`)
+		actual, modified, err := addKubeVIPToCloudInitConfig(userData, []byte("kind: Pod\n"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(modified).To(BeTrue())
+		Expect(string(actual)).To(Equal(`#cloud-config
+hostname: test
+write_files:
+    - path: /etc/kubernetes/manifests/kube-vip.yaml
+      owner: root:root
+      permissions: "0644"
+      content: |
+        kind: Pod
+`))
+
+		ignition := []byte(`{"ignition":{"version":"3.3.0"}}`)
+		actual, modified, err = addKubeVIPToCloudInitConfig(ignition, []byte("kind: Pod\n"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(modified).To(BeFalse())
+		Expect(actual).To(Equal(ignition))
+	})
+
 	DescribeTable("capk user",
 		func(userData []byte, sshAuthorizedKey string, expectedOrNil []byte) {
 			actual, modified, err := addCapkUserToCloudInitConfig(userData, []byte(sshAuthorizedKey))
@@ -304,7 +591,8 @@ var _ = Describe("reconcile a kubevirt machine", func() {
 				APIVersion: "kubevirt.io",
 			},
 			ObjectMeta: metav1.ObjectMeta{
-				Name: kubevirtMachineName,
+				Name:   kubevirtMachineName,
+				Labels: map[string]string{clusterv1.ClusterNameLabel: clusterName},
 			},
 		}
 
@@ -389,6 +677,37 @@ var _ = Describe("reconcile a kubevirt machine", func() {
 		Expect(bootstrapDataSecret.Labels).To(HaveKeyWithValue("hello", "world"))
 	})
 
+	It("should only publish the infra objects it would create when the cluster is annotated for dry-run", func() {
+		kubevirtCluster.Annotations = map[string]string{infrav1.DryRunAnnotation: "true"}
+		objects := []client.Object{
+			cluster,
+			kubevirtCluster,
+			machine,
+			kubevirtMachine,
+			sshKeySecret,
+			bootstrapSecret,
+		}
+
+		setupClient(kubevirt.DefaultMachineFactory{}, objects)
+		recorder := record.NewFakeRecorder(10)
+		kubevirtMachineReconciler.Recorder = recorder
+
+		infraClusterMock.EXPECT().GenerateInfraClusterClient(kubevirtMachine.Spec.InfraClusterSecretRef, kubevirtMachine.Namespace, machineContext.Context).Return(fakeClient, kubevirtMachine.Namespace, nil)
+
+		out, err := kubevirtMachineReconciler.reconcileDryRun(machineContext)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(out).To(Equal(ctrl.Result{}))
+
+		vmKey := client.ObjectKey{Namespace: kubevirtMachine.Namespace, Name: kubevirtMachine.Name}
+		Expect(apierrors.IsNotFound(fakeClient.Get(gocontext.Background(), vmKey, &kubevirtv1.VirtualMachine{}))).To(BeTrue())
+		userDataKey := client.ObjectKey{Namespace: kubevirtMachine.Namespace, Name: bootstrapSecretName + "-userdata"}
+		Expect(apierrors.IsNotFound(fakeClient.Get(gocontext.Background(), userDataKey, &corev1.Secret{}))).To(BeTrue())
+
+		Expect(recorder.Events).To(Receive(ContainSubstring("Would create Secret")))
+		Expect(recorder.Events).To(Receive(ContainSubstring("Would create VirtualMachine")))
+		Expect(conditions.GetReason(machineContext.KubevirtMachine, infrav1.VMProvisionedCondition)).To(Equal(infrav1.DryRunReason))
+	})
+
 	It("should ensure deletion of KubevirtMachine garbage collects everything successfully", func() {
 		objects := []client.Object{
 			cluster,
@@ -403,9 +722,13 @@ var _ = Describe("reconcile a kubevirt machine", func() {
 		setupClient(machineFactoryMock, objects)
 
 		machineMock.EXPECT().IsTerminal().Return(false, "", nil).Times(1)
+		machineMock.EXPECT().Adopt(gomock.Any()).Return(false, nil).AnyTimes()
+		machineMock.EXPECT().RedeliverBootstrapData(gomock.Any()).Return(false, nil).AnyTimes()
+		machineMock.EXPECT().VMIStatus().Return(nil).AnyTimes()
 		machineMock.EXPECT().Exists().Return(true).Times(1)
 		machineMock.EXPECT().IsReady().Return(false).AnyTimes()
 		machineMock.EXPECT().Address().Return("1.1.1.1").AnyTimes()
+		machineMock.EXPECT().ExternalAddress().Return("1.1.1.1").AnyTimes()
 		machineMock.EXPECT().SupportsCheckingIsBootstrapped().Return(false).AnyTimes()
 		machineMock.EXPECT().GenerateProviderID().Return("abc", nil).AnyTimes()
 		machineMock.EXPECT().GenerateProviderID().Return("abc", nil).AnyTimes()
@@ -506,6 +829,9 @@ var _ = Describe("reconcile a kubevirt machine", func() {
 
 	It("should be able to delete KubeVirt VM even when cluster objects don't exist", func() {
 		controllerutil.AddFinalizer(kubevirtMachine, infrav1.MachineFinalizer)
+		vm.Labels[infrav1.KubevirtMachineNameLabel] = kubevirtMachine.Name
+		vm.Labels[infrav1.KubevirtMachineNamespaceLabel] = kubevirtMachine.Namespace
+		vm.Annotations = map[string]string{infrav1.InfraOwnerAnnotation: ownership.MachineOwner(kubevirtMachine)}
 		objects := []client.Object{
 			machine,
 			kubevirtMachine,
@@ -536,6 +862,115 @@ var _ = Describe("reconcile a kubevirt machine", func() {
 		Expect(machineContext.Machine.ObjectMeta.Finalizers).To(BeEmpty())
 	})
 
+	It("should keep the VM and the finalizer of the deleted machine in read-only mode", func() {
+		controllerutil.AddFinalizer(kubevirtMachine, infrav1.MachineFinalizer)
+		vm.Labels[infrav1.KubevirtMachineNameLabel] = kubevirtMachine.Name
+		vm.Labels[infrav1.KubevirtMachineNamespaceLabel] = kubevirtMachine.Namespace
+		vm.Annotations = map[string]string{infrav1.InfraOwnerAnnotation: ownership.MachineOwner(kubevirtMachine)}
+		objects := []client.Object{
+			machine,
+			kubevirtMachine,
+			bootstrapUserDataSecret,
+			vm,
+		}
+
+		setupClient(machineFactoryMock, objects)
+		kubevirtMachineReconciler.ReadOnly = true
+
+		machineContext = &context.MachineContext{
+			Context:         gocontext.Background(),
+			Machine:         machine,
+			KubevirtMachine: kubevirtMachine,
+			ReadOnly:        true,
+			Logger:          testLogger,
+		}
+
+		infraClusterMock.EXPECT().GenerateInfraClusterClient(kubevirtMachine.Spec.InfraClusterSecretRef, kubevirtMachine.Namespace, machineContext.Context).Return(client.NewDryRunClient(fakeClient), kubevirtMachine.Namespace, nil)
+
+		out, err := kubevirtMachineReconciler.reconcileDelete(machineContext)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(out).To(Equal(ctrl.Result{}))
+
+		Expect(fakeClient.Get(gocontext.Background(), client.ObjectKeyFromObject(vm), &kubevirtv1.VirtualMachine{})).To(Succeed())
+		Expect(kubevirtMachine.Finalizers).To(ContainElement(infrav1.MachineFinalizer))
+	})
+
+	It("should leave a VM and a secret merely named like the machine on deletion", func() {
+		controllerutil.AddFinalizer(kubevirtMachine, infrav1.MachineFinalizer)
+		vm.Labels = nil
+		objects := []client.Object{
+			machine,
+			kubevirtMachine,
+			bootstrapUserDataSecret,
+			vm,
+		}
+
+		setupClient(machineFactoryMock, objects)
+		recorder := record.NewFakeRecorder(10)
+		kubevirtMachineReconciler.Recorder = recorder
+
+		machineContext = &context.MachineContext{
+			Context:         gocontext.Background(),
+			Machine:         machine,
+			KubevirtMachine: kubevirtMachine,
+			Logger:          testLogger,
+		}
+
+		infraClusterMock.EXPECT().GenerateInfraClusterClient(kubevirtMachine.Spec.InfraClusterSecretRef, kubevirtMachine.Namespace, machineContext.Context).Return(fakeClient, kubevirtMachine.Namespace, nil)
+
+		out, err := kubevirtMachineReconciler.reconcileDelete(machineContext)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(out).To(Equal(ctrl.Result{}))
+
+		Expect(fakeClient.Get(gocontext.Background(), client.ObjectKeyFromObject(vm), &kubevirtv1.VirtualMachine{})).To(Succeed())
+		Expect(fakeClient.Get(gocontext.Background(), client.ObjectKeyFromObject(bootstrapUserDataSecret), &corev1.Secret{})).To(Succeed())
+		Expect(recorder.Events).To(Receive(ContainSubstring(infraObjectNotOwnedReason)))
+		Expect(recorder.Events).To(Receive(ContainSubstring(infraObjectNotOwnedReason)))
+
+		Expect(machineContext.Machine.ObjectMeta.Finalizers).To(BeEmpty())
+	})
+
+	DescribeTable("should delete the bootstrap secret created before the owner annotation", func(clusterNameLabel string, deleted bool) {
+		controllerutil.AddFinalizer(kubevirtMachine, infrav1.MachineFinalizer)
+		vm.Labels[infrav1.KubevirtMachineNameLabel] = kubevirtMachine.Name
+		vm.Labels[infrav1.KubevirtMachineNamespaceLabel] = kubevirtMachine.Namespace
+		vm.Annotations = map[string]string{infrav1.InfraOwnerAnnotation: ownership.MachineOwner(kubevirtMachine)}
+		bootstrapUserDataSecret.Labels = map[string]string{clusterv1.ClusterNameLabel: clusterNameLabel}
+		objects := []client.Object{
+			machine,
+			kubevirtMachine,
+			bootstrapUserDataSecret,
+			vm,
+		}
+
+		setupClient(machineFactoryMock, objects)
+		recorder := record.NewFakeRecorder(10)
+		kubevirtMachineReconciler.Recorder = recorder
+
+		machineContext = &context.MachineContext{
+			Context:         gocontext.Background(),
+			Machine:         machine,
+			KubevirtMachine: kubevirtMachine,
+			Logger:          testLogger,
+		}
+
+		infraClusterMock.EXPECT().GenerateInfraClusterClient(kubevirtMachine.Spec.InfraClusterSecretRef, kubevirtMachine.Namespace, machineContext.Context).Return(fakeClient, kubevirtMachine.Namespace, nil)
+
+		_, err := kubevirtMachineReconciler.reconcileDelete(machineContext)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		err = fakeClient.Get(gocontext.Background(), client.ObjectKeyFromObject(bootstrapUserDataSecret), &corev1.Secret{})
+		if deleted {
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		} else {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(recorder.Events).To(Receive(ContainSubstring(infraObjectNotOwnedReason)))
+		}
+	},
+		Entry("with the cluster name label of the machine", "kvcluster", true),
+		Entry("with the cluster name label of another cluster", "another-cluster", false),
+	)
+
 	It("should create KubeVirt VM with externally managed cluster and no ssh key", func() {
 
 		kubevirtCluster.Annotations = map[string]string{
@@ -626,47 +1061,154 @@ var _ = Describe("reconcile a kubevirt machine", func() {
 		Expect(bootstrapDataSecret.Labels).To(HaveKeyWithValue("hello", "world"))
 	})
 
-	It("should detect when VMI is ready and mark KubevirtMachine ready", func() {
-		vmi.Status.Conditions = []kubevirtv1.VirtualMachineInstanceCondition{
-			{
-				Type:   kubevirtv1.VirtualMachineInstanceReady,
-				Status: corev1.ConditionTrue,
-			},
-			{
-				Type:   kubevirtv1.VirtualMachineInstanceIsMigratable,
-				Status: corev1.ConditionTrue,
-			},
-		}
-		vmi.Status.Interfaces = []kubevirtv1.VirtualMachineInstanceNetworkInterface{
+	Context("with batched VM creation", func() {
+		const machineSetName = "md-0-abcde"
 
-			{
-				IP: "1.1.1.1",
-			},
+		newSibling := func(name string, dataSecretName string) (*infrav1.KubevirtMachine, *clusterv1.Machine) {
+			sibling := testing.NewKubevirtMachine(name, name+"-machine")
+			sibling.Labels = map[string]string{clusterv1.MachineSetNameLabel: machineSetName}
+			siblingMachine := testing.NewMachine(clusterName, name+"-machine", sibling)
+			siblingMachine.Spec.Bootstrap.DataSecretName = &dataSecretName
+			return sibling, siblingMachine
 		}
 
-		objects := []client.Object{
-			cluster,
-			kubevirtCluster,
-			machine,
-			kubevirtMachine,
-			sshKeySecret,
-			bootstrapSecret,
-			bootstrapUserDataSecret,
-			vm,
-			vmi,
+		vmExists := func(name string) bool {
+			vmKey := client.ObjectKey{Namespace: kubevirtMachine.Namespace, Name: name}
+			return fakeClient.Get(gocontext.Background(), vmKey, &kubevirtv1.VirtualMachine{}) == nil
 		}
 
-		setupClient(kubevirt.DefaultMachineFactory{}, objects)
-
-		infraClusterMock.EXPECT().GenerateInfraClusterClient(kubevirtMachine.Spec.InfraClusterSecretRef, kubevirtMachine.Namespace, machineContext.Context).Return(fakeClient, kubevirtMachine.Namespace, nil)
-
-		Expect(machineContext.KubevirtMachine.Status.Ready).To(BeFalse())
-		out, err := kubevirtMachineReconciler.reconcileNormal(machineContext)
+		BeforeEach(func() {
+			kubevirtCluster.Spec.BatchedVMCreation = &infrav1.BatchedVMCreationSpec{MaxBatchSize: 3, Parallelism: 2}
+			kubevirtMachine.Labels = map[string]string{clusterv1.MachineSetNameLabel: machineSetName}
+		})
 
-		Expect(err).ShouldNot(HaveOccurred())
+		It("should create the VMs of the pending machines of the MachineSet with the same spec", func() {
+			pending, pendingMachine := newSibling("pending", bootstrapSecretName)
+			provisioned, provisionedMachine := newSibling("provisioned", bootstrapSecretName)
+			provisioned.Spec.ProviderID = ptr.To("kubevirt://provisioned")
+			different, differentMachine := newSibling("different", bootstrapSecretName)
+			different.Spec.Architecture = "arm64"
+			other, otherMachine := newSibling("other", bootstrapSecretName)
+			other.Labels[clusterv1.MachineSetNameLabel] = "md-1-fghij"
+			pendingAfterBatch, pendingAfterBatchMachine := newSibling("pending-z", bootstrapSecretName)
+			pendingBeyondBatch, pendingBeyondBatchMachine := newSibling("pending-zz", bootstrapSecretName)
 
-		// should expect to re-enqueue while waiting for VMI to come online
-		Expect(out).To(Equal(ctrl.Result{}))
+			objects := []client.Object{
+				cluster,
+				kubevirtCluster,
+				machine,
+				kubevirtMachine,
+				sshKeySecret,
+				bootstrapSecret,
+				pending, pendingMachine,
+				provisioned, provisionedMachine,
+				different, differentMachine,
+				other, otherMachine,
+				pendingAfterBatch, pendingAfterBatchMachine,
+				pendingBeyondBatch, pendingBeyondBatchMachine,
+			}
+
+			setupClient(kubevirt.DefaultMachineFactory{}, objects)
+			recorder := record.NewFakeRecorder(10)
+			kubevirtMachineReconciler.Recorder = recorder
+
+			infraClusterMock.EXPECT().GenerateInfraClusterClient(kubevirtMachine.Spec.InfraClusterSecretRef, kubevirtMachine.Namespace, machineContext.Context).Return(fakeClient, kubevirtMachine.Namespace, nil)
+
+			out, err := kubevirtMachineReconciler.reconcileNormal(machineContext)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(out).To(Equal(ctrl.Result{RequeueAfter: 20 * time.Second}))
+
+			Expect(vmExists(kubevirtMachine.Name)).To(BeTrue())
+			Expect(vmExists(pending.Name)).To(BeTrue())
+			Expect(vmExists(pendingAfterBatch.Name)).To(BeTrue())
+			Expect(vmExists(pendingBeyondBatch.Name)).To(BeFalse())
+			Expect(vmExists(provisioned.Name)).To(BeFalse())
+			Expect(vmExists(different.Name)).To(BeFalse())
+			Expect(vmExists(other.Name)).To(BeFalse())
+
+			Expect(recorder.Events).To(HaveLen(2))
+			Expect(<-recorder.Events).To(ContainSubstring(batchedVMCreatedReason))
+		})
+
+		It("should leave the machines failing to create their VM in the batch to their own reconcile", func() {
+			pending, pendingMachine := newSibling("pending", bootstrapSecretName)
+			failing, failingMachine := newSibling("failing", "missing-bootstrap-secret")
+
+			objects := []client.Object{
+				cluster,
+				kubevirtCluster,
+				machine,
+				kubevirtMachine,
+				sshKeySecret,
+				bootstrapSecret,
+				pending, pendingMachine,
+				failing, failingMachine,
+			}
+
+			setupClient(kubevirt.DefaultMachineFactory{}, objects)
+			recorder := record.NewFakeRecorder(10)
+			kubevirtMachineReconciler.Recorder = recorder
+
+			infraClusterMock.EXPECT().GenerateInfraClusterClient(kubevirtMachine.Spec.InfraClusterSecretRef, kubevirtMachine.Namespace, machineContext.Context).Return(fakeClient, kubevirtMachine.Namespace, nil)
+
+			out, err := kubevirtMachineReconciler.reconcileNormal(machineContext)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(out).To(Equal(ctrl.Result{RequeueAfter: 20 * time.Second}))
+
+			Expect(vmExists(kubevirtMachine.Name)).To(BeTrue())
+			Expect(vmExists(pending.Name)).To(BeTrue())
+			Expect(vmExists(failing.Name)).To(BeFalse())
+
+			events := []string{<-recorder.Events, <-recorder.Events}
+			Expect(events).To(ConsistOf(
+				ContainSubstring(batchedVMCreateFailedReason),
+				ContainSubstring(batchedVMCreatedReason),
+			))
+			Expect(conditions.Get(machineContext.KubevirtMachine, infrav1.VMProvisionedCondition)).To(BeNil())
+		})
+	})
+
+	It("should detect when VMI is ready and mark KubevirtMachine ready", func() {
+		vmi.Status.Conditions = []kubevirtv1.VirtualMachineInstanceCondition{
+			{
+				Type:   kubevirtv1.VirtualMachineInstanceReady,
+				Status: corev1.ConditionTrue,
+			},
+			{
+				Type:   kubevirtv1.VirtualMachineInstanceIsMigratable,
+				Status: corev1.ConditionTrue,
+			},
+		}
+		vmi.Status.Interfaces = []kubevirtv1.VirtualMachineInstanceNetworkInterface{
+
+			{
+				IP: "1.1.1.1",
+			},
+		}
+
+		objects := []client.Object{
+			cluster,
+			kubevirtCluster,
+			machine,
+			kubevirtMachine,
+			sshKeySecret,
+			bootstrapSecret,
+			bootstrapUserDataSecret,
+			vm,
+			vmi,
+		}
+
+		setupClient(kubevirt.DefaultMachineFactory{}, objects)
+
+		infraClusterMock.EXPECT().GenerateInfraClusterClient(kubevirtMachine.Spec.InfraClusterSecretRef, kubevirtMachine.Namespace, machineContext.Context).Return(fakeClient, kubevirtMachine.Namespace, nil)
+
+		Expect(machineContext.KubevirtMachine.Status.Ready).To(BeFalse())
+		out, err := kubevirtMachineReconciler.reconcileNormal(machineContext)
+
+		Expect(err).ShouldNot(HaveOccurred())
+
+		// should expect to re-enqueue while waiting for VMI to come online
+		Expect(out).To(Equal(ctrl.Result{}))
 
 		// should expect VM to be created with expected name
 		vm := &kubevirtv1.VirtualMachine{}
@@ -759,6 +1301,128 @@ var _ = Describe("reconcile a kubevirt machine", func() {
 		Expect(*machineContext.KubevirtMachine.Status.FailureMessage).To(Equal("VMI has reached a permanent finalized state"))
 	})
 
+	It("should not set FailureReason for an unrecoverable VMI while the cluster is in maintenance", func() {
+		vmi.Status.Phase = kubevirtv1.Failed
+		runStrategy := kubevirtv1.RunStrategyOnce
+		vm.Spec.RunStrategy = &runStrategy
+		kubevirtCluster.Annotations = map[string]string{
+			infrav1.MaintenanceAnnotation: time.Now().Add(time.Hour).Format(time.RFC3339),
+		}
+
+		objects := []client.Object{
+			cluster,
+			kubevirtCluster,
+			machine,
+			kubevirtMachine,
+			sshKeySecret,
+			bootstrapSecret,
+			bootstrapUserDataSecret,
+			vm,
+			vmi,
+		}
+
+		setupClient(kubevirt.DefaultMachineFactory{}, objects)
+
+		infraClusterMock.EXPECT().GenerateInfraClusterClient(kubevirtMachine.Spec.InfraClusterSecretRef, kubevirtMachine.Namespace, machineContext.Context).Return(fakeClient, kubevirtMachine.Namespace, nil)
+
+		out, err := kubevirtMachineReconciler.reconcileNormal(machineContext)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(out.RequeueAfter).To(Equal(time.Minute))
+		Expect(machineContext.KubevirtMachine.Status.FailureReason).To(BeNil())
+		Expect(conditions.GetReason(machineContext.KubevirtMachine, infrav1.VMProvisionedCondition)).To(Equal(infrav1.InMaintenanceReason))
+	})
+
+	It("should propagate the infra node of the VMI onto the workload Node once it changes", func() {
+		vmi.Status.NodeName = "infra-node-2"
+		infraNode := &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name:   "infra-node-2",
+			Labels: map[string]string{corev1.LabelTopologyZone: "zone-b"},
+		}}
+		kubevirtMachine.Status.NodeUpdated = true
+		kubevirtMachine.Status.VirtualMachineInstance = &infrav1.VirtualMachineInstanceInfo{NodeName: "infra-node-1", Zone: "zone-a"}
+
+		objects := []client.Object{
+			cluster,
+			kubevirtCluster,
+			machine,
+			kubevirtMachine,
+			sshKeySecret,
+			bootstrapSecret,
+			bootstrapUserDataSecret,
+			vm,
+			vmi,
+			infraNode,
+		}
+
+		setupClient(kubevirt.DefaultMachineFactory{}, objects)
+
+		infraClusterMock.EXPECT().GenerateInfraClusterClient(kubevirtMachine.Spec.InfraClusterSecretRef, kubevirtMachine.Namespace, machineContext.Context).Return(fakeClient, kubevirtMachine.Namespace, nil)
+
+		_, _ = kubevirtMachineReconciler.reconcileNormal(machineContext)
+		Expect(machineContext.KubevirtMachine.Status.VirtualMachineInstance.NodeName).To(Equal("infra-node-2"))
+		Expect(machineContext.KubevirtMachine.Status.VirtualMachineInstance.Zone).To(Equal("zone-b"))
+		Expect(machineContext.KubevirtMachine.Status.NodeUpdated).To(BeFalse())
+	})
+
+	Context("when the VM of a provisioned machine was deleted", func() {
+		var workloadClusterClient client.Client
+
+		newNode := func(providerID string) *corev1.Node {
+			return &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: kubevirtMachine.Name},
+				Spec:       corev1.NodeSpec{ProviderID: providerID},
+			}
+		}
+
+		BeforeEach(func() {
+			providerID := "kubevirt://" + kubevirtMachine.Name
+			kubevirtMachine.Spec.ProviderID = &providerID
+		})
+
+		reconcile := func() (ctrl.Result, error) {
+			objects := []client.Object{
+				cluster,
+				kubevirtCluster,
+				machine,
+				kubevirtMachine,
+				sshKeySecret,
+				bootstrapSecret,
+				bootstrapUserDataSecret,
+			}
+			setupClient(kubevirt.DefaultMachineFactory{}, objects)
+
+			infraClusterMock.EXPECT().GenerateInfraClusterClient(kubevirtMachine.Spec.InfraClusterSecretRef, kubevirtMachine.Namespace, machineContext.Context).Return(fakeClient, kubevirtMachine.Namespace, nil)
+			workloadClusterMock.EXPECT().GenerateWorkloadClusterClient(machineContext).Return(workloadClusterClient, nil)
+
+			return kubevirtMachineReconciler.reconcileNormal(machineContext)
+		}
+
+		It("should fail the machine and delete its Node instead of recreating the VM", func() {
+			workloadClusterClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(newNode(*kubevirtMachine.Spec.ProviderID)).Build()
+
+			_, err := reconcile()
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(machineContext.KubevirtMachine.Status.Ready).To(BeFalse())
+			Expect(machineContext.KubevirtMachine.Status.FailureReason).ToNot(BeNil())
+			Expect(*machineContext.KubevirtMachine.Status.FailureReason).To(Equal(capierrors.UpdateMachineError))
+			Expect(conditions.GetReason(machineContext.KubevirtMachine, infrav1.VMProvisionedCondition)).To(Equal(infrav1.VMDeletedReason))
+
+			Expect(apierrors.IsNotFound(workloadClusterClient.Get(gocontext.Background(), client.ObjectKey{Name: kubevirtMachine.Name}, &corev1.Node{}))).To(BeTrue())
+			Expect(apierrors.IsNotFound(fakeClient.Get(gocontext.Background(), client.ObjectKeyFromObject(kubevirtMachine), &kubevirtv1.VirtualMachine{}))).To(BeTrue())
+		})
+
+		It("should not delete a Node registered by another VM", func() {
+			workloadClusterClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(newNode("kubevirt://another-vm")).Build()
+
+			_, err := reconcile()
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(machineContext.KubevirtMachine.Status.FailureReason).ToNot(BeNil())
+			Expect(workloadClusterClient.Get(gocontext.Background(), client.ObjectKey{Name: kubevirtMachine.Name}, &corev1.Node{})).To(Succeed())
+		})
+	})
+
 	Context("update kubevirt machine conditions correctly", func() {
 		It("adds a failed VMProvisionedCondition with reason WaitingForClusterInfrastructureReason when the infrastructure is not ready", func() {
 			cluster.Status.InfrastructureReady = false
@@ -926,6 +1590,45 @@ var _ = Describe("reconcile a kubevirt machine", func() {
 				Expect(conditions[0].Type).To(Equal(infrav1.VMProvisionedCondition))
 				Expect(conditions[0].Status).To(Equal(corev1.ConditionFalse))
 				Expect(conditions[0].Reason).To(Equal(infrav1.VMCreateFailedReason))
+				Expect(machineContext.KubevirtMachine.Status.FailureReason).To(BeNil())
+			})
+
+			It("sets a terminal failure when the VM template is invalid", func() {
+				objects := []client.Object{
+					cluster,
+					kubevirtCluster,
+					machine,
+					kubevirtMachine,
+					sshKeySecret,
+					bootstrapSecret,
+				}
+
+				injectErr := interceptor.Funcs{
+					Create: func(ctx gocontext.Context, client client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+						vm, ok := obj.(*kubevirtv1.VirtualMachine)
+						if ok {
+							return apierrors.NewInvalid(kubevirtv1.VirtualMachineGroupVersionKind.GroupKind(), vm.Name, nil)
+						}
+						return nil
+					},
+				}
+
+				setupClientWithInterceptors(kubevirt.DefaultMachineFactory{}, objects, injectErr)
+
+				infraClusterMock.EXPECT().GenerateInfraClusterClient(kubevirtMachine.Spec.InfraClusterSecretRef, kubevirtMachine.Namespace, machineContext.Context).Return(fakeClient, kubevirtMachine.Namespace, nil).Times(2)
+
+				_, err := kubevirtMachineReconciler.reconcileNormal(machineContext)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				Expect(machineContext.KubevirtMachine.Status.FailureReason).ToNot(BeNil())
+				Expect(*machineContext.KubevirtMachine.Status.FailureReason).To(Equal(capierrors.CreateMachineError))
+				Expect(machineContext.KubevirtMachine.Status.FailureMessage).ToNot(BeNil())
+				Expect(conditions.GetSeverity(machineContext.KubevirtMachine, infrav1.VMProvisionedCondition)).To(HaveValue(Equal(clusterv1.ConditionSeverityError)))
+
+				// the creation is not retried
+				_, err = kubevirtMachineReconciler.reconcileNormal(machineContext)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(apierrors.IsNotFound(fakeClient.Get(gocontext.Background(), client.ObjectKeyFromObject(kubevirtMachine), &kubevirtv1.VirtualMachine{}))).To(BeTrue())
 			})
 
 			It("adds a succeeded VMProvisionedCondition", func() {
@@ -952,8 +1655,12 @@ var _ = Describe("reconcile a kubevirt machine", func() {
 				machineMock.EXPECT().IsBootstrapped().Return(true).AnyTimes()
 				machineMock.EXPECT().GenerateProviderID().Return("abc", nil).Times(1)
 				machineMock.EXPECT().IsTerminal().Return(false, "", nil).Times(1)
+				machineMock.EXPECT().Adopt(gomock.Any()).Return(false, nil).AnyTimes()
+				machineMock.EXPECT().RedeliverBootstrapData(gomock.Any()).Return(false, nil).AnyTimes()
+				machineMock.EXPECT().VMIStatus().Return(nil).AnyTimes()
 				machineMock.EXPECT().Exists().Return(true).Times(1)
 				machineMock.EXPECT().Address().Return("1.1.1.1").Times(1)
+				machineMock.EXPECT().ExternalAddress().Return("1.1.1.1").AnyTimes()
 				machineMock.EXPECT().SupportsCheckingIsBootstrapped().Return(false).Times(1)
 				machineMock.EXPECT().DrainNodeIfNeeded(gomock.Any()).Return(time.Duration(0), nil)
 				machineMock.EXPECT().IsLiveMigratable().Return(false, "", "", nil).Times(1)
@@ -971,6 +1678,39 @@ var _ = Describe("reconcile a kubevirt machine", func() {
 				Expect(conditions[1].Type).To(Equal(infrav1.VMProvisionedCondition))
 				Expect(conditions[1].Status).To(Equal(corev1.ConditionTrue))
 			})
+			It("restarts the VM when the bootstrap data changed before it booted", func() {
+				objects := []client.Object{
+					cluster,
+					kubevirtCluster,
+					machine,
+					kubevirtMachine,
+					bootstrapSecret,
+					bootstrapUserDataSecret,
+					sshKeySecret,
+					vm,
+					vmi,
+				}
+
+				setupClient(machineFactoryMock, objects)
+
+				machineMock.EXPECT().IsTerminal().Return(false, "", nil).Times(1)
+				machineMock.EXPECT().Adopt(gomock.Any()).Return(false, nil).AnyTimes()
+				machineMock.EXPECT().RedeliverBootstrapData(gomock.Any()).Return(true, nil).Times(1)
+				machineMock.EXPECT().VMIStatus().Return(nil).AnyTimes()
+				machineMock.EXPECT().Exists().Return(true).Times(1)
+
+				machineFactoryMock.EXPECT().NewMachine(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(machineMock, nil).Times(1)
+
+				infraClusterMock.EXPECT().GenerateInfraClusterClient(kubevirtMachine.Spec.InfraClusterSecretRef, kubevirtMachine.Namespace, machineContext.Context).Return(fakeClient, kubevirtMachine.Namespace, nil)
+
+				result, err := kubevirtMachineReconciler.reconcileNormal(machineContext)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(result.RequeueAfter).To(Equal(20 * time.Second))
+
+				conditions := machineContext.KubevirtMachine.GetConditions()
+				Expect(conditions[0].Type).To(Equal(infrav1.VMProvisionedCondition))
+				Expect(conditions[0].Reason).To(Equal(infrav1.WaitingForBootstrapDataReason))
+			})
 			It("adds a failed BootstrapExecSucceededCondition with reason BootstrapFailedReason when bootstraping is possible and failed", func() {
 				vmiReadyCondition := kubevirtv1.VirtualMachineInstanceCondition{
 					Type:   kubevirtv1.VirtualMachineInstanceReady,
@@ -998,10 +1738,14 @@ var _ = Describe("reconcile a kubevirt machine", func() {
 				}
 
 				machineMock.EXPECT().IsTerminal().Return(false, "", nil).Times(1)
+				machineMock.EXPECT().Adopt(gomock.Any()).Return(false, nil).AnyTimes()
+				machineMock.EXPECT().RedeliverBootstrapData(gomock.Any()).Return(false, nil).AnyTimes()
+				machineMock.EXPECT().VMIStatus().Return(nil).AnyTimes()
 				machineMock.EXPECT().Exists().Return(true).Times(1)
 				machineMock.EXPECT().Create(nil).Return(nil).AnyTimes()
 				machineMock.EXPECT().IsReady().Return(true).Times(1)
 				machineMock.EXPECT().Address().Return("1.1.1.1").Times(1)
+				machineMock.EXPECT().ExternalAddress().Return("1.1.1.1").AnyTimes()
 				machineMock.EXPECT().GenerateProviderID().Return("abc", nil).AnyTimes()
 				machineMock.EXPECT().SupportsCheckingIsBootstrapped().Return(true)
 				machineMock.EXPECT().IsBootstrapped().Return(false)
@@ -1049,9 +1793,13 @@ var _ = Describe("reconcile a kubevirt machine", func() {
 				}
 
 				machineMock.EXPECT().IsTerminal().Return(false, "", nil).Times(1)
+				machineMock.EXPECT().Adopt(gomock.Any()).Return(false, nil).AnyTimes()
+				machineMock.EXPECT().RedeliverBootstrapData(gomock.Any()).Return(false, nil).AnyTimes()
+				machineMock.EXPECT().VMIStatus().Return(nil).AnyTimes()
 				machineMock.EXPECT().Exists().Return(true).Times(1)
 				machineMock.EXPECT().IsReady().Return(true).Times(2)
 				machineMock.EXPECT().Address().Return("1.1.1.1").Times(1)
+				machineMock.EXPECT().ExternalAddress().Return("1.1.1.1").AnyTimes()
 				machineMock.EXPECT().GenerateProviderID().Return("abc", nil).Times(1)
 				machineMock.EXPECT().SupportsCheckingIsBootstrapped().Return(true)
 				machineMock.EXPECT().IsBootstrapped().Return(true)
@@ -1105,9 +1853,13 @@ var _ = Describe("reconcile a kubevirt machine", func() {
 				}
 
 				machineMock.EXPECT().IsTerminal().Return(false, "", nil).Times(1)
+				machineMock.EXPECT().Adopt(gomock.Any()).Return(false, nil).AnyTimes()
+				machineMock.EXPECT().RedeliverBootstrapData(gomock.Any()).Return(false, nil).AnyTimes()
+				machineMock.EXPECT().VMIStatus().Return(nil).AnyTimes()
 				machineMock.EXPECT().Exists().Return(true).Times(1)
 				machineMock.EXPECT().IsReady().Return(true).Times(2)
 				machineMock.EXPECT().Address().Return("1.1.1.1").Times(1)
+				machineMock.EXPECT().ExternalAddress().Return("1.1.1.1").AnyTimes()
 				machineMock.EXPECT().GenerateProviderID().Return("abc", nil).Times(1)
 				machineMock.EXPECT().SupportsCheckingIsBootstrapped().Return(true)
 				machineMock.EXPECT().IsBootstrapped().Return(true)
@@ -1159,9 +1911,13 @@ var _ = Describe("reconcile a kubevirt machine", func() {
 
 				const requeueDurationSeconds = 3
 				machineMock.EXPECT().IsTerminal().Return(false, "", nil).Times(1)
+				machineMock.EXPECT().Adopt(gomock.Any()).Return(false, nil).AnyTimes()
+				machineMock.EXPECT().RedeliverBootstrapData(gomock.Any()).Return(false, nil).AnyTimes()
+				machineMock.EXPECT().VMIStatus().Return(nil).AnyTimes()
 				machineMock.EXPECT().Exists().Return(true).Times(1)
 				machineMock.EXPECT().IsReady().Return(true).Times(1)
 				machineMock.EXPECT().Address().Return("1.1.1.1").Times(1)
+				machineMock.EXPECT().ExternalAddress().Return("1.1.1.1").AnyTimes()
 				machineMock.EXPECT().DrainNodeIfNeeded(gomock.Any()).Return(time.Second*requeueDurationSeconds, nil).Times(1)
 
 				machineFactoryMock.EXPECT().NewMachine(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(machineMock, nil).Times(1)
@@ -1204,9 +1960,13 @@ var _ = Describe("reconcile a kubevirt machine", func() {
 
 				const requeueDurationSeconds = 3
 				machineMock.EXPECT().IsTerminal().Return(false, "", nil).Times(1)
+				machineMock.EXPECT().Adopt(gomock.Any()).Return(false, nil).AnyTimes()
+				machineMock.EXPECT().RedeliverBootstrapData(gomock.Any()).Return(false, nil).AnyTimes()
+				machineMock.EXPECT().VMIStatus().Return(nil).AnyTimes()
 				machineMock.EXPECT().Exists().Return(true).Times(1)
 				machineMock.EXPECT().IsReady().Return(true).Times(1)
 				machineMock.EXPECT().Address().Return("1.1.1.1").Times(1)
+				machineMock.EXPECT().ExternalAddress().Return("1.1.1.1").AnyTimes()
 				machineMock.EXPECT().DrainNodeIfNeeded(gomock.Any()).Return(time.Second*requeueDurationSeconds, fmt.Errorf("mock error")).Times(1)
 
 				machineFactoryMock.EXPECT().NewMachine(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(machineMock, nil).Times(1)
@@ -1369,6 +2129,73 @@ var _ = Describe("updateNodeProviderID", func() {
 		Expect(kubevirtMachine.Status.NodeUpdated).To(BeTrue())
 	})
 
+	It("should set the topology of the VM on the Node", func() {
+		kubevirtMachine.Spec.ProviderID = &expectedProviderId
+		kubevirtMachine.Status.VirtualMachineInstance = &infrav1.VirtualMachineInstanceInfo{NodeName: "infra-node-1", Zone: "zone-a"}
+		machineContext := &context.MachineContext{KubevirtMachine: kubevirtMachine, Logger: testLogger}
+		workloadClusterMock.EXPECT().GenerateWorkloadClusterClient(machineContext).Return(fakeWorkloadClusterClient, nil)
+		_, err := kubevirtMachineReconciler.updateNodeProviderID(machineContext)
+		Expect(err).ShouldNot(HaveOccurred())
+		workloadClusterNode := &corev1.Node{}
+		Expect(fakeWorkloadClusterClient.Get(machineContext, client.ObjectKey{Name: kubevirtMachine.Name}, workloadClusterNode)).To(Succeed())
+		Expect(workloadClusterNode.Spec.ProviderID).To(Equal(expectedProviderId))
+		Expect(workloadClusterNode.Labels).To(HaveKeyWithValue(infrav1.HypervisorLabel, "infra-node-1"))
+		Expect(workloadClusterNode.Labels).To(HaveKeyWithValue(corev1.LabelTopologyZone, "zone-a"))
+		Expect(kubevirtMachine.Status.NodeUpdated).To(BeTrue())
+	})
+
+	It("should update the topology of a Node after its VM migrated", func() {
+		kubevirtMachine.Spec.ProviderID = &expectedProviderId
+		workloadClusterNode := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   kubevirtMachine.Name,
+				Labels: map[string]string{infrav1.HypervisorLabel: "infra-node-1"},
+			},
+			Spec: corev1.NodeSpec{ProviderID: expectedProviderId},
+		}
+		fakeWorkloadClusterClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(workloadClusterNode).Build()
+		kubevirtMachine.Status.VirtualMachineInstance = &infrav1.VirtualMachineInstanceInfo{NodeName: "infra-node-2"}
+		machineContext := &context.MachineContext{KubevirtMachine: kubevirtMachine, Logger: testLogger}
+		workloadClusterMock.EXPECT().GenerateWorkloadClusterClient(machineContext).Return(fakeWorkloadClusterClient, nil)
+		_, err := kubevirtMachineReconciler.updateNodeProviderID(machineContext)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(fakeWorkloadClusterClient.Get(machineContext, client.ObjectKeyFromObject(workloadClusterNode), workloadClusterNode)).To(Succeed())
+		Expect(workloadClusterNode.Labels).To(HaveKeyWithValue(infrav1.HypervisorLabel, "infra-node-2"))
+	})
+
+	It("should mirror the health of the Node", func() {
+		kubevirtMachine.Spec.ProviderID = &expectedProviderId
+		kubevirtMachine.Status.NodeUpdated = true
+		workloadClusterNode := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: kubevirtMachine.Name},
+			Spec:       corev1.NodeSpec{ProviderID: expectedProviderId},
+			Status: corev1.NodeStatus{
+				NodeInfo: corev1.NodeSystemInfo{KubeletVersion: "v1.30.1"},
+				Conditions: []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionFalse, Message: "container runtime network not ready"},
+				},
+			},
+		}
+		fakeWorkloadClusterClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(workloadClusterNode).Build()
+		machineContext := &context.MachineContext{KubevirtMachine: kubevirtMachine, Logger: testLogger}
+		workloadClusterMock.EXPECT().GenerateWorkloadClusterClient(machineContext).Return(fakeWorkloadClusterClient, nil).Times(2)
+
+		_, err := kubevirtMachineReconciler.updateNodeProviderID(machineContext)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(kubevirtMachine.Status.Node).To(Equal(&infrav1.NodeInfo{Ready: corev1.ConditionFalse, KubeletVersion: "v1.30.1"}))
+		Expect(conditions.IsFalse(kubevirtMachine, infrav1.NodeReadyCondition)).To(BeTrue())
+		Expect(conditions.GetReason(kubevirtMachine, infrav1.NodeReadyCondition)).To(Equal(infrav1.NodeNotReadyReason))
+		Expect(conditions.GetMessage(kubevirtMachine, infrav1.NodeReadyCondition)).To(Equal("container runtime network not ready"))
+
+		workloadClusterNode.Status.Conditions[0].Status = corev1.ConditionTrue
+		Expect(fakeWorkloadClusterClient.Status().Update(machineContext, workloadClusterNode)).To(Succeed())
+
+		_, err = kubevirtMachineReconciler.updateNodeProviderID(machineContext)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(kubevirtMachine.Status.Node.Ready).To(Equal(corev1.ConditionTrue))
+		Expect(conditions.IsTrue(kubevirtMachine, infrav1.NodeReadyCondition)).To(BeTrue())
+	})
+
 	It("GenerateWorkloadClusterClient failure", func() {
 		kubevirtMachine.Spec.ProviderID = &expectedProviderId
 		machineContext := &context.MachineContext{KubevirtMachine: kubevirtMachine, Logger: testLogger}
@@ -1399,5 +2226,1655 @@ var _ = Describe("updateNodeProviderID", func() {
 		).To(Succeed())
 		Expect(workloadClusterNode.Spec.ProviderID).NotTo(Equal(expectedProviderId))
 		Expect(kubevirtMachine.Status.NodeUpdated).To(BeFalse())
+		Expect(conditions.GetReason(kubevirtMachineNotExist, infrav1.NodeReadyCondition)).To(Equal(infrav1.WaitingForNodeReason))
+	})
+})
+
+var _ = Describe("run a command inside a kubevirt machine", func() {
+	var (
+		mockCtrl         *gomock.Controller
+		infraClusterMock *infraclustermock.MockInfraCluster
+		guestAgentMock   *guestagentmock.MockRunner
+		recorder         *record.FakeRecorder
+		machineContext   *context.MachineContext
+		restConfig       = &rest.Config{Host: "https://infra"}
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		infraClusterMock = infraclustermock.NewMockInfraCluster(mockCtrl)
+		guestAgentMock = guestagentmock.NewMockRunner(mockCtrl)
+		recorder = record.NewFakeRecorder(10)
+
+		kubevirtMachine = testing.NewKubevirtMachine("test-kubevirt-machine", "test-machine")
+		kubevirtMachine.Annotations = map[string]string{infrav1.RunCommandAnnotation: "kubelet-logs"}
+
+		fakeClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(kubevirtMachine).Build()
+		kubevirtMachineReconciler = KubevirtMachineReconciler{
+			Client:       fakeClient,
+			InfraCluster: infraClusterMock,
+			GuestAgent:   guestAgentMock,
+			Recorder:     recorder,
+		}
+		machineContext = &context.MachineContext{
+			Context:         gocontext.Background(),
+			KubevirtMachine: kubevirtMachine,
+			Logger:          ctrl.Log.WithName("test"),
+		}
+	})
+
+	getResult := func() *corev1.ConfigMap {
+		configMap := &corev1.ConfigMap{}
+		Expect(fakeClient.Get(gocontext.Background(), client.ObjectKey{Name: kubevirtMachine.Name + "-kubelet-logs"}, configMap)).To(Succeed())
+		return configMap
+	}
+
+	It("should run an allowed command once and store its result", func() {
+		infraClusterMock.EXPECT().GenerateInfraClusterRestConfig(kubevirtMachine.Spec.InfraClusterSecretRef, kubevirtMachine.Namespace, machineContext.Context).Return(restConfig, "infra-ns", nil)
+		guestAgentMock.EXPECT().Run(machineContext, restConfig, "infra-ns", kubevirtMachine.Name, guestagent.Commands["kubelet-logs"]).Return(&guestagent.Result{Stdout: "kubelet is running"}, nil)
+
+		Expect(kubevirtMachineReconciler.reconcileCommand(machineContext, "infra-ns")).To(Succeed())
+		Expect(kubevirtMachine.Annotations).ToNot(HaveKey(infrav1.RunCommandAnnotation))
+
+		configMap := getResult()
+		Expect(configMap.Data).To(HaveKeyWithValue("exitCode", "0"))
+		Expect(configMap.Data).To(HaveKeyWithValue("stdout", "kubelet is running"))
+		Expect(configMap.OwnerReferences).To(HaveLen(1))
+		Expect(recorder.Events).To(Receive(ContainSubstring(commandSucceededReason)))
+
+		// the annotation is gone, nothing runs anymore
+		Expect(kubevirtMachineReconciler.reconcileCommand(machineContext, "infra-ns")).To(Succeed())
+	})
+
+	It("should store the error of a command that could not run", func() {
+		infraClusterMock.EXPECT().GenerateInfraClusterRestConfig(gomock.Any(), gomock.Any(), gomock.Any()).Return(restConfig, "infra-ns", nil)
+		guestAgentMock.EXPECT().Run(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("guest agent not connected"))
+
+		Expect(kubevirtMachineReconciler.reconcileCommand(machineContext, "infra-ns")).To(Succeed())
+		Expect(kubevirtMachine.Annotations).ToNot(HaveKey(infrav1.RunCommandAnnotation))
+		Expect(getResult().Data).To(HaveKeyWithValue("error", "guest agent not connected"))
+		Expect(recorder.Events).To(Receive(ContainSubstring(commandFailedReason)))
+	})
+
+	It("should not run a command that is not allowed", func() {
+		kubevirtMachine.Annotations[infrav1.RunCommandAnnotation] = "rm -rf /"
+
+		Expect(kubevirtMachineReconciler.reconcileCommand(machineContext, "infra-ns")).To(Succeed())
+		Expect(kubevirtMachine.Annotations).ToNot(HaveKey(infrav1.RunCommandAnnotation))
+		Expect(recorder.Events).To(Receive(ContainSubstring("is not allowed")))
+	})
+
+	It("should not run a command when the guest agent of the machine is disabled", func() {
+		kubevirtMachine.Spec.GuestAgent = &infrav1.GuestAgentSpec{Mode: infrav1.GuestAgentDisabled}
+
+		Expect(kubevirtMachineReconciler.reconcileCommand(machineContext, "infra-ns")).To(Succeed())
+		Expect(kubevirtMachine.Annotations).ToNot(HaveKey(infrav1.RunCommandAnnotation))
+		Expect(recorder.Events).To(Receive(ContainSubstring("guest agent of the machine is disabled")))
+	})
+
+	It("should run the command in the background, and store its result once it completes", func() {
+		machineContext.Operations = operations.NewTracker(1)
+		release := make(chan struct{})
+		infraClusterMock.EXPECT().GenerateInfraClusterRestConfig(gomock.Any(), gomock.Any(), gomock.Any()).Return(restConfig, "infra-ns", nil).AnyTimes()
+		guestAgentMock.EXPECT().Run(gomock.Any(), restConfig, "infra-ns", kubevirtMachine.Name, guestagent.Commands["kubelet-logs"]).DoAndReturn(
+			func(gocontext.Context, *rest.Config, string, string, []string) (*guestagent.Result, error) {
+				<-release
+				return &guestagent.Result{Stdout: "kubelet is running"}, nil
+			})
+
+		Expect(kubevirtMachineReconciler.reconcileCommand(machineContext, "infra-ns")).To(Succeed())
+		Expect(kubevirtMachine.Annotations).To(HaveKey(infrav1.RunCommandAnnotation))
+		Expect(kubevirtMachine.Status.Operations).To(ConsistOf(HaveField("Phase", infrav1.RunningMachineOperationPhase)))
+
+		close(release)
+		Eventually(func() map[string]string {
+			Expect(kubevirtMachineReconciler.reconcileCommand(machineContext, "infra-ns")).To(Succeed())
+			return kubevirtMachine.Annotations
+		}).ShouldNot(HaveKey(infrav1.RunCommandAnnotation))
+		Expect(kubevirtMachine.Status.Operations).To(ConsistOf(And(
+			HaveField("Type", "Command/kubelet-logs"),
+			HaveField("Phase", infrav1.SucceededMachineOperationPhase),
+		)))
+		Expect(getResult().Data).To(HaveKeyWithValue("stdout", "kubelet is running"))
+	})
+
+	It("should leave the command pending in read-only mode", func() {
+		kubevirtMachineReconciler.ReadOnly = true
+
+		Expect(kubevirtMachineReconciler.reconcileCommand(machineContext, "infra-ns")).To(Succeed())
+		Expect(kubevirtMachine.Annotations).To(HaveKeyWithValue(infrav1.RunCommandAnnotation, "kubelet-logs"))
+		Expect(recorder.Events).ToNot(Receive())
+	})
+})
+
+var _ = Describe("provisioning timeouts", func() {
+	var (
+		mockCtrl       *gomock.Controller
+		machineMock    *machinemocks.MockMachineInterface
+		machineContext *context.MachineContext
+		reconciler     KubevirtMachineReconciler
+		now            = time.Date(2024, time.March, 4, 10, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		machineMock = machinemocks.NewMockMachineInterface(mockCtrl)
+
+		kubevirtMachine := testing.NewKubevirtMachine("test-kubevirt-machine", "test-machine")
+		kubevirtMachine.Spec.ProvisioningTimeouts = &infrav1.ProvisioningTimeouts{
+			VMStart:          &metav1.Duration{Duration: 10 * time.Minute},
+			MaxVMRecreations: 1,
+		}
+		machineContext = &context.MachineContext{
+			Context:         gocontext.Background(),
+			Machine:         testing.NewMachine("test-cluster", "test-machine", kubevirtMachine),
+			KubevirtMachine: kubevirtMachine,
+			Logger:          ctrl.Log.WithName("test"),
+		}
+		reconciler = KubevirtMachineReconciler{}
+	})
+
+	It("should expire a phase once it lasted longer than its timeout", func() {
+		Expect(provisioningPhaseExpired(machineContext, infrav1.VMStartPhase, now)).To(BeFalse())
+		Expect(machineContext.KubevirtMachine.Status.ProvisioningPhase).To(Equal(infrav1.VMStartPhase))
+		Expect(machineContext.KubevirtMachine.Status.ProvisioningPhaseStartTime.Time).To(Equal(now))
+
+		Expect(provisioningPhaseExpired(machineContext, infrav1.VMStartPhase, now.Add(10*time.Minute))).To(BeFalse())
+		Expect(provisioningPhaseExpired(machineContext, infrav1.VMStartPhase, now.Add(11*time.Minute))).To(BeTrue())
+	})
+
+	It("should restart the clock when the phase changes", func() {
+		Expect(provisioningPhaseExpired(machineContext, infrav1.DataVolumeImportPhase, now)).To(BeFalse())
+		Expect(provisioningPhaseExpired(machineContext, infrav1.VMStartPhase, now.Add(time.Hour))).To(BeFalse())
+		Expect(provisioningPhaseExpired(machineContext, infrav1.VMStartPhase, now.Add(time.Hour+5*time.Minute))).To(BeFalse())
+	})
+
+	It("should not expire phases without a timeout", func() {
+		Expect(provisioningPhaseExpired(machineContext, infrav1.BootstrapPhase, now)).To(BeFalse())
+		Expect(provisioningPhaseExpired(machineContext, infrav1.BootstrapPhase, now.Add(24*time.Hour))).To(BeFalse())
+	})
+
+	It("should not track machines whose Node registered", func() {
+		machineContext.Machine.Status.NodeRef = &corev1.ObjectReference{Name: "test-kubevirt-machine"}
+		Expect(provisioningPhaseExpired(machineContext, infrav1.VMStartPhase, now)).To(BeFalse())
+		Expect(machineContext.KubevirtMachine.Status.ProvisioningPhase).To(BeEmpty())
+	})
+
+	It("should recreate the VM, then fail the machine", func() {
+		Expect(provisioningPhaseExpired(machineContext, infrav1.VMStartPhase, now)).To(BeFalse())
+		machineMock.EXPECT().Delete().Return(nil).Times(1)
+
+		out, err := reconciler.reconcileProvisioningTimeout(machineContext, machineMock)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.RequeueAfter).ToNot(BeZero())
+		Expect(machineContext.KubevirtMachine.Status.VMRecreations).To(Equal(int32(1)))
+		Expect(machineContext.KubevirtMachine.Status.ProvisioningPhase).To(BeEmpty())
+		Expect(machineContext.KubevirtMachine.Status.FailureReason).To(BeNil())
+		Expect(conditions.GetReason(machineContext.KubevirtMachine, infrav1.VMProvisionedCondition)).To(Equal(infrav1.ProvisioningTimedOutReason))
+		Expect(conditions.GetSeverity(machineContext.KubevirtMachine, infrav1.VMProvisionedCondition)).To(HaveValue(Equal(clusterv1.ConditionSeverityWarning)))
+
+		Expect(provisioningPhaseExpired(machineContext, infrav1.VMStartPhase, now)).To(BeFalse())
+		_, err = reconciler.reconcileProvisioningTimeout(machineContext, machineMock)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(machineContext.KubevirtMachine.Status.FailureReason).To(HaveValue(Equal(capierrors.CreateMachineError)))
+		Expect(*machineContext.KubevirtMachine.Status.FailureMessage).To(Equal("VMStart phase did not complete within 10m0s"))
+		Expect(conditions.GetSeverity(machineContext.KubevirtMachine, infrav1.VMProvisionedCondition)).To(HaveValue(Equal(clusterv1.ConditionSeverityError)))
+	})
+
+	It("should fail the machine when its Node does not register in time", func() {
+		machineContext.KubevirtMachine.Spec.ProvisioningTimeouts.NodeRegistration = &metav1.Duration{Duration: 5 * time.Minute}
+		Expect(provisioningPhaseExpired(machineContext, infrav1.NodeRegistrationPhase, now)).To(BeFalse())
+
+		_, err := reconciler.reconcileProvisioningTimeout(machineContext, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(machineContext.KubevirtMachine.Status.VMRecreations).To(BeZero())
+		Expect(machineContext.KubevirtMachine.Status.FailureReason).To(HaveValue(Equal(capierrors.CreateMachineError)))
+	})
+})
+
+var _ = Describe("bootstrap token refresh", func() {
+	var (
+		machineContext *context.MachineContext
+		reconciler     KubevirtMachineReconciler
+		recorder       *record.FakeRecorder
+		config         *unstructured.Unstructured
+	)
+
+	BeforeEach(func() {
+		kubevirtMachine := testing.NewKubevirtMachine("test-kubevirt-machine", "test-machine")
+		machine := testing.NewMachine("test-cluster", "test-machine", kubevirtMachine)
+		machine.Namespace = "default"
+		machine.CreationTimestamp = metav1.NewTime(time.Now().Add(-10 * time.Minute))
+		machine.Spec.Bootstrap.ConfigRef = &corev1.ObjectReference{
+			APIVersion: "bootstrap.cluster.x-k8s.io/v1beta1",
+			Kind:       "KubeadmConfig",
+			Name:       "test-machine-config",
+		}
+		machineContext = &context.MachineContext{
+			Context:         gocontext.Background(),
+			Machine:         machine,
+			KubevirtMachine: kubevirtMachine,
+			Logger:          ctrl.Log.WithName("test"),
+		}
+
+		config = &unstructured.Unstructured{}
+		config.SetAPIVersion("bootstrap.cluster.x-k8s.io/v1beta1")
+		config.SetKind("KubeadmConfig")
+		config.SetNamespace("default")
+		config.SetName("test-machine-config")
+	})
+
+	setupReconciler := func(ttl time.Duration) {
+		recorder = record.NewFakeRecorder(10)
+		reconciler = KubevirtMachineReconciler{
+			Client:            fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(config).Build(),
+			Recorder:          recorder,
+			BootstrapTokenTTL: ttl,
+		}
+	}
+
+	getAnnotations := func() map[string]string {
+		updated := config.DeepCopy()
+		Expect(reconciler.Client.Get(gocontext.Background(), client.ObjectKeyFromObject(config), updated)).To(Succeed())
+		return updated.GetAnnotations()
+	}
+
+	It("should request a fresh join token for the machines pending for half the TTL", func() {
+		setupReconciler(15 * time.Minute)
+
+		Expect(reconciler.reconcileBootstrapTokenRefresh(machineContext)).To(Succeed())
+		requested := getAnnotations()[infrav1.BootstrapTokenRefreshRequestedAnnotation]
+		Expect(time.Parse(time.RFC3339, requested)).To(BeTemporally("~", time.Now(), time.Minute))
+		Expect(recorder.Events).To(Receive(ContainSubstring(bootstrapTokenRefreshRequestedReason)))
+		Expect(machineContext.KubevirtMachine.Status.BootstrapTokenExpiryTime.Time).To(BeTemporally("~", time.Now().Add(5*time.Minute), time.Second))
+
+		// the request is not repeated until the bootstrap provider answers it
+		Expect(reconciler.reconcileBootstrapTokenRefresh(machineContext)).To(Succeed())
+		Expect(recorder.Events).ToNot(Receive())
+	})
+
+	It("should wait for half the TTL after the last refresh", func() {
+		refreshed := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+		config.SetAnnotations(map[string]string{
+			infrav1.BootstrapTokenRefreshRequestedAnnotation: refreshed,
+			infrav1.BootstrapTokenRefreshedAnnotation:        refreshed,
+		})
+		setupReconciler(15 * time.Minute)
+
+		Expect(reconciler.reconcileBootstrapTokenRefresh(machineContext)).To(Succeed())
+		Expect(getAnnotations()).To(HaveKeyWithValue(infrav1.BootstrapTokenRefreshRequestedAnnotation, refreshed))
+		Expect(recorder.Events).ToNot(Receive())
+	})
+
+	Context("holding the VMs whose join token is about to expire", func() {
+		var (
+			infraClient client.Client
+			vm          *kubevirtv1.VirtualMachine
+		)
+
+		BeforeEach(func() {
+			refreshed := time.Now().Add(-12 * time.Minute).UTC().Format(time.RFC3339)
+			config.SetAnnotations(map[string]string{
+				infrav1.BootstrapTokenRefreshRequestedAnnotation: refreshed,
+				infrav1.BootstrapTokenRefreshedAnnotation:        refreshed,
+			})
+			vm = &kubevirtv1.VirtualMachine{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-kubevirt-machine"},
+				Spec:       kubevirtv1.VirtualMachineSpec{RunStrategy: ptr.To(kubevirtv1.RunStrategyAlways)},
+			}
+			infraClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(vm).Build()
+			setupReconciler(15 * time.Minute)
+			reconciler.BootstrapTokenMinValidity = 5 * time.Minute
+		})
+
+		getVM := func() *kubevirtv1.VirtualMachine {
+			updated := &kubevirtv1.VirtualMachine{}
+			Expect(infraClient.Get(gocontext.Background(), client.ObjectKeyFromObject(vm), updated)).To(Succeed())
+			return updated
+		}
+
+		It("should hold the VM until the join token is refreshed", func() {
+			Expect(reconciler.reconcileBootstrapTokenRefresh(machineContext)).To(Succeed())
+			_, held, err := reconciler.reconcileBootstrapTokenHold(machineContext, infraClient, "default")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(held).To(BeTrue())
+			Expect(getVM().Spec.RunStrategy).To(HaveValue(Equal(kubevirtv1.RunStrategyHalted)))
+			Expect(getVM().Annotations).To(HaveKeyWithValue(infrav1.BootstrapTokenHoldRunStrategyAnnotation, string(kubevirtv1.RunStrategyAlways)))
+			Expect(conditions.GetReason(machineContext.KubevirtMachine, infrav1.VMProvisionedCondition)).To(Equal(infrav1.WaitingForBootstrapTokenReason))
+
+			// the bootstrap provider answers the request
+			updated := config.DeepCopy()
+			Expect(reconciler.Client.Get(gocontext.Background(), client.ObjectKeyFromObject(config), updated)).To(Succeed())
+			annotations := updated.GetAnnotations()
+			annotations[infrav1.BootstrapTokenRefreshedAnnotation] = annotations[infrav1.BootstrapTokenRefreshRequestedAnnotation]
+			updated.SetAnnotations(annotations)
+			Expect(reconciler.Client.Update(gocontext.Background(), updated)).To(Succeed())
+
+			Expect(reconciler.reconcileBootstrapTokenRefresh(machineContext)).To(Succeed())
+			_, held, err = reconciler.reconcileBootstrapTokenHold(machineContext, infraClient, "default")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(held).To(BeFalse())
+			Expect(getVM().Spec.RunStrategy).To(HaveValue(Equal(kubevirtv1.RunStrategyAlways)))
+			Expect(getVM().Annotations).ToNot(HaveKey(infrav1.BootstrapTokenHoldRunStrategyAnnotation))
+
+			var events []string
+			for len(recorder.Events) > 0 {
+				events = append(events, <-recorder.Events)
+			}
+			Expect(events).To(ContainElement(ContainSubstring(bootstrapTokenHoldReleasedReason)))
+		})
+
+		It("should start the held VM when the bootstrap provider does not answer the request in time", func() {
+			Expect(reconciler.reconcileBootstrapTokenRefresh(machineContext)).To(Succeed())
+			_, held, err := reconciler.reconcileBootstrapTokenHold(machineContext, infraClient, "default")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(held).To(BeTrue())
+
+			// the request is left unanswered past the timeout
+			updated := config.DeepCopy()
+			Expect(reconciler.Client.Get(gocontext.Background(), client.ObjectKeyFromObject(config), updated)).To(Succeed())
+			annotations := updated.GetAnnotations()
+			annotations[infrav1.BootstrapTokenRefreshRequestedAnnotation] = time.Now().Add(-bootstrapTokenRefreshTimeout - time.Minute).UTC().Format(time.RFC3339)
+			updated.SetAnnotations(annotations)
+			Expect(reconciler.Client.Update(gocontext.Background(), updated)).To(Succeed())
+
+			Expect(reconciler.reconcileBootstrapTokenRefresh(machineContext)).To(Succeed())
+			_, held, err = reconciler.reconcileBootstrapTokenHold(machineContext, infraClient, "default")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(held).To(BeFalse())
+			Expect(getVM().Spec.RunStrategy).To(HaveValue(Equal(kubevirtv1.RunStrategyAlways)))
+			Expect(getVM().Annotations).ToNot(HaveKey(infrav1.BootstrapTokenHoldRunStrategyAnnotation))
+
+			var events []string
+			for len(recorder.Events) > 0 {
+				events = append(events, <-recorder.Events)
+			}
+			Expect(events).To(ContainElement(And(ContainSubstring(corev1.EventTypeWarning), ContainSubstring(bootstrapTokenHoldReleasedReason))))
+		})
+
+		It("should not hold the VM whose guest already booted", func() {
+			vmi := &kubevirtv1.VirtualMachineInstance{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-kubevirt-machine"},
+				Status:     kubevirtv1.VirtualMachineInstanceStatus{Phase: kubevirtv1.Running},
+			}
+			Expect(infraClient.Create(gocontext.Background(), vmi)).To(Succeed())
+
+			Expect(reconciler.reconcileBootstrapTokenRefresh(machineContext)).To(Succeed())
+			_, held, err := reconciler.reconcileBootstrapTokenHold(machineContext, infraClient, "default")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(held).To(BeFalse())
+			Expect(getVM().Spec.RunStrategy).To(HaveValue(Equal(kubevirtv1.RunStrategyAlways)))
+		})
+
+		It("should not hold the VM when the bootstrap provider never answered a refresh", func() {
+			config.SetAnnotations(nil)
+			setupReconciler(15 * time.Minute)
+			reconciler.BootstrapTokenMinValidity = 5 * time.Minute
+			machineContext.Machine.CreationTimestamp = metav1.NewTime(time.Now().Add(-12 * time.Minute))
+
+			Expect(reconciler.reconcileBootstrapTokenRefresh(machineContext)).To(Succeed())
+			_, held, err := reconciler.reconcileBootstrapTokenHold(machineContext, infraClient, "default")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(held).To(BeFalse())
+			Expect(getVM().Spec.RunStrategy).To(HaveValue(Equal(kubevirtv1.RunStrategyAlways)))
+		})
+	})
+
+	It("should not request a refresh from the bootstrap configs other than KubeadmConfigs", func() {
+		machineContext.Machine.Spec.Bootstrap.ConfigRef.Kind = "TalosConfig"
+		config.SetKind("TalosConfig")
+		setupReconciler(15 * time.Minute)
+
+		Expect(reconciler.reconcileBootstrapTokenRefresh(machineContext)).To(Succeed())
+		Expect(getAnnotations()).ToNot(HaveKey(infrav1.BootstrapTokenRefreshRequestedAnnotation))
+		Expect(machineContext.KubevirtMachine.Status.BootstrapTokenExpiryTime).To(BeNil())
+	})
+
+	It("should not request a refresh for the machines pending for a short time, or when disabled", func() {
+		setupReconciler(time.Hour)
+		Expect(reconciler.reconcileBootstrapTokenRefresh(machineContext)).To(Succeed())
+		Expect(getAnnotations()).ToNot(HaveKey(infrav1.BootstrapTokenRefreshRequestedAnnotation))
+
+		setupReconciler(0)
+		Expect(reconciler.reconcileBootstrapTokenRefresh(machineContext)).To(Succeed())
+		Expect(getAnnotations()).ToNot(HaveKey(infrav1.BootstrapTokenRefreshRequestedAnnotation))
+	})
+})
+
+var _ = Describe("machine identity", func() {
+	var machineContext *context.MachineContext
+
+	BeforeEach(func() {
+		kubevirtCluster = testing.NewKubevirtCluster("test-kubevirt-cluster", "test-kubevirt-cluster")
+
+		caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		caCert, err := certutil.NewSelfSignedCACert(certutil.Config{CommonName: "machines-ca"}, caKey)
+		Expect(err).ToNot(HaveOccurred())
+		caKeyDER, err := x509.MarshalECPrivateKey(caKey)
+		Expect(err).ToNot(HaveOccurred())
+		caSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: kubevirtCluster.Namespace, Name: "machines-ca"},
+			Data: map[string][]byte{
+				corev1.TLSCertKey:       pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw}),
+				corev1.TLSPrivateKeyKey: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: caKeyDER}),
+			},
+		}
+
+		kubevirtCluster.Spec.MachineIdentity = &infrav1.MachineIdentitySpec{CASecretName: caSecret.Name}
+		cluster = testing.NewCluster("test-kubevirt-cluster", kubevirtCluster)
+		kubevirtMachine = testing.NewKubevirtMachine("test-kubevirt-machine", "test-machine")
+
+		fakeClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(caSecret, kubevirtMachine).Build()
+		kubevirtMachineReconciler = KubevirtMachineReconciler{Client: fakeClient}
+		machineContext = &context.MachineContext{
+			Context:         gocontext.Background(),
+			Cluster:         cluster,
+			KubevirtCluster: kubevirtCluster,
+			KubevirtMachine: kubevirtMachine,
+			Logger:          ctrl.Log.WithName("test"),
+		}
+	})
+
+	It("should issue the certificate of the machine once", func() {
+		secret, err := kubevirtMachineReconciler.reconcileMachineIdentity(machineContext)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(secret.Data).To(HaveKey(corev1.TLSCertKey))
+		Expect(secret.Data).To(HaveKey(corev1.TLSPrivateKeyKey))
+		Expect(secret.Data).To(HaveKey(corev1.ServiceAccountRootCAKey))
+		Expect(secret.OwnerReferences).To(HaveLen(1))
+		Expect(conditions.IsTrue(kubevirtMachine, infrav1.MachineIdentityCertificateCondition)).To(BeTrue())
+
+		again, err := kubevirtMachineReconciler.reconcileMachineIdentity(machineContext)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(again.Data[corev1.TLSCertKey]).To(Equal(secret.Data[corev1.TLSCertKey]))
+	})
+
+	It("should renew an expiring certificate of a machine not provisioned yet", func() {
+		kubevirtCluster.Spec.MachineIdentity.Validity = &metav1.Duration{Duration: time.Hour}
+		secret, err := kubevirtMachineReconciler.reconcileMachineIdentity(machineContext)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(conditions.GetReason(kubevirtMachine, infrav1.MachineIdentityCertificateCondition)).To(Equal(infrav1.MachineIdentityExpiringReason))
+
+		renewed, err := kubevirtMachineReconciler.reconcileMachineIdentity(machineContext)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(renewed.Data[corev1.TLSCertKey]).ToNot(Equal(secret.Data[corev1.TLSCertKey]))
+	})
+
+	It("should report the expiring certificate of a provisioned machine", func() {
+		kubevirtCluster.Spec.MachineIdentity.Validity = &metav1.Duration{Duration: time.Hour}
+		secret, err := kubevirtMachineReconciler.reconcileMachineIdentity(machineContext)
+		Expect(err).ToNot(HaveOccurred())
+
+		providerID := "kubevirt://test-kubevirt-machine"
+		kubevirtMachine.Spec.ProviderID = &providerID
+		again, err := kubevirtMachineReconciler.reconcileMachineIdentity(machineContext)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(again.Data[corev1.TLSCertKey]).To(Equal(secret.Data[corev1.TLSCertKey]))
+		Expect(conditions.GetReason(kubevirtMachine, infrav1.MachineIdentityCertificateCondition)).To(Equal(infrav1.MachineIdentityExpiringReason))
+		Expect(conditions.GetMessage(kubevirtMachine, infrav1.MachineIdentityCertificateCondition)).To(ContainSubstring("replace the machine"))
+	})
+
+	It("should report a missing CA secret", func() {
+		kubevirtCluster.Spec.MachineIdentity.CASecretName = "missing"
+		_, err := kubevirtMachineReconciler.reconcileMachineIdentity(machineContext)
+		Expect(err).To(HaveOccurred())
+		Expect(conditions.GetReason(kubevirtMachine, infrav1.MachineIdentityCertificateCondition)).To(Equal(infrav1.MachineIdentityIssueFailedReason))
+	})
+
+	It("should not issue a certificate when the cluster does not enable machine identities", func() {
+		kubevirtCluster.Spec.MachineIdentity = nil
+		secret, err := kubevirtMachineReconciler.reconcileMachineIdentity(machineContext)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(secret).To(BeNil())
+		Expect(conditions.Has(kubevirtMachine, infrav1.MachineIdentityCertificateCondition)).To(BeFalse())
+	})
+
+	It("should add the certificate to the cloud-init config", func() {
+		identity := map[string][]byte{
+			corev1.TLSCertKey:              []byte("cert\n"),
+			corev1.TLSPrivateKeyKey:        []byte("key\n"),
+			corev1.ServiceAccountRootCAKey: []byte("ca\n"),
+		}
+		actual, modified, err := addMachineIdentityToCloudInitConfig([]byte("#cloud-config\nhostname: test\n"), identity)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(modified).To(BeTrue())
+		Expect(string(actual)).To(Equal(`#cloud-config
+hostname: test
+write_files:
+    - path: /etc/capk/identity/tls.crt
+      owner: root:root
+      permissions: "0644"
+      content: |
+        cert
+    - path: /etc/capk/identity/tls.key
+      owner: root:root
+      permissions: "0600"
+      content: |
+        key
+    - path: /etc/capk/identity/ca.crt
+      owner: root:root
+      permissions: "0644"
+      content: |
+        ca
+`))
+
+		ignition := []byte(`{"ignition":{"version":"3.3.0"}}`)
+		actual, modified, err = addMachineIdentityToCloudInitConfig(ignition, identity)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(modified).To(BeFalse())
+		Expect(actual).To(Equal(ignition))
+	})
+})
+
+var _ = Describe("infra ownership", func() {
+	BeforeEach(func() {
+		kubevirtCluster = testing.NewKubevirtCluster("test-cluster", "test-kubevirt-cluster")
+		cluster = testing.NewCluster("test-cluster", kubevirtCluster)
+		kubevirtMachine = testing.NewKubevirtMachine("test-kubevirt-machine", "test-machine")
+		machine = testing.NewMachine("test-cluster", "test-machine", kubevirtMachine)
+	})
+
+	isInfraOwnedElsewhere := func() bool {
+		fakeClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(cluster, kubevirtCluster).Build()
+		kubevirtMachineReconciler = KubevirtMachineReconciler{Client: fakeClient}
+		return kubevirtMachineReconciler.isMachineInfraOwnedElsewhere(gocontext.Background(), machine)
+	}
+
+	It("should report the infra resources owned by another management cluster", func() {
+		conditions.MarkFalse(kubevirtCluster, infrav1.InfraOwnershipCondition, infrav1.InfraOwnedElsewhereReason, clusterv1.ConditionSeverityWarning, "")
+		Expect(isInfraOwnedElsewhere()).To(BeTrue())
+	})
+
+	It("should not report the infra resources owned by this management cluster", func() {
+		conditions.MarkTrue(kubevirtCluster, infrav1.InfraOwnershipCondition)
+		Expect(isInfraOwnedElsewhere()).To(BeFalse())
+	})
+
+	It("should not report the infra resources of clusters without lease", func() {
+		Expect(isInfraOwnedElsewhere()).To(BeFalse())
+	})
+})
+
+var _ = Describe("disk retention policy", func() {
+	var (
+		dataVolume     *cdiv1.DataVolume
+		pvc            *corev1.PersistentVolumeClaim
+		machineContext *context.MachineContext
+	)
+
+	BeforeEach(func() {
+		kubevirtCluster = testing.NewKubevirtCluster("test-cluster", "test-kubevirt-cluster")
+		cluster = testing.NewCluster("test-cluster", kubevirtCluster)
+		kubevirtMachine = testing.NewKubevirtMachine("test-kubevirt-machine", "test-machine")
+		machine = testing.NewMachine("test-cluster", "test-machine", kubevirtMachine)
+
+		vm = &kubevirtv1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: kubevirtMachine.Name, UID: "vm-uid"},
+			Spec: kubevirtv1.VirtualMachineSpec{
+				DataVolumeTemplates: []kubevirtv1.DataVolumeTemplateSpec{{ObjectMeta: metav1.ObjectMeta{Name: "root"}}},
+				Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+					Spec: kubevirtv1.VirtualMachineInstanceSpec{
+						Volumes: []kubevirtv1.Volume{{
+							Name: "data",
+							VolumeSource: kubevirtv1.VolumeSource{
+								PersistentVolumeClaim: &kubevirtv1.PersistentVolumeClaimVolumeSource{
+									PersistentVolumeClaimVolumeSource: corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"},
+								},
+							},
+						}},
+					},
+				},
+			},
+		}
+		dataVolume = &cdiv1.DataVolume{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "root",
+				OwnerReferences: []metav1.OwnerReference{{APIVersion: "kubevirt.io/v1", Kind: "VirtualMachine", Name: vm.Name, UID: vm.UID}},
+			},
+		}
+		pvc = &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data"}}
+	})
+
+	applyDiskRetentionPolicy := func(policy infrav1.DiskRetentionPolicy, objects ...client.Object) (bool, error) {
+		kubevirtCluster.Spec.DiskRetentionPolicy = policy
+		fakeClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(append(objects, cluster, kubevirtCluster, vm, dataVolume, pvc)...).Build()
+		kubevirtMachineReconciler = KubevirtMachineReconciler{Client: fakeClient}
+		machineContext = &context.MachineContext{
+			Context:         gocontext.Background(),
+			Machine:         machine,
+			KubevirtMachine: kubevirtMachine,
+			Logger:          ctrl.Log.WithName("test"),
+		}
+		return kubevirtMachineReconciler.applyDiskRetentionPolicy(machineContext, fakeClient, "")
+	}
+
+	expectOrphaned := func(name string, orphaned bool) {
+		orphanedPVC := &corev1.PersistentVolumeClaim{}
+		Expect(fakeClient.Get(gocontext.Background(), client.ObjectKey{Name: name}, orphanedPVC)).To(Succeed())
+		if orphaned {
+			Expect(orphanedPVC.Labels).To(HaveKeyWithValue(infrav1.OrphanedFromMachineLabel, kubevirtMachine.Name))
+			Expect(orphanedPVC.Labels).To(HaveKeyWithValue(clusterv1.ClusterNameLabel, "test-cluster"))
+		} else {
+			Expect(orphanedPVC.Labels).ToNot(HaveKey(infrav1.OrphanedFromMachineLabel))
+		}
+	}
+
+	It("should let the DataVolumes be deleted with the VM and report the PVCs it does not own", func() {
+		deletable, err := applyDiskRetentionPolicy(infrav1.DeleteDiskRetentionPolicy, &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "root"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deletable).To(BeTrue())
+
+		Expect(fakeClient.Get(gocontext.Background(), client.ObjectKeyFromObject(dataVolume), dataVolume)).To(Succeed())
+		Expect(dataVolume.OwnerReferences).To(HaveLen(1))
+		expectOrphaned("data", true)
+		expectOrphaned("root", false)
+	})
+
+	It("should detach the DataVolumes from the VM when retaining the disks", func() {
+		deletable, err := applyDiskRetentionPolicy(infrav1.RetainDiskRetentionPolicy, &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "root"}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deletable).To(BeTrue())
+
+		Expect(fakeClient.Get(gocontext.Background(), client.ObjectKeyFromObject(dataVolume), dataVolume)).To(Succeed())
+		Expect(dataVolume.OwnerReferences).To(BeEmpty())
+		expectOrphaned("data", true)
+		expectOrphaned("root", true)
+	})
+
+	It("should wait for the snapshot of the VM before deleting it", func() {
+		deletable, err := applyDiskRetentionPolicy(infrav1.SnapshotThenDeleteDiskRetentionPolicy)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deletable).To(BeFalse())
+
+		snapshot := &snapshotv1.VirtualMachineSnapshot{}
+		Expect(fakeClient.Get(gocontext.Background(), client.ObjectKey{Name: vm.Name + "-final"}, snapshot)).To(Succeed())
+		Expect(snapshot.Spec.Source.Kind).To(Equal("VirtualMachine"))
+		Expect(snapshot.Spec.Source.Name).To(Equal(vm.Name))
+		expectOrphaned("data", false)
+	})
+
+	It("should let the VM be deleted once its snapshot is ready", func() {
+		readyToUse := true
+		snapshot := &snapshotv1.VirtualMachineSnapshot{
+			ObjectMeta: metav1.ObjectMeta{Name: vm.Name + "-final"},
+			Status:     &snapshotv1.VirtualMachineSnapshotStatus{Phase: snapshotv1.Succeeded, ReadyToUse: &readyToUse},
+		}
+		deletable, err := applyDiskRetentionPolicy(infrav1.SnapshotThenDeleteDiskRetentionPolicy, snapshot)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deletable).To(BeTrue())
+		expectOrphaned("data", true)
+	})
+
+	It("should fail when the snapshot of the VM failed", func() {
+		message := "no volume snapshot class"
+		snapshot := &snapshotv1.VirtualMachineSnapshot{
+			ObjectMeta: metav1.ObjectMeta{Name: vm.Name + "-final"},
+			Status:     &snapshotv1.VirtualMachineSnapshotStatus{Phase: snapshotv1.Failed, Error: &snapshotv1.Error{Message: &message}},
+		}
+		_, err := applyDiskRetentionPolicy(infrav1.SnapshotThenDeleteDiskRetentionPolicy, snapshot)
+		Expect(err).To(MatchError(ContainSubstring(message)))
+	})
+})
+
+var _ = Describe("preemptible machines", func() {
+	var (
+		mockCtrl            *gomock.Controller
+		workloadClusterMock *workloadclustermock.MockWorkloadCluster
+		kubeClient          *k8sfake.Clientset
+		machineContext      *context.MachineContext
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		workloadClusterMock = workloadclustermock.NewMockWorkloadCluster(mockCtrl)
+
+		kubevirtCluster = testing.NewKubevirtCluster("test-cluster", "test-kubevirt-cluster")
+		cluster = testing.NewCluster("test-cluster", kubevirtCluster)
+		kubevirtMachine = testing.NewKubevirtMachine("test-kubevirt-machine", "test-machine")
+		kubevirtMachine.Spec.Preemptible = &infrav1.PreemptibleSpec{}
+		machine = testing.NewMachine("test-cluster", "test-machine", kubevirtMachine)
+
+		vmi = testing.NewVirtualMachineInstance(kubevirtMachine)
+		vm = testing.NewVirtualMachine(vmi)
+		vm.Spec.RunStrategy = ptr.To(kubevirtv1.RunStrategyAlways)
+		kubeClient = k8sfake.NewSimpleClientset(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: kubevirtMachine.Name}})
+	})
+
+	reconcileReclaim := func(objects ...client.Object) (ctrl.Result, bool) {
+		fakeClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(objects...).Build()
+		kubevirtMachineReconciler = KubevirtMachineReconciler{Client: fakeClient, WorkloadCluster: workloadClusterMock}
+		machineContext = &context.MachineContext{
+			Context:         gocontext.Background(),
+			Cluster:         cluster,
+			KubevirtCluster: kubevirtCluster,
+			Machine:         machine,
+			KubevirtMachine: kubevirtMachine,
+			Logger:          ctrl.Log.WithName("test"),
+		}
+		workloadClusterMock.EXPECT().GenerateWorkloadClusterK8sClient(gomock.Any()).Return(kubeClient, nil).AnyTimes()
+
+		res, reclaimed, err := kubevirtMachineReconciler.reconcileReclaim(machineContext, fakeClient, vm.Namespace)
+		Expect(err).ToNot(HaveOccurred())
+		return res, reclaimed
+	}
+
+	getNode := func() *corev1.Node {
+		node, err := kubeClient.CoreV1().Nodes().Get(gocontext.Background(), kubevirtMachine.Name, metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		return node
+	}
+
+	It("should leave the machines not requested to be reclaimed alone", func() {
+		_, reclaimed := reconcileReclaim(vm, vmi)
+		Expect(reclaimed).To(BeFalse())
+		Expect(getNode().Spec.Taints).To(BeEmpty())
+	})
+
+	It("should drain the Node and stop the VM of a machine requested to be reclaimed", func() {
+		vm.Annotations = map[string]string{infrav1.ReclaimAnnotation: "true"}
+
+		res, reclaimed := reconcileReclaim(vm, vmi)
+		Expect(reclaimed).To(BeTrue())
+		Expect(res.RequeueAfter).To(Equal(10 * time.Second))
+		Expect(conditions.GetReason(kubevirtMachine, infrav1.VMProvisionedCondition)).To(Equal(infrav1.ReclaimingReason))
+
+		node := getNode()
+		Expect(node.Spec.Unschedulable).To(BeTrue())
+		Expect(node.Spec.Taints).To(ContainElement(HaveField("Key", infrav1.ReclaimedTaint)))
+
+		stoppedVM := &kubevirtv1.VirtualMachine{}
+		Expect(fakeClient.Get(gocontext.Background(), client.ObjectKeyFromObject(vm), stoppedVM)).To(Succeed())
+		Expect(stoppedVM.Spec.RunStrategy).To(HaveValue(Equal(kubevirtv1.RunStrategyHalted)))
+
+		stoppedVM.Status.PrintableStatus = kubevirtv1.VirtualMachineStatusStopped
+		vm = stoppedVM
+		res, reclaimed = reconcileReclaim(vm)
+		Expect(reclaimed).To(BeTrue())
+		Expect(res.RequeueAfter).To(Equal(reclaimCheckInterval))
+		Expect(conditions.GetReason(kubevirtMachine, infrav1.VMProvisionedCondition)).To(Equal(infrav1.ReclaimedReason))
+		Expect(kubevirtMachine.Status.Ready).To(BeFalse())
+	})
+
+	It("should reclaim a machine whose VMI is evicted by the infra cluster", func() {
+		vmi.Status.EvacuationNodeName = "infra-node"
+
+		_, reclaimed := reconcileReclaim(vm, vmi)
+		Expect(reclaimed).To(BeTrue())
+
+		updatedVM := &kubevirtv1.VirtualMachine{}
+		Expect(fakeClient.Get(gocontext.Background(), client.ObjectKeyFromObject(vm), updatedVM)).To(Succeed())
+		Expect(updatedVM.Annotations).To(HaveKeyWithValue(infrav1.ReclaimAnnotation, "true"))
+	})
+
+	It("should start the VM and untaint the Node once the reclaim is withdrawn", func() {
+		conditions.MarkFalse(kubevirtMachine, infrav1.VMProvisionedCondition, infrav1.ReclaimedReason, clusterv1.ConditionSeverityInfo, "")
+		vm.Annotations = map[string]string{infrav1.HibernatedRunStrategyAnnotation: string(kubevirtv1.RunStrategyAlways)}
+		vm.Spec.RunStrategy = ptr.To(kubevirtv1.RunStrategyHalted)
+		node := getNode()
+		node.Spec.Unschedulable = true
+		node.Spec.Taints = []corev1.Taint{{Key: infrav1.ReclaimedTaint, Value: "true", Effect: corev1.TaintEffectNoSchedule}}
+		_, err := kubeClient.CoreV1().Nodes().Update(gocontext.Background(), node, metav1.UpdateOptions{})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, reclaimed := reconcileReclaim(vm)
+		Expect(reclaimed).To(BeFalse())
+
+		startedVM := &kubevirtv1.VirtualMachine{}
+		Expect(fakeClient.Get(gocontext.Background(), client.ObjectKeyFromObject(vm), startedVM)).To(Succeed())
+		Expect(startedVM.Spec.RunStrategy).To(HaveValue(Equal(kubevirtv1.RunStrategyAlways)))
+		Expect(getNode().Spec.Unschedulable).To(BeFalse())
+		Expect(getNode().Spec.Taints).To(BeEmpty())
+	})
+})
+
+var _ = Describe("KubevirtMachine phase transitions", func() {
+	type phase struct {
+		result          ctrl.Result
+		ready           bool
+		vmCreated       bool
+		vmProvisioned   corev1.ConditionStatus
+		vmProvisionedBy string
+		nodeUpdated     bool
+	}
+
+	DescribeTable("should move the machine to its next phase", func(setup func(*testing.MachineFixture), expected phase) {
+		fixture := testing.NewMachineFixture("default", "kvcluster", "test-machine")
+		setup(fixture)
+
+		managementClient := fixture.NewManagementClient()
+		infraCluster := fixture.NewInfraCluster()
+		workloadCluster := fixture.NewWorkloadCluster()
+		reconciler := KubevirtMachineReconciler{
+			Client:          managementClient,
+			InfraCluster:    infraCluster,
+			WorkloadCluster: workloadCluster,
+			MachineFactory:  kubevirt.DefaultMachineFactory{},
+		}
+
+		key := client.ObjectKeyFromObject(fixture.KubevirtMachine)
+		result, err := reconciler.Reconcile(gocontext.Background(), ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(expected.result))
+
+		reconciled := &infrav1.KubevirtMachine{}
+		Expect(managementClient.Get(gocontext.Background(), key, reconciled)).To(Succeed())
+		Expect(reconciled.Status.Ready).To(Equal(expected.ready))
+		var vmProvisioned corev1.ConditionStatus
+		if condition := conditions.Get(reconciled, infrav1.VMProvisionedCondition); condition != nil {
+			vmProvisioned = condition.Status
+		}
+		Expect(vmProvisioned).To(Equal(expected.vmProvisioned))
+		Expect(conditions.GetReason(reconciled, infrav1.VMProvisionedCondition)).To(Equal(expected.vmProvisionedBy))
+
+		vmErr := infraCluster.Client.Get(gocontext.Background(), key, &kubevirtv1.VirtualMachine{})
+		if expected.vmCreated {
+			Expect(vmErr).ToNot(HaveOccurred())
+		} else {
+			Expect(apierrors.IsNotFound(vmErr)).To(BeTrue())
+		}
+
+		if expected.nodeUpdated {
+			node := &corev1.Node{}
+			Expect(workloadCluster.Client.Get(gocontext.Background(), client.ObjectKey{Name: key.Name}, node)).To(Succeed())
+			Expect(node.Spec.ProviderID).To(Equal("kubevirt://" + key.Name))
+		}
+	},
+		Entry("waiting for the cluster infrastructure", func(f *testing.MachineFixture) {
+			f.Cluster.Status.InfrastructureReady = false
+		}, phase{
+			vmProvisioned:   corev1.ConditionFalse,
+			vmProvisionedBy: infrav1.WaitingForClusterInfrastructureReason,
+		}),
+		Entry("creating the VM", func(_ *testing.MachineFixture) {}, phase{
+			result:    ctrl.Result{RequeueAfter: 20 * time.Second},
+			vmCreated: true,
+		}),
+		Entry("waiting for the machine image", func(f *testing.MachineFixture) {
+			f.KubevirtMachine.Spec.Image = &infrav1.MachineImageReference{Name: "ubuntu"}
+		}, phase{
+			result:          ctrl.Result{RequeueAfter: 30 * time.Second},
+			vmProvisioned:   corev1.ConditionFalse,
+			vmProvisionedBy: infrav1.WaitingForMachineImageReason,
+		}),
+		Entry("waiting for infra nodes of the architecture of the machine", func(f *testing.MachineFixture) {
+			f.KubevirtMachine.Spec.Architecture = "arm64"
+			f.KubevirtCluster.Status.Infra = &infrav1.InfraStatus{NodeArchitectures: []string{"amd64"}}
+		}, phase{
+			result:          ctrl.Result{RequeueAfter: time.Minute},
+			vmProvisioned:   corev1.ConditionFalse,
+			vmProvisionedBy: infrav1.InfraFeatureUnavailableReason,
+		}),
+		Entry("waiting for infra nodes supporting nested virtualization", func(f *testing.MachineFixture) {
+			f.KubevirtMachine.Spec.RequiresNestedVirtualization = true
+			f.KubevirtCluster.Status.NestedVirtualization = &infrav1.NestedVirtualizationStatus{}
+		}, phase{
+			result:          ctrl.Result{RequeueAfter: time.Minute},
+			vmProvisioned:   corev1.ConditionFalse,
+			vmProvisionedBy: infrav1.InfraFeatureUnavailableReason,
+		}),
+		Entry("waiting for infra nodes providing the GPUs of the machine", func(f *testing.MachineFixture) {
+			f.KubevirtMachine.Spec.GPUs = []infrav1.MachineGPU{{Name: "vgpu", MediatedDeviceType: "GRID T4-1Q"}}
+			f.KubevirtCluster.Status.Infra = &infrav1.InfraStatus{
+				KubeVirtVersion: "v1.2.1",
+				HostDevices:     []infrav1.InfraHostDevice{{ResourceName: "nvidia.com/GRID_T4-1Q", MediatedDeviceType: "GRID T4-1Q", Nodes: ptr.To[int32](0)}},
+			}
+		}, phase{
+			result:          ctrl.Result{RequeueAfter: time.Minute},
+			vmProvisioned:   corev1.ConditionFalse,
+			vmProvisionedBy: infrav1.InfraFeatureUnavailableReason,
+		}),
+		Entry("waiting for the preflight checks of the control plane upgrade", func(f *testing.MachineFixture) {
+			f.Machine.Labels[clusterv1.MachineControlPlaneLabel] = ""
+			f.Machine.Spec.Version = ptr.To("v1.30.2")
+			f.KubevirtCluster.Status.UpgradePreflight = &infrav1.UpgradePreflightStatus{TargetVersion: "v1.30.2"}
+			conditions.MarkFalse(f.KubevirtCluster, infrav1.UpgradePreflightPassedCondition, infrav1.UpgradePreflightFailedReason,
+				clusterv1.ConditionSeverityWarning, "PodDisruptionBudgets: default/web allow no disruption")
+		}, phase{
+			result:          ctrl.Result{RequeueAfter: time.Minute},
+			vmProvisioned:   corev1.ConditionFalse,
+			vmProvisionedBy: infrav1.WaitingForUpgradePreflightReason,
+		}),
+		Entry("waiting for room in the quota of the cluster", func(f *testing.MachineFixture) {
+			f.KubevirtCluster.Spec.Quota = &infrav1.ClusterQuota{MaxMachines: ptr.To[int32](0)}
+		}, phase{
+			result:          ctrl.Result{RequeueAfter: time.Minute},
+			vmProvisioned:   corev1.ConditionFalse,
+			vmProvisionedBy: infrav1.QuotaExceededReason,
+		}),
+		Entry("waiting for the VM to start", func(f *testing.MachineFixture) {
+			f.WithVirtualMachine(nil)
+		}, phase{
+			result:          ctrl.Result{RequeueAfter: 20 * time.Second},
+			vmCreated:       true,
+			vmProvisioned:   corev1.ConditionFalse,
+			vmProvisionedBy: "VMNotReady",
+		}),
+		Entry("failing fast without the required guest agent", func(f *testing.MachineFixture) {
+			f.KubevirtMachine.Spec.GuestAgent = &infrav1.GuestAgentSpec{Mode: infrav1.GuestAgentRequired}
+			vmi := testing.NewVirtualMachineInstance(f.KubevirtMachine)
+			vmi.Status.Phase = kubevirtv1.Running
+			vmi.Status.PhaseTransitionTimestamps = []kubevirtv1.VirtualMachineInstancePhaseTransitionTimestamp{
+				{Phase: kubevirtv1.Running, PhaseTransitionTimestamp: metav1.NewTime(time.Now().Add(-10 * time.Minute))},
+			}
+			f.WithVirtualMachine(vmi)
+		}, phase{
+			result:          ctrl.Result{RequeueAfter: 20 * time.Second},
+			vmCreated:       true,
+			vmProvisioned:   corev1.ConditionFalse,
+			vmProvisionedBy: infrav1.AgentNotConnectedReason,
+		}),
+		Entry("waiting for the Node to register", func(f *testing.MachineFixture) {
+			f.WithVirtualMachine(testing.NewReadyVirtualMachineInstance(f.KubevirtMachine))
+		}, phase{
+			result:        ctrl.Result{RequeueAfter: 10 * time.Second},
+			ready:         true,
+			vmCreated:     true,
+			vmProvisioned: corev1.ConditionTrue,
+		}),
+		Entry("provisioned", func(f *testing.MachineFixture) {
+			f.WithVirtualMachine(testing.NewReadyVirtualMachineInstance(f.KubevirtMachine)).WithNode(testing.NewNode(f.KubevirtMachine))
+		}, phase{
+			ready:         true,
+			vmCreated:     true,
+			vmProvisioned: corev1.ConditionTrue,
+			nodeUpdated:   true,
+		}),
+	)
+
+	It("should report the machines whose bootstrap is not checked as ready", func() {
+		fixture := testing.NewMachineFixture("default", "kvcluster", "test-machine")
+		fixture.WithVirtualMachine(testing.NewReadyVirtualMachineInstance(fixture.KubevirtMachine)).WithNode(testing.NewNode(fixture.KubevirtMachine))
+
+		managementClient := fixture.NewManagementClient()
+		reconciler := KubevirtMachineReconciler{
+			Client:          managementClient,
+			InfraCluster:    fixture.NewInfraCluster(),
+			WorkloadCluster: fixture.NewWorkloadCluster(),
+			MachineFactory:  kubevirt.DefaultMachineFactory{},
+		}
+
+		key := client.ObjectKeyFromObject(fixture.KubevirtMachine)
+		_, err := reconciler.Reconcile(gocontext.Background(), ctrl.Request{NamespacedName: key})
+		Expect(err).ToNot(HaveOccurred())
+
+		reconciled := &infrav1.KubevirtMachine{}
+		Expect(managementClient.Get(gocontext.Background(), key, reconciled)).To(Succeed())
+		Expect(conditions.Has(reconciled, infrav1.BootstrapExecSucceededCondition)).To(BeFalse())
+		Expect(conditions.IsTrue(reconciled, clusterv1.ReadyCondition)).To(BeTrue())
+		Expect(meta.IsStatusConditionTrue(reconciled.GetV1Beta2Conditions(), infrav1.ReadyV1Beta2Condition)).To(BeTrue())
+	})
+})
+
+var _ = Describe("bootstrap failures", func() {
+	var (
+		mockCtrl         *gomock.Controller
+		machineMock      *machinemocks.MockMachineInterface
+		infraClusterMock *infraclustermock.MockInfraCluster
+		guestAgentMock   *guestagentmock.MockRunner
+		recorder         *record.FakeRecorder
+		machineContext   *context.MachineContext
+		reconciler       KubevirtMachineReconciler
+		config           *unstructured.Unstructured
+		restConfig       = &rest.Config{Host: "https://infra"}
+		now              = time.Date(2024, time.March, 4, 10, 0, 0, 0, time.UTC)
+	)
+
+	const tokenLog = "error execution phase preflight: couldn't validate the identity of the API Server: could not find a JWS signature in the cluster-info ConfigMap for token ID \"abcdef\"\n"
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		machineMock = machinemocks.NewMockMachineInterface(mockCtrl)
+		infraClusterMock = infraclustermock.NewMockInfraCluster(mockCtrl)
+		guestAgentMock = guestagentmock.NewMockRunner(mockCtrl)
+		recorder = record.NewFakeRecorder(10)
+
+		kubevirtMachine := testing.NewKubevirtMachine("test-kubevirt-machine", "test-machine")
+		kubevirtMachine.Spec.BootstrapFailures = &infrav1.BootstrapFailuresSpec{MaxRetries: 1}
+		kubevirtMachine.Status.ProvisioningPhase = infrav1.BootstrapPhase
+		kubevirtMachine.Status.ProvisioningPhaseStartTime = &metav1.Time{Time: now.Add(-5 * time.Minute)}
+		machine := testing.NewMachine("test-cluster", "test-machine", kubevirtMachine)
+		machine.Namespace = "default"
+		machine.Spec.Bootstrap.ConfigRef = &corev1.ObjectReference{
+			APIVersion: "bootstrap.cluster.x-k8s.io/v1beta1",
+			Kind:       "KubeadmConfig",
+			Name:       "test-machine-config",
+		}
+		machineContext = &context.MachineContext{
+			Context:         gocontext.Background(),
+			Machine:         machine,
+			KubevirtMachine: kubevirtMachine,
+			Logger:          ctrl.Log.WithName("test"),
+		}
+
+		config = &unstructured.Unstructured{}
+		config.SetAPIVersion("bootstrap.cluster.x-k8s.io/v1beta1")
+		config.SetKind("KubeadmConfig")
+		config.SetNamespace("default")
+		config.SetName("test-machine-config")
+
+		reconciler = KubevirtMachineReconciler{
+			Client:       fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(config).Build(),
+			InfraCluster: infraClusterMock,
+			GuestAgent:   guestAgentMock,
+			Recorder:     recorder,
+		}
+	})
+
+	expectBootstrapLog := func(log string) {
+		infraClusterMock.EXPECT().GenerateInfraClusterRestConfig(gomock.Any(), gomock.Any(), gomock.Any()).Return(restConfig, "infra-ns", nil)
+		guestAgentMock.EXPECT().Run(machineContext, restConfig, "infra-ns", "test-kubevirt-machine", guestagent.BootstrapLogCommand).Return(&guestagent.Result{Stdout: log}, nil)
+	}
+
+	It("should recreate the VM after a network failure, within the retries", func() {
+		expectBootstrapLog("dial tcp 10.0.0.1:6443: connect: no route to host\n")
+		machineMock.EXPECT().Delete().Return(nil).Times(1)
+
+		retried, err := reconciler.reconcileBootstrapFailure(machineContext, machineMock, "infra-ns", now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(retried).To(BeTrue())
+		status := machineContext.KubevirtMachine.Status
+		Expect(status.BootstrapRetries).To(Equal(int32(1)))
+		Expect(status.BootstrapDiagnosis.Reason).To(Equal(infrav1.BootstrapNetworkUnreachableReason))
+		Expect(status.ProvisioningPhase).To(BeEmpty())
+		Expect(recorder.Events).To(Receive(ContainSubstring("recreating the VM (1/1)")))
+
+		// the retries are exhausted, the failure is only reported
+		machineContext.KubevirtMachine.Status.ProvisioningPhaseStartTime = &metav1.Time{Time: now}
+		expectBootstrapLog("dial tcp 10.0.0.1:6443: connect: no route to host\n")
+		retried, err = reconciler.reconcileBootstrapFailure(machineContext, machineMock, "infra-ns", now.Add(5*time.Minute))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(retried).To(BeFalse())
+		Expect(machineContext.KubevirtMachine.Status.BootstrapRetries).To(Equal(int32(1)))
+	})
+
+	It("should recreate the VM with a fresh join token after a join token failure", func() {
+		expectBootstrapLog(tokenLog)
+
+		retried, err := reconciler.reconcileBootstrapFailure(machineContext, machineMock, "infra-ns", now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(retried).To(BeFalse())
+		Expect(machineContext.KubevirtMachine.Status.BootstrapDiagnosis.Reason).To(Equal(infrav1.BootstrapTokenExpiredReason))
+		Expect(recorder.Events).To(Receive(ContainSubstring("requested a fresh join token")))
+
+		updated := config.DeepCopy()
+		Expect(reconciler.Client.Get(gocontext.Background(), client.ObjectKeyFromObject(config), updated)).To(Succeed())
+		requested := updated.GetAnnotations()[infrav1.BootstrapTokenRefreshRequestedAnnotation]
+		Expect(requested).ToNot(BeEmpty())
+
+		// the VM is not recreated until the bootstrap provider answers
+		expectBootstrapLog(tokenLog)
+		retried, err = reconciler.reconcileBootstrapFailure(machineContext, machineMock, "infra-ns", now.Add(time.Minute))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(retried).To(BeFalse())
+
+		annotations := updated.GetAnnotations()
+		annotations[infrav1.BootstrapTokenRefreshedAnnotation] = requested
+		updated.SetAnnotations(annotations)
+		Expect(reconciler.Client.Update(gocontext.Background(), updated)).To(Succeed())
+
+		expectBootstrapLog(tokenLog)
+		machineMock.EXPECT().Delete().Return(nil).Times(1)
+		retried, err = reconciler.reconcileBootstrapFailure(machineContext, machineMock, "infra-ns", now.Add(2*time.Minute))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(retried).To(BeTrue())
+		Expect(machineContext.KubevirtMachine.Status.BootstrapRetries).To(Equal(int32(1)))
+		Expect(recorder.Events).To(Receive(ContainSubstring("recreating the VM with a fresh join token (1/1)")))
+	})
+
+	It("should not retry the failures that are not transient", func() {
+		expectBootstrapLog("\t[ERROR NumCPU]: the number of available CPUs 1 is less than the required 2\n")
+
+		retried, err := reconciler.reconcileBootstrapFailure(machineContext, machineMock, "infra-ns", now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(retried).To(BeFalse())
+		Expect(machineContext.KubevirtMachine.Status.BootstrapDiagnosis.Reason).To(Equal(infrav1.BootstrapPreflightFailedReason))
+		Expect(machineContext.KubevirtMachine.Status.BootstrapRetries).To(BeZero())
+	})
+
+	It("should leave cloud-init time to run and read the log at most once an interval", func() {
+		retried, err := reconciler.reconcileBootstrapFailure(machineContext, machineMock, "infra-ns", now.Add(-4*time.Minute))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(retried).To(BeFalse())
+		Expect(machineContext.KubevirtMachine.Status.BootstrapDiagnosis).To(BeNil())
+
+		infraClusterMock.EXPECT().GenerateInfraClusterRestConfig(gomock.Any(), gomock.Any(), gomock.Any()).Return(restConfig, "infra-ns", nil)
+		guestAgentMock.EXPECT().Run(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("guest agent not connected"))
+		retried, err = reconciler.reconcileBootstrapFailure(machineContext, machineMock, "infra-ns", now)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(retried).To(BeFalse())
+		Expect(machineContext.KubevirtMachine.Status.BootstrapDiagnosis.CheckTime.Time).To(Equal(now))
+
+		retried, err = reconciler.reconcileBootstrapFailure(machineContext, machineMock, "infra-ns", now.Add(30*time.Second))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(retried).To(BeFalse())
+	})
+})
+
+var _ = Describe("disk export", func() {
+	var (
+		machineContext *context.MachineContext
+		reconciler     KubevirtMachineReconciler
+		infraClient    client.Client
+		vm             *kubevirtv1.VirtualMachine
+	)
+
+	BeforeEach(func() {
+		kubevirtMachine := testing.NewKubevirtMachine("test-kubevirt-machine", "test-machine")
+		kubevirtMachine.Namespace = "default"
+		kubevirtMachine.UID = "kubevirt-machine-uid"
+		kubevirtMachine.Annotations = map[string]string{infrav1.ExportDisksAnnotation: "4h"}
+		failureReason := capierrors.UpdateMachineError
+		kubevirtMachine.Status.FailureReason = &failureReason
+		machine := testing.NewMachine("test-cluster", "test-machine", kubevirtMachine)
+		machineContext = &context.MachineContext{
+			Context:         gocontext.Background(),
+			Machine:         machine,
+			KubevirtMachine: kubevirtMachine,
+			Logger:          ctrl.Log.WithName("test"),
+		}
+
+		vm = &kubevirtv1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "infra", Name: kubevirtMachine.Name},
+			Spec:       kubevirtv1.VirtualMachineSpec{RunStrategy: ptr.To(kubevirtv1.RunStrategyAlways)},
+			Status:     kubevirtv1.VirtualMachineStatus{PrintableStatus: kubevirtv1.VirtualMachineStatusStopped},
+		}
+		reconciler = KubevirtMachineReconciler{Client: fake.NewClientBuilder().WithScheme(testing.SetupScheme()).Build()}
+	})
+
+	setupInfraClient := func(objects ...client.Object) {
+		infraClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(append(objects, vm)...).Build()
+	}
+
+	getVM := func() *kubevirtv1.VirtualMachine {
+		updated := &kubevirtv1.VirtualMachine{}
+		Expect(infraClient.Get(gocontext.Background(), client.ObjectKeyFromObject(vm), updated)).To(Succeed())
+		return updated
+	}
+
+	readyExport := func() *exportv1.VirtualMachineExport {
+		return &exportv1.VirtualMachineExport{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "infra", Name: "test-kubevirt-machine-export"},
+			Status: &exportv1.VirtualMachineExportStatus{
+				Phase:             exportv1.Ready,
+				TokenSecretRef:    ptr.To("export-token-test"),
+				TTLExpirationTime: &metav1.Time{Time: time.Now().Add(time.Hour)},
+				Links: &exportv1.VirtualMachineExportLinks{
+					External: &exportv1.VirtualMachineExportLink{
+						Cert: "export-ca",
+						Volumes: []exportv1.VirtualMachineExportVolume{{
+							Name:    "dv-disk",
+							Formats: []exportv1.VirtualMachineExportVolumeFormat{{Format: exportv1.KubeVirtGz, Url: "https://export.example.com/dv-disk.img.gz"}},
+						}},
+					},
+				},
+			},
+		}
+	}
+
+	exportToken := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "infra", Name: "export-token-test"},
+		Data:       map[string][]byte{"token": []byte("secret-token")},
+	}
+
+	It("should only export the disks of the failed machines", func() {
+		machineContext.KubevirtMachine.Status.FailureReason = nil
+		setupInfraClient()
+
+		_, exporting, err := reconciler.reconcileDiskExport(machineContext, infraClient, "infra")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(exporting).To(BeFalse())
+		Expect(machineContext.KubevirtMachine.Status.DiskExport.Message).To(ContainSubstring("once the machine failed"))
+		Expect(getVM().Spec.RunStrategy).To(HaveValue(Equal(kubevirtv1.RunStrategyAlways)))
+	})
+
+	It("should stop the VM and export its disks", func() {
+		vm.Status.PrintableStatus = kubevirtv1.VirtualMachineStatusRunning
+		setupInfraClient()
+
+		res, exporting, err := reconciler.reconcileDiskExport(machineContext, infraClient, "infra")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(exporting).To(BeTrue())
+		Expect(res.RequeueAfter).To(Equal(10 * time.Second))
+		Expect(getVM().Spec.RunStrategy).To(HaveValue(Equal(kubevirtv1.RunStrategyHalted)))
+		Expect(infraClient.Get(gocontext.Background(), client.ObjectKey{Namespace: "infra", Name: "test-kubevirt-machine-export"}, &exportv1.VirtualMachineExport{})).
+			To(MatchError(ContainSubstring("not found")))
+
+		// KubeVirt stopped the VM
+		stopped := getVM()
+		stopped.Status.PrintableStatus = kubevirtv1.VirtualMachineStatusStopped
+		Expect(infraClient.Update(gocontext.Background(), stopped)).To(Succeed())
+
+		_, exporting, err = reconciler.reconcileDiskExport(machineContext, infraClient, "infra")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(exporting).To(BeTrue())
+		export := &exportv1.VirtualMachineExport{}
+		Expect(infraClient.Get(gocontext.Background(), client.ObjectKey{Namespace: "infra", Name: "test-kubevirt-machine-export"}, export)).To(Succeed())
+		Expect(export.Spec.Source.Kind).To(Equal("VirtualMachine"))
+		Expect(export.Spec.Source.Name).To(Equal(vm.Name))
+		Expect(export.Spec.TTLDuration).To(HaveValue(Equal(metav1.Duration{Duration: 4 * time.Hour})))
+		Expect(machineContext.KubevirtMachine.Status.DiskExport.Phase).To(Equal(string(exportv1.Pending)))
+	})
+
+	It("should reject an invalid lifetime", func() {
+		machineContext.KubevirtMachine.Annotations[infrav1.ExportDisksAnnotation] = "forever"
+		setupInfraClient()
+
+		_, exporting, err := reconciler.reconcileDiskExport(machineContext, infraClient, "infra")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(exporting).To(BeFalse())
+		Expect(machineContext.KubevirtMachine.Status.DiskExport.Message).To(ContainSubstring("Invalid lifetime"))
+	})
+
+	It("should publish the URLs and the token of a ready export", func() {
+		setupInfraClient(readyExport(), exportToken)
+
+		res, exporting, err := reconciler.reconcileDiskExport(machineContext, infraClient, "infra")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(exporting).To(BeTrue())
+		Expect(res.RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+
+		status := machineContext.KubevirtMachine.Status.DiskExport
+		Expect(status.Phase).To(Equal(string(exportv1.Ready)))
+		Expect(status.Volumes).To(Equal([]infrav1.ExportedVolume{{Name: "dv-disk", URL: "https://export.example.com/dv-disk.img.gz"}}))
+		Expect(status.TokenSecretName).To(Equal("test-kubevirt-machine-export"))
+
+		secret := &corev1.Secret{}
+		Expect(reconciler.Client.Get(gocontext.Background(), client.ObjectKey{Namespace: "default", Name: status.TokenSecretName}, secret)).To(Succeed())
+		Expect(secret.Data).To(HaveKeyWithValue("token", []byte("secret-token")))
+		Expect(secret.Data).To(HaveKeyWithValue("ca.crt", []byte("export-ca")))
+		Expect(metav1.IsControlledBy(secret, machineContext.KubevirtMachine)).To(BeTrue())
+	})
+
+	It("should report an expired export without recreating it", func() {
+		machineContext.KubevirtMachine.Status.DiskExport = &infrav1.DiskExportStatus{
+			Phase:          string(exportv1.Ready),
+			ExpirationTime: &metav1.Time{Time: time.Now().Add(-time.Minute)},
+		}
+		setupInfraClient()
+
+		_, exporting, err := reconciler.reconcileDiskExport(machineContext, infraClient, "infra")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(exporting).To(BeTrue())
+		Expect(machineContext.KubevirtMachine.Status.DiskExport.Phase).To(Equal(diskExportExpiredPhase))
+		Expect(infraClient.Get(gocontext.Background(), client.ObjectKey{Namespace: "infra", Name: "test-kubevirt-machine-export"}, &exportv1.VirtualMachineExport{})).
+			To(MatchError(ContainSubstring("not found")))
+	})
+
+	It("should remove the export and restore the VM once the annotation is removed", func() {
+		vm.Annotations = map[string]string{infrav1.DiskExportRunStrategyAnnotation: string(kubevirtv1.RunStrategyAlways)}
+		vm.Spec.RunStrategy = ptr.To(kubevirtv1.RunStrategyHalted)
+		setupInfraClient(readyExport(), exportToken)
+		_, _, err := reconciler.reconcileDiskExport(machineContext, infraClient, "infra")
+		Expect(err).ToNot(HaveOccurred())
+
+		delete(machineContext.KubevirtMachine.Annotations, infrav1.ExportDisksAnnotation)
+		_, exporting, err := reconciler.reconcileDiskExport(machineContext, infraClient, "infra")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(exporting).To(BeFalse())
+		Expect(machineContext.KubevirtMachine.Status.DiskExport).To(BeNil())
+		Expect(getVM().Spec.RunStrategy).To(HaveValue(Equal(kubevirtv1.RunStrategyAlways)))
+		Expect(getVM().Annotations).ToNot(HaveKey(infrav1.DiskExportRunStrategyAnnotation))
+		Expect(infraClient.Get(gocontext.Background(), client.ObjectKey{Namespace: "infra", Name: "test-kubevirt-machine-export"}, &exportv1.VirtualMachineExport{})).
+			To(MatchError(ContainSubstring("not found")))
+		Expect(reconciler.Client.Get(gocontext.Background(), client.ObjectKey{Namespace: "default", Name: "test-kubevirt-machine-export"}, &corev1.Secret{})).
+			To(MatchError(ContainSubstring("not found")))
+	})
+})
+
+var _ = Describe("disk encryption", func() {
+	var machineContext *context.MachineContext
+
+	BeforeEach(func() {
+		kubevirtCluster = testing.NewKubevirtCluster("test-kubevirt-cluster", "test-kubevirt-cluster")
+		kubevirtCluster.Spec.DiskEncryption = &infrav1.DiskEncryptionSpec{Mode: infrav1.GuestDiskEncryptionMode}
+		cluster = testing.NewCluster("test-kubevirt-cluster", kubevirtCluster)
+		kubevirtMachine = testing.NewKubevirtMachine("test-kubevirt-machine", "test-machine")
+
+		fakeClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(kubevirtCluster, kubevirtMachine).Build()
+		kubevirtMachineReconciler = KubevirtMachineReconciler{Client: fakeClient}
+		machineContext = &context.MachineContext{
+			Context:         gocontext.Background(),
+			Cluster:         cluster,
+			KubevirtCluster: kubevirtCluster,
+			KubevirtMachine: kubevirtMachine,
+			Logger:          ctrl.Log.WithName("test"),
+		}
+	})
+
+	It("should generate the key of the cluster once", func() {
+		key, err := kubevirtMachineReconciler.reconcileDiskEncryptionKey(machineContext)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(key).ToNot(BeEmpty())
+
+		secret := &corev1.Secret{}
+		Expect(fakeClient.Get(gocontext.Background(), client.ObjectKey{Namespace: kubevirtCluster.Namespace, Name: "test-kubevirt-cluster-disk-encryption"}, secret)).To(Succeed())
+		Expect(secret.Data[diskEncryptionKeySecretKey]).To(Equal(key))
+		Expect(metav1.IsControlledBy(secret, kubevirtCluster)).To(BeTrue())
+
+		again, err := kubevirtMachineReconciler.reconcileDiskEncryptionKey(machineContext)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(again).To(Equal(key))
+	})
+
+	It("should open the encrypted disks at boot in the cloud-init config", func() {
+		disks := []kubevirt.GuestEncryptedDisk{{Name: "data", Serial: "data"}}
+		actual, modified, err := addDiskEncryptionToCloudInitConfig([]byte("#cloud-config\nbootcmd:\n- echo booted\n"), []byte("c2VjcmV0"), disks)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(modified).To(BeTrue())
+		Expect(string(actual)).To(Equal(`#cloud-config
+bootcmd:
+    - mkdir -p /run/capk && (umask 077 && printf '%s' 'c2VjcmV0' > /run/capk/disk-encryption.key)
+    - dev=$(ls /dev/disk/by-id/*[-_]data | head -n 1) && [ -n "$dev" ] && { cryptsetup isLuks "$dev" || cryptsetup luksFormat --batch-mode "$dev" /run/capk/disk-encryption.key; } && { [ -e /dev/mapper/data ] || cryptsetup open --key-file /run/capk/disk-encryption.key "$dev" data; }
+    - echo booted
+`))
+
+		ignition := []byte(`{"ignition":{"version":"3.3.0"}}`)
+		actual, modified, err = addDiskEncryptionToCloudInitConfig(ignition, []byte("c2VjcmV0"), disks)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(modified).To(BeFalse())
+		Expect(actual).To(Equal(ignition))
+	})
+})
+
+var _ = Describe("metadata propagation", func() {
+	var machineContext *context.MachineContext
+
+	BeforeEach(func() {
+		kubevirtCluster = testing.NewKubevirtCluster("test-kubevirt-cluster", "test-kubevirt-cluster")
+		kubevirtCluster.Spec.MetadataPropagation = &infrav1.MetadataPropagationSpec{
+			Labels:      []string{"example.com/tenant"},
+			Annotations: []string{"example.com/owner"},
+		}
+		cluster = testing.NewCluster("test-kubevirt-cluster", kubevirtCluster)
+		cluster.Labels = map[string]string{"example.com/tenant": "acme", "unrelated": "value"}
+		cluster.Annotations = map[string]string{"example.com/owner": "team-a"}
+		kubevirtMachine = testing.NewKubevirtMachine("test-kubevirt-machine", "test-machine")
+		machineContext = &context.MachineContext{
+			Context:         gocontext.Background(),
+			Cluster:         cluster,
+			KubevirtCluster: kubevirtCluster,
+			KubevirtMachine: kubevirtMachine,
+			Logger:          ctrl.Log.WithName("test"),
+		}
+	})
+
+	It("should propagate the metadata of the Cluster onto the existing VM", func() {
+		vm := testing.NewVirtualMachine(testing.NewVirtualMachineInstance(kubevirtMachine))
+		vm.Labels = map[string]string{"example.com/tenant": "previous", "kept": "value"}
+		fakeClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(vm).Build()
+		kubevirtMachineReconciler = KubevirtMachineReconciler{Client: fakeClient}
+
+		Expect(kubevirtMachineReconciler.reconcileVMMetadata(machineContext, fakeClient, vm.Namespace)).To(Succeed())
+
+		updated := &kubevirtv1.VirtualMachine{}
+		Expect(fakeClient.Get(gocontext.Background(), client.ObjectKeyFromObject(vm), updated)).To(Succeed())
+		Expect(updated.Labels).To(Equal(map[string]string{"example.com/tenant": "acme", "kept": "value"}))
+		Expect(updated.Annotations).To(HaveKeyWithValue("example.com/owner", "team-a"))
+	})
+
+	It("should ignore the machines without VM", func() {
+		fakeClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).Build()
+		kubevirtMachineReconciler = KubevirtMachineReconciler{Client: fakeClient}
+
+		Expect(kubevirtMachineReconciler.reconcileVMMetadata(machineContext, fakeClient, "default")).To(Succeed())
+	})
+})
+
+var _ = Describe("VM drift", func() {
+	var (
+		mockCtrl       *gomock.Controller
+		machineMock    *machinemocks.MockMachineInterface
+		machineContext *context.MachineContext
+		reconciler     KubevirtMachineReconciler
+		now            = time.Date(2024, time.March, 4, 10, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		machineMock = machinemocks.NewMockMachineInterface(mockCtrl)
+
+		kubevirtCluster := testing.NewKubevirtCluster("test-kubevirt-cluster", "test-kubevirt-cluster")
+		kubevirtCluster.Spec.VMDrift = &infrav1.VMDriftSpec{Remediation: infrav1.ReportVMDriftRemediation}
+		kubevirtMachine := testing.NewKubevirtMachine("test-kubevirt-machine", "test-machine")
+		machineContext = &context.MachineContext{
+			Context:         gocontext.Background(),
+			KubevirtCluster: kubevirtCluster,
+			KubevirtMachine: kubevirtMachine,
+			Logger:          ctrl.Log.WithName("test"),
+		}
+		reconciler = KubevirtMachineReconciler{}
+	})
+
+	It("should report the drifted fields, and check the VM again once the interval elapsed", func() {
+		machineMock.EXPECT().Drift().Return([]string{"memory", "disks"}).Times(1)
+
+		Expect(reconciler.reconcileVMDrift(machineContext, machineMock, now)).To(Succeed())
+		Expect(conditions.GetReason(machineContext.KubevirtMachine, infrav1.VMInSyncCondition)).To(Equal(infrav1.VMDriftedReason))
+		Expect(conditions.GetMessage(machineContext.KubevirtMachine, infrav1.VMInSyncCondition)).To(Equal("The memory, disks of the VM changed in the infra cluster"))
+		Expect(machineContext.KubevirtMachine.Status.VMDriftCheckTime.Time).To(Equal(now))
+
+		Expect(reconciler.reconcileVMDrift(machineContext, machineMock, now.Add(time.Minute))).To(Succeed())
+
+		machineMock.EXPECT().Drift().Return(nil).Times(1)
+		Expect(reconciler.reconcileVMDrift(machineContext, machineMock, now.Add(defaultVMDriftCheckInterval))).To(Succeed())
+		Expect(conditions.IsTrue(machineContext.KubevirtMachine, infrav1.VMInSyncCondition)).To(BeTrue())
+	})
+
+	It("should revert the drifted fields when the cluster requests it", func() {
+		machineContext.KubevirtCluster.Spec.VMDrift.Remediation = infrav1.RevertVMDriftRemediation
+		machineMock.EXPECT().Drift().Return([]string{"cpu"}).Times(1)
+		machineMock.EXPECT().RevertDrift(gomock.Any()).Return([]string{"cpu"}, nil).Times(1)
+
+		Expect(reconciler.reconcileVMDrift(machineContext, machineMock, now)).To(Succeed())
+		Expect(conditions.IsTrue(machineContext.KubevirtMachine, infrav1.VMInSyncCondition)).To(BeTrue())
+	})
+
+	It("should not check the VMs when the cluster does not enable it", func() {
+		machineContext.KubevirtCluster.Spec.VMDrift = nil
+		conditions.MarkTrue(machineContext.KubevirtMachine, infrav1.VMInSyncCondition)
+
+		Expect(reconciler.reconcileVMDrift(machineContext, machineMock, now)).To(Succeed())
+		Expect(conditions.Has(machineContext.KubevirtMachine, infrav1.VMInSyncCondition)).To(BeFalse())
+		Expect(vmDriftCheckInterval(machineContext.KubevirtCluster)).To(BeZero())
+	})
+})
+
+var _ = Describe("GPU status", func() {
+	var (
+		mockCtrl         *gomock.Controller
+		infraClusterMock *infraclustermock.MockInfraCluster
+		guestAgentMock   *guestagentmock.MockRunner
+		machineContext   *context.MachineContext
+		reconciler       KubevirtMachineReconciler
+		restConfig       = &rest.Config{Host: "https://infra"}
+		now              = time.Date(2024, time.March, 4, 10, 0, 0, 0, time.UTC)
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		infraClusterMock = infraclustermock.NewMockInfraCluster(mockCtrl)
+		guestAgentMock = guestagentmock.NewMockRunner(mockCtrl)
+
+		kubevirtMachine := testing.NewKubevirtMachine("test-kubevirt-machine", "test-machine")
+		kubevirtMachine.Spec.GPUs = []infrav1.MachineGPU{
+			{Name: "vgpu", MediatedDeviceType: "GRID T4-1Q"},
+			{Name: "gpu", DeviceName: "nvidia.com/TU104GL_Tesla_T4"},
+		}
+		kubevirtMachine.Status.VirtualMachineInstance = &infrav1.VirtualMachineInstanceInfo{NodeName: "node-a"}
+		kubevirtCluster := testing.NewKubevirtCluster("test-cluster", "test-kubevirt-cluster")
+		kubevirtCluster.Status.Infra = &infrav1.InfraStatus{
+			KubeVirtVersion: "v1.2.1",
+			HostDevices: []infrav1.InfraHostDevice{
+				{ResourceName: "nvidia.com/GRID_T4-1Q", MediatedDeviceType: "GRID T4-1Q"},
+				{ResourceName: "nvidia.com/TU104GL_Tesla_T4"},
+			},
+		}
+		machineContext = &context.MachineContext{
+			Context:         gocontext.Background(),
+			KubevirtCluster: kubevirtCluster,
+			KubevirtMachine: kubevirtMachine,
+			Logger:          ctrl.Log.WithName("test"),
+		}
+
+		reconciler = KubevirtMachineReconciler{
+			InfraCluster: infraClusterMock,
+			GuestAgent:   guestAgentMock,
+		}
+	})
+
+	expectMediatedDevices := func(uuids map[string]string, err error) {
+		infraClusterMock.EXPECT().GenerateInfraClusterRestConfig(gomock.Any(), gomock.Any(), gomock.Any()).Return(restConfig, "infra-ns", nil)
+		guestAgentMock.EXPECT().MediatedDevices(machineContext, restConfig, "infra-ns", "test-kubevirt-machine").Return(uuids, err)
+	}
+
+	It("should report the GPUs assigned to the VMI with the UUIDs of their mediated devices", func() {
+		expectMediatedDevices(map[string]string{"vgpu": "b1e5c9a2-3f4d-4e6b-8a7c-9d0e1f2a3b4c"}, nil)
+
+		Expect(reconciler.reconcileGPUStatus(machineContext, "infra-ns", now)).To(Succeed())
+		Expect(machineContext.KubevirtMachine.Status.GPUs).To(Equal(&infrav1.GPUStatus{
+			NodeName: "node-a",
+			Devices: []infrav1.AssignedGPU{
+				{Name: "vgpu", DeviceName: "nvidia.com/GRID_T4-1Q", MediatedDeviceUUID: "b1e5c9a2-3f4d-4e6b-8a7c-9d0e1f2a3b4c"},
+				{Name: "gpu", DeviceName: "nvidia.com/TU104GL_Tesla_T4"},
+			},
+			CheckTime: metav1.Time{Time: now},
+		}))
+
+		// the GPUs are not read again on the same node before the interval
+		Expect(reconciler.reconcileGPUStatus(machineContext, "infra-ns", now.Add(time.Minute))).To(Succeed())
+
+		// but they are once the VMI moved to another node
+		machineContext.KubevirtMachine.Status.VirtualMachineInstance.NodeName = "node-b"
+		expectMediatedDevices(map[string]string{"vgpu": "c2f6d0b3-4a5e-4f7c-9b8d-0e1f2a3b4c5d"}, nil)
+		Expect(reconciler.reconcileGPUStatus(machineContext, "infra-ns", now.Add(2*time.Minute))).To(Succeed())
+		Expect(machineContext.KubevirtMachine.Status.GPUs.NodeName).To(Equal("node-b"))
+		Expect(machineContext.KubevirtMachine.Status.GPUs.Devices[0].MediatedDeviceUUID).To(Equal("c2f6d0b3-4a5e-4f7c-9b8d-0e1f2a3b4c5d"))
+	})
+
+	It("should read the GPUs again until the mediated devices are assigned", func() {
+		expectMediatedDevices(map[string]string{}, nil)
+		Expect(reconciler.reconcileGPUStatus(machineContext, "infra-ns", now)).To(Succeed())
+		Expect(machineContext.KubevirtMachine.Status.GPUs.Devices[0].MediatedDeviceUUID).To(BeEmpty())
+
+		expectMediatedDevices(map[string]string{"vgpu": "b1e5c9a2-3f4d-4e6b-8a7c-9d0e1f2a3b4c"}, nil)
+		Expect(reconciler.reconcileGPUStatus(machineContext, "infra-ns", now.Add(time.Minute))).To(Succeed())
+		Expect(machineContext.KubevirtMachine.Status.GPUs.Devices[0].MediatedDeviceUUID).To(Equal("b1e5c9a2-3f4d-4e6b-8a7c-9d0e1f2a3b4c"))
+	})
+
+	It("should not fail when the GPUs cannot be read", func() {
+		expectMediatedDevices(nil, errors.New("no running virt-launcher pod"))
+
+		Expect(reconciler.reconcileGPUStatus(machineContext, "infra-ns", now)).To(Succeed())
+		Expect(machineContext.KubevirtMachine.Status.GPUs).To(BeNil())
+	})
+
+	It("should not report the GPUs of the machines without GPUs or scheduled VMIs", func() {
+		machineContext.KubevirtMachine.Status.VirtualMachineInstance.NodeName = ""
+		Expect(reconciler.reconcileGPUStatus(machineContext, "infra-ns", now)).To(Succeed())
+		Expect(machineContext.KubevirtMachine.Status.GPUs).To(BeNil())
+
+		machineContext.KubevirtMachine.Spec.GPUs = nil
+		machineContext.KubevirtMachine.Status.GPUs = &infrav1.GPUStatus{NodeName: "node-a"}
+		Expect(reconciler.reconcileGPUStatus(machineContext, "infra-ns", now)).To(Succeed())
+		Expect(machineContext.KubevirtMachine.Status.GPUs).To(BeNil())
+	})
+})
+
+var _ = Describe("guest agent condition", func() {
+	now := time.Date(2024, time.March, 4, 10, 0, 0, 0, time.UTC)
+
+	DescribeTable("should report whether the guest agent is connected", func(guestAgent *infrav1.GuestAgentSpec, vmi *infrav1.VirtualMachineInstanceInfo, status corev1.ConditionStatus, reason string, severity clusterv1.ConditionSeverity) {
+		kubevirtMachine := testing.NewKubevirtMachine("test-kubevirt-machine", "test-machine")
+		kubevirtMachine.Spec.GuestAgent = guestAgent
+		kubevirtMachine.Status.VirtualMachineInstance = vmi
+		machineContext := &context.MachineContext{Context: gocontext.Background(), KubevirtMachine: kubevirtMachine}
+
+		reconcileGuestAgentCondition(machineContext, now)
+
+		condition := conditions.Get(kubevirtMachine, infrav1.GuestAgentConnectedCondition)
+		if status == "" {
+			Expect(condition).To(BeNil())
+			return
+		}
+		Expect(condition.Status).To(Equal(status))
+		Expect(condition.Reason).To(Equal(reason))
+		Expect(condition.Severity).To(Equal(severity))
+		Expect(guestAgentNotConnected(kubevirtMachine)).To(Equal(severity == clusterv1.ConditionSeverityError))
+	},
+		Entry("connected", nil,
+			&infrav1.VirtualMachineInstanceInfo{Phase: kubevirtv1.Running, RunningTime: &metav1.Time{Time: now.Add(-time.Hour)}, AgentConnected: true},
+			corev1.ConditionTrue, "", clusterv1.ConditionSeverity("")),
+		Entry("without VMI", nil, nil, corev1.ConditionStatus(""), "", clusterv1.ConditionSeverity("")),
+		Entry("VMI not running", nil,
+			&infrav1.VirtualMachineInstanceInfo{Phase: kubevirtv1.Scheduling},
+			corev1.ConditionFalse, infrav1.WaitingForGuestAgentReason, clusterv1.ConditionSeverityInfo),
+		Entry("optional agent not connected", nil,
+			&infrav1.VirtualMachineInstanceInfo{Phase: kubevirtv1.Running, RunningTime: &metav1.Time{Time: now.Add(-time.Hour)}},
+			corev1.ConditionFalse, infrav1.AgentNotConnectedReason, clusterv1.ConditionSeverityInfo),
+		Entry("required agent within its connect timeout", &infrav1.GuestAgentSpec{Mode: infrav1.GuestAgentRequired},
+			&infrav1.VirtualMachineInstanceInfo{Phase: kubevirtv1.Running, RunningTime: &metav1.Time{Time: now.Add(-time.Minute)}},
+			corev1.ConditionFalse, infrav1.WaitingForGuestAgentReason, clusterv1.ConditionSeverityInfo),
+		Entry("required agent past its connect timeout", &infrav1.GuestAgentSpec{Mode: infrav1.GuestAgentRequired, ConnectTimeout: &metav1.Duration{Duration: 30 * time.Second}},
+			&infrav1.VirtualMachineInstanceInfo{Phase: kubevirtv1.Running, RunningTime: &metav1.Time{Time: now.Add(-time.Minute)}},
+			corev1.ConditionFalse, infrav1.AgentNotConnectedReason, clusterv1.ConditionSeverityError),
+		Entry("disabled agent", &infrav1.GuestAgentSpec{Mode: infrav1.GuestAgentDisabled},
+			&infrav1.VirtualMachineInstanceInfo{Phase: kubevirtv1.Running, RunningTime: &metav1.Time{Time: now.Add(-time.Hour)}},
+			corev1.ConditionStatus(""), "", clusterv1.ConditionSeverity("")),
+	)
+})
+
+var _ = Describe("cluster quota", func() {
+	var (
+		machineContext *context.MachineContext
+		created        = time.Date(2024, time.March, 4, 10, 0, 0, 0, time.UTC)
+	)
+
+	newMachine := func(name string, creationTime time.Time) *infrav1.KubevirtMachine {
+		kubevirtMachine := testing.NewKubevirtMachine(name, name)
+		kubevirtMachine.Labels = map[string]string{clusterv1.ClusterNameLabel: "test-cluster"}
+		kubevirtMachine.CreationTimestamp = metav1.NewTime(creationTime)
+		return kubevirtMachine
+	}
+
+	BeforeEach(func() {
+		kubevirtCluster := testing.NewKubevirtCluster("test-cluster", "test-kubevirt-cluster")
+		kubevirtCluster.Spec.Quota = &infrav1.ClusterQuota{MaxMachines: ptr.To[int32](2)}
+		machineContext = &context.MachineContext{
+			Context:         gocontext.Background(),
+			Cluster:         testing.NewCluster("test-cluster", kubevirtCluster),
+			KubevirtCluster: kubevirtCluster,
+			KubevirtMachine: newMachine("machine-b", created),
+			Logger:          ctrl.Log.WithName("test"),
+		}
+	})
+
+	quotaExceeded := func(objects ...client.Object) string {
+		reconciler := KubevirtMachineReconciler{
+			Client: fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(objects...).Build(),
+		}
+		message, err := reconciler.quotaExceeded(machineContext)
+		Expect(err).NotTo(HaveOccurred())
+		return message
+	}
+
+	It("should count the machines created before the machine", func() {
+		Expect(quotaExceeded(machineContext.KubevirtMachine, newMachine("machine-a", created.Add(-time.Minute)))).To(BeEmpty())
+		Expect(quotaExceeded(machineContext.KubevirtMachine, newMachine("machine-a", created.Add(-time.Minute)), newMachine("machine-c", created))).To(BeEmpty())
+		Expect(quotaExceeded(machineContext.KubevirtMachine, newMachine("machine-a", created.Add(-time.Minute)), newMachine("machine-0", created))).
+			To(Equal("the cluster would have 3 machines, more than its quota of 2"))
+	})
+
+	It("should count the provisioned machines created after the machine", func() {
+		provisioned := newMachine("machine-c", created.Add(time.Minute))
+		provisioned.Spec.ProviderID = ptr.To("kubevirt://machine-c")
+		Expect(quotaExceeded(machineContext.KubevirtMachine, newMachine("machine-a", created.Add(-time.Minute)), provisioned)).
+			To(Equal("the cluster would have 3 machines, more than its quota of 2"))
+	})
+
+	It("should not count the machines being deleted", func() {
+		deleted := newMachine("machine-a", created.Add(-time.Minute))
+		deleted.DeletionTimestamp = ptr.To(metav1.NewTime(created))
+		Expect(quotaExceeded(machineContext.KubevirtMachine, newMachine("machine-0", created.Add(-time.Minute)), deleted)).To(BeEmpty())
 	})
 })
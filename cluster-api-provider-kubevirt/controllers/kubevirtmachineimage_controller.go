@@ -0,0 +1,478 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	gocontext "context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/infracluster"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/resources"
+)
+
+// machineImageImportCheckInterval is the interval between two checks of the imports of an image in progress. The
+// DataVolumes of the infra clusters are not watched.
+const machineImageImportCheckInterval = 30 * time.Second
+
+// KubevirtMachineImageReconciler imports each KubevirtMachineImage, and its variants, once in every infra cluster
+// used by the KubevirtClusters and the KubevirtMachines of its namespace, and by the KubevirtMachines of the
+// namespaces it is shared with, through CDI DataVolumes the disks of the VMs are cloned from.
+type KubevirtMachineImageReconciler struct {
+	client.Client
+	InfraCluster infracluster.InfraCluster
+	// ReadOnly keeps the finalizer of the deleted images, for the controller deployed with a read-only infra
+	// cluster client.
+	ReadOnly bool
+}
+
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtmachineimages,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtmachineimages/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtclusters;kubevirtmachines;kubevirtmachineimagechannels,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cdi.kubevirt.io,resources=datavolumes,verbs=get;create;delete
+// +kubebuilder:rbac:groups=cdi.kubevirt.io,resources=datavolumes/source,verbs=create
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;create;update;delete
+
+// Reconcile imports a KubevirtMachineImage in the infra clusters, and deletes its imports once it is deleted.
+func (r *KubevirtMachineImageReconciler) Reconcile(goctx gocontext.Context, req ctrl.Request) (_ ctrl.Result, rerr error) {
+	image := &infrav1.KubevirtMachineImage{}
+	if err := r.Client.Get(goctx, req.NamespacedName, image); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	patchHelper, err := patch.NewHelper(image, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	defer func() {
+		if err := patchHelper.Patch(goctx, image); err != nil && rerr == nil {
+			rerr = errors.Wrap(err, "failed to patch KubevirtMachineImage")
+		}
+	}()
+
+	if !image.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.reconcileDelete(goctx, image)
+	}
+
+	if !controllerutil.ContainsFinalizer(image, infrav1.MachineImageFinalizer) {
+		controllerutil.AddFinalizer(image, infrav1.MachineImageFinalizer)
+		return ctrl.Result{}, nil
+	}
+
+	infraClusterSecretRefs, cloneNamespaces, err := r.infraClusters(goctx, image)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	imports := make([]infrav1.MachineImageImport, 0, len(infraClusterSecretRefs))
+	ready := len(infraClusterSecretRefs) > 0
+	for _, infraClusterSecretRef := range infraClusterSecretRefs {
+		var grantees []string
+		if infraClusterSecretRef == nil {
+			grantees = cloneNamespaces
+		}
+		infraClusterImports, err := r.reconcileImports(goctx, image, infraClusterSecretRef, grantees)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		for _, imageImport := range infraClusterImports {
+			imports = append(imports, imageImport)
+			ready = ready && imageImport.Ready
+		}
+	}
+	image.Status.Imports = imports
+	image.Status.Ready = ready
+
+	if !ready && len(imports) > 0 {
+		return ctrl.Result{RequeueAfter: machineImageImportCheckInterval}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// infraClusters returns the references to the kubeconfigs of the infra clusters the image is imported in: the ones
+// of the KubevirtClusters of its namespace, of the KubevirtMachines booting it directly or through a channel, and of
+// its previous imports. A nil reference stands for the management cluster. It returns as well the namespaces the
+// image is shared with whose machines run their VMs in the management cluster: they clone the import of the
+// namespace of the image, while the VMs of the other infra clusters run in the namespace of their import.
+func (r *KubevirtMachineImageReconciler) infraClusters(ctx gocontext.Context, image *infrav1.KubevirtMachineImage) ([]*corev1.ObjectReference, []string, error) {
+	var infraClusterSecretRefs []*corev1.ObjectReference
+	add := func(infraClusterSecretRef *corev1.ObjectReference) {
+		for _, ref := range infraClusterSecretRefs {
+			if kubevirt.SameInfraCluster(ref, infraClusterSecretRef) {
+				return
+			}
+		}
+		infraClusterSecretRefs = append(infraClusterSecretRefs, infraClusterSecretRef)
+	}
+
+	for _, imageImport := range image.Status.Imports {
+		add(imageImport.InfraClusterSecretRef)
+	}
+
+	kubevirtClusters := &infrav1.KubevirtClusterList{}
+	if err := r.Client.List(ctx, kubevirtClusters, client.InNamespace(image.Namespace)); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to list KubevirtClusters")
+	}
+	for _, kubevirtCluster := range kubevirtClusters.Items {
+		add(kubevirtCluster.Spec.InfraClusterSecretRef)
+	}
+
+	// The image is imported for the machines of the channels about to publish it as well
+	channels := &infrav1.KubevirtMachineImageChannelList{}
+	if err := r.Client.List(ctx, channels, client.InNamespace(image.Namespace)); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to list KubevirtMachineImageChannels")
+	}
+	publishing := map[string]bool{}
+	for _, channel := range channels.Items {
+		if channel.Spec.Image == image.Name || channel.Status.Image == image.Name {
+			publishing[channel.Name] = true
+		}
+	}
+
+	kubevirtMachines := &infrav1.KubevirtMachineList{}
+	if err := r.Client.List(ctx, kubevirtMachines, client.InNamespace(image.Namespace)); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to list KubevirtMachines")
+	}
+	for _, kubevirtMachine := range kubevirtMachines.Items {
+		reference := kubevirtMachine.Spec.Image
+		if reference == nil || kubevirtMachine.Spec.InfraClusterSecretRef == nil {
+			continue
+		}
+		if (reference.Name == image.Name && (reference.Namespace == "" || reference.Namespace == image.Namespace)) ||
+			(reference.Channel != "" && publishing[reference.Channel]) {
+			add(kubevirtMachine.Spec.InfraClusterSecretRef)
+		}
+	}
+
+	var cloneNamespaces []string
+	for _, namespace := range image.Spec.SharedWith {
+		if namespace == image.Namespace {
+			continue
+		}
+		kubevirtMachines := &infrav1.KubevirtMachineList{}
+		if err := r.Client.List(ctx, kubevirtMachines, client.InNamespace(namespace)); err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to list KubevirtMachines of namespace %s", namespace)
+		}
+		cloning := false
+		for _, kubevirtMachine := range kubevirtMachines.Items {
+			reference := kubevirtMachine.Spec.Image
+			if reference == nil || reference.Channel != "" || reference.Namespace != image.Namespace || reference.Name != image.Name {
+				continue
+			}
+			add(kubevirt.MachineImageInfraClusterSecretRef(image, &kubevirtMachine))
+			cloning = cloning || kubevirtMachine.Spec.InfraClusterSecretRef == nil
+		}
+		if cloning {
+			cloneNamespaces = append(cloneNamespaces, namespace)
+		}
+	}
+	sort.Strings(cloneNamespaces)
+
+	return infraClusterSecretRefs, cloneNamespaces, nil
+}
+
+// reconcileImports imports the image and its variants in an infra cluster, grants the VMs of the given namespaces
+// to clone them, and deletes the imports of the variants removed from the image.
+func (r *KubevirtMachineImageReconciler) reconcileImports(ctx gocontext.Context, image *infrav1.KubevirtMachineImage, infraClusterSecretRef *corev1.ObjectReference, grantees []string) ([]infrav1.MachineImageImport, error) {
+	infraClusterClient, infraClusterNamespace, err := r.InfraCluster.GenerateInfraClusterClient(infraClusterSecretRef, image.Namespace, ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate infra cluster client")
+	}
+
+	granted := false
+	for _, previous := range image.Status.Imports {
+		granted = granted || (kubevirt.SameInfraCluster(previous.InfraClusterSecretRef, infraClusterSecretRef) && len(previous.CloneGrants) > 0)
+	}
+	if len(grantees) > 0 {
+		if err := r.reconcileCloneGrant(ctx, infraClusterClient, image, infraClusterNamespace, grantees); err != nil {
+			return nil, err
+		}
+	} else if granted {
+		if err := deleteCloneGrant(ctx, infraClusterClient, image, infraClusterNamespace); err != nil {
+			return nil, err
+		}
+	}
+
+	variants := kubevirt.MachineImageVariants(image)
+	imports := make([]infrav1.MachineImageImport, 0, len(variants))
+	for _, variant := range variants {
+		imageImport, err := reconcileImport(ctx, infraClusterClient, image, infraClusterSecretRef, kubevirt.NewMachineImageDataVolume(image, variant, infraClusterNamespace))
+		if err != nil {
+			return nil, err
+		}
+		imageImport.Architecture = variant
+		imageImport.CloneGrants = grantees
+		imports = append(imports, *imageImport)
+	}
+
+	for _, previous := range image.Status.Imports {
+		if !kubevirt.SameInfraCluster(previous.InfraClusterSecretRef, infraClusterSecretRef) || slices.Contains(variants, previous.Architecture) {
+			continue
+		}
+		ctrl.LoggerFrom(ctx).Info("Variant of the machine image removed, deleting its import", "namespace", previous.Namespace, "dataVolume", previous.DataVolumeName)
+		dataVolume := &cdiv1.DataVolume{}
+		dataVolume.Namespace, dataVolume.Name = previous.Namespace, previous.DataVolumeName
+		if err := infraClusterClient.Delete(ctx, dataVolume); err != nil && !apierrors.IsNotFound(err) {
+			return nil, errors.Wrapf(err, "failed to delete DataVolume %s/%s", dataVolume.Namespace, dataVolume.Name)
+		}
+	}
+
+	return imports, nil
+}
+
+// reconcileImport creates the DataVolume importing a variant of the image in an infra cluster, and reports its
+// progress. The DataVolume is deleted, to be created again, when the source of the variant changes.
+func reconcileImport(ctx gocontext.Context, infraClusterClient client.Client, image *infrav1.KubevirtMachineImage, infraClusterSecretRef *corev1.ObjectReference, dataVolume *cdiv1.DataVolume) (*infrav1.MachineImageImport, error) {
+	imageImport := &infrav1.MachineImageImport{
+		InfraClusterSecretRef: infraClusterSecretRef,
+		Namespace:             dataVolume.Namespace,
+		DataVolumeName:        dataVolume.Name,
+	}
+
+	existing := &cdiv1.DataVolume{}
+	if err := infraClusterClient.Get(ctx, client.ObjectKeyFromObject(dataVolume), existing); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, errors.Wrapf(err, "failed to fetch DataVolume %s/%s", dataVolume.Namespace, dataVolume.Name)
+		}
+		ctrl.LoggerFrom(ctx).Info("Importing machine image", "namespace", dataVolume.Namespace, "dataVolume", dataVolume.Name)
+		if err := infraClusterClient.Create(ctx, dataVolume); err != nil {
+			return nil, errors.Wrapf(err, "failed to create DataVolume %s/%s", dataVolume.Namespace, dataVolume.Name)
+		}
+		return imageImport, nil
+	}
+
+	if existing.Annotations[infrav1.MachineImageSourceAnnotation] != dataVolume.Annotations[infrav1.MachineImageSourceAnnotation] {
+		if existing.DeletionTimestamp.IsZero() {
+			ctrl.LoggerFrom(ctx).Info("Source of the machine image changed, importing it again", "namespace", dataVolume.Namespace, "dataVolume", dataVolume.Name)
+			if err := infraClusterClient.Delete(ctx, existing); err != nil && !apierrors.IsNotFound(err) {
+				return nil, errors.Wrapf(err, "failed to delete DataVolume %s/%s", dataVolume.Namespace, dataVolume.Name)
+			}
+		}
+		return imageImport, nil
+	}
+
+	imageImport.Phase = string(existing.Status.Phase)
+	imageImport.Progress = string(existing.Status.Progress)
+	imageImport.Ready = existing.Status.Phase == cdiv1.Succeeded
+	return imageImport, nil
+}
+
+// reconcileCloneGrant grants the VMs of the given namespaces to clone the image imported in a namespace of an infra
+// cluster, as CDI requires for the clones across namespaces.
+func (r *KubevirtMachineImageReconciler) reconcileCloneGrant(ctx gocontext.Context, infraClusterClient client.Client, image *infrav1.KubevirtMachineImage, namespace string, grantees []string) error {
+	desiredRole := kubevirt.NewMachineImageCloneRole(image, namespace)
+	role := &rbacv1.Role{ObjectMeta: desiredRole.ObjectMeta}
+	if err := resources.CreateOrUpdate(ctx, infraClusterClient, infraClusterClient, "Role", role, func() {
+		role.Rules = desiredRole.Rules
+	}); err != nil {
+		return err
+	}
+
+	desiredRoleBinding := kubevirt.NewMachineImageCloneRoleBinding(image, namespace, grantees)
+	roleBinding := &rbacv1.RoleBinding{ObjectMeta: desiredRoleBinding.ObjectMeta}
+	return resources.CreateOrUpdate(ctx, infraClusterClient, infraClusterClient, "RoleBinding", roleBinding, func() {
+		roleBinding.RoleRef = desiredRoleBinding.RoleRef
+		roleBinding.Subjects = desiredRoleBinding.Subjects
+	})
+}
+
+// deleteCloneGrant revokes the grant to clone the image imported in a namespace of an infra cluster.
+func deleteCloneGrant(ctx gocontext.Context, infraClusterClient client.Client, image *infrav1.KubevirtMachineImage, namespace string) error {
+	name := kubevirt.MachineImageCloneGrantName(image)
+	for kind, object := range map[string]client.Object{"RoleBinding": &rbacv1.RoleBinding{}, "Role": &rbacv1.Role{}} {
+		object.SetNamespace(namespace)
+		object.SetName(name)
+		if err := infraClusterClient.Delete(ctx, object); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete %s %s/%s", kind, namespace, name)
+		}
+	}
+	return nil
+}
+
+// reconcileDelete deletes the imports of the image, and the grants to clone them, from the infra clusters.
+func (r *KubevirtMachineImageReconciler) reconcileDelete(ctx gocontext.Context, image *infrav1.KubevirtMachineImage) error {
+	for _, imageImport := range image.Status.Imports {
+		infraClusterClient, _, err := r.InfraCluster.GenerateInfraClusterClient(imageImport.InfraClusterSecretRef, image.Namespace, ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to generate infra cluster client")
+		}
+		dataVolume := &cdiv1.DataVolume{}
+		dataVolume.Namespace, dataVolume.Name = imageImport.Namespace, imageImport.DataVolumeName
+		if err := infraClusterClient.Delete(ctx, dataVolume); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete DataVolume %s/%s", dataVolume.Namespace, dataVolume.Name)
+		}
+		if len(imageImport.CloneGrants) > 0 {
+			if err := deleteCloneGrant(ctx, infraClusterClient, image, imageImport.Namespace); err != nil {
+				return err
+			}
+		}
+	}
+
+	if r.ReadOnly {
+		ctrl.LoggerFrom(ctx).Info("Read-only, keeping the finalizer of the deleted image")
+		return nil
+	}
+	controllerutil.RemoveFinalizer(image, infrav1.MachineImageFinalizer)
+	return nil
+}
+
+// kubevirtClusterToMachineImages maps a KubevirtCluster to the KubevirtMachineImages of its namespace, to import
+// them in its infra cluster.
+func (r *KubevirtMachineImageReconciler) kubevirtClusterToMachineImages(ctx gocontext.Context, o client.Object) []ctrl.Request {
+	images := &infrav1.KubevirtMachineImageList{}
+	if err := r.Client.List(ctx, images, client.InNamespace(o.GetNamespace())); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed to list KubevirtMachineImages")
+		return nil
+	}
+
+	requests := make([]ctrl.Request, 0, len(images.Items))
+	for _, image := range images.Items {
+		requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&image)})
+	}
+	return requests
+}
+
+// kubevirtMachineToMachineImages maps a KubevirtMachine to the KubevirtMachineImage it boots, possibly shared from
+// another namespace, or to the images published and about to be published in its channel.
+func (r *KubevirtMachineImageReconciler) kubevirtMachineToMachineImages(ctx gocontext.Context, o client.Object) []ctrl.Request {
+	kubevirtMachine, ok := o.(*infrav1.KubevirtMachine)
+	if !ok || kubevirtMachine.Spec.Image == nil {
+		return nil
+	}
+	if kubevirtMachine.Spec.Image.Channel == "" {
+		namespace := kubevirtMachine.Spec.Image.Namespace
+		if namespace == "" {
+			namespace = kubevirtMachine.Namespace
+		}
+		return []ctrl.Request{{NamespacedName: client.ObjectKey{Namespace: namespace, Name: kubevirtMachine.Spec.Image.Name}}}
+	}
+
+	channel := &infrav1.KubevirtMachineImageChannel{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: kubevirtMachine.Namespace, Name: kubevirtMachine.Spec.Image.Channel}, channel); err != nil {
+		return nil
+	}
+	return machineImageChannelToMachineImages(ctx, channel)
+}
+
+// machineImageChannelToMachineImages maps a KubevirtMachineImageChannel to the images it publishes and is about to
+// publish, to import them in the infra clusters of its machines.
+func machineImageChannelToMachineImages(_ gocontext.Context, o client.Object) []ctrl.Request {
+	channel, ok := o.(*infrav1.KubevirtMachineImageChannel)
+	if !ok {
+		return nil
+	}
+	requests := []ctrl.Request{{NamespacedName: client.ObjectKey{Namespace: channel.Namespace, Name: channel.Spec.Image}}}
+	if channel.Status.Image != "" && channel.Status.Image != channel.Spec.Image {
+		requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKey{Namespace: channel.Namespace, Name: channel.Status.Image}})
+	}
+	return requests
+}
+
+// SetupWithManager will add watches for this controller.
+func (r *KubevirtMachineImageReconciler) SetupWithManager(_ gocontext.Context, mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&infrav1.KubevirtMachineImage{}).
+		Watches(&infrav1.KubevirtCluster{}, handler.EnqueueRequestsFromMapFunc(r.kubevirtClusterToMachineImages)).
+		Watches(&infrav1.KubevirtMachine{}, handler.EnqueueRequestsFromMapFunc(r.kubevirtMachineToMachineImages)).
+		Watches(&infrav1.KubevirtMachineImageChannel{}, handler.EnqueueRequestsFromMapFunc(machineImageChannelToMachineImages)).
+		Complete(r)
+}
+
+// resolveMachineImage fetches the KubevirtMachineImage of the machine, or the one published in its channel, into
+// its context, and returns false while it is not imported in the infra cluster of the machine yet.
+func (r *KubevirtMachineReconciler) resolveMachineImage(ctx *context.MachineContext) (bool, error) {
+	reference := ctx.KubevirtMachine.Spec.Image
+	if reference == nil {
+		return true, nil
+	}
+
+	namespace, name := reference.Namespace, reference.Name
+	if namespace == "" || reference.Channel != "" {
+		namespace = ctx.KubevirtMachine.Namespace
+	}
+	if reference.Channel != "" {
+		channel := &infrav1.KubevirtMachineImageChannel{}
+		if err := r.Client.Get(ctx, client.ObjectKey{Namespace: ctx.KubevirtMachine.Namespace, Name: reference.Channel}, channel); err != nil {
+			if apierrors.IsNotFound(err) {
+				conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.WaitingForMachineImageReason, clusterv1.ConditionSeverityWarning,
+					"KubevirtMachineImageChannel %s not found", reference.Channel)
+				return false, nil
+			}
+			return false, errors.Wrapf(err, "failed to fetch KubevirtMachineImageChannel %s", reference.Channel)
+		}
+		if channel.Status.Image == "" {
+			conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.WaitingForMachineImageReason, clusterv1.ConditionSeverityInfo,
+				"No image published in KubevirtMachineImageChannel %s yet", reference.Channel)
+			return false, nil
+		}
+		name = channel.Status.Image
+	}
+
+	image := &infrav1.KubevirtMachineImage{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, image); err != nil {
+		if apierrors.IsNotFound(err) {
+			conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.WaitingForMachineImageReason, clusterv1.ConditionSeverityWarning,
+				"KubevirtMachineImage %s/%s not found", namespace, name)
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "failed to fetch KubevirtMachineImage %s/%s", namespace, name)
+	}
+
+	if !kubevirt.MachineImageSharedWith(image, ctx.KubevirtMachine.Namespace) {
+		conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.WaitingForMachineImageReason, clusterv1.ConditionSeverityWarning,
+			"KubevirtMachineImage %s/%s is not shared with namespace %s", namespace, name, ctx.KubevirtMachine.Namespace)
+		return false, nil
+	}
+
+	variant, found := kubevirt.MachineImageVariantFor(image, ctx.KubevirtMachine.Spec.Architecture)
+	if !found {
+		conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.WaitingForMachineImageReason, clusterv1.ConditionSeverityWarning,
+			"KubevirtMachineImage %s/%s has no variant for the %s architecture", namespace, name, ctx.KubevirtMachine.Spec.Architecture)
+		return false, nil
+	}
+
+	imageImport := kubevirt.FindMachineImageImport(image, kubevirt.MachineImageInfraClusterSecretRef(image, ctx.KubevirtMachine), variant)
+	if imageImport == nil || !imageImport.Ready {
+		ctx.Logger.Info("Waiting for the machine image to be imported in the infra cluster...", "image", name)
+		conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.WaitingForMachineImageReason, clusterv1.ConditionSeverityInfo,
+			"KubevirtMachineImage %s is not imported in the infra cluster yet", name)
+		return false, nil
+	}
+
+	ctx.MachineImage = image
+	return true, nil
+}
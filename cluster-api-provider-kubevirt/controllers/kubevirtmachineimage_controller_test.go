@@ -0,0 +1,270 @@
+package controllers_test
+
+import (
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/controllers"
+	infraclustermock "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/infracluster/mock"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("Reconcile a machine image", func() {
+	var (
+		image         *infrav1.KubevirtMachineImage
+		dataVolumeKey client.ObjectKey
+		reconciler    controllers.KubevirtMachineImageReconciler
+		request       ctrl.Request
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		infraClusterMock = infraclustermock.NewMockInfraCluster(mockCtrl)
+
+		kubevirtCluster = testing.NewKubevirtCluster("test-cluster", "test-kubevirt-cluster")
+		kubevirtCluster.Namespace = "capi"
+		image = &infrav1.KubevirtMachineImage{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "ubuntu",
+				Namespace:  "capi",
+				Finalizers: []string{infrav1.MachineImageFinalizer},
+			},
+			Spec: infrav1.KubevirtMachineImageSpec{
+				Source: infrav1.MachineImageSource{URL: "https://images/ubuntu.qcow2"},
+				Size:   resource.MustParse("10Gi"),
+			},
+		}
+		request = ctrl.Request{NamespacedName: client.ObjectKeyFromObject(image)}
+		dataVolumeKey = client.ObjectKey{Namespace: "infra", Name: "capi-ubuntu-image"}
+	})
+
+	setupClient := func(objects ...client.Object) {
+		objects = append(objects, kubevirtCluster, image)
+		fakeClient = fake.NewClientBuilder().
+			WithScheme(testing.SetupScheme()).
+			WithObjects(objects...).
+			WithStatusSubresource(objects...).
+			Build()
+		reconciler = controllers.KubevirtMachineImageReconciler{
+			Client:       fakeClient,
+			InfraCluster: infraClusterMock,
+		}
+		infraClusterMock.EXPECT().GenerateInfraClusterClient(nil, "capi", gomock.Any()).Return(fakeClient, "infra", nil).AnyTimes()
+	}
+
+	reconcile := func() ctrl.Result {
+		result, err := reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+		return result
+	}
+
+	getImage := func() *infrav1.KubevirtMachineImage {
+		updated := &infrav1.KubevirtMachineImage{}
+		Expect(fakeClient.Get(fakeContext, request.NamespacedName, updated)).To(Succeed())
+		return updated
+	}
+
+	setDataVolumePhase := func(phase cdiv1.DataVolumePhase) {
+		dataVolume := &cdiv1.DataVolume{}
+		Expect(fakeClient.Get(fakeContext, dataVolumeKey, dataVolume)).To(Succeed())
+		dataVolume.Status.Phase = phase
+		dataVolume.Status.Progress = "100.0%"
+		Expect(fakeClient.Update(fakeContext, dataVolume)).To(Succeed())
+	}
+
+	It("should import the image once in the infra cluster of the KubevirtClusters", func() {
+		setupClient()
+
+		Expect(reconcile()).To(Equal(ctrl.Result{RequeueAfter: 30 * time.Second}))
+		dataVolume := &cdiv1.DataVolume{}
+		Expect(fakeClient.Get(fakeContext, dataVolumeKey, dataVolume)).To(Succeed())
+		Expect(dataVolume.Spec.Source.HTTP.URL).To(Equal("https://images/ubuntu.qcow2"))
+		Expect(getImage().Status.Ready).To(BeFalse())
+		Expect(getImage().Status.Imports).To(ConsistOf(HaveField("DataVolumeName", "capi-ubuntu-image")))
+
+		setDataVolumePhase(cdiv1.Succeeded)
+		Expect(reconcile()).To(Equal(ctrl.Result{}))
+		Expect(getImage().Status.Ready).To(BeTrue())
+		Expect(getImage().Status.Imports).To(ConsistOf(infrav1.MachineImageImport{
+			Namespace:      "infra",
+			DataVolumeName: "capi-ubuntu-image",
+			Phase:          string(cdiv1.Succeeded),
+			Progress:       "100.0%",
+			Ready:          true,
+		}))
+	})
+
+	It("should import the image again when its checksum changes", func() {
+		setupClient()
+		reconcile()
+		setDataVolumePhase(cdiv1.Succeeded)
+
+		updated := getImage()
+		updated.Spec.Checksum = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+		Expect(fakeClient.Update(fakeContext, updated)).To(Succeed())
+
+		Expect(reconcile()).To(Equal(ctrl.Result{RequeueAfter: 30 * time.Second}))
+		Expect(getImage().Status.Ready).To(BeFalse())
+		Expect(apierrors.IsNotFound(fakeClient.Get(fakeContext, dataVolumeKey, &cdiv1.DataVolume{}))).To(BeTrue())
+
+		reconcile()
+		dataVolume := &cdiv1.DataVolume{}
+		Expect(fakeClient.Get(fakeContext, dataVolumeKey, dataVolume)).To(Succeed())
+		Expect(dataVolume.Annotations).To(HaveKeyWithValue(infrav1.MachineImageSourceAnnotation, "https://images/ubuntu.qcow2#"+updated.Spec.Checksum))
+	})
+
+	It("should import the variants of the image for the other architectures", func() {
+		image.Spec.Variants = []infrav1.MachineImageVariant{
+			{Architecture: "arm64", Source: infrav1.MachineImageSource{URL: "https://images/ubuntu-arm64.qcow2"}},
+		}
+		setupClient()
+
+		reconcile()
+		dataVolume := &cdiv1.DataVolume{}
+		variantKey := client.ObjectKey{Namespace: "infra", Name: "capi-ubuntu-arm64-image"}
+		Expect(fakeClient.Get(fakeContext, variantKey, dataVolume)).To(Succeed())
+		Expect(dataVolume.Spec.Source.HTTP.URL).To(Equal("https://images/ubuntu-arm64.qcow2"))
+		Expect(getImage().Status.Imports).To(ConsistOf(
+			And(HaveField("DataVolumeName", "capi-ubuntu-image"), HaveField("Architecture", "")),
+			And(HaveField("DataVolumeName", "capi-ubuntu-arm64-image"), HaveField("Architecture", "arm64")),
+		))
+
+		updated := getImage()
+		updated.Spec.Variants = nil
+		Expect(fakeClient.Update(fakeContext, updated)).To(Succeed())
+		reconcile()
+		Expect(apierrors.IsNotFound(fakeClient.Get(fakeContext, variantKey, &cdiv1.DataVolume{}))).To(BeTrue())
+		Expect(getImage().Status.Imports).To(ConsistOf(HaveField("DataVolumeName", "capi-ubuntu-image")))
+	})
+
+	It("should import the image in the infra cluster of the KubevirtMachines booting it", func() {
+		infraClusterSecretRef := &corev1.ObjectReference{Namespace: "capi", Name: "external-infra"}
+		kubevirtMachine := testing.NewKubevirtMachine("test-kubevirt-machine", "test-machine")
+		kubevirtMachine.Namespace = "capi"
+		kubevirtMachine.Spec.InfraClusterSecretRef = infraClusterSecretRef
+		kubevirtMachine.Spec.Image = &infrav1.MachineImageReference{Name: "ubuntu"}
+		setupClient(kubevirtMachine)
+		infraClusterMock.EXPECT().GenerateInfraClusterClient(infraClusterSecretRef, "capi", gomock.Any()).Return(fakeClient, "external", nil)
+
+		reconcile()
+
+		Expect(getImage().Status.Imports).To(HaveLen(2))
+		Expect(fakeClient.Get(fakeContext, client.ObjectKey{Namespace: "external", Name: "capi-ubuntu-image"}, &cdiv1.DataVolume{})).To(Succeed())
+	})
+
+	It("should import the image in the infra cluster of the KubevirtMachines of the channels publishing it", func() {
+		infraClusterSecretRef := &corev1.ObjectReference{Namespace: "capi", Name: "external-infra"}
+		kubevirtMachine := testing.NewKubevirtMachine("test-kubevirt-machine", "test-machine")
+		kubevirtMachine.Namespace = "capi"
+		kubevirtMachine.Spec.InfraClusterSecretRef = infraClusterSecretRef
+		kubevirtMachine.Spec.Image = &infrav1.MachineImageReference{Channel: "stable"}
+		channel := &infrav1.KubevirtMachineImageChannel{
+			ObjectMeta: metav1.ObjectMeta{Name: "stable", Namespace: "capi"},
+			Spec:       infrav1.KubevirtMachineImageChannelSpec{Image: "ubuntu"},
+		}
+		setupClient(kubevirtMachine, channel)
+		infraClusterMock.EXPECT().GenerateInfraClusterClient(infraClusterSecretRef, "capi", gomock.Any()).Return(fakeClient, "external", nil)
+
+		reconcile()
+
+		Expect(getImage().Status.Imports).To(HaveLen(2))
+		Expect(fakeClient.Get(fakeContext, client.ObjectKey{Namespace: "external", Name: "capi-ubuntu-image"}, &cdiv1.DataVolume{})).To(Succeed())
+	})
+
+	It("should grant the VMs of the namespaces the image is shared with to clone it", func() {
+		image.Spec.SharedWith = []string{"tenant-b", "tenant-a"}
+		var objects []client.Object
+		for _, namespace := range []string{"tenant-a", "tenant-b"} {
+			kubevirtMachine := testing.NewKubevirtMachine("test-kubevirt-machine", "test-machine")
+			kubevirtMachine.Namespace = namespace
+			kubevirtMachine.Spec.Image = &infrav1.MachineImageReference{Namespace: "capi", Name: "ubuntu"}
+			objects = append(objects, kubevirtMachine)
+		}
+		setupClient(objects...)
+
+		reconcile()
+
+		Expect(getImage().Status.Imports).To(ConsistOf(HaveField("CloneGrants", []string{"tenant-a", "tenant-b"})))
+		cloneGrantKey := client.ObjectKey{Namespace: "infra", Name: "capi-ubuntu-image-clone"}
+		role := &rbacv1.Role{}
+		Expect(fakeClient.Get(fakeContext, cloneGrantKey, role)).To(Succeed())
+		Expect(role.Rules).To(ConsistOf(rbacv1.PolicyRule{
+			APIGroups:     []string{"cdi.kubevirt.io"},
+			Resources:     []string{"datavolumes/source"},
+			ResourceNames: []string{"capi-ubuntu-image"},
+			Verbs:         []string{"create"},
+		}))
+		roleBinding := &rbacv1.RoleBinding{}
+		Expect(fakeClient.Get(fakeContext, cloneGrantKey, roleBinding)).To(Succeed())
+		Expect(roleBinding.Subjects).To(ConsistOf(
+			rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: "tenant-a", Name: "default"},
+			rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: "tenant-b", Name: "default"},
+		))
+
+		updated := getImage()
+		updated.Spec.SharedWith = nil
+		Expect(fakeClient.Update(fakeContext, updated)).To(Succeed())
+		reconcile()
+
+		Expect(getImage().Status.Imports).To(ConsistOf(HaveField("CloneGrants", BeEmpty())))
+		Expect(apierrors.IsNotFound(fakeClient.Get(fakeContext, cloneGrantKey, &rbacv1.Role{}))).To(BeTrue())
+		Expect(apierrors.IsNotFound(fakeClient.Get(fakeContext, cloneGrantKey, &rbacv1.RoleBinding{}))).To(BeTrue())
+	})
+
+	It("should import a shared image in the infra cluster of the KubevirtMachines of other namespaces", func() {
+		image.Spec.SharedWith = []string{"tenant"}
+		kubevirtMachine := testing.NewKubevirtMachine("test-kubevirt-machine", "test-machine")
+		kubevirtMachine.Namespace = "tenant"
+		kubevirtMachine.Spec.InfraClusterSecretRef = &corev1.ObjectReference{Name: "external-infra"}
+		kubevirtMachine.Spec.Image = &infrav1.MachineImageReference{Namespace: "capi", Name: "ubuntu"}
+		setupClient(kubevirtMachine)
+		infraClusterSecretRef := &corev1.ObjectReference{Namespace: "tenant", Name: "external-infra"}
+		infraClusterMock.EXPECT().GenerateInfraClusterClient(infraClusterSecretRef, "capi", gomock.Any()).Return(fakeClient, "external", nil)
+
+		reconcile()
+
+		Expect(getImage().Status.Imports).To(HaveLen(2))
+		Expect(getImage().Status.Imports).To(ContainElement(HaveField("InfraClusterSecretRef", infraClusterSecretRef)))
+		Expect(getImage().Status.Imports).To(HaveEach(HaveField("CloneGrants", BeEmpty())))
+	})
+
+	It("should delete the imports of a deleted image", func() {
+		setupClient()
+		reconcile()
+
+		Expect(fakeClient.Delete(fakeContext, image)).To(Succeed())
+		reconcile()
+
+		Expect(apierrors.IsNotFound(fakeClient.Get(fakeContext, dataVolumeKey, &cdiv1.DataVolume{}))).To(BeTrue())
+		Expect(apierrors.IsNotFound(fakeClient.Get(fakeContext, request.NamespacedName, &infrav1.KubevirtMachineImage{}))).To(BeTrue())
+	})
+
+	It("should revoke the clone grants of a deleted image", func() {
+		image.Spec.SharedWith = []string{"tenant"}
+		kubevirtMachine := testing.NewKubevirtMachine("test-kubevirt-machine", "test-machine")
+		kubevirtMachine.Namespace = "tenant"
+		kubevirtMachine.Spec.Image = &infrav1.MachineImageReference{Namespace: "capi", Name: "ubuntu"}
+		setupClient(kubevirtMachine)
+		reconcile()
+
+		Expect(fakeClient.Delete(fakeContext, image)).To(Succeed())
+		reconcile()
+
+		cloneGrantKey := client.ObjectKey{Namespace: "infra", Name: "capi-ubuntu-image-clone"}
+		Expect(apierrors.IsNotFound(fakeClient.Get(fakeContext, cloneGrantKey, &rbacv1.Role{}))).To(BeTrue())
+		Expect(apierrors.IsNotFound(fakeClient.Get(fakeContext, cloneGrantKey, &rbacv1.RoleBinding{}))).To(BeTrue())
+	})
+})
@@ -0,0 +1,191 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	gocontext "context"
+	"time"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/maintenance"
+)
+
+// KubevirtMachineImageChannelReconciler publishes the new images of the KubevirtMachineImageChannels once they are
+// imported, and rolls out the MachineDeployments booting them within the maintenance windows of the channels.
+type KubevirtMachineImageChannelReconciler struct {
+	client.Client
+	Recorder record.EventRecorder
+	// ReadOnly only reports the MachineDeployments the channel would roll out, and leaves the image unpublished for
+	// the controller managing the clusters, for the controller deployed with read-only infra and workload cluster
+	// clients.
+	ReadOnly bool
+}
+
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtmachineimagechannels,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtmachineimagechannels/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtmachineimages;kubevirtmachinetemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinedeployments,verbs=get;list;watch;patch
+
+// Reconcile publishes the image of a KubevirtMachineImageChannel once it is imported in all the infra clusters and
+// the channel is within one of its maintenance windows, and rolls out the MachineDeployments booting the channel.
+func (r *KubevirtMachineImageChannelReconciler) Reconcile(goctx gocontext.Context, req ctrl.Request) (_ ctrl.Result, rerr error) {
+	channel := &infrav1.KubevirtMachineImageChannel{}
+	if err := r.Client.Get(goctx, req.NamespacedName, channel); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if channel.Spec.Image == channel.Status.Image {
+		return ctrl.Result{}, nil
+	}
+
+	patchHelper, err := patch.NewHelper(channel, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	defer func() {
+		if err := patchHelper.Patch(goctx, channel); err != nil && rerr == nil {
+			rerr = errors.Wrap(err, "failed to patch KubevirtMachineImageChannel")
+		}
+	}()
+
+	log := ctrl.LoggerFrom(goctx)
+	image := &infrav1.KubevirtMachineImage{}
+	if err := r.Client.Get(goctx, client.ObjectKey{Namespace: channel.Namespace, Name: channel.Spec.Image}, image); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("Waiting for the KubevirtMachineImage of the channel to be created", "image", channel.Spec.Image)
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, errors.Wrapf(err, "failed to fetch KubevirtMachineImage %s", channel.Spec.Image)
+	}
+	if !image.Status.Ready {
+		log.Info("Waiting for the KubevirtMachineImage of the channel to be imported", "image", channel.Spec.Image)
+		return ctrl.Result{}, nil
+	}
+
+	now := time.Now()
+	if channel.Spec.Rollout != nil {
+		windows, err := maintenance.NewWindows(channel.Spec.Rollout.MaintenanceWindows)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !windows.Open(now) {
+			next := windows.NextOpening(now)
+			log.Info("Waiting for the next maintenance window to publish the image", "image", channel.Spec.Image, "window", next)
+			if next.IsZero() {
+				return ctrl.Result{}, nil
+			}
+			return ctrl.Result{RequeueAfter: next.Sub(now)}, nil
+		}
+	}
+
+	var rolledOut []string
+	if channel.Spec.Rollout != nil && r.ReadOnly {
+		log.Info("Read-only, leaving the image of the channel unpublished", "image", channel.Spec.Image)
+		_, err := r.rolloutMachineDeployments(goctx, channel, now)
+		return ctrl.Result{}, err
+	}
+	if channel.Spec.Rollout != nil {
+		if rolledOut, err = r.rolloutMachineDeployments(goctx, channel, now); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	log.Info("Published machine image", "image", channel.Spec.Image, "machineDeployments", rolledOut)
+	channel.Status.Image = channel.Spec.Image
+	channel.Status.PublishedTime = &metav1.Time{Time: now}
+	channel.Status.RolledOutMachineDeployments = rolledOut
+	return ctrl.Result{}, nil
+}
+
+// rolloutMachineDeployments rolls out the MachineDeployments whose KubevirtMachineTemplate boots the channel, by
+// setting their rolloutAfter time, and returns their names. When read-only, the rollouts are only reported.
+func (r *KubevirtMachineImageChannelReconciler) rolloutMachineDeployments(ctx gocontext.Context, channel *infrav1.KubevirtMachineImageChannel, now time.Time) ([]string, error) {
+	machineDeployments := &clusterv1.MachineDeploymentList{}
+	if err := r.Client.List(ctx, machineDeployments, client.InNamespace(channel.Namespace)); err != nil {
+		return nil, errors.Wrap(err, "failed to list MachineDeployments")
+	}
+
+	var rolledOut []string
+	for i := range machineDeployments.Items {
+		machineDeployment := &machineDeployments.Items[i]
+		infrastructureRef := machineDeployment.Spec.Template.Spec.InfrastructureRef
+		if infrastructureRef.Kind != "KubevirtMachineTemplate" {
+			continue
+		}
+
+		template := &infrav1.KubevirtMachineTemplate{}
+		if err := r.Client.Get(ctx, client.ObjectKey{Namespace: machineDeployment.Namespace, Name: infrastructureRef.Name}, template); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to fetch KubevirtMachineTemplate %s", infrastructureRef.Name)
+		}
+		if reference := template.Spec.Template.Spec.Image; reference == nil || reference.Channel != channel.Name {
+			continue
+		}
+
+		if r.ReadOnly {
+			recordPlannedChange(r.Recorder, channel, "roll out", "MachineDeployment", machineDeployment.Namespace, machineDeployment.Name)
+			continue
+		}
+		patchBase := client.MergeFrom(machineDeployment.DeepCopy())
+		machineDeployment.Spec.RolloutAfter = &metav1.Time{Time: now}
+		if err := r.Client.Patch(ctx, machineDeployment, patchBase); err != nil {
+			return nil, errors.Wrapf(err, "failed to roll out MachineDeployment %s", machineDeployment.Name)
+		}
+		rolledOut = append(rolledOut, machineDeployment.Name)
+	}
+	return rolledOut, nil
+}
+
+// machineImageToMachineImageChannels maps a KubevirtMachineImage to the KubevirtMachineImageChannels about to publish
+// it, to publish it once it is imported.
+func (r *KubevirtMachineImageChannelReconciler) machineImageToMachineImageChannels(ctx gocontext.Context, o client.Object) []ctrl.Request {
+	channels := &infrav1.KubevirtMachineImageChannelList{}
+	if err := r.Client.List(ctx, channels, client.InNamespace(o.GetNamespace())); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed to list KubevirtMachineImageChannels")
+		return nil
+	}
+
+	var requests []ctrl.Request
+	for _, channel := range channels.Items {
+		if channel.Spec.Image == o.GetName() {
+			requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&channel)})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager will add watches for this controller.
+func (r *KubevirtMachineImageChannelReconciler) SetupWithManager(_ gocontext.Context, mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&infrav1.KubevirtMachineImageChannel{}).
+		Watches(&infrav1.KubevirtMachineImage{}, handler.EnqueueRequestsFromMapFunc(r.machineImageToMachineImageChannels)).
+		Complete(r)
+}
@@ -0,0 +1,163 @@
+package controllers_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/controllers"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("Reconcile a machine image channel", func() {
+	var (
+		channel    *infrav1.KubevirtMachineImageChannel
+		image      *infrav1.KubevirtMachineImage
+		reconciler controllers.KubevirtMachineImageChannelReconciler
+		request    ctrl.Request
+	)
+
+	newMachineDeployment := func(name, templateName string) *clusterv1.MachineDeployment {
+		return &clusterv1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "capi"},
+			Spec: clusterv1.MachineDeploymentSpec{
+				ClusterName: "test-cluster",
+				Template: clusterv1.MachineTemplateSpec{
+					Spec: clusterv1.MachineSpec{
+						ClusterName:       "test-cluster",
+						InfrastructureRef: corev1.ObjectReference{Kind: "KubevirtMachineTemplate", Name: templateName},
+					},
+				},
+			},
+		}
+	}
+
+	newKubevirtMachineTemplate := func(name string, image *infrav1.MachineImageReference) *infrav1.KubevirtMachineTemplate {
+		template := &infrav1.KubevirtMachineTemplate{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "capi"}}
+		template.Spec.Template.Spec.Image = image
+		return template
+	}
+
+	BeforeEach(func() {
+		image = &infrav1.KubevirtMachineImage{
+			ObjectMeta: metav1.ObjectMeta{Name: "ubuntu-2404", Namespace: "capi"},
+			Status:     infrav1.KubevirtMachineImageStatus{Ready: true},
+		}
+		channel = &infrav1.KubevirtMachineImageChannel{
+			ObjectMeta: metav1.ObjectMeta{Name: "ubuntu", Namespace: "capi"},
+			Spec:       infrav1.KubevirtMachineImageChannelSpec{Image: "ubuntu-2404"},
+			Status:     infrav1.KubevirtMachineImageChannelStatus{Image: "ubuntu-2310"},
+		}
+		request = ctrl.Request{NamespacedName: client.ObjectKeyFromObject(channel)}
+	})
+
+	setupClient := func(objects ...client.Object) {
+		objects = append(objects, channel, image)
+		fakeClient = fake.NewClientBuilder().
+			WithScheme(testing.SetupScheme()).
+			WithObjects(objects...).
+			WithStatusSubresource(objects...).
+			Build()
+		reconciler = controllers.KubevirtMachineImageChannelReconciler{Client: fakeClient}
+	}
+
+	reconcile := func() ctrl.Result {
+		result, err := reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+		return result
+	}
+
+	getChannel := func() *infrav1.KubevirtMachineImageChannel {
+		updated := &infrav1.KubevirtMachineImageChannel{}
+		Expect(fakeClient.Get(fakeContext, request.NamespacedName, updated)).To(Succeed())
+		return updated
+	}
+
+	getMachineDeployment := func(name string) *clusterv1.MachineDeployment {
+		machineDeployment := &clusterv1.MachineDeployment{}
+		Expect(fakeClient.Get(fakeContext, client.ObjectKey{Namespace: "capi", Name: name}, machineDeployment)).To(Succeed())
+		return machineDeployment
+	}
+
+	It("should publish an imported image without rolling out the MachineDeployments", func() {
+		setupClient(
+			newMachineDeployment("md", "template"),
+			newKubevirtMachineTemplate("template", &infrav1.MachineImageReference{Channel: "ubuntu"}),
+		)
+
+		Expect(reconcile()).To(Equal(ctrl.Result{}))
+
+		Expect(getChannel().Status.Image).To(Equal("ubuntu-2404"))
+		Expect(getChannel().Status.PublishedTime).ToNot(BeNil())
+		Expect(getMachineDeployment("md").Spec.RolloutAfter).To(BeNil())
+	})
+
+	It("should wait for the image to be imported", func() {
+		image.Status.Ready = false
+		setupClient()
+
+		reconcile()
+
+		Expect(getChannel().Status.Image).To(Equal("ubuntu-2310"))
+	})
+
+	It("should roll out the MachineDeployments booting the channel", func() {
+		channel.Spec.Rollout = &infrav1.ImageChannelRollout{}
+		setupClient(
+			newMachineDeployment("md-channel", "template-channel"),
+			newKubevirtMachineTemplate("template-channel", &infrav1.MachineImageReference{Channel: "ubuntu"}),
+			newMachineDeployment("md-image", "template-image"),
+			newKubevirtMachineTemplate("template-image", &infrav1.MachineImageReference{Name: "ubuntu-2404"}),
+			newMachineDeployment("md-other", "template-other"),
+			newKubevirtMachineTemplate("template-other", &infrav1.MachineImageReference{Channel: "fedora"}),
+		)
+
+		Expect(reconcile()).To(Equal(ctrl.Result{}))
+
+		Expect(getChannel().Status.Image).To(Equal("ubuntu-2404"))
+		Expect(getChannel().Status.RolledOutMachineDeployments).To(ConsistOf("md-channel"))
+		Expect(getMachineDeployment("md-channel").Spec.RolloutAfter).ToNot(BeNil())
+		Expect(getMachineDeployment("md-image").Spec.RolloutAfter).To(BeNil())
+		Expect(getMachineDeployment("md-other").Spec.RolloutAfter).To(BeNil())
+	})
+
+	It("should only report the rollouts when read-only", func() {
+		channel.Spec.Rollout = &infrav1.ImageChannelRollout{}
+		setupClient(
+			newMachineDeployment("md", "template"),
+			newKubevirtMachineTemplate("template", &infrav1.MachineImageReference{Channel: "ubuntu"}),
+		)
+		recorder := record.NewFakeRecorder(10)
+		reconciler.Recorder = recorder
+		reconciler.ReadOnly = true
+
+		Expect(reconcile()).To(Equal(ctrl.Result{}))
+
+		Expect(getChannel().Status.Image).To(Equal("ubuntu-2310"))
+		Expect(getMachineDeployment("md").Spec.RolloutAfter).To(BeNil())
+		Expect(recorder.Events).To(Receive(ContainSubstring("Would roll out MachineDeployment capi/md")))
+	})
+
+	It("should wait for the next maintenance window", func() {
+		// A window of one minute a year, that the test is very unlikely to run in
+		channel.Spec.Rollout = &infrav1.ImageChannelRollout{
+			MaintenanceWindows: []infrav1.MaintenanceWindow{{Start: "0 3 29 2 *", End: "1 3 29 2 *"}},
+		}
+		setupClient(
+			newMachineDeployment("md", "template"),
+			newKubevirtMachineTemplate("template", &infrav1.MachineImageReference{Channel: "ubuntu"}),
+		)
+
+		Expect(reconcile().RequeueAfter).To(BeNumerically(">", 0))
+
+		Expect(getChannel().Status.Image).To(Equal("ubuntu-2310"))
+		Expect(getMachineDeployment("md").Spec.RolloutAfter).To(BeNil())
+	})
+})
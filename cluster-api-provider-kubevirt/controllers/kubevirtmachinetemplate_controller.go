@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	gocontext "context"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
+)
+
+// templateNodeOperatingSystem is the operating system of the Nodes of the machines, bootstrapped with cloud-init.
+const templateNodeOperatingSystem = "linux"
+
+// KubevirtMachineTemplateReconciler reports the architecture of the Nodes of the machines created from the
+// KubevirtMachineTemplates, for the cluster autoscaler to scale the MachineDeployments of mixed-architecture clusters
+// from zero.
+type KubevirtMachineTemplateReconciler struct {
+	client.Client
+}
+
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtmachinetemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtmachinetemplates/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtmachineimages;kubevirtmachineimagechannels,verbs=get;list;watch
+
+// Reconcile reports in status.nodeInfo the architecture of the machines of a KubevirtMachineTemplate: the one of its
+// spec, or else the one of the image it boots.
+func (r *KubevirtMachineTemplateReconciler) Reconcile(goctx gocontext.Context, req ctrl.Request) (ctrl.Result, error) {
+	template := &infrav1.KubevirtMachineTemplate{}
+	if err := r.Client.Get(goctx, req.NamespacedName, template); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	architecture, err := r.templateArchitecture(goctx, template)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	nodeInfo := &infrav1.TemplateNodeInfo{Architecture: architecture, OperatingSystem: templateNodeOperatingSystem}
+	if equality.Semantic.DeepEqual(template.Status.NodeInfo, nodeInfo) {
+		return ctrl.Result{}, nil
+	}
+
+	patchBase := client.MergeFrom(template.DeepCopy())
+	template.Status.NodeInfo = nodeInfo
+	if err := r.Client.Status().Patch(goctx, template, patchBase); err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to patch the status of KubevirtMachineTemplate %s", template.Name)
+	}
+	return ctrl.Result{}, nil
+}
+
+// templateArchitecture returns the architecture of the machines of the template, or an empty string when it is left
+// to the default of KubeVirt or its image is not found yet.
+func (r *KubevirtMachineTemplateReconciler) templateArchitecture(ctx gocontext.Context, template *infrav1.KubevirtMachineTemplate) (string, error) {
+	spec := &template.Spec.Template.Spec
+	if spec.Architecture != "" {
+		return spec.Architecture, nil
+	}
+	reference := spec.Image
+	if reference == nil {
+		return "", nil
+	}
+
+	namespace, name := reference.Namespace, reference.Name
+	if namespace == "" || reference.Channel != "" {
+		namespace = template.Namespace
+	}
+	if reference.Channel != "" {
+		channel := &infrav1.KubevirtMachineImageChannel{}
+		if err := r.Client.Get(ctx, client.ObjectKey{Namespace: template.Namespace, Name: reference.Channel}, channel); err != nil {
+			if apierrors.IsNotFound(err) {
+				return "", nil
+			}
+			return "", errors.Wrapf(err, "failed to fetch KubevirtMachineImageChannel %s", reference.Channel)
+		}
+		name = channel.Status.Image
+		if name == "" {
+			return "", nil
+		}
+	}
+
+	image := &infrav1.KubevirtMachineImage{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, image); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", errors.Wrapf(err, "failed to fetch KubevirtMachineImage %s/%s", namespace, name)
+	}
+	return kubevirt.MachineImageArchitecture(image), nil
+}
+
+// machineImageToKubevirtMachineTemplates maps a KubevirtMachineImage or a KubevirtMachineImageChannel to the
+// KubevirtMachineTemplates which may boot it, to report the architecture of its image.
+func (r *KubevirtMachineTemplateReconciler) machineImageToKubevirtMachineTemplates(ctx gocontext.Context, o client.Object) []ctrl.Request {
+	templates := &infrav1.KubevirtMachineTemplateList{}
+	if err := r.Client.List(ctx, templates); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed to list KubevirtMachineTemplates")
+		return nil
+	}
+
+	var requests []ctrl.Request
+	for _, template := range templates.Items {
+		reference := template.Spec.Template.Spec.Image
+		if reference == nil || template.Spec.Template.Spec.Architecture != "" {
+			continue
+		}
+		namespace := reference.Namespace
+		if namespace == "" || reference.Channel != "" {
+			namespace = template.Namespace
+		}
+		if namespace == o.GetNamespace() {
+			requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&template)})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager will add watches for this controller.
+func (r *KubevirtMachineTemplateReconciler) SetupWithManager(_ gocontext.Context, mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&infrav1.KubevirtMachineTemplate{}).
+		Watches(&infrav1.KubevirtMachineImage{}, handler.EnqueueRequestsFromMapFunc(r.machineImageToKubevirtMachineTemplates)).
+		Watches(&infrav1.KubevirtMachineImageChannel{}, handler.EnqueueRequestsFromMapFunc(r.machineImageToKubevirtMachineTemplates)).
+		Complete(r)
+}
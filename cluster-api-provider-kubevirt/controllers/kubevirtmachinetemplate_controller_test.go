@@ -0,0 +1,74 @@
+package controllers_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/controllers"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("Reconcile a machine template", func() {
+	var (
+		template   *infrav1.KubevirtMachineTemplate
+		reconciler controllers.KubevirtMachineTemplateReconciler
+		request    ctrl.Request
+	)
+
+	BeforeEach(func() {
+		template = &infrav1.KubevirtMachineTemplate{ObjectMeta: metav1.ObjectMeta{Name: "md-0", Namespace: "capi"}}
+		request = ctrl.Request{NamespacedName: client.ObjectKeyFromObject(template)}
+	})
+
+	setupClient := func(objects ...client.Object) {
+		objects = append(objects, template)
+		fakeClient = fake.NewClientBuilder().
+			WithScheme(testing.SetupScheme()).
+			WithObjects(objects...).
+			WithStatusSubresource(objects...).
+			Build()
+		reconciler = controllers.KubevirtMachineTemplateReconciler{Client: fakeClient}
+	}
+
+	getNodeInfo := func() *infrav1.TemplateNodeInfo {
+		_, err := reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+		updated := &infrav1.KubevirtMachineTemplate{}
+		Expect(fakeClient.Get(fakeContext, request.NamespacedName, updated)).To(Succeed())
+		return updated.Status.NodeInfo
+	}
+
+	It("should report the architecture of the template", func() {
+		template.Spec.Template.Spec.Architecture = "arm64"
+		setupClient()
+
+		Expect(getNodeInfo()).To(Equal(&infrav1.TemplateNodeInfo{Architecture: "arm64", OperatingSystem: "linux"}))
+	})
+
+	It("should report the architecture of the image published in the channel of the template", func() {
+		template.Spec.Template.Spec.Image = &infrav1.MachineImageReference{Channel: "ubuntu"}
+		channel := &infrav1.KubevirtMachineImageChannel{
+			ObjectMeta: metav1.ObjectMeta{Name: "ubuntu", Namespace: "capi"},
+			Status:     infrav1.KubevirtMachineImageChannelStatus{Image: "ubuntu-2404"},
+		}
+		image := &infrav1.KubevirtMachineImage{
+			ObjectMeta: metav1.ObjectMeta{Name: "ubuntu-2404", Namespace: "capi"},
+			Spec:       infrav1.KubevirtMachineImageSpec{Architecture: "arm64"},
+		}
+		setupClient(channel, image)
+
+		Expect(getNodeInfo()).To(Equal(&infrav1.TemplateNodeInfo{Architecture: "arm64", OperatingSystem: "linux"}))
+	})
+
+	It("should leave the architecture to the default of KubeVirt when the template sets none", func() {
+		template.Spec.Template.Spec.Image = &infrav1.MachineImageReference{Name: "missing"}
+		setupClient()
+
+		Expect(getNodeInfo()).To(Equal(&infrav1.TemplateNodeInfo{OperatingSystem: "linux"}))
+	})
+})
@@ -0,0 +1,308 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	gocontext "context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/hibernation"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/infracluster"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/maintenance"
+)
+
+const (
+	remediationActionReason    = "RemediationAction"
+	remediationEscalatedReason = "RemediationEscalated"
+)
+
+// defaultRemediationSteps are the steps of the remediations not defining any.
+var defaultRemediationSteps = []infrav1.RemediationStep{{Action: infrav1.RestartRemediationAction}}
+
+// KubevirtRemediationReconciler implements the external remediation of the MachineHealthChecks referencing a
+// KubevirtRemediationTemplate: the VM of the unhealthy machine is restarted, reset or migrated, step by step,
+// and the machine is replaced once the steps are exhausted. The MachineHealthCheck deletes the
+// KubevirtRemediation as soon as the machine is healthy again, which ends the remediation.
+type KubevirtRemediationReconciler struct {
+	client.Client
+	InfraCluster infracluster.InfraCluster
+	Recorder     record.EventRecorder
+	// ReadOnly leaves the replacement of the machines to the controller managing the clusters, for the controller
+	// deployed with read-only infra and workload cluster clients.
+	ReadOnly bool
+}
+
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtremediations,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtremediations/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtremediationtemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines;machines/status,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=kubevirt.io,resources=virtualmachineinstances,verbs=get;delete
+// +kubebuilder:rbac:groups=kubevirt.io,resources=virtualmachineinstancemigrations,verbs=get;create
+
+// Reconcile runs the current step of a KubevirtRemediation.
+func (r *KubevirtRemediationReconciler) Reconcile(goctx gocontext.Context, req ctrl.Request) (_ ctrl.Result, rerr error) {
+	log := ctrl.LoggerFrom(goctx)
+
+	remediation := &infrav1.KubevirtRemediation{}
+	if err := r.Client.Get(goctx, req.NamespacedName, remediation); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if !remediation.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	// The MachineHealthCheck names the remediation after the machine, and makes the machine own it
+	machine, err := util.GetOwnerMachine(goctx, r.Client, remediation.ObjectMeta)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if machine == nil {
+		log.Info("Waiting for MachineHealthCheck Controller to set OwnerRef on KubevirtRemediation")
+		return ctrl.Result{}, nil
+	}
+
+	cluster, err := util.GetClusterFromMetadata(goctx, r.Client, machine.ObjectMeta)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if annotations.IsPaused(cluster, remediation) {
+		log.Info("Reconciliation is paused for this object")
+		return ctrl.Result{}, nil
+	}
+
+	kubevirtCluster := &infrav1.KubevirtCluster{}
+	if err := r.Client.Get(goctx, client.ObjectKey{Namespace: remediation.Namespace, Name: cluster.Spec.InfrastructureRef.Name}, kubevirtCluster); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to fetch KubevirtCluster")
+	}
+	kubevirtMachine := &infrav1.KubevirtMachine{}
+	if err := r.Client.Get(goctx, client.ObjectKey{Namespace: remediation.Namespace, Name: machine.Spec.InfrastructureRef.Name}, kubevirtMachine); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to fetch KubevirtMachine")
+	}
+
+	machineContext := &context.MachineContext{
+		Context:         goctx,
+		Cluster:         cluster,
+		KubevirtCluster: kubevirtCluster,
+		Machine:         machine,
+		KubevirtMachine: kubevirtMachine,
+		Logger:          ctrl.LoggerFrom(goctx).WithName(req.Namespace).WithName(req.Name),
+	}
+
+	patchHelper, err := patch.NewHelper(remediation, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	defer func() {
+		if err := patchHelper.Patch(goctx, remediation); err != nil && rerr == nil {
+			rerr = errors.Wrap(err, "failed to patch KubevirtRemediation")
+		}
+	}()
+
+	// The VMs of a hibernated cluster are stopped on purpose
+	if hibernation.IsClusterHibernated(kubevirtCluster) {
+		log.Info("KubevirtCluster is hibernated, waiting for it to be resumed")
+		return ctrl.Result{RequeueAfter: time.Minute}, nil
+	}
+
+	// The VMs of a cluster in maintenance may be down on purpose
+	if maintenance.InMaintenance(kubevirtCluster, time.Now()) {
+		log.Info("KubevirtCluster is in maintenance, waiting for the maintenance to be over")
+		return ctrl.Result{RequeueAfter: time.Minute}, nil
+	}
+
+	if isDryRun(kubevirtCluster) {
+		log.Info("KubevirtCluster is annotated for dry-run, no VM will be remediated")
+		return ctrl.Result{}, nil
+	}
+
+	// The VMs belong to the management cluster holding the infra ownership lease
+	if isInfraOwnedElsewhere(kubevirtCluster) {
+		log.Info("Another management cluster owns the infra resources, waiting for the infra ownership lease")
+		return ctrl.Result{RequeueAfter: time.Minute}, nil
+	}
+
+	return r.reconcileRemediation(machineContext, remediation)
+}
+
+func (r *KubevirtRemediationReconciler) reconcileRemediation(ctx *context.MachineContext, remediation *infrav1.KubevirtRemediation) (ctrl.Result, error) {
+	status := &remediation.Status
+	steps := remediation.Spec.Steps
+	if len(steps) == 0 {
+		steps = defaultRemediationSteps
+	}
+
+	if status.Phase == infrav1.ReplacingRemediationPhase || int(status.Step) >= len(steps) {
+		return ctrl.Result{}, r.requestMachineReplacement(ctx, remediation)
+	}
+
+	infraClusterSecretRef := ctx.KubevirtMachine.Spec.InfraClusterSecretRef
+	if infraClusterSecretRef == nil {
+		infraClusterSecretRef = ctx.KubevirtCluster.Spec.InfraClusterSecretRef
+	}
+	infraClusterClient, infraClusterNamespace, err := r.InfraCluster.GenerateInfraClusterClient(infraClusterSecretRef, ctx.KubevirtMachine.Namespace, ctx.Context)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, errors.Wrap(err, "failed to generate infra cluster client")
+	}
+
+	vm := &kubevirtv1.VirtualMachine{}
+	vmKey := client.ObjectKey{Namespace: machineVMNamespace(ctx.KubevirtMachine, infraClusterNamespace), Name: ctx.KubevirtMachine.Name}
+	if err := infraClusterClient.Get(ctx, vmKey, vm); err != nil {
+		if apierrors.IsNotFound(err) {
+			// Nothing left to remediate, the machine needs to be replaced
+			status.Phase = infrav1.ReplacingRemediationPhase
+			return ctrl.Result{}, r.requestMachineReplacement(ctx, remediation)
+		}
+		return ctrl.Result{}, errors.Wrapf(err, "failed to fetch VM %s", vmKey)
+	}
+
+	step := steps[status.Step]
+	timeout := infrav1.DefaultRemediationStepTimeout
+	if step.Timeout != nil {
+		timeout = step.Timeout.Duration
+	}
+	now := time.Now()
+
+	switch status.Phase {
+	case "":
+		ctx.Logger.Info("Remediating machine", "step", status.Step, "action", step.Action)
+		r.recordEvent(remediation, corev1.EventTypeNormal, remediationActionReason,
+			fmt.Sprintf("Step %d/%d: %s of VM %s", status.Step+1, len(steps), step.Action, vmKey))
+		status.StepStartTime = &metav1.Time{Time: now}
+
+		switch step.Action {
+		case infrav1.MigrateThenRestartRemediationAction:
+			status.MigrationName = fmt.Sprintf("%s-remediation-%d", vm.Name, status.Step)
+			if err := kubevirt.MigrateVirtualMachine(ctx, infraClusterClient, vm, status.MigrationName); err != nil {
+				return ctrl.Result{}, err
+			}
+			status.Phase = infrav1.MigratingRemediationPhase
+			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		case infrav1.ResetRemediationAction:
+			if err := kubevirt.ResetVirtualMachine(ctx, infraClusterClient, vm); err != nil {
+				return ctrl.Result{}, err
+			}
+		default:
+			if _, err := kubevirt.RestartVirtualMachine(ctx, infraClusterClient, vm); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		status.Phase = infrav1.WaitingRemediationPhase
+		return ctrl.Result{RequeueAfter: timeout}, nil
+
+	case infrav1.MigratingRemediationPhase:
+		finished, err := kubevirt.IsMigrationFinished(ctx, infraClusterClient, vm.Namespace, status.MigrationName)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		// The VM is restarted even if its migration failed or is stuck, on whatever node it runs
+		if !finished && now.Sub(status.StepStartTime.Time) < timeout {
+			ctx.Logger.Info("Waiting for the migration of the VM to complete...", "migration", status.MigrationName)
+			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		}
+		if !finished {
+			ctx.Logger.Info("Migration of the VM did not complete in time, restarting it anyway", "migration", status.MigrationName)
+		}
+
+		if _, err := kubevirt.RestartVirtualMachine(ctx, infraClusterClient, vm); err != nil {
+			return ctrl.Result{}, err
+		}
+		status.Phase = infrav1.WaitingRemediationPhase
+		status.StepStartTime = &metav1.Time{Time: now}
+		return ctrl.Result{RequeueAfter: timeout}, nil
+
+	default:
+		if remaining := status.StepStartTime.Add(timeout).Sub(now); remaining > 0 {
+			return ctrl.Result{RequeueAfter: remaining}, nil
+		}
+
+		// The machine did not recover, escalate to the next step
+		status.Step++
+		status.Phase = ""
+		status.StepStartTime = nil
+		status.MigrationName = ""
+		return ctrl.Result{Requeue: true}, nil
+	}
+}
+
+// requestMachineReplacement marks the machine as remediated by its owner, so that the MachineSet or the control
+// plane provider owning it deletes and replaces it.
+func (r *KubevirtRemediationReconciler) requestMachineReplacement(ctx *context.MachineContext, remediation *infrav1.KubevirtRemediation) error {
+	if remediation.Status.Phase != infrav1.ReplacingRemediationPhase {
+		remediation.Status.Phase = infrav1.ReplacingRemediationPhase
+		ctx.Logger.Info("Remediation steps exhausted, requesting the replacement of the machine")
+		r.recordEvent(remediation, corev1.EventTypeWarning, remediationEscalatedReason,
+			fmt.Sprintf("Machine %s did not recover, requesting its replacement", ctx.Machine.Name))
+	}
+
+	if conditions.IsFalse(ctx.Machine, clusterv1.MachineOwnerRemediatedCondition) {
+		return nil
+	}
+	if r.ReadOnly {
+		ctx.Logger.Info("Read-only, leaving the replacement of the machine to the controller managing the cluster")
+		return nil
+	}
+
+	patchHelper, err := patch.NewHelper(ctx.Machine, r.Client)
+	if err != nil {
+		return err
+	}
+	conditions.MarkFalse(ctx.Machine, clusterv1.MachineOwnerRemediatedCondition, clusterv1.WaitingForRemediationReason, clusterv1.ConditionSeverityWarning,
+		"KubevirtRemediation %s did not recover the machine", remediation.Name)
+	if err := patchHelper.Patch(ctx, ctx.Machine, patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{
+		clusterv1.MachineOwnerRemediatedCondition,
+	}}); err != nil {
+		return errors.Wrapf(err, "failed to patch Machine %s", ctx.Machine.Name)
+	}
+
+	return nil
+}
+
+func (r *KubevirtRemediationReconciler) recordEvent(remediation *infrav1.KubevirtRemediation, eventType, reason, message string) {
+	if r.Recorder != nil {
+		r.Recorder.Event(remediation, eventType, reason, message)
+	}
+}
+
+// SetupWithManager will add watches for this controller.
+func (r *KubevirtRemediationReconciler) SetupWithManager(ctx gocontext.Context, mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&infrav1.KubevirtRemediation{}).
+		WithEventFilter(predicates.ResourceNotPaused(ctrl.LoggerFrom(ctx))).
+		Complete(r)
+}
@@ -0,0 +1,221 @@
+package controllers_test
+
+import (
+	"time"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/controllers"
+	infraclustermock "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/infracluster/mock"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("Reconcile a remediation", func() {
+	var (
+		machine         *clusterv1.Machine
+		kubevirtMachine *infrav1.KubevirtMachine
+		remediation     *infrav1.KubevirtRemediation
+		vm              *kubevirtv1.VirtualMachine
+		vmi             *kubevirtv1.VirtualMachineInstance
+		reconciler      controllers.KubevirtRemediationReconciler
+		request         ctrl.Request
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		infraClusterMock = infraclustermock.NewMockInfraCluster(mockCtrl)
+
+		kubevirtCluster = testing.NewKubevirtCluster("test-cluster", "test-kubevirt-cluster")
+		cluster = testing.NewCluster("test-cluster", kubevirtCluster)
+		kubevirtMachine = testing.NewKubevirtMachine("test-kubevirt-machine", "test-machine")
+		machine = testing.NewMachine(cluster.Name, "test-machine", kubevirtMachine)
+
+		remediation = &infrav1.KubevirtRemediation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: machine.Name,
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: clusterv1.GroupVersion.String(),
+					Kind:       "Machine",
+					Name:       machine.Name,
+				}},
+			},
+		}
+		request = ctrl.Request{NamespacedName: client.ObjectKeyFromObject(remediation)}
+
+		vmi = testing.NewVirtualMachineInstance(kubevirtMachine)
+		vm = testing.NewVirtualMachine(vmi)
+	})
+
+	setupClient := func() {
+		objects := []client.Object{cluster, kubevirtCluster, machine, kubevirtMachine, remediation, vm, vmi}
+		fakeClient = fake.NewClientBuilder().
+			WithScheme(testing.SetupScheme()).
+			WithObjects(objects...).
+			WithStatusSubresource(objects...).
+			Build()
+		reconciler = controllers.KubevirtRemediationReconciler{
+			Client:       fakeClient,
+			InfraCluster: infraClusterMock,
+		}
+		infraClusterMock.EXPECT().GenerateInfraClusterClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeClient, kubevirtCluster.Namespace, nil).AnyTimes()
+	}
+
+	reconcile := func() ctrl.Result {
+		result, err := reconciler.Reconcile(fakeContext, request)
+		Expect(err).ToNot(HaveOccurred())
+		return result
+	}
+
+	getRemediation := func() *infrav1.KubevirtRemediation {
+		updated := &infrav1.KubevirtRemediation{}
+		Expect(fakeClient.Get(fakeContext, request.NamespacedName, updated)).To(Succeed())
+		return updated
+	}
+
+	vmiExists := func() bool {
+		err := fakeClient.Get(fakeContext, client.ObjectKeyFromObject(vmi), &kubevirtv1.VirtualMachineInstance{})
+		if apierrors.IsNotFound(err) {
+			return false
+		}
+		Expect(err).ToNot(HaveOccurred())
+		return true
+	}
+
+	It("should restart the VM by default and wait for the machine to recover", func() {
+		setupClient()
+
+		Expect(reconcile()).To(Equal(ctrl.Result{RequeueAfter: infrav1.DefaultRemediationStepTimeout}))
+		Expect(vmiExists()).To(BeFalse())
+
+		updated := getRemediation()
+		Expect(updated.Status.Step).To(BeZero())
+		Expect(updated.Status.Phase).To(Equal(infrav1.WaitingRemediationPhase))
+		Expect(updated.Status.StepStartTime).ToNot(BeNil())
+	})
+
+	It("should escalate to the next step once the machine did not recover in time", func() {
+		remediation.Spec.Steps = []infrav1.RemediationStep{
+			{Action: infrav1.RestartRemediationAction, Timeout: &metav1.Duration{Duration: time.Minute}},
+			{Action: infrav1.ResetRemediationAction},
+		}
+		remediation.Status.Phase = infrav1.WaitingRemediationPhase
+		remediation.Status.StepStartTime = &metav1.Time{Time: time.Now().Add(-2 * time.Minute)}
+		setupClient()
+
+		Expect(reconcile()).To(Equal(ctrl.Result{Requeue: true}))
+		Expect(vmiExists()).To(BeTrue())
+		Expect(getRemediation().Status.Step).To(Equal(int32(1)))
+
+		Expect(reconcile()).To(Equal(ctrl.Result{RequeueAfter: infrav1.DefaultRemediationStepTimeout}))
+		Expect(vmiExists()).To(BeFalse())
+		Expect(getRemediation().Status.Phase).To(Equal(infrav1.WaitingRemediationPhase))
+	})
+
+	It("should keep waiting while the step did not time out", func() {
+		remediation.Status.Phase = infrav1.WaitingRemediationPhase
+		remediation.Status.StepStartTime = &metav1.Time{Time: time.Now().Add(-time.Minute)}
+		setupClient()
+
+		result := reconcile()
+		Expect(result.RequeueAfter).To(BeNumerically("~", infrav1.DefaultRemediationStepTimeout-time.Minute, time.Second))
+		Expect(vmiExists()).To(BeTrue())
+		Expect(getRemediation().Status.Step).To(BeZero())
+	})
+
+	It("should migrate the VM before restarting it", func() {
+		remediation.Spec.Steps = []infrav1.RemediationStep{{Action: infrav1.MigrateThenRestartRemediationAction}}
+		setupClient()
+
+		Expect(reconcile()).To(Equal(ctrl.Result{RequeueAfter: 10 * time.Second}))
+		updated := getRemediation()
+		Expect(updated.Status.Phase).To(Equal(infrav1.MigratingRemediationPhase))
+
+		migration := &kubevirtv1.VirtualMachineInstanceMigration{}
+		Expect(fakeClient.Get(fakeContext, client.ObjectKey{Namespace: vm.Namespace, Name: updated.Status.MigrationName}, migration)).To(Succeed())
+		Expect(migration.Spec.VMIName).To(Equal(vm.Name))
+
+		// the migration is still running
+		Expect(reconcile()).To(Equal(ctrl.Result{RequeueAfter: 10 * time.Second}))
+		Expect(vmiExists()).To(BeTrue())
+
+		migration.Status.Phase = kubevirtv1.MigrationSucceeded
+		Expect(fakeClient.Update(fakeContext, migration)).To(Succeed())
+
+		Expect(reconcile()).To(Equal(ctrl.Result{RequeueAfter: infrav1.DefaultRemediationStepTimeout}))
+		Expect(vmiExists()).To(BeFalse())
+		Expect(getRemediation().Status.Phase).To(Equal(infrav1.WaitingRemediationPhase))
+	})
+
+	It("should request the replacement of the machine once the steps are exhausted", func() {
+		remediation.Status.Step = 1
+		setupClient()
+
+		Expect(reconcile()).To(Equal(ctrl.Result{}))
+		Expect(vmiExists()).To(BeTrue())
+		Expect(getRemediation().Status.Phase).To(Equal(infrav1.ReplacingRemediationPhase))
+
+		updatedMachine := &clusterv1.Machine{}
+		Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(machine), updatedMachine)).To(Succeed())
+		Expect(conditions.IsFalse(updatedMachine, clusterv1.MachineOwnerRemediatedCondition)).To(BeTrue())
+		Expect(conditions.GetReason(updatedMachine, clusterv1.MachineOwnerRemediatedCondition)).To(Equal(clusterv1.WaitingForRemediationReason))
+	})
+
+	It("should not remediate the machines of a hibernated cluster", func() {
+		kubevirtCluster.Spec.Hibernated = true
+		setupClient()
+
+		Expect(reconcile()).To(Equal(ctrl.Result{RequeueAfter: time.Minute}))
+		Expect(vmiExists()).To(BeTrue())
+	})
+
+	It("should only report the replacement of the machine when read-only", func() {
+		remediation.Status.Step = 1
+		setupClient()
+		reconciler.ReadOnly = true
+
+		Expect(reconcile()).To(Equal(ctrl.Result{}))
+		Expect(getRemediation().Status.Phase).To(Equal(infrav1.ReplacingRemediationPhase))
+
+		updatedMachine := &clusterv1.Machine{}
+		Expect(fakeClient.Get(fakeContext, client.ObjectKeyFromObject(machine), updatedMachine)).To(Succeed())
+		Expect(conditions.Has(updatedMachine, clusterv1.MachineOwnerRemediatedCondition)).To(BeFalse())
+	})
+
+	It("should not remediate the machines of a cluster in maintenance", func() {
+		kubevirtCluster.Annotations = map[string]string{infrav1.MaintenanceAnnotation: time.Now().Add(time.Hour).Format(time.RFC3339)}
+		setupClient()
+
+		Expect(reconcile()).To(Equal(ctrl.Result{RequeueAfter: time.Minute}))
+		Expect(vmiExists()).To(BeTrue())
+		Expect(getRemediation().Status.Phase).To(BeEmpty())
+	})
+
+	It("should not remediate the machines of a cluster annotated for dry-run", func() {
+		kubevirtCluster.Annotations = map[string]string{infrav1.DryRunAnnotation: "true"}
+		setupClient()
+
+		Expect(reconcile()).To(Equal(ctrl.Result{}))
+		Expect(vmiExists()).To(BeTrue())
+		Expect(getRemediation().Status.Phase).To(BeEmpty())
+	})
+
+	It("should not remediate the machines while another management cluster owns the infra resources", func() {
+		conditions.MarkFalse(kubevirtCluster, infrav1.InfraOwnershipCondition, infrav1.InfraOwnedElsewhereReason, clusterv1.ConditionSeverityWarning, "")
+		setupClient()
+
+		Expect(reconcile()).To(Equal(ctrl.Result{RequeueAfter: time.Minute}))
+		Expect(vmiExists()).To(BeTrue())
+		Expect(getRemediation().Status.Phase).To(BeEmpty())
+	})
+})
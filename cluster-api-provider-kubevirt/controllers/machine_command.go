@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	gocontext "context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/guestagent"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/operations"
+)
+
+const (
+	commandSucceededReason = "CommandSucceeded"
+	commandFailedReason    = "CommandFailed"
+
+	// commandOperationPrefix prefixes the name of a command in the type of the operation running it.
+	commandOperationPrefix = "Command/"
+
+	// maxCommandOutputSize keeps the result ConfigMaps well below the size limit of objects.
+	maxCommandOutputSize = 256 * 1024
+)
+
+// reconcileCommand runs the command requested with the run-command annotation inside the VM, stores its result
+// in a ConfigMap owned by the KubevirtMachine, and removes the annotation. The command is run in the background,
+// and polled by the next reconciles. It is only run once, even if it fails.
+func (r *KubevirtMachineReconciler) reconcileCommand(ctx *context.MachineContext, vmNamespace string) error {
+	name, requested := ctx.KubevirtMachine.Annotations[infrav1.RunCommandAnnotation]
+	if !requested || r.GuestAgent == nil || r.ReadOnly {
+		return nil
+	}
+
+	command, allowed := guestagent.Commands[name]
+	if !allowed {
+		delete(ctx.KubevirtMachine.Annotations, infrav1.RunCommandAnnotation)
+		r.recordCommandEvent(ctx, corev1.EventTypeWarning, commandFailedReason, fmt.Sprintf("Command %q is not allowed", name))
+		return nil
+	}
+	if !kubevirt.GuestAgentEnabled(ctx.KubevirtMachine) {
+		delete(ctx.KubevirtMachine.Annotations, infrav1.RunCommandAnnotation)
+		r.recordCommandEvent(ctx, corev1.EventTypeWarning, commandFailedReason, fmt.Sprintf("Command %q cannot run, the guest agent of the machine is disabled", name))
+		return nil
+	}
+
+	restConfig, _, err := r.InfraCluster.GenerateInfraClusterRestConfig(ctx.KubevirtMachine.Spec.InfraClusterSecretRef, ctx.KubevirtMachine.Namespace, ctx.Context)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate infra cluster config")
+	}
+
+	operation := ctx.Operations.Poll(ctx, ctx.KubevirtMachine, commandOperationPrefix+name, func(operationCtx gocontext.Context) (interface{}, error) {
+		ctx.Logger.Info("Running command inside the VM", "command", name)
+		return r.GuestAgent.Run(operationCtx, restConfig, vmNamespace, ctx.KubevirtMachine.Name, command)
+	})
+	operations.SetRecord(&ctx.KubevirtMachine.Status.Operations, operation)
+	if !operation.Done() {
+		return nil
+	}
+
+	delete(ctx.KubevirtMachine.Annotations, infrav1.RunCommandAnnotation)
+	data := map[string]string{
+		"command": name,
+		"time":    operation.StartTime.UTC().Format(time.RFC3339),
+	}
+
+	if operation.Err != nil {
+		data["error"] = operation.Err.Error()
+		r.recordCommandEvent(ctx, corev1.EventTypeWarning, commandFailedReason, fmt.Sprintf("Command %q failed: %v", name, operation.Err))
+	} else {
+		result := operation.Result.(*guestagent.Result)
+		data["exitCode"] = strconv.Itoa(result.ExitCode)
+		data["stdout"] = truncateOutput(result.Stdout)
+		data["stderr"] = truncateOutput(result.Stderr)
+		if result.ExitCode == 0 {
+			r.recordCommandEvent(ctx, corev1.EventTypeNormal, commandSucceededReason, fmt.Sprintf("Command %q succeeded", name))
+		} else {
+			r.recordCommandEvent(ctx, corev1.EventTypeWarning, commandFailedReason, fmt.Sprintf("Command %q exited with code %d", name, result.ExitCode))
+		}
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s", ctx.KubevirtMachine.Name, name),
+			Namespace: ctx.KubevirtMachine.Namespace,
+		},
+		Data: data,
+	}
+	if err := controllerutil.SetOwnerReference(ctx.KubevirtMachine, configMap, r.Client.Scheme()); err != nil {
+		return err
+	}
+
+	// The result of a previous run of the same command is overwritten
+	err = r.Client.Create(ctx, configMap)
+	if apierrors.IsAlreadyExists(err) {
+		err = r.Client.Update(ctx, configMap)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to store the result of command %q", name)
+	}
+
+	return nil
+}
+
+func (r *KubevirtMachineReconciler) recordCommandEvent(ctx *context.MachineContext, eventType, reason, message string) {
+	if r.Recorder != nil {
+		r.Recorder.Event(ctx.KubevirtMachine, eventType, reason, message)
+	}
+}
+
+// truncateOutput keeps the end of the output, where errors usually are.
+func truncateOutput(output string) string {
+	if len(output) <= maxCommandOutputSize {
+		return output
+	}
+	return output[len(output)-maxCommandOutputSize:]
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
+)
+
+// gpuStatusCheckInterval is the interval between two reads of the GPUs assigned to a VMI staying on the same node.
+const gpuStatusCheckInterval = 10 * time.Minute
+
+// reconcileGPUStatus reports the GPUs of the machine assigned to its VMI, with the UUIDs of their mediated devices
+// read from the virt-launcher pod of the VMI. They are read again when the VMI moves to another node, a mediated
+// device was not assigned yet, or after gpuStatusCheckInterval. The failures to read them are not fatal.
+func (r *KubevirtMachineReconciler) reconcileGPUStatus(ctx *context.MachineContext, vmNamespace string, now time.Time) error {
+	status := &ctx.KubevirtMachine.Status
+	if len(ctx.KubevirtMachine.Spec.GPUs) == 0 {
+		status.GPUs = nil
+		return nil
+	}
+	vmi := status.VirtualMachineInstance
+	if r.GuestAgent == nil || vmi == nil || vmi.NodeName == "" {
+		return nil
+	}
+	if previous := status.GPUs; previous != nil && previous.NodeName == vmi.NodeName && !missingMediatedDevices(ctx, previous) &&
+		now.Sub(previous.CheckTime.Time) < gpuStatusCheckInterval {
+		return nil
+	}
+
+	restConfig, _, err := r.InfraCluster.GenerateInfraClusterRestConfig(ctx.KubevirtMachine.Spec.InfraClusterSecretRef, ctx.KubevirtMachine.Namespace, ctx.Context)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate infra cluster config")
+	}
+
+	// The virt-launcher pod may be replaced by a migration, the GPUs are read again at the next reconcile
+	uuids, err := r.GuestAgent.MediatedDevices(ctx, restConfig, vmNamespace, ctx.KubevirtMachine.Name)
+	if err != nil {
+		ctx.Logger.V(4).Info("Failed to read the mediated devices of the VMI", "error", err.Error())
+		return nil
+	}
+
+	gpus := &infrav1.GPUStatus{NodeName: vmi.NodeName, CheckTime: metav1.Time{Time: now}}
+	for _, gpu := range ctx.KubevirtMachine.Spec.GPUs {
+		gpus.Devices = append(gpus.Devices, infrav1.AssignedGPU{
+			Name:               gpu.Name,
+			DeviceName:         kubevirt.GPUDeviceName(gpu, ctx.KubevirtCluster.Status.Infra),
+			MediatedDeviceUUID: uuids[gpu.Name],
+		})
+	}
+	status.GPUs = gpus
+	return nil
+}
+
+// missingMediatedDevices returns true if a mediated device of the machine has no UUID in the reported GPUs.
+func missingMediatedDevices(ctx *context.MachineContext, gpus *infrav1.GPUStatus) bool {
+	for _, gpu := range ctx.KubevirtMachine.Spec.GPUs {
+		if gpu.MediatedDeviceType == "" {
+			continue
+		}
+		assigned := false
+		for _, device := range gpus.Devices {
+			assigned = assigned || (device.Name == gpu.Name && device.MediatedDeviceUUID != "")
+		}
+		if !assigned {
+			return true
+		}
+	}
+	return false
+}
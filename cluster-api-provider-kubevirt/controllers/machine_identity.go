@@ -0,0 +1,175 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/machineidentity"
+)
+
+// machineIdentityDir is the directory of the VMs the machine certificate, its key and the CA certificate are
+// written to.
+const machineIdentityDir = "/etc/capk/identity"
+
+// machineIdentitySecretName returns the name of the secret holding the certificate of the KubevirtMachine.
+func machineIdentitySecretName(kubevirtMachine *infrav1.KubevirtMachine) string {
+	return kubevirtMachine.Name + "-identity"
+}
+
+// reconcileMachineIdentity returns the secret holding the client certificate of the machine, or nil if the
+// cluster does not enable machine identities. The certificate is issued when missing, and renewed when it is
+// about to expire until the machine is provisioned; past that, it is only delivered again to a new VM.
+func (r *KubevirtMachineReconciler) reconcileMachineIdentity(ctx *context.MachineContext) (*corev1.Secret, error) {
+	spec := ctx.KubevirtCluster.Spec.MachineIdentity
+	if spec == nil {
+		conditions.Delete(ctx.KubevirtMachine, infrav1.MachineIdentityCertificateCondition)
+		return nil, nil
+	}
+
+	validity := infrav1.DefaultMachineIdentityValidity
+	if spec.Validity != nil {
+		validity = spec.Validity.Duration
+	}
+	renewBefore := infrav1.DefaultMachineIdentityRenewBefore
+	if spec.RenewBefore != nil {
+		renewBefore = spec.RenewBefore.Duration
+	}
+
+	secret := &corev1.Secret{}
+	key := client.ObjectKey{Namespace: ctx.KubevirtMachine.Namespace, Name: machineIdentitySecretName(ctx.KubevirtMachine)}
+	if err := r.Client.Get(ctx, key, secret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		secret = nil
+	}
+
+	var notAfter time.Time
+	issue := secret == nil
+	if !issue {
+		var err error
+		if notAfter, err = machineidentity.NotAfter(secret.Data[corev1.TLSCertKey]); err != nil {
+			issue = true
+		} else if ctx.KubevirtMachine.Spec.ProviderID == nil && time.Until(notAfter) < renewBefore {
+			issue = true
+		}
+	}
+
+	if issue {
+		var err error
+		if secret, notAfter, err = r.issueMachineIdentity(ctx, spec.CASecretName, validity); err != nil {
+			conditions.MarkFalse(ctx.KubevirtMachine, infrav1.MachineIdentityCertificateCondition, infrav1.MachineIdentityIssueFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return nil, err
+		}
+		ctx.Logger.Info("Issued machine identity certificate", "notAfter", notAfter)
+	}
+
+	switch remaining := time.Until(notAfter); {
+	case remaining <= 0:
+		conditions.MarkFalse(ctx.KubevirtMachine, infrav1.MachineIdentityCertificateCondition, infrav1.MachineIdentityExpiredReason, clusterv1.ConditionSeverityError,
+			"The certificate expired at %s, replace the machine to rotate it", notAfter.UTC().Format(time.RFC3339))
+	case remaining < renewBefore:
+		conditions.MarkFalse(ctx.KubevirtMachine, infrav1.MachineIdentityCertificateCondition, infrav1.MachineIdentityExpiringReason, clusterv1.ConditionSeverityWarning,
+			"The certificate expires at %s, replace the machine to rotate it", notAfter.UTC().Format(time.RFC3339))
+	default:
+		conditions.MarkTrue(ctx.KubevirtMachine, infrav1.MachineIdentityCertificateCondition)
+	}
+
+	return secret, nil
+}
+
+// issueMachineIdentity signs a new certificate for the machine with the CA of the cluster, and stores it in a
+// secret owned by the KubevirtMachine.
+func (r *KubevirtMachineReconciler) issueMachineIdentity(ctx *context.MachineContext, caSecretName string, validity time.Duration) (*corev1.Secret, time.Time, error) {
+	caSecret := &corev1.Secret{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: ctx.KubevirtCluster.Namespace, Name: caSecretName}, caSecret); err != nil {
+		return nil, time.Time{}, errors.Wrapf(err, "failed to get the CA secret %s", caSecretName)
+	}
+	ca, err := machineidentity.LoadCA(caSecret)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	certPEM, keyPEM, err := ca.Issue(ctx.KubevirtMachine.Name, ctx.Cluster.Name, validity)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	notAfter, err := machineidentity.NotAfter(certPEM)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: ctx.KubevirtMachine.Namespace,
+			Name:      machineIdentitySecretName(ctx.KubevirtMachine),
+		},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		if secret.Labels == nil {
+			secret.Labels = map[string]string{}
+		}
+		secret.Labels[clusterv1.ClusterNameLabel] = ctx.Cluster.Name
+		secret.Type = corev1.SecretTypeTLS
+		secret.Data = map[string][]byte{
+			corev1.TLSCertKey:              certPEM,
+			corev1.TLSPrivateKeyKey:        keyPEM,
+			corev1.ServiceAccountRootCAKey: ca.CertificatePEM,
+		}
+		return controllerutil.SetControllerReference(ctx.KubevirtMachine, secret, r.Client.Scheme())
+	}); err != nil {
+		return nil, time.Time{}, errors.Wrap(err, "failed to store the machine certificate")
+	}
+
+	return secret, notAfter, nil
+}
+
+// addMachineIdentityToCloudInitConfig adds the machine certificate, its key and the CA certificate to the machine
+// cloud-init bootstrap user-data. If the user-data is not the expected cloud-init config, then returns the latter
+// content as-is. The returned boolean indicates whether the userdata was modified or not.
+func addMachineIdentityToCloudInitConfig(userdata []byte, identity map[string][]byte) ([]byte, bool, error) {
+	root, data, err := parseCloudConfig(userdata)
+	if err != nil || data == nil {
+		return userdata, false, err
+	}
+
+	files := []cloudInitFile{
+		{Path: machineIdentityDir + "/" + corev1.TLSCertKey, Owner: "root:root", Permissions: "0644", Content: string(identity[corev1.TLSCertKey])},
+		{Path: machineIdentityDir + "/" + corev1.TLSPrivateKeyKey, Owner: "root:root", Permissions: "0600", Content: string(identity[corev1.TLSPrivateKeyKey])},
+		{Path: machineIdentityDir + "/" + corev1.ServiceAccountRootCAKey, Owner: "root:root", Permissions: "0644", Content: string(identity[corev1.ServiceAccountRootCAKey])},
+	}
+	if err := appendCloudInitFiles(data, files); err != nil {
+		return nil, false, fmt.Errorf("failed to render machine identity files as valid yaml: %w", err)
+	}
+
+	ud, err := yaml.Marshal(root)
+	return ud, true, err
+}
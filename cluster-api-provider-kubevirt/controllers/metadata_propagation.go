@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	gocontext "context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/resources"
+)
+
+// reconcileVMMetadata propagates the labels and annotations of the Cluster onto the existing VM of the machine, the
+// new VMs getting them on creation. The VMI template is left as is, so that the running VMI is not affected.
+func (r *KubevirtMachineReconciler) reconcileVMMetadata(ctx *context.MachineContext, infraClusterClient client.Client, vmNamespace string) error {
+	if ctx.KubevirtCluster.Spec.MetadataPropagation == nil {
+		return nil
+	}
+
+	vm := &kubevirtv1.VirtualMachine{}
+	if err := infraClusterClient.Get(ctx, client.ObjectKey{Namespace: vmNamespace, Name: ctx.KubevirtMachine.Name}, vm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to fetch VM %s/%s", vmNamespace, ctx.KubevirtMachine.Name)
+	}
+	return propagateMetadata(ctx, infraClusterClient, "VM", vm, ctx.Cluster, ctx.KubevirtCluster)
+}
+
+// reconcileServiceMetadata propagates the labels and annotations of the Cluster onto the existing Services
+// publishing the control plane endpoint of the cluster, the new Services getting them on creation.
+func (r *KubevirtClusterReconciler) reconcileServiceMetadata(ctx *context.ClusterContext, infraClusterClient client.Client, namespace string, names ...string) error {
+	if ctx.KubevirtCluster.Spec.MetadataPropagation == nil {
+		return nil
+	}
+
+	for _, name := range names {
+		service := &corev1.Service{}
+		if err := infraClusterClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, service); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return errors.Wrapf(err, "failed to fetch Service %s/%s", namespace, name)
+		}
+		if err := propagateMetadata(ctx, infraClusterClient, "Service", service, ctx.Cluster, ctx.KubevirtCluster); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// propagateMetadata patches the object with the labels and annotations of the Cluster it is missing.
+func propagateMetadata(ctx gocontext.Context, c client.Client, kind string, object client.Object, cluster *clusterv1.Cluster, kubevirtCluster *infrav1.KubevirtCluster) error {
+	original := object.DeepCopyObject().(client.Object)
+	if !resources.PropagateMetadata(object, cluster, kubevirtCluster) {
+		return nil
+	}
+	if err := c.Patch(ctx, object, client.MergeFrom(original)); err != nil {
+		return errors.Wrapf(err, "failed to propagate the metadata of cluster %s to %s %s/%s", cluster.Name, kind, object.GetNamespace(), object.GetName())
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
+)
+
+// +kubebuilder:rbac:groups=migrations.kubevirt.io,resources=migrationpolicies,verbs=get;create;update;delete
+// +kubebuilder:rbac:groups=kubevirt.io,resources=virtualmachineinstancemigrations,verbs=list
+
+// reconcileMigrationPolicy creates or updates the MigrationPolicy of the infra cluster applying the migration policy
+// of the cluster to its VMs, and deletes it once the migration policy is removed.
+func (r *KubevirtClusterReconciler) reconcileMigrationPolicy(ctx *context.ClusterContext, infraClusterClient client.Client) error {
+	kubevirtCluster := ctx.KubevirtCluster
+	if kubevirtCluster.Spec.MigrationPolicy == nil {
+		return r.deleteMigrationPolicy(ctx, infraClusterClient)
+	}
+
+	name := kubevirt.MigrationPolicyName("KubevirtCluster", kubevirtCluster.Namespace, kubevirtCluster.Name)
+	labels := map[string]string{clusterv1.ClusterNameLabel: ctx.Cluster.Name}
+	if err := kubevirt.ReconcileMigrationPolicy(ctx, infraClusterClient, name, kubevirtCluster.Spec.MigrationPolicy, labels); err != nil {
+		return err
+	}
+	kubevirtCluster.Status.MigrationPolicy = name
+	return nil
+}
+
+// deleteMigrationPolicy deletes the MigrationPolicy of the infra cluster created for the cluster, if any.
+func (r *KubevirtClusterReconciler) deleteMigrationPolicy(ctx *context.ClusterContext, infraClusterClient client.Client) error {
+	if ctx.KubevirtCluster.Status.MigrationPolicy == "" {
+		return nil
+	}
+	if err := kubevirt.DeleteMigrationPolicy(ctx, infraClusterClient, ctx.KubevirtCluster.Status.MigrationPolicy); err != nil {
+		return err
+	}
+	ctx.KubevirtCluster.Status.MigrationPolicy = ""
+	return nil
+}
+
+// reconcileMachineMigrationPolicy creates or updates the MigrationPolicy of the infra cluster of a machine
+// overriding the migration policy of its cluster. The VMs of the other machines are selected by the MigrationPolicy
+// of their cluster.
+func (r *KubevirtMachineReconciler) reconcileMachineMigrationPolicy(ctx *context.MachineContext, infraClusterClient client.Client) error {
+	kubevirtMachine := ctx.KubevirtMachine
+	if kubevirtMachine.Spec.MigrationPolicy == nil && kubevirtMachine.Status.MigrationPolicy == "" {
+		return nil
+	}
+
+	name, spec := kubevirt.MachineMigrationPolicy(ctx)
+	labels := map[string]string{
+		clusterv1.ClusterNameLabel:       ctx.Cluster.Name,
+		infrav1.KubevirtMachineNameLabel: kubevirtMachine.Name,
+	}
+	if err := kubevirt.ReconcileMigrationPolicy(ctx, infraClusterClient, name, spec, labels); err != nil {
+		return err
+	}
+	kubevirtMachine.Status.MigrationPolicy = name
+	return nil
+}
+
+// deleteMachineMigrationPolicy deletes the MigrationPolicy of the infra cluster created for the machine, if any.
+func (r *KubevirtMachineReconciler) deleteMachineMigrationPolicy(ctx *context.MachineContext, infraClusterClient client.Client) error {
+	if ctx.KubevirtMachine.Status.MigrationPolicy == "" {
+		return nil
+	}
+	if err := kubevirt.DeleteMigrationPolicy(ctx, infraClusterClient, ctx.KubevirtMachine.Status.MigrationPolicy); err != nil {
+		return err
+	}
+	ctx.KubevirtMachine.Status.MigrationPolicy = ""
+	return nil
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
+)
+
+// nestedVirtualizationCheckInterval is the minimum interval between two checks of the infra nodes supporting
+// nested virtualization.
+const nestedVirtualizationCheckInterval = 5 * time.Minute
+
+// reconcileNestedVirtualization reports the infra nodes supporting nested virtualization in the status of the
+// cluster, and flags the failure domains having such nodes. The check is refreshed by the reconciliations of the
+// cluster. The infra credentials of a cluster are commonly restricted to its namespace; the check is skipped when
+// they do not allow to list the nodes.
+func (r *KubevirtClusterReconciler) reconcileNestedVirtualization(ctx *context.ClusterContext, infraClusterClient client.Client) error {
+	status := &ctx.KubevirtCluster.Status
+	now := time.Now()
+	if status.NestedVirtualization != nil && now.Sub(status.NestedVirtualization.LastCheckTime.Time) < nestedVirtualizationCheckInterval {
+		return nil
+	}
+
+	nodes, err := kubevirt.FindNestedVirtualizationNodes(ctx, infraClusterClient)
+	if err != nil {
+		if apierrors.IsForbidden(err) {
+			ctx.Logger.V(4).Info("Not allowed to list the infra nodes, skipping the nested virtualization check")
+			status.NestedVirtualization = nil
+			return nil
+		}
+		return err
+	}
+
+	names := make([]string, 0, len(nodes))
+	zones := sets.New[string]()
+	for _, node := range nodes {
+		names = append(names, node.Name)
+		if zone := node.Labels[corev1.LabelTopologyZone]; zone != "" {
+			zones.Insert(zone)
+		}
+	}
+	status.NestedVirtualization = &infrav1.NestedVirtualizationStatus{
+		Nodes:         names,
+		LastCheckTime: metav1.Time{Time: now},
+	}
+
+	for name, failureDomain := range status.FailureDomains {
+		if failureDomain.Attributes == nil {
+			failureDomain.Attributes = map[string]string{}
+		}
+		if zones.Has(name) {
+			failureDomain.Attributes[infrav1.NestedVirtualizationAttribute] = "true"
+		} else {
+			delete(failureDomain.Attributes, infrav1.NestedVirtualizationAttribute)
+		}
+		status.FailureDomains[name] = failureDomain
+	}
+
+	return nil
+}
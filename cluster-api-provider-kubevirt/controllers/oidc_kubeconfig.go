@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/oidckubeconfig"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/workloadcluster"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/workloadclient"
+)
+
+// reconcileOIDCKubeconfig stores the OIDC kubeconfig of the end users of the cluster in its distribution secret, or
+// deletes the secret when the cluster no longer requests it. The kubeconfig reaches the external endpoint of the
+// control plane when published, and is generated once the admin kubeconfig exists.
+func (r *KubevirtClusterReconciler) reconcileOIDCKubeconfig(ctx *context.ClusterContext) (ctrl.Result, error) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: ctx.KubevirtCluster.Namespace,
+			Name:      oidckubeconfig.SecretName(ctx.Cluster.Name),
+		},
+	}
+
+	spec := ctx.KubevirtCluster.Spec.OIDCKubeconfig
+	if spec == nil {
+		if err := r.Client.Get(ctx, client.ObjectKeyFromObject(secret), secret); err != nil {
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+		if !metav1.IsControlledBy(secret, ctx.KubevirtCluster) {
+			return ctrl.Result{}, nil
+		}
+		if err := r.Client.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to delete the OIDC kubeconfig secret %s", secret.Name)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	adminKubeconfig, err := workloadcluster.Kubeconfig(ctx, r.Client)
+	if err != nil {
+		if apierrors.IsNotFound(errors.Cause(err)) {
+			ctx.Logger.V(4).Info("Waiting for the admin kubeconfig to generate the OIDC kubeconfig")
+			return ctrl.Result{RequeueAfter: 20 * time.Second}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	server := ""
+	if endpoint := ctx.KubevirtCluster.Status.ExternalControlPlaneEndpoint; endpoint != nil {
+		server = "https://" + net.JoinHostPort(endpoint.Host, strconv.Itoa(endpoint.Port))
+	}
+	kubeconfig, err := oidckubeconfig.Generate(adminKubeconfig, ctx.Cluster.Name, server, spec)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		if secret.Labels == nil {
+			secret.Labels = map[string]string{}
+		}
+		secret.Labels[clusterv1.ClusterNameLabel] = ctx.Cluster.Name
+		secret.Data = map[string][]byte{workloadclient.KubeconfigSecretKey: kubeconfig}
+		return controllerutil.SetControllerReference(ctx.KubevirtCluster, secret, r.Client.Scheme())
+	}); err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to store the OIDC kubeconfig in secret %s", secret.Name)
+	}
+	return ctrl.Result{}, nil
+}
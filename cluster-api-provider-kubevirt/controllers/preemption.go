@@ -0,0 +1,155 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
+)
+
+// reclaimCheckInterval is the interval between two checks of a reclaimed machine for the withdrawal of the reclaim
+// request, which is set on the VM in the infra cluster.
+const reclaimCheckInterval = time.Minute
+
+// reconcileReclaim reclaims a preemptible machine whose VM is requested to be reclaimed: its Node is tainted and
+// drained, then its VM stopped. The VM is started again, and the Node untainted, once the request is withdrawn. It
+// returns true while the machine is reclaimed, in which case the reconciliation of the machine stops there.
+func (r *KubevirtMachineReconciler) reconcileReclaim(ctx *context.MachineContext, infraClusterClient client.Client, vmNamespace string) (ctrl.Result, bool, error) {
+	if ctx.KubevirtMachine.Spec.Preemptible == nil {
+		return ctrl.Result{}, false, nil
+	}
+
+	vmKey := client.ObjectKey{Namespace: vmNamespace, Name: ctx.KubevirtMachine.Name}
+	vm := &kubevirtv1.VirtualMachine{}
+	if err := infraClusterClient.Get(ctx, vmKey, vm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, false, nil
+		}
+		return ctrl.Result{}, false, errors.Wrapf(err, "failed to fetch VM %s", vmKey)
+	}
+	vmi := &kubevirtv1.VirtualMachineInstance{}
+	if err := infraClusterClient.Get(ctx, vmKey, vmi); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, false, errors.Wrapf(err, "failed to fetch VMI %s", vmKey)
+		}
+		vmi = nil
+	}
+
+	reason := conditions.GetReason(ctx.KubevirtMachine, infrav1.VMProvisionedCondition)
+	if !kubevirt.IsReclaimRequested(vm, vmi) {
+		if reason != infrav1.ReclaimingReason && reason != infrav1.ReclaimedReason {
+			return ctrl.Result{}, false, nil
+		}
+		return r.endReclaim(ctx, infraClusterClient, vm)
+	}
+
+	if err := kubevirt.RequestReclaim(ctx, infraClusterClient, vm); err != nil {
+		return ctrl.Result{}, false, err
+	}
+	ctx.KubevirtMachine.Status.Ready = false
+
+	if !kubevirt.IsHibernated(vm) {
+		conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.ReclaimingReason, clusterv1.ConditionSeverityInfo, "")
+		if retryDuration, err := r.drainReclaimedNode(ctx); err != nil || retryDuration > 0 {
+			return ctrl.Result{RequeueAfter: retryDuration}, true, err
+		}
+		ctx.Logger.Info("Stopping the VM of the reclaimed machine...")
+	}
+
+	stopped, err := kubevirt.HibernateVirtualMachine(ctx, infraClusterClient, vm)
+	if err != nil {
+		return ctrl.Result{}, true, err
+	}
+	if !stopped {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, true, nil
+	}
+
+	if reason != infrav1.ReclaimedReason {
+		ctx.Logger.Info("Machine reclaimed")
+		if r.Recorder != nil {
+			r.Recorder.Event(ctx.KubevirtMachine, corev1.EventTypeNormal, infrav1.ReclaimedReason, "The VM of the preemptible machine is stopped")
+		}
+	}
+	conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.ReclaimedReason, clusterv1.ConditionSeverityInfo, "")
+	return ctrl.Result{RequeueAfter: reclaimCheckInterval}, true, nil
+}
+
+// drainReclaimedNode taints the Node of the reclaimed machine, if any, so that its pods are not scheduled on it
+// again, and drains it. It returns a non-zero duration when the drain did not complete and has to be retried.
+func (r *KubevirtMachineReconciler) drainReclaimedNode(ctx *context.MachineContext) (time.Duration, error) {
+	if r.WorkloadCluster == nil {
+		return 0, nil
+	}
+	kubeClient, err := r.WorkloadCluster.GenerateWorkloadClusterK8sClient(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to create workload cluster client")
+	}
+	node, err := kubeClient.CoreV1().Nodes().Get(ctx, ctx.KubevirtMachine.Name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return 0, nil
+		}
+		return 0, errors.Wrapf(err, "failed to fetch workload cluster node %s", ctx.KubevirtMachine.Name)
+	}
+
+	if kubevirt.SetReclaimedTaint(node) {
+		if node, err = kubeClient.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{}); err != nil {
+			return 0, errors.Wrapf(err, "failed to taint workload cluster node %s", ctx.KubevirtMachine.Name)
+		}
+	}
+	return kubevirt.DrainNode(ctx, ctx.Logger, ctx.Operations, ctx.KubevirtMachine, kubeClient, node, r.ReadOnly)
+}
+
+// endReclaim starts the VM of a machine whose reclaim request was withdrawn, and untaints and uncordons its Node.
+func (r *KubevirtMachineReconciler) endReclaim(ctx *context.MachineContext, infraClusterClient client.Client, vm *kubevirtv1.VirtualMachine) (ctrl.Result, bool, error) {
+	if err := kubevirt.ResumeVirtualMachine(ctx, infraClusterClient, vm); err != nil {
+		return ctrl.Result{}, false, err
+	}
+
+	if r.WorkloadCluster != nil {
+		kubeClient, err := r.WorkloadCluster.GenerateWorkloadClusterK8sClient(ctx)
+		if err != nil {
+			return ctrl.Result{}, false, errors.Wrap(err, "failed to create workload cluster client")
+		}
+		node, err := kubeClient.CoreV1().Nodes().Get(ctx, ctx.KubevirtMachine.Name, metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, false, errors.Wrapf(err, "failed to fetch workload cluster node %s", ctx.KubevirtMachine.Name)
+		}
+		if err == nil && (kubevirt.RemoveReclaimedTaint(node) || node.Spec.Unschedulable) {
+			node.Spec.Unschedulable = false
+			if _, err := kubeClient.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{}); err != nil {
+				return ctrl.Result{}, false, errors.Wrapf(err, "failed to untaint workload cluster node %s", ctx.KubevirtMachine.Name)
+			}
+		}
+	}
+
+	ctx.Logger.Info("Reclaim withdrawn, starting the VM of the machine")
+	return ctrl.Result{}, false, nil
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/version"
+)
+
+// stampProviderVersion records the provider build reconciling the object in its annotations.
+func stampProviderVersion(obj metav1.Object) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[infrav1.ProviderVersionAnnotation] = version.Get().String()
+	obj.SetAnnotations(annotations)
+}
+
+// providerInfo returns the provider build reported in the status of the KubevirtClusters.
+func providerInfo() *infrav1.ProviderInfo {
+	info := version.Get()
+	return &infrav1.ProviderInfo{
+		Version:   info.GitVersion,
+		Commit:    info.GitCommit,
+		BuildDate: info.BuildDate,
+	}
+}
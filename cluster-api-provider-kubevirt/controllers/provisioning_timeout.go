@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
+)
+
+// provisioningPhaseExpired records the provisioning phase the machine is waiting on, and reports if the machine has
+// been waiting on it for longer than its timeout. Machines whose Node already registered are not provisioning
+// anymore, and never time out.
+func provisioningPhaseExpired(ctx *context.MachineContext, phase infrav1.ProvisioningPhase, now time.Time) bool {
+	if ctx.Machine == nil || ctx.Machine.Status.NodeRef != nil {
+		return false
+	}
+
+	status := &ctx.KubevirtMachine.Status
+	if status.ProvisioningPhase != phase || status.ProvisioningPhaseStartTime == nil {
+		status.ProvisioningPhase = phase
+		status.ProvisioningPhaseStartTime = &metav1.Time{Time: now}
+		return false
+	}
+
+	timeout := provisioningTimeout(ctx.KubevirtMachine.Spec.ProvisioningTimeouts, phase)
+	return timeout != nil && now.Sub(status.ProvisioningPhaseStartTime.Time) > timeout.Duration
+}
+
+// clearProvisioningPhase records that the machine completed its provisioning.
+func clearProvisioningPhase(kubevirtMachine *infrav1.KubevirtMachine) {
+	kubevirtMachine.Status.ProvisioningPhase = ""
+	kubevirtMachine.Status.ProvisioningPhaseStartTime = nil
+}
+
+func provisioningTimeout(timeouts *infrav1.ProvisioningTimeouts, phase infrav1.ProvisioningPhase) *metav1.Duration {
+	if timeouts == nil {
+		return nil
+	}
+	switch phase {
+	case infrav1.DataVolumeImportPhase:
+		return timeouts.DataVolumeImport
+	case infrav1.VMStartPhase:
+		return timeouts.VMStart
+	case infrav1.BootstrapPhase:
+		return timeouts.Bootstrap
+	case infrav1.NodeRegistrationPhase:
+		return timeouts.NodeRegistration
+	}
+	return nil
+}
+
+// reconcileProvisioningTimeout handles a machine that exceeded the timeout of its provisioning phase: its VM is
+// deleted so that it gets created again, or the machine is failed once it ran out of recreations. externalMachine
+// is nil when the VM cannot be recreated.
+func (r *KubevirtMachineReconciler) reconcileProvisioningTimeout(ctx *context.MachineContext, externalMachine kubevirt.MachineInterface) (ctrl.Result, error) {
+	status := &ctx.KubevirtMachine.Status
+	message := fmt.Sprintf("%s phase did not complete within %s", status.ProvisioningPhase,
+		provisioningTimeout(ctx.KubevirtMachine.Spec.ProvisioningTimeouts, status.ProvisioningPhase).Duration)
+	status.Ready = false
+
+	if externalMachine != nil && status.VMRecreations < ctx.KubevirtMachine.Spec.ProvisioningTimeouts.MaxVMRecreations {
+		ctx.Logger.Info("Provisioning timed out, recreating the VM", "phase", status.ProvisioningPhase)
+		if err := externalMachine.Delete(); err != nil {
+			return ctrl.Result{}, errors.Wrap(err, "failed to delete timed out VM")
+		}
+		status.VMRecreations++
+		clearProvisioningPhase(ctx.KubevirtMachine)
+		conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.ProvisioningTimedOutReason, clusterv1.ConditionSeverityWarning,
+			"%s, recreating the VM (%d/%d)", message, status.VMRecreations, ctx.KubevirtMachine.Spec.ProvisioningTimeouts.MaxVMRecreations)
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+
+	ctx.Logger.Info("Provisioning timed out, failing the machine", "phase", status.ProvisioningPhase)
+	failureErr := capierrors.CreateMachineError
+	status.FailureReason = &failureErr
+	status.FailureMessage = &message
+	conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMProvisionedCondition, infrav1.ProvisioningTimedOutReason, clusterv1.ConditionSeverityError, message)
+	return ctrl.Result{}, nil
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"github.com/pkg/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
+)
+
+// quotaExceeded returns a message if creating the VM of the machine would exceed the quota of its cluster, or an
+// empty string otherwise. The machines of the cluster count against the quota once provisioned, or when they were
+// created before the machine: the oldest machines get their VMs first, whatever the order of their reconciles.
+// The machines being deleted do not count.
+func (r *KubevirtMachineReconciler) quotaExceeded(ctx *context.MachineContext) (string, error) {
+	quota := ctx.KubevirtCluster.Spec.Quota
+	if quota == nil {
+		return "", nil
+	}
+
+	kubevirtMachines := &infrav1.KubevirtMachineList{}
+	if err := r.Client.List(ctx, kubevirtMachines, client.InNamespace(ctx.KubevirtMachine.Namespace), client.MatchingLabels{clusterv1.ClusterNameLabel: ctx.Cluster.Name}); err != nil {
+		return "", errors.Wrap(err, "failed to list the KubevirtMachines of the cluster")
+	}
+
+	var counted []infrav1.KubevirtMachine
+	for _, kubevirtMachine := range kubevirtMachines.Items {
+		if kubevirtMachine.Name == ctx.KubevirtMachine.Name || !kubevirtMachine.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if kubevirtMachine.Spec.ProviderID != nil || createdBefore(&kubevirtMachine, ctx.KubevirtMachine) {
+			counted = append(counted, kubevirtMachine)
+		}
+	}
+	return kubevirt.QuotaExceeded(quota, counted, ctx.KubevirtMachine), nil
+}
+
+// createdBefore returns true if the first machine was created before the second one, or at the same time with a
+// lower name.
+func createdBefore(first, second *infrav1.KubevirtMachine) bool {
+	if !first.CreationTimestamp.Equal(&second.CreationTimestamp) {
+		return first.CreationTimestamp.Before(&second.CreationTimestamp)
+	}
+	return first.Name < second.Name
+}
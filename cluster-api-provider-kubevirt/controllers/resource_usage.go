@@ -0,0 +1,128 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
+)
+
+// resourceUsageUpdateInterval is the interval between two computations of the resources consumed by the VMs of a
+// cluster.
+const resourceUsageUpdateInterval = 5 * time.Minute
+
+var (
+	clusterVirtualMachines = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "capk_cluster_virtual_machines",
+		Help: "Number of VMs of a KubevirtCluster in the infra cluster, running or not.",
+	}, []string{"namespace", "cluster"})
+
+	clusterCPUCores = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "capk_cluster_cpu_cores",
+		Help: "Number of vCPUs of the running VMs of a KubevirtCluster.",
+	}, []string{"namespace", "cluster"})
+
+	clusterMemoryBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "capk_cluster_memory_bytes",
+		Help: "Guest memory of the running VMs of a KubevirtCluster, in bytes.",
+	}, []string{"namespace", "cluster"})
+
+	clusterStorageBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "capk_cluster_storage_bytes",
+		Help: "Capacity of the PVCs of the disks of the VMs of a KubevirtCluster, in bytes.",
+	}, []string{"namespace", "cluster"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(clusterVirtualMachines, clusterCPUCores, clusterMemoryBytes, clusterStorageBytes)
+}
+
+// reconcileResourceUsage reports the resources of the infra cluster consumed by the VMs of the cluster in the status
+// and the metrics of the cluster. The usage is refreshed by the reconciliations of the cluster.
+func (r *KubevirtClusterReconciler) reconcileResourceUsage(ctx *context.ClusterContext, infraClusterClient client.Client, infraClusterNamespace string) error {
+	status := &ctx.KubevirtCluster.Status
+	now := time.Now()
+	if status.ResourceUsage != nil && !status.ResourceUsage.LastUpdateTime.Time.Before(startTime) && now.Sub(status.ResourceUsage.LastUpdateTime.Time) < resourceUsageUpdateInterval {
+		return nil
+	}
+
+	kubevirtMachines := &infrav1.KubevirtMachineList{}
+	if err := r.Client.List(ctx, kubevirtMachines, client.InNamespace(ctx.KubevirtCluster.Namespace), client.MatchingLabels{clusterv1.ClusterNameLabel: ctx.Cluster.Name}); err != nil {
+		return errors.Wrap(err, "failed to list KubevirtMachines")
+	}
+
+	usage := kubevirt.Usage{}
+	var vms int32
+	for _, kubevirtMachine := range kubevirtMachines.Items {
+		vmNamespace := kubevirtMachine.Spec.VirtualMachineTemplate.ObjectMeta.Namespace
+		if vmNamespace == "" {
+			vmNamespace = infraClusterNamespace
+		}
+
+		vm := &kubevirtv1.VirtualMachine{}
+		if err := infraClusterClient.Get(ctx, client.ObjectKey{Namespace: vmNamespace, Name: kubevirtMachine.Name}, vm); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return errors.Wrapf(err, "failed to fetch VM %s/%s", vmNamespace, kubevirtMachine.Name)
+		}
+
+		vmUsage, err := kubevirt.GetVirtualMachineUsage(ctx, infraClusterClient, vm)
+		if err != nil {
+			return err
+		}
+		usage.Add(vmUsage)
+		vms++
+	}
+
+	status.ResourceUsage = &infrav1.ResourceUsage{
+		VirtualMachines: vms,
+		CPU:             usage.CPU,
+		Memory:          usage.Memory,
+		Storage:         usage.Storage,
+		LastUpdateTime:  metav1.Time{Time: now},
+	}
+
+	namespace, name := ctx.KubevirtCluster.Namespace, ctx.KubevirtCluster.Name
+	clusterVirtualMachines.WithLabelValues(namespace, name).Set(float64(vms))
+	clusterCPUCores.WithLabelValues(namespace, name).Set(usage.CPU.AsApproximateFloat64())
+	clusterMemoryBytes.WithLabelValues(namespace, name).Set(usage.Memory.AsApproximateFloat64())
+	clusterStorageBytes.WithLabelValues(namespace, name).Set(usage.Storage.AsApproximateFloat64())
+
+	return nil
+}
+
+// deleteResourceUsageMetrics removes the metrics reporting the resources consumed by the VMs of the KubevirtCluster.
+func deleteResourceUsageMetrics(kubevirtCluster *infrav1.KubevirtCluster) {
+	labels := prometheus.Labels{"namespace": kubevirtCluster.Namespace, "cluster": kubevirtCluster.Name}
+	clusterVirtualMachines.Delete(labels)
+	clusterCPUCores.Delete(labels)
+	clusterMemoryBytes.Delete(labels)
+	clusterStorageBytes.Delete(labels)
+}
@@ -0,0 +1,297 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/guestagent"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
+)
+
+const (
+	// rebootCheckInterval is the interval between two checks of the VMs of a cluster for a pending reboot.
+	rebootCheckInterval = 10 * time.Minute
+
+	rebootingMachineReason = "RebootingMachine"
+	machineRebootedReason  = "MachineRebooted"
+
+	uncordonPatch = `{"spec":{"unschedulable":false}}`
+)
+
+// reconcileRollingReboot reboots the VMs whose guest reports a pending reboot, one machine at a time: the Node of
+// the machine is drained, its VM restarted, and the Node uncordoned once it is ready again. A reboot only starts
+// when all the machines are ready and etcd is healthy. Machines are rebooted by failure domain, so that a failure
+// domain is done before the next one starts.
+func (r *KubevirtClusterReconciler) reconcileRollingReboot(ctx *context.ClusterContext, infraClusterClient client.Client, infraClusterNamespace string) (ctrl.Result, error) {
+	status := &ctx.KubevirtCluster.Status
+	if ctx.KubevirtCluster.Annotations[infrav1.RollingRebootAnnotation] != "true" || r.GuestAgent == nil || r.WorkloadCluster == nil {
+		status.PendingReboots = nil
+		status.LastRebootCheckTime = nil
+		return ctrl.Result{}, nil
+	}
+
+	kubevirtMachines := &infrav1.KubevirtMachineList{}
+	if err := r.Client.List(ctx, kubevirtMachines, client.InNamespace(ctx.KubevirtCluster.Namespace), client.MatchingLabels{clusterv1.ClusterNameLabel: ctx.Cluster.Name}); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to list KubevirtMachines")
+	}
+	machines, err := r.getMachinesByInfraName(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Finish the reboot in progress before starting another one
+	for i := range kubevirtMachines.Items {
+		kubevirtMachine := &kubevirtMachines.Items[i]
+		if _, rebooting := kubevirtMachine.Annotations[infrav1.RebootingVMIAnnotation]; rebooting {
+			return r.reconcileMachineReboot(ctx, infraClusterClient, infraClusterNamespace, kubevirtMachine, machines[kubevirtMachine.Name])
+		}
+	}
+
+	now := time.Now()
+	if status.LastRebootCheckTime == nil || now.Sub(status.LastRebootCheckTime.Time) >= rebootCheckInterval {
+		pending, err := r.findPendingReboots(ctx, infraClusterNamespace, kubevirtMachines.Items, machines)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		status.PendingReboots = pending
+		status.LastRebootCheckTime = &metav1.Time{Time: now}
+	}
+	nextCheck := ctrl.Result{RequeueAfter: status.LastRebootCheckTime.Add(rebootCheckInterval).Sub(now)}
+	if len(status.PendingReboots) == 0 {
+		return nextCheck, nil
+	}
+
+	if healthy, reason, err := r.isControlPlaneHealthy(ctx, kubevirtMachines.Items); err != nil {
+		return ctrl.Result{}, err
+	} else if !healthy {
+		ctx.Logger.Info("Waiting for the cluster to be healthy before rebooting the next machine...", "reason", reason)
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+
+	name := status.PendingReboots[0]
+	status.PendingReboots = status.PendingReboots[1:]
+	for i := range kubevirtMachines.Items {
+		kubevirtMachine := &kubevirtMachines.Items[i]
+		if kubevirtMachine.Name == name {
+			return r.startMachineReboot(ctx, infraClusterClient, infraClusterNamespace, kubevirtMachine, machines[name])
+		}
+	}
+
+	// The machine is gone, go on with the next one
+	return ctrl.Result{RequeueAfter: time.Second}, nil
+}
+
+// findPendingReboots returns the names of the ready machines whose guest reports a pending reboot, sorted by
+// failure domain. Machines whose guest agent cannot run the check are skipped.
+func (r *KubevirtClusterReconciler) findPendingReboots(ctx *context.ClusterContext, infraClusterNamespace string, kubevirtMachines []infrav1.KubevirtMachine, machines map[string]*clusterv1.Machine) ([]string, error) {
+	restConfig, _, err := r.InfraCluster.GenerateInfraClusterRestConfig(ctx.KubevirtCluster.Spec.InfraClusterSecretRef, ctx.KubevirtCluster.Namespace, ctx.Context)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate infra cluster config")
+	}
+
+	failureDomain := func(name string) string {
+		if machine := machines[name]; machine != nil && machine.Spec.FailureDomain != nil {
+			return *machine.Spec.FailureDomain
+		}
+		return ""
+	}
+
+	var pending []string
+	for _, kubevirtMachine := range kubevirtMachines {
+		if !kubevirtMachine.Status.Ready || !kubevirt.GuestAgentEnabled(&kubevirtMachine) {
+			continue
+		}
+
+		result, err := r.GuestAgent.Run(ctx, restConfig, machineVMNamespace(&kubevirtMachine, infraClusterNamespace), kubevirtMachine.Name, guestagent.RebootRequiredCommand)
+		if err != nil {
+			ctx.Logger.V(4).Info("Failed to check the VM for a pending reboot", "machine", kubevirtMachine.Name, "error", err.Error())
+			continue
+		}
+		if result.ExitCode == 0 {
+			pending = append(pending, kubevirtMachine.Name)
+		}
+	}
+
+	sort.SliceStable(pending, func(i, j int) bool {
+		if failureDomain(pending[i]) != failureDomain(pending[j]) {
+			return failureDomain(pending[i]) < failureDomain(pending[j])
+		}
+		return pending[i] < pending[j]
+	})
+
+	return pending, nil
+}
+
+// startMachineReboot marks the machine as rebooting with the UID of its current VMI.
+func (r *KubevirtClusterReconciler) startMachineReboot(ctx *context.ClusterContext, infraClusterClient client.Client, infraClusterNamespace string, kubevirtMachine *infrav1.KubevirtMachine, machine *clusterv1.Machine) (ctrl.Result, error) {
+	vmi := &kubevirtv1.VirtualMachineInstance{}
+	vmiKey := client.ObjectKey{Namespace: machineVMNamespace(kubevirtMachine, infraClusterNamespace), Name: kubevirtMachine.Name}
+	if err := infraClusterClient.Get(ctx, vmiKey, vmi); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{RequeueAfter: time.Second}, nil
+		}
+		return ctrl.Result{}, errors.Wrapf(err, "failed to fetch VMI %s", vmiKey)
+	}
+
+	patchBase := client.MergeFrom(kubevirtMachine.DeepCopy())
+	if kubevirtMachine.Annotations == nil {
+		kubevirtMachine.Annotations = map[string]string{}
+	}
+	kubevirtMachine.Annotations[infrav1.RebootingVMIAnnotation] = string(vmi.UID)
+	if err := r.Client.Patch(ctx, kubevirtMachine, patchBase); err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to patch KubevirtMachine %s", kubevirtMachine.Name)
+	}
+
+	ctx.Logger.Info("Rebooting machine", "machine", kubevirtMachine.Name)
+	r.recordRebootEvent(ctx, machine, rebootingMachineReason, fmt.Sprintf("Rebooting machine %s, %d more machines of the cluster pending",
+		kubevirtMachine.Name, len(ctx.KubevirtCluster.Status.PendingReboots)))
+
+	return ctrl.Result{RequeueAfter: time.Second}, nil
+}
+
+// reconcileMachineReboot drains the Node of a rebooting machine and restarts its VM, then uncordons the Node
+// once it is ready again and ends the reboot.
+func (r *KubevirtClusterReconciler) reconcileMachineReboot(ctx *context.ClusterContext, infraClusterClient client.Client, infraClusterNamespace string, kubevirtMachine *infrav1.KubevirtMachine, machine *clusterv1.Machine) (ctrl.Result, error) {
+	previousVMIUID := kubevirtMachine.Annotations[infrav1.RebootingVMIAnnotation]
+	vmKey := client.ObjectKey{Namespace: machineVMNamespace(kubevirtMachine, infraClusterNamespace), Name: kubevirtMachine.Name}
+
+	kubeClient, err := r.WorkloadCluster.GenerateClusterK8sClient(ctx)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to create workload cluster client")
+	}
+	node, err := kubeClient.CoreV1().Nodes().Get(ctx, kubevirtMachine.Name, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to fetch workload cluster node %s", kubevirtMachine.Name)
+	}
+	if apierrors.IsNotFound(err) {
+		node = nil
+	}
+
+	vm := &kubevirtv1.VirtualMachine{}
+	if err := infraClusterClient.Get(ctx, vmKey, vm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return r.endMachineReboot(ctx, kubevirtMachine)
+		}
+		return ctrl.Result{}, errors.Wrapf(err, "failed to fetch VM %s", vmKey)
+	}
+
+	vmi := &kubevirtv1.VirtualMachineInstance{}
+	if err := infraClusterClient.Get(ctx, vmKey, vmi); err != nil && !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to fetch VMI %s", vmKey)
+	}
+	if string(vmi.UID) == previousVMIUID && vmi.DeletionTimestamp == nil {
+		if node != nil {
+			retryDuration, err := kubevirt.DrainNode(ctx, ctx.Logger, r.Operations, kubevirtMachine, kubeClient, node, r.ReadOnly)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if retryDuration > 0 {
+				return ctrl.Result{RequeueAfter: retryDuration}, nil
+			}
+		}
+
+		if _, err := kubevirt.RestartVirtualMachine(ctx, infraClusterClient, vm); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+
+	restarted, err := kubevirt.IsRestarted(ctx, infraClusterClient, vm, previousVMIUID)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !restarted || node == nil || nodeReadyStatus(node) != corev1.ConditionTrue {
+		ctx.Logger.Info("Waiting for the rebooted machine to be ready...", "machine", kubevirtMachine.Name)
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+
+	if node.Spec.Unschedulable {
+		if _, err := kubeClient.CoreV1().Nodes().Patch(ctx, node.Name, types.MergePatchType, []byte(uncordonPatch), metav1.PatchOptions{}); err != nil {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to uncordon workload cluster node %s", node.Name)
+		}
+	}
+
+	r.recordRebootEvent(ctx, machine, machineRebootedReason, fmt.Sprintf("Machine %s rebooted", kubevirtMachine.Name))
+	return r.endMachineReboot(ctx, kubevirtMachine)
+}
+
+func (r *KubevirtClusterReconciler) endMachineReboot(ctx *context.ClusterContext, kubevirtMachine *infrav1.KubevirtMachine) (ctrl.Result, error) {
+	patchBase := client.MergeFrom(kubevirtMachine.DeepCopy())
+	delete(kubevirtMachine.Annotations, infrav1.RebootingVMIAnnotation)
+	if err := r.Client.Patch(ctx, kubevirtMachine, patchBase); err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to patch KubevirtMachine %s", kubevirtMachine.Name)
+	}
+	ctx.Logger.Info("Machine rebooted", "machine", kubevirtMachine.Name)
+
+	return ctrl.Result{RequeueAfter: time.Second}, nil
+}
+
+// recordRebootEvent records the progress of the rolling reboot on the KubevirtCluster, and on the
+// MachineDeployment of the machine, if any.
+func (r *KubevirtClusterReconciler) recordRebootEvent(ctx *context.ClusterContext, machine *clusterv1.Machine, reason, message string) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Event(ctx.KubevirtCluster, corev1.EventTypeNormal, reason, message)
+
+	if machine == nil || machine.Labels[clusterv1.MachineDeploymentNameLabel] == "" {
+		return
+	}
+	machineDeployment := &clusterv1.MachineDeployment{}
+	key := client.ObjectKey{Namespace: machine.Namespace, Name: machine.Labels[clusterv1.MachineDeploymentNameLabel]}
+	if err := r.Client.Get(ctx, key, machineDeployment); err != nil {
+		ctx.Logger.V(4).Info("Failed to fetch the MachineDeployment of the machine", "machineDeployment", key, "error", err.Error())
+		return
+	}
+	r.Recorder.Event(machineDeployment, corev1.EventTypeNormal, reason, message)
+}
+
+// getMachinesByInfraName returns the Machines of the cluster, by name of their KubevirtMachine.
+func (r *KubevirtClusterReconciler) getMachinesByInfraName(ctx *context.ClusterContext) (map[string]*clusterv1.Machine, error) {
+	machines := &clusterv1.MachineList{}
+	if err := r.Client.List(ctx, machines, client.InNamespace(ctx.Cluster.Namespace), client.MatchingLabels{clusterv1.ClusterNameLabel: ctx.Cluster.Name}); err != nil {
+		return nil, errors.Wrap(err, "failed to list Machines")
+	}
+
+	byInfraName := make(map[string]*clusterv1.Machine, len(machines.Items))
+	for i := range machines.Items {
+		byInfraName[machines.Items[i].Spec.InfrastructureRef.Name] = &machines.Items[i]
+	}
+	return byInfraName, nil
+}
+
+func machineVMNamespace(kubevirtMachine *infrav1.KubevirtMachine, infraClusterNamespace string) string {
+	if namespace := kubevirtMachine.Spec.VirtualMachineTemplate.ObjectMeta.Namespace; namespace != "" {
+		return namespace
+	}
+	return infraClusterNamespace
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"github.com/pkg/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+// reconcileSkipRemediation sets the skip-remediation annotation of Cluster API on the Machines of the cluster while
+// reason is not empty, e.g. while the VMs of the cluster are stopped by its hibernation or during the maintenance
+// of the infra, so that the MachineHealthChecks do not delete the machines, and their disks, for their Nodes being
+// not ready. The annotation is removed once reason is empty, from the Machines the controller set it on only.
+func (r *KubevirtClusterReconciler) reconcileSkipRemediation(ctx *context.ClusterContext, reason string) error {
+	machines := &clusterv1.MachineList{}
+	if err := r.Client.List(ctx, machines, client.InNamespace(ctx.Cluster.Namespace), client.MatchingLabels{clusterv1.ClusterNameLabel: ctx.Cluster.Name}); err != nil {
+		return errors.Wrap(err, "failed to list the Machines of the cluster")
+	}
+
+	for i := range machines.Items {
+		machine := &machines.Items[i]
+		_, skipped := machine.Annotations[clusterv1.MachineSkipRemediationAnnotation]
+		current, setByController := machine.Annotations[infrav1.SkipRemediationReasonAnnotation]
+
+		patchBase := client.MergeFrom(machine.DeepCopy())
+		switch {
+		case reason != "" && (!skipped || setByController) && current != reason:
+			if machine.Annotations == nil {
+				machine.Annotations = map[string]string{}
+			}
+			machine.Annotations[clusterv1.MachineSkipRemediationAnnotation] = ""
+			machine.Annotations[infrav1.SkipRemediationReasonAnnotation] = reason
+		case reason == "" && setByController:
+			delete(machine.Annotations, clusterv1.MachineSkipRemediationAnnotation)
+			delete(machine.Annotations, infrav1.SkipRemediationReasonAnnotation)
+		default:
+			continue
+		}
+		if err := r.Client.Patch(ctx, machine, patchBase); err != nil {
+			return errors.Wrapf(err, "failed to patch Machine %s", machine.Name)
+		}
+	}
+	return nil
+}
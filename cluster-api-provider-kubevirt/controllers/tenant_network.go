@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/tenantnetwork"
+)
+
+// reconcileTenantNetwork allocates the subnet of the cluster from the tenant supernet, records it in the status of
+// the cluster, and declares it in the infra SDN.
+func (r *KubevirtClusterReconciler) reconcileTenantNetwork(ctx *context.ClusterContext, infraClusterClient client.Client, infraClusterNamespace string) error {
+	if r.SubnetAllocator == nil {
+		return errors.New("the tenant network of the cluster requires the --tenant-supernet flag of the controller")
+	}
+
+	subnet, err := r.SubnetAllocator.Allocate(ctx, r.Client, ctx.KubevirtCluster)
+	if err != nil {
+		return err
+	}
+	ctx.KubevirtCluster.Status.TenantSubnet = subnet.String()
+
+	network, err := tenantnetwork.NewNetwork(ctx, infraClusterClient, infraClusterNamespace)
+	if err != nil {
+		return err
+	}
+	return network.Reconcile(ctx, subnet)
+}
+
+// deleteTenantNetwork deletes the network object of a deleted cluster, if any, and frees its subnet.
+func (r *KubevirtClusterReconciler) deleteTenantNetwork(ctx *context.ClusterContext, infraClusterClient client.Client, infraClusterNamespace string) error {
+	if ctx.KubevirtCluster.Spec.TenantNetwork == nil {
+		return nil
+	}
+
+	network, err := tenantnetwork.NewNetwork(ctx, infraClusterClient, infraClusterNamespace)
+	if err != nil {
+		return err
+	}
+	if err := network.Delete(ctx); err != nil {
+		return err
+	}
+	if r.SubnetAllocator != nil {
+		r.SubnetAllocator.Release(ctx.KubevirtCluster)
+	}
+	return nil
+}
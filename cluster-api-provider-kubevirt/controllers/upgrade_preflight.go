@@ -0,0 +1,204 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/upgradepreflight"
+)
+
+const (
+	// upgradePreflightRetryInterval is the interval between two runs of the preflight checks while they fail.
+	upgradePreflightRetryInterval = time.Minute
+
+	upgradePreflightPassedReason = "UpgradePreflightPassed"
+	upgradePreflightFailedReason = "UpgradePreflightFailed"
+)
+
+// reconcileUpgradePreflight runs the preflight checks once the version of the control plane differs from the one
+// it runs, and reports the result in the UpgradePreflightPassed condition, holding the creation of the control
+// plane VMs of the new version while it is false. Failed checks run again periodically; passed checks are not run
+// again for the same version, the upgrade in progress disrupting what they check.
+func (r *KubevirtClusterReconciler) reconcileUpgradePreflight(ctx *context.ClusterContext, infraClusterNamespace string) (ctrl.Result, error) {
+	spec := ctx.KubevirtCluster.Spec.UpgradePreflight
+	targetVersion := ""
+	if spec != nil {
+		var err error
+		if targetVersion, err = r.pendingControlPlaneUpgrade(ctx); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if targetVersion == "" {
+		ctx.KubevirtCluster.Status.UpgradePreflight = nil
+		conditions.Delete(ctx.KubevirtCluster, infrav1.UpgradePreflightPassedCondition)
+		return ctrl.Result{}, nil
+	}
+
+	now := time.Now()
+	if status := ctx.KubevirtCluster.Status.UpgradePreflight; status != nil && status.TargetVersion == targetVersion {
+		if conditions.IsTrue(ctx.KubevirtCluster, infrav1.UpgradePreflightPassedCondition) {
+			return ctrl.Result{}, nil
+		}
+		if nextCheck := status.LastCheckTime.Add(upgradePreflightRetryInterval); now.Before(nextCheck) {
+			return ctrl.Result{RequeueAfter: nextCheck.Sub(now)}, nil
+		}
+	}
+
+	ctx.Logger.Info("Running the preflight checks of the control plane upgrade", "version", targetVersion)
+	checks, err := r.runUpgradePreflightChecks(ctx, infraClusterNamespace, spec)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	ctx.KubevirtCluster.Status.UpgradePreflight = &infrav1.UpgradePreflightStatus{
+		TargetVersion: targetVersion,
+		Checks:        checks,
+		LastCheckTime: metav1.Time{Time: now},
+	}
+
+	if upgradepreflight.Passed(checks) {
+		conditions.MarkTrue(ctx.KubevirtCluster, infrav1.UpgradePreflightPassedCondition)
+		r.recordUpgradePreflightEvent(ctx, corev1.EventTypeNormal, upgradePreflightPassedReason,
+			fmt.Sprintf("Preflight checks of the upgrade to %s passed", targetVersion))
+		return ctrl.Result{}, nil
+	}
+
+	failures := upgradepreflight.Failures(checks)
+	conditions.MarkFalse(ctx.KubevirtCluster, infrav1.UpgradePreflightPassedCondition, infrav1.UpgradePreflightFailedReason,
+		clusterv1.ConditionSeverityWarning, failures)
+	r.recordUpgradePreflightEvent(ctx, corev1.EventTypeWarning, upgradePreflightFailedReason,
+		fmt.Sprintf("Preflight checks of the upgrade to %s failed: %s", targetVersion, failures))
+	return ctrl.Result{RequeueAfter: upgradePreflightRetryInterval}, nil
+}
+
+// runUpgradePreflightChecks runs the preflight checks on the control plane machines of the cluster. The checks
+// needing the guest agent or the workload cluster are skipped when the reconciler has none.
+func (r *KubevirtClusterReconciler) runUpgradePreflightChecks(ctx *context.ClusterContext, infraClusterNamespace string, spec *infrav1.UpgradePreflightSpec) ([]infrav1.UpgradePreflightCheck, error) {
+	kubevirtMachines := &infrav1.KubevirtMachineList{}
+	if err := r.Client.List(ctx, kubevirtMachines, client.InNamespace(ctx.KubevirtCluster.Namespace), client.MatchingLabels{clusterv1.ClusterNameLabel: ctx.Cluster.Name}); err != nil {
+		return nil, errors.Wrap(err, "failed to list KubevirtMachines")
+	}
+	machines, err := r.getMachinesByInfraName(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var controlPlaneMachines []infrav1.KubevirtMachine
+	var controlPlaneVMs []types.NamespacedName
+	for _, kubevirtMachine := range kubevirtMachines.Items {
+		if machine := machines[kubevirtMachine.Name]; machine == nil || !util.IsControlPlaneMachine(machine) {
+			continue
+		}
+		controlPlaneMachines = append(controlPlaneMachines, kubevirtMachine)
+		if kubevirtMachine.Status.Ready {
+			controlPlaneVMs = append(controlPlaneVMs, types.NamespacedName{
+				Namespace: machineVMNamespace(&kubevirtMachine, infraClusterNamespace),
+				Name:      kubevirtMachine.Name,
+			})
+		}
+	}
+
+	var checks []infrav1.UpgradePreflightCheck
+	if r.GuestAgent != nil {
+		restConfig, _, err := r.InfraCluster.GenerateInfraClusterRestConfig(ctx.KubevirtCluster.Spec.InfraClusterSecretRef, ctx.KubevirtCluster.Namespace, ctx.Context)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to generate infra cluster config")
+		}
+		checks = append(checks, upgradepreflight.EtcdFreeSpace(ctx, r.GuestAgent, restConfig, controlPlaneVMs, spec.MinEtcdFreeSpace))
+	}
+
+	checks = append(checks,
+		upgradepreflight.KubeVirtVersion(ctx.KubevirtCluster.Status.Infra, spec.MinKubeVirtVersion),
+		upgradepreflight.LiveMigration(controlPlaneMachines),
+	)
+
+	if r.WorkloadCluster != nil {
+		workloadClusterClient, err := r.WorkloadCluster.GenerateClusterClient(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create workload cluster client")
+		}
+		check, err := upgradepreflight.PodDisruptionBudgets(ctx, workloadClusterClient)
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, check)
+	}
+
+	return checks, nil
+}
+
+// pendingControlPlaneUpgrade returns the version the control plane of the cluster is upgraded to, or an empty string
+// when its spec and status report the same version. Control planes not reporting their version are never upgraded.
+func (r *KubevirtClusterReconciler) pendingControlPlaneUpgrade(ctx *context.ClusterContext) (string, error) {
+	controlPlaneRef := ctx.Cluster.Spec.ControlPlaneRef
+	if controlPlaneRef == nil {
+		return "", nil
+	}
+
+	controlPlane := &unstructured.Unstructured{}
+	controlPlane.SetGroupVersionKind(controlPlaneRef.GroupVersionKind())
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: controlPlaneRef.Namespace, Name: controlPlaneRef.Name}, controlPlane); err != nil {
+		return "", errors.Wrapf(err, "failed to fetch control plane %s/%s", controlPlaneRef.Namespace, controlPlaneRef.Name)
+	}
+
+	desired, _, _ := unstructured.NestedString(controlPlane.Object, "spec", "version")
+	current, _, _ := unstructured.NestedString(controlPlane.Object, "status", "version")
+	if desired == "" || current == "" || desired == current {
+		return "", nil
+	}
+	return desired, nil
+}
+
+// heldByUpgradePreflight returns a message describing why the VM of a control plane machine of the version the
+// control plane is upgraded to is not created yet, or an empty string when it can be created.
+func heldByUpgradePreflight(ctx *context.MachineContext) string {
+	status := ctx.KubevirtCluster.Status.UpgradePreflight
+	if status == nil || !util.IsControlPlaneMachine(ctx.Machine) || ctx.Machine.Spec.Version == nil || *ctx.Machine.Spec.Version != status.TargetVersion {
+		return ""
+	}
+
+	passed := conditions.Get(ctx.KubevirtCluster, infrav1.UpgradePreflightPassedCondition)
+	switch {
+	case passed == nil:
+		return fmt.Sprintf("preflight checks of the upgrade to %s not run yet", status.TargetVersion)
+	case passed.Status != corev1.ConditionTrue:
+		return fmt.Sprintf("preflight checks of the upgrade to %s failed: %s", status.TargetVersion, passed.Message)
+	}
+	return ""
+}
+
+// recordUpgradePreflightEvent records the result of the preflight checks on the KubevirtCluster.
+func (r *KubevirtClusterReconciler) recordUpgradePreflightEvent(ctx *context.ClusterContext, eventType, reason, message string) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Event(ctx.KubevirtCluster, eventType, reason, message)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
+)
+
+const (
+	// defaultVMDriftCheckInterval is the interval between two checks of the drift of a VM, unless the
+	// KubevirtCluster sets it.
+	defaultVMDriftCheckInterval = 5 * time.Minute
+
+	// vmDriftRevertedReason is the reason of the events of the VMs whose drift was reverted.
+	vmDriftRevertedReason = "VMDriftReverted"
+)
+
+// vmDriftCheckInterval returns the interval between two checks of the drift of the VMs of the cluster, or 0 when
+// the cluster does not enable the detection of the drift.
+func vmDriftCheckInterval(kubevirtCluster *infrav1.KubevirtCluster) time.Duration {
+	spec := kubevirtCluster.Spec.VMDrift
+	if spec == nil {
+		return 0
+	}
+	if spec.CheckInterval != nil && spec.CheckInterval.Duration > 0 {
+		return spec.CheckInterval.Duration
+	}
+	return defaultVMDriftCheckInterval
+}
+
+// reconcileVMDrift compares the VM with the VM rendered from the machine once the check interval elapsed, and
+// reports the fields changed in the infra cluster in the VMInSync condition. The drifted fields are patched back
+// to their rendered values when the KubevirtCluster requests it.
+func (r *KubevirtMachineReconciler) reconcileVMDrift(ctx *context.MachineContext, externalMachine kubevirt.MachineInterface, now time.Time) error {
+	interval := vmDriftCheckInterval(ctx.KubevirtCluster)
+	if interval == 0 {
+		conditions.Delete(ctx.KubevirtMachine, infrav1.VMInSyncCondition)
+		ctx.KubevirtMachine.Status.VMDriftCheckTime = nil
+		return nil
+	}
+	if last := ctx.KubevirtMachine.Status.VMDriftCheckTime; last != nil && now.Sub(last.Time) < interval {
+		return nil
+	}
+
+	fields := externalMachine.Drift()
+	if len(fields) > 0 && ctx.KubevirtCluster.Spec.VMDrift.Remediation == infrav1.RevertVMDriftRemediation {
+		reverted, err := externalMachine.RevertDrift(ctx.Context)
+		if err != nil {
+			conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMInSyncCondition, infrav1.VMDriftRevertFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return err
+		}
+		ctx.Logger.Info("Reverted the drift of the VM", "fields", reverted)
+		if r.Recorder != nil {
+			r.Recorder.Eventf(ctx.KubevirtMachine, corev1.EventTypeNormal, vmDriftRevertedReason, "Reverted the %s of the VM changed in the infra cluster", strings.Join(reverted, ", "))
+		}
+		fields = nil
+	}
+	ctx.KubevirtMachine.Status.VMDriftCheckTime = &metav1.Time{Time: now}
+
+	if len(fields) == 0 {
+		conditions.MarkTrue(ctx.KubevirtMachine, infrav1.VMInSyncCondition)
+		return nil
+	}
+	message := fmt.Sprintf("The %s of the VM changed in the infra cluster", strings.Join(fields, ", "))
+	ctx.Logger.Info("VM drifted from the KubevirtMachine", "fields", fields)
+	if r.Recorder != nil && !conditions.IsFalse(ctx.KubevirtMachine, infrav1.VMInSyncCondition) {
+		r.Recorder.Event(ctx.KubevirtMachine, corev1.EventTypeWarning, infrav1.VMDriftedReason, message)
+	}
+	conditions.MarkFalse(ctx.KubevirtMachine, infrav1.VMInSyncCondition, infrav1.VMDriftedReason, clusterv1.ConditionSeverityWarning, "%s", message)
+	return nil
+}
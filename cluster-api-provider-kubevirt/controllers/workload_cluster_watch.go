@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	gocontext "context"
+
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/workloadcluster"
+)
+
+// watchWorkloadClusterNodes makes sure the Nodes of the workload cluster of the machine are watched, so that the
+// machine is reconciled as soon as its Node is deleted or changes readiness, instead of at the next resync. The
+// watcher drops the watches of a workload cluster that stops answering; they are started again by the next
+// reconciliation of any of its machines.
+func (r *KubevirtMachineReconciler) watchWorkloadClusterNodes(ctx *context.MachineContext) error {
+	if r.WorkloadClusterWatcher == nil || r.controller == nil {
+		return nil
+	}
+	// The apiserver of the workload cluster is not available before the first control plane node is up
+	if !conditions.IsTrue(ctx.Cluster, clusterv1.ControlPlaneInitializedCondition) {
+		return nil
+	}
+
+	return r.WorkloadClusterWatcher.Watch(ctx.ClusterContext(), workloadcluster.WatchInput{
+		Name:         "kubevirtmachine-watchNodes",
+		Controller:   r.controller,
+		Kind:         &corev1.Node{},
+		EventHandler: handler.EnqueueRequestsFromMapFunc(NodeToKubevirtMachine(ctx.Cluster.Namespace)),
+		Predicates:   []predicate.Predicate{nodeReadinessChanged()},
+	})
+}
+
+// NodeToKubevirtMachine returns a handler.MapFunc enqueueing the KubevirtMachine of a workload cluster Node. Nodes
+// are named after their KubevirtMachine, which lives in the namespace of the cluster.
+func NodeToKubevirtMachine(namespace string) handler.MapFunc {
+	return func(_ gocontext.Context, o client.Object) []ctrl.Request {
+		return []ctrl.Request{{NamespacedName: client.ObjectKey{Namespace: namespace, Name: o.GetName()}}}
+	}
+}
+
+// nodeReadinessChanged filters out the updates of Nodes that change neither their readiness nor their labels,
+// such as the periodic status updates of kubelet.
+func nodeReadinessChanged() predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldNode, ok := e.ObjectOld.(*corev1.Node)
+			if !ok {
+				return false
+			}
+			newNode, ok := e.ObjectNew.(*corev1.Node)
+			if !ok {
+				return false
+			}
+			return nodeReadyStatus(oldNode) != nodeReadyStatus(newNode) || !hasLabels(oldNode, newNode.Labels) ||
+				len(oldNode.Labels) != len(newNode.Labels)
+		},
+	}
+}
+
+func nodeReadyStatus(node *corev1.Node) corev1.ConditionStatus {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status
+		}
+	}
+	return corev1.ConditionUnknown
+}
@@ -0,0 +1,25 @@
+#!/usr/bin/env bash
+
+# Prints the -ldflags stamping the version of the provider into the manager binary.
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+GIT_COMMIT="$(git rev-parse HEAD)"
+
+if [[ -z "$(git status --porcelain 2>/dev/null)" ]]; then
+    GIT_TREE_STATE="clean"
+else
+    GIT_TREE_STATE="dirty"
+fi
+
+GIT_VERSION="${GIT_VERSION:-$(git describe --tags --abbrev=14 --match "v[0-9]*" 2>/dev/null || echo "v0.0.0-${GIT_COMMIT:0:14}")}"
+if [[ "${GIT_TREE_STATE}" == "dirty" ]]; then
+    GIT_VERSION+="-dirty"
+fi
+
+BUILD_DATE="$(date ${SOURCE_DATE_EPOCH:+"--date=@${SOURCE_DATE_EPOCH}"} -u +'%Y-%m-%dT%H:%M:%SZ')"
+
+pkg="sigs.k8s.io/cluster-api-provider-kubevirt/pkg/version"
+echo "-X '${pkg}.gitVersion=${GIT_VERSION}' -X '${pkg}.gitCommit=${GIT_COMMIT}' -X '${pkg}.gitTreeState=${GIT_TREE_STATE}' -X '${pkg}.buildDate=${BUILD_DATE}'"
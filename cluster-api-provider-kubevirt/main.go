@@ -19,18 +19,26 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"math/rand"
+	"net/netip"
 	"os"
 	"time"
 
 	"github.com/spf13/pflag"
 	"k8s.io/apimachinery/pkg/runtime"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	"k8s.io/client-go/tools/leaderelection"
 	cliflag "k8s.io/component-base/cli/flag"
 	"k8s.io/klog/v2"
 	"k8s.io/klog/v2/klogr"
 	kubevirtv1 "kubevirt.io/api/core/v1"
+	exportv1 "kubevirt.io/api/export/v1alpha1"
+	migrationsv1alpha1 "kubevirt.io/api/migrations/v1alpha1"
+	snapshotv1 "kubevirt.io/api/snapshot/v1alpha1"
 	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/feature"
@@ -44,10 +52,19 @@ import (
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/controllers"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/cachewarmup"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/faultinjection"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/guestagent"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/hostinventory"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/infracluster"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/operations"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/permissions"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/tenantnetwork"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/version"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/webhookhandler"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/workloadcluster"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/workloadclient"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -55,14 +72,29 @@ var (
 	setupLog = ctrl.Log.WithName("setup")
 
 	//flags.
-	metricsBindAddr      string
-	enableLeaderElection bool
-	syncPeriod           time.Duration
-	concurrency          int
-	healthAddr           string
-	webhookPort          int
-	webhookCertDir       string
-	watchNamespace       string
+	metricsBindAddr           string
+	enableLeaderElection      bool
+	leaderElectionLease       time.Duration
+	leaderElectionRenew       time.Duration
+	leaderElectionRetry       time.Duration
+	syncPeriod                time.Duration
+	concurrency               int
+	infraCallTimeout          time.Duration
+	operationConcurrency      int
+	healthAddr                string
+	webhookPort               int
+	webhookCertDir            string
+	watchNamespace            string
+	failureInjection          bool
+	tenantSupernet            string
+	tenantPrefixLength        int
+	managementNetworks        []string
+	infraNodePortRange        string
+	bootstrapTokenTTL         time.Duration
+	bootstrapTokenMinValidity time.Duration
+	credentialPluginDir       string
+	readOnly                  bool
+	hostInventoryInterval     time.Duration
 )
 
 func init() {
@@ -77,7 +109,10 @@ func registerScheme() (*runtime.Scheme, error) {
 		infrav1.AddToScheme,
 		clusterv1.AddToScheme,
 		kubevirtv1.AddToScheme,
+		migrationsv1alpha1.AddToScheme,
 		cdiv1.AddToScheme,
+		snapshotv1.AddToScheme,
+		exportv1.AddToScheme,
 		// +kubebuilder:scaffold:scheme
 	} {
 		if err := f(myscheme); err != nil {
@@ -92,8 +127,18 @@ func initFlags(fs *pflag.FlagSet) {
 		"The address the metric endpoint binds to.")
 	fs.IntVar(&concurrency, "concurrency", 10,
 		"The number of machines to process simultaneously")
+	fs.DurationVar(&infraCallTimeout, "infra-call-timeout", 30*time.Second,
+		"The timeout of each call to the infra clusters, e.g. the creations and deletions of VMs and the starts of the commands run by their guest agent, so that an unresponsive infra cluster does not hold the reconciles. Set to 0 to disable it.")
+	fs.IntVar(&operationConcurrency, "operation-concurrency", 10,
+		"The number of long operations, e.g. drains of Nodes and commands run in VMs, to run simultaneously in the background of each controller")
 	fs.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
+	fs.DurationVar(&leaderElectionLease, "leader-elect-lease-duration", 15*time.Second,
+		"The duration the replicas waiting for the lead wait for before taking over the lease of a leader that stopped renewing it, e.g. after a crash. A leader stopping gracefully releases its lease at once.")
+	fs.DurationVar(&leaderElectionRenew, "leader-elect-renew-deadline", 10*time.Second,
+		"The duration the leader retries renewing its lease for before giving the lead up and exiting. Must be less than the lease duration.")
+	fs.DurationVar(&leaderElectionRetry, "leader-elect-retry-period", 2*time.Second,
+		"The interval the replicas try to acquire or renew the lease at. Must be less than the renew deadline.")
 	fs.DurationVar(&syncPeriod, "sync-period", 60*time.Second,
 		"The minimum interval at which watched resources are reconciled (e.g. 15m)")
 	fs.StringVar(&healthAddr, "health-addr", ":9440",
@@ -105,6 +150,33 @@ func initFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&watchNamespace, "namespace", "",
 		"Namespace that the controller watches to reconcile cluster-api objects. If unspecified, the controller watches for cluster-api objects across all namespaces.")
 
+	fs.BoolVar(&failureInjection, "enable-failure-injection", false,
+		"Simulate the failures requested by the clusters with the capk.cluster.x-k8s.io/inject-failures annotation. Only meant for staging environments.")
+
+	fs.StringVar(&tenantSupernet, "tenant-supernet", "",
+		"The supernet the subnets of the clusters requesting a tenant network are allocated from (e.g. 10.128.0.0/14). If unspecified, tenant networks are disabled.")
+	fs.IntVar(&tenantPrefixLength, "tenant-subnet-prefix-length", tenantnetwork.DefaultPrefixLength,
+		"The prefix length of the subnets allocated to the clusters from the tenant supernet.")
+	fs.StringSliceVar(&managementNetworks, "management-networks", nil,
+		"The networks of the management and infra clusters (e.g. 10.0.0.0/16,10.96.0.0/12), which the pod and service CIDRs of the workload clusters must not overlap. The KubevirtClusters of the clusters overlapping them are rejected at creation.")
+
+	fs.StringVar(&infraNodePortRange, "infra-node-port-range", "30000-32767",
+		"The node port range of the infra clusters, i.e. the --service-node-port-range of their API servers. The KubevirtClusters requesting node ports out of it are rejected at creation. Set to an empty string to skip the check.")
+
+	fs.DurationVar(&bootstrapTokenTTL, "bootstrap-token-ttl", 0,
+		"The TTL of the join tokens of the bootstrap provider, e.g. 15m for kubeadm. The machines still pending after half of it request a fresh token from their KubeadmConfig, for bootstrap providers implementing the refresh handshake. Disabled when 0.")
+	fs.DurationVar(&bootstrapTokenMinValidity, "bootstrap-token-min-validity", 5*time.Minute,
+		"The validity the join token of a VM must have left when the VM starts. The start of the VMs whose token would expire sooner is held until the bootstrap provider refreshes it. Set to 0 to never hold the VMs.")
+
+	fs.StringVar(&credentialPluginDir, "credential-plugin-dir", "",
+		"The directory of the exec credential plugins the kubeconfigs of the workload clusters may run, e.g. a mounted volume. If unspecified, the kubeconfigs using exec credential plugins are refused.")
+
+	fs.BoolVar(&readOnly, "read-only", false,
+		"Reconcile the clusters and report their status without writing to the infra and workload clusters: the writes, including the drains of the Nodes, are sent as server-side dry-runs, the commands requested on the machines are left pending, and the finalizers of the deleted objects are kept. Meant for shadow deployments next to the controller managing the clusters.")
+
+	fs.DurationVar(&hostInventoryInterval, "host-inventory-interval", 5*time.Minute,
+		"The interval at which each replica of the controller publishes the network facts of its host, i.e. its interfaces, addresses, offloads and routes to the tenant and management networks, in a ConfigMap of its namespace. Set to 0 to disable the inventory.")
+
 	feature.MutableGates.AddFlag(fs)
 }
 
@@ -118,6 +190,26 @@ func main() {
 
 	ctrl.SetLogger(klogr.New())
 
+	if err := validateLeaderElection(); err != nil {
+		setupLog.Error(err, "invalid leader election flags")
+		os.Exit(1)
+	}
+	if err := validateOperationConcurrency(); err != nil {
+		setupLog.Error(err, "invalid operation concurrency flag")
+		os.Exit(1)
+	}
+	if failureInjection {
+		setupLog.Info("Failure injection is enabled, clusters may request simulated failures")
+		faultinjection.SetEnabled(true)
+	}
+	if readOnly {
+		setupLog.Info("Read-only mode is enabled, the writes to the infra and workload clusters are sent as dry-runs")
+	}
+	if credentialPluginDir != "" {
+		setupLog.Info("Workload kubeconfigs may run the exec credential plugins of the directory", "dir", credentialPluginDir)
+		workloadclient.SetCredentialPluginDir(credentialPluginDir)
+	}
+
 	myscheme, err := registerScheme()
 	if err != nil {
 		setupLog.Error(err, "can't register scheme")
@@ -136,7 +228,12 @@ func main() {
 		Scheme:           myscheme,
 		Metrics:          server.Options{BindAddress: metricsBindAddr},
 		LeaderElection:   enableLeaderElection,
-		LeaderElectionID: "controller-leader-election-capk",
+		LeaderElectionID: leaderElectionID(),
+		LeaseDuration:    &leaderElectionLease,
+		RenewDeadline:    &leaderElectionRenew,
+		RetryPeriod:      &leaderElectionRetry,
+		// The process exits once the manager stopped, after the background operations of the controllers returned
+		LeaderElectionReleaseOnCancel: true,
 		Cache: cache.Options{
 			SyncPeriod:        &syncPeriod,
 			DefaultNamespaces: defaultNamespaces,
@@ -153,41 +250,171 @@ func main() {
 	ctx := ctrl.SetupSignalHandler()
 
 	setupChecks(mgr)
-	setupReconcilers(ctx, mgr)
-	setupWebhooks(mgr)
+	checkInfraCompatibility(ctx, mgr)
+	infraPermissions := checkInfraPermissions(ctx, mgr)
+	subnetAllocator := newSubnetAllocator()
+	setupReconcilers(ctx, mgr, infraPermissions, subnetAllocator)
+	setupWebhooks(mgr, subnetAllocator)
+	setupHostInventory(mgr)
 
 	// +kubebuilder:scaffold:builder
-	setupLog.Info("starting manager")
+	setupLog.Info("starting manager", "version", version.Get().String())
 	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
 }
 
+// validateLeaderElection checks the durations of the leader election, which the leader elector of client-go only
+// rejects once the manager starts.
+func validateLeaderElection() error {
+	if leaderElectionLease <= 0 || leaderElectionRenew <= 0 || leaderElectionRetry <= 0 {
+		return fmt.Errorf("the leader election durations must be positive")
+	}
+	if leaderElectionLease <= leaderElectionRenew {
+		return fmt.Errorf("the lease duration %s must be greater than the renew deadline %s", leaderElectionLease, leaderElectionRenew)
+	}
+	if float64(leaderElectionRenew) <= leaderelection.JitterFactor*float64(leaderElectionRetry) {
+		return fmt.Errorf("the renew deadline %s must be greater than %v times the retry period %s", leaderElectionRenew, leaderelection.JitterFactor, leaderElectionRetry)
+	}
+	return nil
+}
+
+// validateOperationConcurrency checks the number of operations run in the background at once, which the operation
+// trackers cannot start any operation with, or panic on, when it is not positive.
+func validateOperationConcurrency() error {
+	if operationConcurrency <= 0 {
+		return fmt.Errorf("the operation concurrency %d must be positive", operationConcurrency)
+	}
+	return nil
+}
+
+// leaderElectionID returns the name of the leader election lease. The read-only replicas have their own lease, so
+// that a shadow deployment never takes the lead over the replicas managing the clusters.
+func leaderElectionID() string {
+	if readOnly {
+		return "controller-leader-election-capk-read-only"
+	}
+	return "controller-leader-election-capk"
+}
+
 func setupChecks(mgr ctrl.Manager) {
 	if err := mgr.AddReadyzCheck("ping", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to create ready check")
 		os.Exit(1)
 	}
 
+	// Warm the cache of every replica, so that the replica taking the lead over reconciles at once
+	warmer := &cachewarmup.Warmer{
+		Cache: mgr.GetCache(),
+		Objects: []k8sclient.Object{
+			&infrav1.KubevirtCluster{},
+			&infrav1.KubevirtMachine{},
+			&infrav1.KubevirtMachineImage{},
+			&infrav1.KubevirtMachineImageChannel{},
+			&infrav1.KubevirtRemediation{},
+			&clusterv1.Cluster{},
+			&clusterv1.Machine{},
+			&clusterv1.MachineDeployment{},
+			&kubevirtv1.VirtualMachineInstance{},
+		},
+	}
+	if err := mgr.Add(warmer); err != nil {
+		setupLog.Error(err, "unable to warm the cache")
+		os.Exit(1)
+	}
+	if err := mgr.AddReadyzCheck("cache-warmup", warmer.Check); err != nil {
+		setupLog.Error(err, "unable to create ready check")
+		os.Exit(1)
+	}
+
 	if err := mgr.AddHealthzCheck("ping", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to create health check")
 		os.Exit(1)
 	}
 }
 
-func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
+// checkInfraCompatibility warns at startup when the management cluster, the infra cluster of the KubevirtClusters
+// without infra cluster secret, runs versions of KubeVirt and CDI the provider does not support. The infra cluster of
+// each KubevirtCluster is checked again by its reconciliations.
+func checkInfraCompatibility(ctx context.Context, mgr ctrl.Manager) {
+	c, err := k8sclient.New(mgr.GetConfig(), k8sclient.Options{Scheme: mgr.GetScheme()})
+	if err != nil {
+		setupLog.Error(err, "unable to check the versions of KubeVirt and CDI")
+		return
+	}
+	infra, err := kubevirt.DetectInfraStatus(ctx, c)
+	if err != nil {
+		setupLog.Error(err, "unable to check the versions of KubeVirt and CDI")
+		return
+	}
+
+	if message := kubevirt.UnsupportedInfraVersions(infra); message != "" {
+		setupLog.Info("WARNING: the management cluster runs versions of KubeVirt and CDI the provider does not support", "reason", message)
+		return
+	}
+	setupLog.Info("Detected the versions of KubeVirt and CDI of the management cluster", "kubevirt", infra.KubeVirtVersion, "cdi", infra.CDIVersion)
+}
+
+// checkInfraPermissions checks at startup that the controller holds the roles it needs in the watched namespaces of
+// the management cluster, the infra cluster of the KubevirtClusters without infra cluster secret. These clusters
+// report the missing permissions in their InfraPermissionsAvailable condition rather than failing to reconcile.
+func checkInfraPermissions(ctx context.Context, mgr ctrl.Manager) *permissions.Report {
+	clientset, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to check the permissions of the controller")
+		return nil
+	}
+	missing, err := permissions.Missing(ctx, clientset.AuthorizationV1().SelfSubjectAccessReviews(), watchNamespace, permissions.Roles(nil)...)
+	if err != nil {
+		setupLog.Error(err, "unable to check the permissions of the controller")
+		return nil
+	}
+
+	if len(missing) > 0 {
+		setupLog.Info("WARNING: the controller is missing permissions in the management cluster, the clusters using it as infra cluster are not reconciled", "missing", missing)
+	}
+	return &permissions.Report{Missing: missing}
+}
+
+// newSubnetAllocator returns the allocator of the subnets of the tenant networks, or nil if they are disabled.
+func newSubnetAllocator() *tenantnetwork.Allocator {
+	if tenantSupernet == "" {
+		return nil
+	}
+	subnetAllocator, err := tenantnetwork.NewAllocator(tenantSupernet, tenantPrefixLength)
+	if err != nil {
+		setupLog.Error(err, "unable to create the tenant subnet allocator")
+		os.Exit(1)
+	}
+	return subnetAllocator
+}
+
+func setupReconcilers(ctx context.Context, mgr ctrl.Manager, infraPermissions *permissions.Report, subnetAllocator *tenantnetwork.Allocator) {
 	noCachedClient, err := k8sclient.New(mgr.GetConfig(), k8sclient.Options{Scheme: mgr.GetClient().Scheme()})
 	if err != nil {
 		setupLog.Error(err, "unable to create controller; failed to generate no-cached client")
 		os.Exit(1)
 	}
+	infraCluster := infracluster.New(mgr.GetClient(), noCachedClient, mgr.GetConfig(), infraCallTimeout)
+	workloadCluster := workloadcluster.New(mgr.GetClient())
+	if readOnly {
+		infraCluster = infracluster.NewReadOnly(infraCluster)
+		workloadCluster = workloadcluster.NewReadOnly(mgr.GetClient())
+	}
 
 	if err := (&controllers.KubevirtMachineReconciler{
-		Client:          mgr.GetClient(),
-		InfraCluster:    infracluster.New(mgr.GetClient(), noCachedClient),
-		WorkloadCluster: workloadcluster.New(mgr.GetClient()),
-		MachineFactory:  kubevirt.DefaultMachineFactory{},
+		Client:                    mgr.GetClient(),
+		InfraCluster:              infraCluster,
+		WorkloadCluster:           workloadCluster,
+		MachineFactory:            kubevirt.DefaultMachineFactory{},
+		Recorder:                  mgr.GetEventRecorderFor("kubevirtmachine-controller"),
+		GuestAgent:                guestagent.NewRunner(),
+		WorkloadClusterWatcher:    workloadcluster.NewWatcher(mgr.GetClient(), workloadcluster.WatcherOptions{}),
+		BootstrapTokenTTL:         bootstrapTokenTTL,
+		BootstrapTokenMinValidity: bootstrapTokenMinValidity,
+		ReadOnly:                  readOnly,
+		Operations:                operations.NewTracker(operationConcurrency),
 	}).SetupWithManager(ctx, mgr, controller.Options{
 		MaxConcurrentReconciles: concurrency,
 	}); err != nil {
@@ -196,19 +423,203 @@ func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
 	}
 
 	if err := (&controllers.KubevirtClusterReconciler{
-		Client:       mgr.GetClient(),
-		APIReader:    mgr.GetAPIReader(),
-		InfraCluster: infracluster.New(mgr.GetClient(), noCachedClient),
-		Log:          ctrl.Log.WithName("controllers").WithName("KubevirtCluster"),
+		Client:           mgr.GetClient(),
+		APIReader:        mgr.GetAPIReader(),
+		InfraCluster:     infraCluster,
+		Recorder:         mgr.GetEventRecorderFor("kubevirtcluster-controller"),
+		Log:              ctrl.Log.WithName("controllers").WithName("KubevirtCluster"),
+		WorkloadCluster:  workloadCluster,
+		GuestAgent:       guestagent.NewRunner(),
+		SubnetAllocator:  subnetAllocator,
+		InfraPermissions: infraPermissions,
+		Operations:       operations.NewTracker(operationConcurrency),
+		ReadOnly:         readOnly,
 	}).SetupWithManager(ctx, mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "KubevirtCluster")
 		os.Exit(1)
 	}
+
+	if err := (&controllers.KubevirtClusterTenantLoadBalancerReconciler{
+		Client:                 mgr.GetClient(),
+		InfraCluster:           infraCluster,
+		WorkloadCluster:        workloadCluster,
+		Log:                    ctrl.Log.WithName("controllers").WithName("KubevirtClusterTenantLoadBalancer"),
+		WorkloadClusterWatcher: workloadcluster.NewWatcher(mgr.GetClient(), workloadcluster.WatcherOptions{}),
+	}).SetupWithManager(ctx, mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KubevirtClusterTenantLoadBalancer")
+		os.Exit(1)
+	}
+
+	if err := (&controllers.KubevirtRemediationReconciler{
+		Client:       mgr.GetClient(),
+		InfraCluster: infraCluster,
+		Recorder:     mgr.GetEventRecorderFor("kubevirtremediation-controller"),
+		ReadOnly:     readOnly,
+	}).SetupWithManager(ctx, mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KubevirtRemediation")
+		os.Exit(1)
+	}
+
+	if err := (&controllers.KubevirtMachineImageReconciler{
+		Client:       mgr.GetClient(),
+		InfraCluster: infraCluster,
+		ReadOnly:     readOnly,
+	}).SetupWithManager(ctx, mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KubevirtMachineImage")
+		os.Exit(1)
+	}
+
+	if err := (&controllers.KubevirtMachineImageChannelReconciler{
+		Client:   mgr.GetClient(),
+		Recorder: mgr.GetEventRecorderFor("kubevirtmachineimagechannel-controller"),
+		ReadOnly: readOnly,
+	}).SetupWithManager(ctx, mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KubevirtMachineImageChannel")
+		os.Exit(1)
+	}
+
+	if err := (&controllers.KubevirtMachineTemplateReconciler{
+		Client: mgr.GetClient(),
+	}).SetupWithManager(ctx, mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KubevirtMachineTemplate")
+		os.Exit(1)
+	}
+
+	if err := (&controllers.KubevirtClusterSmokeTestReconciler{
+		Client:          mgr.GetClient(),
+		WorkloadCluster: workloadCluster,
+		Log:             ctrl.Log.WithName("controllers").WithName("KubevirtClusterSmokeTest"),
+	}).SetupWithManager(ctx, mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KubevirtClusterSmokeTest")
+		os.Exit(1)
+	}
+
+	if err := (&controllers.KubevirtClusterDNSProbeReconciler{
+		Client:          mgr.GetClient(),
+		WorkloadCluster: workloadCluster,
+		Log:             ctrl.Log.WithName("controllers").WithName("KubevirtClusterDNSProbe"),
+	}).SetupWithManager(ctx, mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KubevirtClusterDNSProbe")
+		os.Exit(1)
+	}
+
+	if err := (&controllers.KubevirtClusterTenantCNIReconciler{
+		Client:          mgr.GetClient(),
+		WorkloadCluster: workloadCluster,
+		Log:             ctrl.Log.WithName("controllers").WithName("KubevirtClusterTenantCNI"),
+	}).SetupWithManager(ctx, mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KubevirtClusterTenantCNI")
+		os.Exit(1)
+	}
+
+	if err := (&controllers.KubevirtClusterCSIReconciler{
+		Client:          mgr.GetClient(),
+		APIReader:       mgr.GetAPIReader(),
+		InfraCluster:    infraCluster,
+		WorkloadCluster: workloadCluster,
+		Recorder:        mgr.GetEventRecorderFor("kubevirtcluster-csi-controller"),
+		Log:             ctrl.Log.WithName("controllers").WithName("KubevirtClusterCSI"),
+		ReadOnly:        readOnly,
+	}).SetupWithManager(ctx, mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KubevirtClusterCSI")
+		os.Exit(1)
+	}
+
+	if err := (&controllers.KubevirtClusterPrewarmReconciler{
+		Client:       mgr.GetClient(),
+		InfraCluster: infraCluster,
+		Log:          ctrl.Log.WithName("controllers").WithName("KubevirtClusterPrewarm"),
+	}).SetupWithManager(ctx, mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KubevirtClusterPrewarm")
+		os.Exit(1)
+	}
+}
+
+// setupHostInventory publishes the network facts of the host of the controller, unless the inventory is disabled. The
+// namespace and the node of the controller are set by the downward API of its Deployment.
+func setupHostInventory(mgr ctrl.Manager) {
+	if hostInventoryInterval == 0 {
+		return
+	}
+	namespace := os.Getenv("POD_NAMESPACE")
+	if namespace == "" {
+		setupLog.Info("The namespace of the controller is unknown, the network inventory of the host is disabled")
+		return
+	}
+	host := os.Getenv("NODE_NAME")
+	if host == "" {
+		var err error
+		if host, err = os.Hostname(); err != nil {
+			setupLog.Error(err, "unable to get the name of the host")
+			os.Exit(1)
+		}
+	}
+
+	networks, err := tenantnetwork.ParsePrefixes(managementNetworks)
+	if err != nil {
+		setupLog.Error(err, "invalid management networks")
+		os.Exit(1)
+	}
+	if tenantSupernet != "" {
+		supernet, err := netip.ParsePrefix(tenantSupernet)
+		if err != nil {
+			setupLog.Error(err, "invalid tenant supernet")
+			os.Exit(1)
+		}
+		networks = append([]netip.Prefix{supernet}, networks...)
+	}
+
+	if err := mgr.Add(&hostinventory.Publisher{
+		Client:    mgr.GetClient(),
+		Reader:    mgr.GetAPIReader(),
+		Log:       ctrl.Log.WithName("host-inventory"),
+		Namespace: namespace,
+		Host:      host,
+		Networks:  networks,
+		Interval:  hostInventoryInterval,
+	}); err != nil {
+		setupLog.Error(err, "unable to publish the network inventory of the host")
+		os.Exit(1)
+	}
 }
 
-func setupWebhooks(mgr ctrl.Manager) {
+func setupWebhooks(mgr ctrl.Manager, subnetAllocator *tenantnetwork.Allocator) {
 	if err := webhookhandler.SetupWebhookWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create webhook", "webhook", "KubevirtMachineTemplate")
 		os.Exit(1)
 	}
+	if err := webhookhandler.SetupProviderDefaultsWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "KubevirtProviderConfig")
+		os.Exit(1)
+	}
+	if err := webhookhandler.SetupKubevirtMachineQuotaWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "KubevirtMachine")
+		os.Exit(1)
+	}
+
+	networks, err := tenantnetwork.ParsePrefixes(managementNetworks)
+	if err != nil {
+		setupLog.Error(err, "invalid management networks")
+		os.Exit(1)
+	}
+	noCachedClient, err := k8sclient.New(mgr.GetConfig(), k8sclient.Options{Scheme: mgr.GetClient().Scheme()})
+	if err != nil {
+		setupLog.Error(err, "unable to create webhook; failed to generate no-cached client")
+		os.Exit(1)
+	}
+	var nodePortRange *utilnet.PortRange
+	if infraNodePortRange != "" {
+		if nodePortRange, err = utilnet.ParsePortRange(infraNodePortRange); err != nil {
+			setupLog.Error(err, "invalid infra node port range")
+			os.Exit(1)
+		}
+	}
+	if err := webhookhandler.SetupKubevirtClusterWebhookWithManager(mgr, infracluster.New(mgr.GetClient(), noCachedClient, mgr.GetConfig(), infraCallTimeout), webhookhandler.NetworkOptions{
+		ManagementNetworks: networks,
+		SubnetAllocator:    subnetAllocator,
+		NodePortRange:      nodePortRange,
+	}); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "KubevirtCluster")
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cachewarmup starts the informers of the objects watched by the controllers in every replica of the
+// provider, leader or not, so that a replica taking the lead reconciles from a synced cache at once rather than
+// lists all the objects first.
+package cachewarmup
+
+import (
+	gocontext "context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Warmer starts the informers of the objects in the cache.
+type Warmer struct {
+	Cache   cache.Cache
+	Objects []client.Object
+
+	warm atomic.Bool
+}
+
+// Start starts the informers of the objects, and waits for them to sync.
+func (w *Warmer) Start(ctx gocontext.Context) error {
+	for _, obj := range w.Objects {
+		if _, err := w.Cache.GetInformer(ctx, obj); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return errors.Wrapf(err, "failed to warm the cache of %T", obj)
+		}
+	}
+	if !w.Cache.WaitForCacheSync(ctx) {
+		return nil
+	}
+	w.warm.Store(true)
+	return nil
+}
+
+// NeedLeaderElection returns false, the caches of the replicas waiting for the lead are warmed too.
+func (w *Warmer) NeedLeaderElection() bool {
+	return false
+}
+
+// Check is the readiness check of the warmup, failing until the cache is warm, so that the rolling updates of the
+// provider wait for the new replicas to be able to take the lead over quickly.
+func (w *Warmer) Check(_ *http.Request) error {
+	if !w.warm.Load() {
+		return fmt.Errorf("the cache of %d types of objects is not warm yet", len(w.Objects))
+	}
+	return nil
+}
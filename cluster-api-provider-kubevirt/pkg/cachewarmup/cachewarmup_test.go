@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cachewarmup
+
+import (
+	gocontext "context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("Cache warmup", func() {
+	var (
+		ctx       = gocontext.Background()
+		informers *informertest.FakeInformers
+		warmer    *Warmer
+	)
+
+	BeforeEach(func() {
+		informers = &informertest.FakeInformers{Scheme: testing.SetupScheme()}
+		warmer = &Warmer{
+			Cache:   informers,
+			Objects: []client.Object{&infrav1.KubevirtCluster{}, &infrav1.KubevirtMachine{}},
+		}
+	})
+
+	It("should start the informers of the objects and become ready once they synced", func() {
+		Expect(warmer.NeedLeaderElection()).To(BeFalse())
+		Expect(warmer.Check(nil)).To(MatchError("the cache of 2 types of objects is not warm yet"))
+
+		Expect(warmer.Start(ctx)).To(Succeed())
+		Expect(informers.InformersByGVK).To(HaveKey(infrav1.GroupVersion.WithKind("KubevirtCluster")))
+		Expect(informers.InformersByGVK).To(HaveKey(infrav1.GroupVersion.WithKind("KubevirtMachine")))
+		Expect(warmer.Check(nil)).To(Succeed())
+	})
+
+	It("should not become ready while the cache is not synced", func() {
+		informers.Synced = new(bool)
+
+		Expect(warmer.Start(ctx)).To(Succeed())
+		Expect(warmer.Check(nil)).To(HaveOccurred())
+	})
+
+	It("should fail when an informer cannot be started", func() {
+		informers.Error = errors.New("no matches for kind")
+
+		Expect(warmer.Start(ctx)).To(MatchError(ContainSubstring("failed to warm the cache of *v1alpha1.KubevirtCluster")))
+		Expect(warmer.Check(nil)).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package v1beta2 computes the conditions of the provider objects following the v1beta2 conventions of Cluster
+// API from their v1beta1 conditions.
+package v1beta2
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+// Setter is an object with both v1beta1 and v1beta2 conditions.
+type Setter interface {
+	conditions.Setter
+	GetV1Beta2Conditions() []metav1.Condition
+	SetV1Beta2Conditions([]metav1.Condition)
+}
+
+// Source is a v1beta1 condition mirrored as a v1beta2 condition.
+type Source struct {
+	// Type is the type of both the v1beta1 and the v1beta2 conditions.
+	Type clusterv1.ConditionType
+
+	// TrueReason is the reason of the v1beta2 condition when it is true, as v1beta1 true conditions have none.
+	TrueReason string
+
+	// Summarized conditions are summarized into Ready when they are reported.
+	Summarized bool
+
+	// Required conditions are summarized into Ready, and are unknown until they are reported.
+	Required bool
+}
+
+// SetFromV1Beta1 sets the v1beta2 conditions of the object: the ones mirroring its v1beta1 conditions, Deleting
+// and Ready, which summarizes the sources marked as such. The mirrored conditions that are not reported anymore
+// are removed.
+func SetFromV1Beta1(obj Setter, sources []Source) {
+	v1beta2Conditions := obj.GetV1Beta2Conditions()
+	generation := obj.GetGeneration()
+
+	var notReady, unknown []string
+	for _, source := range sources {
+		condition, found := mirror(obj, source)
+		if !found {
+			meta.RemoveStatusCondition(&v1beta2Conditions, string(source.Type))
+			if !source.Required {
+				continue
+			}
+		} else {
+			condition.ObservedGeneration = generation
+			meta.SetStatusCondition(&v1beta2Conditions, condition)
+		}
+
+		if !source.Summarized && !source.Required {
+			continue
+		}
+		switch condition.Status {
+		case metav1.ConditionFalse:
+			notReady = append(notReady, summaryLine(condition))
+		case metav1.ConditionUnknown:
+			unknown = append(unknown, summaryLine(condition))
+		}
+	}
+
+	deleting := metav1.Condition{
+		Type:               infrav1.DeletingV1Beta2Condition,
+		Status:             metav1.ConditionFalse,
+		Reason:             infrav1.NotDeletingV1Beta2Reason,
+		ObservedGeneration: generation,
+	}
+	if !obj.GetDeletionTimestamp().IsZero() {
+		deleting.Status = metav1.ConditionTrue
+		deleting.Reason = infrav1.DeletingV1Beta2Reason
+	}
+	meta.SetStatusCondition(&v1beta2Conditions, deleting)
+
+	ready := metav1.Condition{
+		Type:               infrav1.ReadyV1Beta2Condition,
+		Status:             metav1.ConditionTrue,
+		Reason:             infrav1.ReadyV1Beta2Reason,
+		ObservedGeneration: generation,
+	}
+	switch {
+	case deleting.Status == metav1.ConditionTrue:
+		ready.Status = metav1.ConditionFalse
+		ready.Reason = infrav1.DeletingV1Beta2Reason
+		ready.Message = "Object is being deleted"
+	case len(notReady) > 0:
+		ready.Status = metav1.ConditionFalse
+		ready.Reason = infrav1.NotReadyV1Beta2Reason
+		ready.Message = strings.Join(append(notReady, unknown...), "\n")
+	case len(unknown) > 0:
+		ready.Status = metav1.ConditionUnknown
+		ready.Reason = infrav1.ReadyUnknownV1Beta2Reason
+		ready.Message = strings.Join(unknown, "\n")
+	}
+	meta.SetStatusCondition(&v1beta2Conditions, ready)
+
+	obj.SetV1Beta2Conditions(v1beta2Conditions)
+}
+
+// mirror returns the v1beta2 condition mirroring the v1beta1 condition of the source, or an unknown condition and
+// false if the v1beta1 condition is not reported.
+func mirror(obj conditions.Getter, source Source) (metav1.Condition, bool) {
+	v1beta1Condition := conditions.Get(obj, source.Type)
+	if v1beta1Condition == nil {
+		return metav1.Condition{
+			Type:    string(source.Type),
+			Status:  metav1.ConditionUnknown,
+			Reason:  infrav1.NotYetReportedV1Beta2Reason,
+			Message: "Condition not yet reported",
+		}, false
+	}
+
+	condition := metav1.Condition{
+		Type:               string(source.Type),
+		Status:             metav1.ConditionStatus(v1beta1Condition.Status),
+		Reason:             v1beta1Condition.Reason,
+		Message:            v1beta1Condition.Message,
+		LastTransitionTime: v1beta1Condition.LastTransitionTime,
+	}
+	if condition.Status == metav1.ConditionTrue {
+		condition.Reason = source.TrueReason
+	} else if condition.Reason == "" {
+		condition.Reason = infrav1.NotYetReportedV1Beta2Reason
+	}
+	return condition, true
+}
+
+// summaryLine describes a condition preventing Ready from being true.
+func summaryLine(condition metav1.Condition) string {
+	if condition.Message == "" {
+		return fmt.Sprintf("* %s: %s", condition.Type, condition.Reason)
+	}
+	return fmt.Sprintf("* %s: %s", condition.Type, condition.Message)
+}
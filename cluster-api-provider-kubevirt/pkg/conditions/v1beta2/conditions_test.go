@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	v1beta2conditions "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/conditions/v1beta2"
+)
+
+var _ = Describe("SetFromV1Beta1", func() {
+	var kubevirtMachine *infrav1.KubevirtMachine
+
+	sources := []v1beta2conditions.Source{
+		{Type: infrav1.VMProvisionedCondition, TrueReason: infrav1.VMProvisionedV1Beta2Reason, Required: true},
+		{Type: infrav1.BootstrapExecSucceededCondition, TrueReason: infrav1.BootstrapSucceededV1Beta2Reason, Summarized: true},
+		{Type: infrav1.NodeReadyCondition, TrueReason: infrav1.NodeReadyV1Beta2Reason},
+	}
+
+	BeforeEach(func() {
+		kubevirtMachine = &infrav1.KubevirtMachine{ObjectMeta: metav1.ObjectMeta{Name: "test-machine", Generation: 3}}
+	})
+
+	It("should report Ready unknown until the required conditions are reported", func() {
+		v1beta2conditions.SetFromV1Beta1(kubevirtMachine, sources)
+
+		ready := meta.FindStatusCondition(kubevirtMachine.GetV1Beta2Conditions(), infrav1.ReadyV1Beta2Condition)
+		Expect(ready.Status).To(Equal(metav1.ConditionUnknown))
+		Expect(ready.Reason).To(Equal(infrav1.ReadyUnknownV1Beta2Reason))
+		Expect(ready.Message).To(Equal("* VMProvisioned: Condition not yet reported"))
+		Expect(ready.ObservedGeneration).To(Equal(int64(3)))
+		Expect(meta.FindStatusCondition(kubevirtMachine.GetV1Beta2Conditions(), string(infrav1.VMProvisionedCondition))).To(BeNil())
+		Expect(meta.IsStatusConditionFalse(kubevirtMachine.GetV1Beta2Conditions(), infrav1.DeletingV1Beta2Condition)).To(BeTrue())
+	})
+
+	It("should mirror the v1beta1 conditions with a reason and summarize them into Ready", func() {
+		conditions.MarkTrue(kubevirtMachine, infrav1.VMProvisionedCondition)
+		conditions.MarkFalse(kubevirtMachine, infrav1.BootstrapExecSucceededCondition, infrav1.BootstrappingReason, clusterv1.ConditionSeverityInfo, "Waiting for bootstrap")
+		conditions.MarkFalse(kubevirtMachine, infrav1.NodeReadyCondition, "KubeletNotReady", clusterv1.ConditionSeverityWarning, "")
+		v1beta2conditions.SetFromV1Beta1(kubevirtMachine, sources)
+
+		v1beta2Conditions := kubevirtMachine.GetV1Beta2Conditions()
+		provisioned := meta.FindStatusCondition(v1beta2Conditions, string(infrav1.VMProvisionedCondition))
+		Expect(provisioned.Status).To(Equal(metav1.ConditionTrue))
+		Expect(provisioned.Reason).To(Equal(infrav1.VMProvisionedV1Beta2Reason))
+		Expect(provisioned.ObservedGeneration).To(Equal(int64(3)))
+		Expect(meta.FindStatusCondition(v1beta2Conditions, string(infrav1.BootstrapExecSucceededCondition)).Reason).To(Equal(infrav1.BootstrappingReason))
+
+		ready := meta.FindStatusCondition(v1beta2Conditions, infrav1.ReadyV1Beta2Condition)
+		Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+		Expect(ready.Reason).To(Equal(infrav1.NotReadyV1Beta2Reason))
+		Expect(ready.Message).To(Equal("* BootstrapExecSucceeded: Waiting for bootstrap"))
+
+		conditions.MarkTrue(kubevirtMachine, infrav1.BootstrapExecSucceededCondition)
+		v1beta2conditions.SetFromV1Beta1(kubevirtMachine, sources)
+		ready = meta.FindStatusCondition(kubevirtMachine.GetV1Beta2Conditions(), infrav1.ReadyV1Beta2Condition)
+		Expect(ready.Status).To(Equal(metav1.ConditionTrue))
+		Expect(ready.Reason).To(Equal(infrav1.ReadyV1Beta2Reason))
+	})
+
+	It("should remove the conditions that are not reported anymore", func() {
+		conditions.MarkTrue(kubevirtMachine, infrav1.NodeReadyCondition)
+		v1beta2conditions.SetFromV1Beta1(kubevirtMachine, sources)
+		Expect(meta.FindStatusCondition(kubevirtMachine.GetV1Beta2Conditions(), string(infrav1.NodeReadyCondition))).ToNot(BeNil())
+
+		conditions.Delete(kubevirtMachine, infrav1.NodeReadyCondition)
+		v1beta2conditions.SetFromV1Beta1(kubevirtMachine, sources)
+		Expect(meta.FindStatusCondition(kubevirtMachine.GetV1Beta2Conditions(), string(infrav1.NodeReadyCondition))).To(BeNil())
+	})
+
+	It("should report a deleted object as not ready", func() {
+		kubevirtMachine.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+		conditions.MarkTrue(kubevirtMachine, infrav1.VMProvisionedCondition)
+		v1beta2conditions.SetFromV1Beta1(kubevirtMachine, sources)
+
+		Expect(meta.IsStatusConditionTrue(kubevirtMachine.GetV1Beta2Conditions(), infrav1.DeletingV1Beta2Condition)).To(BeTrue())
+		ready := meta.FindStatusCondition(kubevirtMachine.GetV1Beta2Conditions(), infrav1.ReadyV1Beta2Condition)
+		Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+		Expect(ready.Reason).To(Equal(infrav1.DeletingV1Beta2Reason))
+	})
+})
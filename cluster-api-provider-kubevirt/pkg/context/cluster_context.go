@@ -26,6 +26,7 @@ import (
 	"sigs.k8s.io/cluster-api/util/patch"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	v1beta2conditions "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/conditions/v1beta2"
 )
 
 // ClusterContext is a Go context used with a KubeVirt cluster.
@@ -48,9 +49,24 @@ func (c *ClusterContext) PatchKubevirtCluster(patchHelper *patch.Helper) error {
 	conditions.SetSummary(c.KubevirtCluster,
 		conditions.WithConditions(
 			infrav1.LoadBalancerAvailableCondition,
+			infrav1.InfraOwnershipCondition,
 		),
 		conditions.WithStepCounterIf(c.KubevirtCluster.ObjectMeta.DeletionTimestamp.IsZero()),
 	)
+	v1beta2conditions.SetFromV1Beta1(c.KubevirtCluster, []v1beta2conditions.Source{
+		{Type: infrav1.LoadBalancerAvailableCondition, TrueReason: infrav1.LoadBalancerAvailableV1Beta2Reason, Required: true},
+		{Type: infrav1.InfraOwnershipCondition, TrueReason: infrav1.InfraOwnedV1Beta2Reason, Summarized: true},
+		{Type: infrav1.ExternalControlPlaneEndpointAvailableCondition, TrueReason: infrav1.LoadBalancerAvailableV1Beta2Reason},
+		{Type: infrav1.ClusterVerifiedCondition, TrueReason: infrav1.ClusterVerifiedV1Beta2Reason},
+		{Type: infrav1.WorkloadDNSHealthyCondition, TrueReason: infrav1.WorkloadDNSHealthyV1Beta2Reason},
+		{Type: infrav1.CSIDriverAvailableCondition, TrueReason: infrav1.CSIDriverDeployedV1Beta2Reason},
+		{Type: infrav1.ImagesPrewarmedCondition, TrueReason: infrav1.ImagesPrewarmedV1Beta2Reason},
+		{Type: infrav1.UpgradePreflightPassedCondition, TrueReason: infrav1.UpgradePreflightPassedV1Beta2Reason},
+		{Type: infrav1.InfraCompatibleCondition, TrueReason: infrav1.InfraCompatibleV1Beta2Reason},
+		{Type: infrav1.TenantCNICompatibleCondition, TrueReason: infrav1.TenantCNICompatibleV1Beta2Reason},
+		{Type: infrav1.InfraPermissionsAvailableCondition, TrueReason: infrav1.InfraPermissionsAvailableV1Beta2Reason, Summarized: true},
+		{Type: infrav1.HibernationScheduleValidCondition, TrueReason: infrav1.HibernationScheduleValidV1Beta2Reason},
+	})
 
 	// Patch the object, ignoring conflicts on the conditions owned by this controller.
 	return patchHelper.Patch(
@@ -59,6 +75,12 @@ func (c *ClusterContext) PatchKubevirtCluster(patchHelper *patch.Helper) error {
 		patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{
 			clusterv1.ReadyCondition,
 			infrav1.LoadBalancerAvailableCondition,
+			infrav1.InfraOwnershipCondition,
+			infrav1.ExternalControlPlaneEndpointAvailableCondition,
+			infrav1.UpgradePreflightPassedCondition,
+			infrav1.InfraCompatibleCondition,
+			infrav1.InfraPermissionsAvailableCondition,
+			infrav1.HibernationScheduleValidCondition,
 		}},
 	)
 }
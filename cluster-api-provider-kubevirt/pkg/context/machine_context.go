@@ -29,6 +29,8 @@ import (
 	"sigs.k8s.io/cluster-api/util/patch"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	v1beta2conditions "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/conditions/v1beta2"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/operations"
 )
 
 // MachineContext is a Go context used with a KubeVirt machine.
@@ -39,7 +41,15 @@ type MachineContext struct {
 	KubevirtCluster     *infrav1.KubevirtCluster
 	KubevirtMachine     *infrav1.KubevirtMachine
 	BootstrapDataSecret *corev1.Secret
-	Logger              logr.Logger
+	// MachineImage is the KubevirtMachineImage referenced by the KubevirtMachine, once imported.
+	MachineImage *infrav1.KubevirtMachineImage
+	// DataVolumeSourceMachine is the machine whose disks the imported disks of the VM clone, when created in its batch.
+	DataVolumeSourceMachine string
+	// Operations runs the long operations of the machine in the background, or synchronously when nil.
+	Operations *operations.Tracker
+	// ReadOnly sends the drains of the Nodes as server-side dry-runs, for the controller deployed in read-only mode.
+	ReadOnly bool
+	Logger   logr.Logger
 }
 
 // ClusterContext returns cluster context from this machine context
@@ -67,6 +77,16 @@ func (c *MachineContext) PatchKubevirtMachine(patchHelper *patch.Helper) error {
 			infrav1.BootstrapExecSucceededCondition,
 		),
 	)
+	v1beta2conditions.SetFromV1Beta1(c.KubevirtMachine, []v1beta2conditions.Source{
+		{Type: infrav1.VMProvisionedCondition, TrueReason: infrav1.VMProvisionedV1Beta2Reason, Required: true},
+		// The bootstrap is only checked on the machines whose bootstrap data has the ssh keys of the provider injected.
+		{Type: infrav1.BootstrapExecSucceededCondition, TrueReason: infrav1.BootstrapSucceededV1Beta2Reason, Summarized: true},
+		{Type: infrav1.NodeReadyCondition, TrueReason: infrav1.NodeReadyV1Beta2Reason},
+		{Type: infrav1.VMLiveMigratableCondition, TrueReason: infrav1.VMLiveMigratableV1Beta2Reason},
+		{Type: infrav1.MachineIdentityCertificateCondition, TrueReason: infrav1.MachineIdentityValidV1Beta2Reason},
+		{Type: infrav1.VMInSyncCondition, TrueReason: infrav1.VMInSyncV1Beta2Reason},
+		{Type: infrav1.GuestAgentConnectedCondition, TrueReason: infrav1.GuestAgentConnectedV1Beta2Reason},
+	})
 
 	// Patch the object, ignoring conflicts on the conditions owned by this controller.
 	return patchHelper.Patch(
@@ -76,6 +96,9 @@ func (c *MachineContext) PatchKubevirtMachine(patchHelper *patch.Helper) error {
 			clusterv1.ReadyCondition,
 			infrav1.VMProvisionedCondition,
 			infrav1.BootstrapExecSucceededCondition,
+			infrav1.NodeReadyCondition,
+			infrav1.VMInSyncCondition,
+			infrav1.GuestAgentConnectedCondition,
 		}},
 	)
 }
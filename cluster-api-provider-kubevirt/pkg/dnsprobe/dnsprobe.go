@@ -0,0 +1,181 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dnsprobe probes the DNS and the service network of a workload cluster with a Job resolving the Services
+// of the cluster through its DNS, and reaching the API server through its Service.
+package dnsprobe
+
+import (
+	gocontext "context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+const (
+	// Namespace is the namespace of the workload cluster the probe runs in.
+	Namespace = "capk-dns-probe"
+
+	name         = "capk-dns-probe"
+	defaultImage = "busybox:stable"
+
+	// lookups is the number of lookups of the kubernetes Service, so that the intermittent timeouts of the DNS are
+	// noticed.
+	lookups = 5
+)
+
+// script writes the step that failed to the termination log of the probe. The certificate of the API server does
+// not fit in a packet, so that its TLS handshake fails when the MTU of the service network is wrong; an HTTP error
+// of the API server, e.g. when anonymous requests are refused, still proves the network.
+var script = fmt.Sprintf(`fail() { echo "$1" > /dev/termination-log; exit 1; }
+for i in $(seq %[1]d); do
+  timeout 5 nslookup kubernetes.default.svc > /dev/null || fail "lookup $i/%[1]d of kubernetes.default.svc failed or timed out"
+done
+timeout 5 nslookup kube-dns.kube-system.svc > /dev/null || fail "lookup of kube-dns.kube-system.svc failed or timed out"
+out=$(timeout 10 wget -q -T 5 -O /dev/null https://kubernetes.default.svc/healthz 2>&1) || echo "$out" | grep -q "server returned error" ||
+  fail "request to the kubernetes Service failed or timed out: $out"
+`, lookups)
+
+// Status is the progress of a probe.
+type Status struct {
+	// StartTime is the time the probe started.
+	StartTime time.Time
+	// Completed is true once the probe passed or failed.
+	Completed bool
+	// Failure describes why the probe failed, it is empty if the probe passed.
+	Failure string
+}
+
+// Start creates the probe in the workload cluster, unless it already exists.
+func Start(ctx gocontext.Context, c client.Client, spec *infrav1.WorkloadDNSProbeSpec) error {
+	for _, obj := range objects(spec) {
+		if err := c.Create(ctx, obj); err != nil && !apierrors.IsAlreadyExists(err) {
+			return errors.Wrapf(err, "failed to create DNS probe %T %s", obj, obj.GetName())
+		}
+	}
+	return nil
+}
+
+// Check returns the status of the probe.
+func Check(ctx gocontext.Context, c client.Client) (Status, error) {
+	job := &batchv1.Job{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: Namespace, Name: name}, job); err != nil {
+		return Status{}, errors.Wrap(err, "failed to fetch DNS probe job")
+	}
+
+	status := Status{StartTime: job.CreationTimestamp.Time}
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobFailed:
+			failure, err := podFailure(ctx, c)
+			if err != nil {
+				return Status{}, err
+			}
+			if failure == "" {
+				failure = condition.Message
+			}
+			status.Completed, status.Failure = true, fmt.Sprintf("DNS probe failed: %s", failure)
+		case batchv1.JobComplete:
+			status.Completed = true
+		}
+	}
+	return status, nil
+}
+
+// podFailure returns the step of the probe that failed, as written by the failed pod.
+func podFailure(ctx gocontext.Context, c client.Client) (string, error) {
+	pods := &corev1.PodList{}
+	if err := c.List(ctx, pods, client.InNamespace(Namespace), client.MatchingLabels{"app": name}); err != nil {
+		return "", errors.Wrap(err, "failed to list DNS probe pods")
+	}
+	for _, pod := range pods.Items {
+		for _, containerStatus := range pod.Status.ContainerStatuses {
+			if terminated := containerStatus.State.Terminated; terminated != nil && terminated.Message != "" {
+				return strings.TrimSpace(terminated.Message), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// Cleanup deletes the probe job and its pods, so that the next probe starts anew.
+func Cleanup(ctx gocontext.Context, c client.Client) error {
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: Namespace, Name: name}}
+	if err := c.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed to delete DNS probe job")
+	}
+	return nil
+}
+
+// Remove deletes the namespace of the probe, once it is disabled.
+func Remove(ctx gocontext.Context, c client.Client) error {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: Namespace}}
+	if err := c.Delete(ctx, namespace); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed to delete DNS probe namespace")
+	}
+	return nil
+}
+
+func objects(spec *infrav1.WorkloadDNSProbeSpec) []client.Object {
+	image := spec.Image
+	if image == "" {
+		image = defaultImage
+	}
+
+	labels := map[string]string{"app": name}
+	return []client.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: Namespace}},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Namespace: Namespace, Name: name},
+			Spec: batchv1.JobSpec{
+				// A single attempt, so that intermittent failures are reported
+				BackoffLimit:          ptr.To[int32](0),
+				ActiveDeadlineSeconds: ptr.To[int64](120),
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec: corev1.PodSpec{
+						RestartPolicy:                corev1.RestartPolicyNever,
+						AutomountServiceAccountToken: ptr.To(false),
+						Containers: []corev1.Container{{
+							Name:    "probe",
+							Image:   image,
+							Command: []string{"sh", "-c", script},
+						}},
+						// The probe may run before any worker node joined the cluster.
+						Tolerations: []corev1.Toleration{{
+							Key:      "node-role.kubernetes.io/control-plane",
+							Operator: corev1.TolerationOpExists,
+							Effect:   corev1.TaintEffectNoSchedule,
+						}},
+					},
+				},
+			},
+		},
+	}
+}
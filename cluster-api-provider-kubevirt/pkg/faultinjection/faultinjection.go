@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package faultinjection simulates provider failures on the clusters asking for them, to rehearse runbooks and
+// validate alerting in staging environments. Nothing is injected unless the controller enables it.
+package faultinjection
+
+import (
+	gocontext "context"
+	"net"
+	"strings"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+// Failure is a failure that can be simulated for a cluster.
+type Failure string
+
+const (
+	// KubeconfigSecretMissing makes the kubeconfig secret of the workload cluster look absent.
+	KubeconfigSecretMissing Failure = "kubeconfig-secret-missing"
+	// VMStartFailure makes the creation of the VMs of the cluster fail.
+	VMStartFailure Failure = "vm-start-failure"
+	// APIServerUnreachable makes the connections to the apiserver of the workload cluster fail.
+	APIServerUnreachable Failure = "apiserver-unreachable"
+)
+
+var enabled atomic.Bool
+
+// SetEnabled enables or disables the injection of failures.
+func SetEnabled(enable bool) {
+	enabled.Store(enable)
+}
+
+// Injected returns true if the failure injection is enabled and the KubevirtCluster asks for the failure with
+// the inject-failures annotation.
+func Injected(kubevirtCluster metav1.Object, failure Failure) bool {
+	if !enabled.Load() || kubevirtCluster == nil {
+		return false
+	}
+
+	value, found := kubevirtCluster.GetAnnotations()[infrav1.InjectFailuresAnnotation]
+	if !found {
+		return false
+	}
+	for _, requested := range strings.Split(value, ",") {
+		if Failure(strings.TrimSpace(requested)) == failure {
+			return true
+		}
+	}
+	return false
+}
+
+// Error returns the error reported for a simulated failure.
+func Error(failure Failure) error {
+	return errors.Errorf("injected failure %q", failure)
+}
+
+// UnreachableDial can be used as the Dial function of a REST config to simulate an unreachable apiserver.
+func UnreachableDial(_ gocontext.Context, network, address string) (net.Conn, error) {
+	return nil, &net.OpError{Op: "dial", Net: network, Err: Error(APIServerUnreachable)}
+}
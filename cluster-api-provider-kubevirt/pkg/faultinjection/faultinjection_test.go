@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package faultinjection
+
+import (
+	gocontext "context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+var _ = Describe("Failure injection", func() {
+	var kubevirtCluster *infrav1.KubevirtCluster
+
+	BeforeEach(func() {
+		kubevirtCluster = &infrav1.KubevirtCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-cluster",
+				Annotations: map[string]string{
+					infrav1.InjectFailuresAnnotation: "kubeconfig-secret-missing, vm-start-failure",
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		SetEnabled(false)
+	})
+
+	It("should not inject failures when disabled", func() {
+		Expect(Injected(kubevirtCluster, KubeconfigSecretMissing)).To(BeFalse())
+		Expect(Injected(kubevirtCluster, VMStartFailure)).To(BeFalse())
+	})
+
+	It("should only inject the failures requested by the cluster", func() {
+		SetEnabled(true)
+		Expect(Injected(kubevirtCluster, KubeconfigSecretMissing)).To(BeTrue())
+		Expect(Injected(kubevirtCluster, VMStartFailure)).To(BeTrue())
+		Expect(Injected(kubevirtCluster, APIServerUnreachable)).To(BeFalse())
+	})
+
+	It("should not inject failures in clusters without the annotation", func() {
+		SetEnabled(true)
+		kubevirtCluster.Annotations = nil
+		Expect(Injected(kubevirtCluster, KubeconfigSecretMissing)).To(BeFalse())
+	})
+
+	It("should fail to dial the apiserver", func() {
+		conn, err := UnreachableDial(gocontext.Background(), "tcp", "127.0.0.1:6443")
+		Expect(conn).To(BeNil())
+		Expect(err).To(MatchError(ContainSubstring(string(APIServerUnreachable))))
+	})
+})
@@ -0,0 +1,299 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package guestagent runs commands inside the VMs through the qemu-guest-agent.
+package guestagent
+
+import (
+	"bytes"
+	gocontext "context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+const (
+	// launcherContainer is the container of the virt-launcher pod running libvirt.
+	launcherContainer = "compute"
+	// libvirtURI is the URI of the libvirt daemon of non-root virt-launcher pods.
+	libvirtURI = "qemu+unix:///session?socket=/var/run/libvirt/virtqemud-sock"
+
+	pollInterval = time.Second
+	// Timeout is the maximal duration of a command run inside a VM.
+	Timeout = time.Minute
+)
+
+// Commands are the commands that can be run inside the VMs, by name.
+var Commands = map[string][]string{
+	"kubelet-logs":       {"journalctl", "--unit=kubelet", "--no-pager", "--lines=1000"},
+	"containerd-logs":    {"journalctl", "--unit=containerd", "--no-pager", "--lines=1000"},
+	"restart-kubelet":    {"systemctl", "restart", "kubelet"},
+	"restart-containerd": {"systemctl", "restart", "containerd"},
+}
+
+// RebootRequiredCommand exits with 0 when the guest has to be rebooted to complete an update, e.g. of its kernel.
+var RebootRequiredCommand = []string{"test", "-e", "/var/run/reboot-required"}
+
+// BootstrapLogCommand prints the end of the output of cloud-init, where the failures of the bootstrap data show.
+var BootstrapLogCommand = []string{"tail", "--lines=200", "/var/log/cloud-init-output.log"}
+
+// Result is the outcome of a command run inside a VM.
+type Result struct {
+	ExitCode int
+	Stdout   string
+	Stderr   string
+}
+
+//go:generate mockgen -source=./runner.go -destination=./mock/runner_generated.go -package=mock
+type Runner interface {
+	// Run runs the command inside the VMI with the given namespace and name, in the cluster of the config.
+	Run(ctx gocontext.Context, config *rest.Config, namespace, name string, command []string) (*Result, error)
+	// MediatedDevices returns the UUIDs of the mediated devices assigned to the GPUs of the VMI with the given
+	// namespace and name, by GPU name, read from the libvirt domain of the VMI.
+	MediatedDevices(ctx gocontext.Context, config *rest.Config, namespace, name string) (map[string]string, error)
+}
+
+// NewRunner creates a Runner going through the virt-launcher pods of the VMIs.
+func NewRunner() Runner {
+	return launcherRunner{}
+}
+
+// launcherRunner sends guest-exec requests to the qemu-guest-agent with virsh, from the virt-launcher pod.
+type launcherRunner struct{}
+
+type guestExecResponse struct {
+	Return struct {
+		PID int `json:"pid"`
+	} `json:"return"`
+}
+
+type guestExecStatusResponse struct {
+	Return struct {
+		Exited   bool   `json:"exited"`
+		ExitCode int    `json:"exitcode"`
+		OutData  string `json:"out-data"`
+		ErrData  string `json:"err-data"`
+	} `json:"return"`
+}
+
+// Run implements Runner.
+func (r launcherRunner) Run(ctx gocontext.Context, config *rest.Config, namespace, name string, command []string) (*Result, error) {
+	if len(command) == 0 {
+		return nil, errors.New("empty command")
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	// The command is started within the timeout of the infra calls, then polled until it completes
+	startCtx, cancelStart := withConfigTimeout(ctx, config)
+	defer cancelStart()
+	pod, err := launcherPod(startCtx, clientset, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	domain := fmt.Sprintf("%s_%s", namespace, name)
+	execRequest, err := json.Marshal(map[string]interface{}{
+		"execute": "guest-exec",
+		"arguments": map[string]interface{}{
+			"path":           command[0],
+			"arg":            command[1:],
+			"capture-output": true,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	execResponse := &guestExecResponse{}
+	if err := agentCommand(startCtx, config, clientset, pod, domain, string(execRequest), execResponse); err != nil {
+		return nil, errors.Wrapf(err, "failed to start command in VMI %s/%s", namespace, name)
+	}
+
+	ctx, cancel := gocontext.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	statusRequest := fmt.Sprintf(`{"execute":"guest-exec-status","arguments":{"pid":%d}}`, execResponse.Return.PID)
+	for {
+		statusResponse := &guestExecStatusResponse{}
+		if err := agentCommand(ctx, config, clientset, pod, domain, statusRequest, statusResponse); err != nil {
+			return nil, errors.Wrapf(err, "failed to get the status of the command in VMI %s/%s", namespace, name)
+		}
+
+		if statusResponse.Return.Exited {
+			stdout, err := base64.StdEncoding.DecodeString(statusResponse.Return.OutData)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to decode command output")
+			}
+			stderr, err := base64.StdEncoding.DecodeString(statusResponse.Return.ErrData)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to decode command error output")
+			}
+			return &Result{
+				ExitCode: statusResponse.Return.ExitCode,
+				Stdout:   string(stdout),
+				Stderr:   string(stderr),
+			}, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrapf(ctx.Err(), "command in VMI %s/%s did not complete", namespace, name)
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// MediatedDevices implements Runner.
+func (r launcherRunner) MediatedDevices(ctx gocontext.Context, config *rest.Config, namespace, name string) (map[string]string, error) {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	ctx, cancel := withConfigTimeout(ctx, config)
+	defer cancel()
+	pod, err := launcherPod(ctx, clientset, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	domainXML, err := virsh(ctx, config, clientset, pod, "dumpxml", fmt.Sprintf("%s_%s", namespace, name))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the domain of VMI %s/%s", namespace, name)
+	}
+	return gpuMediatedDevices(domainXML)
+}
+
+// gpuAliasPrefix prefixes the aliases of the host devices KubeVirt defines for the GPUs of the VMIs, followed by
+// the name of the GPU.
+const gpuAliasPrefix = "ua-gpu-"
+
+type domainHostDevices struct {
+	HostDevices []struct {
+		Type   string `xml:"type,attr"`
+		Source struct {
+			Address struct {
+				UUID string `xml:"uuid,attr"`
+			} `xml:"address"`
+		} `xml:"source"`
+		Alias struct {
+			Name string `xml:"name,attr"`
+		} `xml:"alias"`
+	} `xml:"devices>hostdev"`
+}
+
+// gpuMediatedDevices returns the UUIDs of the mediated devices of the GPUs of a libvirt domain, by GPU name.
+func gpuMediatedDevices(domainXML []byte) (map[string]string, error) {
+	domain := &domainHostDevices{}
+	if err := xml.Unmarshal(domainXML, domain); err != nil {
+		return nil, errors.Wrap(err, "failed to decode the domain")
+	}
+
+	devices := map[string]string{}
+	for _, hostDevice := range domain.HostDevices {
+		if hostDevice.Type != "mdev" || !strings.HasPrefix(hostDevice.Alias.Name, gpuAliasPrefix) {
+			continue
+		}
+		devices[strings.TrimPrefix(hostDevice.Alias.Name, gpuAliasPrefix)] = hostDevice.Source.Address.UUID
+	}
+	return devices, nil
+}
+
+// withConfigTimeout bounds the context with the timeout of the REST config of the infra cluster, which client-go
+// applies to the requests of the clientsets but not to the exec streams.
+func withConfigTimeout(ctx gocontext.Context, config *rest.Config) (gocontext.Context, gocontext.CancelFunc) {
+	if config.Timeout <= 0 {
+		return gocontext.WithCancel(ctx)
+	}
+	return gocontext.WithTimeout(ctx, config.Timeout)
+}
+
+// launcherPod returns the running virt-launcher pod of the VMI.
+func launcherPod(ctx gocontext.Context, clientset kubernetes.Interface, namespace, name string) (*corev1.Pod, error) {
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("kubevirt.io=virt-launcher,vm.kubevirt.io/name=%s", name),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the virt-launcher pods of VMI %s/%s", namespace, name)
+	}
+
+	var running []corev1.Pod
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp == nil {
+			running = append(running, pod)
+		}
+	}
+
+	switch len(running) {
+	case 0:
+		return nil, errors.Errorf("no running virt-launcher pod for VMI %s/%s", namespace, name)
+	case 1:
+		return &running[0], nil
+	default:
+		return nil, errors.Errorf("VMI %s/%s is migrating", namespace, name)
+	}
+}
+
+// agentCommand sends the request to the guest agent of the domain, and decodes its response.
+func agentCommand(ctx gocontext.Context, config *rest.Config, clientset kubernetes.Interface, pod *corev1.Pod, domain, request string, response interface{}) error {
+	stdout, err := virsh(ctx, config, clientset, pod, "qemu-agent-command", domain, request)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(stdout, response)
+}
+
+// virsh runs virsh with the arguments in the virt-launcher pod, and returns its output.
+func virsh(ctx gocontext.Context, config *rest.Config, clientset kubernetes.Interface, pod *corev1.Pod, args ...string) ([]byte, error) {
+	req := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: launcherContainer,
+			Command:   append([]string{"virsh", "-c", libvirtURI}, args...),
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create executor")
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := executor.StreamWithContext(ctx, remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr}); err != nil {
+		return nil, errors.Wrapf(err, "virsh failed: %s", stderr.String())
+	}
+
+	return stdout.Bytes(), nil
+}
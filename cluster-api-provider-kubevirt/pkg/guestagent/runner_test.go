@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package guestagent
+
+import (
+	gocontext "context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("launcherPod", func() {
+	ctx := gocontext.Background()
+
+	newLauncherPod := func(name string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels: map[string]string{
+					"kubevirt.io":         "virt-launcher",
+					"vm.kubevirt.io/name": "test-vm",
+				},
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+
+	It("should return the running virt-launcher pod of the VMI", func() {
+		clientset := fake.NewSimpleClientset(
+			newLauncherPod("virt-launcher-test-vm-old", corev1.PodSucceeded),
+			newLauncherPod("virt-launcher-test-vm-new", corev1.PodRunning),
+		)
+
+		pod, err := launcherPod(ctx, clientset, "default", "test-vm")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pod.Name).To(Equal("virt-launcher-test-vm-new"))
+	})
+
+	It("should fail without a running virt-launcher pod", func() {
+		clientset := fake.NewSimpleClientset(newLauncherPod("virt-launcher-test-vm", corev1.PodPending))
+
+		_, err := launcherPod(ctx, clientset, "default", "test-vm")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fail while the VMI is migrating", func() {
+		clientset := fake.NewSimpleClientset(
+			newLauncherPod("virt-launcher-test-vm-source", corev1.PodRunning),
+			newLauncherPod("virt-launcher-test-vm-target", corev1.PodRunning),
+		)
+
+		_, err := launcherPod(ctx, clientset, "default", "test-vm")
+		Expect(err).To(MatchError(ContainSubstring("migrating")))
+	})
+})
+
+var _ = Describe("gpuMediatedDevices", func() {
+	It("should return the UUIDs of the mediated devices of the GPUs", func() {
+		domainXML := []byte(`<domain type="kvm">
+  <devices>
+    <hostdev mode="subsystem" type="mdev" managed="no" model="vfio-pci">
+      <source>
+        <address uuid="b1e5c9a2-3f4d-4e6b-8a7c-9d0e1f2a3b4c"/>
+      </source>
+      <alias name="ua-gpu-vgpu1"/>
+    </hostdev>
+    <hostdev mode="subsystem" type="pci" managed="no">
+      <source>
+        <address domain="0x0000" bus="0x3b" slot="0x00" function="0x0"/>
+      </source>
+      <alias name="ua-gpu-gpu1"/>
+    </hostdev>
+    <hostdev mode="subsystem" type="mdev" managed="no" model="vfio-pci">
+      <source>
+        <address uuid="c2f6d0b3-4a5e-4f7c-9b8d-0e1f2a3b4c5d"/>
+      </source>
+      <alias name="ua-hostdevice-other"/>
+    </hostdev>
+  </devices>
+</domain>`)
+
+		devices, err := gpuMediatedDevices(domainXML)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(devices).To(Equal(map[string]string{"vgpu1": "b1e5c9a2-3f4d-4e6b-8a7c-9d0e1f2a3b4c"}))
+	})
+
+	It("should fail on an invalid domain", func() {
+		_, err := gpuMediatedDevices([]byte("not xml"))
+		Expect(err).To(HaveOccurred())
+	})
+})
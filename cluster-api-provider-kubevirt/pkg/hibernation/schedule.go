@@ -0,0 +1,167 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package hibernation evaluates the hibernation schedules of KubevirtClusters.
+package hibernation
+
+import (
+	"time"
+	// embed the time zone database, the controller image does not ship one.
+	_ "time/tzdata"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+// maxWindowSteps bounds the number of transitions walked when looking for the end of a window, so that
+// overlapping windows that never close cannot loop forever.
+const maxWindowSteps = 1000
+
+var parser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+type window struct {
+	hibernate cron.Schedule
+	resume    cron.Schedule
+}
+
+// active returns true if t falls within the window, that is if the window ends before it starts again.
+func (w window) active(t time.Time) bool {
+	return w.resume.Next(t).Before(w.hibernate.Next(t))
+}
+
+// Schedule is the set of recurring hibernation windows of a cluster.
+type Schedule struct {
+	windows []window
+}
+
+// NewSchedule parses the hibernation schedules of a KubevirtCluster.
+func NewSchedule(schedules []infrav1.HibernationSchedule) (*Schedule, error) {
+	s := &Schedule{}
+	for i, schedule := range schedules {
+		location := time.UTC
+		if schedule.TimeZone != "" {
+			var err error
+			if location, err = time.LoadLocation(schedule.TimeZone); err != nil {
+				return nil, errors.Wrapf(err, "invalid time zone in hibernation schedule %d", i)
+			}
+		}
+
+		hibernate, err := parser.Parse(schedule.Hibernate)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid hibernate expression in hibernation schedule %d", i)
+		}
+		resume, err := parser.Parse(schedule.Resume)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid resume expression in hibernation schedule %d", i)
+		}
+
+		s.windows = append(s.windows, window{
+			hibernate: inLocation(hibernate, location),
+			resume:    inLocation(resume, location),
+		})
+	}
+	return s, nil
+}
+
+// Active returns true if t falls within one of the hibernation windows.
+func (s *Schedule) Active(t time.Time) bool {
+	for _, w := range s.windows {
+		if w.active(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextTransition returns the first time after t at which a hibernation window starts or ends, or the zero
+// time if there are no windows.
+func (s *Schedule) NextTransition(t time.Time) time.Time {
+	var next time.Time
+	for _, w := range s.windows {
+		for _, candidate := range []time.Time{w.hibernate.Next(t), w.resume.Next(t)} {
+			if !candidate.IsZero() && (next.IsZero() || candidate.Before(next)) {
+				next = candidate
+			}
+		}
+	}
+	return next
+}
+
+// WindowEnd returns the time the hibernation window t falls within ends or, if t is outside of all
+// windows, the time the next window ends. It returns the zero time if there is no such window.
+func (s *Schedule) WindowEnd(t time.Time) time.Time {
+	steps := 0
+	for !s.Active(t) {
+		if t = s.NextTransition(t); t.IsZero() || steps == maxWindowSteps {
+			return time.Time{}
+		}
+		steps++
+	}
+	for s.Active(t) {
+		if t = s.NextTransition(t); t.IsZero() || steps == maxWindowSteps {
+			return time.Time{}
+		}
+		steps++
+	}
+	return t
+}
+
+// Requested returns true if the KubevirtCluster should be hibernated at now, either because it is
+// explicitly hibernated or because now falls within one of its hibernation windows, along with the next
+// time a window starts or ends. A window skipped with the skip annotation does not hibernate the cluster;
+// the annotation is resolved to the end of the skipped window on first use, and removed once that window
+// is over.
+func Requested(kubevirtCluster *infrav1.KubevirtCluster, now time.Time) (bool, time.Time, error) {
+	schedule, err := NewSchedule(kubevirtCluster.Spec.HibernationSchedules)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+
+	active := schedule.Active(now)
+	if value, found := kubevirtCluster.Annotations[infrav1.SkipHibernationAnnotation]; found {
+		skipUntil, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			skipUntil = schedule.WindowEnd(now)
+			kubevirtCluster.Annotations[infrav1.SkipHibernationAnnotation] = skipUntil.Format(time.RFC3339)
+		}
+		if now.Before(skipUntil) {
+			active = false
+		} else {
+			delete(kubevirtCluster.Annotations, infrav1.SkipHibernationAnnotation)
+		}
+	}
+
+	return kubevirtCluster.Spec.Hibernated || active, schedule.NextTransition(now), nil
+}
+
+// IsClusterHibernated returns true if the VMs of the cluster are, or are being, stopped by its hibernation.
+func IsClusterHibernated(kubevirtCluster *infrav1.KubevirtCluster) bool {
+	switch kubevirtCluster.Status.HibernationState {
+	case infrav1.HibernationStateHibernating, infrav1.HibernationStateHibernated:
+		return true
+	}
+	return kubevirtCluster.Spec.Hibernated
+}
+
+// inLocation evaluates a cron schedule in the given time zone.
+func inLocation(schedule cron.Schedule, location *time.Location) cron.Schedule {
+	if spec, ok := schedule.(*cron.SpecSchedule); ok {
+		spec.Location = location
+	}
+	return schedule
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hibernation
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+var _ = Describe("Schedule", func() {
+	paris, _ := time.LoadLocation("Europe/Paris")
+	// 2024-01-10 is a Wednesday
+	at := func(day, hour int) time.Time {
+		return time.Date(2024, time.January, day, hour, 0, 0, 0, paris)
+	}
+
+	weeknights := []infrav1.HibernationSchedule{{
+		Hibernate: "0 20 * * 1-5",
+		Resume:    "0 7 * * 1-5",
+		TimeZone:  "Europe/Paris",
+	}}
+
+	It("should be inactive outside of the windows", func() {
+		schedule, err := NewSchedule(weeknights)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(schedule.Active(at(10, 12))).To(BeFalse())
+		Expect(schedule.NextTransition(at(10, 12))).To(BeTemporally("==", at(10, 20)))
+		Expect(schedule.WindowEnd(at(10, 12))).To(BeTemporally("==", at(11, 7)))
+	})
+
+	It("should be active within a window", func() {
+		schedule, err := NewSchedule(weeknights)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(schedule.Active(at(10, 22))).To(BeTrue())
+		Expect(schedule.NextTransition(at(10, 22))).To(BeTemporally("==", at(11, 7)))
+		Expect(schedule.WindowEnd(at(10, 22))).To(BeTemporally("==", at(11, 7)))
+	})
+
+	It("should keep the cluster hibernated over the weekend", func() {
+		schedule, err := NewSchedule(weeknights)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(schedule.Active(at(13, 12))).To(BeTrue())
+		Expect(schedule.WindowEnd(at(13, 12))).To(BeTemporally("==", at(15, 7)))
+	})
+
+	It("should evaluate the expressions in the time zone of the schedule", func() {
+		utc := []infrav1.HibernationSchedule{{Hibernate: "0 20 * * 1-5", Resume: "0 7 * * 1-5"}}
+		// 20:30 in Paris is 19:30 UTC
+		now := time.Date(2024, time.January, 10, 19, 30, 0, 0, time.UTC)
+
+		schedule, err := NewSchedule(weeknights)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(schedule.Active(now)).To(BeTrue())
+
+		schedule, err = NewSchedule(utc)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(schedule.Active(now)).To(BeFalse())
+	})
+
+	It("should have no transitions without windows", func() {
+		schedule, err := NewSchedule(nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(schedule.Active(at(10, 12))).To(BeFalse())
+		Expect(schedule.NextTransition(at(10, 12))).To(BeZero())
+		Expect(schedule.WindowEnd(at(10, 12))).To(BeZero())
+	})
+
+	It("should reject invalid schedules", func() {
+		_, err := NewSchedule([]infrav1.HibernationSchedule{{Hibernate: "0 25 * * *", Resume: "0 7 * * *"}})
+		Expect(err).To(HaveOccurred())
+		_, err = NewSchedule([]infrav1.HibernationSchedule{{Hibernate: "0 20 * * *", Resume: "0 7 * * *", TimeZone: "Mars/Olympus_Mons"}})
+		Expect(err).To(HaveOccurred())
+	})
+
+	Context("Requested", func() {
+		var kubevirtCluster *infrav1.KubevirtCluster
+
+		BeforeEach(func() {
+			kubevirtCluster = &infrav1.KubevirtCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+				Spec:       infrav1.KubevirtClusterSpec{HibernationSchedules: weeknights},
+			}
+		})
+
+		It("should follow the schedules", func() {
+			hibernated, next, err := Requested(kubevirtCluster, at(10, 22))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hibernated).To(BeTrue())
+			Expect(next).To(BeTemporally("==", at(11, 7)))
+
+			hibernated, _, err = Requested(kubevirtCluster, at(11, 12))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hibernated).To(BeFalse())
+		})
+
+		It("should hibernate an explicitly hibernated cluster outside of the windows", func() {
+			kubevirtCluster.Spec.Hibernated = true
+			hibernated, _, err := Requested(kubevirtCluster, at(11, 12))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hibernated).To(BeTrue())
+		})
+
+		It("should skip the current window once", func() {
+			kubevirtCluster.Annotations = map[string]string{infrav1.SkipHibernationAnnotation: "true"}
+
+			hibernated, _, err := Requested(kubevirtCluster, at(10, 22))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hibernated).To(BeFalse())
+			Expect(kubevirtCluster.Annotations).To(HaveKeyWithValue(infrav1.SkipHibernationAnnotation, at(11, 7).Format(time.RFC3339)))
+
+			hibernated, _, err = Requested(kubevirtCluster, at(11, 22))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hibernated).To(BeTrue())
+			Expect(kubevirtCluster.Annotations).ToNot(HaveKey(infrav1.SkipHibernationAnnotation))
+		})
+
+		It("should skip the next window when set outside of the windows", func() {
+			kubevirtCluster.Annotations = map[string]string{infrav1.SkipHibernationAnnotation: ""}
+
+			hibernated, _, err := Requested(kubevirtCluster, at(10, 12))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hibernated).To(BeFalse())
+
+			hibernated, _, err = Requested(kubevirtCluster, at(10, 22))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(hibernated).To(BeFalse())
+			Expect(kubevirtCluster.Annotations).To(HaveKey(infrav1.SkipHibernationAnnotation))
+		})
+
+		It("should fail on invalid schedules", func() {
+			kubevirtCluster.Spec.HibernationSchedules = []infrav1.HibernationSchedule{{Hibernate: "never", Resume: "0 7 * * *"}}
+			_, _, err := Requested(kubevirtCluster, at(10, 22))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	It("should report the cluster hibernated until it is resumed", func() {
+		kubevirtCluster := &infrav1.KubevirtCluster{}
+		Expect(IsClusterHibernated(kubevirtCluster)).To(BeFalse())
+		kubevirtCluster.Status.HibernationState = infrav1.HibernationStateHibernated
+		Expect(IsClusterHibernated(kubevirtCluster)).To(BeTrue())
+		kubevirtCluster.Status.HibernationState = infrav1.HibernationStateResuming
+		Expect(IsClusterHibernated(kubevirtCluster)).To(BeFalse())
+		kubevirtCluster.Spec.Hibernated = true
+		Expect(IsClusterHibernated(kubevirtCluster)).To(BeTrue())
+	})
+})
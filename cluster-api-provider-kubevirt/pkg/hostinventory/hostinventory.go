@@ -0,0 +1,309 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package hostinventory collects the network facts of the host of the controller, i.e. its interfaces, their
+// addresses and offloads, and its routes to the tenant and management networks, so that fleet tooling can verify
+// the host is attached to the management SDN.
+package hostinventory
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"math/bits"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// flags of the routes of /proc/net/route and /proc/net/ipv6_route.
+	rtfUp     = 0x0001
+	rtfReject = 0x0200
+)
+
+// procNetDir is the directory of the routing tables of the kernel, it is changed by the tests.
+var procNetDir = "/proc/net"
+
+// Inventory is the network facts of a host.
+type Inventory struct {
+	// Host is the name of the host.
+	Host string `json:"host"`
+	// CollectionTime is the time the facts were collected.
+	CollectionTime time.Time `json:"collectionTime"`
+	// Interfaces are the network interfaces of the host.
+	Interfaces []Interface `json:"interfaces"`
+	// Routes are the routes of the host to the tenant and management networks.
+	Routes []Route `json:"routes,omitempty"`
+}
+
+// Interface is a network interface of a host.
+type Interface struct {
+	Name string `json:"name"`
+	MAC  string `json:"mac,omitempty"`
+	MTU  int    `json:"mtu"`
+	Up   bool   `json:"up"`
+	// Addresses are the addresses of the interface in CIDR notation.
+	Addresses []string `json:"addresses,omitempty"`
+	// Offloads are the states of the offloads of the interface, by their name in ethtool -k. It is empty when the
+	// driver of the interface does not report them.
+	Offloads map[string]bool `json:"offloads,omitempty"`
+}
+
+// Route is the route of a host to a network.
+type Route struct {
+	// Network is the tenant or management network.
+	Network string `json:"network"`
+	// Destination is the destination of the route used to reach the network, it is empty if the host has no route
+	// to the network.
+	Destination string `json:"destination,omitempty"`
+	// Gateway is the gateway of the route, it is empty for the networks the interface is attached to.
+	Gateway   string `json:"gateway,omitempty"`
+	Interface string `json:"interface,omitempty"`
+	Metric    uint32 `json:"metric,omitempty"`
+}
+
+// route is an entry of the routing tables of the kernel.
+type route struct {
+	dst    netip.Prefix
+	gw     netip.Addr
+	iface  string
+	metric uint32
+}
+
+// Collect collects the network facts of the host, with its routes to the given networks.
+func Collect(host string, networks []netip.Prefix) (*Inventory, error) {
+	interfaces, err := collectInterfaces()
+	if err != nil {
+		return nil, err
+	}
+	routes, err := readRoutes()
+	if err != nil {
+		return nil, err
+	}
+
+	inventory := &Inventory{
+		Host:           host,
+		CollectionTime: time.Now().UTC(),
+		Interfaces:     interfaces,
+	}
+	for _, network := range networks {
+		inventory.Routes = append(inventory.Routes, routeTo(routes, network))
+	}
+	return inventory, nil
+}
+
+func collectInterfaces() ([]Interface, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list the network interfaces")
+	}
+
+	interfaces := make([]Interface, 0, len(ifaces))
+	for _, iface := range ifaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list the addresses of network interface %s", iface.Name)
+		}
+		interfaces = append(interfaces, Interface{
+			Name:      iface.Name,
+			MAC:       iface.HardwareAddr.String(),
+			MTU:       iface.MTU,
+			Up:        iface.Flags&net.FlagUp != 0,
+			Addresses: addrStrings(addrs),
+			Offloads:  offloads(iface.Name),
+		})
+	}
+	sort.Slice(interfaces, func(i, j int) bool { return interfaces[i].Name < interfaces[j].Name })
+	return interfaces, nil
+}
+
+func addrStrings(addrs []net.Addr) []string {
+	var out []string
+	for _, addr := range addrs {
+		out = append(out, addr.String())
+	}
+	return out
+}
+
+// routeTo returns the route the kernel uses to reach the network: the most specific route covering the whole
+// network, with the lowest metric.
+func routeTo(routes []route, network netip.Prefix) Route {
+	var best *route
+	for i := range routes {
+		r := &routes[i]
+		if r.dst.Bits() > network.Bits() || !r.dst.Contains(network.Addr()) {
+			continue
+		}
+		if best == nil || r.dst.Bits() > best.dst.Bits() || (r.dst.Bits() == best.dst.Bits() && r.metric < best.metric) {
+			best = r
+		}
+	}
+
+	out := Route{Network: network.String()}
+	if best == nil {
+		return out
+	}
+	out.Destination = best.dst.String()
+	out.Interface = best.iface
+	out.Metric = best.metric
+	if best.gw.IsValid() && !best.gw.IsUnspecified() {
+		out.Gateway = best.gw.String()
+	}
+	return out
+}
+
+func readRoutes() ([]route, error) {
+	var routes []route
+	for _, table := range []struct {
+		file  string
+		parse func(io.Reader) ([]route, error)
+	}{
+		{file: "route", parse: parseIPv4Routes},
+		{file: "ipv6_route", parse: parseIPv6Routes},
+	} {
+		f, err := os.Open(filepath.Join(procNetDir, table.file))
+		if os.IsNotExist(err) {
+			// IPv6 is disabled.
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read the routing table")
+		}
+		parsed, err := table.parse(f)
+		f.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse the routing table %s", table.file)
+		}
+		routes = append(routes, parsed...)
+	}
+	return routes, nil
+}
+
+// parseIPv4Routes parses /proc/net/route, whose addresses are hexadecimal numbers in the byte order of the host:
+//
+//	Iface	Destination	Gateway	Flags	RefCnt	Use	Metric	Mask	MTU	Window	IRTT
+//	eth0	00000000	0100000A	0003	0	0	100	00000000	0	0	0
+func parseIPv4Routes(r io.Reader) ([]route, error) {
+	var routes []route
+	scanner := bufio.NewScanner(r)
+	for first := true; scanner.Scan(); first = false {
+		fields := strings.Fields(scanner.Text())
+		if first || len(fields) < 8 {
+			continue
+		}
+		flags, err := strconv.ParseUint(fields[3], 16, 32)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid flags %q", fields[3])
+		}
+		if flags&rtfUp == 0 || flags&rtfReject != 0 {
+			continue
+		}
+		dst, err := parseIPv4(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		gw, err := parseIPv4(fields[2])
+		if err != nil {
+			return nil, err
+		}
+		mask, err := strconv.ParseUint(fields[7], 16, 32)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid mask %q", fields[7])
+		}
+		metric, err := strconv.ParseUint(fields[6], 10, 32)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid metric %q", fields[6])
+		}
+		routes = append(routes, route{
+			dst:    netip.PrefixFrom(dst, bits.OnesCount32(uint32(mask))),
+			gw:     gw,
+			iface:  fields[0],
+			metric: uint32(metric),
+		})
+	}
+	return routes, scanner.Err()
+}
+
+func parseIPv4(s string) (netip.Addr, error) {
+	n, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return netip.Addr{}, errors.Wrapf(err, "invalid address %q", s)
+	}
+	var b [4]byte
+	binary.NativeEndian.PutUint32(b[:], uint32(n))
+	return netip.AddrFrom4(b), nil
+}
+
+// parseIPv6Routes parses /proc/net/ipv6_route, whose addresses are hexadecimal numbers in network byte order:
+//
+//	destination prefix-length source source-prefix-length next-hop metric refcnt use flags iface
+func parseIPv6Routes(r io.Reader) ([]route, error) {
+	var routes []route
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		flags, err := strconv.ParseUint(fields[8], 16, 32)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid flags %q", fields[8])
+		}
+		if flags&rtfUp == 0 || flags&rtfReject != 0 {
+			continue
+		}
+		dst, err := parseIPv6(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		prefixLength, err := strconv.ParseUint(fields[1], 16, 8)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid prefix length %q", fields[1])
+		}
+		gw, err := parseIPv6(fields[4])
+		if err != nil {
+			return nil, err
+		}
+		metric, err := strconv.ParseUint(fields[5], 16, 32)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid metric %q", fields[5])
+		}
+		routes = append(routes, route{
+			dst:    netip.PrefixFrom(dst, int(prefixLength)),
+			gw:     gw,
+			iface:  fields[9],
+			metric: uint32(metric),
+		})
+	}
+	return routes, scanner.Err()
+}
+
+func parseIPv6(s string) (netip.Addr, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != 16 {
+		return netip.Addr{}, errors.Errorf("invalid address %q", s)
+	}
+	return netip.AddrFrom16([16]byte(b)), nil
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hostinventory
+
+import (
+	gocontext "context"
+	"encoding/json"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	ipv4Routes = `Iface	Destination	Gateway 	Flags	RefCnt	Use	Metric	Mask		MTU	Window	IRTT
+eth0	00000000	0100000A	0003	0	0	100	00000000	0	0	0
+eth0	0000000A	00000000	0001	0	0	100	0000FFFF	0	0	0
+eth1	0000800A	0100020A	0003	0	0	200	0000FCFF	0	0	0
+eth2	0000800A	0100030A	0003	0	0	50	0000FCFF	0	0	0
+eth1	0500800A	0100020A	0003	0	0	0	FFFFFFFF	0	0	0
+lo	0000810A	00000000	0201	0	0	0	0000FFFF	0	0	0
+`
+	ipv6Routes = `fd000000000000000000000000000000 40 00000000000000000000000000000000 00 00000000000000000000000000000000 00000100 00000001 00000000 00000001     eth0
+00000000000000000000000000000000 00 00000000000000000000000000000000 00 fd000000000000000000000000000001 00000400 00000001 00000000 00000003     eth0
+`
+)
+
+var _ = Describe("Host inventory", func() {
+	BeforeEach(func() {
+		dir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(dir, "route"), []byte(ipv4Routes), 0o600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "ipv6_route"), []byte(ipv6Routes), 0o600)).To(Succeed())
+		previous := procNetDir
+		procNetDir = dir
+		DeferCleanup(func() { procNetDir = previous })
+	})
+
+	It("should report the routes of the host to the networks", func() {
+		routes, err := readRoutes()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(routeTo(routes, netip.MustParsePrefix("10.128.0.0/14"))).To(Equal(Route{
+			Network:     "10.128.0.0/14",
+			Destination: "10.128.0.0/14",
+			Gateway:     "10.3.0.1",
+			Interface:   "eth2",
+			Metric:      50,
+		}), "the most specific route covering the network, with the lowest metric")
+		Expect(routeTo(routes, netip.MustParsePrefix("10.0.0.0/16"))).To(Equal(Route{
+			Network:     "10.0.0.0/16",
+			Destination: "10.0.0.0/16",
+			Interface:   "eth0",
+			Metric:      100,
+		}))
+		Expect(routeTo(routes, netip.MustParsePrefix("10.129.0.0/16"))).To(Equal(Route{
+			Network:     "10.129.0.0/16",
+			Destination: "10.128.0.0/14",
+			Gateway:     "10.3.0.1",
+			Interface:   "eth2",
+			Metric:      50,
+		}), "the reject route is ignored")
+		Expect(routeTo(routes, netip.MustParsePrefix("192.168.0.0/24"))).To(Equal(Route{
+			Network:     "192.168.0.0/24",
+			Destination: "0.0.0.0/0",
+			Gateway:     "10.0.0.1",
+			Interface:   "eth0",
+			Metric:      100,
+		}))
+		Expect(routeTo(routes, netip.MustParsePrefix("fd00:0:0:1::/64"))).To(Equal(Route{
+			Network:     "fd00:0:0:1::/64",
+			Destination: "::/0",
+			Gateway:     "fd00::1",
+			Interface:   "eth0",
+			Metric:      1024,
+		}))
+	})
+
+	It("should report a network the host has no route to", func() {
+		routes, err := parseIPv4Routes(strings.NewReader(`Iface	Destination	Gateway 	Flags	RefCnt	Use	Metric	Mask		MTU	Window	IRTT
+eth0	0000000A	00000000	0001	0	0	100	0000FFFF	0	0	0
+`))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(routeTo(routes, netip.MustParsePrefix("10.128.0.0/14"))).To(Equal(Route{Network: "10.128.0.0/14"}))
+	})
+
+	It("should publish the inventory of the host in a ConfigMap", func() {
+		ctx := gocontext.Background()
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+		publisher := &Publisher{
+			Client:    fakeClient,
+			Reader:    fakeClient,
+			Namespace: "capk-system",
+			Host:      "node-1",
+			Networks:  []netip.Prefix{netip.MustParsePrefix("10.128.0.0/14")},
+		}
+
+		Expect(publisher.Publish(ctx)).To(Succeed())
+		cm := &corev1.ConfigMap{}
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "capk-system", Name: "capk-host-inventory-node-1"}, cm)).To(Succeed())
+		Expect(cm.Labels).To(HaveKey(ConfigMapLabel))
+		inventory := &Inventory{}
+		Expect(json.Unmarshal([]byte(cm.Data[DataKey]), inventory)).To(Succeed())
+		Expect(inventory.Host).To(Equal("node-1"))
+		Expect(inventory.Interfaces).ToNot(BeEmpty())
+		Expect(inventory.Routes).To(ConsistOf(HaveField("Interface", "eth2")))
+
+		// the next collection updates the ConfigMap
+		publisher.Networks = nil
+		Expect(publisher.Publish(ctx)).To(Succeed())
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(cm), cm)).To(Succeed())
+		inventory = &Inventory{}
+		Expect(json.Unmarshal([]byte(cm.Data[DataKey]), inventory)).To(Succeed())
+		Expect(inventory.Routes).To(BeEmpty())
+	})
+})
@@ -0,0 +1,88 @@
+//go:build linux
+
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hostinventory
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// flags of ETHTOOL_GFLAGS.
+	ethFlagTxVLAN = 1 << 7
+	ethFlagRxVLAN = 1 << 8
+	ethFlagLRO    = 1 << 15
+)
+
+// ethtoolValue is struct ethtool_value.
+type ethtoolValue struct {
+	cmd  uint32
+	data uint32
+}
+
+// ifreq is struct ifreq, with a pointer to the ethtool command in its union.
+type ifreq struct {
+	name [unix.IFNAMSIZ]byte
+	data unsafe.Pointer
+	_    [24 - unsafe.Sizeof(uintptr(0))]byte
+}
+
+// offloads returns the states of the offloads of the interface, with the legacy ethtool commands every driver
+// answers. The offloads the driver does not report are left out, and nil is returned if it reports none.
+func offloads(name string) map[string]bool {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil
+	}
+	defer unix.Close(fd)
+
+	get := func(cmd uint32) (uint32, bool) {
+		value := ethtoolValue{cmd: cmd}
+		req := ifreq{data: unsafe.Pointer(&value)}
+		copy(req.name[:unix.IFNAMSIZ-1], name)
+		if _, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), unix.SIOCETHTOOL, uintptr(unsafe.Pointer(&req))); errno != 0 {
+			return 0, false
+		}
+		return value.data, true
+	}
+
+	states := map[string]bool{}
+	for feature, cmd := range map[string]uint32{
+		"rx-checksumming":              unix.ETHTOOL_GRXCSUM,
+		"tx-checksumming":              unix.ETHTOOL_GTXCSUM,
+		"scatter-gather":               unix.ETHTOOL_GSG,
+		"tcp-segmentation-offload":     unix.ETHTOOL_GTSO,
+		"generic-segmentation-offload": unix.ETHTOOL_GGSO,
+		"generic-receive-offload":      unix.ETHTOOL_GGRO,
+	} {
+		if data, ok := get(cmd); ok {
+			states[feature] = data != 0
+		}
+	}
+	if flags, ok := get(unix.ETHTOOL_GFLAGS); ok {
+		states["large-receive-offload"] = flags&ethFlagLRO != 0
+		states["rx-vlan-offload"] = flags&ethFlagRxVLAN != 0
+		states["tx-vlan-offload"] = flags&ethFlagTxVLAN != 0
+	}
+	if len(states) == 0 {
+		return nil
+	}
+	return states
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hostinventory
+
+import (
+	gocontext "context"
+	"encoding/json"
+	"net/netip"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConfigMapLabel is the label of the ConfigMaps of the inventories, so that fleet tooling lists the inventories of all
+	// the hosts running the controller.
+	ConfigMapLabel = "capk.cluster.x-k8s.io/host-inventory"
+
+	// DataKey is the key of the inventory, in JSON, in the ConfigMaps.
+	DataKey = "inventory.json"
+
+	namePrefix = "capk-host-inventory-"
+)
+
+// Publisher periodically publishes the inventory of the host in a ConfigMap named after the host. It runs in every
+// replica of the controller, whether it leads or not, so that each host is reported.
+type Publisher struct {
+	Client client.Client
+	// Reader reads the ConfigMap without a cache, so that the ConfigMaps are not watched.
+	Reader    client.Reader
+	Log       logr.Logger
+	Namespace string
+	Host      string
+	// Networks are the tenant and management networks whose routes are reported.
+	Networks []netip.Prefix
+	Interval time.Duration
+}
+
+// Name returns the name of the ConfigMap of the inventory of the host.
+func Name(host string) string {
+	return namePrefix + host
+}
+
+// Start publishes the inventory until the context is done.
+func (p *Publisher) Start(ctx gocontext.Context) error {
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+	for {
+		if err := p.Publish(ctx); err != nil {
+			p.Log.Error(err, "failed to publish the network inventory of the host", "host", p.Host)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// NeedLeaderElection returns false, every replica reports its host.
+func (p *Publisher) NeedLeaderElection() bool {
+	return false
+}
+
+// Publish collects the inventory of the host and writes it to its ConfigMap.
+func (p *Publisher) Publish(ctx gocontext.Context) error {
+	inventory, err := Collect(p.Host, p.Networks)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(inventory, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode the network inventory")
+	}
+
+	cm := &corev1.ConfigMap{}
+	key := client.ObjectKey{Namespace: p.Namespace, Name: Name(p.Host)}
+	if err := p.Reader.Get(ctx, key, cm); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to get ConfigMap %s", key)
+		}
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: key.Namespace,
+				Name:      key.Name,
+				Labels:    map[string]string{ConfigMapLabel: ""},
+			},
+			Data: map[string]string{DataKey: string(data)},
+		}
+		return errors.Wrapf(p.Client.Create(ctx, cm), "failed to create ConfigMap %s", key)
+	}
+
+	if cm.Labels == nil {
+		cm.Labels = map[string]string{}
+	}
+	cm.Labels[ConfigMapLabel] = ""
+	cm.Data = map[string]string{DataKey: string(data)}
+	return errors.Wrapf(p.Client.Update(ctx, cm), "failed to update ConfigMap %s", key)
+}
@@ -3,6 +3,7 @@ package infracluster
 import (
 	gocontext "context"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -14,14 +15,19 @@ import (
 //go:generate mockgen -source=./infracluster.go -destination=./mock/infracluster_generated.go -package=mock
 type InfraCluster interface {
 	GenerateInfraClusterClient(infraClusterSecretRef *corev1.ObjectReference, ownerNamespace string, context gocontext.Context) (k8sclient.Client, string, error)
+	GenerateInfraClusterRestConfig(infraClusterSecretRef *corev1.ObjectReference, ownerNamespace string, context gocontext.Context) (*rest.Config, string, error)
 }
 
 // ClientFactoryFunc defines the function to create a new client
 type ClientFactoryFunc func(config *rest.Config, options k8sclient.Options) (k8sclient.Client, error)
 
-// New creates new InfraCluster instance
-func New(client k8sclient.Client, noCachedClient k8sclient.Client) InfraCluster {
-	return NewWithFactory(client, noCachedClient, k8sclient.New)
+// New creates new InfraCluster instance, whose clients and REST configs give up on the calls to the infra clusters
+// after callTimeout, unless zero.
+func New(client k8sclient.Client, noCachedClient k8sclient.Client, restConfig *rest.Config, callTimeout time.Duration) InfraCluster {
+	infraCluster := NewWithFactory(client, noCachedClient, k8sclient.New).(*infraCluster)
+	infraCluster.RestConfig = restConfig
+	infraCluster.CallTimeout = callTimeout
+	return infraCluster
 }
 
 // NewWithFactory creates new InfraCluster instance that uses the provided client factory function.
@@ -37,12 +43,41 @@ type infraCluster struct {
 	k8sclient.Client
 	NoCachedClient k8sclient.Client
 	ClientFactory  ClientFactoryFunc
+	// RestConfig is the config of the management cluster, used when it is also the infra cluster.
+	RestConfig *rest.Config
+	// CallTimeout bounds each call to the infra clusters, so that an unresponsive infra cluster does not hold the
+	// reconciles. The calls are only bounded by their context when zero.
+	CallTimeout time.Duration
 }
 
 // GenerateInfraClusterClient creates a client for infra cluster.
 func (w *infraCluster) GenerateInfraClusterClient(infraClusterSecretRef *corev1.ObjectReference, ownerNamespace string, context gocontext.Context) (k8sclient.Client, string, error) {
 	if infraClusterSecretRef == nil {
-		return w.NoCachedClient, ownerNamespace, nil
+		return newTimeoutClient(w.NoCachedClient, w.CallTimeout), ownerNamespace, nil
+	}
+
+	restConfig, namespace, err := w.GenerateInfraClusterRestConfig(infraClusterSecretRef, ownerNamespace, context)
+	if err != nil {
+		return nil, "", err
+	}
+
+	infraClusterClient, err := w.ClientFactory(restConfig, k8sclient.Options{Scheme: w.Client.Scheme()})
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to create infra cluster client")
+	}
+
+	return newTimeoutClient(infraClusterClient, w.CallTimeout), namespace, nil
+}
+
+// GenerateInfraClusterRestConfig creates a REST config for infra cluster.
+func (w *infraCluster) GenerateInfraClusterRestConfig(infraClusterSecretRef *corev1.ObjectReference, ownerNamespace string, context gocontext.Context) (*rest.Config, string, error) {
+	if infraClusterSecretRef == nil {
+		if w.RestConfig == nil {
+			return nil, "", errors.New("no REST config for the management cluster")
+		}
+		restConfig := rest.CopyConfig(w.RestConfig)
+		restConfig.Timeout = w.CallTimeout
+		return restConfig, ownerNamespace, nil
 	}
 
 	infraKubeconfigSecret := &corev1.Secret{}
@@ -78,11 +113,7 @@ func (w *infraCluster) GenerateInfraClusterClient(infraClusterSecretRef *corev1.
 	if err != nil {
 		return nil, "", errors.Wrap(err, "failed to create REST config")
 	}
+	restConfig.Timeout = w.CallTimeout
 
-	infraClusterClient, err := w.ClientFactory(restConfig, k8sclient.Options{Scheme: w.Client.Scheme()})
-	if err != nil {
-		return nil, "", errors.Wrap(err, "failed to create infra cluster client")
-	}
-
-	return infraClusterClient, namespace, nil
+	return restConfig, namespace, nil
 }
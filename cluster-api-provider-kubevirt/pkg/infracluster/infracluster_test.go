@@ -1,6 +1,9 @@
 package infracluster_test
 
 import (
+	gocontext "context"
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
@@ -12,6 +15,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 )
 
 var (
@@ -44,7 +48,7 @@ var _ = Describe("InfraCluster", func() {
 	It("should return the management client and namespace when the infrastructure secret reference is nil", func() {
 		fakeClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).Build()
 
-		infraCluster := New(fakeClient, fakeClient)
+		infraCluster := New(fakeClient, fakeClient, nil, 0)
 		infraClient, infraNamespace, err := infraCluster.GenerateInfraClusterClient(nil, ownerNamespace, nil)
 		Expect(err).NotTo(HaveOccurred())
 		Expect(infraClient).To(BeIdenticalTo(fakeClient))
@@ -59,7 +63,7 @@ var _ = Describe("InfraCluster", func() {
 			Kind:       "Secret",
 			Name:       infraSecretName,
 		}
-		infraCluster := New(fakeClient, nil)
+		infraCluster := New(fakeClient, nil, nil, 0)
 
 		_, _, err := infraCluster.GenerateInfraClusterClient(infraClusterSecretRef, ownerNamespace, nil)
 		Expect(errors.IsNotFound(err)).To(BeTrue())
@@ -81,7 +85,7 @@ var _ = Describe("InfraCluster", func() {
 			Name:       infraSecretName,
 		}
 
-		infraCluster := New(fakeClient, nil)
+		infraCluster := New(fakeClient, nil, nil, 0)
 		_, _, err := infraCluster.GenerateInfraClusterClient(infraClusterSecretRef, ownerNamespace, nil)
 		Expect(err).To(HaveOccurred())
 		Expect(err.Error()).To(Equal("failed to retrieve infra kubeconfig from secret: 'kubeconfig' key is missing"))
@@ -105,7 +109,7 @@ var _ = Describe("InfraCluster", func() {
 			Name:       infraSecretName,
 		}
 
-		infraCluster := New(fakeClient, nil)
+		infraCluster := New(fakeClient, nil, nil, 0)
 		_, _, err := infraCluster.GenerateInfraClusterClient(infraClusterSecretRef, ownerNamespace, nil)
 		Expect(err).To(HaveOccurred())
 		Expect(err.Error()).To(ContainSubstring("failed to create K8s-API client config"))
@@ -174,4 +178,105 @@ var _ = Describe("InfraCluster", func() {
 		Expect(namespace).To(Equal("minastirith"))
 	})
 
+	It("should return the management config when the infrastructure secret reference is nil", func() {
+		fakeClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).Build()
+
+		infraCluster := New(fakeClient, fakeClient, &rest.Config{Host: "https://mordor.com"}, 0)
+		restConfig, namespace, err := infraCluster.GenerateInfraClusterRestConfig(nil, ownerNamespace, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(restConfig.Host).To(Equal("https://mordor.com"))
+		Expect(namespace).To(Equal(ownerNamespace))
+	})
+
+	It("should return the config of the kubeconfig in the referenced infrastructure secret", func() {
+		infraClusterSecret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      infraSecretName,
+				Namespace: ownerNamespace,
+			},
+			Data: map[string][]byte{
+				"kubeconfig": []byte(kubeconfig),
+			},
+		}
+		fakeClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(infraClusterSecret).Build()
+
+		infraClusterSecretRef := &corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Secret",
+			Name:       infraSecretName,
+		}
+
+		infraCluster := New(fakeClient, nil, nil, 0)
+		restConfig, namespace, err := infraCluster.GenerateInfraClusterRestConfig(infraClusterSecretRef, ownerNamespace, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(restConfig.Host).To(Equal("https://gondor.com"))
+		Expect(namespace).To(Equal("minastirith"))
+	})
+
+})
+
+var _ = Describe("Infra call timeout", func() {
+	BeforeEach(func() {
+		fakeClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).Build()
+	})
+
+	// blockingClient blocks the reads until their context is done, like an unresponsive infra cluster.
+	blockingClient := func() client.Client {
+		return interceptor.NewClient(fake.NewClientBuilder().WithScheme(testing.SetupScheme()).Build(), interceptor.Funcs{
+			Get: func(ctx gocontext.Context, _ client.WithWatch, _ client.ObjectKey, _ client.Object, _ ...client.GetOption) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		})
+	}
+
+	It("should give up on the calls to an unresponsive infra cluster", func() {
+		infraCluster := New(fakeClient, blockingClient(), nil, 100*time.Millisecond)
+
+		infraClusterClient, _, err := infraCluster.GenerateInfraClusterClient(nil, ownerNamespace, gocontext.Background())
+		Expect(err).NotTo(HaveOccurred())
+		err = infraClusterClient.Get(gocontext.Background(), client.ObjectKey{Namespace: ownerNamespace, Name: "vm"}, &corev1.Pod{})
+		Expect(err).To(MatchError(gocontext.DeadlineExceeded))
+	})
+
+	It("should not bound the calls without timeout", func() {
+		infraCluster := New(fakeClient, fakeClient, nil, 0)
+
+		infraClusterClient, _, err := infraCluster.GenerateInfraClusterClient(nil, ownerNamespace, gocontext.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(infraClusterClient).To(BeIdenticalTo(fakeClient))
+	})
+
+	It("should set the timeout of the REST configs", func() {
+		infraCluster := New(fakeClient, fakeClient, &rest.Config{Host: "https://mordor.com"}, time.Minute)
+
+		config, _, err := infraCluster.GenerateInfraClusterRestConfig(nil, ownerNamespace, gocontext.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config.Timeout).To(Equal(time.Minute))
+	})
+})
+
+var _ = Describe("Read-only InfraCluster", func() {
+	It("should send the writes to the infra cluster as dry-runs", func() {
+		fakeClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).Build()
+
+		infraCluster := NewReadOnly(New(fakeClient, fakeClient, nil, 0))
+		infraClient, infraNamespace, err := infraCluster.GenerateInfraClusterClient(nil, ownerNamespace, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(infraNamespace).To(Equal(ownerNamespace))
+
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "userdata", Namespace: ownerNamespace}}
+		Expect(infraClient.Create(gocontext.Background(), secret)).To(Succeed())
+		err = fakeClient.Get(gocontext.Background(), client.ObjectKeyFromObject(secret), &corev1.Secret{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should keep the REST config of the infra cluster", func() {
+		restConfig := &rest.Config{Host: "https://infra:6443"}
+		infraCluster := NewReadOnly(New(nil, nil, restConfig, 0))
+
+		config, _, err := infraCluster.GenerateInfraClusterRestConfig(nil, ownerNamespace, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config.Host).To(Equal(restConfig.Host))
+	})
 })
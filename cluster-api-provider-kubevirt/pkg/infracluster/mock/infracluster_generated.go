@@ -10,6 +10,7 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	v1 "k8s.io/api/core/v1"
+	rest "k8s.io/client-go/rest"
 	client "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -51,3 +52,19 @@ func (mr *MockInfraClusterMockRecorder) GenerateInfraClusterClient(infraClusterS
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateInfraClusterClient", reflect.TypeOf((*MockInfraCluster)(nil).GenerateInfraClusterClient), infraClusterSecretRef, ownerNamespace, context)
 }
+
+// GenerateInfraClusterRestConfig mocks base method.
+func (m *MockInfraCluster) GenerateInfraClusterRestConfig(infraClusterSecretRef *v1.ObjectReference, ownerNamespace string, context context.Context) (*rest.Config, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateInfraClusterRestConfig", infraClusterSecretRef, ownerNamespace, context)
+	ret0, _ := ret[0].(*rest.Config)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GenerateInfraClusterRestConfig indicates an expected call of GenerateInfraClusterRestConfig.
+func (mr *MockInfraClusterMockRecorder) GenerateInfraClusterRestConfig(infraClusterSecretRef, ownerNamespace, context interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateInfraClusterRestConfig", reflect.TypeOf((*MockInfraCluster)(nil).GenerateInfraClusterRestConfig), infraClusterSecretRef, ownerNamespace, context)
+}
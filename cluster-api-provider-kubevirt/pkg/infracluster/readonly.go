@@ -0,0 +1,28 @@
+package infracluster
+
+import (
+	gocontext "context"
+
+	corev1 "k8s.io/api/core/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NewReadOnly wraps an InfraCluster so that its clients perform no writes to the infra clusters: their creations,
+// updates, patches and deletions are sent as server-side dry-runs, which the infra clusters validate and admit
+// without persisting them. The REST configs are left as-is, for the commands run in the guests.
+func NewReadOnly(infraCluster InfraCluster) InfraCluster {
+	return &readOnlyInfraCluster{InfraCluster: infraCluster}
+}
+
+type readOnlyInfraCluster struct {
+	InfraCluster
+}
+
+// GenerateInfraClusterClient creates a dry-run client for infra cluster.
+func (r *readOnlyInfraCluster) GenerateInfraClusterClient(infraClusterSecretRef *corev1.ObjectReference, ownerNamespace string, context gocontext.Context) (k8sclient.Client, string, error) {
+	infraClusterClient, namespace, err := r.InfraCluster.GenerateInfraClusterClient(infraClusterSecretRef, ownerNamespace, context)
+	if err != nil {
+		return nil, "", err
+	}
+	return k8sclient.NewDryRunClient(infraClusterClient), namespace, nil
+}
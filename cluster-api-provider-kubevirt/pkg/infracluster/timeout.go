@@ -0,0 +1,102 @@
+package infracluster
+
+import (
+	gocontext "context"
+	"time"
+
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// newTimeoutClient wraps a client of an infra cluster so that each of its calls gives up after timeout, and an
+// unresponsive infra cluster does not hold the reconciles. The client is returned as-is when timeout is zero.
+func newTimeoutClient(client k8sclient.Client, timeout time.Duration) k8sclient.Client {
+	if client == nil || timeout <= 0 {
+		return client
+	}
+	return &timeoutClient{Client: client, timeout: timeout}
+}
+
+type timeoutClient struct {
+	k8sclient.Client
+	timeout time.Duration
+}
+
+// Get implements client.Client.
+func (c *timeoutClient) Get(ctx gocontext.Context, key k8sclient.ObjectKey, obj k8sclient.Object, opts ...k8sclient.GetOption) error {
+	ctx, cancel := gocontext.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.Client.Get(ctx, key, obj, opts...)
+}
+
+// List implements client.Client.
+func (c *timeoutClient) List(ctx gocontext.Context, list k8sclient.ObjectList, opts ...k8sclient.ListOption) error {
+	ctx, cancel := gocontext.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.Client.List(ctx, list, opts...)
+}
+
+// Create implements client.Client.
+func (c *timeoutClient) Create(ctx gocontext.Context, obj k8sclient.Object, opts ...k8sclient.CreateOption) error {
+	ctx, cancel := gocontext.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+// Delete implements client.Client.
+func (c *timeoutClient) Delete(ctx gocontext.Context, obj k8sclient.Object, opts ...k8sclient.DeleteOption) error {
+	ctx, cancel := gocontext.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+// Update implements client.Client.
+func (c *timeoutClient) Update(ctx gocontext.Context, obj k8sclient.Object, opts ...k8sclient.UpdateOption) error {
+	ctx, cancel := gocontext.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+// Patch implements client.Client.
+func (c *timeoutClient) Patch(ctx gocontext.Context, obj k8sclient.Object, patch k8sclient.Patch, opts ...k8sclient.PatchOption) error {
+	ctx, cancel := gocontext.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+// DeleteAllOf implements client.Client.
+func (c *timeoutClient) DeleteAllOf(ctx gocontext.Context, obj k8sclient.Object, opts ...k8sclient.DeleteAllOfOption) error {
+	ctx, cancel := gocontext.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+// Status implements client.Client.
+func (c *timeoutClient) Status() k8sclient.SubResourceWriter {
+	return &timeoutSubResourceWriter{SubResourceWriter: c.Client.Status(), timeout: c.timeout}
+}
+
+type timeoutSubResourceWriter struct {
+	k8sclient.SubResourceWriter
+	timeout time.Duration
+}
+
+// Create implements client.SubResourceWriter.
+func (w *timeoutSubResourceWriter) Create(ctx gocontext.Context, obj k8sclient.Object, subResource k8sclient.Object, opts ...k8sclient.SubResourceCreateOption) error {
+	ctx, cancel := gocontext.WithTimeout(ctx, w.timeout)
+	defer cancel()
+	return w.SubResourceWriter.Create(ctx, obj, subResource, opts...)
+}
+
+// Update implements client.SubResourceWriter.
+func (w *timeoutSubResourceWriter) Update(ctx gocontext.Context, obj k8sclient.Object, opts ...k8sclient.SubResourceUpdateOption) error {
+	ctx, cancel := gocontext.WithTimeout(ctx, w.timeout)
+	defer cancel()
+	return w.SubResourceWriter.Update(ctx, obj, opts...)
+}
+
+// Patch implements client.SubResourceWriter.
+func (w *timeoutSubResourceWriter) Patch(ctx gocontext.Context, obj k8sclient.Object, patch k8sclient.Patch, opts ...k8sclient.SubResourcePatchOption) error {
+	ctx, cancel := gocontext.WithTimeout(ctx, w.timeout)
+	defer cancel()
+	return w.SubResourceWriter.Patch(ctx, obj, patch, opts...)
+}
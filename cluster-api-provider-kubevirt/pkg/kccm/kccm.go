@@ -0,0 +1,248 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kccm deploys the KubeVirt cloud controller manager of a workload cluster next to its KubevirtCluster.
+// The cloud controller manager reaches the workload cluster with the kubeconfig generated by Cluster API, and the
+// infra cluster with the credentials of the KubevirtCluster.
+package kccm
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/hibernation"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/resources"
+)
+
+const (
+	// DefaultImage is the default image of the cloud controller manager.
+	DefaultImage = "quay.io/kubevirt/kubevirt-cloud-controller-manager:v0.5.1"
+
+	// templateKindLabel marks the objects deleted with the cluster by the KubevirtCluster controller.
+	templateKindLabel = "capk.cluster.x-k8s.io/template-kind"
+	appLabel          = "k8s-app"
+	appName           = "kubevirt-cloud-controller-manager"
+
+	cloudConfigKey           = "cloud-config"
+	cloudConfigDir           = "/etc/cloud"
+	kubeconfigDir            = "/etc/kubernetes/kubeconfig"
+	infraKubeconfigDir       = "/etc/kubernetes/infra-kubeconfig"
+	workloadKubeconfigKey    = "value"
+	infraKubeconfigSecretKey = "kubeconfig"
+)
+
+// Name returns the name of the objects of the cloud controller manager of a cluster.
+func Name(cluster *clusterv1.Cluster) string {
+	return cluster.Name + "-kccm"
+}
+
+// cloudConfig is the configuration file of the cloud controller manager.
+type cloudConfig struct {
+	Kubeconfig   string             `json:"kubeconfig,omitempty"`
+	Namespace    string             `json:"namespace"`
+	LoadBalancer loadBalancerConfig `json:"loadBalancer"`
+	InstancesV2  instancesV2Config  `json:"instancesV2"`
+}
+
+type loadBalancerConfig struct {
+	Enabled              bool `json:"enabled"`
+	CreationPollInterval int  `json:"creationPollInterval"`
+	CreationPollTimeout  int  `json:"creationPollTimeout"`
+}
+
+type instancesV2Config struct {
+	Enabled              bool `json:"enabled"`
+	ZoneAndRegionEnabled bool `json:"zoneAndRegionEnabled"`
+}
+
+// Reconcile creates or updates the cloud controller manager of the cluster in the namespace of the cluster,
+// managing the infra objects of the cluster in the given infra namespace. When the infra cluster is the
+// management cluster, the cloud controller manager runs with a service account bound to the permissions it needs;
+// otherwise, it uses the infra credentials of the cluster, which must be in a secret of the same namespace.
+// The existing objects are read with the reader, so that they do not need to be cached.
+func Reconcile(ctx *context.ClusterContext, reader client.Reader, c client.Client, infraNamespace string) error {
+	spec := ctx.KubevirtCluster.Spec.CloudControllerManager
+	infraSecretRef := ctx.KubevirtCluster.Spec.InfraClusterSecretRef
+	if infraSecretRef != nil && infraSecretRef.Namespace != "" && infraSecretRef.Namespace != ctx.KubevirtCluster.Namespace {
+		return fmt.Errorf("the infra cluster secret must be in the namespace of the cluster to deploy the cloud controller manager")
+	}
+
+	config := cloudConfig{
+		Namespace: infraNamespace,
+		LoadBalancer: loadBalancerConfig{
+			Enabled:              spec.LoadBalancer == nil || *spec.LoadBalancer,
+			CreationPollInterval: 5,
+			CreationPollTimeout:  60,
+		},
+		InstancesV2: instancesV2Config{Enabled: true},
+	}
+	if infraSecretRef != nil {
+		config.Kubeconfig = path.Join(infraKubeconfigDir, infraKubeconfigSecretKey)
+	}
+	configData, err := yaml.Marshal(config)
+	if err != nil {
+		return errors.Wrap(err, "failed to render the cloud config")
+	}
+
+	name := Name(ctx.Cluster)
+	labels := map[string]string{
+		clusterv1.ClusterNameLabel: ctx.Cluster.Name,
+		templateKindLabel:          "extra-resource",
+		appLabel:                   appName,
+	}
+	objectMeta := func(object client.Object) {
+		object.SetLabels(labels)
+	}
+
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: ctx.Cluster.Namespace, Name: name}}
+	if err := resources.CreateOrUpdate(ctx, reader, c, "ConfigMap", configMap, func() {
+		objectMeta(configMap)
+		configMap.Data = map[string]string{cloudConfigKey: string(configData)}
+	}); err != nil {
+		return err
+	}
+
+	serviceAccountName := ""
+	if infraSecretRef == nil {
+		serviceAccountName = name
+		if err := reconcileRBAC(ctx, reader, c, name, objectMeta); err != nil {
+			return err
+		}
+	}
+
+	template := podTemplate(ctx, spec, name, serviceAccountName, labels)
+	templateHash, err := resources.TemplateHash(template)
+	if err != nil {
+		return err
+	}
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: ctx.Cluster.Namespace, Name: name}}
+	return resources.CreateOrUpdate(ctx, reader, c, "Deployment", deployment, func() {
+		objectMeta(deployment)
+		deployment.Spec.Replicas = ptr.To(replicas(ctx.KubevirtCluster))
+		if deployment.Spec.Selector == nil {
+			deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{
+				clusterv1.ClusterNameLabel: ctx.Cluster.Name,
+				appLabel:                   appName,
+			}}
+		}
+		resources.SetPodTemplate(deployment, &deployment.Spec.Template, template, templateHash)
+	})
+}
+
+// reconcileRBAC grants the service account of the cloud controller manager the permissions it needs on the infra
+// objects of the cluster.
+func reconcileRBAC(ctx *context.ClusterContext, reader client.Reader, c client.Client, name string, objectMeta func(client.Object)) error {
+	serviceAccount := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: ctx.Cluster.Namespace, Name: name}}
+	if err := resources.CreateOrUpdate(ctx, reader, c, "ServiceAccount", serviceAccount, func() {
+		objectMeta(serviceAccount)
+	}); err != nil {
+		return err
+	}
+
+	role := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Namespace: ctx.Cluster.Namespace, Name: name}}
+	if err := resources.CreateOrUpdate(ctx, reader, c, "Role", role, func() {
+		objectMeta(role)
+		role.Rules = []rbacv1.PolicyRule{
+			{APIGroups: []string{"kubevirt.io"}, Resources: []string{"virtualmachines"}, Verbs: []string{"get", "list", "watch"}},
+			{APIGroups: []string{"kubevirt.io"}, Resources: []string{"virtualmachineinstances"}, Verbs: []string{"get", "list", "watch", "update"}},
+			{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list", "watch"}},
+			{APIGroups: []string{""}, Resources: []string{"services"}, Verbs: []string{"get", "list", "watch", "create", "update", "patch", "delete"}},
+		}
+	}); err != nil {
+		return err
+	}
+
+	roleBinding := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Namespace: ctx.Cluster.Namespace, Name: name}}
+	return resources.CreateOrUpdate(ctx, reader, c, "RoleBinding", roleBinding, func() {
+		objectMeta(roleBinding)
+		roleBinding.RoleRef = rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: name}
+		roleBinding.Subjects = []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Namespace: ctx.Cluster.Namespace, Name: name}}
+	})
+}
+
+// podTemplate returns the pod template of the cloud controller manager deployment.
+func podTemplate(ctx *context.ClusterContext, spec *infrav1.CloudControllerManagerSpec, name, serviceAccountName string, labels map[string]string) corev1.PodTemplateSpec {
+	image := spec.Image
+	if image == "" {
+		image = DefaultImage
+	}
+
+	container := corev1.Container{
+		Name:    appName,
+		Image:   image,
+		Command: []string{"/bin/kubevirt-cloud-controller-manager"},
+		Args: []string{
+			"--cloud-provider=kubevirt",
+			"--cloud-config=" + path.Join(cloudConfigDir, cloudConfigKey),
+			"--kubeconfig=" + path.Join(kubeconfigDir, workloadKubeconfigKey),
+			"--authentication-skip-lookup=true",
+			"--cluster-name=" + ctx.Cluster.Name,
+		},
+		Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("100m"),
+		}},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: "kubeconfig", MountPath: kubeconfigDir, ReadOnly: true},
+			{Name: "cloud-config", MountPath: cloudConfigDir, ReadOnly: true},
+		},
+	}
+	volumes := []corev1.Volume{
+		{Name: "kubeconfig", VolumeSource: corev1.VolumeSource{Secret: resources.WorkloadKubeconfigVolumeSource(ctx.Cluster.Name, ctx.KubevirtCluster)}},
+		{Name: "cloud-config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: name},
+		}}},
+	}
+	if secretRef := ctx.KubevirtCluster.Spec.InfraClusterSecretRef; secretRef != nil {
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{Name: "infra-kubeconfig", MountPath: infraKubeconfigDir, ReadOnly: true})
+		volumes = append(volumes, corev1.Volume{Name: "infra-kubeconfig", VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: secretRef.Name},
+		}})
+	}
+
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: labels},
+		Spec: corev1.PodSpec{
+			ServiceAccountName:           serviceAccountName,
+			AutomountServiceAccountToken: ptr.To(serviceAccountName != ""),
+			Containers:                   []corev1.Container{container},
+			Volumes:                      volumes,
+		},
+	}
+	resources.SetPodServiceMesh(&template.ObjectMeta, ctx.KubevirtCluster.Spec.ServiceMesh)
+	return template
+}
+
+// replicas returns the number of replicas of the controller: none while the cluster is hibernated, as its workload
+// cluster is not reachable.
+func replicas(kubevirtCluster *infrav1.KubevirtCluster) int32 {
+	if hibernation.IsClusterHibernated(kubevirtCluster) {
+		return 0
+	}
+	return 1
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kccm_test
+
+import (
+	gocontext "context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kccm"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("Cloud controller manager", func() {
+	var (
+		fakeClient      client.Client
+		kubevirtCluster *infrav1.KubevirtCluster
+		ctx             *context.ClusterContext
+		key             client.ObjectKey
+	)
+
+	BeforeEach(func() {
+		kubevirtCluster = testing.NewKubevirtCluster("test-cluster", "test-kubevirt-cluster")
+		kubevirtCluster.Spec.CloudControllerManager = &infrav1.CloudControllerManagerSpec{}
+		cluster := testing.NewCluster("test-cluster", kubevirtCluster)
+		ctx = &context.ClusterContext{
+			Logger:          ctrl.LoggerFrom(gocontext.TODO()).WithName("test"),
+			Context:         gocontext.TODO(),
+			Cluster:         cluster,
+			KubevirtCluster: kubevirtCluster,
+		}
+		key = client.ObjectKey{Namespace: cluster.Namespace, Name: kccm.Name(cluster)}
+		fakeClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).Build()
+	})
+
+	It("should deploy the cloud controller manager with a service account when the infra is local", func() {
+		Expect(kccm.Reconcile(ctx, fakeClient, fakeClient, kubevirtCluster.Namespace)).To(Succeed())
+
+		configMap := &corev1.ConfigMap{}
+		Expect(fakeClient.Get(ctx, key, configMap)).To(Succeed())
+		Expect(configMap.Data["cloud-config"]).To(ContainSubstring("namespace: " + kubevirtCluster.Namespace))
+		Expect(configMap.Data["cloud-config"]).NotTo(ContainSubstring("kubeconfig:"))
+
+		Expect(fakeClient.Get(ctx, key, &corev1.ServiceAccount{})).To(Succeed())
+		Expect(fakeClient.Get(ctx, key, &rbacv1.Role{})).To(Succeed())
+		roleBinding := &rbacv1.RoleBinding{}
+		Expect(fakeClient.Get(ctx, key, roleBinding)).To(Succeed())
+		Expect(roleBinding.RoleRef.Name).To(Equal(key.Name))
+
+		deployment := &appsv1.Deployment{}
+		Expect(fakeClient.Get(ctx, key, deployment)).To(Succeed())
+		Expect(deployment.Labels).To(HaveKeyWithValue("capk.cluster.x-k8s.io/template-kind", "extra-resource"))
+		podSpec := deployment.Spec.Template.Spec
+		Expect(podSpec.ServiceAccountName).To(Equal(key.Name))
+		Expect(podSpec.Containers[0].Image).To(Equal(kccm.DefaultImage))
+		Expect(podSpec.Containers[0].Args).To(ContainElement("--cluster-name=test-cluster"))
+		Expect(podSpec.Volumes[0].Secret.SecretName).To(Equal("test-cluster-kubeconfig"))
+	})
+
+	It("should use the infra kubeconfig of the cluster when the infra is external", func() {
+		kubevirtCluster.Spec.InfraClusterSecretRef = &corev1.ObjectReference{Name: "infra-kubeconfig"}
+		kubevirtCluster.Spec.CloudControllerManager.LoadBalancer = ptr.To(false)
+		Expect(kccm.Reconcile(ctx, fakeClient, fakeClient, "infra-namespace")).To(Succeed())
+
+		configMap := &corev1.ConfigMap{}
+		Expect(fakeClient.Get(ctx, key, configMap)).To(Succeed())
+		Expect(configMap.Data["cloud-config"]).To(ContainSubstring("namespace: infra-namespace"))
+		Expect(configMap.Data["cloud-config"]).To(ContainSubstring("kubeconfig: /etc/kubernetes/infra-kubeconfig/kubeconfig"))
+		Expect(configMap.Data["cloud-config"]).To(MatchRegexp(`loadBalancer:\n\s+creationPollInterval: 5\n\s+creationPollTimeout: 60\n\s+enabled: false`))
+
+		Expect(fakeClient.Get(ctx, key, &corev1.ServiceAccount{})).NotTo(Succeed())
+		deployment := &appsv1.Deployment{}
+		Expect(fakeClient.Get(ctx, key, deployment)).To(Succeed())
+		Expect(deployment.Spec.Template.Spec.ServiceAccountName).To(BeEmpty())
+		Expect(deployment.Spec.Template.Spec.Volumes).To(ContainElement(HaveField("Secret.SecretName", "infra-kubeconfig")))
+	})
+
+	It("should only update the deployment when its pod template changes", func() {
+		Expect(kccm.Reconcile(ctx, fakeClient, fakeClient, kubevirtCluster.Namespace)).To(Succeed())
+		deployment := &appsv1.Deployment{}
+		Expect(fakeClient.Get(ctx, key, deployment)).To(Succeed())
+		resourceVersion := deployment.ResourceVersion
+
+		Expect(kccm.Reconcile(ctx, fakeClient, fakeClient, kubevirtCluster.Namespace)).To(Succeed())
+		Expect(fakeClient.Get(ctx, key, deployment)).To(Succeed())
+		Expect(deployment.ResourceVersion).To(Equal(resourceVersion))
+
+		kubevirtCluster.Spec.CloudControllerManager.Image = "example.com/kccm:dev"
+		Expect(kccm.Reconcile(ctx, fakeClient, fakeClient, kubevirtCluster.Namespace)).To(Succeed())
+		Expect(fakeClient.Get(ctx, key, deployment)).To(Succeed())
+		Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("example.com/kccm:dev"))
+	})
+
+	It("should scale the cloud controller manager down while the cluster is hibernated", func() {
+		kubevirtCluster.Status.HibernationState = infrav1.HibernationStateHibernating
+		Expect(kccm.Reconcile(ctx, fakeClient, fakeClient, kubevirtCluster.Namespace)).To(Succeed())
+		deployment := &appsv1.Deployment{}
+		Expect(fakeClient.Get(ctx, key, deployment)).To(Succeed())
+		Expect(deployment.Spec.Replicas).To(HaveValue(BeEquivalentTo(0)))
+
+		kubevirtCluster.Status.HibernationState = infrav1.HibernationStateResuming
+		Expect(kccm.Reconcile(ctx, fakeClient, fakeClient, kubevirtCluster.Namespace)).To(Succeed())
+		Expect(fakeClient.Get(ctx, key, deployment)).To(Succeed())
+		Expect(deployment.Spec.Replicas).To(HaveValue(BeEquivalentTo(1)))
+	})
+
+	It("should exclude the cloud controller manager from the service mesh", func() {
+		kubevirtCluster.Spec.ServiceMesh = &infrav1.ServiceMeshSpec{Type: infrav1.IstioServiceMesh}
+		Expect(kccm.Reconcile(ctx, fakeClient, fakeClient, kubevirtCluster.Namespace)).To(Succeed())
+
+		deployment := &appsv1.Deployment{}
+		Expect(fakeClient.Get(ctx, key, deployment)).To(Succeed())
+		Expect(deployment.Spec.Template.Labels).To(HaveKeyWithValue("sidecar.istio.io/inject", "false"))
+		Expect(deployment.Spec.Template.Annotations).To(HaveKeyWithValue("sidecar.istio.io/inject", "false"))
+		Expect(deployment.Labels).NotTo(HaveKey("sidecar.istio.io/inject"))
+	})
+
+	It("should mount the workload kubeconfig the cluster overrides", func() {
+		kubevirtCluster.Spec.WorkloadKubeconfig = &infrav1.WorkloadKubeconfigSource{
+			SecretRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "external-kubeconfig"},
+				Key:                  "admin.conf",
+			},
+		}
+		Expect(kccm.Reconcile(ctx, fakeClient, fakeClient, kubevirtCluster.Namespace)).To(Succeed())
+
+		deployment := &appsv1.Deployment{}
+		Expect(fakeClient.Get(ctx, key, deployment)).To(Succeed())
+		Expect(deployment.Spec.Template.Spec.Volumes[0].Secret.SecretName).To(Equal("external-kubeconfig"))
+		Expect(deployment.Spec.Template.Spec.Volumes[0].Secret.Items).To(Equal([]corev1.KeyToPath{{Key: "admin.conf", Path: "value"}}))
+	})
+
+	It("should require the infra kubeconfig to be in the namespace of the cluster", func() {
+		kubevirtCluster.Spec.InfraClusterSecretRef = &corev1.ObjectReference{Namespace: "elsewhere", Name: "infra-kubeconfig"}
+		Expect(kccm.Reconcile(ctx, fakeClient, fakeClient, "infra-namespace")).NotTo(Succeed())
+	})
+})
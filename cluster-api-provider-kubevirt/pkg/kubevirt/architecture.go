@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+// architectureMachineTypes are the machine types emulated by default for the guests of each architecture, and the
+// prefix of the machine types available for it, e.g. pc-q35-rhel9.2.0 on amd64.
+var architectureMachineTypes = map[string]struct{ defaultType, prefix string }{
+	"amd64": {defaultType: "q35", prefix: "q35"},
+	"arm64": {defaultType: "virt", prefix: "virt"},
+	"s390x": {defaultType: "s390-ccw-virtio", prefix: "s390-ccw-virtio"},
+}
+
+// MachineArchitecture returns the CPU architecture of the guest of the machine: the one of its spec, or else the
+// one of the image it boots, if any. It is empty when the machine leaves it to the default of KubeVirt.
+func MachineArchitecture(ctx *context.MachineContext) string {
+	if ctx.KubevirtMachine.Spec.Architecture != "" {
+		return ctx.KubevirtMachine.Spec.Architecture
+	}
+	if ctx.KubevirtMachine.Spec.Image != nil && ctx.MachineImage != nil {
+		return MachineImageArchitecture(ctx.MachineImage)
+	}
+	return ""
+}
+
+// MachineTypeFor returns the machine type emulated for the guests of the architecture when the machine sets none,
+// or an empty string to leave it to KubeVirt.
+func MachineTypeFor(architecture string) string {
+	return architectureMachineTypes[architecture].defaultType
+}
+
+// ArchitectureConflicts returns a message describing the settings of the machine its architecture cannot run, or an
+// empty string if there are none.
+func ArchitectureConflicts(spec *infrav1.KubevirtMachineSpec) string {
+	architecture := spec.Architecture
+	if architecture == "" {
+		return ""
+	}
+
+	var conflicts []string
+	var vmiSpec *kubevirtv1.VirtualMachineInstanceSpec
+	if template := spec.VirtualMachineTemplate.Spec.Template; template != nil {
+		vmiSpec = &template.Spec
+	}
+	if vmiSpec != nil && vmiSpec.Architecture != "" && vmiSpec.Architecture != architecture {
+		conflicts = append(conflicts, fmt.Sprintf("the VMI template runs the %s architecture", vmiSpec.Architecture))
+	}
+
+	machineType := spec.MachineType
+	if vmiSpec != nil && vmiSpec.Domain.Machine != nil && vmiSpec.Domain.Machine.Type != "" {
+		machineType = vmiSpec.Domain.Machine.Type
+	}
+	if prefix := architectureMachineTypes[architecture].prefix; machineType != "" && prefix != "" && !strings.Contains(machineType, prefix) {
+		conflicts = append(conflicts, fmt.Sprintf("machine type %s is not a %s machine type", machineType, prefix))
+	}
+
+	// The arm64 guests only boot with UEFI, without secure boot
+	if architecture == "arm64" && vmiSpec != nil && vmiSpec.Domain.Firmware != nil && vmiSpec.Domain.Firmware.Bootloader != nil {
+		bootloader := vmiSpec.Domain.Firmware.Bootloader
+		if bootloader.BIOS != nil {
+			conflicts = append(conflicts, "arm64 guests do not boot with BIOS")
+		}
+		if bootloader.EFI != nil && ptr.Deref(bootloader.EFI.SecureBoot, true) {
+			conflicts = append(conflicts, "arm64 guests do not support secure boot")
+		}
+	}
+
+	if len(conflicts) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("architecture %s conflicts with the VM template: %s", architecture, strings.Join(conflicts, ", "))
+}
+
+// setArchitecture sets the architecture, the machine type and the firmware of the guest in the VMI spec, and
+// schedules the VMI on the infra nodes of its architecture. The values set in the VMI template take precedence.
+func setArchitecture(spec *kubevirtv1.VirtualMachineInstanceSpec, ctx *context.MachineContext) {
+	machineType := ctx.KubevirtMachine.Spec.MachineType
+	if machineType == "" {
+		machineType = MachineTypeFor(ctx.KubevirtMachine.Spec.Architecture)
+	}
+	if machineType != "" && spec.Domain.Machine == nil {
+		spec.Domain.Machine = &kubevirtv1.Machine{Type: machineType}
+	}
+	// KubeVirt boots the arm64 guests with UEFI, which does not support secure boot there
+	if ctx.KubevirtMachine.Spec.Architecture == "arm64" && (spec.Domain.Firmware == nil || spec.Domain.Firmware.Bootloader == nil) {
+		if spec.Domain.Firmware == nil {
+			spec.Domain.Firmware = &kubevirtv1.Firmware{}
+		}
+		spec.Domain.Firmware.Bootloader = &kubevirtv1.Bootloader{EFI: &kubevirtv1.EFI{SecureBoot: ptr.To(false)}}
+	}
+
+	architecture := MachineArchitecture(ctx)
+	if architecture == "" {
+		return
+	}
+	// The architecture of the images is only used for scheduling, KubeVirt defaulting the one of the guest
+	if ctx.KubevirtMachine.Spec.Architecture != "" && spec.Architecture == "" {
+		spec.Architecture = architecture
+	}
+	if spec.NodeSelector == nil {
+		spec.NodeSelector = map[string]string{}
+	}
+	if _, ok := spec.NodeSelector[corev1.LabelArchStable]; !ok {
+		spec.NodeSelector[corev1.LabelArchStable] = architecture
+	}
+}
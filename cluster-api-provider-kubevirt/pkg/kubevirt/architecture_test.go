@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+var _ = Describe("Architecture", func() {
+	var machineContext *context.MachineContext
+
+	BeforeEach(func() {
+		machineContext = &context.MachineContext{
+			Cluster:         cluster,
+			KubevirtCluster: kubevirtCluster,
+			Machine:         machine,
+			KubevirtMachine: kubevirtMachine.DeepCopy(),
+		}
+	})
+
+	It("should run the VMs with the architecture and the machine type of the machine", func() {
+		machineContext.KubevirtMachine.Spec.Architecture = "arm64"
+		machineContext.KubevirtMachine.Spec.MachineType = "virt"
+
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+
+		Expect(newVM.Spec.Template.Spec.Architecture).To(Equal("arm64"))
+		Expect(newVM.Spec.Template.Spec.Domain.Machine).To(Equal(&kubevirtv1.Machine{Type: "virt"}))
+		Expect(newVM.Spec.Template.Spec.NodeSelector).To(HaveKeyWithValue(corev1.LabelArchStable, "arm64"))
+	})
+
+	It("should keep the values of the VM template", func() {
+		machineContext.KubevirtMachine.Spec.Architecture = "arm64"
+		machineContext.KubevirtMachine.Spec.MachineType = "virt"
+		templateSpec := &machineContext.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.Template.Spec
+		templateSpec.Domain.Machine = &kubevirtv1.Machine{Type: "virt-8.2"}
+		templateSpec.NodeSelector = map[string]string{corev1.LabelArchStable: "arm64", "pool": "arm"}
+
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+
+		Expect(newVM.Spec.Template.Spec.Domain.Machine).To(Equal(&kubevirtv1.Machine{Type: "virt-8.2"}))
+		Expect(newVM.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{corev1.LabelArchStable: "arm64", "pool": "arm"}))
+	})
+
+	It("should default the machine type and the firmware of the architecture of the machine", func() {
+		machineContext.KubevirtMachine.Spec.Architecture = "arm64"
+
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+
+		Expect(newVM.Spec.Template.Spec.Domain.Machine).To(Equal(&kubevirtv1.Machine{Type: "virt"}))
+		Expect(newVM.Spec.Template.Spec.Domain.Firmware.Bootloader).To(Equal(&kubevirtv1.Bootloader{EFI: &kubevirtv1.EFI{SecureBoot: ptr.To(false)}}))
+
+		machineContext.KubevirtMachine.Spec.Architecture = "amd64"
+
+		newVM = newVirtualMachineFromKubevirtMachine(machineContext, "default")
+
+		Expect(newVM.Spec.Template.Spec.Domain.Machine).To(Equal(&kubevirtv1.Machine{Type: "q35"}))
+		Expect(newVM.Spec.Template.Spec.Domain.Firmware).To(BeNil())
+	})
+
+	DescribeTable("should report the settings of the VM template the architecture cannot run", func(machineType string, template kubevirtv1.VirtualMachineInstanceSpec, expected string) {
+		spec := machineContext.KubevirtMachine.Spec.DeepCopy()
+		spec.Architecture = "arm64"
+		spec.MachineType = machineType
+		spec.VirtualMachineTemplate.Spec.Template.Spec = template
+
+		Expect(ArchitectureConflicts(spec)).To(Equal(expected))
+	},
+		Entry("none", "virt-rhel9.2.0", kubevirtv1.VirtualMachineInstanceSpec{}, ""),
+		Entry("machine type of another architecture", "pc-q35-rhel9.2.0", kubevirtv1.VirtualMachineInstanceSpec{},
+			"architecture arm64 conflicts with the VM template: machine type pc-q35-rhel9.2.0 is not a virt machine type"),
+		Entry("machine type of the VM template", "", kubevirtv1.VirtualMachineInstanceSpec{Domain: kubevirtv1.DomainSpec{Machine: &kubevirtv1.Machine{Type: "q35"}}},
+			"architecture arm64 conflicts with the VM template: machine type q35 is not a virt machine type"),
+		Entry("architecture of the VM template", "", kubevirtv1.VirtualMachineInstanceSpec{Architecture: "amd64"},
+			"architecture arm64 conflicts with the VM template: the VMI template runs the amd64 architecture"),
+		Entry("BIOS", "", kubevirtv1.VirtualMachineInstanceSpec{Domain: kubevirtv1.DomainSpec{Firmware: &kubevirtv1.Firmware{Bootloader: &kubevirtv1.Bootloader{BIOS: &kubevirtv1.BIOS{}}}}},
+			"architecture arm64 conflicts with the VM template: arm64 guests do not boot with BIOS"),
+		Entry("secure boot", "", kubevirtv1.VirtualMachineInstanceSpec{Domain: kubevirtv1.DomainSpec{Firmware: &kubevirtv1.Firmware{Bootloader: &kubevirtv1.Bootloader{EFI: &kubevirtv1.EFI{}}}}},
+			"architecture arm64 conflicts with the VM template: arm64 guests do not support secure boot"),
+	)
+
+	It("should leave the architecture of the machines not specifying any to KubeVirt", func() {
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+
+		Expect(MachineArchitecture(machineContext)).To(BeEmpty())
+		Expect(newVM.Spec.Template.Spec.Architecture).To(BeEmpty())
+		Expect(newVM.Spec.Template.Spec.Domain.Machine).To(BeNil())
+		Expect(newVM.Spec.Template.Spec.NodeSelector).ToNot(HaveKey(corev1.LabelArchStable))
+	})
+})
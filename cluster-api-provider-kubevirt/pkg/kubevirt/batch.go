@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	"fmt"
+
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+const (
+	// defaultBatchSize is the number of VMs created in a batch when not set.
+	defaultBatchSize = 50
+	// defaultBatchParallelism is the number of VMs of a batch created concurrently when not set.
+	defaultBatchParallelism = 10
+)
+
+// BatchSize returns the maximum number of VMs created in a batch, including the VM of the machine creating it.
+func BatchSize(spec *infrav1.BatchedVMCreationSpec) int {
+	if spec == nil || spec.MaxBatchSize <= 0 {
+		return defaultBatchSize
+	}
+	return int(spec.MaxBatchSize)
+}
+
+// BatchParallelism returns the number of VMs of a batch created concurrently.
+func BatchParallelism(spec *infrav1.BatchedVMCreationSpec) int {
+	if spec == nil || spec.Parallelism <= 0 {
+		return defaultBatchParallelism
+	}
+	return int(spec.Parallelism)
+}
+
+// shareDataVolumeSources makes the disks of the VM imported from an http or registry source clone the disks of the
+// machine creating its batch instead, which CDI starts once their import completes. It runs before the names of the
+// DataVolumes are prefixed with the name of the machine.
+func shareDataVolumeSources(vm *kubevirtv1.VirtualMachine, ctx *context.MachineContext) {
+	if ctx.DataVolumeSourceMachine == "" {
+		return
+	}
+	for i := range vm.Spec.DataVolumeTemplates {
+		template := &vm.Spec.DataVolumeTemplates[i]
+		source := template.Spec.Source
+		if source == nil || (source.HTTP == nil && source.Registry == nil) {
+			continue
+		}
+		template.Spec.Source = &cdiv1.DataVolumeSource{
+			PVC: &cdiv1.DataVolumeSourcePVC{
+				Namespace: vm.Namespace,
+				Name:      fmt.Sprintf("%s-%s", ctx.DataVolumeSourceMachine, template.Name),
+			},
+		}
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+var _ = Describe("Batched VM creation", func() {
+	var machineContext *context.MachineContext
+
+	BeforeEach(func() {
+		machineContext = &context.MachineContext{
+			Cluster:         cluster,
+			KubevirtCluster: kubevirtCluster,
+			Machine:         machine,
+			KubevirtMachine: kubevirtMachine.DeepCopy(),
+		}
+		machineContext.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.DataVolumeTemplates = []kubevirtv1.DataVolumeTemplateSpec{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "root"},
+				Spec: cdiv1.DataVolumeSpec{
+					Source: &cdiv1.DataVolumeSource{Registry: &cdiv1.DataVolumeSourceRegistry{URL: ptr.To("docker://ubuntu:22.04")}},
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "data"},
+				Spec: cdiv1.DataVolumeSpec{
+					Source: &cdiv1.DataVolumeSource{Blank: &cdiv1.DataVolumeBlankImage{}},
+				},
+			},
+		}
+	})
+
+	It("should default the size and the parallelism of the batches", func() {
+		Expect(BatchSize(nil)).To(Equal(50))
+		Expect(BatchParallelism(&infrav1.BatchedVMCreationSpec{})).To(Equal(10))
+		Expect(BatchSize(&infrav1.BatchedVMCreationSpec{MaxBatchSize: 20})).To(Equal(20))
+		Expect(BatchParallelism(&infrav1.BatchedVMCreationSpec{Parallelism: 4})).To(Equal(4))
+	})
+
+	It("should import the disks of the VMs created out of a batch", func() {
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+
+		Expect(newVM.Spec.DataVolumeTemplates[0].Spec.Source.Registry).NotTo(BeNil())
+		Expect(newVM.Spec.DataVolumeTemplates[0].Spec.Source.PVC).To(BeNil())
+	})
+
+	It("should clone the imported disks of the machine creating the batch", func() {
+		machineContext.DataVolumeSourceMachine = "first"
+
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+
+		root := newVM.Spec.DataVolumeTemplates[0]
+		Expect(root.Name).To(Equal(machineContext.KubevirtMachine.Name + "-root"))
+		Expect(root.Spec.Source.Registry).To(BeNil())
+		Expect(root.Spec.Source.PVC).To(Equal(&cdiv1.DataVolumeSourcePVC{Namespace: "default", Name: "first-root"}))
+		Expect(newVM.Spec.DataVolumeTemplates[1].Spec.Source.Blank).NotTo(BeNil())
+	})
+})
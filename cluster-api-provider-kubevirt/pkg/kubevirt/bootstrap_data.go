@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	gocontext "context"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+// bootstrapDataHash returns the hash of the bootstrap data rendered for the machine in its userdata secret, or an
+// empty string if it was not rendered yet.
+func bootstrapDataHash(ctx *context.MachineContext) string {
+	if ctx.BootstrapDataSecret == nil {
+		return ""
+	}
+	h := fnv.New32a()
+	_, _ = h.Write(ctx.BootstrapDataSecret.Data["userdata"])
+	return fmt.Sprintf("%08x", h.Sum32())
+}
+
+// setBootstrapData points the cloud-init volume of the VMI template to the userdata secret, and records the hash
+// of its data. It returns true if the template changed.
+func setBootstrapData(template *kubevirtv1.VirtualMachineInstanceTemplateSpec, secretName, hash string) bool {
+	changed := false
+	if hash != "" && template.ObjectMeta.Annotations[infrav1.BootstrapDataHashAnnotation] != hash {
+		if template.ObjectMeta.Annotations == nil {
+			template.ObjectMeta.Annotations = map[string]string{}
+		}
+		template.ObjectMeta.Annotations[infrav1.BootstrapDataHashAnnotation] = hash
+		changed = true
+	}
+	for i := range template.Spec.Volumes {
+		volume := &template.Spec.Volumes[i]
+		if volume.Name != cloudInitVolumeName || volume.CloudInitConfigDrive == nil {
+			continue
+		}
+		if ref := volume.CloudInitConfigDrive.UserDataSecretRef; ref == nil || ref.Name != secretName {
+			volume.CloudInitConfigDrive.UserDataSecretRef = &corev1.LocalObjectReference{Name: secretName}
+			changed = true
+		}
+	}
+	return changed
+}
+
+// hasBootstrapData returns true if the VMI boots with the bootstrap data of the given userdata secret and hash.
+func hasBootstrapData(vmi *kubevirtv1.VirtualMachineInstance, secretName, hash string) bool {
+	if vmi.Annotations[infrav1.BootstrapDataHashAnnotation] != hash {
+		return false
+	}
+	for _, volume := range vmi.Spec.Volumes {
+		if volume.Name == cloudInitVolumeName && volume.CloudInitConfigDrive != nil {
+			ref := volume.CloudInitConfigDrive.UserDataSecretRef
+			return ref != nil && ref.Name == secretName
+		}
+	}
+	return true
+}
+
+// isBooting returns true while the guest of the VMI did not start yet, so that it did not consume its cloud-init
+// volume: the volume is rendered when the VMI starts, and read once by the guest.
+func isBooting(vmi *kubevirtv1.VirtualMachineInstance) bool {
+	switch vmi.Status.Phase {
+	case "", kubevirtv1.Pending, kubevirtv1.Scheduling, kubevirtv1.Scheduled:
+		return true
+	default:
+		return false
+	}
+}
+
+// RedeliverBootstrapData updates the VM to boot with the current bootstrap data of the machine when the data
+// changed after the VM was created, e.g. when the bootstrap token expired before the VM could boot. A VMI that is
+// still starting is deleted, to be started again with the new data; the VMIs whose guest started are left alone,
+// as cloud-init does not run again for them. It returns true if the VM or its VMI was updated.
+func (m *Machine) RedeliverBootstrapData(ctx gocontext.Context) (bool, error) {
+	if m.vmInstance == nil || m.vmInstance.Spec.Template == nil {
+		return false, nil
+	}
+	hash := bootstrapDataHash(m.machineContext)
+	if hash == "" || (m.vmiInstance != nil && !isBooting(m.vmiInstance)) {
+		return false, nil
+	}
+	secretName := UserDataSecretName(m.machineContext)
+
+	redelivered := false
+	vm := m.vmInstance.DeepCopy()
+	if setBootstrapData(vm.Spec.Template, secretName, hash) {
+		if err := m.client.Patch(ctx, vm, client.MergeFrom(m.vmInstance)); err != nil {
+			return false, errors.Wrapf(err, "failed to update the bootstrap data of VM %s/%s", vm.Namespace, vm.Name)
+		}
+		m.vmInstance = vm
+		redelivered = true
+	}
+
+	if m.vmiInstance != nil && m.vmiInstance.DeletionTimestamp.IsZero() && !hasBootstrapData(m.vmiInstance, secretName, hash) {
+		if err := m.client.Delete(ctx, m.vmiInstance); err != nil && !apierrors.IsNotFound(err) {
+			return false, errors.Wrapf(err, "failed to restart VMI %s/%s", m.vmiInstance.Namespace, m.vmiInstance.Name)
+		}
+		redelivered = true
+	}
+	return redelivered, nil
+}
+
+// HoldVirtualMachine stops the VM until its join token is refreshed, and records the run strategy to restore when
+// it is released. It returns true once the VM is stopped.
+func HoldVirtualMachine(ctx gocontext.Context, c client.Client, vm *kubevirtv1.VirtualMachine) (bool, error) {
+	return haltVirtualMachine(ctx, c, vm, infrav1.BootstrapTokenHoldRunStrategyAnnotation)
+}
+
+// ReleaseVirtualMachine restores the run strategy the VM had before it was held. It is a no-op for a VM that is
+// not held.
+func ReleaseVirtualMachine(ctx gocontext.Context, c client.Client, vm *kubevirtv1.VirtualMachine) error {
+	return restoreVirtualMachine(ctx, c, vm, infrav1.BootstrapTokenHoldRunStrategyAnnotation)
+}
+
+// IsHeld returns true if the start of the VM is held until its join token is refreshed.
+func IsHeld(vm *kubevirtv1.VirtualMachine) bool {
+	_, held := vm.Annotations[infrav1.BootstrapTokenHoldRunStrategyAnnotation]
+	return held
+}
+
+// IsBooted returns true if the guest of the VMI started, and consumed its cloud-init volume.
+func IsBooted(vmi *kubevirtv1.VirtualMachineInstance) bool {
+	return vmi != nil && !isBooting(vmi)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	gocontext "context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("Bootstrap data redelivery", func() {
+	var (
+		machineContext *context.MachineContext
+		virtualMachine *kubevirtv1.VirtualMachine
+		vmi            *kubevirtv1.VirtualMachineInstance
+		previousHash   string
+	)
+	namespace := kubevirtMachine.Namespace
+
+	BeforeEach(func() {
+		machineContext = &context.MachineContext{
+			Context:             gocontext.TODO(),
+			Cluster:             cluster,
+			KubevirtCluster:     kubevirtCluster,
+			Machine:             machine,
+			KubevirtMachine:     kubevirtMachine,
+			BootstrapDataSecret: bootstrapDataSecret.DeepCopy(),
+			Logger:              logger,
+		}
+		virtualMachine = newVirtualMachineFromKubevirtMachine(machineContext, namespace)
+		previousHash = virtualMachine.Spec.Template.ObjectMeta.Annotations[v1alpha1.BootstrapDataHashAnnotation]
+
+		vmi = testing.NewVirtualMachineInstance(kubevirtMachine)
+		vmi.Annotations = map[string]string{v1alpha1.BootstrapDataHashAnnotation: previousHash}
+		vmi.Spec.Volumes = virtualMachine.Spec.Template.Spec.Volumes
+		vmi.Status.Phase = kubevirtv1.Scheduled
+
+		machineContext.BootstrapDataSecret.Data["userdata"] = []byte("#cloud-config\nruncmd: [kubeadm join --token new]\n")
+	})
+
+	redeliver := func(objects ...client.Object) bool {
+		fakeClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(objects...).Build()
+		externalMachine, err := defaultTestMachine(machineContext, namespace, fakeClient, FakeVMCommandExecutor{true}, []byte(sshKey))
+		Expect(err).NotTo(HaveOccurred())
+		redelivered, err := externalMachine.RedeliverBootstrapData(gocontext.Background())
+		Expect(err).NotTo(HaveOccurred())
+		return redelivered
+	}
+
+	It("should record the hash of the bootstrap data in the VMI template", func() {
+		Expect(previousHash).To(HaveLen(8))
+	})
+
+	It("should restart a starting VMI with the new bootstrap data", func() {
+		Expect(redeliver(virtualMachine, vmi)).To(BeTrue())
+
+		vm := &kubevirtv1.VirtualMachine{}
+		Expect(fakeClient.Get(gocontext.Background(), client.ObjectKeyFromObject(virtualMachine), vm)).To(Succeed())
+		Expect(vm.Spec.Template.ObjectMeta.Annotations).To(HaveKeyWithValue(v1alpha1.BootstrapDataHashAnnotation, bootstrapDataHash(machineContext)))
+		Expect(bootstrapDataHash(machineContext)).ToNot(Equal(previousHash))
+		Expect(apierrors.IsNotFound(fakeClient.Get(gocontext.Background(), client.ObjectKeyFromObject(vmi), &kubevirtv1.VirtualMachineInstance{}))).To(BeTrue())
+	})
+
+	It("should update a stopped VM to boot with the new bootstrap data", func() {
+		virtualMachine.Spec.Template.Spec.Volumes[len(virtualMachine.Spec.Template.Spec.Volumes)-1].CloudInitConfigDrive.UserDataSecretRef.Name = "previous-userdata"
+
+		Expect(redeliver(virtualMachine)).To(BeTrue())
+
+		vm := &kubevirtv1.VirtualMachine{}
+		Expect(fakeClient.Get(gocontext.Background(), client.ObjectKeyFromObject(virtualMachine), vm)).To(Succeed())
+		Expect(vm.Spec.Template.ObjectMeta.Annotations).To(HaveKeyWithValue(v1alpha1.BootstrapDataHashAnnotation, bootstrapDataHash(machineContext)))
+		volumes := vm.Spec.Template.Spec.Volumes
+		Expect(volumes[len(volumes)-1].CloudInitConfigDrive.UserDataSecretRef.Name).To(Equal(UserDataSecretName(machineContext)))
+	})
+
+	It("should leave a VMI whose guest started alone", func() {
+		vmi.Status.Phase = kubevirtv1.Running
+
+		Expect(redeliver(virtualMachine, vmi)).To(BeFalse())
+
+		Expect(fakeClient.Get(gocontext.Background(), client.ObjectKeyFromObject(vmi), &kubevirtv1.VirtualMachineInstance{})).To(Succeed())
+		vm := &kubevirtv1.VirtualMachine{}
+		Expect(fakeClient.Get(gocontext.Background(), client.ObjectKeyFromObject(virtualMachine), vm)).To(Succeed())
+		Expect(vm.Spec.Template.ObjectMeta.Annotations).To(HaveKeyWithValue(v1alpha1.BootstrapDataHashAnnotation, previousHash))
+	})
+
+	It("should not restart a VMI booting with the current bootstrap data", func() {
+		machineContext.BootstrapDataSecret = bootstrapDataSecret.DeepCopy()
+
+		Expect(redeliver(virtualMachine, vmi)).To(BeFalse())
+
+		Expect(fakeClient.Get(gocontext.Background(), client.ObjectKeyFromObject(vmi), &kubevirtv1.VirtualMachineInstance{})).To(Succeed())
+	})
+})
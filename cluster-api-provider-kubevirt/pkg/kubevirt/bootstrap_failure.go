@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	"strings"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+// maxBootstrapFailureMessageLength bounds the line of the bootstrap log reported with a failure.
+const maxBootstrapFailureMessageLength = 512
+
+// bootstrapFailureSignals are the lowercase fragments of the lines of the bootstrap log showing a failure, by
+// reason. A line matching several reasons gets the first one: kubeadm reports a rejected token as a failure of its
+// preflight phase, and a failed image pull with the error of the network.
+var bootstrapFailureSignals = []struct {
+	reason    string
+	fragments []string
+}{
+	{
+		reason: infrav1.BootstrapTokenExpiredReason,
+		fragments: []string{
+			"could not find a jws signature",
+			"token id",
+			"bootstrap token",
+			"unauthorized",
+		},
+	},
+	{
+		reason: infrav1.BootstrapImagePullFailedReason,
+		fragments: []string{
+			"failed to pull image",
+			"error pulling image",
+			"errimagepull",
+			"imagepullbackoff",
+			"pull access denied",
+		},
+	},
+	{
+		reason: infrav1.BootstrapNetworkUnreachableReason,
+		fragments: []string{
+			"network is unreachable",
+			"no route to host",
+			"temporary failure in name resolution",
+			"i/o timeout",
+			"connection timed out",
+			"connection refused",
+		},
+	},
+	{
+		reason: infrav1.BootstrapPreflightFailedReason,
+		fragments: []string{
+			"error execution phase preflight",
+			"[error",
+		},
+	},
+}
+
+// ClassifyBootstrapLog returns the reason of the last failure shown by the bootstrap log, with the line showing it.
+// The reason is empty when the log shows no known failure.
+func ClassifyBootstrapLog(log string) (string, string) {
+	lines := strings.Split(log, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		lower := strings.ToLower(line)
+		for _, signal := range bootstrapFailureSignals {
+			for _, fragment := range signal.fragments {
+				if strings.Contains(lower, fragment) {
+					if len(line) > maxBootstrapFailureMessageLength {
+						line = line[:maxBootstrapFailureMessageLength]
+					}
+					return signal.reason, line
+				}
+			}
+		}
+	}
+	return "", ""
+}
+
+// IsTransientBootstrapFailure returns true if the bootstrap may succeed when retried after a failure of the given
+// reason.
+func IsTransientBootstrapFailure(reason string) bool {
+	switch reason {
+	case infrav1.BootstrapNetworkUnreachableReason, infrav1.BootstrapImagePullFailedReason, infrav1.BootstrapTokenExpiredReason:
+		return true
+	}
+	return false
+}
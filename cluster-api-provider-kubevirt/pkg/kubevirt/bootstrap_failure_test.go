@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+var _ = Describe("Bootstrap failure classification", func() {
+	DescribeTable("should classify the last failure of the bootstrap log",
+		func(log, reason, message string) {
+			actualReason, actualMessage := ClassifyBootstrapLog(log)
+			Expect(actualReason).To(Equal(reason))
+			Expect(actualMessage).To(Equal(message))
+		},
+		Entry("expired join token",
+			"[preflight] Running pre-flight checks\n"+
+				"error execution phase preflight: couldn't validate the identity of the API Server: could not find a JWS signature in the cluster-info ConfigMap for token ID \"abcdef\"\n",
+			infrav1.BootstrapTokenExpiredReason,
+			"error execution phase preflight: couldn't validate the identity of the API Server: could not find a JWS signature in the cluster-info ConfigMap for token ID \"abcdef\""),
+		Entry("image pull failure",
+			"[preflight] Pulling images required for setting up a Kubernetes cluster\n"+
+				"\t[ERROR ImagePull]: failed to pull image registry.k8s.io/kube-apiserver:v1.30.1: output: dial tcp: i/o timeout\n",
+			infrav1.BootstrapImagePullFailedReason,
+			"[ERROR ImagePull]: failed to pull image registry.k8s.io/kube-apiserver:v1.30.1: output: dial tcp: i/o timeout"),
+		Entry("unreachable API server",
+			"[preflight] Running pre-flight checks\n"+
+				"failed to request the cluster-info ConfigMap: Get \"https://10.0.0.1:6443/api/v1/namespaces/kube-public/configmaps/cluster-info\": dial tcp 10.0.0.1:6443: connect: no route to host\n",
+			infrav1.BootstrapNetworkUnreachableReason,
+			"failed to request the cluster-info ConfigMap: Get \"https://10.0.0.1:6443/api/v1/namespaces/kube-public/configmaps/cluster-info\": dial tcp 10.0.0.1:6443: connect: no route to host"),
+		Entry("failed preflight check",
+			"[preflight] Running pre-flight checks\n"+
+				"\t[ERROR NumCPU]: the number of available CPUs 1 is less than the required 2\n"+
+				"[preflight] If you know what you are doing, you can make a check non-fatal with `--ignore-preflight-errors=...`\n",
+			infrav1.BootstrapPreflightFailedReason,
+			"[ERROR NumCPU]: the number of available CPUs 1 is less than the required 2"),
+		Entry("last failure of the log",
+			"dial tcp 10.0.0.1:6443: connect: network is unreachable\n"+
+				"\t[ERROR ImagePull]: failed to pull image registry.k8s.io/pause:3.9\n"+
+				"[kubelet-start] Starting the kubelet\n",
+			infrav1.BootstrapImagePullFailedReason,
+			"[ERROR ImagePull]: failed to pull image registry.k8s.io/pause:3.9"),
+		Entry("no failure",
+			"[preflight] Running pre-flight checks\n[kubelet-start] Starting the kubelet\n",
+			"", ""),
+	)
+
+	It("should only retry the transient failures", func() {
+		Expect(IsTransientBootstrapFailure(infrav1.BootstrapNetworkUnreachableReason)).To(BeTrue())
+		Expect(IsTransientBootstrapFailure(infrav1.BootstrapImagePullFailedReason)).To(BeTrue())
+		Expect(IsTransientBootstrapFailure(infrav1.BootstrapTokenExpiredReason)).To(BeTrue())
+		Expect(IsTransientBootstrapFailure(infrav1.BootstrapPreflightFailedReason)).To(BeFalse())
+		Expect(IsTransientBootstrapFailure("")).To(BeFalse())
+	})
+})
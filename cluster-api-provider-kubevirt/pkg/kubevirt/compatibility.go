@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/version"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+// infraCompatibility is the compatibility matrix of the provider: the range of CDI minor versions supported with
+// each minor version of KubeVirt. The KubeVirt versions older than the first entry are not supported; the ones
+// newer than the last entry are not checked, until they are added to the matrix.
+var infraCompatibility = []struct {
+	kubevirt       *version.Version
+	minCDI, maxCDI *version.Version
+}{
+	{version.MustParseGeneric("1.0"), version.MustParseGeneric("1.57"), version.MustParseGeneric("1.57")},
+	{version.MustParseGeneric("1.1"), version.MustParseGeneric("1.57"), version.MustParseGeneric("1.58")},
+	{version.MustParseGeneric("1.2"), version.MustParseGeneric("1.58"), version.MustParseGeneric("1.59")},
+	{version.MustParseGeneric("1.3"), version.MustParseGeneric("1.59"), version.MustParseGeneric("1.60")},
+}
+
+// IsInfraCompatibilityKnown returns true when the version of KubeVirt of the infra cluster is known, and can be
+// checked against the compatibility matrix.
+func IsInfraCompatibilityKnown(infra *infrav1.InfraStatus) bool {
+	if infra == nil || infra.KubeVirtVersion == "" {
+		return false
+	}
+	_, err := version.ParseGeneric(infra.KubeVirtVersion)
+	return err == nil
+}
+
+// UnsupportedInfraVersions returns a message describing why the provider does not support the versions of KubeVirt
+// and CDI of the infra cluster, or an empty string when it supports them or they are unknown. An unknown version of
+// CDI is only checked against the version of KubeVirt once known.
+func UnsupportedInfraVersions(infra *infrav1.InfraStatus) string {
+	if !IsInfraCompatibilityKnown(infra) {
+		return ""
+	}
+	kubevirtVersion := version.MustParseGeneric(infra.KubeVirtVersion)
+	if oldest := infraCompatibility[0].kubevirt; !kubevirtVersion.AtLeast(oldest) {
+		return fmt.Sprintf("KubeVirt %s is not supported, v%s or later required", infra.KubeVirtVersion, oldest)
+	}
+
+	cdiVersion, err := version.ParseGeneric(infra.CDIVersion)
+	if err != nil {
+		return ""
+	}
+	for _, entry := range infraCompatibility {
+		if entry.kubevirt.Major() != kubevirtVersion.Major() || entry.kubevirt.Minor() != kubevirtVersion.Minor() {
+			continue
+		}
+		cdiMinor := version.MajorMinor(cdiVersion.Major(), cdiVersion.Minor())
+		if cdiMinor.AtLeast(entry.minCDI) && !entry.maxCDI.LessThan(cdiMinor) {
+			return ""
+		}
+		supported := fmt.Sprintf("v%s to v%s", entry.minCDI, entry.maxCDI)
+		if entry.minCDI.String() == entry.maxCDI.String() {
+			supported = "v" + entry.minCDI.String()
+		}
+		return fmt.Sprintf("CDI %s is not supported with KubeVirt %s, CDI %s required", infra.CDIVersion, infra.KubeVirtVersion, supported)
+	}
+	return ""
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+var _ = Describe("Infra compatibility", func() {
+	DescribeTable("should check the versions of the infra cluster against the compatibility matrix", func(infra *infrav1.InfraStatus, expected string) {
+		Expect(UnsupportedInfraVersions(infra)).To(Equal(expected))
+	},
+		Entry("supported versions", &infrav1.InfraStatus{KubeVirtVersion: "v1.2.1", CDIVersion: "v1.59.0"}, ""),
+		Entry("unknown versions", &infrav1.InfraStatus{}, ""),
+		Entry("unknown CDI version", &infrav1.InfraStatus{KubeVirtVersion: "v1.2.1"}, ""),
+		Entry("KubeVirt newer than the matrix", &infrav1.InfraStatus{KubeVirtVersion: "v1.9.0", CDIVersion: "v1.50.0"}, ""),
+		Entry("KubeVirt older than the matrix", &infrav1.InfraStatus{KubeVirtVersion: "v0.59.2", CDIVersion: "v1.56.0"},
+			"KubeVirt v0.59.2 is not supported, v1.0 or later required"),
+		Entry("CDI too old for KubeVirt", &infrav1.InfraStatus{KubeVirtVersion: "v1.2.1", CDIVersion: "v1.57.1"},
+			"CDI v1.57.1 is not supported with KubeVirt v1.2.1, CDI v1.58 to v1.59 required"),
+		Entry("CDI too new for KubeVirt", &infrav1.InfraStatus{KubeVirtVersion: "v1.0.1", CDIVersion: "v1.59.0"},
+			"CDI v1.59.0 is not supported with KubeVirt v1.0.1, CDI v1.57 required"),
+	)
+})
@@ -0,0 +1,58 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	"sigs.k8s.io/cluster-api/util"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+const (
+	// DeschedulerEvictAnnotation marks a pod as evictable by the descheduler, despite its local storage.
+	DeschedulerEvictAnnotation = "descheduler.alpha.kubernetes.io/evict"
+
+	// DeschedulerPreferNoEvictionAnnotation asks the descheduler not to evict a pod.
+	DeschedulerPreferNoEvictionAnnotation = "descheduler.alpha.kubernetes.io/prefer-no-eviction"
+)
+
+// setDeschedulerEviction annotates the VMI template, whose annotations KubeVirt copies to the virt-launcher pods,
+// according to the descheduler eviction policy of the cluster. The control plane VMs start protected; the cluster
+// controller makes them eligible once the control plane can spare one of them.
+func setDeschedulerEviction(template *kubevirtv1.VirtualMachineInstanceTemplateSpec, ctx *context.MachineContext) {
+	if ctx.KubevirtCluster == nil || ctx.KubevirtCluster.Spec.DeschedulerEviction == "" {
+		return
+	}
+	allowed := ctx.KubevirtCluster.Spec.DeschedulerEviction == infrav1.AllowDeschedulerEviction && !util.IsControlPlaneMachine(ctx.Machine)
+	SetDeschedulerEviction(template.ObjectMeta.Annotations, allowed)
+}
+
+// SetDeschedulerEviction sets the annotations marking a pod as eligible for, or protected from, the evictions of
+// the descheduler, and returns true if they changed.
+func SetDeschedulerEviction(annotations map[string]string, allowed bool) bool {
+	set, unset := DeschedulerEvictAnnotation, DeschedulerPreferNoEvictionAnnotation
+	if !allowed {
+		set, unset = unset, set
+	}
+	_, found := annotations[unset]
+	changed := found || annotations[set] != "true"
+	delete(annotations, unset)
+	annotations[set] = "true"
+	return changed
+}
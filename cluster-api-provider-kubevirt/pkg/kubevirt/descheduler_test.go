@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+var _ = Describe("Descheduler eviction", func() {
+	var machineContext *context.MachineContext
+
+	BeforeEach(func() {
+		machineContext = &context.MachineContext{
+			Cluster:         cluster,
+			KubevirtCluster: kubevirtCluster.DeepCopy(),
+			Machine:         machine.DeepCopy(),
+			KubevirtMachine: kubevirtMachine,
+		}
+	})
+
+	It("should make the worker VMs eligible when the descheduler eviction is allowed", func() {
+		machineContext.KubevirtCluster.Spec.DeschedulerEviction = infrav1.AllowDeschedulerEviction
+
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+		Expect(newVM.Spec.Template.ObjectMeta.Annotations).To(HaveKeyWithValue(DeschedulerEvictAnnotation, "true"))
+		Expect(newVM.Spec.Template.ObjectMeta.Annotations).ToNot(HaveKey(DeschedulerPreferNoEvictionAnnotation))
+	})
+
+	It("should protect the control plane VMs until the cluster controller makes them eligible", func() {
+		machineContext.KubevirtCluster.Spec.DeschedulerEviction = infrav1.AllowDeschedulerEviction
+		if machineContext.Machine.Labels == nil {
+			machineContext.Machine.Labels = map[string]string{}
+		}
+		machineContext.Machine.Labels[clusterv1.MachineControlPlaneLabel] = ""
+
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+		Expect(newVM.Spec.Template.ObjectMeta.Annotations).To(HaveKeyWithValue(DeschedulerPreferNoEvictionAnnotation, "true"))
+		Expect(newVM.Spec.Template.ObjectMeta.Annotations).ToNot(HaveKey(DeschedulerEvictAnnotation))
+	})
+
+	It("should protect the VMs when the descheduler eviction is prevented", func() {
+		machineContext.KubevirtCluster.Spec.DeschedulerEviction = infrav1.PreventDeschedulerEviction
+
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+		Expect(newVM.Spec.Template.ObjectMeta.Annotations).To(HaveKeyWithValue(DeschedulerPreferNoEvictionAnnotation, "true"))
+	})
+
+	It("should not annotate the VMs without descheduler eviction policy", func() {
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+		Expect(newVM.Spec.Template.ObjectMeta.Annotations).ToNot(HaveKey(DeschedulerEvictAnnotation))
+		Expect(newVM.Spec.Template.ObjectMeta.Annotations).ToNot(HaveKey(DeschedulerPreferNoEvictionAnnotation))
+	})
+
+	It("should report whether the annotations changed", func() {
+		annotations := map[string]string{DeschedulerPreferNoEvictionAnnotation: "true"}
+		Expect(SetDeschedulerEviction(annotations, true)).To(BeTrue())
+		Expect(annotations).To(Equal(map[string]string{DeschedulerEvictAnnotation: "true"}))
+		Expect(SetDeschedulerEviction(annotations, true)).To(BeFalse())
+		Expect(SetDeschedulerEviction(annotations, false)).To(BeTrue())
+		Expect(annotations).To(Equal(map[string]string{DeschedulerPreferNoEvictionAnnotation: "true"}))
+	})
+})
@@ -0,0 +1,240 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	gocontext "context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+// maxDiskSerialLength is the longest serial number KubeVirt accepts for a disk.
+const maxDiskSerialLength = 20
+
+// GuestEncryptedDisk is a data disk of a VM the guest encrypts with LUKS.
+type GuestEncryptedDisk struct {
+	// Name is the name of the disk, and of its LUKS mapping in the guest.
+	Name string
+	// Serial is the serial number the guest finds the disk by.
+	Serial string
+}
+
+// diskEncryptionPolicy returns the disk encryption policy of the cluster of the machine, if any.
+func diskEncryptionPolicy(ctx *context.MachineContext) *infrav1.DiskEncryptionSpec {
+	if ctx.KubevirtCluster == nil {
+		return nil
+	}
+	return ctx.KubevirtCluster.Spec.DiskEncryption
+}
+
+// encryptedStorageClassName returns the storage class the disks of the given storage class are created with by the
+// disk encryption policy, and false if the policy provides no encrypted storage class for them.
+func encryptedStorageClassName(policy *infrav1.DiskEncryptionSpec, name *string) (*string, bool) {
+	if policy == nil || policy.Mode != infrav1.StorageClassDiskEncryptionMode {
+		return name, true
+	}
+	if name != nil && isEncryptedStorageClass(policy, *name) {
+		return name, true
+	}
+	if encrypted, found := policy.StorageClasses[ptr.Deref(name, "")]; found {
+		return ptr.To(encrypted), true
+	}
+	return name, false
+}
+
+// isEncryptedStorageClass returns true if the storage class is one of the encrypted storage classes of the policy.
+func isEncryptedStorageClass(policy *infrav1.DiskEncryptionSpec, name string) bool {
+	for _, encrypted := range policy.StorageClasses {
+		if encrypted == name {
+			return true
+		}
+	}
+	return false
+}
+
+// setDiskEncryption applies the disk encryption policy of the cluster of the machine to the VM: its
+// DataVolumeTemplates get their encrypted storage class, or the data disks encrypted by the guest get the serial
+// number the guest finds them by.
+func setDiskEncryption(vm *kubevirtv1.VirtualMachine, ctx *context.MachineContext) {
+	policy := diskEncryptionPolicy(ctx)
+	if policy == nil {
+		return
+	}
+	if vm.Annotations == nil {
+		vm.Annotations = map[string]string{}
+	}
+	vm.Annotations[infrav1.DiskEncryptionAnnotation] = string(policy.Mode)
+
+	switch policy.Mode {
+	case infrav1.StorageClassDiskEncryptionMode:
+		for i := range vm.Spec.DataVolumeTemplates {
+			spec := &vm.Spec.DataVolumeTemplates[i].Spec
+			switch {
+			case spec.PVC != nil:
+				spec.PVC.StorageClassName, _ = encryptedStorageClassName(policy, spec.PVC.StorageClassName)
+			case spec.Storage != nil:
+				spec.Storage.StorageClassName, _ = encryptedStorageClassName(policy, spec.Storage.StorageClassName)
+			}
+		}
+
+	case infrav1.GuestDiskEncryptionMode:
+		if vm.Spec.Template == nil {
+			return
+		}
+		for _, encrypted := range guestEncryptedDisks(&vm.Spec.Template.Spec) {
+			for i := range vm.Spec.Template.Spec.Domain.Devices.Disks {
+				if disk := &vm.Spec.Template.Spec.Domain.Devices.Disks[i]; disk.Name == encrypted.Name {
+					disk.Serial = encrypted.Serial
+				}
+			}
+		}
+	}
+}
+
+// GuestEncryptedDisks returns the data disks of the VM of the machine its guest encrypts, when the disk encryption
+// policy of its cluster has the guests encrypt them.
+func GuestEncryptedDisks(ctx *context.MachineContext) []GuestEncryptedDisk {
+	policy := diskEncryptionPolicy(ctx)
+	template := ctx.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.Template
+	if policy == nil || policy.Mode != infrav1.GuestDiskEncryptionMode || template == nil {
+		return nil
+	}
+	return guestEncryptedDisks(&template.Spec)
+}
+
+// guestEncryptedDisks returns the disks of the VMI backed by a DataVolume or a PVC, but the one it boots from. Their
+// serial number defaults to their name.
+func guestEncryptedDisks(spec *kubevirtv1.VirtualMachineInstanceSpec) []GuestEncryptedDisk {
+	var claimVolumes []string
+	for _, volume := range spec.Volumes {
+		if volume.DataVolume != nil || volume.PersistentVolumeClaim != nil {
+			claimVolumes = append(claimVolumes, volume.Name)
+		}
+	}
+
+	disks := spec.Domain.Devices.Disks
+	boot := bootDisk(disks)
+	var encrypted []GuestEncryptedDisk
+	for _, disk := range disks {
+		if disk.Name == boot || disk.CDRom != nil || !slices.Contains(claimVolumes, disk.Name) {
+			continue
+		}
+		serial := disk.Serial
+		if serial == "" {
+			serial = disk.Name[:min(len(disk.Name), maxDiskSerialLength)]
+		}
+		encrypted = append(encrypted, GuestEncryptedDisk{Name: disk.Name, Serial: serial})
+	}
+	return encrypted
+}
+
+// bootDisk returns the name of the disk a VM boots from: the one of the lowest boot order, or else the first one.
+func bootDisk(disks []kubevirtv1.Disk) string {
+	boot := -1
+	for i, disk := range disks {
+		if disk.BootOrder != nil && (boot < 0 || *disk.BootOrder < *disks[boot].BootOrder) {
+			boot = i
+		}
+	}
+	if boot < 0 && len(disks) > 0 {
+		boot = 0
+	}
+	if boot < 0 {
+		return ""
+	}
+	return disks[boot].Name
+}
+
+// UnencryptedDataVolumeTemplates returns a message describing the DataVolumeTemplates of the VM of the machine the
+// disk encryption policy of its cluster provides no encrypted storage class for, or an empty string if none.
+func UnencryptedDataVolumeTemplates(ctx *context.MachineContext) string {
+	policy := diskEncryptionPolicy(ctx)
+	if policy == nil || policy.Mode != infrav1.StorageClassDiskEncryptionMode {
+		return ""
+	}
+
+	var unencrypted []string
+	imageTarget := machineImageTarget(ctx)
+	for _, template := range ctx.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.DataVolumeTemplates {
+		storageClassName := dataVolumeTemplateStorageClassName(ctx, &template, imageTarget)
+		if _, encrypted := encryptedStorageClassName(policy, storageClassName); encrypted {
+			continue
+		}
+		if storageClassName == nil {
+			unencrypted = append(unencrypted, fmt.Sprintf("DataVolumeTemplate %s: no encrypted storage class for the default storage class", template.Name))
+		} else {
+			unencrypted = append(unencrypted, fmt.Sprintf("DataVolumeTemplate %s: no encrypted storage class for storage class %s", template.Name, *storageClassName))
+		}
+	}
+	return strings.Join(unencrypted, "; ")
+}
+
+// UnencryptedDisks returns the names of the volumes of the VM whose disks are not encrypted as the disk encryption
+// policy requires: in the StorageClass mode, the volumes whose PVC is not of an encrypted storage class, and in the
+// Guest mode, the data disks of the VMs not created with the policy. The PVCs not created yet are skipped.
+func UnencryptedDisks(ctx gocontext.Context, c client.Client, vm *kubevirtv1.VirtualMachine, policy *infrav1.DiskEncryptionSpec) ([]string, error) {
+	if vm.Spec.Template == nil {
+		return nil, nil
+	}
+
+	var unencrypted []string
+	switch policy.Mode {
+	case infrav1.StorageClassDiskEncryptionMode:
+		for _, volume := range vm.Spec.Template.Spec.Volumes {
+			var claimName string
+			switch {
+			case volume.DataVolume != nil:
+				claimName = volume.DataVolume.Name
+			case volume.PersistentVolumeClaim != nil:
+				claimName = volume.PersistentVolumeClaim.ClaimName
+			default:
+				continue
+			}
+
+			pvc := &corev1.PersistentVolumeClaim{}
+			if err := c.Get(ctx, client.ObjectKey{Namespace: vm.Namespace, Name: claimName}, pvc); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return nil, errors.Wrapf(err, "failed to get PVC %s/%s", vm.Namespace, claimName)
+			}
+			if pvc.Spec.StorageClassName == nil || !isEncryptedStorageClass(policy, *pvc.Spec.StorageClassName) {
+				unencrypted = append(unencrypted, volume.Name)
+			}
+		}
+
+	case infrav1.GuestDiskEncryptionMode:
+		if vm.Annotations[infrav1.DiskEncryptionAnnotation] == string(infrav1.GuestDiskEncryptionMode) {
+			return nil, nil
+		}
+		for _, disk := range guestEncryptedDisks(&vm.Spec.Template.Spec) {
+			unencrypted = append(unencrypted, disk.Name)
+		}
+	}
+	return unencrypted, nil
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	gocontext "context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("Disk encryption", func() {
+	var machineContext *context.MachineContext
+
+	BeforeEach(func() {
+		machineContext = &context.MachineContext{
+			Cluster:         cluster,
+			KubevirtCluster: kubevirtCluster.DeepCopy(),
+			Machine:         machine,
+			KubevirtMachine: kubevirtMachine.DeepCopy(),
+		}
+		vmTemplate := &machineContext.KubevirtMachine.Spec.VirtualMachineTemplate.Spec
+		vmTemplate.DataVolumeTemplates = []kubevirtv1.DataVolumeTemplateSpec{
+			{ObjectMeta: metav1.ObjectMeta{Name: "root"}, Spec: cdiv1.DataVolumeSpec{Storage: &cdiv1.StorageSpec{StorageClassName: ptr.To("fast")}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "data"}, Spec: cdiv1.DataVolumeSpec{Storage: &cdiv1.StorageSpec{}}},
+		}
+		vmTemplate.Template.Spec.Domain.Devices.Disks = []kubevirtv1.Disk{
+			{Name: "rootdisk"},
+			{Name: "datadisk", BootOrder: ptr.To(uint(2))},
+			{Name: "cloudinitdisk"},
+		}
+		vmTemplate.Template.Spec.Volumes = []kubevirtv1.Volume{
+			{Name: "rootdisk", VolumeSource: kubevirtv1.VolumeSource{DataVolume: &kubevirtv1.DataVolumeSource{Name: "root"}}},
+			{Name: "datadisk", VolumeSource: kubevirtv1.VolumeSource{DataVolume: &kubevirtv1.DataVolumeSource{Name: "data"}}},
+			{Name: "cloudinitdisk", VolumeSource: kubevirtv1.VolumeSource{CloudInitNoCloud: &kubevirtv1.CloudInitNoCloudSource{}}},
+		}
+	})
+
+	Context("StorageClass mode", func() {
+		BeforeEach(func() {
+			machineContext.KubevirtCluster.Spec.DiskEncryption = &infrav1.DiskEncryptionSpec{
+				Mode:           infrav1.StorageClassDiskEncryptionMode,
+				StorageClasses: map[string]string{"fast": "fast-encrypted", "": "standard-encrypted"},
+			}
+		})
+
+		It("should create the disks with the encrypted storage classes", func() {
+			newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+
+			Expect(newVM.Annotations).To(HaveKeyWithValue(infrav1.DiskEncryptionAnnotation, "StorageClass"))
+			Expect(newVM.Spec.DataVolumeTemplates[0].Spec.Storage.StorageClassName).To(Equal(ptr.To("fast-encrypted")))
+			Expect(newVM.Spec.DataVolumeTemplates[1].Spec.Storage.StorageClassName).To(Equal(ptr.To("standard-encrypted")))
+			Expect(UnencryptedDataVolumeTemplates(machineContext)).To(BeEmpty())
+		})
+
+		It("should keep the disks of the encrypted storage classes", func() {
+			machineContext.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.DataVolumeTemplates[0].Spec.Storage.StorageClassName = ptr.To("fast-encrypted")
+
+			newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+			Expect(newVM.Spec.DataVolumeTemplates[0].Spec.Storage.StorageClassName).To(Equal(ptr.To("fast-encrypted")))
+		})
+
+		It("should describe the DataVolumeTemplates without an encrypted storage class", func() {
+			machineContext.KubevirtCluster.Spec.DiskEncryption.StorageClasses = map[string]string{"other": "other-encrypted"}
+
+			Expect(UnencryptedDataVolumeTemplates(machineContext)).To(Equal(
+				"DataVolumeTemplate root: no encrypted storage class for storage class fast; " +
+					"DataVolumeTemplate data: no encrypted storage class for the default storage class"))
+		})
+
+		It("should report the disks whose PVC is not of an encrypted storage class", func() {
+			vm := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+			newPVC := func(name, storageClassName string) *corev1.PersistentVolumeClaim {
+				return &corev1.PersistentVolumeClaim{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+					Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: ptr.To(storageClassName)},
+				}
+			}
+			c := fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(
+				newPVC(vm.Spec.Template.Spec.Volumes[0].DataVolume.Name, "fast-encrypted"),
+				newPVC(vm.Spec.Template.Spec.Volumes[1].DataVolume.Name, "standard"),
+			).Build()
+
+			unencrypted, err := UnencryptedDisks(gocontext.Background(), c, vm, machineContext.KubevirtCluster.Spec.DiskEncryption)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(unencrypted).To(Equal([]string{"datadisk"}))
+		})
+	})
+
+	Context("Guest mode", func() {
+		BeforeEach(func() {
+			machineContext.KubevirtCluster.Spec.DiskEncryption = &infrav1.DiskEncryptionSpec{Mode: infrav1.GuestDiskEncryptionMode}
+		})
+
+		It("should encrypt the data disks backed by a volume claim, but the boot disk", func() {
+			Expect(GuestEncryptedDisks(machineContext)).To(Equal([]GuestEncryptedDisk{{Name: "rootdisk", Serial: "rootdisk"}}))
+
+			newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+			Expect(newVM.Annotations).To(HaveKeyWithValue(infrav1.DiskEncryptionAnnotation, "Guest"))
+			Expect(newVM.Spec.Template.Spec.Domain.Devices.Disks[0].Serial).To(Equal("rootdisk"))
+			Expect(newVM.Spec.Template.Spec.Domain.Devices.Disks[1].Serial).To(BeEmpty())
+			Expect(newVM.Spec.DataVolumeTemplates[0].Spec.Storage.StorageClassName).To(Equal(ptr.To("fast")))
+		})
+
+		It("should boot from the first disk without boot order, and keep the serial numbers of the disks", func() {
+			disks := machineContext.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.Template.Spec.Domain.Devices.Disks
+			disks[1].BootOrder = nil
+			disks[1].Serial = "data-serial"
+
+			Expect(GuestEncryptedDisks(machineContext)).To(Equal([]GuestEncryptedDisk{{Name: "datadisk", Serial: "data-serial"}}))
+		})
+
+		It("should report the data disks of the VMs not created with the policy", func() {
+			vm := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+			c := fake.NewClientBuilder().WithScheme(testing.SetupScheme()).Build()
+			policy := machineContext.KubevirtCluster.Spec.DiskEncryption
+
+			unencrypted, err := UnencryptedDisks(gocontext.Background(), c, vm, policy)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(unencrypted).To(BeEmpty())
+
+			delete(vm.Annotations, infrav1.DiskEncryptionAnnotation)
+			unencrypted, err = UnencryptedDisks(gocontext.Background(), c, vm, policy)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(unencrypted).To(Equal([]string{"rootdisk"}))
+		})
+	})
+
+	It("should leave the VMs of the clusters without policy untouched", func() {
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+
+		Expect(newVM.Annotations).ToNot(HaveKey(infrav1.DiskEncryptionAnnotation))
+		Expect(newVM.Spec.DataVolumeTemplates[0].Spec.Storage.StorageClassName).To(Equal(ptr.To("fast")))
+		Expect(GuestEncryptedDisks(machineContext)).To(BeEmpty())
+		Expect(UnencryptedDataVolumeTemplates(machineContext)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,58 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	gocontext "context"
+
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	exportv1 "kubevirt.io/api/export/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+// StopForDiskExport stops the VM while its disks are exported, since KubeVirt only exports the disks of stopped
+// VMs, and records the run strategy to restore once the export is removed. It returns true once the VM is stopped.
+func StopForDiskExport(ctx gocontext.Context, c client.Client, vm *kubevirtv1.VirtualMachine) (bool, error) {
+	return haltVirtualMachine(ctx, c, vm, infrav1.DiskExportRunStrategyAnnotation)
+}
+
+// RestoreAfterDiskExport restores the run strategy the VM had before its disks were exported. It is a no-op for a
+// VM that was not stopped for an export.
+func RestoreAfterDiskExport(ctx gocontext.Context, c client.Client, vm *kubevirtv1.VirtualMachine) error {
+	return restoreVirtualMachine(ctx, c, vm, infrav1.DiskExportRunStrategyAnnotation)
+}
+
+// ExportedVolumes returns the external download URLs of the gzipped raw images of the volumes of the export, or
+// nil if the export is not reachable from outside the infra cluster.
+func ExportedVolumes(export *exportv1.VirtualMachineExport) []infrav1.ExportedVolume {
+	if export.Status == nil || export.Status.Links == nil || export.Status.Links.External == nil {
+		return nil
+	}
+
+	var volumes []infrav1.ExportedVolume
+	for _, volume := range export.Status.Links.External.Volumes {
+		for _, format := range volume.Formats {
+			if format.Format == exportv1.KubeVirtGz {
+				volumes = append(volumes, infrav1.ExportedVolume{Name: volume.Name, URL: format.Url})
+				break
+			}
+		}
+	}
+	return volumes
+}
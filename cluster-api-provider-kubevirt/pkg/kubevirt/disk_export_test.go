@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	gocontext "context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	exportv1 "kubevirt.io/api/export/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("Disk export", func() {
+	var (
+		ctx = gocontext.Background()
+		vm  *kubevirtv1.VirtualMachine
+		c   client.Client
+	)
+
+	BeforeEach(func() {
+		vm = testing.NewVirtualMachine(testing.NewVirtualMachineInstance(kubevirtMachine))
+		vm.Spec.Running = ptr.To(true)
+		c = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(vm).Build()
+	})
+
+	It("should stop the VM and restore its run strategy after the export", func() {
+		stopped, err := StopForDiskExport(ctx, c, vm)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stopped).To(BeFalse())
+		Expect(vm.Spec.RunStrategy).To(HaveValue(Equal(kubevirtv1.RunStrategyHalted)))
+		Expect(vm.Annotations).To(HaveKeyWithValue(infrav1.DiskExportRunStrategyAnnotation, string(kubevirtv1.RunStrategyAlways)))
+
+		Expect(RestoreAfterDiskExport(ctx, c, vm)).To(Succeed())
+		updated := &kubevirtv1.VirtualMachine{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(vm), updated)).To(Succeed())
+		Expect(updated.Spec.RunStrategy).To(HaveValue(Equal(kubevirtv1.RunStrategyAlways)))
+		Expect(updated.Annotations).ToNot(HaveKey(infrav1.DiskExportRunStrategyAnnotation))
+	})
+
+	It("should return the gzip URLs of the external links", func() {
+		export := &exportv1.VirtualMachineExport{
+			Status: &exportv1.VirtualMachineExportStatus{
+				Links: &exportv1.VirtualMachineExportLinks{
+					External: &exportv1.VirtualMachineExportLink{
+						Volumes: []exportv1.VirtualMachineExportVolume{{
+							Name: "dv-disk",
+							Formats: []exportv1.VirtualMachineExportVolumeFormat{
+								{Format: exportv1.KubeVirtRaw, Url: "https://export.example.com/dv-disk/disk.img"},
+								{Format: exportv1.KubeVirtGz, Url: "https://export.example.com/dv-disk/disk.img.gz"},
+							},
+						}},
+					},
+				},
+			},
+		}
+		Expect(ExportedVolumes(export)).To(Equal([]infrav1.ExportedVolume{
+			{Name: "dv-disk", URL: "https://export.example.com/dv-disk/disk.img.gz"},
+		}))
+	})
+
+	It("should return no URL without external links", func() {
+		export := &exportv1.VirtualMachineExport{
+			Status: &exportv1.VirtualMachineExportStatus{
+				Links: &exportv1.VirtualMachineExportLinks{Internal: &exportv1.VirtualMachineExportLink{}},
+			},
+		}
+		Expect(ExportedVolumes(export)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+// setDiskTuning applies the IOThreads policy and the bus, cache mode and IOThread of the disks of the machine to
+// the VMI spec, taking precedence over the values of the VMI template.
+func setDiskTuning(spec *kubevirtv1.VirtualMachineInstanceSpec, ctx *context.MachineContext) {
+	if policy := ctx.KubevirtMachine.Spec.IOThreadsPolicy; policy != nil {
+		spec.Domain.IOThreadsPolicy = ptr.To(*policy)
+	}
+
+	for _, tuning := range ctx.KubevirtMachine.Spec.Disks {
+		for i := range spec.Domain.Devices.Disks {
+			disk := &spec.Domain.Devices.Disks[i]
+			if disk.Name != tuning.Name {
+				continue
+			}
+
+			if tuning.Bus != "" {
+				switch {
+				case disk.LUN != nil:
+					disk.LUN.Bus = tuning.Bus
+				case disk.CDRom != nil:
+					disk.CDRom.Bus = tuning.Bus
+				case disk.Disk != nil:
+					disk.Disk.Bus = tuning.Bus
+				default:
+					disk.Disk = &kubevirtv1.DiskTarget{Bus: tuning.Bus}
+				}
+			}
+			if tuning.Cache != "" {
+				disk.Cache = tuning.Cache
+			}
+			if tuning.DedicatedIOThread != nil {
+				disk.DedicatedIOThread = ptr.To(*tuning.DedicatedIOThread)
+			}
+		}
+	}
+}
+
+// setDiskDiscard preallocates the DataVolumeTemplates of the disks of the machine not passing the discard requests
+// of the guest to the storage, and leaves thin provisioned the ones passing them. It must run before the
+// DataVolumeTemplates are prefixed with the name of the machine.
+func setDiskDiscard(vm *kubevirtv1.VirtualMachine, ctx *context.MachineContext) {
+	if vm.Spec.Template == nil {
+		return
+	}
+
+	for _, tuning := range ctx.KubevirtMachine.Spec.Disks {
+		if tuning.Discard == nil {
+			continue
+		}
+		for _, volume := range vm.Spec.Template.Spec.Volumes {
+			if volume.Name != tuning.Name || volume.DataVolume == nil {
+				continue
+			}
+			for i := range vm.Spec.DataVolumeTemplates {
+				if template := &vm.Spec.DataVolumeTemplates[i]; template.Name == volume.DataVolume.Name {
+					template.Spec.Preallocation = ptr.To(!*tuning.Discard)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+var _ = Describe("Disk tuning", func() {
+	var machineContext *context.MachineContext
+
+	BeforeEach(func() {
+		machineContext = &context.MachineContext{
+			Cluster:         cluster,
+			KubevirtCluster: kubevirtCluster,
+			Machine:         machine,
+			KubevirtMachine: kubevirtMachine.DeepCopy(),
+		}
+		vmTemplate := &machineContext.KubevirtMachine.Spec.VirtualMachineTemplate.Spec
+		vmTemplate.DataVolumeTemplates = []kubevirtv1.DataVolumeTemplateSpec{
+			{ObjectMeta: metav1.ObjectMeta{Name: "root"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "data"}},
+		}
+		vmTemplate.Template.Spec.Domain.Devices.Disks = []kubevirtv1.Disk{
+			{Name: "rootdisk", DiskDevice: kubevirtv1.DiskDevice{Disk: &kubevirtv1.DiskTarget{Bus: kubevirtv1.DiskBusVirtio}}, Cache: kubevirtv1.CacheWriteThrough},
+			{Name: "datadisk"},
+		}
+		vmTemplate.Template.Spec.Volumes = []kubevirtv1.Volume{
+			{Name: "rootdisk", VolumeSource: kubevirtv1.VolumeSource{DataVolume: &kubevirtv1.DataVolumeSource{Name: "root"}}},
+			{Name: "datadisk", VolumeSource: kubevirtv1.VolumeSource{DataVolume: &kubevirtv1.DataVolumeSource{Name: "data"}}},
+		}
+	})
+
+	It("should tune the disks of the VM over the values of the VMI template", func() {
+		machineContext.KubevirtMachine.Spec.IOThreadsPolicy = ptr.To(kubevirtv1.IOThreadsPolicyAuto)
+		machineContext.KubevirtMachine.Spec.Disks = []infrav1.DiskTuning{
+			{Name: "rootdisk", Cache: kubevirtv1.CacheNone, DedicatedIOThread: ptr.To(true)},
+			{Name: "datadisk", Bus: kubevirtv1.DiskBusSCSI, Discard: ptr.To(true)},
+			{Name: "missing", Bus: kubevirtv1.DiskBusSATA},
+		}
+
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+
+		Expect(newVM.Spec.Template.Spec.Domain.IOThreadsPolicy).To(Equal(ptr.To(kubevirtv1.IOThreadsPolicyAuto)))
+		disks := newVM.Spec.Template.Spec.Domain.Devices.Disks
+		Expect(disks[0].Disk.Bus).To(Equal(kubevirtv1.DiskBusVirtio))
+		Expect(disks[0].Cache).To(Equal(kubevirtv1.CacheNone))
+		Expect(disks[0].DedicatedIOThread).To(Equal(ptr.To(true)))
+		Expect(disks[1].Disk).To(Equal(&kubevirtv1.DiskTarget{Bus: kubevirtv1.DiskBusSCSI}))
+		Expect(disks[1].Cache).To(BeEmpty())
+		Expect(newVM.Spec.DataVolumeTemplates[0].Spec.Preallocation).To(BeNil())
+		Expect(newVM.Spec.DataVolumeTemplates[1].Spec.Preallocation).To(Equal(ptr.To(false)))
+	})
+
+	It("should preallocate the disks not passing the discard requests", func() {
+		machineContext.KubevirtMachine.Spec.Disks = []infrav1.DiskTuning{{Name: "rootdisk", Discard: ptr.To(false)}}
+
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+
+		Expect(newVM.Spec.DataVolumeTemplates[0].Name).To(Equal(machineContext.KubevirtMachine.Name + "-root"))
+		Expect(newVM.Spec.DataVolumeTemplates[0].Spec.Preallocation).To(Equal(ptr.To(true)))
+	})
+
+	It("should leave the disks of the machines without tuning untouched", func() {
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+
+		Expect(newVM.Spec.Template.Spec.Domain.IOThreadsPolicy).To(BeNil())
+		Expect(newVM.Spec.Template.Spec.Domain.Devices.Disks[:2]).To(Equal(
+			machineContext.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.Template.Spec.Domain.Devices.Disks))
+	})
+})
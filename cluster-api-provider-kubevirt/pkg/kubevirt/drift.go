@@ -0,0 +1,307 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	gocontext "context"
+	"slices"
+	"sort"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The fields of a VM compared with the VM rendered from its machine.
+const (
+	cpuDriftField      = "cpu"
+	memoryDriftField   = "memory"
+	disksDriftField    = "disks"
+	networksDriftField = "networks"
+)
+
+// Drift returns the fields of the VM that differ from the VM rendered from the machine, i.e. that were changed in
+// the infra cluster: its CPU, memory, disks or networks. The volumes hotplugged into the VM, and the sources of
+// its DataVolumeTemplates, are not compared.
+func (m *Machine) Drift() []string {
+	if m.vmInstance == nil || m.vmInstance.Spec.Template == nil {
+		return nil
+	}
+	return vmDrift(newVirtualMachineFromKubevirtMachine(m.machineContext, m.namespace), m.vmInstance)
+}
+
+// RevertDrift patches the drifted fields of the VM back to the values rendered from the machine, keeping the
+// volumes hotplugged into it. It returns the reverted fields.
+func (m *Machine) RevertDrift(ctx gocontext.Context) ([]string, error) {
+	if m.vmInstance == nil || m.vmInstance.Spec.Template == nil {
+		return nil, nil
+	}
+	desired := newVirtualMachineFromKubevirtMachine(m.machineContext, m.namespace)
+	fields := vmDrift(desired, m.vmInstance)
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	vm := m.vmInstance.DeepCopy()
+	revertDrift(desired, vm, fields)
+	if err := m.client.Patch(ctx, vm, client.MergeFrom(m.vmInstance)); err != nil {
+		return nil, errors.Wrapf(err, "failed to revert the drift of VM %s/%s", vm.Namespace, vm.Name)
+	}
+	m.vmInstance = vm
+	return fields, nil
+}
+
+// vmDrift returns the fields of the live VM that differ from the desired one.
+func vmDrift(desired, live *kubevirtv1.VirtualMachine) []string {
+	desiredSpec, liveSpec := &desired.Spec.Template.Spec, &live.Spec.Template.Spec
+
+	var fields []string
+	if cpuDrifted(&desiredSpec.Domain, &liveSpec.Domain) {
+		fields = append(fields, cpuDriftField)
+	}
+	if memoryDrifted(&desiredSpec.Domain, &liveSpec.Domain) {
+		fields = append(fields, memoryDriftField)
+	}
+	if !slices.Equal(diskKeys(desiredSpec), diskKeys(liveSpec)) {
+		fields = append(fields, disksDriftField)
+	}
+	if !slices.Equal(networkKeys(desiredSpec), networkKeys(liveSpec)) {
+		fields = append(fields, networksDriftField)
+	}
+	return fields
+}
+
+// cpuDrifted returns true if the CPU topology or the CPU resources of the domains differ. The maximum number of
+// sockets KubeVirt defaults for the CPU hotplug is only compared when rendered.
+func cpuDrifted(desired, live *kubevirtv1.DomainSpec) bool {
+	desiredCPU, liveCPU := desired.CPU, live.CPU
+	if desiredCPU == nil {
+		desiredCPU = &kubevirtv1.CPU{}
+	}
+	if liveCPU == nil {
+		liveCPU = &kubevirtv1.CPU{}
+	}
+	if desiredCPU.Sockets != liveCPU.Sockets || desiredCPU.Cores != liveCPU.Cores || desiredCPU.Threads != liveCPU.Threads {
+		return true
+	}
+	if desiredCPU.MaxSockets != 0 && desiredCPU.MaxSockets != liveCPU.MaxSockets {
+		return true
+	}
+	return resourceDrifted(desired.Resources, live.Resources, corev1.ResourceCPU)
+}
+
+// memoryDrifted returns true if the guest memory or the memory resources of the domains differ. The maximum guest
+// memory KubeVirt defaults for the memory hotplug is only compared when rendered.
+func memoryDrifted(desired, live *kubevirtv1.DomainSpec) bool {
+	desiredMemory, liveMemory := desired.Memory, live.Memory
+	if desiredMemory == nil {
+		desiredMemory = &kubevirtv1.Memory{}
+	}
+	if liveMemory == nil {
+		liveMemory = &kubevirtv1.Memory{}
+	}
+	if !equalQuantities(desiredMemory.Guest, liveMemory.Guest) {
+		return true
+	}
+	if desiredMemory.MaxGuest != nil && !equalQuantities(desiredMemory.MaxGuest, liveMemory.MaxGuest) {
+		return true
+	}
+	return resourceDrifted(desired.Resources, live.Resources, corev1.ResourceMemory)
+}
+
+// resourceDrifted returns true if the request or the limit of the resource differ.
+func resourceDrifted(desired, live kubevirtv1.ResourceRequirements, name corev1.ResourceName) bool {
+	for _, lists := range [][2]corev1.ResourceList{{desired.Requests, live.Requests}, {desired.Limits, live.Limits}} {
+		desiredQuantity, desiredFound := lists[0][name]
+		liveQuantity, liveFound := lists[1][name]
+		if desiredFound != liveFound || desiredQuantity.Cmp(liveQuantity) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// equalQuantities returns true if both quantities are unset, or set to the same value.
+func equalQuantities(a, b *resource.Quantity) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Cmp(*b) == 0
+}
+
+// diskKeys returns the disks of the VMI spec and the sources of its volumes, sorted, without the hotplugged volumes
+// and their disks.
+func diskKeys(spec *kubevirtv1.VirtualMachineInstanceSpec) []string {
+	hotplugged := hotpluggedVolumes(spec)
+	var keys []string
+	for _, volume := range spec.Volumes {
+		if !hotplugged[volume.Name] {
+			keys = append(keys, "volume/"+volume.Name+"="+volumeSource(volume))
+		}
+	}
+	for _, disk := range spec.Domain.Devices.Disks {
+		if !hotplugged[disk.Name] {
+			keys = append(keys, "disk/"+disk.Name)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// hotpluggedVolumes returns the names of the volumes hotplugged into the VMI spec.
+func hotpluggedVolumes(spec *kubevirtv1.VirtualMachineInstanceSpec) map[string]bool {
+	hotplugged := map[string]bool{}
+	for _, volume := range spec.Volumes {
+		if (volume.DataVolume != nil && volume.DataVolume.Hotpluggable) ||
+			(volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.Hotpluggable) {
+			hotplugged[volume.Name] = true
+		}
+	}
+	return hotplugged
+}
+
+// volumeSource identifies the source of a volume. The images of the container disks are not part of it, as the
+// channels of the machine images move them to new releases.
+func volumeSource(volume kubevirtv1.Volume) string {
+	switch {
+	case volume.DataVolume != nil:
+		return "dataVolume:" + volume.DataVolume.Name
+	case volume.PersistentVolumeClaim != nil:
+		return "persistentVolumeClaim:" + volume.PersistentVolumeClaim.ClaimName
+	case volume.ContainerDisk != nil:
+		return "containerDisk"
+	case volume.CloudInitNoCloud != nil:
+		return "cloudInitNoCloud"
+	case volume.CloudInitConfigDrive != nil:
+		return "cloudInitConfigDrive"
+	case volume.Secret != nil:
+		return "secret:" + volume.Secret.SecretName
+	case volume.ConfigMap != nil:
+		return "configMap:" + volume.ConfigMap.Name
+	case volume.EmptyDisk != nil:
+		return "emptyDisk"
+	default:
+		return ""
+	}
+}
+
+// networkKeys returns the networks of the VMI spec and the bindings of its interfaces, in their order.
+func networkKeys(spec *kubevirtv1.VirtualMachineInstanceSpec) []string {
+	var keys []string
+	for _, network := range spec.Networks {
+		source := ""
+		switch {
+		case network.Pod != nil:
+			source = "pod"
+		case network.Multus != nil:
+			source = "multus:" + network.Multus.NetworkName
+		}
+		keys = append(keys, "network/"+network.Name+"="+source)
+	}
+	for _, iface := range spec.Domain.Devices.Interfaces {
+		keys = append(keys, "interface/"+iface.Name+"="+interfaceBinding(iface))
+	}
+	return keys
+}
+
+// interfaceBinding returns the binding of an interface to its network.
+func interfaceBinding(iface kubevirtv1.Interface) string {
+	switch {
+	case iface.Binding != nil:
+		return "plugin:" + iface.Binding.Name
+	case iface.Bridge != nil:
+		return "bridge"
+	case iface.Masquerade != nil:
+		return "masquerade"
+	case iface.SRIOV != nil:
+		return "sriov"
+	case iface.Slirp != nil:
+		return "slirp"
+	case iface.Macvtap != nil:
+		return "macvtap"
+	case iface.Passt != nil:
+		return "passt"
+	default:
+		return ""
+	}
+}
+
+// revertDrift sets the drifted fields of the live VM to the values of the desired one. The volumes hotplugged into
+// the live VM are kept, and the DataVolumeTemplates it lost are restored.
+func revertDrift(desired, live *kubevirtv1.VirtualMachine, fields []string) {
+	desiredSpec, liveSpec := &desired.Spec.Template.Spec, &live.Spec.Template.Spec
+	for _, field := range fields {
+		switch field {
+		case cpuDriftField:
+			liveSpec.Domain.CPU = desiredSpec.Domain.CPU.DeepCopy()
+			setResource(&liveSpec.Domain.Resources, desiredSpec.Domain.Resources, corev1.ResourceCPU)
+		case memoryDriftField:
+			liveSpec.Domain.Memory = desiredSpec.Domain.Memory.DeepCopy()
+			setResource(&liveSpec.Domain.Resources, desiredSpec.Domain.Resources, corev1.ResourceMemory)
+		case disksDriftField:
+			hotplugged := hotpluggedVolumes(liveSpec)
+			volumes := append([]kubevirtv1.Volume{}, desiredSpec.Volumes...)
+			for _, volume := range liveSpec.Volumes {
+				if hotplugged[volume.Name] {
+					volumes = append(volumes, volume)
+				}
+			}
+			disks := append([]kubevirtv1.Disk{}, desiredSpec.Domain.Devices.Disks...)
+			for _, disk := range liveSpec.Domain.Devices.Disks {
+				if hotplugged[disk.Name] {
+					disks = append(disks, disk)
+				}
+			}
+			liveSpec.Volumes, liveSpec.Domain.Devices.Disks = volumes, disks
+
+			templates := map[string]bool{}
+			for _, template := range live.Spec.DataVolumeTemplates {
+				templates[template.Name] = true
+			}
+			for _, template := range desired.Spec.DataVolumeTemplates {
+				if !templates[template.Name] {
+					live.Spec.DataVolumeTemplates = append(live.Spec.DataVolumeTemplates, *template.DeepCopy())
+				}
+			}
+		case networksDriftField:
+			liveSpec.Networks = append([]kubevirtv1.Network{}, desiredSpec.Networks...)
+			liveSpec.Domain.Devices.Interfaces = append([]kubevirtv1.Interface{}, desiredSpec.Domain.Devices.Interfaces...)
+		}
+	}
+}
+
+// setResource sets the request and the limit of the resource to the desired ones, removing those not desired.
+func setResource(resources *kubevirtv1.ResourceRequirements, desired kubevirtv1.ResourceRequirements, name corev1.ResourceName) {
+	if quantity, found := desired.Requests[name]; found {
+		if resources.Requests == nil {
+			resources.Requests = corev1.ResourceList{}
+		}
+		resources.Requests[name] = quantity
+	} else {
+		delete(resources.Requests, name)
+	}
+	if quantity, found := desired.Limits[name]; found {
+		if resources.Limits == nil {
+			resources.Limits = corev1.ResourceList{}
+		}
+		resources.Limits[name] = quantity
+	} else {
+		delete(resources.Limits, name)
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	gocontext "context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("VM drift", func() {
+	var (
+		machineContext *context.MachineContext
+		vm             *kubevirtv1.VirtualMachine
+		c              client.Client
+	)
+	namespace := kubevirtMachine.Namespace
+
+	BeforeEach(func() {
+		driftedMachine := kubevirtMachine.DeepCopy()
+		template := driftedMachine.Spec.VirtualMachineTemplate.Spec.Template
+		template.Spec.Domain.CPU = &kubevirtv1.CPU{Cores: 2}
+		template.Spec.Domain.Memory = &kubevirtv1.Memory{Guest: ptr.To(resource.MustParse("4Gi"))}
+		template.Spec.Domain.Devices.Disks = []kubevirtv1.Disk{{Name: "rootdisk"}}
+		template.Spec.Volumes = []kubevirtv1.Volume{{
+			Name:         "rootdisk",
+			VolumeSource: kubevirtv1.VolumeSource{DataVolume: &kubevirtv1.DataVolumeSource{Name: "rootdisk"}},
+		}}
+		template.Spec.Networks = []kubevirtv1.Network{*kubevirtv1.DefaultPodNetwork()}
+		template.Spec.Domain.Devices.Interfaces = []kubevirtv1.Interface{*kubevirtv1.DefaultBridgeNetworkInterface()}
+
+		machineContext = &context.MachineContext{
+			Context:             gocontext.TODO(),
+			Cluster:             cluster,
+			KubevirtCluster:     kubevirtCluster,
+			Machine:             machine,
+			KubevirtMachine:     driftedMachine,
+			BootstrapDataSecret: bootstrapDataSecret,
+			Logger:              logger,
+		}
+		vm = newVirtualMachineFromKubevirtMachine(machineContext, namespace)
+	})
+
+	newMachine := func() *Machine {
+		c = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(vm).Build()
+		externalMachine, err := defaultTestMachine(machineContext, namespace, c, FakeVMCommandExecutor{true}, []byte(sshKey))
+		Expect(err).NotTo(HaveOccurred())
+		return externalMachine
+	}
+
+	It("should not report the VM rendered from the machine", func() {
+		Expect(newMachine().Drift()).To(BeEmpty())
+	})
+
+	It("should report the CPU, memory and networks changed in the infra cluster", func() {
+		domain := &vm.Spec.Template.Spec.Domain
+		domain.CPU.Cores = 4
+		domain.Resources.Limits = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("8Gi")}
+		domain.Devices.Interfaces[0].InterfaceBindingMethod = kubevirtv1.InterfaceBindingMethod{Masquerade: &kubevirtv1.InterfaceMasquerade{}}
+
+		Expect(newMachine().Drift()).To(Equal([]string{cpuDriftField, memoryDriftField, networksDriftField}))
+	})
+
+	It("should ignore the maximums KubeVirt defaults for the hotplug", func() {
+		domain := &vm.Spec.Template.Spec.Domain
+		domain.CPU.MaxSockets = 8
+		domain.Memory.MaxGuest = ptr.To(resource.MustParse("16Gi"))
+
+		Expect(newMachine().Drift()).To(BeEmpty())
+	})
+
+	It("should ignore the hotplugged volumes, but not the removed disks", func() {
+		spec := &vm.Spec.Template.Spec
+		spec.Volumes = append(spec.Volumes, kubevirtv1.Volume{
+			Name:         "hotplugged",
+			VolumeSource: kubevirtv1.VolumeSource{DataVolume: &kubevirtv1.DataVolumeSource{Name: "hotplugged", Hotpluggable: true}},
+		})
+		spec.Domain.Devices.Disks = append(spec.Domain.Devices.Disks, kubevirtv1.Disk{Name: "hotplugged"})
+		Expect(newMachine().Drift()).To(BeEmpty())
+
+		spec.Volumes = spec.Volumes[1:]
+		spec.Domain.Devices.Disks = spec.Domain.Devices.Disks[1:]
+		Expect(newMachine().Drift()).To(Equal([]string{disksDriftField}))
+	})
+
+	It("should revert the drifted fields, keeping the hotplugged volumes", func() {
+		spec := &vm.Spec.Template.Spec
+		spec.Domain.Memory.Guest = ptr.To(resource.MustParse("2Gi"))
+		spec.Volumes = []kubevirtv1.Volume{{
+			Name:         "hotplugged",
+			VolumeSource: kubevirtv1.VolumeSource{PersistentVolumeClaim: &kubevirtv1.PersistentVolumeClaimVolumeSource{Hotpluggable: true}},
+		}}
+		spec.Domain.Devices.Disks = []kubevirtv1.Disk{{Name: "hotplugged"}}
+
+		reverted, err := newMachine().RevertDrift(gocontext.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reverted).To(Equal([]string{memoryDriftField, disksDriftField}))
+
+		updated := &kubevirtv1.VirtualMachine{}
+		Expect(c.Get(gocontext.Background(), client.ObjectKeyFromObject(vm), updated)).To(Succeed())
+		Expect(updated.Spec.Template.Spec.Domain.Memory.Guest.String()).To(Equal("4Gi"))
+		var volumes []string
+		for _, volume := range updated.Spec.Template.Spec.Volumes {
+			volumes = append(volumes, volume.Name)
+		}
+		Expect(volumes).To(ContainElements("rootdisk", "hotplugged"))
+		Expect(vmDrift(newVirtualMachineFromKubevirtMachine(machineContext, namespace), updated)).To(BeEmpty())
+	})
+})
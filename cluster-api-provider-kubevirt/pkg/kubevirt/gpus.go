@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	"fmt"
+
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+// UnavailableGPUs returns a message if a GPU of the machine is not permitted by the configuration of KubeVirt, or
+// no infra node provides it, or an empty string otherwise. The GPUs are considered available while the infra cluster
+// is unknown, as long as their resource name is set.
+func UnavailableGPUs(ctx *context.MachineContext) string {
+	var infra *infrav1.InfraStatus
+	if ctx.KubevirtCluster != nil {
+		infra = ctx.KubevirtCluster.Status.Infra
+	}
+	for _, gpu := range ctx.KubevirtMachine.Spec.GPUs {
+		if _, message := resolveGPU(gpu, infra); message != "" {
+			return message
+		}
+	}
+	return ""
+}
+
+// GPUDeviceName returns the resource name of a GPU of the machine: the one of its spec, or else the one of the
+// mediated devices of its type permitted by the configuration of KubeVirt. It is empty when it cannot be resolved.
+func GPUDeviceName(gpu infrav1.MachineGPU, infra *infrav1.InfraStatus) string {
+	deviceName, _ := resolveGPU(gpu, infra)
+	return deviceName
+}
+
+// resolveGPU returns the resource name of a GPU, and a message if it is not available.
+func resolveGPU(gpu infrav1.MachineGPU, infra *infrav1.InfraStatus) (string, string) {
+	if infra == nil || infra.KubeVirtVersion == "" {
+		if gpu.DeviceName == "" {
+			return "", fmt.Sprintf("the resource name of the mediated devices of type %q of GPU %s is unknown while the configuration of KubeVirt cannot be read, set its deviceName", gpu.MediatedDeviceType, gpu.Name)
+		}
+		return gpu.DeviceName, ""
+	}
+
+	var device *infrav1.InfraHostDevice
+	for i := range infra.HostDevices {
+		candidate := &infra.HostDevices[i]
+		if gpu.DeviceName != "" && candidate.ResourceName != gpu.DeviceName {
+			continue
+		}
+		if gpu.MediatedDeviceType != "" && candidate.MediatedDeviceType != gpu.MediatedDeviceType {
+			continue
+		}
+		device = candidate
+		break
+	}
+
+	switch {
+	case device == nil && gpu.DeviceName == "":
+		return "", fmt.Sprintf("the mediated devices of type %q of GPU %s are not permitted by the configuration of KubeVirt", gpu.MediatedDeviceType, gpu.Name)
+	case device == nil && gpu.MediatedDeviceType == "":
+		return gpu.DeviceName, fmt.Sprintf("the host devices %s of GPU %s are not permitted by the configuration of KubeVirt", gpu.DeviceName, gpu.Name)
+	case device == nil:
+		return gpu.DeviceName, fmt.Sprintf("the host devices %s of GPU %s are not permitted as mediated devices of type %q by the configuration of KubeVirt", gpu.DeviceName, gpu.Name, gpu.MediatedDeviceType)
+	case device.Nodes != nil && *device.Nodes == 0:
+		return device.ResourceName, fmt.Sprintf("no infra node provides the host devices %s of GPU %s", device.ResourceName, gpu.Name)
+	}
+	return device.ResourceName, ""
+}
+
+// setGPUs adds the GPUs of the machine to the GPUs of the VMI template, but the ones it already defines.
+func setGPUs(spec *kubevirtv1.VirtualMachineInstanceSpec, ctx *context.MachineContext) {
+	var infra *infrav1.InfraStatus
+	if ctx.KubevirtCluster != nil {
+		infra = ctx.KubevirtCluster.Status.Infra
+	}
+	for _, gpu := range ctx.KubevirtMachine.Spec.GPUs {
+		defined := false
+		for _, existing := range spec.Domain.Devices.GPUs {
+			defined = defined || existing.Name == gpu.Name
+		}
+		deviceName := GPUDeviceName(gpu, infra)
+		if defined || deviceName == "" {
+			continue
+		}
+		spec.Domain.Devices.GPUs = append(spec.Domain.Devices.GPUs, kubevirtv1.GPU{Name: gpu.Name, DeviceName: deviceName})
+	}
+}
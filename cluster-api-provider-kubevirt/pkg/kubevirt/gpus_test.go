@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+var _ = Describe("GPUs", func() {
+	infra := &infrav1.InfraStatus{
+		KubeVirtVersion: "v1.2.1",
+		HostDevices: []infrav1.InfraHostDevice{
+			{ResourceName: "nvidia.com/GRID_T4-1Q", MediatedDeviceType: "GRID T4-1Q", Nodes: ptr.To[int32](2)},
+			{ResourceName: "nvidia.com/GRID_T4-2Q", MediatedDeviceType: "GRID T4-2Q", Nodes: ptr.To[int32](0)},
+			{ResourceName: "nvidia.com/TU104GL_Tesla_T4"},
+		},
+	}
+
+	DescribeTable("should tell if the GPUs of the machine are available", func(infra *infrav1.InfraStatus, gpu infrav1.MachineGPU, expected string) {
+		ctx := &context.MachineContext{
+			KubevirtCluster: &infrav1.KubevirtCluster{Status: infrav1.KubevirtClusterStatus{Infra: infra}},
+			KubevirtMachine: &infrav1.KubevirtMachine{Spec: infrav1.KubevirtMachineSpec{GPUs: []infrav1.MachineGPU{gpu}}},
+		}
+		if expected == "" {
+			Expect(UnavailableGPUs(ctx)).To(BeEmpty())
+		} else {
+			Expect(UnavailableGPUs(ctx)).To(ContainSubstring(expected))
+		}
+	},
+		Entry("permitted mediated device type", infra, infrav1.MachineGPU{Name: "vgpu", MediatedDeviceType: "GRID T4-1Q"}, ""),
+		Entry("permitted PCI device", infra, infrav1.MachineGPU{Name: "gpu", DeviceName: "nvidia.com/TU104GL_Tesla_T4"}, ""),
+		Entry("consistent resource name and type", infra, infrav1.MachineGPU{Name: "vgpu", DeviceName: "nvidia.com/GRID_T4-1Q", MediatedDeviceType: "GRID T4-1Q"}, ""),
+		Entry("mediated device type not permitted", infra, infrav1.MachineGPU{Name: "vgpu", MediatedDeviceType: "GRID T4-4Q"}, "are not permitted by the configuration of KubeVirt"),
+		Entry("PCI device not permitted", infra, infrav1.MachineGPU{Name: "gpu", DeviceName: "nvidia.com/GA100_A100"}, "are not permitted by the configuration of KubeVirt"),
+		Entry("inconsistent resource name and type", infra, infrav1.MachineGPU{Name: "vgpu", DeviceName: "nvidia.com/GRID_T4-1Q", MediatedDeviceType: "GRID T4-2Q"}, "are not permitted as mediated devices"),
+		Entry("no node providing the device", infra, infrav1.MachineGPU{Name: "vgpu", MediatedDeviceType: "GRID T4-2Q"}, "no infra node provides"),
+		Entry("unknown infra with a resource name", nil, infrav1.MachineGPU{Name: "gpu", DeviceName: "nvidia.com/GA100_A100"}, ""),
+		Entry("unknown infra without a resource name", nil, infrav1.MachineGPU{Name: "vgpu", MediatedDeviceType: "GRID T4-1Q"}, "set its deviceName"),
+	)
+
+	It("should add the GPUs of the machine to the VMI", func() {
+		machineContext := &context.MachineContext{
+			Cluster:         cluster,
+			KubevirtCluster: kubevirtCluster.DeepCopy(),
+			Machine:         machine,
+			KubevirtMachine: kubevirtMachine.DeepCopy(),
+		}
+		machineContext.KubevirtCluster.Status.Infra = infra
+		machineContext.KubevirtMachine.Spec.GPUs = []infrav1.MachineGPU{
+			{Name: "vgpu", MediatedDeviceType: "GRID T4-1Q"},
+			{Name: "gpu", DeviceName: "nvidia.com/TU104GL_Tesla_T4"},
+		}
+		machineContext.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.Template.Spec.Domain.Devices.GPUs = []kubevirtv1.GPU{
+			{Name: "gpu", DeviceName: "nvidia.com/GA100_A100"},
+		}
+
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+
+		Expect(newVM.Spec.Template.Spec.Domain.Devices.GPUs).To(Equal([]kubevirtv1.GPU{
+			{Name: "gpu", DeviceName: "nvidia.com/GA100_A100"},
+			{Name: "vgpu", DeviceName: "nvidia.com/GRID_T4-1Q"},
+		}))
+		Expect(machineContext.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.Template.Spec.Domain.Devices.GPUs).To(HaveLen(1))
+	})
+})
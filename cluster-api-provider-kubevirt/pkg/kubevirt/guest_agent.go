@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	"time"
+
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+// defaultGuestAgentConnectTimeout is how long the required guest agent of a running VMI may take to connect.
+const defaultGuestAgentConnectTimeout = 5 * time.Minute
+
+// GuestAgentMode returns whether the image of the VM of the machine runs the qemu-guest-agent, Optional by default.
+func GuestAgentMode(kubevirtMachine *infrav1.KubevirtMachine) infrav1.GuestAgentMode {
+	if spec := kubevirtMachine.Spec.GuestAgent; spec != nil && spec.Mode != "" {
+		return spec.Mode
+	}
+	return infrav1.GuestAgentOptional
+}
+
+// GuestAgentEnabled returns true unless the machine disables the guest agent of its VM.
+func GuestAgentEnabled(kubevirtMachine *infrav1.KubevirtMachine) bool {
+	return GuestAgentMode(kubevirtMachine) != infrav1.GuestAgentDisabled
+}
+
+// GuestAgentConnectTimeout returns how long the required guest agent of the running VMI of the machine may take
+// to connect.
+func GuestAgentConnectTimeout(kubevirtMachine *infrav1.KubevirtMachine) time.Duration {
+	if spec := kubevirtMachine.Spec.GuestAgent; spec != nil && spec.ConnectTimeout != nil {
+		return spec.ConnectTimeout.Duration
+	}
+	return defaultGuestAgentConnectTimeout
+}
+
+// setGuestAgent attaches the serial console and the VSOCK device of the machine to the VMI, and makes the VMI
+// ready only once its guest agent responds when the machine requires it, unless the template defines a readiness
+// probe.
+func setGuestAgent(spec *kubevirtv1.VirtualMachineInstanceSpec, ctx *context.MachineContext) {
+	guestAgent := ctx.KubevirtMachine.Spec.GuestAgent
+	if guestAgent == nil {
+		return
+	}
+	if guestAgent.SerialConsole != nil {
+		spec.Domain.Devices.AutoattachSerialConsole = guestAgent.SerialConsole
+	}
+	if guestAgent.VSOCK != nil {
+		spec.Domain.Devices.AutoattachVSOCK = guestAgent.VSOCK
+	}
+	if guestAgent.Mode == infrav1.GuestAgentRequired && spec.ReadinessProbe == nil {
+		spec.ReadinessProbe = &kubevirtv1.Probe{
+			Handler:          kubevirtv1.Handler{GuestAgentPing: &kubevirtv1.GuestAgentPing{}},
+			PeriodSeconds:    10,
+			FailureThreshold: 3,
+		}
+	}
+}
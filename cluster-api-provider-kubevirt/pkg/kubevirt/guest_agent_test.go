@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+var _ = Describe("Guest agent", func() {
+	newMachineContext := func(guestAgent *infrav1.GuestAgentSpec) *context.MachineContext {
+		machineContext := &context.MachineContext{
+			Cluster:         cluster,
+			KubevirtCluster: kubevirtCluster,
+			Machine:         machine,
+			KubevirtMachine: kubevirtMachine.DeepCopy(),
+		}
+		machineContext.KubevirtMachine.Spec.GuestAgent = guestAgent
+		return machineContext
+	}
+
+	It("should default to an optional guest agent", func() {
+		machineContext := newMachineContext(nil)
+		Expect(GuestAgentMode(machineContext.KubevirtMachine)).To(Equal(infrav1.GuestAgentOptional))
+		Expect(GuestAgentEnabled(machineContext.KubevirtMachine)).To(BeTrue())
+		Expect(GuestAgentConnectTimeout(machineContext.KubevirtMachine)).To(Equal(5 * time.Minute))
+
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+		Expect(newVM.Spec.Template.Spec.ReadinessProbe).To(BeNil())
+		Expect(newVM.Spec.Template.Spec.Domain.Devices.AutoattachSerialConsole).To(BeNil())
+		Expect(newVM.Spec.Template.Spec.Domain.Devices.AutoattachVSOCK).To(BeNil())
+	})
+
+	It("should make the VMI ready once its required guest agent responds", func() {
+		machineContext := newMachineContext(&infrav1.GuestAgentSpec{
+			Mode:           infrav1.GuestAgentRequired,
+			ConnectTimeout: &metav1.Duration{Duration: 2 * time.Minute},
+		})
+		Expect(GuestAgentConnectTimeout(machineContext.KubevirtMachine)).To(Equal(2 * time.Minute))
+
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+		Expect(newVM.Spec.Template.Spec.ReadinessProbe).To(Equal(&kubevirtv1.Probe{
+			Handler:          kubevirtv1.Handler{GuestAgentPing: &kubevirtv1.GuestAgentPing{}},
+			PeriodSeconds:    10,
+			FailureThreshold: 3,
+		}))
+	})
+
+	It("should keep the readiness probe of the template", func() {
+		machineContext := newMachineContext(&infrav1.GuestAgentSpec{Mode: infrav1.GuestAgentRequired})
+		probe := &kubevirtv1.Probe{InitialDelaySeconds: 60, Handler: kubevirtv1.Handler{GuestAgentPing: &kubevirtv1.GuestAgentPing{}}}
+		machineContext.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.Template.Spec.ReadinessProbe = probe
+
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+		Expect(newVM.Spec.Template.Spec.ReadinessProbe).To(Equal(probe))
+	})
+
+	It("should attach the serial console and the VSOCK device of the machine", func() {
+		machineContext := newMachineContext(&infrav1.GuestAgentSpec{
+			Mode:          infrav1.GuestAgentDisabled,
+			SerialConsole: ptr.To(false),
+			VSOCK:         ptr.To(true),
+		})
+		Expect(GuestAgentEnabled(machineContext.KubevirtMachine)).To(BeFalse())
+
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+		Expect(newVM.Spec.Template.Spec.Domain.Devices.AutoattachSerialConsole).To(Equal(ptr.To(false)))
+		Expect(newVM.Spec.Template.Spec.Domain.Devices.AutoattachVSOCK).To(Equal(ptr.To(true)))
+		Expect(newVM.Spec.Template.Spec.ReadinessProbe).To(BeNil())
+	})
+})
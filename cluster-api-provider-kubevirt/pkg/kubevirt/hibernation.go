@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	gocontext "context"
+
+	"github.com/pkg/errors"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+// HibernateVirtualMachine stops the VM, keeping its disks, and records the run strategy to restore when
+// the VM is resumed. It returns true once the VM is stopped.
+func HibernateVirtualMachine(ctx gocontext.Context, c client.Client, vm *kubevirtv1.VirtualMachine) (bool, error) {
+	return haltVirtualMachine(ctx, c, vm, infrav1.HibernatedRunStrategyAnnotation)
+}
+
+// ResumeVirtualMachine restores the run strategy the VM had before it was hibernated. It is a no-op
+// for a VM that is not hibernated.
+func ResumeVirtualMachine(ctx gocontext.Context, c client.Client, vm *kubevirtv1.VirtualMachine) error {
+	return restoreVirtualMachine(ctx, c, vm, infrav1.HibernatedRunStrategyAnnotation)
+}
+
+// haltVirtualMachine stops the VM, and records the run strategy to restore in the annotation. It returns true
+// once the VM is stopped.
+func haltVirtualMachine(ctx gocontext.Context, c client.Client, vm *kubevirtv1.VirtualMachine, annotation string) (bool, error) {
+	if _, halted := vm.Annotations[annotation]; !halted {
+		runStrategy, err := vm.RunStrategy()
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to get the run strategy of VM %s/%s", vm.Namespace, vm.Name)
+		}
+
+		patchBase := client.MergeFrom(vm.DeepCopy())
+		if vm.Annotations == nil {
+			vm.Annotations = map[string]string{}
+		}
+		vm.Annotations[annotation] = string(runStrategy)
+		halted := kubevirtv1.RunStrategyHalted
+		vm.Spec.RunStrategy = &halted
+		vm.Spec.Running = nil
+
+		if err := c.Patch(ctx, vm, patchBase); err != nil {
+			return false, errors.Wrapf(err, "failed to stop VM %s/%s", vm.Namespace, vm.Name)
+		}
+	}
+
+	return vm.Status.PrintableStatus == kubevirtv1.VirtualMachineStatusStopped, nil
+}
+
+// restoreVirtualMachine restores the run strategy recorded in the annotation, if any.
+func restoreVirtualMachine(ctx gocontext.Context, c client.Client, vm *kubevirtv1.VirtualMachine, annotation string) error {
+	runStrategy, halted := vm.Annotations[annotation]
+	if !halted {
+		return nil
+	}
+
+	patchBase := client.MergeFrom(vm.DeepCopy())
+	delete(vm.Annotations, annotation)
+	restored := kubevirtv1.VirtualMachineRunStrategy(runStrategy)
+	vm.Spec.RunStrategy = &restored
+	vm.Spec.Running = nil
+
+	if err := c.Patch(ctx, vm, patchBase); err != nil {
+		return errors.Wrapf(err, "failed to start VM %s/%s", vm.Namespace, vm.Name)
+	}
+
+	return nil
+}
+
+// IsHibernated returns true if the VM was stopped by the hibernation of its cluster.
+func IsHibernated(vm *kubevirtv1.VirtualMachine) bool {
+	_, hibernated := vm.Annotations[infrav1.HibernatedRunStrategyAnnotation]
+	return hibernated
+}
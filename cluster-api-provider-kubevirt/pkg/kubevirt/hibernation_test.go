@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	gocontext "context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("Hibernation", func() {
+	var (
+		ctx = gocontext.Background()
+		vm  *kubevirtv1.VirtualMachine
+		c   client.Client
+	)
+
+	BeforeEach(func() {
+		vm = testing.NewVirtualMachine(testing.NewVirtualMachineInstance(kubevirtMachine))
+		vm.Spec.Running = ptr.To(true)
+		c = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(vm).Build()
+	})
+
+	It("should halt the VM and record its run strategy", func() {
+		stopped, err := HibernateVirtualMachine(ctx, c, vm)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stopped).To(BeFalse())
+
+		updated := &kubevirtv1.VirtualMachine{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(vm), updated)).To(Succeed())
+		Expect(updated.Spec.Running).To(BeNil())
+		Expect(updated.Spec.RunStrategy).To(HaveValue(Equal(kubevirtv1.RunStrategyHalted)))
+		Expect(updated.Annotations).To(HaveKeyWithValue(infrav1.HibernatedRunStrategyAnnotation, string(kubevirtv1.RunStrategyAlways)))
+		Expect(IsHibernated(updated)).To(BeTrue())
+	})
+
+	It("should report a hibernated VM as stopped once KubeVirt stopped it", func() {
+		_, err := HibernateVirtualMachine(ctx, c, vm)
+		Expect(err).ToNot(HaveOccurred())
+
+		vm.Status.PrintableStatus = kubevirtv1.VirtualMachineStatusStopped
+		stopped, err := HibernateVirtualMachine(ctx, c, vm)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stopped).To(BeTrue())
+		Expect(vm.Annotations).To(HaveKeyWithValue(infrav1.HibernatedRunStrategyAnnotation, string(kubevirtv1.RunStrategyAlways)))
+	})
+
+	It("should restore the recorded run strategy on resume", func() {
+		_, err := HibernateVirtualMachine(ctx, c, vm)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ResumeVirtualMachine(ctx, c, vm)).To(Succeed())
+
+		updated := &kubevirtv1.VirtualMachine{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(vm), updated)).To(Succeed())
+		Expect(updated.Spec.RunStrategy).To(HaveValue(Equal(kubevirtv1.RunStrategyAlways)))
+		Expect(updated.Annotations).ToNot(HaveKey(infrav1.HibernatedRunStrategyAnnotation))
+		Expect(IsHibernated(updated)).To(BeFalse())
+	})
+
+	It("should not modify a VM that is not hibernated on resume", func() {
+		Expect(ResumeVirtualMachine(ctx, c, vm)).To(Succeed())
+
+		updated := &kubevirtv1.VirtualMachine{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(vm), updated)).To(Succeed())
+		Expect(updated.Spec.Running).To(HaveValue(BeTrue()))
+		Expect(updated.Spec.RunStrategy).To(BeNil())
+	})
+})
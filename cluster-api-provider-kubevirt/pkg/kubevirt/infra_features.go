@@ -0,0 +1,261 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	gocontext "context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/version"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+// InfraFeature is a feature of the provider depending on an optional feature of KubeVirt.
+type InfraFeature string
+
+const (
+	// HotplugVolumesFeature allows the VMs to use hotpluggable volumes. It requires the HotplugVolumes feature gate
+	// of KubeVirt.
+	HotplugVolumesFeature InfraFeature = "HotplugVolumes"
+
+	// InstancetypesFeature allows the VM templates to reference instancetypes and preferences. It requires
+	// KubeVirt v1.0.0 or later.
+	InstancetypesFeature InfraFeature = "Instancetypes"
+
+	// VMExportFeature allows to export the disks of the VMs. It requires the VMExport feature gate of KubeVirt.
+	VMExportFeature InfraFeature = "VMExport"
+
+	// LiveMigrationFeature allows the VMs to be live migrated on eviction. It requires the LiveMigration feature
+	// gate of KubeVirt before v1.0.0, where it graduated.
+	LiveMigrationFeature InfraFeature = "LiveMigration"
+
+	// CPUManagerFeature allows the VMs to run on dedicated CPUs. It requires the CPUManager feature gate of KubeVirt.
+	CPUManagerFeature InfraFeature = "CPUManager"
+
+	// VMLiveUpdateFeature allows to hotplug the CPU sockets and the memory of the running VMs. It requires the
+	// VMLiveUpdateFeatures feature gate and the LiveUpdate rollout strategy of KubeVirt.
+	VMLiveUpdateFeature InfraFeature = "VMLiveUpdate"
+)
+
+// InfraFeatures lists the features of the provider depending on the infra cluster.
+var InfraFeatures = []InfraFeature{HotplugVolumesFeature, InstancetypesFeature, VMExportFeature, LiveMigrationFeature, CPUManagerFeature, VMLiveUpdateFeature}
+
+var (
+	infraFeatureGates = map[InfraFeature]string{
+		HotplugVolumesFeature: "HotplugVolumes",
+		VMExportFeature:       "VMExport",
+		LiveMigrationFeature:  "LiveMigration",
+		CPUManagerFeature:     "CPUManager",
+		VMLiveUpdateFeature:   "VMLiveUpdateFeatures",
+	}
+
+	// graduatedFeatureGates are the versions of KubeVirt from which the feature gates are always enabled.
+	graduatedFeatureGates = map[InfraFeature]*version.Version{
+		LiveMigrationFeature: version.MustParseGeneric("1.0.0"),
+	}
+
+	instancetypesMinVersion = version.MustParseGeneric("1.0.0")
+)
+
+// DetectInfraStatus reads the versions and the enabled feature gates of KubeVirt and CDI from their resources in
+// the infra cluster, the host devices KubeVirt permits, and the architectures, the zones and the host devices of
+// its nodes. The fields of a component are left empty when its resource cannot be read with the credentials of the
+// infra cluster, or the component is not deployed.
+func DetectInfraStatus(ctx gocontext.Context, c client.Client) (*infrav1.InfraStatus, error) {
+	status := &infrav1.InfraStatus{}
+
+	kubevirts := &kubevirtv1.KubeVirtList{}
+	if err := c.List(ctx, kubevirts); err != nil {
+		if !isUnreadable(err) {
+			return nil, errors.Wrap(err, "failed to list KubeVirt resources")
+		}
+	} else if len(kubevirts.Items) > 0 {
+		kv := &kubevirts.Items[0]
+		status.KubeVirtVersion = kv.Status.ObservedKubeVirtVersion
+		if kv.Spec.Configuration.DeveloperConfiguration != nil {
+			status.KubeVirtFeatureGates = sortedCopy(kv.Spec.Configuration.DeveloperConfiguration.FeatureGates)
+		}
+		if kv.Spec.Configuration.VMRolloutStrategy != nil {
+			status.VMRolloutStrategy = string(*kv.Spec.Configuration.VMRolloutStrategy)
+		}
+		status.HostDevices = permittedHostDevices(kv.Spec.Configuration.PermittedHostDevices)
+	}
+
+	cdis := &cdiv1.CDIList{}
+	if err := c.List(ctx, cdis); err != nil {
+		if !isUnreadable(err) {
+			return nil, errors.Wrap(err, "failed to list CDI resources")
+		}
+	} else if len(cdis.Items) > 0 {
+		cdi := &cdis.Items[0]
+		status.CDIVersion = cdi.Status.ObservedVersion
+		if cdi.Spec.Config != nil {
+			status.CDIFeatureGates = sortedCopy(cdi.Spec.Config.FeatureGates)
+		}
+	}
+
+	nodes := &corev1.NodeList{}
+	if err := c.List(ctx, nodes); err != nil {
+		if !isUnreadable(err) {
+			return nil, errors.Wrap(err, "failed to list infra nodes")
+		}
+	} else {
+		for _, node := range nodes.Items {
+			if architecture := node.Labels[corev1.LabelArchStable]; architecture != "" && !slices.Contains(status.NodeArchitectures, architecture) {
+				status.NodeArchitectures = append(status.NodeArchitectures, architecture)
+			}
+			if zone := node.Labels[corev1.LabelTopologyZone]; zone != "" && !slices.Contains(status.NodeZones, zone) {
+				status.NodeZones = append(status.NodeZones, zone)
+			}
+		}
+		slices.Sort(status.NodeArchitectures)
+		slices.Sort(status.NodeZones)
+		countHostDeviceNodes(status.HostDevices, nodes.Items)
+	}
+
+	return status, nil
+}
+
+// IsInfraFeatureAvailable returns false if the infra cluster is known not to provide the feature. A feature is
+// considered available while the version of KubeVirt is unknown, so that restricted credentials do not block it.
+func IsInfraFeatureAvailable(infra *infrav1.InfraStatus, feature InfraFeature) bool {
+	if infra == nil || infra.KubeVirtVersion == "" {
+		return true
+	}
+	if feature == VMLiveUpdateFeature && infra.VMRolloutStrategy != string(kubevirtv1.VMRolloutStrategyLiveUpdate) {
+		return false
+	}
+	if gate, found := infraFeatureGates[feature]; found {
+		if graduated, found := graduatedFeatureGates[feature]; found {
+			if v, err := version.ParseGeneric(infra.KubeVirtVersion); err == nil && v.AtLeast(graduated) {
+				return true
+			}
+		}
+		return slices.Contains(infra.KubeVirtFeatureGates, gate)
+	}
+	if feature == InstancetypesFeature {
+		v, err := version.ParseGeneric(infra.KubeVirtVersion)
+		return err != nil || v.AtLeast(instancetypesMinVersion)
+	}
+	return true
+}
+
+// RequiredInfraFeatures returns the features of the infra cluster the VM template uses.
+func RequiredInfraFeatures(template *infrav1.VirtualMachineTemplateSpec) []InfraFeature {
+	var features []InfraFeature
+	if template.Spec.Instancetype != nil || template.Spec.Preference != nil {
+		features = append(features, InstancetypesFeature)
+	}
+	if template.Spec.Template != nil {
+		for _, volume := range template.Spec.Template.Spec.Volumes {
+			if (volume.DataVolume != nil && volume.DataVolume.Hotpluggable) ||
+				(volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.Hotpluggable) {
+				features = append(features, HotplugVolumesFeature)
+				break
+			}
+		}
+		vmiSpec := &template.Spec.Template.Spec
+		if vmiSpec.EvictionStrategy != nil && *vmiSpec.EvictionStrategy == kubevirtv1.EvictionStrategyLiveMigrate {
+			features = append(features, LiveMigrationFeature)
+		}
+		if vmiSpec.Domain.CPU != nil && vmiSpec.Domain.CPU.DedicatedCPUPlacement {
+			features = append(features, CPUManagerFeature)
+		}
+	}
+	return features
+}
+
+// UnavailableInfraFeatures returns a message describing the features used by the VM template the infra cluster
+// does not provide, or an empty string if they are all available.
+func UnavailableInfraFeatures(infra *infrav1.InfraStatus, template *infrav1.VirtualMachineTemplateSpec) string {
+	var missing []string
+	for _, feature := range RequiredInfraFeatures(template) {
+		if IsInfraFeatureAvailable(infra, feature) {
+			continue
+		}
+		if gate, found := infraFeatureGates[feature]; found {
+			missing = append(missing, fmt.Sprintf("%s requires the %s feature gate of KubeVirt", feature, gate))
+		} else {
+			missing = append(missing, fmt.Sprintf("%s requires KubeVirt v%s or later, the infra cluster runs %s", feature, instancetypesMinVersion, infra.KubeVirtVersion))
+		}
+	}
+	return strings.Join(missing, "; ")
+}
+
+// UnavailableArchitecture returns a message if none of the infra nodes runs the architecture of a guest, or an
+// empty string if some do. An architecture is considered available while the infra nodes are unknown.
+func UnavailableArchitecture(infra *infrav1.InfraStatus, architecture string) string {
+	if infra == nil || len(infra.NodeArchitectures) == 0 || architecture == "" || slices.Contains(infra.NodeArchitectures, architecture) {
+		return ""
+	}
+	return fmt.Sprintf("no infra node runs the %s architecture, the infra nodes run %s", architecture, strings.Join(infra.NodeArchitectures, ", "))
+}
+
+// permittedHostDevices returns the PCI and mediated devices the configuration of KubeVirt permits, sorted by
+// resource name.
+func permittedHostDevices(permitted *kubevirtv1.PermittedHostDevices) []infrav1.InfraHostDevice {
+	if permitted == nil {
+		return nil
+	}
+	var devices []infrav1.InfraHostDevice
+	for _, device := range permitted.PciHostDevices {
+		devices = append(devices, infrav1.InfraHostDevice{ResourceName: device.ResourceName})
+	}
+	for _, device := range permitted.MediatedDevices {
+		devices = append(devices, infrav1.InfraHostDevice{ResourceName: device.ResourceName, MediatedDeviceType: device.MDEVNameSelector})
+	}
+	slices.SortFunc(devices, func(a, b infrav1.InfraHostDevice) int { return strings.Compare(a.ResourceName, b.ResourceName) })
+	return slices.CompactFunc(devices, func(a, b infrav1.InfraHostDevice) bool { return a.ResourceName == b.ResourceName })
+}
+
+// countHostDeviceNodes counts the nodes with allocatable devices of each host device.
+func countHostDeviceNodes(devices []infrav1.InfraHostDevice, nodes []corev1.Node) {
+	for i := range devices {
+		var count int32
+		for _, node := range nodes {
+			if allocatable, found := node.Status.Allocatable[corev1.ResourceName(devices[i].ResourceName)]; found && !allocatable.IsZero() {
+				count++
+			}
+		}
+		devices[i].Nodes = &count
+	}
+}
+
+// isUnreadable returns true if the error means the resources of a component cannot be listed, because the
+// credentials do not allow it or the component is not deployed.
+func isUnreadable(err error) bool {
+	return apierrors.IsForbidden(err) || apierrors.IsNotFound(err) || meta.IsNoMatchError(err)
+}
+
+func sortedCopy(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	return sorted
+}
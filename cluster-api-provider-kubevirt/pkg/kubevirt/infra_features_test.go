@@ -0,0 +1,194 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	gocontext "context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("Infra features", func() {
+	It("should read the versions and the feature gates of KubeVirt and CDI", func() {
+		kv := &kubevirtv1.KubeVirt{
+			ObjectMeta: metav1.ObjectMeta{Name: "kubevirt", Namespace: "kubevirt"},
+			Spec: kubevirtv1.KubeVirtSpec{
+				Configuration: kubevirtv1.KubeVirtConfiguration{
+					DeveloperConfiguration: &kubevirtv1.DeveloperConfiguration{FeatureGates: []string{"VMExport", "HotplugVolumes"}},
+					VMRolloutStrategy:      ptr.To(kubevirtv1.VMRolloutStrategyLiveUpdate),
+				},
+			},
+			Status: kubevirtv1.KubeVirtStatus{ObservedKubeVirtVersion: "v1.2.1"},
+		}
+		cdi := &cdiv1.CDI{
+			ObjectMeta: metav1.ObjectMeta{Name: "cdi"},
+			Spec:       cdiv1.CDISpec{Config: &cdiv1.CDIConfigSpec{FeatureGates: []string{"HonorWaitForFirstConsumer"}}},
+		}
+		cdi.Status.ObservedVersion = "v1.59.0"
+		c := fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(kv, cdi).Build()
+
+		status, err := DetectInfraStatus(gocontext.Background(), c)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(status.KubeVirtVersion).To(Equal("v1.2.1"))
+		Expect(status.KubeVirtFeatureGates).To(Equal([]string{"HotplugVolumes", "VMExport"}))
+		Expect(status.VMRolloutStrategy).To(Equal("LiveUpdate"))
+		Expect(status.CDIVersion).To(Equal("v1.59.0"))
+		Expect(status.CDIFeatureGates).To(Equal([]string{"HonorWaitForFirstConsumer"}))
+	})
+
+	It("should read the architectures of the infra nodes", func() {
+		var nodes []client.Object
+		for name, architecture := range map[string]string{"node1": "arm64", "node2": "amd64", "node3": "arm64"} {
+			nodes = append(nodes, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{corev1.LabelArchStable: architecture}}})
+		}
+		c := fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(nodes...).Build()
+
+		status, err := DetectInfraStatus(gocontext.Background(), c)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(status.NodeArchitectures).To(Equal([]string{"amd64", "arm64"}))
+	})
+
+	It("should read the zones of the infra nodes", func() {
+		var nodes []client.Object
+		for name, zone := range map[string]string{"node1": "zone-b", "node2": "zone-a", "node3": "zone-b", "node4": ""} {
+			nodes = append(nodes, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{corev1.LabelTopologyZone: zone}}})
+		}
+		c := fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(nodes...).Build()
+
+		status, err := DetectInfraStatus(gocontext.Background(), c)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(status.NodeZones).To(Equal([]string{"zone-a", "zone-b"}))
+	})
+
+	It("should read the host devices permitted by KubeVirt and the nodes providing them", func() {
+		kv := &kubevirtv1.KubeVirt{
+			ObjectMeta: metav1.ObjectMeta{Name: "kubevirt", Namespace: "kubevirt"},
+			Spec: kubevirtv1.KubeVirtSpec{
+				Configuration: kubevirtv1.KubeVirtConfiguration{
+					PermittedHostDevices: &kubevirtv1.PermittedHostDevices{
+						PciHostDevices:  []kubevirtv1.PciHostDevice{{PCIVendorSelector: "10DE:1EB8", ResourceName: "nvidia.com/TU104GL_Tesla_T4"}},
+						MediatedDevices: []kubevirtv1.MediatedHostDevice{{MDEVNameSelector: "GRID T4-1Q", ResourceName: "nvidia.com/GRID_T4-1Q"}},
+					},
+				},
+			},
+			Status: kubevirtv1.KubeVirtStatus{ObservedKubeVirtVersion: "v1.2.1"},
+		}
+		newNode := func(name string, resources corev1.ResourceList) *corev1.Node {
+			return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}, Status: corev1.NodeStatus{Allocatable: resources}}
+		}
+		c := fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(kv,
+			newNode("node1", corev1.ResourceList{"nvidia.com/GRID_T4-1Q": resource.MustParse("16")}),
+			newNode("node2", corev1.ResourceList{"nvidia.com/GRID_T4-1Q": resource.MustParse("0")}),
+			newNode("node3", nil),
+		).Build()
+
+		status, err := DetectInfraStatus(gocontext.Background(), c)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(status.HostDevices).To(Equal([]infrav1.InfraHostDevice{
+			{ResourceName: "nvidia.com/GRID_T4-1Q", MediatedDeviceType: "GRID T4-1Q", Nodes: ptr.To[int32](1)},
+			{ResourceName: "nvidia.com/TU104GL_Tesla_T4", Nodes: ptr.To[int32](0)},
+		}))
+	})
+
+	It("should tell if the infra nodes run an architecture", func() {
+		infra := &infrav1.InfraStatus{NodeArchitectures: []string{"amd64"}}
+		Expect(UnavailableArchitecture(infra, "amd64")).To(BeEmpty())
+		Expect(UnavailableArchitecture(infra, "")).To(BeEmpty())
+		Expect(UnavailableArchitecture(infra, "arm64")).To(Equal("no infra node runs the arm64 architecture, the infra nodes run amd64"))
+		Expect(UnavailableArchitecture(&infrav1.InfraStatus{}, "arm64")).To(BeEmpty())
+		Expect(UnavailableArchitecture(nil, "arm64")).To(BeEmpty())
+	})
+
+	It("should leave the versions empty when KubeVirt and CDI are not found", func() {
+		c := fake.NewClientBuilder().WithScheme(testing.SetupScheme()).Build()
+
+		status, err := DetectInfraStatus(gocontext.Background(), c)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(status.KubeVirtVersion).To(BeEmpty())
+		Expect(status.CDIVersion).To(BeEmpty())
+	})
+
+	DescribeTable("should tell if a feature is available", func(infra *infrav1.InfraStatus, feature InfraFeature, expected bool) {
+		Expect(IsInfraFeatureAvailable(infra, feature)).To(Equal(expected))
+	},
+		Entry("unknown infra", nil, HotplugVolumesFeature, true),
+		Entry("unknown KubeVirt version", &infrav1.InfraStatus{CDIVersion: "v1.59.0"}, VMExportFeature, true),
+		Entry("enabled feature gate", &infrav1.InfraStatus{KubeVirtVersion: "v1.2.1", KubeVirtFeatureGates: []string{"HotplugVolumes"}}, HotplugVolumesFeature, true),
+		Entry("disabled feature gate", &infrav1.InfraStatus{KubeVirtVersion: "v1.2.1"}, VMExportFeature, false),
+		Entry("instancetypes on v1", &infrav1.InfraStatus{KubeVirtVersion: "v1.0.1"}, InstancetypesFeature, true),
+		Entry("instancetypes before v1", &infrav1.InfraStatus{KubeVirtVersion: "v0.59.2"}, InstancetypesFeature, false),
+		Entry("graduated feature gate", &infrav1.InfraStatus{KubeVirtVersion: "v1.2.1"}, LiveMigrationFeature, true),
+		Entry("feature gate before its graduation", &infrav1.InfraStatus{KubeVirtVersion: "v0.59.2"}, LiveMigrationFeature, false),
+		Entry("disabled CPU manager", &infrav1.InfraStatus{KubeVirtVersion: "v1.2.1", KubeVirtFeatureGates: []string{"HotplugVolumes"}}, CPUManagerFeature, false),
+		Entry("live update", &infrav1.InfraStatus{KubeVirtVersion: "v1.2.1", KubeVirtFeatureGates: []string{"VMLiveUpdateFeatures"}, VMRolloutStrategy: "LiveUpdate"}, VMLiveUpdateFeature, true),
+		Entry("live update with the Stage rollout strategy", &infrav1.InfraStatus{KubeVirtVersion: "v1.2.1", KubeVirtFeatureGates: []string{"VMLiveUpdateFeatures"}, VMRolloutStrategy: "Stage"}, VMLiveUpdateFeature, false),
+	)
+
+	It("should describe the features of the template the infra cluster does not provide", func() {
+		template := &infrav1.VirtualMachineTemplateSpec{
+			Spec: kubevirtv1.VirtualMachineSpec{
+				Instancetype: &kubevirtv1.InstancetypeMatcher{Name: "u1.medium"},
+				Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+					Spec: kubevirtv1.VirtualMachineInstanceSpec{
+						Volumes: []kubevirtv1.Volume{{
+							Name: "data",
+							VolumeSource: kubevirtv1.VolumeSource{
+								DataVolume: &kubevirtv1.DataVolumeSource{Name: "data", Hotpluggable: true},
+							},
+						}},
+					},
+				},
+			},
+		}
+		Expect(RequiredInfraFeatures(template)).To(Equal([]InfraFeature{InstancetypesFeature, HotplugVolumesFeature}))
+
+		Expect(UnavailableInfraFeatures(&infrav1.InfraStatus{KubeVirtVersion: "v0.59.2"}, template)).To(Equal(
+			"Instancetypes requires KubeVirt v1.0.0 or later, the infra cluster runs v0.59.2; HotplugVolumes requires the HotplugVolumes feature gate of KubeVirt"))
+		Expect(UnavailableInfraFeatures(&infrav1.InfraStatus{KubeVirtVersion: "v1.2.1", KubeVirtFeatureGates: []string{"HotplugVolumes"}}, template)).To(BeEmpty())
+		Expect(UnavailableInfraFeatures(nil, template)).To(BeEmpty())
+	})
+
+	It("should require the feature gates of the live migration and the dedicated CPUs of the template", func() {
+		template := &infrav1.VirtualMachineTemplateSpec{
+			Spec: kubevirtv1.VirtualMachineSpec{
+				Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+					Spec: kubevirtv1.VirtualMachineInstanceSpec{
+						EvictionStrategy: ptr.To(kubevirtv1.EvictionStrategyLiveMigrate),
+						Domain:           kubevirtv1.DomainSpec{CPU: &kubevirtv1.CPU{DedicatedCPUPlacement: true}},
+					},
+				},
+			},
+		}
+		Expect(RequiredInfraFeatures(template)).To(Equal([]InfraFeature{LiveMigrationFeature, CPUManagerFeature}))
+
+		Expect(UnavailableInfraFeatures(&infrav1.InfraStatus{KubeVirtVersion: "v1.2.1"}, template)).To(Equal(
+			"CPUManager requires the CPUManager feature gate of KubeVirt"))
+		Expect(UnavailableInfraFeatures(&infrav1.InfraStatus{KubeVirtVersion: "v1.2.1", KubeVirtFeatureGates: []string{"CPUManager"}}, template)).To(BeEmpty())
+	})
+})
@@ -19,23 +19,32 @@ package kubevirt
 import (
 	gocontext "context"
 	"fmt"
+	"net"
+	"strings"
 	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	kubedrain "k8s.io/kubectl/pkg/drain"
 	kubevirtv1 "kubevirt.io/api/core/v1"
 	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controllers/noderefutil"
+	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/faultinjection"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/operations"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/ownership"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/ssh"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/workloadcluster"
 )
@@ -52,6 +61,7 @@ type Machine struct {
 	vmiInstance    *kubevirtv1.VirtualMachineInstance
 	vmInstance     *kubevirtv1.VirtualMachine
 	dataVolumes    []*cdiv1.DataVolume
+	migrations     []kubevirtv1.VirtualMachineInstanceMigration
 
 	sshKeys            *ssh.ClusterNodeSshKeys
 	getCommandExecutor func(string, *ssh.ClusterNodeSshKeys) ssh.VMCommandExecutor
@@ -82,6 +92,9 @@ func NewMachine(ctx *context.MachineContext, client client.Client, namespace str
 		}
 	} else {
 		machine.vmiInstance = vmi
+		if machine.migrations, err = machine.getMigrations(ctx); err != nil {
+			return nil, err
+		}
 	}
 
 	// Get the top level VM object if it exists
@@ -111,11 +124,38 @@ func NewMachine(ctx *context.MachineContext, client client.Client, namespace str
 	return machine, nil
 }
 
+// getMigrations returns the migrations of the namespace of the VM, or none if the credentials of the infra cluster
+// do not allow to list them.
+func (m *Machine) getMigrations(ctx *context.MachineContext) ([]kubevirtv1.VirtualMachineInstanceMigration, error) {
+	migrations := &kubevirtv1.VirtualMachineInstanceMigrationList{}
+	if err := m.client.List(ctx, migrations, client.InNamespace(m.namespace)); err != nil {
+		if isUnreadable(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to list the migrations of namespace %s", m.namespace)
+	}
+	return migrations.Items, nil
+}
+
 // IsTerminal Reports back if the VM is either being requested to terminate or is terminated
 // in a way that it will never recover from.
 func (m *Machine) IsTerminal() (bool, string, error) {
-	if m.vmInstance == nil || m.vmiInstance == nil {
-		// vm/vmi hasn't been created yet
+	if m.vmInstance == nil {
+		// vm hasn't been created yet
+		return false, "", nil
+	}
+
+	// The VM is stopped, or about to be started again, because its cluster is hibernated
+	if IsHibernated(m.vmInstance) {
+		return false, "", nil
+	}
+
+	if failed, _, message := m.startFailure(); failed {
+		return true, message, nil
+	}
+
+	if m.vmiInstance == nil {
+		// vmi hasn't been created yet
 		return false, "", nil
 	}
 
@@ -172,6 +212,47 @@ func (m *Machine) IsTerminal() (bool, string, error) {
 	return false, "", nil
 }
 
+// FailureReason returns the failure reason matching the terminal state reported by IsTerminal.
+func (m *Machine) FailureReason() capierrors.MachineStatusError {
+	if failed, reason, _ := m.startFailure(); failed {
+		return reason
+	}
+	return capierrors.UpdateMachineError
+}
+
+// startFailure reports if the VM can never start, because the import of the image of one of its DataVolumes
+// failed or because its pod exceeds a quota, with the matching failure reason and message.
+func (m *Machine) startFailure() (bool, capierrors.MachineStatusError, string) {
+	for _, dv := range m.dataVolumes {
+		if dv.Status.Phase == cdiv1.Failed {
+			return true, capierrors.CreateMachineError, fmt.Sprintf("DataVolume %s failed to import its image", dv.Name)
+		}
+	}
+
+	cond := m.getVMCondition(kubevirtv1.VirtualMachineFailure)
+	if cond != nil && cond.Status == corev1.ConditionTrue && isQuotaExceeded(cond.Message) {
+		return true, capierrors.InsufficientResourcesMachineError, fmt.Sprintf("VM cannot start: %s", cond.Message)
+	}
+
+	return false, "", ""
+}
+
+// CreateFailureReason returns the failure reason of a VM creation error that retrying cannot fix, such as an
+// invalid VM template or an exceeded quota, and false for any other error.
+func CreateFailureReason(err error) (capierrors.MachineStatusError, bool) {
+	switch {
+	case apierrors.IsInvalid(err), apierrors.IsBadRequest(err):
+		return capierrors.CreateMachineError, true
+	case apierrors.IsForbidden(err) && isQuotaExceeded(err.Error()):
+		return capierrors.InsufficientResourcesMachineError, true
+	}
+	return "", false
+}
+
+func isQuotaExceeded(message string) bool {
+	return strings.Contains(message, "exceeded quota")
+}
+
 // Exists checks if the VM has been provisioned already.
 func (m *Machine) Exists() bool {
 	return m.vmInstance != nil
@@ -180,23 +261,14 @@ func (m *Machine) Exists() bool {
 // Create creates a new VM for this machine.
 func (m *Machine) Create(ctx gocontext.Context) error {
 	m.machineContext.Logger.Info(fmt.Sprintf("Creating VM with role '%s'...", nodeRole(m.machineContext)))
+	if faultinjection.Injected(m.machineContext.KubevirtCluster, faultinjection.VMStartFailure) {
+		return faultinjection.Error(faultinjection.VMStartFailure)
+	}
 
 	virtualMachine := newVirtualMachineFromKubevirtMachine(m.machineContext, m.namespace)
 
 	mutateFn := func() (err error) {
-		if virtualMachine.Labels == nil {
-			virtualMachine.Labels = map[string]string{}
-		}
-		if virtualMachine.Spec.Template.ObjectMeta.Labels == nil {
-			virtualMachine.Spec.Template.ObjectMeta.Labels = map[string]string{}
-		}
-		virtualMachine.Labels[clusterv1.ClusterNameLabel] = m.machineContext.Cluster.Name
-
-		virtualMachine.Labels[infrav1.KubevirtMachineNameLabel] = m.machineContext.KubevirtMachine.Name
-		virtualMachine.Labels[infrav1.KubevirtMachineNamespaceLabel] = m.machineContext.KubevirtMachine.Namespace
-
-		virtualMachine.Spec.Template.ObjectMeta.Labels[infrav1.KubevirtMachineNameLabel] = m.machineContext.KubevirtMachine.Name
-		virtualMachine.Spec.Template.ObjectMeta.Labels[infrav1.KubevirtMachineNamespaceLabel] = m.machineContext.KubevirtMachine.Namespace
+		m.setOwnerLabels(virtualMachine)
 		return nil
 	}
 	if _, err := controllerutil.CreateOrUpdate(ctx, m.client, virtualMachine, mutateFn); err != nil {
@@ -206,6 +278,86 @@ func (m *Machine) Create(ctx gocontext.Context) error {
 	return nil
 }
 
+// setOwnerLabels sets the labels linking the VM, and its VMIs, to the machine, and the owner annotation verified
+// before deleting the VM.
+func (m *Machine) setOwnerLabels(virtualMachine *kubevirtv1.VirtualMachine) {
+	if virtualMachine.Labels == nil {
+		virtualMachine.Labels = map[string]string{}
+	}
+	for key, value := range m.ownerLabels() {
+		virtualMachine.Labels[key] = value
+	}
+	ownership.SetOwner(virtualMachine, ownership.MachineOwner(m.machineContext.KubevirtMachine))
+
+	if virtualMachine.Spec.Template == nil {
+		return
+	}
+	if virtualMachine.Spec.Template.ObjectMeta.Labels == nil {
+		virtualMachine.Spec.Template.ObjectMeta.Labels = map[string]string{}
+	}
+	virtualMachine.Spec.Template.ObjectMeta.Labels[infrav1.KubevirtMachineNameLabel] = m.machineContext.KubevirtMachine.Name
+	virtualMachine.Spec.Template.ObjectMeta.Labels[infrav1.KubevirtMachineNamespaceLabel] = m.machineContext.KubevirtMachine.Namespace
+}
+
+// ownerLabels returns the labels of the VM of the machine. The cluster is unknown when deleting the machine after
+// its cluster, and it is then taken from the labels of the machine if possible.
+func (m *Machine) ownerLabels() map[string]string {
+	labels := map[string]string{
+		infrav1.KubevirtMachineNameLabel:      m.machineContext.KubevirtMachine.Name,
+		infrav1.KubevirtMachineNamespaceLabel: m.machineContext.KubevirtMachine.Namespace,
+	}
+	if m.machineContext.Cluster != nil {
+		labels[clusterv1.ClusterNameLabel] = m.machineContext.Cluster.Name
+	} else if clusterName, found := m.machineContext.KubevirtMachine.Labels[clusterv1.ClusterNameLabel]; found {
+		labels[clusterv1.ClusterNameLabel] = clusterName
+	}
+	return labels
+}
+
+// Adopt claims an existing VM named after the machine that is not labelled for it, which happens when the
+// KubevirtMachine was recreated, or moved, without its VM. The VM gets the labels of the machine, and its
+// cloud-init volume is pointed to the current bootstrap data, instead of creating another VM. Adopt returns
+// true if the VM was adopted, and an error if the VM belongs to another KubevirtMachine or cluster, or was not
+// created by the provider.
+func (m *Machine) Adopt(ctx gocontext.Context) (bool, error) {
+	if m.vmInstance == nil {
+		return false, nil
+	}
+
+	kubevirtMachine := m.machineContext.KubevirtMachine
+	labels := m.vmInstance.Labels
+	if labels[infrav1.KubevirtMachineNameLabel] == kubevirtMachine.Name && labels[infrav1.KubevirtMachineNamespaceLabel] == kubevirtMachine.Namespace {
+		return false, nil
+	}
+	if name, found := labels[infrav1.KubevirtMachineNameLabel]; found {
+		return false, errors.Errorf("VM %s/%s belongs to KubevirtMachine %s/%s", m.vmInstance.Namespace, m.vmInstance.Name, labels[infrav1.KubevirtMachineNamespaceLabel], name)
+	}
+	// The VMs created by the provider carry the name of their cluster, unlike the VMs of users merely named alike
+	clusterName, found := labels[clusterv1.ClusterNameLabel]
+	if !found {
+		return false, errors.Errorf("VM %s/%s was not created for a cluster", m.vmInstance.Namespace, m.vmInstance.Name)
+	}
+	if clusterName != m.machineContext.Cluster.Name {
+		return false, errors.Errorf("VM %s/%s belongs to cluster %s", m.vmInstance.Namespace, m.vmInstance.Name, clusterName)
+	}
+	if owner, found := m.vmInstance.Annotations[infrav1.InfraOwnerAnnotation]; found && owner != ownership.MachineOwner(kubevirtMachine) {
+		return false, errors.Errorf("VM %s/%s belongs to %s", m.vmInstance.Namespace, m.vmInstance.Name, owner)
+	}
+
+	vm := m.vmInstance.DeepCopy()
+	m.setOwnerLabels(vm)
+	if vm.Spec.Template != nil {
+		setBootstrapData(vm.Spec.Template, UserDataSecretName(m.machineContext), bootstrapDataHash(m.machineContext))
+	}
+
+	if err := m.client.Patch(ctx, vm, client.MergeFrom(m.vmInstance)); err != nil {
+		return false, errors.Wrapf(err, "failed to adopt VM %s/%s", vm.Namespace, vm.Name)
+	}
+	m.vmInstance = vm
+
+	return true, nil
+}
+
 // Returns if VMI has ready condition or not.
 func (m *Machine) hasReadyCondition() bool {
 
@@ -223,15 +375,96 @@ func (m *Machine) hasReadyCondition() bool {
 	return false
 }
 
-// Address returns the IP address of the VM.
+// Address returns the internal IP address of the VM, selected by the internalAddress rule of the machine.
 func (m *Machine) Address() string {
-	if m.vmiInstance != nil && len(m.vmiInstance.Status.Interfaces) > 0 {
+	return m.selectAddress(m.machineContext.KubevirtMachine.Spec.InternalAddress)
+}
+
+// ExternalAddress returns the external IP address of the VM, selected by the externalAddress rule of the
+// machine, or the internal IP address if there is no such rule.
+func (m *Machine) ExternalAddress() string {
+	rule := m.machineContext.KubevirtMachine.Spec.ExternalAddress
+	if rule == nil {
+		return m.Address()
+	}
+	return m.selectAddress(rule)
+}
+
+// selectAddress returns the first address reported by the VMI matching the rule, or an empty string if none does.
+func (m *Machine) selectAddress(rule *infrav1.NetworkAddressRule) string {
+	if m.vmiInstance == nil || len(m.vmiInstance.Status.Interfaces) == 0 {
+		return ""
+	}
+	if rule == nil {
 		return m.vmiInstance.Status.Interfaces[0].IP
 	}
 
+	var ipNet *net.IPNet
+	if rule.CIDR != "" {
+		var err error
+		if _, ipNet, err = net.ParseCIDR(rule.CIDR); err != nil {
+			m.machineContext.Logger.Error(err, "invalid address rule CIDR", "cidr", rule.CIDR)
+			return ""
+		}
+	}
+
+	for _, iface := range m.vmiInstance.Status.Interfaces {
+		if rule.Network != "" && iface.Name != rule.Network {
+			continue
+		}
+		ips := iface.IPs
+		if len(ips) == 0 && iface.IP != "" {
+			ips = []string{iface.IP}
+		}
+		for _, ip := range ips {
+			if ipNet == nil || ipNet.Contains(net.ParseIP(ip)) {
+				return ip
+			}
+		}
+	}
+
 	return ""
 }
 
+// VMIStatus returns the state of the VMI, or nil if the VMI does not exist.
+func (m *Machine) VMIStatus() *infrav1.VirtualMachineInstanceInfo {
+	if m.vmiInstance == nil {
+		return nil
+	}
+
+	info := &infrav1.VirtualMachineInstanceInfo{
+		Phase:    m.vmiInstance.Status.Phase,
+		NodeName: m.vmiInstance.Status.NodeName,
+	}
+
+	if migration := m.vmiInstance.Status.MigrationState; migration != nil && !migration.Completed && !migration.Failed {
+		info.MigrationTargetNode = migration.TargetNode
+	}
+	info.Migration = activeMigration(m.vmiInstance, m.migrations)
+
+	for _, cond := range m.vmiInstance.Status.Conditions {
+		if cond.Type == kubevirtv1.VirtualMachineInstanceAgentConnected && cond.Status == corev1.ConditionTrue {
+			info.AgentConnected = true
+		}
+	}
+
+	for _, transition := range m.vmiInstance.Status.PhaseTransitionTimestamps {
+		if transition.Phase == kubevirtv1.Running {
+			info.RunningTime = transition.PhaseTransitionTimestamp.DeepCopy()
+		}
+	}
+
+	if guestOS := m.vmiInstance.Status.GuestOSInfo; guestOS.Name != "" {
+		info.GuestOS = &infrav1.GuestOSInfo{
+			Name:          guestOS.Name,
+			Version:       guestOS.VersionID,
+			KernelRelease: guestOS.KernelRelease,
+		}
+	}
+
+	return info
+}
+
 // IsReady checks if the VM is ready
 func (m *Machine) IsReady() bool {
 	return m.hasReadyCondition()
@@ -260,6 +493,10 @@ func (m *Machine) IsLiveMigratable() (bool, string, string, error) {
 const (
 	defaultCondReason  = "VMNotReady"
 	defaultCondMessage = "VM is not ready"
+
+	// DVWaitingForFirstConsumerReason documents a DataVolume of a storage class binding on first consumer, whose
+	// volume waits for the VM to be scheduled to be bound and populated in the zone of its node.
+	DVWaitingForFirstConsumerReason = "DVWaitingForFirstConsumer"
 )
 
 func (m *Machine) GetVMNotReadyReason() (reason string, message string) {
@@ -302,6 +539,8 @@ func (m *Machine) getDVNotProvisionedReason(dv *cdiv1.DataVolume) (string, strin
 		return "DVPending", msg, true
 	case cdiv1.Failed:
 		return "DVFailed", msg, true
+	case cdiv1.WaitForFirstConsumer, cdiv1.PendingPopulation:
+		return DVWaitingForFirstConsumerReason, fmt.Sprintf("DataVolume %s waits for the VM to be scheduled to bind its volume in the zone of its node", dv.Name), true
 	default:
 		reason := "DVNotReady"
 		for _, dvCond := range dv.Status.Conditions {
@@ -403,8 +642,11 @@ func (m *Machine) Delete() error {
 		}
 		return errors.Wrapf(err, "failed to retrieve VM to delete")
 	}
+	if err := ownership.VerifyOwner(vm, ownership.MachineOwner(m.machineContext.KubevirtMachine), m.ownerLabels()); err != nil {
+		return err
+	}
 
-	if err := m.client.Delete(gocontext.Background(), vm); err != nil {
+	if err := m.client.Delete(m.machineContext.Context, vm, client.Preconditions{UID: &vm.UID}); err != nil {
 		return errors.Wrapf(err, "failed to delete VM")
 	}
 
@@ -458,9 +700,11 @@ const removeGracePeriodAnnotationPatch = `[{"op": "remove", "path": "/metadata/a
 func (m *Machine) removeGracePeriodAnnotation() error {
 	patch := client.RawPatch(types.JSONPatchType, []byte(removeGracePeriodAnnotationPatch))
 
-	if err := m.client.Patch(m.machineContext, m.machineContext.KubevirtMachine, patch); err != nil {
+	// The copy is patched, not to overwrite the status of the machine changed by the reconcile, e.g. its drain
+	if err := m.client.Patch(m.machineContext, m.machineContext.KubevirtMachine.DeepCopy(), patch); err != nil {
 		return fmt.Errorf("failed to remove the %s annotation to the KubeVirtMachine %s; %w", infrav1.VmiDeletionGraceTime, m.machineContext.KubevirtMachine.Name, err)
 	}
+	delete(m.machineContext.KubevirtMachine.Annotations, infrav1.VmiDeletionGraceTime)
 
 	return nil
 }
@@ -542,49 +786,93 @@ func (m *Machine) drainNode(wrkldClstr workloadcluster.WorkloadCluster) (time.Du
 		return 0, fmt.Errorf("unable to get node %q: %w", nodeName, err)
 	}
 
+	return DrainNode(m.machineContext, m.machineContext.Logger, m.machineContext.Operations, m.machineContext.KubevirtMachine, kubeClient, node, m.machineContext.ReadOnly)
+}
+
+// DrainOperation is the type of the operations draining the Nodes of the machines.
+const DrainOperation = "Drain"
+
+// syncDrainTimeout is the time given to the pods to be evicted when the drain holds the reconcile. The eviction of
+// the remaining pods is retried the next time the machine gets reconciled, to allow other machines to be reconciled.
+const syncDrainTimeout = 20 * time.Second
+
+// cordonError is the failure of a drain to cordon the node.
+type cordonError struct {
+	error
+}
+
+// DrainNode cordons a node of a workload cluster and evicts its pods, as the drain operation of the machine run
+// in the background by the tracker, and records the drain in the status of the machine. It returns a non-zero
+// duration while the drain runs, or when it did not complete and has to be retried. A dry-run drain only sends
+// server-side dry-runs, and does not wait for the eviction of the pods.
+func DrainNode(ctx gocontext.Context, logger logr.Logger, tracker *operations.Tracker, kubevirtMachine *infrav1.KubevirtMachine, kubeClient kubernetes.Interface, node *corev1.Node, dryRun bool) (time.Duration, error) {
+	// A drain run in the background waits for the eviction of the pods until the timeout of the operation
+	timeout := time.Duration(0)
+	if tracker == nil {
+		timeout = syncDrainTimeout
+	}
+	operation := tracker.Poll(ctx, kubevirtMachine, DrainOperation, func(ctx gocontext.Context) (interface{}, error) {
+		return nil, drainNode(ctx, logger, kubeClient, node, timeout, dryRun)
+	})
+	operations.SetRecord(&kubevirtMachine.Status.Operations, operation)
+
+	var cordonErr cordonError
+	switch {
+	case !operation.Done():
+		logger.Info("Waiting for the drain of the node...", "node name", node.Name)
+		return operations.PollInterval, nil
+	case errors.As(operation.Err, &cordonErr):
+		// Machine will be re-reconciled after a cordon failure.
+		return 0, operation.Err
+	case operation.Err != nil:
+		// Machine will be re-reconciled after a drain failure.
+		logger.Error(operation.Err, "Drain failed, retry in a second", "node name", node.Name)
+		return time.Second, nil
+	}
+
+	logger.Info("Drain successful", "node name", node.Name)
+	return 0, nil
+}
+
+func drainNode(ctx gocontext.Context, logger logr.Logger, kubeClient kubernetes.Interface, node *corev1.Node, timeout time.Duration, dryRun bool) error {
+	nodeName := node.Name
 	drainer := &kubedrain.Helper{
 		Client:              kubeClient,
-		Ctx:                 m.machineContext,
+		Ctx:                 ctx,
 		Force:               true,
 		IgnoreAllDaemonSets: true,
 		DeleteEmptyDirData:  true,
 		GracePeriodSeconds:  -1,
-		// If a pod is not evicted in 20 seconds, retry the eviction next time the
-		// machine gets reconciled again (to allow other machines to be reconciled).
-		Timeout: 20 * time.Second,
+		Timeout:             timeout,
 		OnPodDeletedOrEvicted: func(pod *corev1.Pod, usingEviction bool) {
 			verbStr := "Deleted"
 			if usingEviction {
 				verbStr = "Evicted"
 			}
-			m.machineContext.Logger.Info(fmt.Sprintf("%s pod from Node", verbStr),
+			logger.Info(fmt.Sprintf("%s pod from Node", verbStr),
 				"pod", fmt.Sprintf("%s/%s", pod.Name, pod.Namespace))
 		},
-		Out: writer{m.machineContext.Logger.Info},
+		Out: writer{logger.Info},
 		ErrOut: writer{func(msg string, keysAndValues ...interface{}) {
-			m.machineContext.Logger.Error(nil, msg, keysAndValues...)
+			logger.Error(nil, msg, keysAndValues...)
 		}},
 	}
 
+	if dryRun {
+		drainer.DryRunStrategy = cmdutil.DryRunServer
+	}
+
 	if noderefutil.IsNodeUnreachable(node) {
 		// When the node is unreachable and some pods are not evicted for as long as this timeout, we ignore them.
 		drainer.SkipWaitForDeleteTimeoutSeconds = 60 * 5 // 5 minutes
 	}
 
-	if err = kubedrain.RunCordonOrUncordon(drainer, node, true); err != nil {
-		// Machine will be re-reconciled after a cordon failure.
-		m.machineContext.Logger.Error(err, "Cordon failed")
-		return 0, errors.Errorf("unable to cordon node %s: %v", nodeName, err)
+	if err := kubedrain.RunCordonOrUncordon(drainer, node, true); err != nil {
+		logger.Error(err, "Cordon failed")
+		return cordonError{errors.Errorf("unable to cordon node %s: %v", nodeName, err)}
 	}
 
-	if err = kubedrain.RunNodeDrain(drainer, node.Name); err != nil {
-		// Machine will be re-reconciled after a drain failure.
-		m.machineContext.Logger.Error(err, "Drain failed, retry in a second", "node name", nodeName)
-		return time.Second, nil
-	}
-
-	m.machineContext.Logger.Info("Drain successful", "node name", nodeName)
-	return 0, nil
+	return kubedrain.RunNodeDrain(drainer, node.Name)
 }
 
 // writer implements io.Writer interface as a pass-through for klog.
@@ -7,8 +7,10 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/ssh"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/workloadcluster"
@@ -23,12 +25,25 @@ type MachineInterface interface {
 	Delete() error
 	// Exists checks if the VM has been provisioned already.
 	Exists() bool
+	// Adopt claims an existing VM that is not labelled for this machine, and reports if it did.
+	Adopt(ctx gocontext.Context) (bool, error)
+	// RedeliverBootstrapData updates the VM, and restarts its VMI if its guest did not start yet, when the
+	// bootstrap data changed since the VM was created, and reports if it did.
+	RedeliverBootstrapData(ctx gocontext.Context) (bool, error)
+	// Drift returns the fields of the VM changed in the infra cluster, compared with the VM rendered from the machine.
+	Drift() []string
+	// RevertDrift patches the drifted fields of the VM back to their rendered values, and returns them.
+	RevertDrift(ctx gocontext.Context) ([]string, error)
 	// IsReady checks if the VM is ready
 	IsReady() bool
+	// VMIStatus returns the state of the VMI, or nil if the VMI does not exist
+	VMIStatus() *infrav1.VirtualMachineInstanceInfo
 	// IsLiveMigratable reports back the live-migratability state of the VM: Status, Reason and Message
 	IsLiveMigratable() (bool, string, string, error)
-	// Address returns the IP address of the VM.
+	// Address returns the internal IP address of the VM.
 	Address() string
+	// ExternalAddress returns the external IP address of the VM.
+	ExternalAddress() string
 	// SupportsCheckingIsBootstrapped checks if we have a method of checking
 	// that this bootstrapper has completed.
 	SupportsCheckingIsBootstrapped() bool
@@ -38,6 +53,8 @@ type MachineInterface interface {
 	GenerateProviderID() (string, error)
 	// IsTerminal reports back if a VM is in a permanent terminal state
 	IsTerminal() (bool, string, error)
+	// FailureReason returns the failure reason matching the terminal state reported by IsTerminal
+	FailureReason() capierrors.MachineStatusError
 
 	DrainNodeIfNeeded(workloadcluster.WorkloadCluster) (time.Duration, error)
 
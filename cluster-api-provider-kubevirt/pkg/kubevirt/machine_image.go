@@ -0,0 +1,300 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+const (
+	// defaultMachineImageArchitecture is the architecture of the images not specifying any.
+	defaultMachineImageArchitecture = "amd64"
+
+	// immediateBindingAnnotation makes CDI import a DataVolume with a WaitForFirstConsumer storage class without
+	// waiting for a VM to consume it, which never happens for the disks of the images.
+	immediateBindingAnnotation = "cdi.kubevirt.io/storage.bind.immediateRequested"
+
+	// cloneServiceAccountName is the service account KubeVirt authorizes the clones of the DataVolumeTemplates of
+	// the VMs for, as the VMs of the machines do not mount any.
+	cloneServiceAccountName = "default"
+)
+
+// MachineImageArchitecture returns the architecture of the image itself, as opposed to the ones of its variants.
+func MachineImageArchitecture(image *infrav1.KubevirtMachineImage) string {
+	if image.Spec.Architecture == "" {
+		return defaultMachineImageArchitecture
+	}
+	return image.Spec.Architecture
+}
+
+// MachineImageVariants returns the variants of the image imported in the infra clusters, as the architecture
+// recorded in their imports: an empty one for the image itself, followed by the ones of its variants.
+func MachineImageVariants(image *infrav1.KubevirtMachineImage) []string {
+	variants := []string{""}
+	for _, variant := range image.Spec.Variants {
+		if variant.Architecture != MachineImageArchitecture(image) {
+			variants = append(variants, variant.Architecture)
+		}
+	}
+	return variants
+}
+
+// MachineImageVariantFor returns the variant of the image booted by the machines of the given architecture, empty
+// for the image itself, or false if the image is not built for it. An empty architecture boots the image itself.
+func MachineImageVariantFor(image *infrav1.KubevirtMachineImage, architecture string) (string, bool) {
+	if architecture == "" || architecture == MachineImageArchitecture(image) {
+		return "", true
+	}
+	if machineImageVariant(image, architecture) != nil {
+		return architecture, true
+	}
+	return "", false
+}
+
+// machineImageVariant returns the variant of the image for the architecture, or nil if it has none.
+func machineImageVariant(image *infrav1.KubevirtMachineImage, architecture string) *infrav1.MachineImageVariant {
+	for i := range image.Spec.Variants {
+		if image.Spec.Variants[i].Architecture == architecture {
+			return &image.Spec.Variants[i]
+		}
+	}
+	return nil
+}
+
+// machineImageBuild returns the source and the checksum of a variant of the image, empty for the image itself.
+func machineImageBuild(image *infrav1.KubevirtMachineImage, variant string) (infrav1.MachineImageSource, string) {
+	if v := machineImageVariant(image, variant); v != nil {
+		return v.Source, v.Checksum
+	}
+	return image.Spec.Source, image.Spec.Checksum
+}
+
+// MachineImageDataVolumeName returns the name of the DataVolume importing a variant of the image, empty for the
+// image itself, in the infra clusters. It includes the namespace of the image, as the images of several namespaces
+// may be imported in the same one.
+func MachineImageDataVolumeName(image *infrav1.KubevirtMachineImage, variant string) string {
+	if variant == "" {
+		return fmt.Sprintf("%s-%s-image", image.Namespace, image.Name)
+	}
+	return fmt.Sprintf("%s-%s-%s-image", image.Namespace, image.Name, variant)
+}
+
+// MachineImageSource returns the source a variant of the image, empty for the image itself, is imported from,
+// including its checksum: registry images are pulled by digest, while the checksum of the http images is appended
+// as a URL fragment identifying their version.
+func MachineImageSource(image *infrav1.KubevirtMachineImage, variant string) string {
+	source, checksum := machineImageBuild(image, variant)
+	if source.Registry == "" {
+		if checksum == "" {
+			return source.URL
+		}
+		return source.URL + "#" + checksum
+	}
+
+	registry := source.Registry
+	if checksum == "" || strings.Contains(registry, "@") {
+		return registry
+	}
+	// Replace the tag, if any, of the image by its digest
+	if i := strings.LastIndex(registry, ":"); i > strings.LastIndex(registry, "/") {
+		registry = registry[:i]
+	}
+	return registry + "@" + checksum
+}
+
+// NewMachineImageDataVolume returns the DataVolume importing a variant of the image, empty for the image itself, in
+// a namespace of an infra cluster.
+func NewMachineImageDataVolume(image *infrav1.KubevirtMachineImage, variant, namespace string) *cdiv1.DataVolume {
+	build, _ := machineImageBuild(image, variant)
+	source := MachineImageSource(image, variant)
+	dataVolume := &cdiv1.DataVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      MachineImageDataVolumeName(image, variant),
+			Namespace: namespace,
+			Annotations: map[string]string{
+				infrav1.MachineImageSourceAnnotation: source,
+				immediateBindingAnnotation:           "true",
+			},
+		},
+		Spec: cdiv1.DataVolumeSpec{
+			Storage: &cdiv1.StorageSpec{
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: image.Spec.Size},
+				},
+				StorageClassName: image.Spec.StorageClassName,
+			},
+		},
+	}
+
+	if build.Registry != "" {
+		dataVolume.Spec.Source = &cdiv1.DataVolumeSource{Registry: &cdiv1.DataVolumeSourceRegistry{URL: &source}}
+	} else {
+		dataVolume.Spec.Source = &cdiv1.DataVolumeSource{HTTP: &cdiv1.DataVolumeSourceHTTP{URL: build.URL}}
+	}
+	return dataVolume
+}
+
+// MachineImageSharedWith returns true if the KubevirtMachines of the namespace may boot the image.
+func MachineImageSharedWith(image *infrav1.KubevirtMachineImage, namespace string) bool {
+	if namespace == image.Namespace {
+		return true
+	}
+	for _, shared := range image.Spec.SharedWith {
+		if shared == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// MachineImageInfraClusterSecretRef returns the reference to the kubeconfig of the infra cluster of a machine
+// booting the image, as recorded in the imports of the image: the references without namespace of the machines
+// of the namespaces the image is shared with are resolved in the namespace of the machine.
+func MachineImageInfraClusterSecretRef(image *infrav1.KubevirtMachineImage, kubevirtMachine *infrav1.KubevirtMachine) *corev1.ObjectReference {
+	ref := kubevirtMachine.Spec.InfraClusterSecretRef
+	if ref == nil || ref.Namespace != "" || kubevirtMachine.Namespace == image.Namespace {
+		return ref
+	}
+	resolved := ref.DeepCopy()
+	resolved.Namespace = kubevirtMachine.Namespace
+	return resolved
+}
+
+// MachineImageCloneGrantName returns the name of the Role and the RoleBinding granting the VMs of other namespaces
+// to clone the image imported in a namespace of the infra cluster.
+func MachineImageCloneGrantName(image *infrav1.KubevirtMachineImage) string {
+	return MachineImageDataVolumeName(image, "") + "-clone"
+}
+
+// NewMachineImageCloneRole returns the Role allowing to clone the variants of the image imported in a namespace, as
+// checked by CDI for the clones across namespaces.
+func NewMachineImageCloneRole(image *infrav1.KubevirtMachineImage, namespace string) *rbacv1.Role {
+	var dataVolumeNames []string
+	for _, variant := range MachineImageVariants(image) {
+		dataVolumeNames = append(dataVolumeNames, MachineImageDataVolumeName(image, variant))
+	}
+	return &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      MachineImageCloneGrantName(image),
+			Namespace: namespace,
+		},
+		Rules: []rbacv1.PolicyRule{{
+			APIGroups:     []string{cdiv1.SchemeGroupVersion.Group},
+			Resources:     []string{"datavolumes/source"},
+			ResourceNames: dataVolumeNames,
+			Verbs:         []string{"create"},
+		}},
+	}
+}
+
+// NewMachineImageCloneRoleBinding returns the RoleBinding granting the VMs of the given namespaces to clone the
+// image imported in a namespace.
+func NewMachineImageCloneRoleBinding(image *infrav1.KubevirtMachineImage, namespace string, grantees []string) *rbacv1.RoleBinding {
+	roleBinding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      MachineImageCloneGrantName(image),
+			Namespace: namespace,
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     MachineImageCloneGrantName(image),
+		},
+	}
+	for _, grantee := range grantees {
+		roleBinding.Subjects = append(roleBinding.Subjects, rbacv1.Subject{
+			Kind:      rbacv1.ServiceAccountKind,
+			Namespace: grantee,
+			Name:      cloneServiceAccountName,
+		})
+	}
+	return roleBinding
+}
+
+// FindMachineImageImport returns the import of a variant of the image, empty for the image itself, in the infra
+// cluster of the given kubeconfig, if any.
+func FindMachineImageImport(image *infrav1.KubevirtMachineImage, infraClusterSecretRef *corev1.ObjectReference, variant string) *infrav1.MachineImageImport {
+	for i := range image.Status.Imports {
+		if image.Status.Imports[i].Architecture == variant && SameInfraCluster(image.Status.Imports[i].InfraClusterSecretRef, infraClusterSecretRef) {
+			return &image.Status.Imports[i]
+		}
+	}
+	return nil
+}
+
+// SameInfraCluster returns true if both references designate the same kubeconfig of an infra cluster, or both the
+// management cluster.
+func SameInfraCluster(a, b *corev1.ObjectReference) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Namespace == b.Namespace && a.Name == b.Name
+}
+
+// useMachineImage clones the variant of the image of the machine for its architecture, resolved in the machine
+// context, into the DataVolumeTemplate of the VM it references.
+func useMachineImage(vm *kubevirtv1.VirtualMachine, ctx *context.MachineContext) {
+	reference := ctx.KubevirtMachine.Spec.Image
+	if reference == nil || ctx.MachineImage == nil {
+		return
+	}
+	variant, found := MachineImageVariantFor(ctx.MachineImage, ctx.KubevirtMachine.Spec.Architecture)
+	if !found {
+		return
+	}
+	imageImport := FindMachineImageImport(ctx.MachineImage, MachineImageInfraClusterSecretRef(ctx.MachineImage, ctx.KubevirtMachine), variant)
+	if imageImport == nil {
+		return
+	}
+
+	for i := range vm.Spec.DataVolumeTemplates {
+		template := &vm.Spec.DataVolumeTemplates[i]
+		if reference.DataVolumeTemplate != "" && template.Name != reference.DataVolumeTemplate {
+			continue
+		}
+
+		template.Spec.Source = &cdiv1.DataVolumeSource{
+			PVC: &cdiv1.DataVolumeSourcePVC{Namespace: imageImport.Namespace, Name: imageImport.DataVolumeName},
+		}
+		template.Spec.SourceRef = nil
+		if template.Spec.PVC == nil {
+			if template.Spec.Storage == nil {
+				template.Spec.Storage = &cdiv1.StorageSpec{}
+			}
+			if template.Spec.Storage.StorageClassName == nil {
+				template.Spec.Storage.StorageClassName = ctx.MachineImage.Spec.StorageClassName
+			}
+			if _, ok := template.Spec.Storage.Resources.Requests[corev1.ResourceStorage]; !ok {
+				if template.Spec.Storage.Resources.Requests == nil {
+					template.Spec.Storage.Resources.Requests = corev1.ResourceList{}
+				}
+				template.Spec.Storage.Resources.Requests[corev1.ResourceStorage] = ctx.MachineImage.Spec.Size
+			}
+		}
+		break
+	}
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+var _ = Describe("Machine images", func() {
+	const checksum = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	DescribeTable("should pin the source of the images to their checksum", func(source infrav1.MachineImageSource, checksum, expected string) {
+		image := &infrav1.KubevirtMachineImage{Spec: infrav1.KubevirtMachineImageSpec{Source: source, Checksum: checksum}}
+		Expect(MachineImageSource(image, "")).To(Equal(expected))
+	},
+		Entry("http", infrav1.MachineImageSource{URL: "https://images/ubuntu.qcow2"}, checksum, "https://images/ubuntu.qcow2#"+checksum),
+		Entry("http without checksum", infrav1.MachineImageSource{URL: "https://images/ubuntu.qcow2"}, "", "https://images/ubuntu.qcow2"),
+		Entry("registry", infrav1.MachineImageSource{Registry: "docker://registry:5000/ubuntu:22.04"}, checksum, "docker://registry:5000/ubuntu@"+checksum),
+		Entry("registry without tag", infrav1.MachineImageSource{Registry: "docker://registry:5000/ubuntu"}, checksum, "docker://registry:5000/ubuntu@"+checksum),
+		Entry("registry with digest", infrav1.MachineImageSource{Registry: "docker://ubuntu@sha256:abc"}, checksum, "docker://ubuntu@sha256:abc"),
+	)
+
+	It("should import the images with their size and storage class", func() {
+		image := &infrav1.KubevirtMachineImage{
+			ObjectMeta: metav1.ObjectMeta{Name: "ubuntu", Namespace: "capi"},
+			Spec: infrav1.KubevirtMachineImageSpec{
+				Source:           infrav1.MachineImageSource{Registry: "docker://ubuntu:22.04"},
+				Size:             resource.MustParse("10Gi"),
+				StorageClassName: ptr.To("fast"),
+			},
+		}
+
+		dataVolume := NewMachineImageDataVolume(image, "", "infra")
+
+		Expect(dataVolume.Namespace).To(Equal("infra"))
+		Expect(dataVolume.Name).To(Equal("capi-ubuntu-image"))
+		Expect(dataVolume.Annotations).To(HaveKeyWithValue(infrav1.MachineImageSourceAnnotation, "docker://ubuntu:22.04"))
+		Expect(dataVolume.Spec.Source.Registry.URL).To(HaveValue(Equal("docker://ubuntu:22.04")))
+		Expect(dataVolume.Spec.Storage.StorageClassName).To(HaveValue(Equal("fast")))
+		Expect(dataVolume.Spec.Storage.Resources.Requests).To(HaveKeyWithValue(corev1.ResourceStorage, resource.MustParse("10Gi")))
+	})
+
+	It("should import the variants of the images for the other architectures", func() {
+		image := &infrav1.KubevirtMachineImage{
+			ObjectMeta: metav1.ObjectMeta{Name: "ubuntu", Namespace: "capi"},
+			Spec: infrav1.KubevirtMachineImageSpec{
+				Source: infrav1.MachineImageSource{URL: "https://images/ubuntu-amd64.qcow2"},
+				Variants: []infrav1.MachineImageVariant{
+					{Architecture: "arm64", Source: infrav1.MachineImageSource{URL: "https://images/ubuntu-arm64.qcow2"}, Checksum: checksum},
+				},
+				Size: resource.MustParse("10Gi"),
+			},
+		}
+
+		Expect(MachineImageVariants(image)).To(Equal([]string{"", "arm64"}))
+		for architecture, expected := range map[string]string{"": "", "amd64": "", "arm64": "arm64"} {
+			variant, found := MachineImageVariantFor(image, architecture)
+			Expect(found).To(BeTrue())
+			Expect(variant).To(Equal(expected))
+		}
+		_, found := MachineImageVariantFor(image, "s390x")
+		Expect(found).To(BeFalse())
+
+		dataVolume := NewMachineImageDataVolume(image, "arm64", "infra")
+		Expect(dataVolume.Name).To(Equal("capi-ubuntu-arm64-image"))
+		Expect(dataVolume.Annotations).To(HaveKeyWithValue(infrav1.MachineImageSourceAnnotation, "https://images/ubuntu-arm64.qcow2#"+checksum))
+		Expect(dataVolume.Spec.Source.HTTP.URL).To(Equal("https://images/ubuntu-arm64.qcow2"))
+		Expect(NewMachineImageCloneRole(image, "infra").Rules[0].ResourceNames).To(Equal([]string{"capi-ubuntu-image", "capi-ubuntu-arm64-image"}))
+	})
+
+	It("should resolve the infra clusters of the machines of the namespaces the image is shared with", func() {
+		image := &infrav1.KubevirtMachineImage{
+			ObjectMeta: metav1.ObjectMeta{Name: "ubuntu", Namespace: "images"},
+			Spec:       infrav1.KubevirtMachineImageSpec{SharedWith: []string{"tenant"}},
+		}
+		tenantMachine := &infrav1.KubevirtMachine{ObjectMeta: metav1.ObjectMeta{Namespace: "tenant"}}
+		tenantMachine.Spec.InfraClusterSecretRef = &corev1.ObjectReference{Name: "infra"}
+		imagesMachine := &infrav1.KubevirtMachine{ObjectMeta: metav1.ObjectMeta{Namespace: "images"}}
+		imagesMachine.Spec.InfraClusterSecretRef = &corev1.ObjectReference{Name: "infra"}
+
+		Expect(MachineImageSharedWith(image, "tenant")).To(BeTrue())
+		Expect(MachineImageSharedWith(image, "images")).To(BeTrue())
+		Expect(MachineImageSharedWith(image, "other")).To(BeFalse())
+		Expect(MachineImageInfraClusterSecretRef(image, tenantMachine)).To(Equal(&corev1.ObjectReference{Namespace: "tenant", Name: "infra"}))
+		Expect(MachineImageInfraClusterSecretRef(image, imagesMachine)).To(Equal(&corev1.ObjectReference{Name: "infra"}))
+		Expect(tenantMachine.Spec.InfraClusterSecretRef.Namespace).To(BeEmpty())
+	})
+
+	It("should clone the image of the machine into the disk of its VM", func() {
+		machineContext := &context.MachineContext{
+			Cluster:         cluster,
+			KubevirtCluster: kubevirtCluster,
+			Machine:         machine,
+			KubevirtMachine: kubevirtMachine.DeepCopy(),
+			MachineImage: &infrav1.KubevirtMachineImage{
+				Spec: infrav1.KubevirtMachineImageSpec{
+					Architecture:     "arm64",
+					Size:             resource.MustParse("10Gi"),
+					StorageClassName: ptr.To("fast"),
+				},
+				Status: infrav1.KubevirtMachineImageStatus{
+					Imports: []infrav1.MachineImageImport{
+						{InfraClusterSecretRef: &corev1.ObjectReference{Namespace: "capi", Name: "other"}, Namespace: "other", DataVolumeName: "other"},
+						{Namespace: "infra", DataVolumeName: "capi-ubuntu-image", Ready: true},
+					},
+				},
+			},
+		}
+		machineContext.KubevirtMachine.Spec.Image = &infrav1.MachineImageReference{Name: "ubuntu", DataVolumeTemplate: "root"}
+		machineContext.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.DataVolumeTemplates = []kubevirtv1.DataVolumeTemplateSpec{
+			{ObjectMeta: metav1.ObjectMeta{Name: "data"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "root"}},
+		}
+
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+
+		Expect(newVM.Spec.DataVolumeTemplates[0].Spec.Source).To(BeNil())
+		root := newVM.Spec.DataVolumeTemplates[1]
+		Expect(root.Name).To(Equal(kubevirtMachineName + "-root"))
+		Expect(root.Spec.Source).To(Equal(&cdiv1.DataVolumeSource{PVC: &cdiv1.DataVolumeSourcePVC{Namespace: "infra", Name: "capi-ubuntu-image"}}))
+		Expect(root.Spec.Storage.StorageClassName).To(HaveValue(Equal("fast")))
+		Expect(root.Spec.Storage.Resources.Requests).To(HaveKeyWithValue(corev1.ResourceStorage, resource.MustParse("10Gi")))
+		Expect(newVM.Spec.Template.Spec.NodeSelector).To(HaveKeyWithValue(corev1.LabelArchStable, "arm64"))
+	})
+
+	It("should clone the variant of the image for the architecture of the machine", func() {
+		machineContext := &context.MachineContext{
+			Cluster:         cluster,
+			KubevirtCluster: kubevirtCluster,
+			Machine:         machine,
+			KubevirtMachine: kubevirtMachine.DeepCopy(),
+			MachineImage: &infrav1.KubevirtMachineImage{
+				Spec: infrav1.KubevirtMachineImageSpec{
+					Variants: []infrav1.MachineImageVariant{{Architecture: "arm64"}},
+					Size:     resource.MustParse("10Gi"),
+				},
+				Status: infrav1.KubevirtMachineImageStatus{
+					Imports: []infrav1.MachineImageImport{
+						{Namespace: "infra", DataVolumeName: "capi-ubuntu-image", Ready: true},
+						{Namespace: "infra", DataVolumeName: "capi-ubuntu-arm64-image", Architecture: "arm64", Ready: true},
+					},
+				},
+			},
+		}
+		machineContext.KubevirtMachine.Spec.Architecture = "arm64"
+		machineContext.KubevirtMachine.Spec.Image = &infrav1.MachineImageReference{Name: "ubuntu"}
+		machineContext.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.DataVolumeTemplates = []kubevirtv1.DataVolumeTemplateSpec{
+			{ObjectMeta: metav1.ObjectMeta{Name: "root"}},
+		}
+
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+
+		Expect(newVM.Spec.DataVolumeTemplates[0].Spec.Source).To(Equal(&cdiv1.DataVolumeSource{PVC: &cdiv1.DataVolumeSourcePVC{Namespace: "infra", Name: "capi-ubuntu-arm64-image"}}))
+		Expect(newVM.Spec.Template.Spec.Architecture).To(Equal("arm64"))
+		Expect(newVM.Spec.Template.Spec.NodeSelector).To(HaveKeyWithValue(corev1.LabelArchStable, "arm64"))
+	})
+})
@@ -29,16 +29,21 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	k8sfake "k8s.io/client-go/kubernetes/fake"
 	k8stesting "k8s.io/client-go/testing"
 	kubevirtv1 "kubevirt.io/api/core/v1"
 	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	"sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/faultinjection"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/operations"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/ownership"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/ssh"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/workloadcluster/mock"
@@ -118,6 +123,12 @@ var _ = Describe("Without KubeVirt VM running", func() {
 		Expect(externalMachine.Address()).To(Equal(""))
 	})
 
+	It("VMIStatus should return nil", func() {
+		externalMachine, err := defaultTestMachine(machineContext, namespace, fakeClient, fakeVMCommandExecutor, []byte{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(externalMachine.VMIStatus()).To(BeNil())
+	})
+
 	It("IsReady should return false", func() {
 		externalMachine, err := defaultTestMachine(machineContext, namespace, fakeClient, fakeVMCommandExecutor, []byte{})
 		Expect(err).NotTo(HaveOccurred())
@@ -191,6 +202,22 @@ var _ = Describe("Without KubeVirt VM running", func() {
 		validateVMExist(virtualMachine, fakeClient, machineContext)
 	})
 
+	It("Create should fail when VM start failures are injected", func() {
+		faultinjection.SetEnabled(true)
+		defer faultinjection.SetEnabled(false)
+
+		machineContext.KubevirtCluster = kubevirtCluster.DeepCopy()
+		machineContext.KubevirtCluster.Annotations = map[string]string{
+			v1alpha1.InjectFailuresAnnotation: string(faultinjection.VMStartFailure),
+		}
+
+		externalMachine, err := defaultTestMachine(machineContext, namespace, fakeClient, fakeVMCommandExecutor, []byte{})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(externalMachine.Create(machineContext.Context)).ToNot(Succeed())
+		validateVMNotExist(virtualMachine, fakeClient, machineContext)
+	})
+
 	It("Delete should be lenient if VM doesn't exist", func() {
 		externalMachine, err := defaultTestMachine(machineContext, namespace, fakeClient, fakeVMCommandExecutor, []byte{})
 		Expect(err).NotTo(HaveOccurred())
@@ -268,6 +295,55 @@ var _ = Describe("With KubeVirt VM running", func() {
 		Expect(externalMachine.IsReady()).To(BeTrue())
 	})
 
+	It("VMIStatus should mirror the VMI", func() {
+		virtualMachineInstance.Status.Phase = kubevirtv1.Running
+		virtualMachineInstance.Status.NodeName = "infra-node-1"
+		virtualMachineInstance.Status.MigrationState = &kubevirtv1.VirtualMachineInstanceMigrationState{TargetNode: "infra-node-2"}
+		virtualMachineInstance.Status.GuestOSInfo = kubevirtv1.VirtualMachineInstanceGuestOSInfo{Name: "Ubuntu", VersionID: "22.04", KernelRelease: "5.15.0-91-generic"}
+		runningTime := metav1.Date(2024, time.March, 4, 10, 0, 0, 0, time.Local)
+		virtualMachineInstance.Status.PhaseTransitionTimestamps = []kubevirtv1.VirtualMachineInstancePhaseTransitionTimestamp{
+			{Phase: kubevirtv1.Scheduled, PhaseTransitionTimestamp: metav1.Date(2024, time.March, 4, 9, 59, 0, 0, time.Local)},
+			{Phase: kubevirtv1.Running, PhaseTransitionTimestamp: runningTime},
+		}
+		virtualMachineInstance.Status.Conditions = append(virtualMachineInstance.Status.Conditions, kubevirtv1.VirtualMachineInstanceCondition{
+			Type:   kubevirtv1.VirtualMachineInstanceAgentConnected,
+			Status: corev1.ConditionTrue,
+		})
+		DeferCleanup(func() {
+			virtualMachineInstance.Status.MigrationState = nil
+			virtualMachineInstance.Status.GuestOSInfo = kubevirtv1.VirtualMachineInstanceGuestOSInfo{}
+			virtualMachineInstance.Status.PhaseTransitionTimestamps = nil
+		})
+		fakeClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(virtualMachineInstance, virtualMachine).Build()
+
+		externalMachine, err := defaultTestMachine(machineContext, namespace, fakeClient, fakeVMCommandExecutor, []byte(sshKey))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(externalMachine.VMIStatus()).To(Equal(&v1alpha1.VirtualMachineInstanceInfo{
+			Phase:               kubevirtv1.Running,
+			NodeName:            "infra-node-1",
+			MigrationTargetNode: "infra-node-2",
+			AgentConnected:      true,
+			RunningTime:         &runningTime,
+			GuestOS: &v1alpha1.GuestOSInfo{
+				Name:          "Ubuntu",
+				Version:       "22.04",
+				KernelRelease: "5.15.0-91-generic",
+			},
+		}))
+	})
+
+	It("VMIStatus should not report a completed migration", func() {
+		virtualMachineInstance.Status.MigrationState = &kubevirtv1.VirtualMachineInstanceMigrationState{TargetNode: "infra-node-2", Completed: true}
+		DeferCleanup(func() { virtualMachineInstance.Status.MigrationState = nil })
+		fakeClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(virtualMachineInstance, virtualMachine).Build()
+
+		externalMachine, err := defaultTestMachine(machineContext, namespace, fakeClient, fakeVMCommandExecutor, []byte(sshKey))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(externalMachine.VMIStatus().MigrationTargetNode).To(BeEmpty())
+		Expect(externalMachine.VMIStatus().AgentConnected).To(BeFalse())
+		Expect(externalMachine.VMIStatus().GuestOS).To(BeNil())
+	})
+
 	It("default mode: IsBootstrapped should return true", func() {
 		externalMachine, err := defaultTestMachine(machineContext, namespace, fakeClient, fakeVMCommandExecutor, []byte(sshKey))
 		Expect(err).NotTo(HaveOccurred())
@@ -315,11 +391,36 @@ var _ = Describe("With KubeVirt VM running", func() {
 		externalMachine, err := defaultTestMachine(machineContext, namespace, fakeClient, fakeVMCommandExecutor, []byte{})
 		Expect(err).NotTo(HaveOccurred())
 		validateVMExist(virtualMachine, fakeClient, machineContext)
+		setVMOwner(virtualMachine, externalMachine, fakeClient)
 
 		Expect(externalMachine.Delete()).To(Succeed())
 		validateVMNotExist(virtualMachine, fakeClient, machineContext)
 	})
 
+	It("Delete should not delete a VM merely named like the machine", func() {
+		externalMachine, err := defaultTestMachine(machineContext, namespace, fakeClient, fakeVMCommandExecutor, []byte{})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = externalMachine.Delete()
+		Expect(ownership.IsNotOwned(err)).To(BeTrue())
+		validateVMExist(virtualMachine, fakeClient, machineContext)
+	})
+
+	It("Delete should not delete a VM owned by another KubevirtMachine", func() {
+		externalMachine, err := defaultTestMachine(machineContext, namespace, fakeClient, fakeVMCommandExecutor, []byte{})
+		Expect(err).NotTo(HaveOccurred())
+		setVMOwner(virtualMachine, externalMachine, fakeClient)
+
+		vm := &kubevirtv1.VirtualMachine{}
+		Expect(fakeClient.Get(gocontext.Background(), client.ObjectKeyFromObject(virtualMachine), vm)).To(Succeed())
+		vm.Annotations[v1alpha1.InfraOwnerAnnotation] = "KubevirtMachine/another-namespace/" + kubevirtMachine.Name
+		Expect(fakeClient.Update(gocontext.Background(), vm)).To(Succeed())
+
+		err = externalMachine.Delete()
+		Expect(ownership.IsNotOwned(err)).To(BeTrue())
+		validateVMExist(virtualMachine, fakeClient, machineContext)
+	})
+
 	Context("test DrainNodeIfNeeded", func() {
 		const nodeName = "control-plane1"
 
@@ -489,6 +590,49 @@ var _ = Describe("With KubeVirt VM running", func() {
 			})
 		})
 
+		When("grace not expired, drained in the background (wrap for BeforeEach)", func() {
+			BeforeEach(func() {
+				graceTime := time.Now().UTC().Add(5 * time.Minute).Format(time.RFC3339)
+				kubevirtMachine.Annotations[v1alpha1.VmiDeletionGraceTime] = graceTime
+				machineContext.Operations = operations.NewTracker(1)
+			})
+
+			It("Should poll the drain of the node", func() {
+				node := &corev1.Node{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: nodeName,
+					},
+				}
+
+				Expect(k8sfake.AddToScheme(setupRemoteScheme())).ToNot(HaveOccurred())
+				cl := k8sfake.NewSimpleClientset(node)
+
+				wlCluster.EXPECT().GenerateWorkloadClusterK8sClient(gomock.Any()).Return(cl, nil).AnyTimes()
+
+				externalMachine, err := defaultTestMachine(machineContext, namespace, fakeClient, fakeVMCommandExecutor, []byte(sshKey))
+				Expect(err).NotTo(HaveOccurred())
+
+				requeueDuration, err := externalMachine.DrainNodeIfNeeded(wlCluster)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(requeueDuration).To(Equal(operations.PollInterval))
+				Expect(kubevirtMachine.Status.Operations).To(ConsistOf(And(
+					HaveField("Type", DrainOperation),
+					HaveField("Phase", v1alpha1.RunningMachineOperationPhase),
+				)))
+
+				By("deleting the VMI once the node is drained")
+				Eventually(func() []v1alpha1.MachineOperation {
+					_, err := externalMachine.DrainNodeIfNeeded(wlCluster)
+					Expect(err).NotTo(HaveOccurred())
+					return kubevirtMachine.Status.Operations
+				}).Should(ConsistOf(HaveField("Phase", v1alpha1.SucceededMachineOperationPhase)))
+
+				vmi := &kubevirtv1.VirtualMachineInstance{}
+				err = fakeClient.Get(gocontext.Background(), client.ObjectKey{Namespace: virtualMachineInstance.Namespace, Name: virtualMachineInstance.Name}, vmi)
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			})
+		})
+
 		When("grace not expired, drain fails (wrap for BeforeEach)", func() {
 			BeforeEach(func() {
 				graceTime := time.Now().UTC().Add(5 * time.Minute).Format(time.RFC3339)
@@ -610,6 +754,49 @@ var _ = Describe("util functions", func() {
 		Expect(newVM.Spec.DataVolumeTemplates[0].ObjectMeta.Name).To(Equal(kubevirtMachineName + "-dv1"))
 		Expect(newVM.Spec.Template.Spec.Volumes[0].VolumeSource.DataVolume.Name).To(Equal(kubevirtMachineName + "-dv1"))
 	})
+
+	It("should exclude the virt-launcher pods from the service mesh of the cluster", func() {
+		machineContext.KubevirtCluster = kubevirtCluster.DeepCopy()
+		machineContext.KubevirtCluster.Spec.ServiceMesh = &v1alpha1.ServiceMeshSpec{
+			Type:           v1alpha1.LinkerdServiceMesh,
+			PodAnnotations: map[string]string{"config.linkerd.io/skip-inbound-ports": "6443"},
+		}
+
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+
+		Expect(newVM.Spec.Template.ObjectMeta.Annotations).To(HaveKeyWithValue("linkerd.io/inject", "disabled"))
+		Expect(newVM.Spec.Template.ObjectMeta.Annotations).To(HaveKeyWithValue("config.linkerd.io/skip-inbound-ports", "6443"))
+		Expect(newVM.Spec.Template.ObjectMeta.Labels).To(HaveKeyWithValue("cluster.x-k8s.io/cluster-name", cluster.Name))
+	})
+
+	It("should propagate the labels and annotations of the Cluster selected by the propagation policy", func() {
+		machineContext.Cluster = cluster.DeepCopy()
+		machineContext.Cluster.Labels = map[string]string{
+			"example.com/tenant":            "acme",
+			"tags.example.com/cost-center":  "1234",
+			"cluster.x-k8s.io/cluster-name": "other",
+			"capk.cluster.x-k8s.io/reclaim": "true",
+			"unrelated":                     "value",
+		}
+		machineContext.Cluster.Annotations = map[string]string{"example.com/owner": "team-a", "unrelated": "value"}
+		machineContext.KubevirtCluster = kubevirtCluster.DeepCopy()
+		machineContext.KubevirtCluster.Spec.MetadataPropagation = &v1alpha1.MetadataPropagationSpec{
+			Labels:      []string{"example.com/tenant", "tags.example.com/", "cluster.x-k8s.io/cluster-name", "capk.cluster.x-k8s.io/"},
+			Annotations: []string{"example.com/owner"},
+		}
+
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+
+		for _, labels := range []map[string]string{newVM.Labels, newVM.Spec.Template.ObjectMeta.Labels} {
+			Expect(labels).To(HaveKeyWithValue("example.com/tenant", "acme"))
+			Expect(labels).To(HaveKeyWithValue("tags.example.com/cost-center", "1234"))
+			Expect(labels).To(HaveKeyWithValue("cluster.x-k8s.io/cluster-name", cluster.Name))
+			Expect(labels).NotTo(HaveKey("unrelated"))
+			Expect(labels).NotTo(HaveKey("capk.cluster.x-k8s.io/reclaim"))
+		}
+		Expect(newVM.Annotations).To(Equal(map[string]string{"example.com/owner": "team-a"}))
+		Expect(kubevirtMachine.Spec.VirtualMachineTemplate.ObjectMeta.Labels).NotTo(HaveKey("example.com/tenant"))
+	})
 })
 
 var _ = Describe("With KubeVirt VM running externally", func() {
@@ -726,6 +913,7 @@ var _ = Describe("With KubeVirt VM running externally", func() {
 		externalMachine, err := defaultTestMachine(machineContext, namespace, fakeClient, fakeVMCommandExecutor, []byte{})
 		Expect(err).NotTo(HaveOccurred())
 		validateVMExist(virtualMachine, fakeClient, machineContext)
+		setVMOwner(virtualMachine, externalMachine, fakeClient)
 
 		Expect(externalMachine.Delete()).To(Succeed())
 		validateVMNotExist(virtualMachine, fakeClient, machineContext)
@@ -969,6 +1157,198 @@ var _ = Describe("check GetVMNotReadyReason", func() {
 				},
 			},
 		}, "DVImagePullFailed", "test message"),
+		Entry("dv waiting for first consumer", &kubevirtv1.VirtualMachine{}, &cdiv1.DataVolume{
+			Status: cdiv1.DataVolumeStatus{
+				Phase: cdiv1.WaitForFirstConsumer,
+			},
+		}, DVWaitingForFirstConsumerReason, "waits for the VM to be scheduled"),
+	)
+})
+
+var _ = Describe("VM adoption", func() {
+	var (
+		machineContext *context.MachineContext
+		virtualMachine *kubevirtv1.VirtualMachine
+	)
+	namespace := kubevirtMachine.Namespace
+
+	BeforeEach(func() {
+		machineContext = &context.MachineContext{
+			Context:             gocontext.TODO(),
+			Cluster:             cluster,
+			KubevirtCluster:     kubevirtCluster,
+			Machine:             machine,
+			KubevirtMachine:     kubevirtMachine,
+			BootstrapDataSecret: bootstrapDataSecret,
+			Logger:              logger,
+		}
+
+		virtualMachine = newVirtualMachineFromKubevirtMachine(machineContext, namespace)
+		virtualMachine.Spec.Template.Spec.Volumes[len(virtualMachine.Spec.Template.Spec.Volumes)-1].CloudInitConfigDrive.UserDataSecretRef.Name = "previous-userdata"
+	})
+
+	newAdoptingMachine := func() *Machine {
+		fakeClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(virtualMachine).Build()
+		externalMachine, err := defaultTestMachine(machineContext, namespace, fakeClient, FakeVMCommandExecutor{true}, []byte(sshKey))
+		Expect(err).NotTo(HaveOccurred())
+		return externalMachine
+	}
+
+	It("should adopt a VM without the labels of the machine", func() {
+		adopted, err := newAdoptingMachine().Adopt(gocontext.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(adopted).To(BeTrue())
+
+		vm := &kubevirtv1.VirtualMachine{}
+		Expect(fakeClient.Get(gocontext.Background(), client.ObjectKeyFromObject(virtualMachine), vm)).To(Succeed())
+		Expect(vm.Labels).To(HaveKeyWithValue(v1alpha1.KubevirtMachineNameLabel, kubevirtMachine.Name))
+		Expect(vm.Labels).To(HaveKeyWithValue(v1alpha1.KubevirtMachineNamespaceLabel, kubevirtMachine.Namespace))
+		Expect(vm.Spec.Template.ObjectMeta.Labels).To(HaveKeyWithValue(v1alpha1.KubevirtMachineNameLabel, kubevirtMachine.Name))
+		volumes := vm.Spec.Template.Spec.Volumes
+		Expect(volumes[len(volumes)-1].CloudInitConfigDrive.UserDataSecretRef.Name).To(Equal(UserDataSecretName(machineContext)))
+	})
+
+	It("should not modify a VM created for the machine", func() {
+		virtualMachine.Labels[v1alpha1.KubevirtMachineNameLabel] = kubevirtMachine.Name
+		virtualMachine.Labels[v1alpha1.KubevirtMachineNamespaceLabel] = kubevirtMachine.Namespace
+
+		adopted, err := newAdoptingMachine().Adopt(gocontext.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(adopted).To(BeFalse())
+	})
+
+	It("should not adopt the VM of another KubevirtMachine", func() {
+		virtualMachine.Labels[v1alpha1.KubevirtMachineNameLabel] = kubevirtMachine.Name
+		virtualMachine.Labels[v1alpha1.KubevirtMachineNamespaceLabel] = "another-namespace"
+
+		adopted, err := newAdoptingMachine().Adopt(gocontext.Background())
+		Expect(err).To(MatchError(ContainSubstring("belongs to KubevirtMachine another-namespace/")))
+		Expect(adopted).To(BeFalse())
+	})
+
+	It("should not adopt the VM of another cluster", func() {
+		virtualMachine.Labels["cluster.x-k8s.io/cluster-name"] = "another-cluster"
+
+		adopted, err := newAdoptingMachine().Adopt(gocontext.Background())
+		Expect(err).To(MatchError(ContainSubstring("belongs to cluster another-cluster")))
+		Expect(adopted).To(BeFalse())
+	})
+
+	It("should not adopt the VM of another KubevirtMachine by its owner annotation", func() {
+		virtualMachine.Annotations = map[string]string{v1alpha1.InfraOwnerAnnotation: "KubevirtMachine/another-namespace/" + kubevirtMachine.Name}
+
+		adopted, err := newAdoptingMachine().Adopt(gocontext.Background())
+		Expect(err).To(MatchError(ContainSubstring("belongs to KubevirtMachine/another-namespace/")))
+		Expect(adopted).To(BeFalse())
+	})
+
+	It("should not adopt a VM merely named like the machine", func() {
+		delete(virtualMachine.Labels, "cluster.x-k8s.io/cluster-name")
+
+		adopted, err := newAdoptingMachine().Adopt(gocontext.Background())
+		Expect(err).To(MatchError(ContainSubstring("was not created for a cluster")))
+		Expect(adopted).To(BeFalse())
+	})
+
+	It("should not adopt anything without a VM", func() {
+		fakeClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).Build()
+		externalMachine, err := defaultTestMachine(machineContext, namespace, fakeClient, FakeVMCommandExecutor{true}, []byte(sshKey))
+		Expect(err).NotTo(HaveOccurred())
+
+		adopted, err := externalMachine.Adopt(gocontext.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(adopted).To(BeFalse())
+	})
+})
+
+var _ = Describe("terminal failures", func() {
+	DescribeTable("should report the VMs that can never start", func(vm *kubevirtv1.VirtualMachine, dv *cdiv1.DataVolume, expectedTerminal bool, expectedReason capierrors.MachineStatusError) {
+		m := Machine{
+			vmInstance: vm,
+		}
+		if dv != nil {
+			m.dataVolumes = []*cdiv1.DataVolume{dv}
+		}
+
+		terminal, _, err := m.IsTerminal()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(terminal).To(Equal(expectedTerminal))
+		if expectedTerminal {
+			Expect(m.FailureReason()).To(Equal(expectedReason))
+		}
+	},
+		Entry("vm without vmi yet", &kubevirtv1.VirtualMachine{}, nil, false, capierrors.MachineStatusError("")),
+		Entry("dv still importing", &kubevirtv1.VirtualMachine{}, &cdiv1.DataVolume{
+			Status: cdiv1.DataVolumeStatus{Phase: cdiv1.ImportInProgress},
+		}, false, capierrors.MachineStatusError("")),
+		Entry("dv failed to import", &kubevirtv1.VirtualMachine{}, &cdiv1.DataVolume{
+			Status: cdiv1.DataVolumeStatus{Phase: cdiv1.Failed},
+		}, true, capierrors.CreateMachineError),
+		Entry("vm pod exceeding a quota", &kubevirtv1.VirtualMachine{
+			Status: kubevirtv1.VirtualMachineStatus{
+				Conditions: []kubevirtv1.VirtualMachineCondition{
+					{
+						Type:    kubevirtv1.VirtualMachineFailure,
+						Status:  corev1.ConditionTrue,
+						Message: `pods "virt-launcher-test" is forbidden: exceeded quota: compute, requested: cpu=4`,
+					},
+				},
+			},
+		}, nil, true, capierrors.InsufficientResourcesMachineError),
+		Entry("vm failing for another reason", &kubevirtv1.VirtualMachine{
+			Status: kubevirtv1.VirtualMachineStatus{
+				Conditions: []kubevirtv1.VirtualMachineCondition{
+					{
+						Type:    kubevirtv1.VirtualMachineFailure,
+						Status:  corev1.ConditionTrue,
+						Message: "failed to create the virt-launcher pod",
+					},
+				},
+			},
+		}, nil, false, capierrors.MachineStatusError("")),
+	)
+
+	DescribeTable("should tell the creation errors that retrying cannot fix", func(err error, expectedTerminal bool, expectedReason capierrors.MachineStatusError) {
+		reason, terminal := CreateFailureReason(err)
+		Expect(terminal).To(Equal(expectedTerminal))
+		Expect(reason).To(Equal(expectedReason))
+	},
+		Entry("invalid template", apierrors.NewInvalid(kubevirtv1.VirtualMachineGroupVersionKind.GroupKind(), "test", nil), true, capierrors.CreateMachineError),
+		Entry("bad request", apierrors.NewBadRequest("unknown field"), true, capierrors.CreateMachineError),
+		Entry("exceeded quota", apierrors.NewForbidden(schema.GroupResource{Resource: "virtualmachines"}, "test", errors.New("exceeded quota: vms")), true, capierrors.InsufficientResourcesMachineError),
+		Entry("forbidden", apierrors.NewForbidden(schema.GroupResource{Resource: "virtualmachines"}, "test", errors.New("no permission")), false, capierrors.MachineStatusError("")),
+		Entry("transient error", apierrors.NewServiceUnavailable("try again"), false, capierrors.MachineStatusError("")),
+	)
+})
+
+var _ = Describe("address selection", func() {
+	interfaces := []kubevirtv1.VirtualMachineInstanceNetworkInterface{
+		{Name: "default", IP: "10.128.0.5", IPs: []string{"10.128.0.5", "fd02::5"}},
+		{Name: "nodes", IP: "192.168.10.5", IPs: []string{"192.168.10.5", "2001:db8::5"}},
+	}
+
+	DescribeTable("should select the addresses with the rules", func(internal, external *v1alpha1.NetworkAddressRule, expectedInternal, expectedExternal string) {
+		kubevirtMachine := &v1alpha1.KubevirtMachine{}
+		kubevirtMachine.Spec.InternalAddress = internal
+		kubevirtMachine.Spec.ExternalAddress = external
+		m := Machine{
+			machineContext: &context.MachineContext{KubevirtMachine: kubevirtMachine, Logger: logger},
+			vmiInstance: &kubevirtv1.VirtualMachineInstance{
+				Status: kubevirtv1.VirtualMachineInstanceStatus{Interfaces: interfaces},
+			},
+		}
+
+		Expect(m.Address()).To(Equal(expectedInternal))
+		Expect(m.ExternalAddress()).To(Equal(expectedExternal))
+	},
+		Entry("without rules", nil, nil, "10.128.0.5", "10.128.0.5"),
+		Entry("with an internal network", &v1alpha1.NetworkAddressRule{Network: "nodes"}, nil, "192.168.10.5", "192.168.10.5"),
+		Entry("with an internal network and an external network",
+			&v1alpha1.NetworkAddressRule{Network: "nodes"}, &v1alpha1.NetworkAddressRule{Network: "default"}, "192.168.10.5", "10.128.0.5"),
+		Entry("with an internal CIDR", &v1alpha1.NetworkAddressRule{CIDR: "2001:db8::/32"}, nil, "2001:db8::5", "2001:db8::5"),
+		Entry("with an internal network and CIDR", &v1alpha1.NetworkAddressRule{Network: "default", CIDR: "fd00::/8"}, nil, "fd02::5", "fd02::5"),
+		Entry("with an unknown network", &v1alpha1.NetworkAddressRule{Network: "storage"}, nil, "", ""),
+		Entry("with an invalid CIDR", &v1alpha1.NetworkAddressRule{CIDR: "not-a-cidr"}, nil, "", ""),
 	)
 })
 
@@ -990,6 +1370,14 @@ func validateVMExist(expected *kubevirtv1.VirtualMachine, fakeClient client.Clie
 	Expect(vm.Namespace).To(Equal(expected.Namespace))
 }
 
+// setVMOwner labels and annotates the VM as created for the machine.
+func setVMOwner(expected *kubevirtv1.VirtualMachine, machine *Machine, fakeClient client.Client) {
+	vm := &kubevirtv1.VirtualMachine{}
+	ExpectWithOffset(1, fakeClient.Get(gocontext.Background(), client.ObjectKeyFromObject(expected), vm)).To(Succeed())
+	machine.setOwnerLabels(vm)
+	ExpectWithOffset(1, fakeClient.Update(gocontext.Background(), vm)).To(Succeed())
+}
+
 type FakeVMCommandExecutor struct {
 	isVMRunning bool
 }
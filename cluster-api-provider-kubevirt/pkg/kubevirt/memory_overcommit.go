@@ -0,0 +1,80 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+// setMemoryOvercommit applies the memory overcommit policy of the cluster of the machine to the VMI template.
+func setMemoryOvercommit(template *kubevirtv1.VirtualMachineInstanceTemplateSpec, ctx *context.MachineContext) {
+	if ctx.KubevirtCluster == nil || ctx.KubevirtCluster.Spec.MemoryOvercommit == nil {
+		return
+	}
+	policy := ctx.KubevirtCluster.Spec.MemoryOvercommit
+
+	if policy.Ballooning != nil {
+		template.Spec.Domain.Devices.AutoattachMemBalloon = ptr.To(*policy.Ballooning)
+	}
+	if policy.FreePageReporting != nil && !*policy.FreePageReporting {
+		template.ObjectMeta.Annotations[kubevirtv1.FreePageReportingDisabledAnnotation] = "true"
+	}
+	overcommitMemory(&template.Spec.Domain, policy.MemoryRequestPercent)
+}
+
+// OvercommitCompute returns the size of the VMs of a cluster with the memory overcommit policy of the cluster
+// applied, so that resizing them keeps overcommitting their memory.
+func OvercommitCompute(compute Compute, policy *infrav1.MemoryOvercommitSpec) Compute {
+	if policy == nil || policy.MemoryRequestPercent == 0 {
+		return compute
+	}
+
+	domain := &kubevirtv1.DomainSpec{Memory: compute.Memory.DeepCopy()}
+	compute.Resources.DeepCopyInto(&domain.Resources)
+	overcommitMemory(domain, policy.MemoryRequestPercent)
+	compute.Memory = domain.Memory
+	compute.Resources = domain.Resources
+	return compute
+}
+
+// overcommitMemory sets the memory request of the domain to the percentage of its guest memory, setting the guest
+// memory to the memory request first if not set. Domains with neither are left untouched.
+func overcommitMemory(domain *kubevirtv1.DomainSpec, requestPercent int32) {
+	if requestPercent == 0 {
+		return
+	}
+
+	guest := guestMemory(domain)
+	if guest.IsZero() {
+		return
+	}
+	if domain.Memory == nil {
+		domain.Memory = &kubevirtv1.Memory{}
+	}
+	domain.Memory.Guest = ptr.To(guest.DeepCopy())
+
+	if domain.Resources.Requests == nil {
+		domain.Resources.Requests = corev1.ResourceList{}
+	}
+	domain.Resources.Requests[corev1.ResourceMemory] = *resource.NewQuantity(guest.Value()*int64(requestPercent)/100, resource.BinarySI)
+}
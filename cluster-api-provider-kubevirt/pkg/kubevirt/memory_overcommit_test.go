@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+var _ = Describe("Memory overcommit", func() {
+	var machineContext *context.MachineContext
+
+	BeforeEach(func() {
+		machineContext = &context.MachineContext{
+			Cluster:         cluster,
+			KubevirtCluster: kubevirtCluster.DeepCopy(),
+			Machine:         machine,
+			KubevirtMachine: kubevirtMachine.DeepCopy(),
+		}
+		machineContext.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.Template.Spec.Domain.Resources.Requests = corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("4Gi"),
+		}
+	})
+
+	It("should apply the memory overcommit policy of the cluster to the VMs", func() {
+		machineContext.KubevirtCluster.Spec.MemoryOvercommit = &infrav1.MemoryOvercommitSpec{
+			Ballooning:           ptr.To(true),
+			FreePageReporting:    ptr.To(false),
+			MemoryRequestPercent: 50,
+		}
+
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+
+		domain := newVM.Spec.Template.Spec.Domain
+		Expect(domain.Devices.AutoattachMemBalloon).To(Equal(ptr.To(true)))
+		Expect(newVM.Spec.Template.ObjectMeta.Annotations).To(HaveKeyWithValue(kubevirtv1.FreePageReportingDisabledAnnotation, "true"))
+		Expect(domain.Memory.Guest.String()).To(Equal("4Gi"))
+		Expect(domain.Resources.Requests.Memory().String()).To(Equal("2Gi"))
+	})
+
+	It("should keep the guest memory set by the VMI template", func() {
+		domain := &machineContext.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.Template.Spec.Domain
+		domain.Memory = &kubevirtv1.Memory{Guest: ptr.To(resource.MustParse("8Gi"))}
+		machineContext.KubevirtCluster.Spec.MemoryOvercommit = &infrav1.MemoryOvercommitSpec{MemoryRequestPercent: 25}
+
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+
+		Expect(newVM.Spec.Template.Spec.Domain.Memory.Guest.String()).To(Equal("8Gi"))
+		Expect(newVM.Spec.Template.Spec.Domain.Resources.Requests.Memory().String()).To(Equal("2Gi"))
+		Expect(newVM.Spec.Template.Spec.Domain.Devices.AutoattachMemBalloon).To(BeNil())
+		Expect(newVM.Spec.Template.ObjectMeta.Annotations).ToNot(HaveKey(kubevirtv1.FreePageReportingDisabledAnnotation))
+	})
+
+	It("should leave the VMs of the clusters without policy untouched", func() {
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+
+		Expect(newVM.Spec.Template.Spec.Domain.Memory).To(BeNil())
+		Expect(newVM.Spec.Template.Spec.Domain.Resources.Requests.Memory().String()).To(Equal("4Gi"))
+	})
+
+	It("should overcommit the memory of the resized VMs", func() {
+		compute := Compute{Resources: kubevirtv1.ResourceRequirements{Requests: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("6Gi"),
+		}}}
+
+		overcommitted := OvercommitCompute(compute, &infrav1.MemoryOvercommitSpec{MemoryRequestPercent: 50})
+
+		Expect(overcommitted.Memory.Guest.String()).To(Equal("6Gi"))
+		Expect(overcommitted.Resources.Requests.Memory().String()).To(Equal("3Gi"))
+		Expect(compute.Resources.Requests.Memory().String()).To(Equal("6Gi"))
+		Expect(OvercommitCompute(compute, nil)).To(Equal(compute))
+	})
+})
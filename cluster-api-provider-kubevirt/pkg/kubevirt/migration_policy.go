@@ -0,0 +1,164 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	gocontext "context"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	migrationsv1alpha1 "kubevirt.io/api/migrations/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+// migrationPhases are the phases of an active migration, in order, used to estimate its progress.
+var migrationPhases = []kubevirtv1.VirtualMachineInstanceMigrationPhase{
+	kubevirtv1.MigrationPending,
+	kubevirtv1.MigrationScheduling,
+	kubevirtv1.MigrationScheduled,
+	kubevirtv1.MigrationPreparingTarget,
+	kubevirtv1.MigrationTargetReady,
+	kubevirtv1.MigrationRunning,
+	kubevirtv1.MigrationSucceeded,
+}
+
+// MigrationPolicyName returns the name of the MigrationPolicy of the infra cluster created for the migration policy
+// of a KubevirtCluster or a KubevirtMachine. MigrationPolicies are cluster-scoped, and their name is also the value
+// of the label selecting the VMIs, hence a hash of the kind, namespace and name of the owner.
+func MigrationPolicyName(kind, namespace, name string) string {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(kind + "/" + namespace + "/" + name))
+	return fmt.Sprintf("capk-%08x", hash.Sum32())
+}
+
+// MachineMigrationPolicy returns the name of the MigrationPolicy selecting the VM of the machine, and its settings:
+// the ones of the cluster, overridden by the ones of the machine. The machines overriding the policy of the cluster
+// get their own MigrationPolicy, which then follows the settings of the cluster. It returns an empty name when
+// neither the cluster nor the machine has a migration policy.
+func MachineMigrationPolicy(ctx *context.MachineContext) (string, *infrav1.MigrationPolicySpec) {
+	var clusterPolicy *infrav1.MigrationPolicySpec
+	if ctx.KubevirtCluster != nil {
+		clusterPolicy = ctx.KubevirtCluster.Spec.MigrationPolicy
+	}
+	kubevirtMachine := ctx.KubevirtMachine
+	if kubevirtMachine.Spec.MigrationPolicy == nil && kubevirtMachine.Status.MigrationPolicy == "" {
+		if clusterPolicy == nil {
+			return "", nil
+		}
+		return MigrationPolicyName("KubevirtCluster", ctx.KubevirtCluster.Namespace, ctx.KubevirtCluster.Name), clusterPolicy
+	}
+
+	policy := &infrav1.MigrationPolicySpec{}
+	if clusterPolicy != nil {
+		clusterPolicy.DeepCopyInto(policy)
+	}
+	if override := kubevirtMachine.Spec.MigrationPolicy; override != nil {
+		if override.BandwidthPerMigration != nil {
+			policy.BandwidthPerMigration = ptr.To(override.BandwidthPerMigration.DeepCopy())
+		}
+		if override.CompletionTimeoutPerGiB != nil {
+			policy.CompletionTimeoutPerGiB = override.CompletionTimeoutPerGiB
+		}
+		if override.AllowAutoConverge != nil {
+			policy.AllowAutoConverge = override.AllowAutoConverge
+		}
+		if override.AllowPostCopy != nil {
+			policy.AllowPostCopy = override.AllowPostCopy
+		}
+	}
+	return MigrationPolicyName("KubevirtMachine", kubevirtMachine.Namespace, kubevirtMachine.Name), policy
+}
+
+// setMigrationPolicy labels the VMI template so that the MigrationPolicy of the machine selects its VMIs.
+func setMigrationPolicy(template *kubevirtv1.VirtualMachineInstanceTemplateSpec, ctx *context.MachineContext) {
+	if name, _ := MachineMigrationPolicy(ctx); name != "" {
+		template.ObjectMeta.Labels[infrav1.MigrationPolicyLabel] = name
+	}
+}
+
+// ReconcileMigrationPolicy creates or updates the MigrationPolicy of the infra cluster with the given name and
+// settings, selecting the VMIs labelled with its name.
+func ReconcileMigrationPolicy(ctx gocontext.Context, c client.Client, name string, spec *infrav1.MigrationPolicySpec, labels map[string]string) error {
+	spec = spec.DeepCopy()
+	policy := &migrationsv1alpha1.MigrationPolicy{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, c, policy, func() error {
+		if policy.Labels == nil {
+			policy.Labels = map[string]string{}
+		}
+		for key, value := range labels {
+			policy.Labels[key] = value
+		}
+		policy.Spec = migrationsv1alpha1.MigrationPolicySpec{
+			Selectors: &migrationsv1alpha1.Selectors{
+				VirtualMachineInstanceSelector: migrationsv1alpha1.LabelSelector{infrav1.MigrationPolicyLabel: name},
+			},
+			AllowAutoConverge:       spec.AllowAutoConverge,
+			BandwidthPerMigration:   spec.BandwidthPerMigration,
+			CompletionTimeoutPerGiB: spec.CompletionTimeoutPerGiB,
+			AllowPostCopy:           spec.AllowPostCopy,
+		}
+		return nil
+	}); err != nil {
+		return errors.Wrapf(err, "failed to reconcile MigrationPolicy %s", name)
+	}
+	return nil
+}
+
+// DeleteMigrationPolicy deletes the MigrationPolicy of the infra cluster with the given name, if it exists.
+func DeleteMigrationPolicy(ctx gocontext.Context, c client.Client, name string) error {
+	policy := &migrationsv1alpha1.MigrationPolicy{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if err := c.Delete(ctx, policy); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete MigrationPolicy %s", name)
+	}
+	return nil
+}
+
+// activeMigration returns the migration of the VMI in progress, if any, among the migrations of its namespace.
+func activeMigration(vmi *kubevirtv1.VirtualMachineInstance, migrations []kubevirtv1.VirtualMachineInstanceMigration) *infrav1.MigrationInfo {
+	for i := range migrations {
+		migration := &migrations[i]
+		if migration.Spec.VMIName != vmi.Name || migration.IsFinal() || migration.DeletionTimestamp != nil {
+			continue
+		}
+
+		info := &infrav1.MigrationInfo{
+			Name:  migration.Name,
+			Phase: migration.Status.Phase,
+		}
+		for i, phase := range migrationPhases {
+			if phase == migration.Status.Phase {
+				info.ProgressPercent = int32(i * 100 / (len(migrationPhases) - 1))
+			}
+		}
+		if state := vmi.Status.MigrationState; state != nil && state.MigrationUID == migration.UID {
+			info.Mode = state.Mode
+			info.SourceNode = state.SourceNode
+			info.StartTime = state.StartTimestamp.DeepCopy()
+		}
+		return info
+	}
+	return nil
+}
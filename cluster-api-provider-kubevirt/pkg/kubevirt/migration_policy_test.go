@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	gocontext "context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	migrationsv1alpha1 "kubevirt.io/api/migrations/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("Migration policy", func() {
+	var machineContext *context.MachineContext
+
+	BeforeEach(func() {
+		machineContext = &context.MachineContext{
+			Cluster:         cluster,
+			KubevirtCluster: kubevirtCluster.DeepCopy(),
+			Machine:         machine,
+			KubevirtMachine: kubevirtMachine.DeepCopy(),
+		}
+	})
+
+	It("should select the VMs with the migration policy of their cluster", func() {
+		machineContext.KubevirtCluster.Spec.MigrationPolicy = &infrav1.MigrationPolicySpec{AllowAutoConverge: ptr.To(true)}
+
+		name, policy := MachineMigrationPolicy(machineContext)
+		Expect(name).To(Equal(MigrationPolicyName("KubevirtCluster", kubevirtCluster.Namespace, kubevirtCluster.Name)))
+		Expect(policy).To(Equal(machineContext.KubevirtCluster.Spec.MigrationPolicy))
+
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+		Expect(newVM.Spec.Template.ObjectMeta.Labels).To(HaveKeyWithValue(infrav1.MigrationPolicyLabel, name))
+	})
+
+	It("should override the migration policy of the cluster with the one of the machine", func() {
+		machineContext.KubevirtCluster.Spec.MigrationPolicy = &infrav1.MigrationPolicySpec{
+			AllowAutoConverge:     ptr.To(true),
+			BandwidthPerMigration: ptr.To(resource.MustParse("64Mi")),
+		}
+		machineContext.KubevirtMachine.Spec.MigrationPolicy = &infrav1.MigrationPolicySpec{
+			BandwidthPerMigration: ptr.To(resource.MustParse("128Mi")),
+			AllowPostCopy:         ptr.To(true),
+		}
+
+		name, policy := MachineMigrationPolicy(machineContext)
+		Expect(name).To(Equal(MigrationPolicyName("KubevirtMachine", kubevirtMachine.Namespace, kubevirtMachine.Name)))
+		Expect(policy).To(Equal(&infrav1.MigrationPolicySpec{
+			AllowAutoConverge:     ptr.To(true),
+			BandwidthPerMigration: ptr.To(resource.MustParse("128Mi")),
+			AllowPostCopy:         ptr.To(true),
+		}))
+		Expect(machineContext.KubevirtCluster.Spec.MigrationPolicy.BandwidthPerMigration.String()).To(Equal("64Mi"))
+	})
+
+	It("should not label the VMs without migration policy", func() {
+		name, _ := MachineMigrationPolicy(machineContext)
+		Expect(name).To(BeEmpty())
+
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+		Expect(newVM.Spec.Template.ObjectMeta.Labels).ToNot(HaveKey(infrav1.MigrationPolicyLabel))
+	})
+
+	It("should create, update and delete the MigrationPolicy of the infra cluster", func() {
+		c := fake.NewClientBuilder().WithScheme(testing.SetupScheme()).Build()
+		ctx := gocontext.Background()
+
+		Expect(ReconcileMigrationPolicy(ctx, c, "capk-test", &infrav1.MigrationPolicySpec{CompletionTimeoutPerGiB: ptr.To[int64](150)}, map[string]string{"a": "b"})).To(Succeed())
+		Expect(ReconcileMigrationPolicy(ctx, c, "capk-test", &infrav1.MigrationPolicySpec{AllowPostCopy: ptr.To(true)}, nil)).To(Succeed())
+
+		policy := &migrationsv1alpha1.MigrationPolicy{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: "capk-test"}, policy)).To(Succeed())
+		Expect(policy.Labels).To(HaveKeyWithValue("a", "b"))
+		Expect(policy.Spec).To(Equal(migrationsv1alpha1.MigrationPolicySpec{
+			Selectors: &migrationsv1alpha1.Selectors{
+				VirtualMachineInstanceSelector: migrationsv1alpha1.LabelSelector{infrav1.MigrationPolicyLabel: "capk-test"},
+			},
+			AllowPostCopy: ptr.To(true),
+		}))
+
+		Expect(DeleteMigrationPolicy(ctx, c, "capk-test")).To(Succeed())
+		Expect(DeleteMigrationPolicy(ctx, c, "capk-test")).To(Succeed())
+	})
+
+	It("should report the migration of the VMI in progress", func() {
+		vmi := &kubevirtv1.VirtualMachineInstance{ObjectMeta: metav1.ObjectMeta{Name: "vm"}}
+		vmi.Status.MigrationState = &kubevirtv1.VirtualMachineInstanceMigrationState{
+			MigrationUID: "running",
+			Mode:         kubevirtv1.MigrationPreCopy,
+			SourceNode:   "node1",
+		}
+		migrations := []kubevirtv1.VirtualMachineInstanceMigration{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "done", UID: "done"},
+				Spec:       kubevirtv1.VirtualMachineInstanceMigrationSpec{VMIName: "vm"},
+				Status:     kubevirtv1.VirtualMachineInstanceMigrationStatus{Phase: kubevirtv1.MigrationSucceeded},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "other", UID: "other"},
+				Spec:       kubevirtv1.VirtualMachineInstanceMigrationSpec{VMIName: "other"},
+				Status:     kubevirtv1.VirtualMachineInstanceMigrationStatus{Phase: kubevirtv1.MigrationScheduling},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "running", UID: "running"},
+				Spec:       kubevirtv1.VirtualMachineInstanceMigrationSpec{VMIName: "vm"},
+				Status:     kubevirtv1.VirtualMachineInstanceMigrationStatus{Phase: kubevirtv1.MigrationRunning},
+			},
+		}
+
+		Expect(activeMigration(vmi, migrations)).To(Equal(&infrav1.MigrationInfo{
+			Name:            "running",
+			Phase:           kubevirtv1.MigrationRunning,
+			ProgressPercent: 83,
+			Mode:            kubevirtv1.MigrationPreCopy,
+			SourceNode:      "node1",
+		}))
+		Expect(activeMigration(vmi, migrations[:2])).To(BeNil())
+	})
+})
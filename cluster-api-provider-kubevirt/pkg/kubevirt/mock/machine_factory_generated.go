@@ -10,10 +10,12 @@ import (
 	time "time"
 
 	gomock "github.com/golang/mock/gomock"
+	v1alpha1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
 	context0 "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
 	kubevirt "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
 	ssh "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/ssh"
 	workloadcluster "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/workloadcluster"
+	errors "sigs.k8s.io/cluster-api/errors"
 	client "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -54,6 +56,21 @@ func (mr *MockMachineInterfaceMockRecorder) Address() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Address", reflect.TypeOf((*MockMachineInterface)(nil).Address))
 }
 
+// Adopt mocks base method.
+func (m *MockMachineInterface) Adopt(ctx context.Context) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Adopt", ctx)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Adopt indicates an expected call of Adopt.
+func (mr *MockMachineInterfaceMockRecorder) Adopt(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Adopt", reflect.TypeOf((*MockMachineInterface)(nil).Adopt), ctx)
+}
+
 // Create mocks base method.
 func (m *MockMachineInterface) Create(ctx context.Context) error {
 	m.ctrl.T.Helper()
@@ -97,6 +114,20 @@ func (mr *MockMachineInterfaceMockRecorder) DrainNodeIfNeeded(arg0 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DrainNodeIfNeeded", reflect.TypeOf((*MockMachineInterface)(nil).DrainNodeIfNeeded), arg0)
 }
 
+// Drift mocks base method.
+func (m *MockMachineInterface) Drift() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Drift")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// Drift indicates an expected call of Drift.
+func (mr *MockMachineInterfaceMockRecorder) Drift() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Drift", reflect.TypeOf((*MockMachineInterface)(nil).Drift))
+}
+
 // Exists mocks base method.
 func (m *MockMachineInterface) Exists() bool {
 	m.ctrl.T.Helper()
@@ -111,6 +142,34 @@ func (mr *MockMachineInterfaceMockRecorder) Exists() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exists", reflect.TypeOf((*MockMachineInterface)(nil).Exists))
 }
 
+// ExternalAddress mocks base method.
+func (m *MockMachineInterface) ExternalAddress() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExternalAddress")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ExternalAddress indicates an expected call of ExternalAddress.
+func (mr *MockMachineInterfaceMockRecorder) ExternalAddress() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExternalAddress", reflect.TypeOf((*MockMachineInterface)(nil).ExternalAddress))
+}
+
+// FailureReason mocks base method.
+func (m *MockMachineInterface) FailureReason() errors.MachineStatusError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailureReason")
+	ret0, _ := ret[0].(errors.MachineStatusError)
+	return ret0
+}
+
+// FailureReason indicates an expected call of FailureReason.
+func (mr *MockMachineInterfaceMockRecorder) FailureReason() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailureReason", reflect.TypeOf((*MockMachineInterface)(nil).FailureReason))
+}
+
 // GenerateProviderID mocks base method.
 func (m *MockMachineInterface) GenerateProviderID() (string, error) {
 	m.ctrl.T.Helper()
@@ -192,6 +251,36 @@ func (mr *MockMachineInterfaceMockRecorder) IsTerminal() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsTerminal", reflect.TypeOf((*MockMachineInterface)(nil).IsTerminal))
 }
 
+// RedeliverBootstrapData mocks base method.
+func (m *MockMachineInterface) RedeliverBootstrapData(ctx context.Context) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeliverBootstrapData", ctx)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedeliverBootstrapData indicates an expected call of RedeliverBootstrapData.
+func (mr *MockMachineInterfaceMockRecorder) RedeliverBootstrapData(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeliverBootstrapData", reflect.TypeOf((*MockMachineInterface)(nil).RedeliverBootstrapData), ctx)
+}
+
+// RevertDrift mocks base method.
+func (m *MockMachineInterface) RevertDrift(ctx context.Context) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevertDrift", ctx)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevertDrift indicates an expected call of RevertDrift.
+func (mr *MockMachineInterfaceMockRecorder) RevertDrift(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevertDrift", reflect.TypeOf((*MockMachineInterface)(nil).RevertDrift), ctx)
+}
+
 // SupportsCheckingIsBootstrapped mocks base method.
 func (m *MockMachineInterface) SupportsCheckingIsBootstrapped() bool {
 	m.ctrl.T.Helper()
@@ -206,6 +295,20 @@ func (mr *MockMachineInterfaceMockRecorder) SupportsCheckingIsBootstrapped() *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SupportsCheckingIsBootstrapped", reflect.TypeOf((*MockMachineInterface)(nil).SupportsCheckingIsBootstrapped))
 }
 
+// VMIStatus mocks base method.
+func (m *MockMachineInterface) VMIStatus() *v1alpha1.VirtualMachineInstanceInfo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VMIStatus")
+	ret0, _ := ret[0].(*v1alpha1.VirtualMachineInstanceInfo)
+	return ret0
+}
+
+// VMIStatus indicates an expected call of VMIStatus.
+func (mr *MockMachineInterfaceMockRecorder) VMIStatus() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VMIStatus", reflect.TypeOf((*MockMachineInterface)(nil).VMIStatus))
+}
+
 // MockMachineFactory is a mock of MachineFactory interface.
 type MockMachineFactory struct {
 	ctrl     *gomock.Controller
@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	gocontext "context"
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+const (
+	// KVMDeviceResource is the resource of the KubeVirt device plugin exposing /dev/kvm to the VMs of a node.
+	KVMDeviceResource corev1.ResourceName = "devices.kubevirt.io/kvm"
+
+	// The node labeller of KubeVirt labels the nodes with the CPU features VMs can use; the Intel and AMD
+	// virtualization extensions are only usable when the node allows nested virtualization.
+	vmxFeatureLabel = "cpu-feature.node.kubevirt.io/vmx"
+	svmFeatureLabel = "cpu-feature.node.kubevirt.io/svm"
+)
+
+var nestedVirtualizationFeatureLabels = []string{vmxFeatureLabel, svmFeatureLabel}
+
+// SupportsNestedVirtualization returns true if the infra node exposes /dev/kvm to its VMs, and its VMs can use the
+// virtualization extensions of its CPU.
+func SupportsNestedVirtualization(node *corev1.Node) bool {
+	if kvm, found := node.Status.Allocatable[KVMDeviceResource]; !found || kvm.IsZero() {
+		return false
+	}
+	for _, label := range nestedVirtualizationFeatureLabels {
+		if node.Labels[label] == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// FindNestedVirtualizationNodes returns the infra nodes supporting nested virtualization, sorted by name.
+func FindNestedVirtualizationNodes(ctx gocontext.Context, c client.Client) ([]corev1.Node, error) {
+	nodes := &corev1.NodeList{}
+	if err := c.List(ctx, nodes); err != nil {
+		return nil, errors.Wrap(err, "failed to list infra nodes")
+	}
+
+	var capable []corev1.Node
+	for _, node := range nodes.Items {
+		if SupportsNestedVirtualization(&node) {
+			capable = append(capable, node)
+		}
+	}
+	sort.Slice(capable, func(i, j int) bool { return capable[i].Name < capable[j].Name })
+
+	return capable, nil
+}
+
+// UnavailableNestedVirtualization returns a message if the machine requires nested virtualization and none of the
+// infra nodes supports it, or an empty string otherwise. Nested virtualization is considered available while the
+// infra nodes are unknown.
+func UnavailableNestedVirtualization(ctx *context.MachineContext) string {
+	if !ctx.KubevirtMachine.Spec.RequiresNestedVirtualization {
+		return ""
+	}
+	nested := ctx.KubevirtCluster.Status.NestedVirtualization
+	if nested == nil || len(nested.Nodes) > 0 {
+		return ""
+	}
+	return fmt.Sprintf("no infra node supports nested virtualization, the nodes need allocatable %s and the %s or %s label set to true", KVMDeviceResource, vmxFeatureLabel, svmFeatureLabel)
+}
+
+// requireNestedVirtualization restricts the VMI to the infra nodes supporting nested virtualization, on top of the
+// node affinity of the VMI template, if any.
+func requireNestedVirtualization(spec *kubevirtv1.VirtualMachineInstanceSpec) {
+	if spec.Affinity == nil {
+		spec.Affinity = &corev1.Affinity{}
+	}
+	if spec.Affinity.NodeAffinity == nil {
+		spec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	nodeAffinity := spec.Affinity.NodeAffinity
+	if nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{}
+	}
+	selector := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+
+	// Node selector terms are ORed, their expressions ANDed: each term of the template is split in one term per
+	// feature label
+	terms := selector.NodeSelectorTerms
+	if len(terms) == 0 {
+		terms = []corev1.NodeSelectorTerm{{}}
+	}
+	var required []corev1.NodeSelectorTerm
+	for _, term := range terms {
+		for _, label := range nestedVirtualizationFeatureLabels {
+			featureTerm := *term.DeepCopy()
+			featureTerm.MatchExpressions = append(featureTerm.MatchExpressions, corev1.NodeSelectorRequirement{
+				Key:      label,
+				Operator: corev1.NodeSelectorOpIn,
+				Values:   []string{"true"},
+			})
+			required = append(required, featureTerm)
+		}
+	}
+	selector.NodeSelectorTerms = required
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	gocontext "context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("Nested virtualization", func() {
+	newNode := func(name string, kvmDevices int64, labels map[string]string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{KVMDeviceResource: *resource.NewQuantity(kvmDevices, resource.DecimalSI)},
+			},
+		}
+	}
+
+	DescribeTable("should detect the infra nodes supporting nested virtualization", func(node *corev1.Node, expected bool) {
+		Expect(SupportsNestedVirtualization(node)).To(Equal(expected))
+	},
+		Entry("intel", newNode("node", 110, map[string]string{vmxFeatureLabel: "true"}), true),
+		Entry("amd", newNode("node", 110, map[string]string{svmFeatureLabel: "true"}), true),
+		Entry("without nested virtualization", newNode("node", 110, nil), false),
+		Entry("without /dev/kvm", newNode("node", 0, map[string]string{vmxFeatureLabel: "true"}), false),
+	)
+
+	It("should list the infra nodes supporting nested virtualization", func() {
+		c := fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(
+			newNode("node-c", 110, map[string]string{svmFeatureLabel: "true"}),
+			newNode("node-b", 110, nil),
+			newNode("node-a", 110, map[string]string{vmxFeatureLabel: "true"}),
+		).Build()
+
+		nodes, err := FindNestedVirtualizationNodes(gocontext.Background(), c)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(nodes).To(HaveLen(2))
+		Expect(nodes[0].Name).To(Equal("node-a"))
+		Expect(nodes[1].Name).To(Equal("node-c"))
+	})
+
+	DescribeTable("should report the machines requiring nested virtualization no infra node supports", func(required bool, nested *infrav1.NestedVirtualizationStatus, unavailable bool) {
+		ctx := &context.MachineContext{
+			KubevirtCluster: &infrav1.KubevirtCluster{Status: infrav1.KubevirtClusterStatus{NestedVirtualization: nested}},
+			KubevirtMachine: &infrav1.KubevirtMachine{Spec: infrav1.KubevirtMachineSpec{RequiresNestedVirtualization: required}},
+		}
+		if unavailable {
+			Expect(UnavailableNestedVirtualization(ctx)).To(ContainSubstring("no infra node supports nested virtualization"))
+		} else {
+			Expect(UnavailableNestedVirtualization(ctx)).To(BeEmpty())
+		}
+	},
+		Entry("without capable nodes", true, &infrav1.NestedVirtualizationStatus{}, true),
+		Entry("with capable nodes", true, &infrav1.NestedVirtualizationStatus{Nodes: []string{"node-a"}}, false),
+		Entry("with unknown nodes", true, nil, false),
+		Entry("not required", false, &infrav1.NestedVirtualizationStatus{}, false),
+	)
+
+	It("should schedule the VMs requiring nested virtualization on capable nodes", func() {
+		machineContext := &context.MachineContext{
+			Cluster:         cluster,
+			KubevirtCluster: kubevirtCluster,
+			Machine:         machine,
+			KubevirtMachine: kubevirtMachine.DeepCopy(),
+		}
+		machineContext.KubevirtMachine.Spec.RequiresNestedVirtualization = true
+		machineContext.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.Template.Spec.Affinity = &corev1.Affinity{
+			NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+						MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{"gpu"}}},
+					}},
+				},
+			},
+		}
+
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+
+		pool := corev1.NodeSelectorRequirement{Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{"gpu"}}
+		Expect(newVM.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms).To(Equal([]corev1.NodeSelectorTerm{
+			{MatchExpressions: []corev1.NodeSelectorRequirement{pool, {Key: vmxFeatureLabel, Operator: corev1.NodeSelectorOpIn, Values: []string{"true"}}}},
+			{MatchExpressions: []corev1.NodeSelectorRequirement{pool, {Key: svmFeatureLabel, Operator: corev1.NodeSelectorOpIn, Values: []string{"true"}}}},
+		}))
+		// the template of the machine is left as-is
+		Expect(machineContext.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.Template.Spec.Affinity.NodeAffinity.
+			RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions).To(HaveLen(1))
+	})
+
+	It("should not constrain the VMs not requiring nested virtualization", func() {
+		machineContext := &context.MachineContext{
+			Cluster:         cluster,
+			KubevirtCluster: kubevirtCluster,
+			Machine:         machine,
+			KubevirtMachine: kubevirtMachine.DeepCopy(),
+		}
+
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+		Expect(newVM.Spec.Template.Spec.Affinity).To(BeNil())
+	})
+})
@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	gocontext "context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+// makePreemptible sets the priority class of a preemptible VM, and its eviction strategy to External, so that
+// the evictions of the infra cluster reclaim the machine instead of migrating its VM.
+func makePreemptible(spec *kubevirtv1.VirtualMachineInstanceSpec, preemptible *infrav1.PreemptibleSpec) {
+	if preemptible.PriorityClassName != "" {
+		spec.PriorityClassName = preemptible.PriorityClassName
+	}
+	external := kubevirtv1.EvictionStrategyExternal
+	spec.EvictionStrategy = &external
+}
+
+// IsReclaimRequested returns true if the VM is annotated to be reclaimed, or if the infra cluster evicts its VMI.
+func IsReclaimRequested(vm *kubevirtv1.VirtualMachine, vmi *kubevirtv1.VirtualMachineInstance) bool {
+	if vm.Annotations[infrav1.ReclaimAnnotation] == "true" {
+		return true
+	}
+	return vmi != nil && vmi.DeletionTimestamp == nil && vmi.Status.EvacuationNodeName != ""
+}
+
+// RequestReclaim annotates the VM to be reclaimed, so that the request outlives the VMI whose eviction triggered it.
+func RequestReclaim(ctx gocontext.Context, c client.Client, vm *kubevirtv1.VirtualMachine) error {
+	if vm.Annotations[infrav1.ReclaimAnnotation] == "true" {
+		return nil
+	}
+
+	patchBase := client.MergeFrom(vm.DeepCopy())
+	if vm.Annotations == nil {
+		vm.Annotations = map[string]string{}
+	}
+	vm.Annotations[infrav1.ReclaimAnnotation] = "true"
+	if err := c.Patch(ctx, vm, patchBase); err != nil {
+		return errors.Wrapf(err, "failed to request the reclaim of VM %s/%s", vm.Namespace, vm.Name)
+	}
+	return nil
+}
+
+// SetReclaimedTaint adds the taint of the reclaimed machines to the Node, and returns whether it changed.
+func SetReclaimedTaint(node *corev1.Node) bool {
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == infrav1.ReclaimedTaint {
+			return false
+		}
+	}
+	node.Spec.Taints = append(node.Spec.Taints, corev1.Taint{
+		Key:    infrav1.ReclaimedTaint,
+		Value:  "true",
+		Effect: corev1.TaintEffectNoSchedule,
+	})
+	return true
+}
+
+// RemoveReclaimedTaint removes the taint of the reclaimed machines from the Node, and returns whether it changed.
+func RemoveReclaimedTaint(node *corev1.Node) bool {
+	var taints []corev1.Taint
+	for _, taint := range node.Spec.Taints {
+		if taint.Key != infrav1.ReclaimedTaint {
+			taints = append(taints, taint)
+		}
+	}
+	if len(taints) == len(node.Spec.Taints) {
+		return false
+	}
+	node.Spec.Taints = taints
+	return true
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+var _ = Describe("Preemption", func() {
+	It("should create the VMs of preemptible machines with a low priority and reclaimed on eviction", func() {
+		machineContext := &context.MachineContext{
+			Cluster:         cluster,
+			KubevirtCluster: kubevirtCluster,
+			Machine:         machine,
+			KubevirtMachine: kubevirtMachine.DeepCopy(),
+		}
+		machineContext.KubevirtMachine.Spec.Preemptible = &infrav1.PreemptibleSpec{PriorityClassName: "batch"}
+
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+
+		Expect(newVM.Labels).To(HaveKeyWithValue(infrav1.PreemptibleLabel, "true"))
+		Expect(newVM.Spec.Template.ObjectMeta.Labels).To(HaveKeyWithValue(infrav1.PreemptibleLabel, "true"))
+		Expect(newVM.Spec.Template.Spec.PriorityClassName).To(Equal("batch"))
+		Expect(newVM.Spec.Template.Spec.EvictionStrategy).To(HaveValue(Equal(kubevirtv1.EvictionStrategyExternal)))
+	})
+
+	It("should not change the VMs of the other machines", func() {
+		machineContext := &context.MachineContext{
+			Cluster:         cluster,
+			KubevirtCluster: kubevirtCluster,
+			Machine:         machine,
+			KubevirtMachine: kubevirtMachine.DeepCopy(),
+		}
+
+		newVM := newVirtualMachineFromKubevirtMachine(machineContext, "default")
+
+		Expect(newVM.Labels).ToNot(HaveKey(infrav1.PreemptibleLabel))
+		Expect(newVM.Spec.Template.Spec.PriorityClassName).To(BeEmpty())
+	})
+
+	DescribeTable("should tell the reclaimed VMs", func(annotations map[string]string, vmi *kubevirtv1.VirtualMachineInstance, expected bool) {
+		vm := &kubevirtv1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Annotations: annotations}}
+		Expect(IsReclaimRequested(vm, vmi)).To(Equal(expected))
+	},
+		Entry("annotated", map[string]string{infrav1.ReclaimAnnotation: "true"}, nil, true),
+		Entry("evicted", nil, &kubevirtv1.VirtualMachineInstance{Status: kubevirtv1.VirtualMachineInstanceStatus{EvacuationNodeName: "node"}}, true),
+		Entry("running", nil, &kubevirtv1.VirtualMachineInstance{}, false),
+		Entry("stopped", nil, nil, false),
+	)
+
+	It("should set and remove the taint of the reclaimed Nodes once", func() {
+		node := &corev1.Node{Spec: corev1.NodeSpec{Taints: []corev1.Taint{{Key: "other", Effect: corev1.TaintEffectNoSchedule}}}}
+
+		Expect(SetReclaimedTaint(node)).To(BeTrue())
+		Expect(SetReclaimedTaint(node)).To(BeFalse())
+		Expect(node.Spec.Taints).To(HaveLen(2))
+
+		Expect(RemoveReclaimedTaint(node)).To(BeTrue())
+		Expect(RemoveReclaimedTaint(node)).To(BeFalse())
+		Expect(node.Spec.Taints).To(Equal([]corev1.Taint{{Key: "other", Effect: corev1.TaintEffectNoSchedule}}))
+	})
+})
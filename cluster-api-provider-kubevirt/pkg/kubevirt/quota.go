@@ -0,0 +1,62 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+// MachineUsage returns the vCPUs and the guest memory of the VM of the machine, from its template. The VMs sized by
+// an instance type count the vCPUs and memory their template sets, if any.
+func MachineUsage(kubevirtMachine *infrav1.KubevirtMachine) Usage {
+	template := kubevirtMachine.Spec.VirtualMachineTemplate.Spec.Template
+	if template == nil {
+		return Usage{CPU: *resource.NewQuantity(1, resource.DecimalSI)}
+	}
+	return Usage{
+		CPU:    *resource.NewQuantity(vCPUs(&template.Spec.Domain), resource.DecimalSI),
+		Memory: guestMemory(&template.Spec.Domain),
+	}
+}
+
+// QuotaExceeded returns a message if the machine, on top of the machines of its cluster already counted against
+// the quota of the cluster, would exceed the quota, or an empty string otherwise.
+func QuotaExceeded(quota *infrav1.ClusterQuota, counted []infrav1.KubevirtMachine, kubevirtMachine *infrav1.KubevirtMachine) string {
+	if quota == nil {
+		return ""
+	}
+
+	usage := MachineUsage(kubevirtMachine)
+	for i := range counted {
+		usage.Add(MachineUsage(&counted[i]))
+	}
+	machines := len(counted) + 1
+
+	switch {
+	case quota.MaxMachines != nil && machines > int(*quota.MaxMachines):
+		return fmt.Sprintf("the cluster would have %d machines, more than its quota of %d", machines, *quota.MaxMachines)
+	case quota.MaxTotalCPU != nil && usage.CPU.Cmp(*quota.MaxTotalCPU) > 0:
+		return fmt.Sprintf("the VMs of the cluster would have %s vCPUs, more than its quota of %s", usage.CPU.String(), quota.MaxTotalCPU.String())
+	case quota.MaxTotalMemory != nil && usage.Memory.Cmp(*quota.MaxTotalMemory) > 0:
+		return fmt.Sprintf("the VMs of the cluster would have %s of memory, more than its quota of %s", usage.Memory.String(), quota.MaxTotalMemory.String())
+	}
+	return ""
+}
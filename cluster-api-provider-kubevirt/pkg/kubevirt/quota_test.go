@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+var _ = Describe("Quota", func() {
+	newMachine := func(cores uint32, memory string) infrav1.KubevirtMachine {
+		kubevirtMachine := infrav1.KubevirtMachine{}
+		kubevirtMachine.Spec.VirtualMachineTemplate.Spec.Template = &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+			Spec: kubevirtv1.VirtualMachineInstanceSpec{
+				Domain: kubevirtv1.DomainSpec{
+					CPU:       &kubevirtv1.CPU{Cores: cores},
+					Resources: kubevirtv1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(memory)}},
+				},
+			},
+		}
+		return kubevirtMachine
+	}
+
+	It("should count the vCPUs and the memory of the template of a machine", func() {
+		kubevirtMachine := newMachine(4, "8Gi")
+		usage := MachineUsage(&kubevirtMachine)
+		Expect(usage.CPU.Value()).To(Equal(int64(4)))
+		Expect(usage.Memory.String()).To(Equal("8Gi"))
+
+		usage = MachineUsage(&infrav1.KubevirtMachine{})
+		Expect(usage.CPU.Value()).To(Equal(int64(1)))
+	})
+
+	counted := []infrav1.KubevirtMachine{newMachine(2, "4Gi"), newMachine(2, "4Gi")}
+
+	DescribeTable("should tell if a machine exceeds the quota of its cluster", func(quota *infrav1.ClusterQuota, expected string) {
+		kubevirtMachine := newMachine(2, "4Gi")
+		if expected == "" {
+			Expect(QuotaExceeded(quota, counted, &kubevirtMachine)).To(BeEmpty())
+		} else {
+			Expect(QuotaExceeded(quota, counted, &kubevirtMachine)).To(Equal(expected))
+		}
+	},
+		Entry("without quota", nil, ""),
+		Entry("within the quota", &infrav1.ClusterQuota{MaxMachines: ptr.To[int32](3), MaxTotalCPU: ptr.To(resource.MustParse("6")), MaxTotalMemory: ptr.To(resource.MustParse("12Gi"))}, ""),
+		Entry("too many machines", &infrav1.ClusterQuota{MaxMachines: ptr.To[int32](2)}, "the cluster would have 3 machines, more than its quota of 2"),
+		Entry("too many vCPUs", &infrav1.ClusterQuota{MaxTotalCPU: ptr.To(resource.MustParse("5"))}, "the VMs of the cluster would have 6 vCPUs, more than its quota of 5"),
+		Entry("too much memory", &infrav1.ClusterQuota{MaxTotalMemory: ptr.To(resource.MustParse("10Gi"))}, "the VMs of the cluster would have 12Gi of memory, more than its quota of 10Gi"),
+	)
+})
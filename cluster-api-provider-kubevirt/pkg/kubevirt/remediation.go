@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	gocontext "context"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ResetVirtualMachine stops the VM immediately, without shutting down its guest, by force deleting its VMI, if any.
+// The VMI is then created again according to the run strategy of the VM.
+func ResetVirtualMachine(ctx gocontext.Context, c client.Client, vm *kubevirtv1.VirtualMachine) error {
+	vmi := &kubevirtv1.VirtualMachineInstance{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(vm), vmi); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to fetch VMI %s/%s", vm.Namespace, vm.Name)
+	}
+
+	if err := c.Delete(ctx, vmi, client.GracePeriodSeconds(0)); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to reset VMI %s/%s", vm.Namespace, vm.Name)
+	}
+
+	return nil
+}
+
+// MigrateVirtualMachine live migrates the VM to another infra node, with a migration of the given name. It is a
+// no-op if the migration already exists.
+func MigrateVirtualMachine(ctx gocontext.Context, c client.Client, vm *kubevirtv1.VirtualMachine, migrationName string) error {
+	migration := &kubevirtv1.VirtualMachineInstanceMigration{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: vm.Namespace,
+			Name:      migrationName,
+		},
+		Spec: kubevirtv1.VirtualMachineInstanceMigrationSpec{
+			VMIName: vm.Name,
+		},
+	}
+	if err := c.Create(ctx, migration); err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "failed to migrate VM %s/%s", vm.Namespace, vm.Name)
+	}
+
+	return nil
+}
+
+// IsMigrationFinished returns true once the migration of the given name either succeeded or failed. A migration
+// that does not exist anymore is finished.
+func IsMigrationFinished(ctx gocontext.Context, c client.Client, namespace, migrationName string) (bool, error) {
+	migration := &kubevirtv1.VirtualMachineInstanceMigration{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: migrationName}, migration); err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, errors.Wrapf(err, "failed to fetch migration %s/%s", namespace, migrationName)
+	}
+
+	return migration.IsFinal(), nil
+}
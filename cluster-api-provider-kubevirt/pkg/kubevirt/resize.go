@@ -0,0 +1,182 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	gocontext "context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Compute is the size of a VM: the CPU, memory and resources of its domain.
+type Compute struct {
+	CPU       *kubevirtv1.CPU
+	Memory    *kubevirtv1.Memory
+	Resources kubevirtv1.ResourceRequirements
+}
+
+// Equal returns true if both sizes are the same.
+func (c Compute) Equal(other Compute) bool {
+	return equality.Semantic.DeepEqual(c, other)
+}
+
+// GetCompute returns the size of the VMs created from the VM spec.
+func GetCompute(spec *kubevirtv1.VirtualMachineSpec) Compute {
+	if spec.Template == nil {
+		return Compute{}
+	}
+	domain := spec.Template.Spec.Domain.DeepCopy()
+	return Compute{
+		CPU:       domain.CPU,
+		Memory:    domain.Memory,
+		Resources: domain.Resources,
+	}
+}
+
+// SetCompute sets the size of the VMs created from the VM spec.
+func SetCompute(spec *kubevirtv1.VirtualMachineSpec, compute Compute) {
+	if spec.Template == nil {
+		spec.Template = &kubevirtv1.VirtualMachineInstanceTemplateSpec{}
+	}
+	domain := &spec.Template.Spec.Domain
+	domain.CPU = compute.CPU.DeepCopy()
+	domain.Memory = compute.Memory.DeepCopy()
+	compute.Resources.DeepCopyInto(&domain.Resources)
+}
+
+// ResizeVirtualMachine sets the new size of the VM and restarts it by deleting its VMI, if any. It returns the
+// UID of the deleted VMI, or an empty string if the VM was not running.
+func ResizeVirtualMachine(ctx gocontext.Context, c client.Client, vm *kubevirtv1.VirtualMachine, compute Compute) (string, error) {
+	patchBase := client.MergeFrom(vm.DeepCopy())
+	SetCompute(&vm.Spec, compute)
+	if err := c.Patch(ctx, vm, patchBase); err != nil {
+		return "", errors.Wrapf(err, "failed to resize VM %s/%s", vm.Namespace, vm.Name)
+	}
+
+	return RestartVirtualMachine(ctx, c, vm)
+}
+
+// IsHotpluggable returns true if the VMs can be resized from a size to the other while they run: only the number of
+// CPU sockets and the guest memory grow, within their maximums.
+func IsHotpluggable(from, to Compute) bool {
+	if from.CPU == nil || to.CPU == nil || from.Memory == nil || to.Memory == nil || from.Memory.Guest == nil || to.Memory.Guest == nil {
+		return false
+	}
+	if to.CPU.Sockets < from.CPU.Sockets || (to.CPU.MaxSockets != 0 && to.CPU.Sockets > to.CPU.MaxSockets) {
+		return false
+	}
+	if to.Memory.Guest.Cmp(*from.Memory.Guest) < 0 || (to.Memory.MaxGuest != nil && to.Memory.Guest.Cmp(*to.Memory.MaxGuest) > 0) {
+		return false
+	}
+
+	// Nothing else may change
+	from = Compute{CPU: from.CPU.DeepCopy(), Memory: from.Memory.DeepCopy(), Resources: from.Resources}
+	from.CPU.Sockets = to.CPU.Sockets
+	from.Memory.Guest = to.Memory.Guest
+	return from.Equal(to)
+}
+
+// HotplugVirtualMachine sets the new size of the VM, which KubeVirt applies to its running VMI, live migrating it if
+// needed. It returns the UID of the VMI, or an empty string if the VM was not running.
+func HotplugVirtualMachine(ctx gocontext.Context, c client.Client, vm *kubevirtv1.VirtualMachine, compute Compute) (string, error) {
+	patchBase := client.MergeFrom(vm.DeepCopy())
+	SetCompute(&vm.Spec, compute)
+	if err := c.Patch(ctx, vm, patchBase); err != nil {
+		return "", errors.Wrapf(err, "failed to resize VM %s/%s", vm.Namespace, vm.Name)
+	}
+
+	vmi := &kubevirtv1.VirtualMachineInstance{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(vm), vmi); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", errors.Wrapf(err, "failed to fetch VMI %s/%s", vm.Namespace, vm.Name)
+	}
+	return string(vmi.UID), nil
+}
+
+// HotplugStatus returns whether the VMI with the given UID runs with the size of the VM, and whether KubeVirt
+// requires a restart of the VM to apply the size. Both are false when the VMI was restarted or is stopping.
+func HotplugStatus(ctx gocontext.Context, c client.Client, vm *kubevirtv1.VirtualMachine, vmiUID string) (bool, bool, error) {
+	vmi := &kubevirtv1.VirtualMachineInstance{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(vm), vmi); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, false, nil
+		}
+		return false, false, errors.Wrapf(err, "failed to fetch VMI %s/%s", vm.Namespace, vm.Name)
+	}
+	if string(vmi.UID) != vmiUID || vmi.DeletionTimestamp != nil {
+		return false, false, nil
+	}
+
+	for _, condition := range vm.Status.Conditions {
+		if condition.Type == kubevirtv1.VirtualMachineRestartRequired && condition.Status == corev1.ConditionTrue {
+			return false, true, nil
+		}
+	}
+
+	compute := GetCompute(&vm.Spec)
+	if compute.CPU != nil && compute.CPU.Sockets != 0 &&
+		(vmi.Status.CurrentCPUTopology == nil || vmi.Status.CurrentCPUTopology.Sockets != compute.CPU.Sockets) {
+		return false, false, nil
+	}
+	if compute.Memory != nil && compute.Memory.Guest != nil &&
+		(vmi.Status.Memory == nil || vmi.Status.Memory.GuestCurrent == nil || vmi.Status.Memory.GuestCurrent.Cmp(*compute.Memory.Guest) != 0) {
+		return false, false, nil
+	}
+	return true, false, nil
+}
+
+// RestartVirtualMachine restarts the VM by deleting its VMI, if any. It returns the UID of the deleted VMI, or an
+// empty string if the VM was not running.
+func RestartVirtualMachine(ctx gocontext.Context, c client.Client, vm *kubevirtv1.VirtualMachine) (string, error) {
+	vmi := &kubevirtv1.VirtualMachineInstance{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(vm), vmi); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", errors.Wrapf(err, "failed to fetch VMI %s/%s", vm.Namespace, vm.Name)
+	}
+
+	if err := c.Delete(ctx, vmi); err != nil && !apierrors.IsNotFound(err) {
+		return "", errors.Wrapf(err, "failed to restart VMI %s/%s", vm.Namespace, vm.Name)
+	}
+
+	return string(vmi.UID), nil
+}
+
+// IsRestarted returns true once the VM runs a new VMI, replacing the one with the given UID, and is ready.
+func IsRestarted(ctx gocontext.Context, c client.Client, vm *kubevirtv1.VirtualMachine, previousVMIUID string) (bool, error) {
+	if !vm.Status.Ready {
+		return false, nil
+	}
+
+	vmi := &kubevirtv1.VirtualMachineInstance{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(vm), vmi); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "failed to fetch VMI %s/%s", vm.Namespace, vm.Name)
+	}
+
+	return string(vmi.UID) != previousVMIUID && vmi.DeletionTimestamp == nil, nil
+}
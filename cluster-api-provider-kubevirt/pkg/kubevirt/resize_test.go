@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	gocontext "context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("Resize", func() {
+	var (
+		ctx     = gocontext.Background()
+		vmi     *kubevirtv1.VirtualMachineInstance
+		vm      *kubevirtv1.VirtualMachine
+		c       client.Client
+		compute = Compute{
+			CPU:    &kubevirtv1.CPU{Cores: 4},
+			Memory: &kubevirtv1.Memory{Guest: ptr.To(resource.MustParse("8Gi"))},
+		}
+	)
+
+	BeforeEach(func() {
+		vmi = testing.NewVirtualMachineInstance(kubevirtMachine)
+		vmi.UID = "old-vmi"
+		vm = testing.NewVirtualMachine(vmi)
+		c = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(vm, vmi).Build()
+	})
+
+	It("should set the size of a VM spec without a template", func() {
+		spec := &kubevirtv1.VirtualMachineSpec{}
+		Expect(GetCompute(spec).Equal(Compute{})).To(BeTrue())
+		SetCompute(spec, compute)
+		Expect(GetCompute(spec).Equal(compute)).To(BeTrue())
+	})
+
+	It("should resize the VM and restart its VMI", func() {
+		previousVMIUID, err := ResizeVirtualMachine(ctx, c, vm, compute)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(previousVMIUID).To(Equal("old-vmi"))
+
+		updated := &kubevirtv1.VirtualMachine{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(vm), updated)).To(Succeed())
+		Expect(GetCompute(&updated.Spec).Equal(compute)).To(BeTrue())
+
+		restarted, err := IsRestarted(ctx, c, updated, previousVMIUID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(restarted).To(BeFalse())
+	})
+
+	DescribeTable("should only hotplug growing CPU sockets and guest memory",
+		func(from, to Compute, hotpluggable bool) {
+			Expect(IsHotpluggable(from, to)).To(Equal(hotpluggable))
+		},
+		Entry("more sockets and memory",
+			Compute{CPU: &kubevirtv1.CPU{Sockets: 1}, Memory: &kubevirtv1.Memory{Guest: ptr.To(resource.MustParse("2Gi"))}},
+			Compute{CPU: &kubevirtv1.CPU{Sockets: 2}, Memory: &kubevirtv1.Memory{Guest: ptr.To(resource.MustParse("4Gi"))}},
+			true),
+		Entry("fewer sockets",
+			Compute{CPU: &kubevirtv1.CPU{Sockets: 2}, Memory: &kubevirtv1.Memory{Guest: ptr.To(resource.MustParse("2Gi"))}},
+			Compute{CPU: &kubevirtv1.CPU{Sockets: 1}, Memory: &kubevirtv1.Memory{Guest: ptr.To(resource.MustParse("2Gi"))}},
+			false),
+		Entry("less memory",
+			Compute{CPU: &kubevirtv1.CPU{Sockets: 1}, Memory: &kubevirtv1.Memory{Guest: ptr.To(resource.MustParse("4Gi"))}},
+			Compute{CPU: &kubevirtv1.CPU{Sockets: 1}, Memory: &kubevirtv1.Memory{Guest: ptr.To(resource.MustParse("2Gi"))}},
+			false),
+		Entry("more memory than the maximum",
+			Compute{CPU: &kubevirtv1.CPU{Sockets: 1}, Memory: &kubevirtv1.Memory{Guest: ptr.To(resource.MustParse("2Gi")), MaxGuest: ptr.To(resource.MustParse("4Gi"))}},
+			Compute{CPU: &kubevirtv1.CPU{Sockets: 1}, Memory: &kubevirtv1.Memory{Guest: ptr.To(resource.MustParse("8Gi")), MaxGuest: ptr.To(resource.MustParse("4Gi"))}},
+			false),
+		Entry("more cores",
+			Compute{CPU: &kubevirtv1.CPU{Sockets: 1, Cores: 1}, Memory: &kubevirtv1.Memory{Guest: ptr.To(resource.MustParse("2Gi"))}},
+			Compute{CPU: &kubevirtv1.CPU{Sockets: 1, Cores: 2}, Memory: &kubevirtv1.Memory{Guest: ptr.To(resource.MustParse("2Gi"))}},
+			false),
+		Entry("no guest memory",
+			Compute{CPU: &kubevirtv1.CPU{Sockets: 1}},
+			Compute{CPU: &kubevirtv1.CPU{Sockets: 2}},
+			false),
+	)
+
+	It("should hotplug the new size without restarting the VMI", func() {
+		hotplugged := Compute{CPU: &kubevirtv1.CPU{Sockets: 2}, Memory: &kubevirtv1.Memory{Guest: ptr.To(resource.MustParse("8Gi"))}}
+		vmiUID, err := HotplugVirtualMachine(ctx, c, vm, hotplugged)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(vmiUID).To(Equal("old-vmi"))
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(vmi), vmi)).To(Succeed())
+
+		done, restartRequired, err := HotplugStatus(ctx, c, vm, vmiUID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(done).To(BeFalse())
+		Expect(restartRequired).To(BeFalse())
+
+		vmi.Status.CurrentCPUTopology = &kubevirtv1.CPUTopology{Sockets: 2}
+		vmi.Status.Memory = &kubevirtv1.MemoryStatus{GuestCurrent: ptr.To(resource.MustParse("8Gi"))}
+		Expect(c.Update(ctx, vmi)).To(Succeed())
+		done, restartRequired, err = HotplugStatus(ctx, c, vm, vmiUID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(done).To(BeTrue())
+		Expect(restartRequired).To(BeFalse())
+	})
+
+	It("should report the hotplugs KubeVirt cannot apply live", func() {
+		vm.Status.Conditions = []kubevirtv1.VirtualMachineCondition{{Type: kubevirtv1.VirtualMachineRestartRequired, Status: corev1.ConditionTrue}}
+		done, restartRequired, err := HotplugStatus(ctx, c, vm, "old-vmi")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(done).To(BeFalse())
+		Expect(restartRequired).To(BeTrue())
+
+		// the VMI was restarted since
+		_, restartRequired, err = HotplugStatus(ctx, c, vm, "older-vmi")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(restartRequired).To(BeFalse())
+	})
+
+	It("should report the VM restarted once a new VMI is ready", func() {
+		vm.Status.Ready = true
+		restarted, err := IsRestarted(ctx, c, vm, "old-vmi")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(restarted).To(BeFalse())
+
+		restarted, err = IsRestarted(ctx, c, vm, "older-vmi")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(restarted).To(BeTrue())
+	})
+})
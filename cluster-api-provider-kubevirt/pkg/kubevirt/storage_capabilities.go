@@ -0,0 +1,302 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+// StorageCapability is a capability of a storage class of the infra cluster the disks of a VM may depend on.
+type StorageCapability string
+
+const (
+	// ReadWriteManyCapability allows the disks to be attached to the source and the target of a live migration.
+	ReadWriteManyCapability StorageCapability = "ReadWriteMany"
+
+	// VolumeExpansionCapability allows CDI to clone a disk into a larger one with the CSI clones and the snapshots.
+	VolumeExpansionCapability StorageCapability = "VolumeExpansion"
+
+	// SnapshotCapability allows to snapshot the disks, e.g. before deleting them with the SnapshotThenDelete disk
+	// retention policy.
+	SnapshotCapability StorageCapability = "Snapshot"
+)
+
+const (
+	// defaultVirtStorageClassAnnotation marks the storage class CDI uses for the disks of the VMs not requesting
+	// any, in place of the default storage class of the cluster.
+	defaultVirtStorageClassAnnotation = "storageclass.kubevirt.io/is-default-virt-class"
+	defaultStorageClassAnnotation     = "storageclass.kubernetes.io/is-default-class"
+)
+
+// honorWaitForFirstConsumerFeatureGate makes CDI wait for the first consumer of the DataVolumes of the storage
+// classes binding on first consumer, i.e. the virt-launcher pod of their VM, to populate them in the zone of its node.
+const honorWaitForFirstConsumerFeatureGate = "HonorWaitForFirstConsumer"
+
+var volumeSnapshotClassListGVK = schema.GroupVersionKind{Group: "snapshot.storage.k8s.io", Version: "v1", Kind: "VolumeSnapshotClassList"}
+
+// storageRequirement is a capability a DataVolumeTemplate of a VM requires from its storage class, and the reason.
+type storageRequirement struct {
+	dataVolume       string
+	storageClassName *string
+	capability       StorageCapability
+	reason           string
+}
+
+// requiredStorageCapabilities returns the capabilities the DataVolumeTemplates of the VM of the machine require
+// from their storage classes.
+func requiredStorageCapabilities(ctx *context.MachineContext) []storageRequirement {
+	vmTemplate := &ctx.KubevirtMachine.Spec.VirtualMachineTemplate
+	liveMigrate := false
+	if vmTemplate.Spec.Template != nil && vmTemplate.Spec.Template.Spec.EvictionStrategy != nil {
+		liveMigrate = *vmTemplate.Spec.Template.Spec.EvictionStrategy == kubevirtv1.EvictionStrategyLiveMigrate
+	}
+	snapshot := ctx.KubevirtCluster != nil && ctx.KubevirtCluster.Spec.DiskRetentionPolicy == infrav1.SnapshotThenDeleteDiskRetentionPolicy
+
+	var requirements []storageRequirement
+	imageTarget := machineImageTarget(ctx)
+	for _, template := range vmTemplate.Spec.DataVolumeTemplates {
+		var accessModes []corev1.PersistentVolumeAccessMode
+		var size resource.Quantity
+		switch {
+		case template.Spec.PVC != nil:
+			accessModes, size = template.Spec.PVC.AccessModes, template.Spec.PVC.Resources.Requests[corev1.ResourceStorage]
+		case template.Spec.Storage != nil:
+			accessModes, size = template.Spec.Storage.AccessModes, template.Spec.Storage.Resources.Requests[corev1.ResourceStorage]
+		}
+		storageClassName, _ := encryptedStorageClassName(diskEncryptionPolicy(ctx), dataVolumeTemplateStorageClassName(ctx, &template, imageTarget))
+
+		require := func(capability StorageCapability, reason string) {
+			requirements = append(requirements, storageRequirement{
+				dataVolume:       template.Name,
+				storageClassName: storageClassName,
+				capability:       capability,
+				reason:           reason,
+			})
+		}
+		if liveMigrate {
+			require(ReadWriteManyCapability, "required by the LiveMigrate eviction strategy")
+		} else if slices.Contains(accessModes, corev1.ReadWriteMany) {
+			require(ReadWriteManyCapability, "requested by the DataVolumeTemplate")
+		}
+		if template.Name == imageTarget {
+			// CDI copies the image into the disks of other storage classes, whatever their size
+			if !size.IsZero() && size.Cmp(ctx.MachineImage.Spec.Size) > 0 && ptr.Equal(storageClassName, ctx.MachineImage.Spec.StorageClassName) {
+				require(VolumeExpansionCapability, "required to clone the KubevirtMachineImage into a larger disk")
+			}
+		}
+		if snapshot {
+			require(SnapshotCapability, "required by the SnapshotThenDelete disk retention policy")
+		}
+	}
+	return requirements
+}
+
+// dataVolumeTemplateStorageClassName returns the storage class of the DataVolumeTemplate, defaulting to the one of
+// the image cloned into it, if any.
+func dataVolumeTemplateStorageClassName(ctx *context.MachineContext, template *kubevirtv1.DataVolumeTemplateSpec, imageTarget string) *string {
+	switch {
+	case template.Spec.PVC != nil:
+		return template.Spec.PVC.StorageClassName
+	case template.Spec.Storage != nil && template.Spec.Storage.StorageClassName != nil:
+		return template.Spec.Storage.StorageClassName
+	case template.Name == imageTarget:
+		return ctx.MachineImage.Spec.StorageClassName
+	}
+	return nil
+}
+
+// machineImageTarget returns the name of the DataVolumeTemplate the image of the machine is cloned into, if any.
+func machineImageTarget(ctx *context.MachineContext) string {
+	reference := ctx.KubevirtMachine.Spec.Image
+	templates := ctx.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.DataVolumeTemplates
+	if reference == nil || ctx.MachineImage == nil || len(templates) == 0 {
+		return ""
+	}
+	if reference.DataVolumeTemplate != "" {
+		return reference.DataVolumeTemplate
+	}
+	return templates[0].Name
+}
+
+// UnavailableStorageCapabilities returns a message describing the capabilities the DataVolumeTemplates of the VM
+// of the machine require and their storage classes lack in the infra cluster, or an empty string if none does. The
+// capabilities are probed from the StorageClasses, the CDI StorageProfiles and the VolumeSnapshotClasses; those
+// that cannot be read with the credentials of the infra cluster are considered available.
+func UnavailableStorageCapabilities(ctx *context.MachineContext, c client.Client) (string, error) {
+	requirements := requiredStorageCapabilities(ctx)
+	if len(requirements) == 0 {
+		return "", nil
+	}
+
+	storageClasses := &storagev1.StorageClassList{}
+	if err := c.List(ctx, storageClasses); err != nil {
+		if isUnreadable(err) {
+			return "", nil
+		}
+		return "", errors.Wrap(err, "failed to list storage classes")
+	}
+
+	var missing []string
+	for _, requirement := range requirements {
+		storageClass := findStorageClass(storageClasses.Items, requirement.storageClassName)
+		if storageClass == nil {
+			if requirement.storageClassName != nil {
+				missing = append(missing, fmt.Sprintf("DataVolumeTemplate %s: storage class %s not found", requirement.dataVolume, *requirement.storageClassName))
+			}
+			continue
+		}
+
+		supported, err := supportsStorageCapability(ctx, c, storageClass, requirement.capability)
+		if err != nil {
+			return "", err
+		}
+		if !supported {
+			missing = append(missing, fmt.Sprintf("DataVolumeTemplate %s: storage class %s does not support %s, %s",
+				requirement.dataVolume, storageClass.Name, requirement.capability, requirement.reason))
+		}
+	}
+	return strings.Join(missing, "; "), nil
+}
+
+// UnhonoredWaitForFirstConsumer returns a message describing the DataVolumeTemplates of the VM of the machine whose
+// storage class binds the volumes on first consumer while CDI does not wait for the VM to be scheduled, or an empty
+// string if none does. CDI then binds the volumes in the zone of its importer pods, where the VM may not be
+// scheduled; they are only reported when the infra nodes span several zones and the configuration of CDI is known.
+func UnhonoredWaitForFirstConsumer(ctx *context.MachineContext, c client.Client) (string, error) {
+	if ctx.KubevirtCluster == nil {
+		return "", nil
+	}
+	infra := ctx.KubevirtCluster.Status.Infra
+	if infra == nil || infra.CDIVersion == "" || len(infra.NodeZones) < 2 || slices.Contains(infra.CDIFeatureGates, honorWaitForFirstConsumerFeatureGate) {
+		return "", nil
+	}
+	templates := ctx.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.DataVolumeTemplates
+	if len(templates) == 0 {
+		return "", nil
+	}
+
+	storageClasses := &storagev1.StorageClassList{}
+	if err := c.List(ctx, storageClasses); err != nil {
+		if isUnreadable(err) {
+			return "", nil
+		}
+		return "", errors.Wrap(err, "failed to list storage classes")
+	}
+
+	var unhonored []string
+	imageTarget := machineImageTarget(ctx)
+	for _, template := range templates {
+		storageClassName, _ := encryptedStorageClassName(diskEncryptionPolicy(ctx), dataVolumeTemplateStorageClassName(ctx, &template, imageTarget))
+		storageClass := findStorageClass(storageClasses.Items, storageClassName)
+		if storageClass == nil || storageClass.VolumeBindingMode == nil || *storageClass.VolumeBindingMode != storagev1.VolumeBindingWaitForFirstConsumer {
+			continue
+		}
+		unhonored = append(unhonored, fmt.Sprintf("DataVolumeTemplate %s: storage class %s binds its volumes on first consumer", template.Name, storageClass.Name))
+	}
+	if len(unhonored) == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("%s, but the %s feature gate of CDI is disabled while the infra nodes span the zones %s",
+		strings.Join(unhonored, "; "), honorWaitForFirstConsumerFeatureGate, strings.Join(infra.NodeZones, ", ")), nil
+}
+
+// findStorageClass returns the storage class of the given name, or the default one for the VMs when nil.
+func findStorageClass(storageClasses []storagev1.StorageClass, name *string) *storagev1.StorageClass {
+	var defaultClass *storagev1.StorageClass
+	for i := range storageClasses {
+		storageClass := &storageClasses[i]
+		switch {
+		case name != nil:
+			if storageClass.Name == *name {
+				return storageClass
+			}
+		case storageClass.Annotations[defaultVirtStorageClassAnnotation] == "true":
+			return storageClass
+		case storageClass.Annotations[defaultStorageClassAnnotation] == "true" && defaultClass == nil:
+			defaultClass = storageClass
+		}
+	}
+	return defaultClass
+}
+
+// supportsStorageCapability returns false if the storage class is known not to provide the capability.
+func supportsStorageCapability(ctx *context.MachineContext, c client.Client, storageClass *storagev1.StorageClass, capability StorageCapability) (bool, error) {
+	profile := &cdiv1.StorageProfile{}
+	if err := c.Get(ctx, client.ObjectKey{Name: storageClass.Name}, profile); err != nil {
+		if !isUnreadable(err) {
+			return false, errors.Wrapf(err, "failed to fetch StorageProfile %s", storageClass.Name)
+		}
+		profile = nil
+	}
+
+	switch capability {
+	case ReadWriteManyCapability:
+		// CDI leaves the claim properties of the storage classes it does not know empty
+		if profile == nil || len(profile.Status.ClaimPropertySets) == 0 {
+			return true, nil
+		}
+		for _, claimPropertySet := range profile.Status.ClaimPropertySets {
+			if slices.Contains(claimPropertySet.AccessModes, corev1.ReadWriteMany) {
+				return true, nil
+			}
+		}
+		return false, nil
+
+	case VolumeExpansionCapability:
+		// The host-assisted clones copy the image into a disk of any size
+		if profile == nil || profile.Status.CloneStrategy == nil || *profile.Status.CloneStrategy == cdiv1.CloneStrategyHostAssisted {
+			return true, nil
+		}
+		return storageClass.AllowVolumeExpansion != nil && *storageClass.AllowVolumeExpansion, nil
+
+	case SnapshotCapability:
+		if profile != nil && profile.Status.SnapshotClass != nil {
+			return true, nil
+		}
+		snapshotClasses := &unstructured.UnstructuredList{}
+		snapshotClasses.SetGroupVersionKind(volumeSnapshotClassListGVK)
+		if err := c.List(ctx, snapshotClasses); err != nil {
+			if isUnreadable(err) {
+				return true, nil
+			}
+			return false, errors.Wrap(err, "failed to list VolumeSnapshotClasses")
+		}
+		for _, snapshotClass := range snapshotClasses.Items {
+			if driver, _, _ := unstructured.NestedString(snapshotClass.Object, "driver"); driver == storageClass.Provisioner {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	gocontext "context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("Storage capabilities", func() {
+	var machineContext *context.MachineContext
+
+	newStorageClass := func(name string, allowExpansion bool, annotations map[string]string) *storagev1.StorageClass {
+		return &storagev1.StorageClass{
+			ObjectMeta:           metav1.ObjectMeta{Name: name, Annotations: annotations},
+			Provisioner:          name + ".csi.example.com",
+			AllowVolumeExpansion: ptr.To(allowExpansion),
+		}
+	}
+
+	newStorageProfile := func(name string, accessMode corev1.PersistentVolumeAccessMode, cloneStrategy cdiv1.CDICloneStrategy) *cdiv1.StorageProfile {
+		profile := &cdiv1.StorageProfile{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		profile.Status.ClaimPropertySets = []cdiv1.ClaimPropertySet{{AccessModes: []corev1.PersistentVolumeAccessMode{accessMode}}}
+		profile.Status.CloneStrategy = ptr.To(cloneStrategy)
+		return profile
+	}
+
+	probe := func(objects ...client.Object) string {
+		c := fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(objects...).Build()
+		message, err := UnavailableStorageCapabilities(machineContext, c)
+		Expect(err).ToNot(HaveOccurred())
+		return message
+	}
+
+	BeforeEach(func() {
+		machineContext = &context.MachineContext{
+			Context:         gocontext.Background(),
+			Cluster:         cluster,
+			KubevirtCluster: kubevirtCluster.DeepCopy(),
+			Machine:         machine,
+			KubevirtMachine: kubevirtMachine.DeepCopy(),
+		}
+		machineContext.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.DataVolumeTemplates = []kubevirtv1.DataVolumeTemplateSpec{{
+			ObjectMeta: metav1.ObjectMeta{Name: "root"},
+			Spec: cdiv1.DataVolumeSpec{
+				Storage: &cdiv1.StorageSpec{
+					StorageClassName: ptr.To("fast"),
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("20Gi")},
+					},
+				},
+			},
+		}}
+	})
+
+	It("should require ReadWriteMany from the storage classes of the live migratable VMs", func() {
+		machineContext.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.Template.Spec.EvictionStrategy = ptr.To(kubevirtv1.EvictionStrategyLiveMigrate)
+
+		Expect(probe(newStorageClass("fast", true, nil), newStorageProfile("fast", corev1.ReadWriteOnce, cdiv1.CloneStrategyCsiClone))).To(Equal(
+			"DataVolumeTemplate root: storage class fast does not support ReadWriteMany, required by the LiveMigrate eviction strategy"))
+		Expect(probe(newStorageClass("fast", true, nil), newStorageProfile("fast", corev1.ReadWriteMany, cdiv1.CloneStrategyCsiClone))).To(BeEmpty())
+		// the capabilities of the storage classes CDI does not know are unknown
+		Expect(probe(newStorageClass("fast", true, nil), &cdiv1.StorageProfile{ObjectMeta: metav1.ObjectMeta{Name: "fast"}})).To(BeEmpty())
+	})
+
+	It("should require the expansion of the storage class cloning the image into a larger disk", func() {
+		machineContext.KubevirtMachine.Spec.Image = &infrav1.MachineImageReference{Name: "ubuntu"}
+		machineContext.MachineImage = &infrav1.KubevirtMachineImage{
+			Spec: infrav1.KubevirtMachineImageSpec{Size: resource.MustParse("10Gi"), StorageClassName: ptr.To("fast")},
+		}
+
+		Expect(probe(newStorageClass("fast", false, nil), newStorageProfile("fast", corev1.ReadWriteOnce, cdiv1.CloneStrategyCsiClone))).To(Equal(
+			"DataVolumeTemplate root: storage class fast does not support VolumeExpansion, required to clone the KubevirtMachineImage into a larger disk"))
+		Expect(probe(newStorageClass("fast", true, nil), newStorageProfile("fast", corev1.ReadWriteOnce, cdiv1.CloneStrategyCsiClone))).To(BeEmpty())
+		Expect(probe(newStorageClass("fast", false, nil), newStorageProfile("fast", corev1.ReadWriteOnce, cdiv1.CloneStrategyHostAssisted))).To(BeEmpty())
+	})
+
+	It("should require snapshots from the storage classes of the clusters snapshotting the disks", func() {
+		machineContext.KubevirtCluster.Spec.DiskRetentionPolicy = infrav1.SnapshotThenDeleteDiskRetentionPolicy
+		snapshotClass := &unstructured.Unstructured{}
+		snapshotClass.SetAPIVersion("snapshot.storage.k8s.io/v1")
+		snapshotClass.SetKind("VolumeSnapshotClass")
+		snapshotClass.SetName("fast")
+		snapshotClass.Object["driver"] = "fast.csi.example.com"
+		snapshotClass.Object["deletionPolicy"] = "Delete"
+
+		Expect(probe(newStorageClass("fast", true, nil))).To(Equal(
+			"DataVolumeTemplate root: storage class fast does not support Snapshot, required by the SnapshotThenDelete disk retention policy"))
+		Expect(probe(newStorageClass("fast", true, nil), snapshotClass)).To(BeEmpty())
+	})
+
+	It("should probe the default storage class of the VMs, and report the missing storage classes", func() {
+		machineContext.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.Template.Spec.EvictionStrategy = ptr.To(kubevirtv1.EvictionStrategyLiveMigrate)
+		machineContext.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.DataVolumeTemplates[0].Spec.Storage.StorageClassName = nil
+
+		Expect(probe(
+			newStorageClass("standard", true, map[string]string{defaultStorageClassAnnotation: "true"}),
+			newStorageClass("shared", true, map[string]string{defaultVirtStorageClassAnnotation: "true"}),
+			newStorageProfile("standard", corev1.ReadWriteOnce, cdiv1.CloneStrategyCsiClone),
+			newStorageProfile("shared", corev1.ReadWriteMany, cdiv1.CloneStrategyCsiClone),
+		)).To(BeEmpty())
+
+		machineContext.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.DataVolumeTemplates[0].Spec.Storage.StorageClassName = ptr.To("fast")
+		Expect(probe()).To(Equal("DataVolumeTemplate root: storage class fast not found"))
+	})
+
+	It("should probe the encrypted storage classes the disks are created with", func() {
+		machineContext.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.Template.Spec.EvictionStrategy = ptr.To(kubevirtv1.EvictionStrategyLiveMigrate)
+		machineContext.KubevirtCluster.Spec.DiskEncryption = &infrav1.DiskEncryptionSpec{
+			Mode:           infrav1.StorageClassDiskEncryptionMode,
+			StorageClasses: map[string]string{"fast": "fast-encrypted"},
+		}
+
+		Expect(probe(newStorageClass("fast-encrypted", true, nil), newStorageProfile("fast-encrypted", corev1.ReadWriteOnce, cdiv1.CloneStrategyCsiClone))).To(Equal(
+			"DataVolumeTemplate root: storage class fast-encrypted does not support ReadWriteMany, required by the LiveMigrate eviction strategy"))
+	})
+
+	It("should not require anything from the VMs without DataVolumeTemplates", func() {
+		machineContext.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.DataVolumeTemplates = nil
+		machineContext.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.Template.Spec.EvictionStrategy = ptr.To(kubevirtv1.EvictionStrategyLiveMigrate)
+
+		Expect(probe()).To(BeEmpty())
+	})
+
+	Context("volumes binding on first consumer", func() {
+		newWFFCStorageClass := func(name string) *storagev1.StorageClass {
+			storageClass := newStorageClass(name, true, nil)
+			storageClass.VolumeBindingMode = ptr.To(storagev1.VolumeBindingWaitForFirstConsumer)
+			return storageClass
+		}
+
+		probeBinding := func(objects ...client.Object) string {
+			c := fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(objects...).Build()
+			message, err := UnhonoredWaitForFirstConsumer(machineContext, c)
+			Expect(err).ToNot(HaveOccurred())
+			return message
+		}
+
+		BeforeEach(func() {
+			machineContext.KubevirtCluster.Status.Infra = &infrav1.InfraStatus{
+				CDIVersion: "v1.59.0",
+				NodeZones:  []string{"zone-a", "zone-b"},
+			}
+		})
+
+		It("should report the storage classes binding on first consumer while CDI does not wait for the VM", func() {
+			Expect(probeBinding(newWFFCStorageClass("fast"))).To(Equal(
+				"DataVolumeTemplate root: storage class fast binds its volumes on first consumer, but the HonorWaitForFirstConsumer " +
+					"feature gate of CDI is disabled while the infra nodes span the zones zone-a, zone-b"))
+			Expect(probeBinding(newStorageClass("fast", true, nil))).To(BeEmpty())
+		})
+
+		It("should not report the volumes when CDI waits for the VM", func() {
+			machineContext.KubevirtCluster.Status.Infra.CDIFeatureGates = []string{"HonorWaitForFirstConsumer"}
+
+			Expect(probeBinding(newWFFCStorageClass("fast"))).To(BeEmpty())
+		})
+
+		It("should not report the volumes of the single zone infra clusters, or when CDI is unknown", func() {
+			machineContext.KubevirtCluster.Status.Infra.NodeZones = []string{"zone-a"}
+			Expect(probeBinding(newWFFCStorageClass("fast"))).To(BeEmpty())
+
+			machineContext.KubevirtCluster.Status.Infra = &infrav1.InfraStatus{NodeZones: []string{"zone-a", "zone-b"}}
+			Expect(probeBinding(newWFFCStorageClass("fast"))).To(BeEmpty())
+		})
+
+		It("should probe the storage class the image is cloned into", func() {
+			machineContext.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.DataVolumeTemplates[0].Spec.Storage.StorageClassName = nil
+			machineContext.KubevirtMachine.Spec.Image = &infrav1.MachineImageReference{Name: "ubuntu"}
+			machineContext.MachineImage = &infrav1.KubevirtMachineImage{
+				Spec: infrav1.KubevirtMachineImageSpec{Size: resource.MustParse("10Gi"), StorageClassName: ptr.To("zonal")},
+			}
+
+			Expect(probeBinding(newWFFCStorageClass("zonal"))).To(ContainSubstring("storage class zonal binds its volumes on first consumer"))
+		})
+	})
+})
@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	gocontext "context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Usage is the amount of resources of the infra cluster consumed by VMs.
+type Usage struct {
+	CPU     resource.Quantity
+	Memory  resource.Quantity
+	Storage resource.Quantity
+}
+
+// Add adds the usage of another VM.
+func (u *Usage) Add(other Usage) {
+	u.CPU.Add(other.CPU)
+	u.Memory.Add(other.Memory)
+	u.Storage.Add(other.Storage)
+}
+
+// GetVirtualMachineUsage returns the resources consumed by the VM: the vCPUs and the guest memory of its VMI, if
+// running, and the capacity of the PVCs of its disks.
+func GetVirtualMachineUsage(ctx gocontext.Context, c client.Client, vm *kubevirtv1.VirtualMachine) (Usage, error) {
+	usage := Usage{}
+
+	// The spec of the VMI has the size of the VM expanded from its instance type, if any
+	if vm.Status.Created {
+		vmi := &kubevirtv1.VirtualMachineInstance{}
+		if err := c.Get(ctx, client.ObjectKeyFromObject(vm), vmi); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return Usage{}, errors.Wrapf(err, "failed to get VMI %s/%s", vm.Namespace, vm.Name)
+			}
+		} else if !vmi.IsFinal() {
+			usage.CPU = *resource.NewQuantity(vCPUs(&vmi.Spec.Domain), resource.DecimalSI)
+			usage.Memory = guestMemory(&vmi.Spec.Domain)
+		}
+	}
+
+	var claimNames []string
+	for _, template := range vm.Spec.DataVolumeTemplates {
+		claimNames = append(claimNames, template.Name)
+	}
+	if vm.Spec.Template != nil {
+		for _, volume := range vm.Spec.Template.Spec.Volumes {
+			if volume.PersistentVolumeClaim != nil {
+				claimNames = append(claimNames, volume.PersistentVolumeClaim.ClaimName)
+			}
+		}
+	}
+	for _, claimName := range claimNames {
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := c.Get(ctx, client.ObjectKey{Namespace: vm.Namespace, Name: claimName}, pvc); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return Usage{}, errors.Wrapf(err, "failed to get PVC %s/%s", vm.Namespace, claimName)
+		}
+		if capacity, found := pvc.Status.Capacity[corev1.ResourceStorage]; found {
+			usage.Storage.Add(capacity)
+		} else if request, found := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; found {
+			usage.Storage.Add(request)
+		}
+	}
+
+	return usage, nil
+}
+
+// vCPUs returns the number of vCPUs of the domain, from its CPU topology or, if not set, its CPU resources.
+func vCPUs(domain *kubevirtv1.DomainSpec) int64 {
+	if cpu := domain.CPU; cpu != nil && (cpu.Sockets != 0 || cpu.Cores != 0 || cpu.Threads != 0) {
+		return int64(max(cpu.Sockets, 1)) * int64(max(cpu.Cores, 1)) * int64(max(cpu.Threads, 1))
+	}
+	if limit, found := domain.Resources.Limits[corev1.ResourceCPU]; found {
+		return limit.Value()
+	}
+	if request, found := domain.Resources.Requests[corev1.ResourceCPU]; found {
+		return request.Value()
+	}
+	return 1
+}
+
+// guestMemory returns the memory of the guest of the domain, or its memory request if not set.
+func guestMemory(domain *kubevirtv1.DomainSpec) resource.Quantity {
+	if domain.Memory != nil && domain.Memory.Guest != nil {
+		return domain.Memory.Guest.DeepCopy()
+	}
+	return domain.Resources.Requests.Memory().DeepCopy()
+}
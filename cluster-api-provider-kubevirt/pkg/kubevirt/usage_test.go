@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirt
+
+import (
+	gocontext "context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("Usage", func() {
+	var (
+		ctx = gocontext.Background()
+		vmi *kubevirtv1.VirtualMachineInstance
+		vm  *kubevirtv1.VirtualMachine
+		pvc *corev1.PersistentVolumeClaim
+	)
+
+	BeforeEach(func() {
+		vmi = testing.NewVirtualMachineInstance(kubevirtMachine)
+		vmi.Spec.Domain.CPU = &kubevirtv1.CPU{Sockets: 2, Cores: 2}
+		vmi.Spec.Domain.Memory = &kubevirtv1.Memory{Guest: ptr.To(resource.MustParse("4Gi"))}
+		vm = testing.NewVirtualMachine(vmi)
+		vm.Status.Created = true
+		vm.Spec.DataVolumeTemplates = []kubevirtv1.DataVolumeTemplateSpec{{ObjectMeta: metav1.ObjectMeta{Name: "root"}}}
+		pvc = &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: vm.Namespace, Name: "root"},
+			Status: corev1.PersistentVolumeClaimStatus{
+				Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("20Gi")},
+			},
+		}
+	})
+
+	getUsage := func(objects ...client.Object) Usage {
+		c := fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(objects...).Build()
+		usage, err := GetVirtualMachineUsage(ctx, c, vm)
+		Expect(err).ToNot(HaveOccurred())
+		return usage
+	}
+
+	It("should count the vCPUs, the guest memory and the disks of a running VM", func() {
+		usage := getUsage(vm, vmi, pvc)
+		Expect(usage.CPU.Value()).To(Equal(int64(4)))
+		Expect(usage.Memory.Equal(resource.MustParse("4Gi"))).To(BeTrue())
+		Expect(usage.Storage.Equal(resource.MustParse("20Gi"))).To(BeTrue())
+	})
+
+	It("should only count the disks of a stopped VM", func() {
+		vm.Status.Created = false
+		usage := getUsage(vm, pvc)
+		Expect(usage.CPU.IsZero()).To(BeTrue())
+		Expect(usage.Memory.IsZero()).To(BeTrue())
+		Expect(usage.Storage.Equal(resource.MustParse("20Gi"))).To(BeTrue())
+	})
+
+	It("should fall back to the resources of the domain and the requests of the PVCs", func() {
+		vmi.Spec.Domain.CPU = nil
+		vmi.Spec.Domain.Memory = nil
+		vmi.Spec.Domain.Resources.Requests = corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("3"),
+			corev1.ResourceMemory: resource.MustParse("2Gi"),
+		}
+		pvc.Status.Capacity = nil
+		pvc.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}
+
+		usage := getUsage(vm, vmi, pvc)
+		Expect(usage.CPU.Value()).To(Equal(int64(3)))
+		Expect(usage.Memory.Equal(resource.MustParse("2Gi"))).To(BeTrue())
+		Expect(usage.Storage.Equal(resource.MustParse("10Gi"))).To(BeTrue())
+	})
+})
@@ -25,9 +25,14 @@ import (
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/kind/pkg/cluster/constants"
 
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/resources"
 )
 
+// cloudInitVolumeName is the name of the volume, and disk, of the VMs holding the bootstrap data.
+const cloudInitVolumeName = "cloudinitvolume"
+
 type CommandExecutor interface {
 	ExecuteCommand(command string) (string, error)
 }
@@ -93,11 +98,20 @@ func newVirtualMachineFromKubevirtMachine(ctx *context.MachineContext, namespace
 	if ctx.KubevirtMachine.Spec.VirtualMachineTemplate.ObjectMeta.Annotations != nil {
 		virtualMachine.ObjectMeta.Annotations = mapCopy(ctx.KubevirtMachine.Spec.VirtualMachineTemplate.ObjectMeta.Annotations)
 	}
+	resources.PropagateMetadata(&virtualMachine.ObjectMeta, ctx.Cluster, ctx.KubevirtCluster)
 
 	virtualMachine.ObjectMeta.Labels["kubevirt.io/vm"] = ctx.KubevirtMachine.Name
 	virtualMachine.ObjectMeta.Labels["name"] = ctx.KubevirtMachine.Name
 	virtualMachine.ObjectMeta.Labels["cluster.x-k8s.io/role"] = nodeRole(ctx)
 	virtualMachine.ObjectMeta.Labels["cluster.x-k8s.io/cluster-name"] = ctx.Cluster.Name
+	if ctx.KubevirtMachine.Spec.Preemptible != nil {
+		virtualMachine.ObjectMeta.Labels[infrav1.PreemptibleLabel] = "true"
+	}
+
+	useMachineImage(virtualMachine, ctx)
+	shareDataVolumeSources(virtualMachine, ctx)
+	setDiskDiscard(virtualMachine, ctx)
+	setDiskEncryption(virtualMachine, ctx)
 
 	// make each datavolume unique by appending machine name as a prefix
 	virtualMachine = prefixDataVolumeTemplates(virtualMachine, ctx.KubevirtMachine.Name)
@@ -130,6 +144,7 @@ func buildVirtualMachineInstanceTemplate(ctx *context.MachineContext) *kubevirtv
 	if ctx.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.Template.ObjectMeta.Annotations != nil {
 		template.ObjectMeta.Annotations = mapCopy(ctx.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.Template.ObjectMeta.Annotations)
 	}
+	resources.PropagateMetadata(&template.ObjectMeta, ctx.Cluster, ctx.KubevirtCluster)
 
 	template.ObjectMeta.Labels["kubevirt.io/vm"] = ctx.KubevirtMachine.Name
 	template.ObjectMeta.Labels["name"] = ctx.KubevirtMachine.Name
@@ -137,19 +152,36 @@ func buildVirtualMachineInstanceTemplate(ctx *context.MachineContext) *kubevirtv
 	template.ObjectMeta.Labels["cluster.x-k8s.io/cluster-name"] = ctx.Cluster.Name
 
 	template.Spec = *ctx.KubevirtMachine.Spec.VirtualMachineTemplate.Spec.Template.Spec.DeepCopy()
+	setArchitecture(&template.Spec, ctx)
+	setDiskTuning(&template.Spec, ctx)
+	setMemoryOvercommit(template, ctx)
+	setMigrationPolicy(template, ctx)
+	setDeschedulerEviction(template, ctx)
+	setGPUs(&template.Spec, ctx)
+	setGuestAgent(&template.Spec, ctx)
+	if ctx.KubevirtCluster != nil {
+		resources.SetPodServiceMesh(&template.ObjectMeta, ctx.KubevirtCluster.Spec.ServiceMesh)
+	}
+	if ctx.KubevirtMachine.Spec.RequiresNestedVirtualization {
+		requireNestedVirtualization(&template.Spec)
+	}
+	if ctx.KubevirtMachine.Spec.Preemptible != nil {
+		template.ObjectMeta.Labels[infrav1.PreemptibleLabel] = "true"
+		makePreemptible(&template.Spec, ctx.KubevirtMachine.Spec.Preemptible)
+	}
 
-	cloudInitVolumeName := "cloudinitvolume"
 	cloudInitVolume := kubevirtv1.Volume{
 		Name: cloudInitVolumeName,
 		VolumeSource: kubevirtv1.VolumeSource{
 			CloudInitConfigDrive: &kubevirtv1.CloudInitConfigDriveSource{
 				UserDataSecretRef: &corev1.LocalObjectReference{
-					Name: *ctx.Machine.Spec.Bootstrap.DataSecretName + "-userdata",
+					Name: UserDataSecretName(ctx),
 				},
 			},
 		},
 	}
 	template.Spec.Volumes = append(template.Spec.Volumes, cloudInitVolume)
+	setBootstrapData(template, UserDataSecretName(ctx), bootstrapDataHash(ctx))
 
 	cloudInitDisk := kubevirtv1.Disk{
 		Name: cloudInitVolumeName,
@@ -164,6 +196,11 @@ func buildVirtualMachineInstanceTemplate(ctx *context.MachineContext) *kubevirtv
 	return template
 }
 
+// UserDataSecretName returns the name of the secret holding the bootstrap data of the VM in the infra cluster.
+func UserDataSecretName(ctx *context.MachineContext) string {
+	return resources.InfraName(ctx.KubevirtCluster, *ctx.Machine.Spec.Bootstrap.DataSecretName+"-userdata")
+}
+
 // nodeRole returns the role of this node ("control-plane" or "worker").
 func nodeRole(ctx *context.MachineContext) string {
 	if util.IsControlPlaneMachine(ctx.Machine) {
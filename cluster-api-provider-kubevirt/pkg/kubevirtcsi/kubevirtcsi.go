@@ -0,0 +1,420 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kubevirtcsi deploys the KubeVirt CSI driver of a workload cluster. Its controller service runs next to
+// the KubevirtCluster, and provisions the volumes of the workload cluster as DataVolumes of the infra cluster
+// hotplugged to the VMs; its node service runs on the Nodes of the workload cluster.
+package kubevirtcsi
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/hibernation"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/resources"
+)
+
+const (
+	// DefaultImage is the default image of the CSI driver.
+	DefaultImage = "quay.io/kubevirt/kubevirt-csi-driver:latest"
+
+	// DriverName is the name of the CSI driver, and the provisioner of its storage classes.
+	DriverName = "csi.kubevirt.io"
+
+	// NodeNamespace is the namespace of the node service of the CSI driver in the workload cluster.
+	NodeNamespace = "kubevirt-csi-driver"
+
+	provisionerImage = "registry.k8s.io/sig-storage/csi-provisioner:v3.5.0"
+	attacherImage    = "registry.k8s.io/sig-storage/csi-attacher:v4.3.0"
+	registrarImage   = "registry.k8s.io/sig-storage/csi-node-driver-registrar:v2.8.0"
+
+	// templateKindLabel marks the objects deleted with the cluster by the KubevirtCluster controller.
+	templateKindLabel = "capk.cluster.x-k8s.io/template-kind"
+	appLabel          = "app"
+	controllerAppName = "kubevirt-csi-controller"
+	nodeAppName       = "kubevirt-csi-node"
+
+	// infraStorageClassParameter is the parameter of the storage classes of the workload cluster naming the
+	// storage class of the infra cluster.
+	infraStorageClassParameter = "infraStorageClassName"
+	defaultStorageClassKey     = "storageclass.kubernetes.io/is-default-class"
+
+	socketDir             = "/csi"
+	kubeconfigDir         = "/var/run/secrets/tenantcluster"
+	infraKubeconfigDir    = "/var/run/secrets/infracluster"
+	workloadKubeconfigKey = "value"
+	infraKubeconfigKey    = "kubeconfig"
+	kubeletDir            = "/var/lib/kubelet"
+)
+
+// Name returns the name of the objects of the controller service of the CSI driver of a cluster.
+func Name(cluster *clusterv1.Cluster) string {
+	return cluster.Name + "-csi"
+}
+
+// ReconcileController creates or updates the controller service of the CSI driver of the cluster in the namespace
+// of the cluster, provisioning the volumes in the given infra namespace. When the infra cluster is the management
+// cluster, the controller runs with a service account bound to the permissions it needs; otherwise, it uses the
+// infra credentials of the cluster, which must be in a secret of the same namespace. The existing objects are read
+// with the reader, so that they do not need to be cached.
+func ReconcileController(ctx *context.ClusterContext, reader client.Reader, c client.Client, infraNamespace string) error {
+	infraSecretRef := ctx.KubevirtCluster.Spec.InfraClusterSecretRef
+	if infraSecretRef != nil && infraSecretRef.Namespace != "" && infraSecretRef.Namespace != ctx.KubevirtCluster.Namespace {
+		return fmt.Errorf("the infra cluster secret must be in the namespace of the cluster to deploy the CSI driver")
+	}
+
+	name := Name(ctx.Cluster)
+	labels := map[string]string{
+		clusterv1.ClusterNameLabel: ctx.Cluster.Name,
+		templateKindLabel:          "extra-resource",
+		appLabel:                   controllerAppName,
+	}
+
+	serviceAccountName := ""
+	if infraSecretRef == nil {
+		serviceAccountName = name
+		if err := reconcileControllerRBAC(ctx, reader, c, name, labels); err != nil {
+			return err
+		}
+	}
+
+	template := controllerPodTemplate(ctx, infraNamespace, serviceAccountName, labels)
+	templateHash, err := resources.TemplateHash(template)
+	if err != nil {
+		return err
+	}
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: ctx.Cluster.Namespace, Name: name}}
+	return resources.CreateOrUpdate(ctx, reader, c, "Deployment", deployment, func() {
+		deployment.SetLabels(labels)
+		deployment.Spec.Replicas = ptr.To(replicas(ctx.KubevirtCluster))
+		if deployment.Spec.Selector == nil {
+			deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{
+				clusterv1.ClusterNameLabel: ctx.Cluster.Name,
+				appLabel:                   controllerAppName,
+			}}
+		}
+		resources.SetPodTemplate(deployment, &deployment.Spec.Template, template, templateHash)
+	})
+}
+
+// reconcileControllerRBAC grants the service account of the controller service the permissions it needs to
+// provision the volumes in the infra namespace and to hotplug them to the VMs.
+func reconcileControllerRBAC(ctx *context.ClusterContext, reader client.Reader, c client.Client, name string, labels map[string]string) error {
+	serviceAccount := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: ctx.Cluster.Namespace, Name: name}}
+	if err := resources.CreateOrUpdate(ctx, reader, c, "ServiceAccount", serviceAccount, func() {
+		serviceAccount.SetLabels(labels)
+	}); err != nil {
+		return err
+	}
+
+	role := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Namespace: ctx.Cluster.Namespace, Name: name}}
+	if err := resources.CreateOrUpdate(ctx, reader, c, "Role", role, func() {
+		role.SetLabels(labels)
+		role.Rules = []rbacv1.PolicyRule{
+			{APIGroups: []string{"cdi.kubevirt.io"}, Resources: []string{"datavolumes"}, Verbs: []string{"get", "create", "delete"}},
+			{APIGroups: []string{"kubevirt.io"}, Resources: []string{"virtualmachineinstances", "virtualmachines"}, Verbs: []string{"get", "list"}},
+			{APIGroups: []string{"subresources.kubevirt.io"}, Resources: []string{"virtualmachineinstances/addvolume", "virtualmachineinstances/removevolume"}, Verbs: []string{"update"}},
+			{APIGroups: []string{""}, Resources: []string{"persistentvolumeclaims"}, Verbs: []string{"get"}},
+		}
+	}); err != nil {
+		return err
+	}
+
+	roleBinding := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Namespace: ctx.Cluster.Namespace, Name: name}}
+	return resources.CreateOrUpdate(ctx, reader, c, "RoleBinding", roleBinding, func() {
+		roleBinding.SetLabels(labels)
+		roleBinding.RoleRef = rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: name}
+		roleBinding.Subjects = []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Namespace: ctx.Cluster.Namespace, Name: name}}
+	})
+}
+
+// controllerPodTemplate returns the pod template of the controller service deployment, running the CSI driver
+// with the provisioner and attacher sidecars of the workload cluster.
+func controllerPodTemplate(ctx *context.ClusterContext, infraNamespace, serviceAccountName string, labels map[string]string) corev1.PodTemplateSpec {
+	workloadKubeconfig := "--kubeconfig=" + path.Join(kubeconfigDir, workloadKubeconfigKey)
+	socketMount := corev1.VolumeMount{Name: "socket-dir", MountPath: socketDir}
+	kubeconfigMount := corev1.VolumeMount{Name: "tenantcluster", MountPath: kubeconfigDir, ReadOnly: true}
+
+	driver := corev1.Container{
+		Name:  "csi-driver",
+		Image: image(ctx.KubevirtCluster.Spec.CSIDriver),
+		Args: []string{
+			"--endpoint=unix://" + path.Join(socketDir, "csi.sock"),
+			"--infra-cluster-namespace=" + infraNamespace,
+			"--infra-cluster-labels=" + clusterv1.ClusterNameLabel + "=" + ctx.Cluster.Name,
+			"--tenant-cluster-kubeconfig=" + path.Join(kubeconfigDir, workloadKubeconfigKey),
+			"--run-node-service=false",
+			"--run-controller-service=true",
+		},
+		VolumeMounts: []corev1.VolumeMount{socketMount, kubeconfigMount},
+	}
+	volumes := []corev1.Volume{
+		{Name: "socket-dir", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+		{Name: "tenantcluster", VolumeSource: corev1.VolumeSource{Secret: resources.WorkloadKubeconfigVolumeSource(ctx.Cluster.Name, ctx.KubevirtCluster)}},
+	}
+	if secretRef := ctx.KubevirtCluster.Spec.InfraClusterSecretRef; secretRef != nil {
+		driver.Args = append(driver.Args, "--infra-cluster-kubeconfig="+path.Join(infraKubeconfigDir, infraKubeconfigKey))
+		driver.VolumeMounts = append(driver.VolumeMounts, corev1.VolumeMount{Name: "infracluster", MountPath: infraKubeconfigDir, ReadOnly: true})
+		volumes = append(volumes, corev1.Volume{Name: "infracluster", VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: secretRef.Name},
+		}})
+	}
+
+	sidecarArgs := []string{"--csi-address=" + path.Join(socketDir, "csi.sock"), workloadKubeconfig, "--timeout=3m"}
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: labels},
+		Spec: corev1.PodSpec{
+			ServiceAccountName:           serviceAccountName,
+			AutomountServiceAccountToken: ptr.To(serviceAccountName != ""),
+			Containers: []corev1.Container{
+				driver,
+				{
+					Name:         "csi-provisioner",
+					Image:        provisionerImage,
+					Args:         append([]string{"--default-fstype=ext4"}, sidecarArgs...),
+					VolumeMounts: []corev1.VolumeMount{socketMount, kubeconfigMount},
+				},
+				{
+					Name:         "csi-attacher",
+					Image:        attacherImage,
+					Args:         sidecarArgs,
+					VolumeMounts: []corev1.VolumeMount{socketMount, kubeconfigMount},
+				},
+			},
+			Volumes: volumes,
+		},
+	}
+	resources.SetPodServiceMesh(&template.ObjectMeta, ctx.KubevirtCluster.Spec.ServiceMesh)
+	return template
+}
+
+// ReconcileNode creates or updates the CSI driver, its node service and the mapped storage classes in the workload
+// cluster, and deletes the storage classes no longer mapped. The parameters of an existing storage class are
+// immutable; a storage class mapped to another infra storage class must be deleted to be recreated.
+func ReconcileNode(ctx *context.ClusterContext, workloadClient client.Client) error {
+	labels := map[string]string{
+		clusterv1.ClusterNameLabel: ctx.Cluster.Name,
+		appLabel:                   nodeAppName,
+	}
+
+	csiDriver := &storagev1.CSIDriver{ObjectMeta: metav1.ObjectMeta{Name: DriverName}}
+	if err := resources.CreateOrUpdate(ctx, workloadClient, workloadClient, "CSIDriver", csiDriver, func() {
+		csiDriver.SetLabels(labels)
+		csiDriver.Spec.AttachRequired = ptr.To(true)
+		csiDriver.Spec.PodInfoOnMount = ptr.To(true)
+	}); err != nil {
+		return err
+	}
+
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: NodeNamespace}}
+	if err := resources.CreateOrUpdate(ctx, workloadClient, workloadClient, "Namespace", namespace, func() {
+		namespace.SetLabels(labels)
+	}); err != nil {
+		return err
+	}
+
+	if err := reconcileNodeRBAC(ctx, workloadClient, labels); err != nil {
+		return err
+	}
+
+	template := nodePodTemplate(ctx, labels)
+	templateHash, err := resources.TemplateHash(template)
+	if err != nil {
+		return err
+	}
+	daemonSet := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Namespace: NodeNamespace, Name: nodeAppName}}
+	if err := resources.CreateOrUpdate(ctx, workloadClient, workloadClient, "DaemonSet", daemonSet, func() {
+		daemonSet.SetLabels(labels)
+		if daemonSet.Spec.Selector == nil {
+			daemonSet.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{appLabel: nodeAppName}}
+		}
+		resources.SetPodTemplate(daemonSet, &daemonSet.Spec.Template, template, templateHash)
+	}); err != nil {
+		return err
+	}
+
+	return reconcileStorageClasses(ctx, workloadClient, labels)
+}
+
+// reconcileNodeRBAC grants the service account of the node service the permissions it needs in the workload
+// cluster.
+func reconcileNodeRBAC(ctx *context.ClusterContext, workloadClient client.Client, labels map[string]string) error {
+	serviceAccount := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: NodeNamespace, Name: nodeAppName}}
+	if err := resources.CreateOrUpdate(ctx, workloadClient, workloadClient, "ServiceAccount", serviceAccount, func() {
+		serviceAccount.SetLabels(labels)
+	}); err != nil {
+		return err
+	}
+
+	clusterRole := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: nodeAppName}}
+	if err := resources.CreateOrUpdate(ctx, workloadClient, workloadClient, "ClusterRole", clusterRole, func() {
+		clusterRole.SetLabels(labels)
+		clusterRole.Rules = []rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"nodes", "persistentvolumes", "persistentvolumeclaims"}, Verbs: []string{"get", "list", "watch"}},
+			{APIGroups: []string{"storage.k8s.io"}, Resources: []string{"csinodes", "storageclasses", "volumeattachments"}, Verbs: []string{"get", "list", "watch"}},
+			{APIGroups: []string{""}, Resources: []string{"events"}, Verbs: []string{"create", "patch", "update"}},
+		}
+	}); err != nil {
+		return err
+	}
+
+	clusterRoleBinding := &rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: nodeAppName}}
+	return resources.CreateOrUpdate(ctx, workloadClient, workloadClient, "ClusterRoleBinding", clusterRoleBinding, func() {
+		clusterRoleBinding.SetLabels(labels)
+		clusterRoleBinding.RoleRef = rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: nodeAppName}
+		clusterRoleBinding.Subjects = []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Namespace: NodeNamespace, Name: nodeAppName}}
+	})
+}
+
+// nodePodTemplate returns the pod template of the node service daemon set, running the CSI driver with the node
+// driver registrar sidecar on every Node.
+func nodePodTemplate(ctx *context.ClusterContext, labels map[string]string) corev1.PodTemplateSpec {
+	pluginDir := path.Join(kubeletDir, "plugins", DriverName)
+	hostPath := func(name, path string, pathType corev1.HostPathType) corev1.Volume {
+		return corev1.Volume{Name: name, VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: path, Type: &pathType}}}
+	}
+
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: labels},
+		Spec: corev1.PodSpec{
+			ServiceAccountName: nodeAppName,
+			HostNetwork:        true,
+			PriorityClassName:  "system-node-critical",
+			Tolerations:        []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+			Containers: []corev1.Container{
+				{
+					Name:  "csi-driver",
+					Image: image(ctx.KubevirtCluster.Spec.CSIDriver),
+					Args: []string{
+						"--endpoint=unix://" + path.Join(socketDir, "csi.sock"),
+						"--node-name=$(KUBE_NODE_NAME)",
+						"--run-node-service=true",
+						"--run-controller-service=false",
+					},
+					Env: []corev1.EnvVar{{Name: "KUBE_NODE_NAME", ValueFrom: &corev1.EnvVarSource{
+						FieldRef: &corev1.ObjectFieldSelector{FieldPath: "spec.nodeName"},
+					}}},
+					SecurityContext: &corev1.SecurityContext{Privileged: ptr.To(true)},
+					VolumeMounts: []corev1.VolumeMount{
+						{Name: "kubelet-dir", MountPath: kubeletDir, MountPropagation: ptr.To(corev1.MountPropagationBidirectional)},
+						{Name: "plugin-dir", MountPath: socketDir},
+						{Name: "device-dir", MountPath: "/dev"},
+						{Name: "udev", MountPath: "/run/udev"},
+					},
+				},
+				{
+					Name:  "csi-node-driver-registrar",
+					Image: registrarImage,
+					Args: []string{
+						"--csi-address=" + path.Join(socketDir, "csi.sock"),
+						"--kubelet-registration-path=" + path.Join(pluginDir, "csi.sock"),
+					},
+					VolumeMounts: []corev1.VolumeMount{
+						{Name: "plugin-dir", MountPath: socketDir},
+						{Name: "registration-dir", MountPath: "/registration"},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				hostPath("kubelet-dir", kubeletDir, corev1.HostPathDirectory),
+				hostPath("plugin-dir", pluginDir, corev1.HostPathDirectoryOrCreate),
+				hostPath("registration-dir", path.Join(kubeletDir, "plugins_registry"), corev1.HostPathDirectory),
+				hostPath("device-dir", "/dev", corev1.HostPathDirectory),
+				hostPath("udev", "/run/udev", corev1.HostPathDirectory),
+			},
+		},
+	}
+}
+
+// reconcileStorageClasses creates the mapped storage classes, updates which one is the default, and deletes the
+// storage classes of the driver no longer mapped.
+func reconcileStorageClasses(ctx *context.ClusterContext, workloadClient client.Client, labels map[string]string) error {
+	mapped := map[string]bool{}
+	var remapped []string
+	for _, mapping := range ctx.KubevirtCluster.Spec.CSIDriver.StorageClasses {
+		mapped[mapping.Name] = true
+
+		storageClass := &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: mapping.Name}}
+		if err := resources.CreateOrUpdate(ctx, workloadClient, workloadClient, "StorageClass", storageClass, func() {
+			storageClass.SetLabels(labels)
+			annotations := storageClass.GetAnnotations()
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			annotations[defaultStorageClassKey] = fmt.Sprint(mapping.Default)
+			storageClass.SetAnnotations(annotations)
+			if storageClass.Provisioner == "" {
+				storageClass.Provisioner = DriverName
+				storageClass.Parameters = map[string]string{infraStorageClassParameter: mapping.InfraStorageClassName, "bus": "scsi"}
+				storageClass.AllowVolumeExpansion = ptr.To(false)
+				storageClass.VolumeBindingMode = ptr.To(storagev1.VolumeBindingWaitForFirstConsumer)
+			}
+		}); err != nil {
+			return err
+		}
+		if storageClass.Parameters[infraStorageClassParameter] != mapping.InfraStorageClassName {
+			remapped = append(remapped, mapping.Name)
+		}
+	}
+	if len(remapped) > 0 {
+		return errors.Errorf("storage classes %s exist with another infra storage class, delete them to recreate them", strings.Join(remapped, ", "))
+	}
+
+	storageClasses := &storagev1.StorageClassList{}
+	if err := workloadClient.List(ctx, storageClasses, client.MatchingLabels(labels)); err != nil {
+		return errors.Wrap(err, "failed to list the storage classes")
+	}
+	for i := range storageClasses.Items {
+		storageClass := &storageClasses.Items[i]
+		if mapped[storageClass.Name] {
+			continue
+		}
+		if err := workloadClient.Delete(ctx, storageClass); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete StorageClass %s", storageClass.Name)
+		}
+	}
+	return nil
+}
+
+func image(spec *infrav1.CSIDriverSpec) string {
+	if spec.Image == "" {
+		return DefaultImage
+	}
+	return spec.Image
+}
+
+// replicas returns the number of replicas of the controller: none while the cluster is hibernated, as its workload
+// cluster is not reachable.
+func replicas(kubevirtCluster *infrav1.KubevirtCluster) int32 {
+	if hibernation.IsClusterHibernated(kubevirtCluster) {
+		return 0
+	}
+	return 1
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubevirtcsi_test
+
+import (
+	gocontext "context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirtcsi"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("KubeVirt CSI driver", func() {
+	var (
+		fakeClient      client.Client
+		kubevirtCluster *infrav1.KubevirtCluster
+		ctx             *context.ClusterContext
+		key             client.ObjectKey
+	)
+
+	BeforeEach(func() {
+		kubevirtCluster = testing.NewKubevirtCluster("test-cluster", "test-kubevirt-cluster")
+		kubevirtCluster.Spec.CSIDriver = &infrav1.CSIDriverSpec{
+			StorageClasses: []infrav1.StorageClassMapping{
+				{Name: "kubevirt", InfraStorageClassName: "ceph-block", Default: true},
+				{Name: "kubevirt-fast", InfraStorageClassName: "local-nvme"},
+			},
+		}
+		cluster := testing.NewCluster("test-cluster", kubevirtCluster)
+		ctx = &context.ClusterContext{
+			Logger:          ctrl.LoggerFrom(gocontext.TODO()).WithName("test"),
+			Context:         gocontext.TODO(),
+			Cluster:         cluster,
+			KubevirtCluster: kubevirtCluster,
+		}
+		key = client.ObjectKey{Namespace: cluster.Namespace, Name: kubevirtcsi.Name(cluster)}
+		fakeClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).Build()
+	})
+
+	Context("controller service", func() {
+		It("should deploy the controller with a service account when the infra is local", func() {
+			Expect(kubevirtcsi.ReconcileController(ctx, fakeClient, fakeClient, "infra-namespace")).To(Succeed())
+
+			Expect(fakeClient.Get(ctx, key, &corev1.ServiceAccount{})).To(Succeed())
+			role := &rbacv1.Role{}
+			Expect(fakeClient.Get(ctx, key, role)).To(Succeed())
+			Expect(role.Rules).To(ContainElement(HaveField("Resources", ContainElement("virtualmachineinstances/addvolume"))))
+			Expect(fakeClient.Get(ctx, key, &rbacv1.RoleBinding{})).To(Succeed())
+
+			deployment := &appsv1.Deployment{}
+			Expect(fakeClient.Get(ctx, key, deployment)).To(Succeed())
+			Expect(deployment.Labels).To(HaveKeyWithValue("capk.cluster.x-k8s.io/template-kind", "extra-resource"))
+			podSpec := deployment.Spec.Template.Spec
+			Expect(podSpec.ServiceAccountName).To(Equal(key.Name))
+			Expect(podSpec.Containers).To(HaveLen(3))
+			Expect(podSpec.Containers[0].Image).To(Equal(kubevirtcsi.DefaultImage))
+			Expect(podSpec.Containers[0].Args).To(ContainElements(
+				"--infra-cluster-namespace=infra-namespace",
+				"--infra-cluster-labels=cluster.x-k8s.io/cluster-name=test-cluster",
+				"--run-controller-service=true",
+			))
+		})
+
+		It("should use the infra credentials of the cluster when the infra is external", func() {
+			kubevirtCluster.Spec.InfraClusterSecretRef = &corev1.ObjectReference{Name: "infra-kubeconfig"}
+			kubevirtCluster.Spec.CSIDriver.Image = "example.com/kubevirt-csi-driver:dev"
+			Expect(kubevirtcsi.ReconcileController(ctx, fakeClient, fakeClient, "infra-namespace")).To(Succeed())
+
+			Expect(apierrors.IsNotFound(fakeClient.Get(ctx, key, &corev1.ServiceAccount{}))).To(BeTrue())
+			deployment := &appsv1.Deployment{}
+			Expect(fakeClient.Get(ctx, key, deployment)).To(Succeed())
+			driver := deployment.Spec.Template.Spec.Containers[0]
+			Expect(driver.Image).To(Equal("example.com/kubevirt-csi-driver:dev"))
+			Expect(driver.Args).To(ContainElement("--infra-cluster-kubeconfig=/var/run/secrets/infracluster/kubeconfig"))
+			Expect(deployment.Spec.Template.Spec.Volumes).To(ContainElement(HaveField("Secret.SecretName", "infra-kubeconfig")))
+		})
+
+		It("should scale the controller down while the cluster is hibernated", func() {
+			kubevirtCluster.Spec.Hibernated = true
+			Expect(kubevirtcsi.ReconcileController(ctx, fakeClient, fakeClient, "infra-namespace")).To(Succeed())
+			deployment := &appsv1.Deployment{}
+			Expect(fakeClient.Get(ctx, key, deployment)).To(Succeed())
+			Expect(deployment.Spec.Replicas).To(HaveValue(BeEquivalentTo(0)))
+
+			kubevirtCluster.Spec.Hibernated = false
+			Expect(kubevirtcsi.ReconcileController(ctx, fakeClient, fakeClient, "infra-namespace")).To(Succeed())
+			Expect(fakeClient.Get(ctx, key, deployment)).To(Succeed())
+			Expect(deployment.Spec.Replicas).To(HaveValue(BeEquivalentTo(1)))
+		})
+
+		It("should refuse infra credentials from another namespace", func() {
+			kubevirtCluster.Spec.InfraClusterSecretRef = &corev1.ObjectReference{Namespace: "other", Name: "infra-kubeconfig"}
+			Expect(kubevirtcsi.ReconcileController(ctx, fakeClient, fakeClient, "infra-namespace")).To(
+				MatchError(ContainSubstring("must be in the namespace of the cluster")))
+		})
+	})
+
+	Context("node service", func() {
+		getStorageClass := func(name string) *storagev1.StorageClass {
+			storageClass := &storagev1.StorageClass{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: name}, storageClass)).To(Succeed())
+			return storageClass
+		}
+
+		It("should deploy the node service and the mapped storage classes", func() {
+			Expect(kubevirtcsi.ReconcileNode(ctx, fakeClient)).To(Succeed())
+
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: kubevirtcsi.DriverName}, &storagev1.CSIDriver{})).To(Succeed())
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Name: kubevirtcsi.NodeNamespace}, &corev1.Namespace{})).To(Succeed())
+			daemonSet := &appsv1.DaemonSet{}
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: kubevirtcsi.NodeNamespace, Name: "kubevirt-csi-node"}, daemonSet)).To(Succeed())
+			Expect(daemonSet.Spec.Template.Spec.Containers[0].Args).To(ContainElement("--run-node-service=true"))
+
+			storageClass := getStorageClass("kubevirt")
+			Expect(storageClass.Provisioner).To(Equal(kubevirtcsi.DriverName))
+			Expect(storageClass.Parameters).To(HaveKeyWithValue("infraStorageClassName", "ceph-block"))
+			Expect(storageClass.Annotations).To(HaveKeyWithValue("storageclass.kubernetes.io/is-default-class", "true"))
+			Expect(getStorageClass("kubevirt-fast").Annotations).To(HaveKeyWithValue("storageclass.kubernetes.io/is-default-class", "false"))
+		})
+
+		It("should update the default storage class and delete the storage classes no longer mapped", func() {
+			Expect(kubevirtcsi.ReconcileNode(ctx, fakeClient)).To(Succeed())
+
+			kubevirtCluster.Spec.CSIDriver.StorageClasses = []infrav1.StorageClassMapping{
+				{Name: "kubevirt-fast", InfraStorageClassName: "local-nvme", Default: true},
+			}
+			Expect(kubevirtcsi.ReconcileNode(ctx, fakeClient)).To(Succeed())
+
+			Expect(getStorageClass("kubevirt-fast").Annotations).To(HaveKeyWithValue("storageclass.kubernetes.io/is-default-class", "true"))
+			Expect(apierrors.IsNotFound(fakeClient.Get(ctx, client.ObjectKey{Name: "kubevirt"}, &storagev1.StorageClass{}))).To(BeTrue())
+		})
+
+		It("should not change the infra storage class of an existing storage class", func() {
+			Expect(kubevirtcsi.ReconcileNode(ctx, fakeClient)).To(Succeed())
+
+			kubevirtCluster.Spec.CSIDriver.StorageClasses[0].InfraStorageClassName = "ceph-fs"
+			Expect(kubevirtcsi.ReconcileNode(ctx, fakeClient)).To(MatchError(ContainSubstring("storage classes kubevirt exist with another infra storage class")))
+			Expect(getStorageClass("kubevirt").Parameters).To(HaveKeyWithValue("infraStorageClassName", "ceph-block"))
+		})
+	})
+})
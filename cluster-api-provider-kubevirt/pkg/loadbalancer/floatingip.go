@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancer
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+// FloatingIP claims a floating IP from a pool of the infra SDN for the control plane endpoint of a cluster.
+type FloatingIP struct {
+	name           string
+	spec           infrav1.FloatingIPSpec
+	infraClient    runtimeclient.Client
+	infraNamespace string
+}
+
+// NewFloatingIP returns the floating IP of the control plane endpoint of the cluster, claimed with an object in
+// the given namespace of the infra cluster.
+func NewFloatingIP(ctx *context.ClusterContext, client runtimeclient.Client, namespace string) (*FloatingIP, error) {
+	spec := ctx.KubevirtCluster.Spec.ControlPlaneFloatingIP
+	if spec == nil || spec.APIVersion == "" || spec.Kind == "" || spec.Pool == "" {
+		return nil, errors.New("the apiVersion, the kind and the pool of the floating IP claim must be set")
+	}
+
+	return &FloatingIP{
+		name:           ctx.Cluster.Name + "-fip",
+		spec:           *spec,
+		infraClient:    client,
+		infraNamespace: namespace,
+	}, nil
+}
+
+// Address creates the claim of the floating IP if it does not exist, with the target of its NAT rule if not
+// empty, and returns the allocated floating IP, or an empty string while it is not allocated yet.
+func (f *FloatingIP) Address(ctx *context.ClusterContext, target string) (string, error) {
+	claim := f.newClaim()
+	if err := f.infraClient.Get(ctx, runtimeclient.ObjectKeyFromObject(claim), claim); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return "", errors.Wrapf(err, "failed to get floating IP claim %s", f.spec.Kind)
+		}
+		if err := f.create(ctx, target); err != nil {
+			return "", err
+		}
+		return "", nil
+	}
+
+	addressField := f.spec.AddressField
+	if addressField == "" {
+		addressField = DefaultAddressField
+	}
+	address, _, err := unstructured.NestedString(claim.Object, strings.Split(addressField, ".")...)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read %s of the floating IP claim %s", addressField, f.spec.Kind)
+	}
+	return address, nil
+}
+
+// Release deletes the claim of the floating IP, returning the floating IP to its pool.
+func (f *FloatingIP) Release(ctx *context.ClusterContext) error {
+	if err := f.infraClient.Delete(ctx, f.newClaim()); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete floating IP claim %s", f.spec.Kind)
+	}
+	return nil
+}
+
+// Object returns the kind and the name of the claim of the floating IP.
+func (f *FloatingIP) Object() (string, string) {
+	return f.spec.Kind, f.name
+}
+
+func (f *FloatingIP) create(ctx *context.ClusterContext, target string) error {
+	spec := map[string]interface{}{}
+	if len(f.spec.Spec.Raw) > 0 {
+		if err := utiljson.Unmarshal(f.spec.Spec.Raw, &spec); err != nil {
+			return errors.Wrap(err, "failed to parse the spec of the floating IP claim")
+		}
+	}
+	spec["pool"] = f.spec.Pool
+	if target != "" {
+		spec["target"] = target
+	}
+
+	claim := f.newClaim()
+	claim.Object["spec"] = spec
+	claim.SetLabels(map[string]string{clusterv1.ClusterNameLabel: ctx.Cluster.Name})
+	if err := f.infraClient.Create(ctx, claim); err != nil {
+		return errors.Wrapf(err, "failed to create floating IP claim %s", f.spec.Kind)
+	}
+	return nil
+}
+
+func (f *FloatingIP) newClaim() *unstructured.Unstructured {
+	claim := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	claim.SetAPIVersion(f.spec.APIVersion)
+	claim.SetKind(f.spec.Kind)
+	claim.SetNamespace(f.infraNamespace)
+	claim.SetName(f.name)
+	return claim
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancer_test
+
+import (
+	gocontext "context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/loadbalancer"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("Floating IP", func() {
+	var (
+		fakeClient client.Client
+		kvCluster  *infrav1.KubevirtCluster
+		ctx        *context.ClusterContext
+	)
+
+	BeforeEach(func() {
+		kvCluster = testing.NewKubevirtCluster(clusterName, kubevirtClusterName)
+		kvCluster.Spec.ControlPlaneFloatingIP = &infrav1.FloatingIPSpec{
+			APIVersion:   "sdn.example.com/v1",
+			Kind:         "FloatingIPClaim",
+			Pool:         "public",
+			Spec:         runtime.RawExtension{Raw: []byte(`{"port":6443}`)},
+			AddressField: "status.ip",
+		}
+		ctx = &context.ClusterContext{
+			Logger:          ctrl.LoggerFrom(gocontext.TODO()).WithName("test"),
+			Context:         gocontext.TODO(),
+			Cluster:         cluster,
+			KubevirtCluster: kvCluster,
+		}
+		fakeClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).Build()
+	})
+
+	It("should claim the floating IP with its NAT target and wait for its allocation", func() {
+		floatingIP, err := loadbalancer.NewFloatingIP(ctx, fakeClient, "test-namespace")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(floatingIP.Address(ctx, "10.96.0.10")).To(BeEmpty())
+
+		claim := &unstructured.Unstructured{}
+		claim.SetAPIVersion("sdn.example.com/v1")
+		claim.SetKind("FloatingIPClaim")
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "test-namespace", Name: clusterName + "-fip"}, claim)).To(Succeed())
+		Expect(claim.Object["spec"]).To(Equal(map[string]interface{}{"pool": "public", "target": "10.96.0.10", "port": int64(6443)}))
+		Expect(claim.GetLabels()).To(HaveKeyWithValue("cluster.x-k8s.io/cluster-name", clusterName))
+
+		Expect(floatingIP.Address(ctx, "10.96.0.10")).To(BeEmpty())
+		Expect(unstructured.SetNestedField(claim.Object, "203.0.113.20", "status", "ip")).To(Succeed())
+		Expect(fakeClient.Update(ctx, claim)).To(Succeed())
+		Expect(floatingIP.Address(ctx, "10.96.0.10")).To(Equal("203.0.113.20"))
+
+		Expect(floatingIP.Release(ctx)).To(Succeed())
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(claim), claim)).NotTo(Succeed())
+		Expect(floatingIP.Release(ctx)).To(Succeed())
+	})
+
+	It("should fail without the pool of the floating IP", func() {
+		kvCluster.Spec.ControlPlaneFloatingIP.Pool = ""
+		_, err := loadbalancer.NewFloatingIP(ctx, fakeClient, "test-namespace")
+		Expect(err).To(HaveOccurred())
+	})
+})
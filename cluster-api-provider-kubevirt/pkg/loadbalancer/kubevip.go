@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancer
+
+import (
+	"errors"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+const (
+	// KubeVIPManifestPath is the path of the kube-vip static pod manifest on the control plane machines.
+	KubeVIPManifestPath = "/etc/kubernetes/manifests/kube-vip.yaml"
+
+	// DefaultKubeVIPImage is the default image of kube-vip.
+	DefaultKubeVIPImage = "ghcr.io/kube-vip/kube-vip:v0.8.0"
+
+	// DefaultKubeVIPInterface is the default network interface kube-vip announces the virtual IP on.
+	DefaultKubeVIPInterface = "eth0"
+
+	kubeVIPKubeconfigPath = "/etc/kubernetes/admin.conf"
+)
+
+// KubeVIPManifest renders the static pod announcing the control plane endpoint of the cluster as a virtual IP,
+// with kube-vip in ARP mode and leader election among the control plane machines.
+func KubeVIPManifest(kubevirtCluster *infrav1.KubevirtCluster) ([]byte, error) {
+	endpoint := kubevirtCluster.Spec.ControlPlaneEndpoint
+	if endpoint.Host == "" {
+		return nil, errors.New("controlPlaneEndpoint.host must be set to the virtual IP for the KubeVIP publisher")
+	}
+	port := endpoint.Port
+	if port == 0 {
+		port = 6443
+	}
+
+	image, iface := DefaultKubeVIPImage, DefaultKubeVIPInterface
+	if spec := kubevirtCluster.Spec.ControlPlaneEndpointPublisher.KubeVIP; spec != nil {
+		if spec.Image != "" {
+			image = spec.Image
+		}
+		if spec.Interface != "" {
+			iface = spec.Interface
+		}
+	}
+
+	pod := &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Name: "kube-vip", Namespace: metav1.NamespaceSystem},
+		Spec: corev1.PodSpec{
+			HostNetwork: true,
+			HostAliases: []corev1.HostAlias{{IP: "127.0.0.1", Hostnames: []string{"kubernetes"}}},
+			Containers: []corev1.Container{{
+				Name:  "kube-vip",
+				Image: image,
+				Args:  []string{"manager"},
+				Env: []corev1.EnvVar{
+					{Name: "vip_arp", Value: "true"},
+					{Name: "port", Value: strconv.Itoa(port)},
+					{Name: "vip_interface", Value: iface},
+					{Name: "vip_cidr", Value: "32"},
+					{Name: "cp_enable", Value: "true"},
+					{Name: "cp_namespace", Value: metav1.NamespaceSystem},
+					{Name: "vip_leaderelection", Value: "true"},
+					{Name: "vip_leasename", Value: "plndr-cp-lock"},
+					{Name: "address", Value: endpoint.Host},
+				},
+				SecurityContext: &corev1.SecurityContext{
+					Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"NET_ADMIN", "NET_RAW"}},
+				},
+				VolumeMounts: []corev1.VolumeMount{{Name: "kubeconfig", MountPath: kubeVIPKubeconfigPath}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: "kubeconfig",
+				VolumeSource: corev1.VolumeSource{
+					HostPath: &corev1.HostPathVolumeSource{Path: kubeVIPKubeconfigPath},
+				},
+			}},
+		},
+	}
+
+	return yaml.Marshal(pod)
+}
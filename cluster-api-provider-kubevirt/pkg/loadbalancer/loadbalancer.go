@@ -31,12 +31,15 @@ import (
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/ownership"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/resources"
 )
 
 // LoadBalancer manages the load balancer for a specific KubeVirt cluster.
 type LoadBalancer struct {
 	name            string
 	service         *corev1.Service
+	template        infrav1.ControlPlaneServiceTemplate
 	kubevirtCluster *infrav1.KubevirtCluster
 	infraClient     runtimeclient.Client
 	infraNamespace  string
@@ -44,7 +47,23 @@ type LoadBalancer struct {
 
 // NewLoadBalancer returns a new helper for managing a mock load-balancer (using service).
 func NewLoadBalancer(ctx *context.ClusterContext, client runtimeclient.Client, namespace string) (*LoadBalancer, error) {
-	name := ctx.Cluster.Name + "-lb"
+	return newLoadBalancer(ctx, client, namespace, resources.InfraName(ctx.KubevirtCluster, ctx.Cluster.Name+"-lb"), ctx.KubevirtCluster.Spec.ControlPlaneServiceTemplate)
+}
+
+// NewExternalLoadBalancer returns a new helper for managing the service publishing the external control plane
+// endpoint of the cluster. The service is of type LoadBalancer, unless its template sets another type.
+func NewExternalLoadBalancer(ctx *context.ClusterContext, client runtimeclient.Client, namespace string) (*LoadBalancer, error) {
+	template := infrav1.ControlPlaneServiceTemplate{}
+	if external := ctx.KubevirtCluster.Spec.ExternalControlPlaneEndpoint; external != nil {
+		template = *external.ServiceTemplate.DeepCopy()
+	}
+	if template.Spec.Type == "" {
+		template.Spec.Type = corev1.ServiceTypeLoadBalancer
+	}
+	return newLoadBalancer(ctx, client, namespace, resources.InfraName(ctx.KubevirtCluster, ctx.Cluster.Name+"-lb-external"), template)
+}
+
+func newLoadBalancer(ctx *context.ClusterContext, client runtimeclient.Client, namespace, name string, template infrav1.ControlPlaneServiceTemplate) (*LoadBalancer, error) {
 	// Look for the service that is mocking the load-balancer for the cluster.
 	// Filter based on the label and the roles regardless of whether or not it is running.
 	loadBalancer := &corev1.Service{}
@@ -63,6 +82,7 @@ func NewLoadBalancer(ctx *context.ClusterContext, client runtimeclient.Client, n
 	return &LoadBalancer{
 		name:            name,
 		service:         loadBalancer,
+		template:        template,
 		kubevirtCluster: ctx.KubevirtCluster,
 		infraClient:     client,
 		infraNamespace:  namespace,
@@ -102,15 +122,21 @@ func (l *LoadBalancer) Create(ctx *context.ClusterContext) error {
 		},
 	}
 
-	lbService.Labels = ctx.KubevirtCluster.Spec.ControlPlaneServiceTemplate.ObjectMeta.Labels
-	lbService.Annotations = ctx.KubevirtCluster.Spec.ControlPlaneServiceTemplate.ObjectMeta.Annotations
-	lbService.Spec.Type = ctx.KubevirtCluster.Spec.ControlPlaneServiceTemplate.Spec.Type
+	lbService.Labels = l.template.ObjectMeta.Labels
+	lbService.Annotations = l.template.ObjectMeta.Annotations
+	lbService.Spec.Type = l.template.Spec.Type
+	if lbService.Spec.Type == corev1.ServiceTypeNodePort || lbService.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		lbService.Spec.Ports[0].NodePort = l.template.Spec.NodePort
+	}
+	resources.SetServiceServiceMesh(lbService, l.kubevirtCluster.Spec.ServiceMesh)
 
 	mutateFn := func() (err error) {
 		if lbService.Labels == nil {
 			lbService.Labels = map[string]string{}
 		}
+		resources.PropagateMetadata(lbService, ctx.Cluster, ctx.KubevirtCluster)
 		lbService.Labels[clusterv1.ClusterNameLabel] = ctx.Cluster.Name
+		ownership.SetOwner(lbService, ownership.ClusterOwner(ctx.KubevirtCluster))
 
 		return nil
 	}
@@ -121,6 +147,23 @@ func (l *LoadBalancer) Create(ctx *context.ClusterContext) error {
 	return nil
 }
 
+// NodePorts returns the node ports of the infra cluster exposing the load balancer service, if it exists.
+func (l *LoadBalancer) NodePorts() []infrav1.InfraNodePort {
+	if l.service == nil {
+		return nil
+	}
+	var nodePorts []infrav1.InfraNodePort
+	for _, port := range l.service.Spec.Ports {
+		if port.NodePort != 0 {
+			nodePorts = append(nodePorts, infrav1.InfraNodePort{
+				Service:  l.service.Namespace + "/" + l.service.Name,
+				NodePort: port.NodePort,
+			})
+		}
+	}
+	return nodePorts
+}
+
 // IP returns ip address of the load balancer
 func (l *LoadBalancer) IP(ctx *context.ClusterContext) (string, error) {
 	loadBalancer := &corev1.Service{}
@@ -154,16 +197,24 @@ func (l *LoadBalancer) ExternalIP(ctx *context.ClusterContext) (string, error) {
 		return "", fmt.Errorf("the load balancer external IP is not ready yet")
 	}
 
+	// Some cloud load balancers are only published with a hostname
+	if ingress := loadBalancer.Status.LoadBalancer.Ingress[0]; ingress.IP == "" && ingress.Hostname != "" {
+		return ingress.Hostname, nil
+	}
 	return loadBalancer.Status.LoadBalancer.Ingress[0].IP, nil
 }
 
-// Delete deletes load-balancer service.
+// Delete deletes load-balancer service. A service the provider did not create for the cluster is left in place,
+// and reported with a NotOwnedError.
 func (l *LoadBalancer) Delete(ctx *context.ClusterContext) error {
 	if !l.IsFound() {
 		return nil
 	}
+	if err := ownership.VerifyOwner(l.service, ownership.ClusterOwner(ctx.KubevirtCluster), map[string]string{clusterv1.ClusterNameLabel: ctx.Cluster.Name}); err != nil {
+		return err
+	}
 
-	if err := l.infraClient.Delete(ctx, l.service); err != nil {
+	if err := l.infraClient.Delete(ctx, l.service, runtimeclient.Preconditions{UID: &l.service.UID}); err != nil {
 		return errors.Wrapf(err, "failed to delete load balancer service")
 	}
 
@@ -30,6 +30,7 @@ import (
 	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/loadbalancer"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/ownership"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
 )
 
@@ -112,6 +113,124 @@ var _ = Describe("Load Balancer", func() {
 			Expect(err).To(HaveOccurred())
 		})
 	})
+
+	Context("when the cluster bypasses a service mesh", func() {
+		var meshedCluster *infrav1.KubevirtCluster
+
+		BeforeEach(func() {
+			meshedCluster = kubevirtCluster.DeepCopy()
+			meshedCluster.Spec.ControlPlaneServiceTemplate.ObjectMeta.Annotations = map[string]string{"a": "b"}
+			meshedCluster.Spec.ServiceMesh = &infrav1.ServiceMeshSpec{
+				Type:               infrav1.LinkerdServiceMesh,
+				ServiceAnnotations: map[string]string{"c": "d"},
+			}
+			fakeClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(cluster, meshedCluster).Build()
+		})
+
+		It("should mark the ports of the service opaque to the mesh", func() {
+			meshedContext := &context.ClusterContext{
+				Logger:          clusterContext.Logger,
+				Context:         clusterContext.Context,
+				Cluster:         cluster,
+				KubevirtCluster: meshedCluster,
+			}
+			lb, err = loadbalancer.NewLoadBalancer(meshedContext, fakeClient, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lb.Create(meshedContext)).To(Succeed())
+
+			service := &corev1.Service{}
+			Expect(fakeClient.Get(gocontext.TODO(), client.ObjectKey{Name: clusterName + "-lb"}, service)).To(Succeed())
+			Expect(service.Annotations).To(Equal(map[string]string{
+				"a":                              "b",
+				"c":                              "d",
+				"config.linkerd.io/opaque-ports": "6443",
+				infrav1.InfraOwnerAnnotation:     "KubevirtCluster//" + meshedCluster.Name,
+			}))
+			Expect(meshedCluster.Spec.ControlPlaneServiceTemplate.ObjectMeta.Annotations).To(Equal(map[string]string{"a": "b"}))
+		})
+	})
+
+	Context("when deleting the service", func() {
+		BeforeEach(func() {
+			fakeClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).Build()
+		})
+
+		It("should delete the service it created", func() {
+			lb, err = loadbalancer.NewLoadBalancer(clusterContext, fakeClient, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lb.Create(clusterContext)).To(Succeed())
+
+			lb, err = loadbalancer.NewLoadBalancer(clusterContext, fakeClient, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lb.Delete(clusterContext)).To(Succeed())
+			Expect(fakeClient.Get(gocontext.TODO(), client.ObjectKey{Name: clusterName + "-lb"}, &corev1.Service{})).NotTo(Succeed())
+		})
+
+		It("should leave a service merely named like the load balancer", func() {
+			Expect(fakeClient.Create(gocontext.TODO(), &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: clusterName + "-lb"}})).To(Succeed())
+
+			lb, err = loadbalancer.NewLoadBalancer(clusterContext, fakeClient, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ownership.IsNotOwned(lb.Delete(clusterContext))).To(BeTrue())
+			Expect(fakeClient.Get(gocontext.TODO(), client.ObjectKey{Name: clusterName + "-lb"}, &corev1.Service{})).To(Succeed())
+		})
+
+		It("should leave the service of another KubevirtCluster of the cluster", func() {
+			Expect(fakeClient.Create(gocontext.TODO(), &corev1.Service{ObjectMeta: metav1.ObjectMeta{
+				Name:        clusterName + "-lb",
+				Labels:      map[string]string{"cluster.x-k8s.io/cluster-name": clusterName},
+				Annotations: map[string]string{infrav1.InfraOwnerAnnotation: "KubevirtCluster/another-namespace/" + kubevirtClusterName},
+			}})).To(Succeed())
+
+			lb, err = loadbalancer.NewLoadBalancer(clusterContext, fakeClient, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ownership.IsNotOwned(lb.Delete(clusterContext))).To(BeTrue())
+			Expect(fakeClient.Get(gocontext.TODO(), client.ObjectKey{Name: clusterName + "-lb"}, &corev1.Service{})).To(Succeed())
+		})
+	})
+
+	It("should request the node port of the template and report it", func() {
+		nodePortCluster := kubevirtCluster.DeepCopy()
+		nodePortCluster.Spec.ControlPlaneServiceTemplate.Spec = infrav1.ServiceSpecTemplate{Type: corev1.ServiceTypeNodePort, NodePort: 30443}
+		nodePortContext := &context.ClusterContext{
+			Logger:          clusterContext.Logger,
+			Context:         clusterContext.Context,
+			Cluster:         cluster,
+			KubevirtCluster: nodePortCluster,
+		}
+		fakeClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).Build()
+		lb, err = loadbalancer.NewLoadBalancer(nodePortContext, fakeClient, "default")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lb.NodePorts()).To(BeEmpty())
+		Expect(lb.Create(nodePortContext)).To(Succeed())
+
+		lb, err = loadbalancer.NewLoadBalancer(nodePortContext, fakeClient, "default")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lb.NodePorts()).To(Equal([]infrav1.InfraNodePort{{Service: "default/" + clusterName + "-lb", NodePort: 30443}}))
+	})
+
+	It("should prefix the name of the service and propagate the metadata of the Cluster", func() {
+		taggedCluster := cluster.DeepCopy()
+		taggedCluster.Labels = map[string]string{"example.com/tenant": "acme"}
+		taggedKubevirtCluster := kubevirtCluster.DeepCopy()
+		taggedKubevirtCluster.Spec.NamingPrefix = "acme-"
+		taggedKubevirtCluster.Spec.MetadataPropagation = &infrav1.MetadataPropagationSpec{Labels: []string{"example.com/tenant"}}
+		taggedContext := &context.ClusterContext{
+			Logger:          clusterContext.Logger,
+			Context:         clusterContext.Context,
+			Cluster:         taggedCluster,
+			KubevirtCluster: taggedKubevirtCluster,
+		}
+		fakeClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).Build()
+		lb, err = loadbalancer.NewLoadBalancer(taggedContext, fakeClient, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lb.Create(taggedContext)).To(Succeed())
+
+		service := &corev1.Service{}
+		Expect(fakeClient.Get(gocontext.TODO(), client.ObjectKey{Name: "acme-" + clusterName + "-lb"}, service)).To(Succeed())
+		Expect(service.Labels).To(HaveKeyWithValue("example.com/tenant", "acme"))
+		Expect(service.Labels).To(HaveKeyWithValue("cluster.x-k8s.io/cluster-name", clusterName))
+	})
 })
 
 func newLoadBalancerService(ctx *context.ClusterContext, kubevirtCluster *infrav1.KubevirtCluster) *corev1.Service {
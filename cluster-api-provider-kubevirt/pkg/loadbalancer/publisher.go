@@ -0,0 +1,240 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+// DefaultAddressField is the default field of the external load balancer objects holding their address.
+const DefaultAddressField = "status.address"
+
+// Publisher publishes the control plane endpoint of a KubeVirt cluster.
+type Publisher interface {
+	// IsFound checks if the infra object publishing the endpoint already exists, or is not needed.
+	IsFound() bool
+
+	// Create creates the infra object publishing the endpoint.
+	Create(ctx *context.ClusterContext) error
+
+	// Endpoint returns the published endpoint, or an error while it is not known yet.
+	Endpoint(ctx *context.ClusterContext) (infrav1.APIEndpoint, error)
+
+	// Delete deletes the infra object publishing the endpoint, if any.
+	Delete(ctx *context.ClusterContext) error
+
+	// Object returns the kind and the name of the infra object publishing the endpoint.
+	Object() (string, string)
+}
+
+// PublisherType returns the type of the publisher of the control plane endpoint of the cluster.
+func PublisherType(kubevirtCluster *infrav1.KubevirtCluster) infrav1.EndpointPublisherType {
+	if publisher := kubevirtCluster.Spec.ControlPlaneEndpointPublisher; publisher != nil && publisher.Type != "" {
+		return publisher.Type
+	}
+	return infrav1.ServiceEndpointPublisher
+}
+
+// NewPublisher returns the publisher of the control plane endpoint selected by the cluster, managing its infra
+// objects in the given namespace.
+func NewPublisher(ctx *context.ClusterContext, client runtimeclient.Client, namespace string) (Publisher, error) {
+	switch publisherType := PublisherType(ctx.KubevirtCluster); publisherType {
+	case infrav1.ServiceEndpointPublisher:
+		return NewLoadBalancer(ctx, client, namespace)
+	case infrav1.ExternalLoadBalancerEndpointPublisher:
+		return newExternalAPILoadBalancer(ctx, client, namespace)
+	case infrav1.KubeVIPEndpointPublisher, infrav1.StaticEndpointPublisher:
+		return &staticPublisher{publisherType: publisherType}, nil
+	default:
+		return nil, fmt.Errorf("unknown control plane endpoint publisher %q", publisherType)
+	}
+}
+
+// staticPublisher publishes the control plane endpoint set in the cluster spec, without any infra object. The
+// KubeVIP publisher is one: the endpoint is announced by the control plane machines themselves.
+type staticPublisher struct {
+	publisherType infrav1.EndpointPublisherType
+}
+
+// IsFound returns true, as there is no infra object to create.
+func (p *staticPublisher) IsFound() bool {
+	return true
+}
+
+// Create does nothing.
+func (p *staticPublisher) Create(_ *context.ClusterContext) error {
+	return nil
+}
+
+// Endpoint always fails, as the endpoint has to be set in the cluster spec.
+func (p *staticPublisher) Endpoint(_ *context.ClusterContext) (infrav1.APIEndpoint, error) {
+	return infrav1.APIEndpoint{}, fmt.Errorf("controlPlaneEndpoint.host must be set for the %s publisher", p.publisherType)
+}
+
+// Delete does nothing.
+func (p *staticPublisher) Delete(_ *context.ClusterContext) error {
+	return nil
+}
+
+// Object returns empty strings, as there is no infra object.
+func (p *staticPublisher) Object() (string, string) {
+	return "", ""
+}
+
+// externalAPILoadBalancer publishes the control plane endpoint with an object of a load balancer API of the infra
+// cluster, such as the ms-sdn load balancer CRD.
+type externalAPILoadBalancer struct {
+	name           string
+	spec           infrav1.ExternalLoadBalancerPublisherSpec
+	object         *unstructured.Unstructured
+	infraClient    runtimeclient.Client
+	infraNamespace string
+}
+
+func newExternalAPILoadBalancer(ctx *context.ClusterContext, client runtimeclient.Client, namespace string) (*externalAPILoadBalancer, error) {
+	spec := ctx.KubevirtCluster.Spec.ControlPlaneEndpointPublisher.ExternalLoadBalancer
+	if spec == nil || spec.APIVersion == "" || spec.Kind == "" {
+		return nil, errors.New("the apiVersion and the kind of the external load balancer must be set")
+	}
+
+	name := ctx.Cluster.Name + "-lb"
+	object := &unstructured.Unstructured{}
+	object.SetAPIVersion(spec.APIVersion)
+	object.SetKind(spec.Kind)
+	if err := client.Get(ctx.Context, runtimeclient.ObjectKey{Namespace: namespace, Name: name}, object); err != nil {
+		if apierrors.IsNotFound(err) {
+			object = nil
+		} else {
+			return nil, err
+		}
+	}
+
+	return &externalAPILoadBalancer{
+		name:           name,
+		spec:           *spec,
+		object:         object,
+		infraClient:    client,
+		infraNamespace: namespace,
+	}, nil
+}
+
+// IsFound checks if the load balancer object already exists.
+func (l *externalAPILoadBalancer) IsFound() bool {
+	return l.object != nil
+}
+
+// Create creates the load balancer object, with the spec set in the cluster.
+func (l *externalAPILoadBalancer) Create(ctx *context.ClusterContext) error {
+	if l.IsFound() {
+		return fmt.Errorf("the load balancer %s already exists", l.spec.Kind)
+	}
+
+	object := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	if len(l.spec.Spec.Raw) > 0 {
+		spec := map[string]interface{}{}
+		if err := json.Unmarshal(l.spec.Spec.Raw, &spec); err != nil {
+			return errors.Wrap(err, "failed to parse the spec of the external load balancer")
+		}
+		object.Object["spec"] = spec
+	}
+	object.SetAPIVersion(l.spec.APIVersion)
+	object.SetKind(l.spec.Kind)
+	object.SetNamespace(l.infraNamespace)
+	object.SetName(l.name)
+	object.SetLabels(map[string]string{clusterv1.ClusterNameLabel: ctx.Cluster.Name})
+
+	if err := l.infraClient.Create(ctx, object); err != nil {
+		return errors.Wrapf(err, "failed to create load balancer %s", l.spec.Kind)
+	}
+	l.object = object
+	return nil
+}
+
+// Endpoint returns the address assigned to the load balancer object.
+func (l *externalAPILoadBalancer) Endpoint(ctx *context.ClusterContext) (infrav1.APIEndpoint, error) {
+	object := &unstructured.Unstructured{}
+	object.SetAPIVersion(l.spec.APIVersion)
+	object.SetKind(l.spec.Kind)
+	if err := l.infraClient.Get(ctx, runtimeclient.ObjectKey{Namespace: l.infraNamespace, Name: l.name}, object); err != nil {
+		return infrav1.APIEndpoint{}, err
+	}
+
+	addressField := l.spec.AddressField
+	if addressField == "" {
+		addressField = DefaultAddressField
+	}
+	address, found, err := unstructured.NestedString(object.Object, strings.Split(addressField, ".")...)
+	if err != nil {
+		return infrav1.APIEndpoint{}, errors.Wrapf(err, "failed to read %s of the load balancer %s", addressField, l.spec.Kind)
+	}
+	if !found || address == "" {
+		return infrav1.APIEndpoint{}, fmt.Errorf("the load balancer %s address is not ready yet", l.spec.Kind)
+	}
+
+	return infrav1.APIEndpoint{Host: address, Port: 6443}, nil
+}
+
+// Delete deletes the load balancer object.
+func (l *externalAPILoadBalancer) Delete(ctx *context.ClusterContext) error {
+	if !l.IsFound() {
+		return nil
+	}
+
+	if err := l.infraClient.Delete(ctx, l.object); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete load balancer %s", l.spec.Kind)
+	}
+
+	return nil
+}
+
+// Object returns the kind and the name of the load balancer object.
+func (l *externalAPILoadBalancer) Object() (string, string) {
+	return l.spec.Kind, l.name
+}
+
+// Endpoint returns the address of the service, its external address if it is of type LoadBalancer.
+func (l *LoadBalancer) Endpoint(ctx *context.ClusterContext) (infrav1.APIEndpoint, error) {
+	var host string
+	var err error
+	if l.template.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		host, err = l.ExternalIP(ctx)
+	} else {
+		host, err = l.IP(ctx)
+	}
+	if err != nil {
+		return infrav1.APIEndpoint{}, err
+	}
+
+	return infrav1.APIEndpoint{Host: host, Port: 6443}, nil
+}
+
+// Object returns the kind and the name of the service.
+func (l *LoadBalancer) Object() (string, string) {
+	return "Service", l.name
+}
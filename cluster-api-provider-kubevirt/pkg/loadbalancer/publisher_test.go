@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancer_test
+
+import (
+	gocontext "context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/loadbalancer"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("Publisher", func() {
+	var (
+		fakeClient client.Client
+		kvCluster  *infrav1.KubevirtCluster
+		ctx        *context.ClusterContext
+	)
+
+	BeforeEach(func() {
+		kvCluster = testing.NewKubevirtCluster(clusterName, kubevirtClusterName)
+		ctx = &context.ClusterContext{
+			Logger:          ctrl.LoggerFrom(gocontext.TODO()).WithName("test"),
+			Context:         gocontext.TODO(),
+			Cluster:         cluster,
+			KubevirtCluster: kvCluster,
+		}
+		fakeClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).Build()
+	})
+
+	It("should publish the endpoint with a service by default", func() {
+		publisher, err := loadbalancer.NewPublisher(ctx, fakeClient, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(publisher).To(BeAssignableToTypeOf(&loadbalancer.LoadBalancer{}))
+
+		kind, name := publisher.Object()
+		Expect(kind).To(Equal("Service"))
+		Expect(name).To(Equal(clusterName + "-lb"))
+	})
+
+	It("should require the endpoint to be set for the static publisher", func() {
+		kvCluster.Spec.ControlPlaneEndpointPublisher = &infrav1.EndpointPublisherSpec{Type: infrav1.StaticEndpointPublisher}
+		publisher, err := loadbalancer.NewPublisher(ctx, fakeClient, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(publisher.IsFound()).To(BeTrue())
+
+		_, err = publisher.Endpoint(ctx)
+		Expect(err).To(MatchError(ContainSubstring("controlPlaneEndpoint.host must be set")))
+	})
+
+	Context("with an external load balancer API", func() {
+		BeforeEach(func() {
+			kvCluster.Spec.ControlPlaneEndpointPublisher = &infrav1.EndpointPublisherSpec{
+				Type: infrav1.ExternalLoadBalancerEndpointPublisher,
+				ExternalLoadBalancer: &infrav1.ExternalLoadBalancerPublisherSpec{
+					APIVersion:   "sdn.example.com/v1",
+					Kind:         "LoadBalancer",
+					Spec:         runtime.RawExtension{Raw: []byte(`{"port":6443,"pool":"tenant"}`)},
+					AddressField: "status.vip",
+				},
+			}
+		})
+
+		It("should create the load balancer object and wait for its address", func() {
+			publisher, err := loadbalancer.NewPublisher(ctx, fakeClient, "test-namespace")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(publisher.IsFound()).To(BeFalse())
+			Expect(publisher.Create(ctx)).To(Succeed())
+
+			object := &unstructured.Unstructured{}
+			object.SetAPIVersion("sdn.example.com/v1")
+			object.SetKind("LoadBalancer")
+			Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "test-namespace", Name: clusterName + "-lb"}, object)).To(Succeed())
+			Expect(object.Object["spec"]).To(HaveKeyWithValue("pool", "tenant"))
+
+			_, err = publisher.Endpoint(ctx)
+			Expect(err).To(MatchError(ContainSubstring("not ready yet")))
+
+			Expect(unstructured.SetNestedField(object.Object, "10.1.2.3", "status", "vip")).To(Succeed())
+			Expect(fakeClient.Update(ctx, object)).To(Succeed())
+			Expect(publisher.Endpoint(ctx)).To(Equal(infrav1.APIEndpoint{Host: "10.1.2.3", Port: 6443}))
+
+			publisher, err = loadbalancer.NewPublisher(ctx, fakeClient, "test-namespace")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(publisher.IsFound()).To(BeTrue())
+			Expect(publisher.Delete(ctx)).To(Succeed())
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(object), object)).NotTo(Succeed())
+		})
+
+		It("should fail without the kind of the load balancer object", func() {
+			kvCluster.Spec.ControlPlaneEndpointPublisher.ExternalLoadBalancer.Kind = ""
+			_, err := loadbalancer.NewPublisher(ctx, fakeClient, "test-namespace")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	It("should render the kube-vip static pod announcing the endpoint", func() {
+		kvCluster.Spec.ControlPlaneEndpoint = infrav1.APIEndpoint{Host: "192.168.10.100", Port: 6443}
+		kvCluster.Spec.ControlPlaneEndpointPublisher = &infrav1.EndpointPublisherSpec{
+			Type:    infrav1.KubeVIPEndpointPublisher,
+			KubeVIP: &infrav1.KubeVIPPublisherSpec{Interface: "enp1s0"},
+		}
+
+		manifest, err := loadbalancer.KubeVIPManifest(kvCluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(manifest)).To(ContainSubstring("value: 192.168.10.100"))
+		Expect(string(manifest)).To(ContainSubstring("value: enp1s0"))
+		Expect(string(manifest)).To(ContainSubstring("image: " + loadbalancer.DefaultKubeVIPImage))
+
+		kvCluster.Spec.ControlPlaneEndpoint = infrav1.APIEndpoint{}
+		_, err = loadbalancer.KubeVIPManifest(kvCluster)
+		Expect(err).To(HaveOccurred())
+	})
+})
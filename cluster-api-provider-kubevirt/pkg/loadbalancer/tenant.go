@@ -0,0 +1,391 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancer
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/netip"
+	"sort"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/ownership"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/tenantcni"
+)
+
+// TenantLoadBalancers implements the Services of type LoadBalancer of a workload cluster with objects of a load
+// balancer API of the infra cluster, one per Service, each with a virtual IP of the tenant subnet.
+type TenantLoadBalancers struct {
+	spec           infrav1.TenantLoadBalancerSpec
+	vipPrefix      netip.Prefix
+	clusterName    string
+	owner          string
+	infraClient    runtimeclient.Client
+	infraNamespace string
+}
+
+// NewTenantLoadBalancers returns the tenant load balancers of the cluster, managing their objects in the given
+// namespace of the infra cluster.
+func NewTenantLoadBalancers(ctx *context.ClusterContext, client runtimeclient.Client, namespace string) (*TenantLoadBalancers, error) {
+	spec := ctx.KubevirtCluster.Spec.TenantLoadBalancer
+	if spec == nil || spec.APIVersion == "" || spec.Kind == "" {
+		return nil, errors.New("the apiVersion and the kind of the tenant load balancers must be set")
+	}
+	vipPrefix, err := netip.ParsePrefix(spec.VIPCIDR)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid vipCIDR %q", spec.VIPCIDR)
+	}
+
+	return &TenantLoadBalancers{
+		spec:           *spec,
+		vipPrefix:      vipPrefix.Masked(),
+		clusterName:    ctx.Cluster.Name,
+		owner:          ownership.ClusterOwner(ctx.KubevirtCluster),
+		infraClient:    client,
+		infraNamespace: namespace,
+	}, nil
+}
+
+// Reconcile creates or updates the load balancer object of each Service of type LoadBalancer of the workload
+// cluster, writes their virtual IPs to the status of the Services, and deletes the objects of the Services that
+// are gone. The backends of the load balancers are the ready Nodes of the workload cluster, reached on the node
+// ports of the Services.
+func (t *TenantLoadBalancers) Reconcile(ctx *context.ClusterContext, workloadClient runtimeclient.Client) error {
+	services := &corev1.ServiceList{}
+	if err := workloadClient.List(ctx, services); err != nil {
+		return errors.Wrap(err, "failed to list the services of the workload cluster")
+	}
+	nodes := &corev1.NodeList{}
+	if err := workloadClient.List(ctx, nodes); err != nil {
+		return errors.Wrap(err, "failed to list the nodes of the workload cluster")
+	}
+	backends := nodeAddresses(nodes.Items)
+	localEndpointNodes, err := t.localEndpointNodes(ctx, workloadClient)
+	if err != nil {
+		return err
+	}
+
+	objects, err := t.list(ctx)
+	if err != nil {
+		return err
+	}
+	existing := map[string]*unstructured.Unstructured{}
+	usedVIPs := sets.New[string]()
+	for i := range objects {
+		existing[objects[i].GetName()] = &objects[i]
+		if vip, _, _ := unstructured.NestedString(objects[i].Object, "spec", "vip"); vip != "" {
+			usedVIPs.Insert(vip)
+		}
+	}
+
+	var errs []error
+	for i := range services.Items {
+		service := &services.Items[i]
+		if !isTenantLoadBalancerService(service) {
+			continue
+		}
+
+		name := t.objectName(service)
+		object := existing[name]
+		delete(existing, name)
+
+		vip := ""
+		if object != nil {
+			vip, _, _ = unstructured.NestedString(object.Object, "spec", "vip")
+		}
+		if vip == "" {
+			if vip, err = t.allocateVIP(service, usedVIPs); err != nil {
+				errs = append(errs, errors.Wrapf(err, "failed to assign a virtual IP to service %s/%s", service.Namespace, service.Name))
+				continue
+			}
+			usedVIPs.Insert(vip)
+		}
+
+		serviceBackends := backends
+		if localEndpointNodes != nil && service.Spec.ExternalTrafficPolicy == corev1.ServiceExternalTrafficPolicyLocal {
+			serviceBackends = nodeAddresses(filterNodes(nodes.Items, localEndpointNodes[service.Namespace+"/"+service.Name]))
+		}
+		if err := t.apply(ctx, object, name, service, vip, serviceBackends); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := setServiceIngress(ctx, workloadClient, service, vip); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// The remaining objects belong to services that are gone, or are not of type LoadBalancer anymore
+	for _, object := range existing {
+		if err := t.infraClient.Delete(ctx, object); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, errors.Wrapf(err, "failed to delete load balancer %s %s", t.spec.Kind, object.GetName()))
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// localEndpointNodes returns the names of the Nodes running the ready endpoints of each Service, by namespace and
+// name, when the workload cluster replaces kube-proxy, else nil. The node ports of the Services with a Local
+// external traffic policy are only served by the Nodes running their endpoints, and kube-proxy replacements do not
+// necessarily serve their health check node ports: the load balancers forward their traffic to these Nodes only.
+func (t *TenantLoadBalancers) localEndpointNodes(ctx *context.ClusterContext, workloadClient runtimeclient.Client) (map[string]sets.Set[string], error) {
+	mode := ctx.KubevirtCluster.Spec.TenantKubeProxyReplacement
+	if mode == "" {
+		return nil, nil
+	}
+	config, err := tenantcni.Detect(ctx, workloadClient, mode)
+	if err != nil {
+		return nil, err
+	}
+	if !config.KubeProxyReplacement {
+		return nil, nil
+	}
+
+	endpointSlices := &discoveryv1.EndpointSliceList{}
+	if err := workloadClient.List(ctx, endpointSlices); err != nil {
+		return nil, errors.Wrap(err, "failed to list the endpoint slices of the workload cluster")
+	}
+	nodes := map[string]sets.Set[string]{}
+	for _, endpointSlice := range endpointSlices.Items {
+		serviceName := endpointSlice.Labels[discoveryv1.LabelServiceName]
+		if serviceName == "" {
+			continue
+		}
+		key := endpointSlice.Namespace + "/" + serviceName
+		if nodes[key] == nil {
+			nodes[key] = sets.New[string]()
+		}
+		for _, endpoint := range endpointSlice.Endpoints {
+			if endpoint.NodeName != nil && (endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready) {
+				nodes[key].Insert(*endpoint.NodeName)
+			}
+		}
+	}
+	return nodes, nil
+}
+
+// Delete deletes the load balancer objects of all the Services of the workload cluster.
+func (t *TenantLoadBalancers) Delete(ctx *context.ClusterContext) error {
+	objects, err := t.list(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range objects {
+		if err := t.infraClient.Delete(ctx, &objects[i]); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete load balancer %s %s", t.spec.Kind, objects[i].GetName())
+		}
+	}
+	return nil
+}
+
+// list returns the load balancer objects of the Services of the workload cluster. The objects of another
+// KubevirtCluster of a cluster of the same name are left out, so they are never pruned.
+func (t *TenantLoadBalancers) list(ctx *context.ClusterContext) ([]unstructured.Unstructured, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetAPIVersion(t.spec.APIVersion)
+	list.SetKind(t.spec.Kind + "List")
+	labels := map[string]string{
+		clusterv1.ClusterNameLabel: t.clusterName,
+		infrav1.TenantServiceLabel: "true",
+	}
+	if err := t.infraClient.List(ctx, list, runtimeclient.InNamespace(t.infraNamespace), runtimeclient.MatchingLabels(labels)); err != nil {
+		return nil, errors.Wrapf(err, "failed to list the tenant load balancers %s", t.spec.Kind)
+	}
+	objects := make([]unstructured.Unstructured, 0, len(list.Items))
+	for i := range list.Items {
+		if err := ownership.VerifyOwner(&list.Items[i], t.owner, labels); err == nil {
+			objects = append(objects, list.Items[i])
+		}
+	}
+	return objects, nil
+}
+
+// apply creates the load balancer object of a Service, or updates its spec.
+func (t *TenantLoadBalancers) apply(ctx *context.ClusterContext, object *unstructured.Unstructured, name string, service *corev1.Service, vip string, backends []interface{}) error {
+	spec := map[string]interface{}{}
+	if len(t.spec.Spec.Raw) > 0 {
+		if err := utiljson.Unmarshal(t.spec.Spec.Raw, &spec); err != nil {
+			return errors.Wrap(err, "failed to parse the spec of the tenant load balancers")
+		}
+	}
+	spec["vip"] = vip
+	spec["ports"] = servicePorts(service)
+	spec["backends"] = backends
+
+	if object == nil {
+		object = &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+		object.SetAPIVersion(t.spec.APIVersion)
+		object.SetKind(t.spec.Kind)
+		object.SetNamespace(t.infraNamespace)
+		object.SetName(name)
+		object.SetLabels(map[string]string{
+			clusterv1.ClusterNameLabel: t.clusterName,
+			infrav1.TenantServiceLabel: "true",
+		})
+		object.SetAnnotations(map[string]string{infrav1.TenantServiceAnnotation: service.Namespace + "/" + service.Name})
+		ownership.SetOwner(object, t.owner)
+		if err := t.infraClient.Create(ctx, object); err != nil {
+			return errors.Wrapf(err, "failed to create load balancer %s %s", t.spec.Kind, name)
+		}
+		return nil
+	}
+
+	if apiequality.Semantic.DeepEqual(object.Object["spec"], spec) {
+		return nil
+	}
+	object.Object["spec"] = spec
+	if err := t.infraClient.Update(ctx, object); err != nil {
+		return errors.Wrapf(err, "failed to update load balancer %s %s", t.spec.Kind, name)
+	}
+	return nil
+}
+
+// allocateVIP returns the virtual IP requested by the Service when it is free and in the range of the cluster,
+// else the first free address of the range.
+func (t *TenantLoadBalancers) allocateVIP(service *corev1.Service, used sets.Set[string]) (string, error) {
+	if requested, err := netip.ParseAddr(service.Spec.LoadBalancerIP); err == nil {
+		if !t.vipPrefix.Contains(requested) {
+			return "", fmt.Errorf("the requested load balancer IP %s is not in %s", requested, t.vipPrefix)
+		}
+		if used.Has(requested.String()) {
+			return "", fmt.Errorf("the requested load balancer IP %s is already assigned", requested)
+		}
+		return requested.String(), nil
+	}
+
+	first, last := t.vipPrefix.Addr(), lastAddr(t.vipPrefix)
+	// The network and broadcast addresses of IPv4 subnets cannot be assigned
+	if first.Is4() && t.vipPrefix.Bits() < 31 {
+		first, last = first.Next(), last.Prev()
+	}
+	for addr := first; addr.IsValid() && addr.Compare(last) <= 0; addr = addr.Next() {
+		if !used.Has(addr.String()) {
+			return addr.String(), nil
+		}
+	}
+	return "", fmt.Errorf("no virtual IP left in %s", t.vipPrefix)
+}
+
+// objectName returns the name of the load balancer object of a Service, unique in the infra namespace.
+func (t *TenantLoadBalancers) objectName(service *corev1.Service) string {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(service.Namespace + "/" + service.Name))
+	return fmt.Sprintf("%s-svc-%08x", t.clusterName, hash.Sum32())
+}
+
+// isTenantLoadBalancerService checks if a Service is of type LoadBalancer, and not left to another implementation
+// with a load balancer class.
+func isTenantLoadBalancerService(service *corev1.Service) bool {
+	return service.Spec.Type == corev1.ServiceTypeLoadBalancer && service.Spec.LoadBalancerClass == nil &&
+		service.DeletionTimestamp.IsZero()
+}
+
+// servicePorts returns the ports of the load balancer object of a Service, forwarded to its node ports.
+func servicePorts(service *corev1.Service) []interface{} {
+	ports := []interface{}{}
+	for _, port := range service.Spec.Ports {
+		if port.NodePort == 0 {
+			continue
+		}
+		ports = append(ports, map[string]interface{}{
+			"name":        port.Name,
+			"protocol":    string(port.Protocol),
+			"port":        int64(port.Port),
+			"backendPort": int64(port.NodePort),
+		})
+	}
+	return ports
+}
+
+// nodeAddresses returns the sorted internal addresses of the ready Nodes.
+func nodeAddresses(nodes []corev1.Node) []interface{} {
+	var addresses []string
+	for _, node := range nodes {
+		if !isNodeReady(&node) {
+			continue
+		}
+		for _, address := range node.Status.Addresses {
+			if address.Type == corev1.NodeInternalIP {
+				addresses = append(addresses, address.Address)
+				break
+			}
+		}
+	}
+	sort.Strings(addresses)
+
+	backends := make([]interface{}, 0, len(addresses))
+	for _, address := range addresses {
+		backends = append(backends, address)
+	}
+	return backends
+}
+
+// filterNodes returns the Nodes with the given names.
+func filterNodes(nodes []corev1.Node, names sets.Set[string]) []corev1.Node {
+	var filtered []corev1.Node
+	for _, node := range nodes {
+		if names.Has(node.Name) {
+			filtered = append(filtered, node)
+		}
+	}
+	return filtered
+}
+
+func isNodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// setServiceIngress writes the virtual IP of its load balancer to the status of a Service.
+func setServiceIngress(ctx *context.ClusterContext, workloadClient runtimeclient.Client, service *corev1.Service, vip string) error {
+	ingress := []corev1.LoadBalancerIngress{{IP: vip}}
+	if apiequality.Semantic.DeepEqual(service.Status.LoadBalancer.Ingress, ingress) {
+		return nil
+	}
+	service.Status.LoadBalancer.Ingress = ingress
+	if err := workloadClient.Status().Update(ctx, service); err != nil {
+		return errors.Wrapf(err, "failed to update the status of service %s/%s", service.Namespace, service.Name)
+	}
+	return nil
+}
+
+// lastAddr returns the last address of a prefix.
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	bytes := prefix.Addr().AsSlice()
+	for bit := prefix.Bits(); bit < len(bytes)*8; bit++ {
+		bytes[bit/8] |= 0x80 >> (bit % 8)
+	}
+	addr, _ := netip.AddrFromSlice(bytes)
+	return addr
+}
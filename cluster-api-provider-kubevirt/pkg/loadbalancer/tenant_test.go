@@ -0,0 +1,237 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadbalancer_test
+
+import (
+	gocontext "context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/loadbalancer"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("Tenant load balancers", func() {
+	var (
+		infraClient    client.Client
+		workloadClient client.Client
+		kvCluster      *infrav1.KubevirtCluster
+		ctx            *context.ClusterContext
+	)
+
+	newService := func(name, loadBalancerIP string, nodePort int32) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: corev1.ServiceSpec{
+				Type:           corev1.ServiceTypeLoadBalancer,
+				LoadBalancerIP: loadBalancerIP,
+				Ports:          []corev1.ServicePort{{Name: "http", Protocol: corev1.ProtocolTCP, Port: 80, NodePort: nodePort}},
+			},
+		}
+	}
+
+	newNode := func(name, address string, ready corev1.ConditionStatus) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{
+				Addresses:  []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: address}},
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+			},
+		}
+	}
+
+	listObjects := func() []unstructured.Unstructured {
+		list := &unstructured.UnstructuredList{}
+		list.SetAPIVersion("sdn.example.com/v1")
+		list.SetKind("LoadBalancerList")
+		Expect(infraClient.List(ctx, list, client.InNamespace("test-namespace"))).To(Succeed())
+		return list.Items
+	}
+
+	getIngress := func(name string) []corev1.LoadBalancerIngress {
+		service := &corev1.Service{}
+		Expect(workloadClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, service)).To(Succeed())
+		return service.Status.LoadBalancer.Ingress
+	}
+
+	BeforeEach(func() {
+		kvCluster = testing.NewKubevirtCluster(clusterName, kubevirtClusterName)
+		kvCluster.Spec.TenantLoadBalancer = &infrav1.TenantLoadBalancerSpec{
+			APIVersion: "sdn.example.com/v1",
+			Kind:       "LoadBalancer",
+			VIPCIDR:    "10.10.0.192/30",
+			Spec:       runtime.RawExtension{Raw: []byte(`{"nat":true}`)},
+		}
+		ctx = &context.ClusterContext{
+			Logger:          ctrl.LoggerFrom(gocontext.TODO()).WithName("test"),
+			Context:         gocontext.TODO(),
+			Cluster:         cluster,
+			KubevirtCluster: kvCluster,
+		}
+
+		scheme := testing.SetupScheme()
+		gv := schema.GroupVersion{Group: "sdn.example.com", Version: "v1"}
+		scheme.AddKnownTypeWithName(gv.WithKind("LoadBalancer"), &unstructured.Unstructured{})
+		scheme.AddKnownTypeWithName(gv.WithKind("LoadBalancerList"), &unstructured.UnstructuredList{})
+		infraClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+	})
+
+	setupWorkloadClient := func(objects ...client.Object) {
+		workloadClient = fake.NewClientBuilder().
+			WithScheme(testing.SetupScheme()).
+			WithObjects(objects...).
+			WithStatusSubresource(&corev1.Service{}).
+			Build()
+	}
+
+	It("should provision a load balancer with a virtual IP for each service", func() {
+		setupWorkloadClient(
+			newService("web", "", 30080),
+			newService("requested", "10.10.0.194", 30081),
+			&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "internal"}},
+			newNode("node-b", "10.10.0.12", corev1.ConditionTrue),
+			newNode("node-a", "10.10.0.11", corev1.ConditionTrue),
+			newNode("node-c", "10.10.0.13", corev1.ConditionFalse),
+		)
+		tenantLoadBalancers, err := loadbalancer.NewTenantLoadBalancers(ctx, infraClient, "test-namespace")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(tenantLoadBalancers.Reconcile(ctx, workloadClient)).To(Succeed())
+
+		objects := listObjects()
+		Expect(objects).To(HaveLen(2))
+		vips := map[string]string{}
+		for _, object := range objects {
+			Expect(object.GetLabels()).To(HaveKeyWithValue(infrav1.TenantServiceLabel, "true"))
+			Expect(object.Object["spec"]).To(HaveKeyWithValue("nat", true))
+			Expect(object.Object["spec"]).To(HaveKeyWithValue("backends", ConsistOf("10.10.0.11", "10.10.0.12")))
+			vip, _, _ := unstructured.NestedString(object.Object, "spec", "vip")
+			vips[object.GetAnnotations()[infrav1.TenantServiceAnnotation]] = vip
+		}
+		Expect(vips).To(Equal(map[string]string{"default/web": "10.10.0.193", "default/requested": "10.10.0.194"}))
+
+		Expect(getIngress("web")).To(Equal([]corev1.LoadBalancerIngress{{IP: "10.10.0.193"}}))
+		Expect(getIngress("requested")).To(Equal([]corev1.LoadBalancerIngress{{IP: "10.10.0.194"}}))
+		Expect(getIngress("internal")).To(BeEmpty())
+	})
+
+	It("should keep the virtual IPs and delete the load balancers of the deleted services", func() {
+		setupWorkloadClient(newService("web", "", 30080), newService("other", "", 30081))
+		tenantLoadBalancers, err := loadbalancer.NewTenantLoadBalancers(ctx, infraClient, "test-namespace")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tenantLoadBalancers.Reconcile(ctx, workloadClient)).To(Succeed())
+		Expect(listObjects()).To(HaveLen(2))
+		ingress := getIngress("web")
+		Expect(ingress).To(HaveLen(1))
+
+		Expect(workloadClient.Delete(ctx, newService("other", "", 0))).To(Succeed())
+		Expect(tenantLoadBalancers.Reconcile(ctx, workloadClient)).To(Succeed())
+
+		objects := listObjects()
+		Expect(objects).To(HaveLen(1))
+		Expect(objects[0].GetAnnotations()).To(HaveKeyWithValue(infrav1.TenantServiceAnnotation, "default/web"))
+		Expect(getIngress("web")).To(Equal(ingress))
+
+		Expect(tenantLoadBalancers.Delete(ctx)).To(Succeed())
+		Expect(listObjects()).To(BeEmpty())
+	})
+
+	It("should never prune the load balancers of another KubevirtCluster of the same name", func() {
+		other := &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{}}}
+		other.SetAPIVersion("sdn.example.com/v1")
+		other.SetKind("LoadBalancer")
+		other.SetNamespace("test-namespace")
+		other.SetName(clusterName + "-svc-other")
+		other.SetLabels(map[string]string{"cluster.x-k8s.io/cluster-name": clusterName, infrav1.TenantServiceLabel: "true"})
+		other.SetAnnotations(map[string]string{infrav1.InfraOwnerAnnotation: "KubevirtCluster/another-namespace/" + kubevirtClusterName})
+		Expect(infraClient.Create(ctx, other)).To(Succeed())
+
+		setupWorkloadClient(newService("web", "", 30080))
+		tenantLoadBalancers, err := loadbalancer.NewTenantLoadBalancers(ctx, infraClient, "test-namespace")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tenantLoadBalancers.Reconcile(ctx, workloadClient)).To(Succeed())
+		Expect(listObjects()).To(HaveLen(2))
+
+		Expect(tenantLoadBalancers.Delete(ctx)).To(Succeed())
+		objects := listObjects()
+		Expect(objects).To(HaveLen(1))
+		Expect(objects[0].GetName()).To(Equal(other.GetName()))
+	})
+
+	It("should report the services left without a virtual IP", func() {
+		setupWorkloadClient(newService("a", "", 30080), newService("b", "", 30081), newService("c", "", 30082))
+		tenantLoadBalancers, err := loadbalancer.NewTenantLoadBalancers(ctx, infraClient, "test-namespace")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(tenantLoadBalancers.Reconcile(ctx, workloadClient)).To(MatchError(ContainSubstring("no virtual IP left in 10.10.0.192/30")))
+		Expect(listObjects()).To(HaveLen(2))
+	})
+
+	It("should only forward the traffic of the local services to the nodes of their endpoints without kube-proxy", func() {
+		kvCluster.Spec.TenantKubeProxyReplacement = infrav1.DetectKubeProxyReplacement
+		local := newService("local", "", 30080)
+		local.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyLocal
+		setupWorkloadClient(
+			local,
+			newService("cluster", "", 30081),
+			newNode("node-a", "10.10.0.11", corev1.ConditionTrue),
+			newNode("node-b", "10.10.0.12", corev1.ConditionTrue),
+			&discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "local-abcde",
+					Labels:    map[string]string{discoveryv1.LabelServiceName: "local"},
+				},
+				AddressType: discoveryv1.AddressTypeIPv4,
+				Endpoints: []discoveryv1.Endpoint{
+					{Addresses: []string{"192.168.0.10"}, NodeName: ptr.To("node-b")},
+					{Addresses: []string{"192.168.0.11"}, NodeName: ptr.To("node-a"), Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false)}},
+				},
+			},
+		)
+		tenantLoadBalancers, err := loadbalancer.NewTenantLoadBalancers(ctx, infraClient, "test-namespace")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(tenantLoadBalancers.Reconcile(ctx, workloadClient)).To(Succeed())
+
+		backends := map[string]interface{}{}
+		for _, object := range listObjects() {
+			backends[object.GetAnnotations()[infrav1.TenantServiceAnnotation]] = object.Object["spec"].(map[string]interface{})["backends"]
+		}
+		Expect(backends).To(HaveKeyWithValue("default/local", ConsistOf("10.10.0.12")))
+		Expect(backends).To(HaveKeyWithValue("default/cluster", ConsistOf("10.10.0.11", "10.10.0.12")))
+	})
+
+	It("should fail with an invalid virtual IP range", func() {
+		kvCluster.Spec.TenantLoadBalancer.VIPCIDR = "10.10.0.192"
+		_, err := loadbalancer.NewTenantLoadBalancers(ctx, infraClient, "test-namespace")
+		Expect(err).To(MatchError(ContainSubstring("invalid vipCIDR")))
+	})
+})
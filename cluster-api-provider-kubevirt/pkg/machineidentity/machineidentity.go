@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package machineidentity issues the client certificates identifying the machines of a cluster to its internal
+// services.
+package machineidentity
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+)
+
+// CA is the certificate authority signing the machine certificates.
+type CA struct {
+	Certificate    *x509.Certificate
+	CertificatePEM []byte
+	Key            crypto.Signer
+}
+
+// LoadCA reads the certificate authority from the tls.crt and tls.key entries of the secret.
+func LoadCA(secret *corev1.Secret) (*CA, error) {
+	certPEM, keyPEM := secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey]
+	if len(certPEM) == 0 || len(keyPEM) == 0 {
+		return nil, errors.Errorf("secret %s/%s is missing %s or %s", secret.Namespace, secret.Name, corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+	}
+
+	certs, err := certutil.ParseCertsPEM(certPEM)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the CA certificate of secret %s/%s", secret.Namespace, secret.Name)
+	}
+	if !certs[0].IsCA {
+		return nil, errors.Errorf("the certificate of secret %s/%s is not a CA", secret.Namespace, secret.Name)
+	}
+
+	key, err := keyutil.ParsePrivateKeyPEM(keyPEM)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the CA key of secret %s/%s", secret.Namespace, secret.Name)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.Errorf("the CA key of secret %s/%s cannot sign certificates", secret.Namespace, secret.Name)
+	}
+
+	return &CA{Certificate: certs[0], CertificatePEM: certPEM, Key: signer}, nil
+}
+
+// Issue creates a new key and a client certificate for the machine, signed by the CA and valid from now for the
+// validity. It returns the PEM encoded certificate and key.
+func (ca *CA) Issue(machineName, clusterName string, validity time.Duration) ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to generate the machine key")
+	}
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt64))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to generate the certificate serial number")
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName:   machineName,
+			Organization: []string{clusterName},
+		},
+		DNSNames:    []string{machineName},
+		NotBefore:   now.Add(-5 * time.Minute),
+		NotAfter:    now.Add(validity),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, ca.Certificate, key.Public(), ca.Key)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to sign the machine certificate")
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to encode the machine key")
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: certutil.CertificateBlockType, Bytes: certDER})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: keyutil.ECPrivateKeyBlockType, Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+// NotAfter returns the expiry time of the first certificate of the PEM data.
+func NotAfter(certPEM []byte) (time.Time, error) {
+	certs, err := certutil.ParseCertsPEM(certPEM)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "failed to parse the machine certificate")
+	}
+	return certs[0].NotAfter, nil
+}
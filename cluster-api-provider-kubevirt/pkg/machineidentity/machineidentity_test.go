@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machineidentity
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	certutil "k8s.io/client-go/util/cert"
+)
+
+var _ = Describe("Machine identity", func() {
+	var caSecret *corev1.Secret
+
+	BeforeEach(func() {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		cert, err := certutil.NewSelfSignedCACert(certutil.Config{CommonName: "machines-ca"}, key)
+		Expect(err).ToNot(HaveOccurred())
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		Expect(err).ToNot(HaveOccurred())
+
+		caSecret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "machines-ca"},
+			Data: map[string][]byte{
+				corev1.TLSCertKey:       pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}),
+				corev1.TLSPrivateKeyKey: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+			},
+		}
+	})
+
+	It("should issue a client certificate signed by the CA", func() {
+		ca, err := LoadCA(caSecret)
+		Expect(err).ToNot(HaveOccurred())
+
+		certPEM, keyPEM, err := ca.Issue("machine-a", "cluster-a", time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(keyPEM).ToNot(BeEmpty())
+
+		certs, err := certutil.ParseCertsPEM(certPEM)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(certs[0].Subject.CommonName).To(Equal("machine-a"))
+		Expect(certs[0].Subject.Organization).To(Equal([]string{"cluster-a"}))
+
+		roots := x509.NewCertPool()
+		roots.AddCert(ca.Certificate)
+		_, err = certs[0].Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+		Expect(err).ToNot(HaveOccurred())
+
+		notAfter, err := NotAfter(certPEM)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(notAfter).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+	})
+
+	It("should fail to load a secret without key", func() {
+		delete(caSecret.Data, corev1.TLSPrivateKeyKey)
+		_, err := LoadCA(caSecret)
+		Expect(err).To(MatchError(ContainSubstring("is missing")))
+	})
+
+	It("should fail to load a certificate that is not a CA", func() {
+		ca, err := LoadCA(caSecret)
+		Expect(err).ToNot(HaveOccurred())
+		certPEM, keyPEM, err := ca.Issue("machine-a", "cluster-a", time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+
+		caSecret.Data = map[string][]byte{corev1.TLSCertKey: certPEM, corev1.TLSPrivateKeyKey: keyPEM}
+		_, err = LoadCA(caSecret)
+		Expect(err).To(MatchError(ContainSubstring("is not a CA")))
+	})
+})
@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package maintenance handles the maintenance mode of KubevirtClusters, requested with the maintenance annotation.
+package maintenance
+
+import (
+	"time"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+const (
+	// DefaultDuration is the duration of a maintenance requested with "true".
+	DefaultDuration = 2 * time.Hour
+	// MaxDuration is the longest a maintenance can last, whatever was requested.
+	MaxDuration = 24 * time.Hour
+)
+
+// Resolve replaces the value of the maintenance annotation of the KubevirtCluster with the end time of the
+// maintenance, or removes the annotation if that time is past. It returns the end time of the current
+// maintenance, which is zero if the cluster is not in maintenance.
+func Resolve(kubevirtCluster *infrav1.KubevirtCluster, now time.Time) time.Time {
+	value, found := kubevirtCluster.Annotations[infrav1.MaintenanceAnnotation]
+	if !found {
+		return time.Time{}
+	}
+
+	end, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration <= 0 {
+			duration = DefaultDuration
+		}
+		end = now.Add(duration)
+	}
+	if latest := now.Add(MaxDuration); end.After(latest) {
+		end = latest
+	}
+	end = end.UTC().Truncate(time.Second)
+
+	if !now.Before(end) {
+		delete(kubevirtCluster.Annotations, infrav1.MaintenanceAnnotation)
+		return time.Time{}
+	}
+
+	kubevirtCluster.Annotations[infrav1.MaintenanceAnnotation] = end.Format(time.RFC3339)
+	return end
+}
+
+// InMaintenance returns true if the KubevirtCluster is in maintenance, without modifying it. A maintenance that
+// was not resolved yet by the KubevirtCluster controller is considered in progress.
+func InMaintenance(kubevirtCluster *infrav1.KubevirtCluster, now time.Time) bool {
+	value, found := kubevirtCluster.Annotations[infrav1.MaintenanceAnnotation]
+	if !found {
+		return false
+	}
+
+	end, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return true
+	}
+	return now.Before(end)
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+var _ = Describe("Maintenance", func() {
+	now := time.Date(2024, time.March, 4, 10, 0, 0, 0, time.UTC)
+
+	newKubevirtCluster := func(value string) *infrav1.KubevirtCluster {
+		return &infrav1.KubevirtCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "test-cluster",
+				Annotations: map[string]string{infrav1.MaintenanceAnnotation: value},
+			},
+		}
+	}
+
+	It("should not be in maintenance without the annotation", func() {
+		kubevirtCluster := &infrav1.KubevirtCluster{}
+		Expect(Resolve(kubevirtCluster, now).IsZero()).To(BeTrue())
+		Expect(InMaintenance(kubevirtCluster, now)).To(BeFalse())
+	})
+
+	DescribeTable("should resolve the end of the maintenance", func(value string, expected time.Time) {
+		kubevirtCluster := newKubevirtCluster(value)
+		Expect(InMaintenance(kubevirtCluster, now)).To(BeTrue())
+
+		end := Resolve(kubevirtCluster, now)
+		Expect(end).To(Equal(expected))
+		Expect(kubevirtCluster.Annotations).To(HaveKeyWithValue(infrav1.MaintenanceAnnotation, expected.Format(time.RFC3339)))
+		Expect(InMaintenance(kubevirtCluster, now)).To(BeTrue())
+
+		// the end time is stable
+		Expect(Resolve(kubevirtCluster, now.Add(time.Minute))).To(Equal(expected))
+	},
+		Entry("with the default duration", "true", now.Add(DefaultDuration)),
+		Entry("with a duration", "30m", now.Add(30*time.Minute)),
+		Entry("with a duration longer than the maximum", "72h", now.Add(MaxDuration)),
+		Entry("with an end time", "2024-03-04T12:00:00Z", now.Add(2*time.Hour)),
+	)
+
+	It("should remove the annotation once the maintenance expired", func() {
+		kubevirtCluster := newKubevirtCluster("1h")
+		end := Resolve(kubevirtCluster, now)
+
+		Expect(InMaintenance(kubevirtCluster, end)).To(BeFalse())
+		Expect(Resolve(kubevirtCluster, end).IsZero()).To(BeTrue())
+		Expect(kubevirtCluster.Annotations).ToNot(HaveKey(infrav1.MaintenanceAnnotation))
+	})
+})
@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"time"
+	// embed the time zone database, the controller image does not ship one.
+	_ "time/tzdata"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+var parser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+type window struct {
+	start cron.Schedule
+	end   cron.Schedule
+}
+
+// open returns true if t falls within the window, that is if the window ends before it starts again.
+func (w window) open(t time.Time) bool {
+	return w.end.Next(t).Before(w.start.Next(t))
+}
+
+// Windows is a set of recurring maintenance windows, disruptive changes are only applied in.
+type Windows struct {
+	windows []window
+}
+
+// NewWindows parses maintenance windows.
+func NewWindows(maintenanceWindows []infrav1.MaintenanceWindow) (*Windows, error) {
+	w := &Windows{}
+	for i, maintenanceWindow := range maintenanceWindows {
+		location := time.UTC
+		if maintenanceWindow.TimeZone != "" {
+			var err error
+			if location, err = time.LoadLocation(maintenanceWindow.TimeZone); err != nil {
+				return nil, errors.Wrapf(err, "invalid time zone in maintenance window %d", i)
+			}
+		}
+
+		start, err := parser.Parse(maintenanceWindow.Start)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid start expression in maintenance window %d", i)
+		}
+		end, err := parser.Parse(maintenanceWindow.End)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid end expression in maintenance window %d", i)
+		}
+
+		w.windows = append(w.windows, window{
+			start: inLocation(start, location),
+			end:   inLocation(end, location),
+		})
+	}
+	return w, nil
+}
+
+// Open returns true if t falls within one of the windows, or if there are no windows at all.
+func (w *Windows) Open(t time.Time) bool {
+	if len(w.windows) == 0 {
+		return true
+	}
+	for _, window := range w.windows {
+		if window.open(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpening returns the first time after t at which a window starts, or the zero time if there are no windows.
+func (w *Windows) NextOpening(t time.Time) time.Time {
+	var next time.Time
+	for _, window := range w.windows {
+		if candidate := window.start.Next(t); !candidate.IsZero() && (next.IsZero() || candidate.Before(next)) {
+			next = candidate
+		}
+	}
+	return next
+}
+
+// inLocation evaluates a cron schedule in the given time zone.
+func inLocation(schedule cron.Schedule, location *time.Location) cron.Schedule {
+	if spec, ok := schedule.(*cron.SpecSchedule); ok {
+		spec.Location = location
+	}
+	return schedule
+}
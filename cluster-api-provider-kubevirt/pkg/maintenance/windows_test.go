@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+var _ = Describe("Windows", func() {
+	paris, _ := time.LoadLocation("Europe/Paris")
+	// 2024-01-10 is a Wednesday
+	at := func(day, hour int) time.Time {
+		return time.Date(2024, time.January, day, hour, 0, 0, 0, paris)
+	}
+
+	weekends := []infrav1.MaintenanceWindow{{
+		Start:    "0 22 * * 6",
+		End:      "0 4 * * 0",
+		TimeZone: "Europe/Paris",
+	}}
+
+	It("should be closed outside of the windows", func() {
+		windows, err := NewWindows(weekends)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(windows.Open(at(10, 12))).To(BeFalse())
+		Expect(windows.NextOpening(at(10, 12))).To(BeTemporally("==", at(13, 22)))
+	})
+
+	It("should be open within a window", func() {
+		windows, err := NewWindows(weekends)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(windows.Open(at(13, 22))).To(BeTrue())
+		Expect(windows.Open(at(14, 3))).To(BeTrue())
+		Expect(windows.Open(at(14, 4))).To(BeFalse())
+	})
+
+	It("should always be open without windows", func() {
+		windows, err := NewWindows(nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(windows.Open(at(10, 12))).To(BeTrue())
+		Expect(windows.NextOpening(at(10, 12))).To(BeZero())
+	})
+
+	It("should reject invalid windows", func() {
+		_, err := NewWindows([]infrav1.MaintenanceWindow{{Start: "0 22 * *", End: "0 4 * * 0"}})
+		Expect(err).To(HaveOccurred())
+		_, err = NewWindows([]infrav1.MaintenanceWindow{{Start: "0 22 * * 6", End: "0 4 * * 0", TimeZone: "Mars/Olympus"}})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package oidckubeconfig generates the kubeconfigs of the end users of the workload clusters, logging them in with
+// an OIDC identity provider, from the admin kubeconfigs of the clusters stripped of their credentials.
+package oidckubeconfig
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+// The kubeconfigs run kubelogin as a kubectl plugin, which logs the users in with their browser.
+const (
+	execCommand    = "kubectl"
+	execAPIVersion = "client.authentication.k8s.io/v1beta1"
+)
+
+var execArgs = []string{"oidc-login", "get-token"}
+
+// SecretName returns the name of the secret the OIDC kubeconfig of a cluster is stored in.
+func SecretName(clusterName string) string {
+	return clusterName + "-oidc-kubeconfig"
+}
+
+// Generate returns the OIDC kubeconfig of a cluster. It reaches the server and trusts the certificate authority of
+// the current context of the admin kubeconfig, or the given server if not empty.
+func Generate(adminKubeconfig []byte, clusterName, server string, spec *infrav1.OIDCKubeconfigSpec) ([]byte, error) {
+	admin, err := clientcmd.Load(adminKubeconfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the admin kubeconfig")
+	}
+	adminContext, ok := admin.Contexts[admin.CurrentContext]
+	if !ok {
+		return nil, errors.Errorf("the current context %q of the admin kubeconfig is missing", admin.CurrentContext)
+	}
+	adminCluster, ok := admin.Clusters[adminContext.Cluster]
+	if !ok {
+		return nil, errors.Errorf("the cluster %q of the admin kubeconfig is missing", adminContext.Cluster)
+	}
+
+	cluster := clientcmdapi.NewCluster()
+	cluster.Server = adminCluster.Server
+	if server != "" {
+		cluster.Server = server
+	}
+	cluster.CertificateAuthorityData = adminCluster.CertificateAuthorityData
+	cluster.TLSServerName = adminCluster.TLSServerName
+
+	args := append([]string{}, execArgs...)
+	args = append(args, "--oidc-issuer-url="+spec.IssuerURL, "--oidc-client-id="+spec.ClientID)
+	for _, scope := range spec.ExtraScopes {
+		args = append(args, "--oidc-extra-scope="+scope)
+	}
+	user := clientcmdapi.NewAuthInfo()
+	user.Exec = &clientcmdapi.ExecConfig{
+		Command:         execCommand,
+		Args:            args,
+		APIVersion:      execAPIVersion,
+		InteractiveMode: clientcmdapi.IfAvailableExecInteractiveMode,
+	}
+
+	userName := clusterName + "-oidc"
+	contextName := userName + "@" + clusterName
+	context := clientcmdapi.NewContext()
+	context.Cluster = clusterName
+	context.AuthInfo = userName
+
+	config := clientcmdapi.NewConfig()
+	config.Clusters[clusterName] = cluster
+	config.AuthInfos[userName] = user
+	config.Contexts[contextName] = context
+	config.CurrentContext = contextName
+	return clientcmd.Write(*config)
+}
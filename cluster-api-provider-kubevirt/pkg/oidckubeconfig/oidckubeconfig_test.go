@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oidckubeconfig_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/oidckubeconfig"
+)
+
+var _ = Describe("Generate", func() {
+	var adminKubeconfig []byte
+
+	BeforeEach(func() {
+		admin := clientcmdapi.NewConfig()
+		admin.Clusters["test-cluster"] = &clientcmdapi.Cluster{Server: "https://10.0.0.1:6443", CertificateAuthorityData: []byte("ca")}
+		admin.AuthInfos["test-cluster-admin"] = &clientcmdapi.AuthInfo{ClientCertificateData: []byte("cert"), ClientKeyData: []byte("key")}
+		admin.Contexts["test-cluster-admin@test-cluster"] = &clientcmdapi.Context{Cluster: "test-cluster", AuthInfo: "test-cluster-admin"}
+		admin.CurrentContext = "test-cluster-admin@test-cluster"
+		var err error
+		adminKubeconfig, err = clientcmd.Write(*admin)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	spec := &infrav1.OIDCKubeconfigSpec{
+		IssuerURL:   "https://issuer.example.com",
+		ClientID:    "kubernetes",
+		ExtraScopes: []string{"email", "groups"},
+	}
+
+	It("logs in with the OIDC client and keeps no admin credentials", func() {
+		kubeconfig, err := oidckubeconfig.Generate(adminKubeconfig, "test-cluster", "", spec)
+		Expect(err).ToNot(HaveOccurred())
+
+		config, err := clientcmd.Load(kubeconfig)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config.CurrentContext).To(Equal("test-cluster-oidc@test-cluster"))
+		Expect(config.Clusters).To(HaveKey("test-cluster"))
+		Expect(config.Clusters["test-cluster"].Server).To(Equal("https://10.0.0.1:6443"))
+		Expect(config.Clusters["test-cluster"].CertificateAuthorityData).To(Equal([]byte("ca")))
+		Expect(config.AuthInfos).To(HaveLen(1))
+		user := config.AuthInfos["test-cluster-oidc"]
+		Expect(user).ToNot(BeNil())
+		Expect(user.ClientCertificateData).To(BeEmpty())
+		Expect(user.ClientKeyData).To(BeEmpty())
+		Expect(user.Exec).ToNot(BeNil())
+		Expect(user.Exec.Command).To(Equal("kubectl"))
+		Expect(user.Exec.Args).To(Equal([]string{
+			"oidc-login", "get-token",
+			"--oidc-issuer-url=https://issuer.example.com",
+			"--oidc-client-id=kubernetes",
+			"--oidc-extra-scope=email",
+			"--oidc-extra-scope=groups",
+		}))
+	})
+
+	It("reaches the given server", func() {
+		kubeconfig, err := oidckubeconfig.Generate(adminKubeconfig, "test-cluster", "https://api.example.com:443", spec)
+		Expect(err).ToNot(HaveOccurred())
+
+		config, err := clientcmd.Load(kubeconfig)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config.Clusters["test-cluster"].Server).To(Equal("https://api.example.com:443"))
+	})
+
+	It("fails when the admin kubeconfig has no current context", func() {
+		_, err := oidckubeconfig.Generate([]byte("apiVersion: v1\nkind: Config\n"), "test-cluster", "", spec)
+		Expect(err).To(HaveOccurred())
+	})
+})
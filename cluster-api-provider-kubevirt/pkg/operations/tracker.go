@@ -0,0 +1,226 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package operations runs the long operations of the controllers, e.g. the drains of the Nodes, in the background,
+// so that the reconciles poll them rather than hold a worker until they complete.
+package operations
+
+import (
+	gocontext "context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+// PollInterval is the interval the reconciles poll the running operations at, in case the event of their
+// completion was missed.
+const PollInterval = 10 * time.Second
+
+var (
+	// Timeout is the maximal duration of an operation, from the time it gets a slot to run.
+	Timeout = 15 * time.Minute
+)
+
+// Func is a long operation. Its context is cancelled at its timeout, or when the tracker stops, but not at the end
+// of the reconcile that started it.
+type Func func(ctx gocontext.Context) (interface{}, error)
+
+// Operation is the state of a long operation of an object.
+type Operation struct {
+	// Type is the type of the operation, unique per object.
+	Type string
+
+	// StartTime is the time the operation was started.
+	StartTime time.Time
+
+	// CompletionTime is the time the operation completed, zero while it runs.
+	CompletionTime time.Time
+
+	// Result is the result of the operation, once completed.
+	Result interface{}
+
+	// Err is the error the operation failed with, if any.
+	Err error
+}
+
+// Done returns whether the operation completed.
+func (o Operation) Done() bool {
+	return !o.CompletionTime.IsZero()
+}
+
+type key struct {
+	object        types.NamespacedName
+	operationType string
+}
+
+// Tracker runs the long operations of the objects of a controller in the background.
+type Tracker struct {
+	slots  chan struct{}
+	events chan event.GenericEvent
+
+	stopped gocontext.Context
+	stop    gocontext.CancelFunc
+	running sync.WaitGroup
+
+	lock       sync.Mutex
+	operations map[key]*Operation
+}
+
+// NewTracker returns a tracker running up to maxConcurrent operations at once, the other ones waiting for a slot.
+// maxConcurrent must be positive.
+func NewTracker(maxConcurrent int) *Tracker {
+	stopped, stop := gocontext.WithCancel(gocontext.Background())
+	return &Tracker{
+		slots:      make(chan struct{}, maxConcurrent),
+		stopped:    stopped,
+		stop:       stop,
+		operations: map[key]*Operation{},
+	}
+}
+
+// Start waits for the context to be done, then cancels the running operations and waits for them to return. Added
+// to the manager, the tracker stops with the controllers, before the leader election lease is released, so that
+// the operations of a replica losing the lead do not run along with the ones started again by the next leader.
+func (t *Tracker) Start(ctx gocontext.Context) error {
+	<-ctx.Done()
+	t.stop()
+	t.running.Wait()
+	return nil
+}
+
+// Source returns the source of the events enqueueing the objects whose operations completed, for the controller
+// polling them to watch. It must be called before the first operation starts.
+func (t *Tracker) Source() source.Source {
+	t.events = make(chan event.GenericEvent)
+	return source.Channel(t.events, &handler.EnqueueRequestForObject{})
+}
+
+// Poll starts the operation of the type for the object unless it was already started, and returns its state. A
+// completed operation is returned once, then forgotten, so that the next poll starts it again. A nil tracker runs
+// the operation synchronously.
+func (t *Tracker) Poll(ctx gocontext.Context, obj client.Object, operationType string, fn Func) Operation {
+	if t == nil {
+		operation := Operation{Type: operationType, StartTime: time.Now()}
+		operation.Result, operation.Err = fn(ctx)
+		operation.CompletionTime = time.Now()
+		return operation
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	k := key{object: client.ObjectKeyFromObject(obj), operationType: operationType}
+	if operation, found := t.operations[k]; found {
+		if operation.Done() {
+			delete(t.operations, k)
+		}
+		return *operation
+	}
+	t.forgetExpired()
+
+	operation := &Operation{Type: operationType, StartTime: time.Now()}
+	t.operations[k] = operation
+	notified := &metav1.PartialObjectMetadata{
+		ObjectMeta: metav1.ObjectMeta{Namespace: obj.GetNamespace(), Name: obj.GetName()},
+	}
+	t.running.Add(1)
+	go t.run(gocontext.WithoutCancel(ctx), operation, notified, fn)
+	return *operation
+}
+
+func (t *Tracker) run(ctx gocontext.Context, operation *Operation, notified client.Object, fn Func) {
+	defer t.running.Done()
+
+	var result interface{}
+	var err error
+	select {
+	case t.slots <- struct{}{}:
+		result, err = t.call(ctx, fn)
+		<-t.slots
+	case <-t.stopped.Done():
+		err = errors.New("the operation was not started before the controller stopped")
+	}
+
+	t.lock.Lock()
+	operation.Result, operation.Err, operation.CompletionTime = result, err, time.Now()
+	t.lock.Unlock()
+
+	if t.events != nil {
+		select {
+		case t.events <- event.GenericEvent{Object: notified}:
+		case <-t.stopped.Done():
+		}
+	}
+}
+
+func (t *Tracker) call(ctx gocontext.Context, fn Func) (result interface{}, err error) {
+	ctx, cancel := gocontext.WithTimeout(ctx, Timeout)
+	defer cancel()
+	stop := gocontext.AfterFunc(t.stopped, cancel)
+	defer stop()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("operation panicked: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
+
+// forgetExpired forgets the operations completed for longer than the timeout, which were not polled again, e.g.
+// because their object was deleted.
+func (t *Tracker) forgetExpired() {
+	for k, operation := range t.operations {
+		if operation.Done() && time.Since(operation.CompletionTime) > Timeout {
+			delete(t.operations, k)
+		}
+	}
+}
+
+// SetRecord records the operation in the operations of the status of a machine, replacing the record of the
+// previous operation of its type.
+func SetRecord(records *[]infrav1.MachineOperation, operation Operation) {
+	record := infrav1.MachineOperation{
+		Type:      operation.Type,
+		Phase:     infrav1.RunningMachineOperationPhase,
+		StartTime: metav1.NewTime(operation.StartTime),
+	}
+	if operation.Done() {
+		record.Phase = infrav1.SucceededMachineOperationPhase
+		record.CompletionTime = &metav1.Time{Time: operation.CompletionTime}
+		if operation.Err != nil {
+			record.Phase = infrav1.FailedMachineOperationPhase
+			record.Message = operation.Err.Error()
+		}
+	}
+
+	for i := range *records {
+		if (*records)[i].Type == record.Type {
+			(*records)[i] = record
+			return
+		}
+	}
+	*records = append(*records, record)
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operations
+
+import (
+	gocontext "context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+var _ = Describe("Operation tracker", func() {
+	var (
+		ctx             = gocontext.Background()
+		kubevirtMachine *infrav1.KubevirtMachine
+	)
+
+	BeforeEach(func() {
+		kubevirtMachine = newKubevirtMachine("test-machine")
+	})
+
+	It("should run the operations synchronously without a tracker", func() {
+		var tracker *Tracker
+		operation := tracker.Poll(ctx, kubevirtMachine, "Drain", func(gocontext.Context) (interface{}, error) {
+			return "drained", nil
+		})
+
+		Expect(operation.Done()).To(BeTrue())
+		Expect(operation.Result).To(Equal("drained"))
+	})
+
+	It("should run an operation in the background and return its result once", func() {
+		tracker := NewTracker(1)
+		tracker.events = make(chan event.GenericEvent, 1)
+		release := make(chan struct{})
+		runs := 0
+		drain := func(gocontext.Context) (interface{}, error) {
+			runs++
+			<-release
+			return nil, errors.New("pods not evicted")
+		}
+
+		operation := tracker.Poll(ctx, kubevirtMachine, "Drain", drain)
+		Expect(operation.Done()).To(BeFalse())
+		Expect(tracker.Poll(ctx, kubevirtMachine, "Drain", drain).Done()).To(BeFalse())
+
+		close(release)
+		var completed event.GenericEvent
+		Eventually(tracker.events).Should(Receive(&completed))
+		Expect(completed.Object.GetName()).To(Equal(kubevirtMachine.Name))
+
+		operation = tracker.Poll(ctx, kubevirtMachine, "Drain", drain)
+		Expect(operation.Done()).To(BeTrue())
+		Expect(operation.Err).To(MatchError("pods not evicted"))
+		Expect(runs).To(Equal(1))
+
+		// the completed operation is forgotten, the next poll starts it again
+		Expect(tracker.Poll(ctx, kubevirtMachine, "Drain", drain).Done()).To(BeFalse())
+		Eventually(tracker.events).Should(Receive())
+		Expect(runs).To(Equal(2))
+	})
+
+	It("should wait for a slot to run an operation", func() {
+		tracker := NewTracker(1)
+		release := make(chan struct{})
+		started := make(chan string, 2)
+		operation := func(name string) Func {
+			return func(gocontext.Context) (interface{}, error) {
+				started <- name
+				<-release
+				return nil, nil
+			}
+		}
+		otherMachine := newKubevirtMachine("other-machine")
+
+		tracker.Poll(ctx, kubevirtMachine, "Drain", operation("first"))
+		tracker.Poll(ctx, otherMachine, "Drain", operation("second"))
+
+		var first, second string
+		Eventually(started).Should(Receive(&first))
+		Consistently(started, 100*time.Millisecond).ShouldNot(Receive())
+		close(release)
+		Eventually(started).Should(Receive(&second))
+		Expect([]string{first, second}).To(ConsistOf("first", "second"))
+	})
+
+	It("should cancel the running operations and wait for them when stopped", func() {
+		tracker := NewTracker(1)
+		tracker.events = make(chan event.GenericEvent)
+		cancelled := make(chan struct{})
+		drain := func(ctx gocontext.Context) (interface{}, error) {
+			<-ctx.Done()
+			time.Sleep(50 * time.Millisecond)
+			close(cancelled)
+			return nil, ctx.Err()
+		}
+		otherMachine := newKubevirtMachine("other-machine")
+		tracker.Poll(ctx, kubevirtMachine, "Drain", drain)
+		tracker.Poll(ctx, otherMachine, "Drain", drain)
+
+		managerCtx, stopManager := gocontext.WithCancel(ctx)
+		stopped := make(chan error)
+		go func() { stopped <- tracker.Start(managerCtx) }()
+		Consistently(stopped, 100*time.Millisecond).ShouldNot(Receive())
+
+		stopManager()
+		Eventually(stopped).Should(Receive(BeNil()))
+		Expect(cancelled).To(BeClosed(), "the running operation returned before the tracker stopped")
+		Expect([]error{
+			tracker.Poll(ctx, kubevirtMachine, "Drain", drain).Err,
+			tracker.Poll(ctx, otherMachine, "Drain", drain).Err,
+		}).To(ConsistOf(MatchError(gocontext.Canceled), MatchError(ContainSubstring("not started"))))
+	})
+
+	It("should fail the operations that panic", func() {
+		tracker := NewTracker(1)
+		tracker.events = make(chan event.GenericEvent, 1)
+		panicking := func(gocontext.Context) (interface{}, error) {
+			panic("nil node")
+		}
+
+		tracker.Poll(ctx, kubevirtMachine, "Drain", panicking)
+		Eventually(tracker.events).Should(Receive())
+		Expect(tracker.Poll(ctx, kubevirtMachine, "Drain", panicking).Err).To(MatchError(ContainSubstring("nil node")))
+	})
+
+	It("should record the operations in the status of the machine", func() {
+		start := time.Date(2024, time.March, 4, 10, 0, 0, 0, time.UTC)
+		records := []infrav1.MachineOperation{{Type: "Command/kubelet-logs", Phase: infrav1.SucceededMachineOperationPhase}}
+
+		SetRecord(&records, Operation{Type: "Drain", StartTime: start})
+		Expect(records).To(HaveLen(2))
+		Expect(records[1].Phase).To(Equal(infrav1.RunningMachineOperationPhase))
+		Expect(records[1].CompletionTime).To(BeNil())
+
+		SetRecord(&records, Operation{Type: "Drain", StartTime: start, CompletionTime: start.Add(time.Minute), Err: errors.New("pods not evicted")})
+		Expect(records).To(HaveLen(2))
+		Expect(records[1].Phase).To(Equal(infrav1.FailedMachineOperationPhase))
+		Expect(records[1].Message).To(Equal("pods not evicted"))
+		Expect(records[1].CompletionTime.Time).To(Equal(start.Add(time.Minute)))
+	})
+})
+
+func newKubevirtMachine(name string) *infrav1.KubevirtMachine {
+	return &infrav1.KubevirtMachine{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test-namespace", Name: name},
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ownership implements the lease a management cluster holds in the infra cluster before mutating the
+// infra resources of a cluster, and the verification of the owner of the infra objects before deleting them.
+package ownership
+
+import (
+	gocontext "context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+const (
+	// PreviousHolderAnnotation records on the lease the holder it was acquired from.
+	PreviousHolderAnnotation = "capk.cluster.x-k8s.io/previous-holder"
+
+	// TakenOverAnnotation records on the lease the time it was last taken over before expiring.
+	TakenOverAnnotation = "capk.cluster.x-k8s.io/taken-over"
+)
+
+// Result is the outcome of an attempt to acquire the lease.
+type Result struct {
+	// Held is true if the management cluster holds the lease.
+	Held bool
+
+	// Holder is the holder of the lease.
+	Holder string
+
+	// PreviousHolder is the holder the lease was just acquired from, if any.
+	PreviousHolder string
+
+	// TakenOver is true if the lease was just taken over from a holder it had not expired for.
+	TakenOver bool
+}
+
+// LeaseName returns the name of the lease of the KubevirtCluster in the infra cluster.
+func LeaseName(kubevirtCluster *infrav1.KubevirtCluster) string {
+	return fmt.Sprintf("capk-%s-%s", kubevirtCluster.Namespace, kubevirtCluster.Name)
+}
+
+// HolderIdentity identifies a KubevirtCluster in a management cluster. The UID differs between the copies of a
+// KubevirtCluster moved from one management cluster to another.
+func HolderIdentity(kubevirtCluster *infrav1.KubevirtCluster) string {
+	return fmt.Sprintf("%s/%s@%s", kubevirtCluster.Namespace, kubevirtCluster.Name, kubevirtCluster.UID)
+}
+
+// Acquire creates, renews or acquires the lease of the KubevirtCluster in the namespace of the infra cluster. The
+// lease of another holder is only acquired once expired, unless takeOver is true. The lease is renewed when a third
+// of its duration elapsed. Concurrent attempts are arbitrated by the apiserver with a conflict error.
+func Acquire(ctx gocontext.Context, c client.Client, namespace string, kubevirtCluster *infrav1.KubevirtCluster, duration time.Duration, takeOver bool) (*Result, error) {
+	identity := HolderIdentity(kubevirtCluster)
+	now := metav1.NewMicroTime(time.Now())
+
+	lease := &coordinationv1.Lease{}
+	key := client.ObjectKey{Namespace: namespace, Name: LeaseName(kubevirtCluster)}
+	if err := c.Get(ctx, key, lease); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, errors.Wrap(err, "failed to get the ownership lease")
+		}
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      key.Name,
+				Labels:    map[string]string{clusterv1.ClusterNameLabel: kubevirtCluster.Name},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       ptr.To(identity),
+				LeaseDurationSeconds: ptr.To(int32(duration.Seconds())),
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}
+		if err := c.Create(ctx, lease); err != nil {
+			return nil, errors.Wrap(err, "failed to create the ownership lease")
+		}
+		return &Result{Held: true, Holder: identity}, nil
+	}
+
+	holder := ptr.Deref(lease.Spec.HolderIdentity, "")
+	if holder == identity {
+		if lease.Spec.RenewTime != nil && now.Sub(lease.Spec.RenewTime.Time) < duration/3 {
+			return &Result{Held: true, Holder: identity}, nil
+		}
+		lease.Spec.RenewTime = &now
+		lease.Spec.LeaseDurationSeconds = ptr.To(int32(duration.Seconds()))
+		if err := c.Update(ctx, lease); err != nil {
+			return nil, errors.Wrap(err, "failed to renew the ownership lease")
+		}
+		return &Result{Held: true, Holder: identity}, nil
+	}
+
+	expired := holder == "" || IsExpired(lease, now.Time)
+	if !expired && !takeOver {
+		return &Result{Holder: holder}, nil
+	}
+
+	if lease.Annotations == nil {
+		lease.Annotations = map[string]string{}
+	}
+	lease.Annotations[PreviousHolderAnnotation] = holder
+	if !expired {
+		lease.Annotations[TakenOverAnnotation] = now.UTC().Format(time.RFC3339)
+	}
+	lease.Spec.HolderIdentity = ptr.To(identity)
+	lease.Spec.LeaseDurationSeconds = ptr.To(int32(duration.Seconds()))
+	lease.Spec.AcquireTime = &now
+	lease.Spec.RenewTime = &now
+	lease.Spec.LeaseTransitions = ptr.To(ptr.Deref(lease.Spec.LeaseTransitions, 0) + 1)
+	if err := c.Update(ctx, lease); err != nil {
+		return nil, errors.Wrap(err, "failed to acquire the ownership lease")
+	}
+	return &Result{Held: true, Holder: identity, PreviousHolder: holder, TakenOver: !expired}, nil
+}
+
+// Release deletes the lease of the KubevirtCluster if it holds it.
+func Release(ctx gocontext.Context, c client.Client, namespace string, kubevirtCluster *infrav1.KubevirtCluster) error {
+	lease := &coordinationv1.Lease{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: LeaseName(kubevirtCluster)}, lease); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if ptr.Deref(lease.Spec.HolderIdentity, "") != HolderIdentity(kubevirtCluster) {
+		return nil
+	}
+	return client.IgnoreNotFound(c.Delete(ctx, lease, client.Preconditions{ResourceVersion: &lease.ResourceVersion}))
+}
+
+// IsExpired returns true if the lease was not renewed within its duration.
+func IsExpired(lease *coordinationv1.Lease, now time.Time) bool {
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	return now.After(lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second))
+}
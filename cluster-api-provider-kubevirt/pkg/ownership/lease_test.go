@@ -0,0 +1,128 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ownership
+
+import (
+	gocontext "context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("Ownership lease", func() {
+	var (
+		ctx             = gocontext.Background()
+		fakeClient      client.Client
+		kubevirtCluster *infrav1.KubevirtCluster
+		otherCluster    *infrav1.KubevirtCluster
+	)
+
+	BeforeEach(func() {
+		fakeClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).Build()
+		kubevirtCluster = testing.NewKubevirtCluster("cluster", "kvcluster")
+		kubevirtCluster.Namespace = "default"
+		kubevirtCluster.UID = "uid-1"
+		otherCluster = kubevirtCluster.DeepCopy()
+		otherCluster.UID = "uid-2"
+	})
+
+	getLease := func() *coordinationv1.Lease {
+		lease := &coordinationv1.Lease{}
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "infra", Name: "capk-default-kvcluster"}, lease)).To(Succeed())
+		return lease
+	}
+
+	It("should create the lease and renew it", func() {
+		result, err := Acquire(ctx, fakeClient, "infra", kubevirtCluster, time.Minute, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Held).To(BeTrue())
+		Expect(*getLease().Spec.HolderIdentity).To(Equal("default/kvcluster@uid-1"))
+
+		lease := getLease()
+		lease.Spec.RenewTime = &metav1.MicroTime{Time: time.Now().Add(-30 * time.Second)}
+		Expect(fakeClient.Update(ctx, lease)).To(Succeed())
+
+		result, err = Acquire(ctx, fakeClient, "infra", kubevirtCluster, time.Minute, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Held).To(BeTrue())
+		Expect(getLease().Spec.RenewTime.Time).To(BeTemporally("~", time.Now(), time.Second))
+	})
+
+	It("should not acquire the valid lease of another holder", func() {
+		_, err := Acquire(ctx, fakeClient, "infra", otherCluster, time.Minute, false)
+		Expect(err).ToNot(HaveOccurred())
+
+		result, err := Acquire(ctx, fakeClient, "infra", kubevirtCluster, time.Minute, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Held).To(BeFalse())
+		Expect(result.Holder).To(Equal("default/kvcluster@uid-2"))
+	})
+
+	It("should acquire the expired lease of another holder", func() {
+		_, err := Acquire(ctx, fakeClient, "infra", otherCluster, time.Minute, false)
+		Expect(err).ToNot(HaveOccurred())
+		lease := getLease()
+		lease.Spec.RenewTime = &metav1.MicroTime{Time: time.Now().Add(-2 * time.Minute)}
+		Expect(fakeClient.Update(ctx, lease)).To(Succeed())
+
+		result, err := Acquire(ctx, fakeClient, "infra", kubevirtCluster, time.Minute, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Held).To(BeTrue())
+		Expect(result.TakenOver).To(BeFalse())
+		Expect(result.PreviousHolder).To(Equal("default/kvcluster@uid-2"))
+
+		lease = getLease()
+		Expect(lease.Annotations).To(HaveKeyWithValue(PreviousHolderAnnotation, "default/kvcluster@uid-2"))
+		Expect(lease.Annotations).ToNot(HaveKey(TakenOverAnnotation))
+		Expect(ptr.Deref(lease.Spec.LeaseTransitions, 0)).To(Equal(int32(1)))
+	})
+
+	It("should take the valid lease of another holder over", func() {
+		_, err := Acquire(ctx, fakeClient, "infra", otherCluster, time.Minute, false)
+		Expect(err).ToNot(HaveOccurred())
+
+		result, err := Acquire(ctx, fakeClient, "infra", kubevirtCluster, time.Minute, true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Held).To(BeTrue())
+		Expect(result.TakenOver).To(BeTrue())
+		Expect(getLease().Annotations).To(HaveKey(TakenOverAnnotation))
+
+		result, err = Acquire(ctx, fakeClient, "infra", otherCluster, time.Minute, false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Held).To(BeFalse())
+	})
+
+	It("should only release the lease it holds", func() {
+		_, err := Acquire(ctx, fakeClient, "infra", otherCluster, time.Minute, false)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(Release(ctx, fakeClient, "infra", kubevirtCluster)).To(Succeed())
+		getLease()
+
+		Expect(Release(ctx, fakeClient, "infra", otherCluster)).To(Succeed())
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: "infra", Name: "capk-default-kvcluster"}, &coordinationv1.Lease{})).ToNot(Succeed())
+	})
+})
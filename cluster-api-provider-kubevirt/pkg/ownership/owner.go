@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ownership
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+// NotOwnedError is returned for the infra objects the provider did not create for the owner they would be deleted
+// for, e.g. the VM of a user merely named after a machine.
+type NotOwnedError struct {
+	Object string
+	Owner  string
+	Reason string
+}
+
+func (e *NotOwnedError) Error() string {
+	return fmt.Sprintf("%s was not created for %s: %s", e.Object, e.Owner, e.Reason)
+}
+
+// IsNotOwned returns true if the error reports an infra object not created for its owner.
+func IsNotOwned(err error) bool {
+	var notOwned *NotOwnedError
+	return errors.As(err, &notOwned)
+}
+
+// ClusterOwner returns the owner recorded on the infra objects created for a KubevirtCluster.
+func ClusterOwner(kubevirtCluster *infrav1.KubevirtCluster) string {
+	return fmt.Sprintf("KubevirtCluster/%s/%s", kubevirtCluster.Namespace, kubevirtCluster.Name)
+}
+
+// MachineOwner returns the owner recorded on the infra objects created for a KubevirtMachine.
+func MachineOwner(kubevirtMachine *infrav1.KubevirtMachine) string {
+	return fmt.Sprintf("KubevirtMachine/%s/%s", kubevirtMachine.Namespace, kubevirtMachine.Name)
+}
+
+// SetOwner records the owner an infra object is created for in its owner annotation. The annotations are copied,
+// as they may be shared with the template of the object.
+func SetOwner(obj metav1.Object, owner string) {
+	annotations := make(map[string]string, len(obj.GetAnnotations())+1)
+	for key, value := range obj.GetAnnotations() {
+		annotations[key] = value
+	}
+	annotations[infrav1.InfraOwnerAnnotation] = owner
+	obj.SetAnnotations(annotations)
+}
+
+// VerifyOwner returns a NotOwnedError unless the infra object carries the labels and the owner annotation the
+// provider sets on the objects it creates for the owner. The objects created before the owner annotation was
+// introduced are verified with their labels only, and there must be some.
+func VerifyOwner(obj metav1.Object, owner string, labels map[string]string) error {
+	object := obj.GetNamespace() + "/" + obj.GetName()
+	if obj.GetNamespace() == "" {
+		object = obj.GetName()
+	}
+
+	for key, value := range labels {
+		if actual, found := obj.GetLabels()[key]; !found || actual != value {
+			return &NotOwnedError{Object: object, Owner: owner, Reason: fmt.Sprintf("label %s is not %s", key, value)}
+		}
+	}
+	actual, found := obj.GetAnnotations()[infrav1.InfraOwnerAnnotation]
+	if !found && len(labels) == 0 {
+		return &NotOwnedError{Object: object, Owner: owner, Reason: "it has no owner annotation"}
+	}
+	if found && actual != owner {
+		return &NotOwnedError{Object: object, Owner: owner, Reason: fmt.Sprintf("it belongs to %s", actual)}
+	}
+	return nil
+}
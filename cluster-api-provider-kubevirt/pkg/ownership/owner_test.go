@@ -0,0 +1,80 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ownership
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("Infra object owner", func() {
+	var (
+		owner  string
+		labels map[string]string
+	)
+
+	BeforeEach(func() {
+		kubevirtMachine := testing.NewKubevirtMachine("machine", "machine")
+		kubevirtMachine.Namespace = "default"
+		owner = MachineOwner(kubevirtMachine)
+		labels = map[string]string{"cluster.x-k8s.io/cluster-name": "cluster"}
+	})
+
+	newSecret := func(labels, annotations map[string]string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "infra", Name: "machine", Labels: labels, Annotations: annotations}}
+	}
+
+	It("should record the owner without modifying shared annotations", func() {
+		shared := map[string]string{"a": "b"}
+		secret := newSecret(nil, shared)
+		SetOwner(secret, owner)
+
+		Expect(owner).To(Equal("KubevirtMachine/default/machine"))
+		Expect(secret.Annotations).To(Equal(map[string]string{"a": "b", infrav1.InfraOwnerAnnotation: owner}))
+		Expect(shared).To(Equal(map[string]string{"a": "b"}))
+	})
+
+	It("should verify the labels and the owner annotation", func() {
+		Expect(VerifyOwner(newSecret(labels, map[string]string{infrav1.InfraOwnerAnnotation: owner}), owner, labels)).To(Succeed())
+	})
+
+	It("should verify the labels of the objects created before the owner annotation", func() {
+		Expect(VerifyOwner(newSecret(labels, nil), owner, labels)).To(Succeed())
+	})
+
+	It("should reject the objects without the labels", func() {
+		err := VerifyOwner(newSecret(nil, map[string]string{infrav1.InfraOwnerAnnotation: owner}), owner, labels)
+		Expect(IsNotOwned(err)).To(BeTrue())
+		Expect(err).To(MatchError("infra/machine was not created for KubevirtMachine/default/machine: label cluster.x-k8s.io/cluster-name is not cluster"))
+	})
+
+	It("should reject the objects of another owner", func() {
+		err := VerifyOwner(newSecret(labels, map[string]string{infrav1.InfraOwnerAnnotation: "KubevirtMachine/other/machine"}), owner, labels)
+		Expect(IsNotOwned(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("it belongs to KubevirtMachine/other/machine")))
+	})
+
+	It("should reject the objects without an owner annotation when there are no labels to verify", func() {
+		Expect(IsNotOwned(VerifyOwner(newSecret(nil, nil), owner, nil))).To(BeTrue())
+		Expect(VerifyOwner(newSecret(nil, map[string]string{infrav1.InfraOwnerAnnotation: owner}), owner, nil)).To(Succeed())
+	})
+})
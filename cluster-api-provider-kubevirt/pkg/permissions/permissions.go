@@ -0,0 +1,227 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package permissions defines the roles the provider needs in the infra namespaces, split by concern so that each
+// can be granted on its own, and checks whether the provider holds them.
+package permissions
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	kubevirtcore "kubevirt.io/api/core"
+	cdicore "kubevirt.io/containerized-data-importer-api/pkg/apis/core"
+)
+
+const (
+	// InfraVMRoleName is the name of the role managing the VMs of the machines and their disks, services and
+	// userdata secrets, the DaemonSets prewarming their images and the infra ownership leases of the clusters.
+	InfraVMRoleName = "capk-infra-vm-role"
+	// SecretReaderRoleName is the name of the role reading the secrets, e.g. the infra kubeconfigs and the
+	// bootstrap data.
+	SecretReaderRoleName = "capk-secret-reader-role"
+	// SDNRoleName is the name of the role managing the objects of the infra SDN, e.g. the ms-sdn tenant networks,
+	// load balancers and floating IP claims.
+	SDNRoleName = "capk-sdn-role"
+)
+
+// Report is the result of a check of the permissions of the provider.
+type Report struct {
+	// Missing are the permissions not held, as returned by Missing.
+	Missing []string
+}
+
+// Role is a named set of permissions of the provider in a namespace.
+type Role struct {
+	Name  string
+	Rules []rbacv1.PolicyRule
+}
+
+// InfraVMRole returns the role managing the VMs of the machines in an infra namespace.
+func InfraVMRole() Role {
+	return Role{
+		Name: InfraVMRoleName,
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{kubevirtcore.GroupName},
+				Resources: []string{"virtualmachines"},
+				Verbs:     []string{"create", "delete", "get", "list", "patch", "update", "watch"},
+			},
+			{
+				APIGroups: []string{kubevirtcore.GroupName},
+				Resources: []string{"virtualmachineinstances"},
+				Verbs:     []string{"delete", "get", "list", "update", "watch"},
+			},
+			{
+				APIGroups: []string{kubevirtcore.GroupName},
+				Resources: []string{"virtualmachineinstancemigrations"},
+				Verbs:     []string{"create", "get", "list"},
+			},
+			{
+				APIGroups: []string{"subresources." + kubevirtcore.GroupName},
+				Resources: []string{"virtualmachineinstances/addvolume", "virtualmachineinstances/removevolume"},
+				Verbs:     []string{"update"},
+			},
+			{
+				APIGroups: []string{"snapshot." + kubevirtcore.GroupName},
+				Resources: []string{"virtualmachinesnapshots"},
+				Verbs:     []string{"create", "get"},
+			},
+			{
+				APIGroups: []string{"export." + kubevirtcore.GroupName},
+				Resources: []string{"virtualmachineexports"},
+				Verbs:     []string{"create", "delete", "get"},
+			},
+			{
+				APIGroups: []string{cdicore.GroupName},
+				Resources: []string{"datavolumes"},
+				Verbs:     []string{"create", "delete", "get", "list", "update", "watch"},
+			},
+			{
+				APIGroups: []string{cdicore.GroupName},
+				Resources: []string{"datavolumes/source"},
+				Verbs:     []string{"create"},
+			},
+			{
+				APIGroups: []string{""},
+				Resources: []string{"services"},
+				Verbs:     []string{"create", "delete", "get", "list", "patch", "update", "watch"},
+			},
+			{
+				APIGroups: []string{""},
+				Resources: []string{"pods"},
+				Verbs:     []string{"get", "list", "patch", "watch"},
+			},
+			{
+				APIGroups: []string{""},
+				Resources: []string{"pods/exec"},
+				Verbs:     []string{"create"},
+			},
+			{
+				APIGroups: []string{""},
+				Resources: []string{"persistentvolumeclaims"},
+				Verbs:     []string{"get", "list", "update"},
+			},
+			{
+				APIGroups: []string{""},
+				Resources: []string{"secrets"},
+				Verbs:     []string{"create", "delete", "patch", "update"},
+			},
+			{
+				APIGroups: []string{appsv1.GroupName},
+				Resources: []string{"daemonsets"},
+				Verbs:     []string{"create", "delete", "get", "update"},
+			},
+			{
+				APIGroups: []string{coordinationv1.GroupName},
+				Resources: []string{"leases"},
+				Verbs:     []string{"create", "delete", "get", "update"},
+			},
+		},
+	}
+}
+
+// SecretReaderRole returns the role reading the secrets of a namespace.
+func SecretReaderRole() Role {
+	return Role{
+		Name: SecretReaderRoleName,
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{""},
+				Resources: []string{"secrets"},
+				Verbs:     []string{"get", "list", "watch"},
+			},
+		},
+	}
+}
+
+// SDNRole returns the role managing the objects of the API groups of the infra SDN.
+func SDNRole(apiGroups []string) Role {
+	return Role{
+		Name: SDNRoleName,
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: apiGroups,
+				Resources: []string{rbacv1.ResourceAll},
+				Verbs:     []string{"create", "delete", "get", "list", "patch", "update", "watch"},
+			},
+		},
+	}
+}
+
+// Roles returns the roles of the provider in an infra namespace. The SDN role is only returned with the API groups
+// of the infra SDN.
+func Roles(sdnAPIGroups []string) []Role {
+	roles := []Role{InfraVMRole(), SecretReaderRole()}
+	if len(sdnAPIGroups) > 0 {
+		roles = append(roles, SDNRole(sdnAPIGroups))
+	}
+	return roles
+}
+
+// Missing returns the permissions of the roles the caller does not hold in the namespace, or in all the namespaces
+// when it is empty, as "<verb> <resource>.<group>" strings. They are checked with SelfSubjectAccessReviews, which
+// any authenticated user may create.
+func Missing(ctx context.Context, reviews authorizationv1client.SelfSubjectAccessReviewInterface, namespace string, roles ...Role) ([]string, error) {
+	var missing []string
+	for _, role := range roles {
+		for _, rule := range role.Rules {
+			for _, group := range rule.APIGroups {
+				for _, resource := range rule.Resources {
+					for _, verb := range rule.Verbs {
+						resource, subresource, _ := strings.Cut(resource, "/")
+						review := &authorizationv1.SelfSubjectAccessReview{
+							Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+								ResourceAttributes: &authorizationv1.ResourceAttributes{
+									Namespace:   namespace,
+									Verb:        verb,
+									Group:       group,
+									Resource:    resource,
+									Subresource: subresource,
+								},
+							},
+						}
+						review, err := reviews.Create(ctx, review, metav1.CreateOptions{})
+						if err != nil {
+							return nil, errors.Wrapf(err, "failed to review the permission to %s %s", verb, resource)
+						}
+						if !review.Status.Allowed {
+							missing = append(missing, format(verb, group, resource, subresource))
+						}
+					}
+				}
+			}
+		}
+	}
+	return missing, nil
+}
+
+func format(verb, group, resource, subresource string) string {
+	if subresource != "" {
+		resource += "/" + subresource
+	}
+	if group != "" {
+		resource += "." + group
+	}
+	return verb + " " + resource
+}
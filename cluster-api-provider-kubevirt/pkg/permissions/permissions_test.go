@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package permissions_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/permissions"
+)
+
+var _ = Describe("Permissions", func() {
+	// newClient allows the reviewed permissions unless their resource is denied.
+	newClient := func(denied ...string) *fake.Clientset {
+		client := fake.NewSimpleClientset()
+		client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+			Expect(review.Spec.ResourceAttributes.Namespace).To(Equal("infra"))
+			review.Status.Allowed = true
+			for _, resource := range denied {
+				if review.Spec.ResourceAttributes.Resource == resource {
+					review.Status.Allowed = false
+				}
+			}
+			return true, review, nil
+		})
+		return client
+	}
+
+	It("should only return the SDN role with the API groups of the SDN", func() {
+		Expect(permissions.Roles(nil)).To(HaveLen(2))
+
+		roles := permissions.Roles([]string{"sdn.example.com"})
+		Expect(roles).To(HaveLen(3))
+		Expect(roles[2].Name).To(Equal(permissions.SDNRoleName))
+		Expect(roles[2].Rules[0].APIGroups).To(ConsistOf("sdn.example.com"))
+	})
+
+	It("should return nothing when all the permissions are held", func() {
+		missing, err := permissions.Missing(context.TODO(), newClient().AuthorizationV1().SelfSubjectAccessReviews(), "infra", permissions.Roles(nil)...)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(missing).To(BeEmpty())
+	})
+
+	It("should return the permissions not held", func() {
+		client := newClient("secrets", "pods")
+
+		missing, err := permissions.Missing(context.TODO(), client.AuthorizationV1().SelfSubjectAccessReviews(), "infra", permissions.SecretReaderRole(), permissions.InfraVMRole())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(missing).To(Equal([]string{
+			"get secrets", "list secrets", "watch secrets",
+			"get pods", "list pods", "patch pods", "watch pods", "create pods/exec",
+			"create secrets", "delete secrets", "patch secrets", "update secrets",
+		}))
+	})
+
+	It("should check the permissions on the DaemonSets prewarming the images", func() {
+		client := newClient("daemonsets")
+
+		missing, err := permissions.Missing(context.TODO(), client.AuthorizationV1().SelfSubjectAccessReviews(), "infra", permissions.InfraVMRole())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(missing).To(ConsistOf("create daemonsets.apps", "delete daemonsets.apps", "get daemonsets.apps", "update daemonsets.apps"))
+	})
+
+	It("should check the permissions on the infra ownership leases", func() {
+		client := newClient("leases")
+
+		missing, err := permissions.Missing(context.TODO(), client.AuthorizationV1().SelfSubjectAccessReviews(), "infra", permissions.InfraVMRole())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(missing).To(ConsistOf("create leases.coordination.k8s.io", "delete leases.coordination.k8s.io", "get leases.coordination.k8s.io", "update leases.coordination.k8s.io"))
+	})
+
+	It("should name the group of the permissions not held", func() {
+		client := newClient("virtualmachineinstances")
+
+		missing, err := permissions.Missing(context.TODO(), client.AuthorizationV1().SelfSubjectAccessReviews(), "infra", permissions.InfraVMRole())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(missing).To(ContainElements("delete virtualmachineinstances.kubevirt.io", "update virtualmachineinstances/addvolume.subresources.kubevirt.io"))
+	})
+})
@@ -0,0 +1,220 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package prewarm pulls the containerDisk images of the machines of a cluster on the infra nodes ahead of time, with
+// a DaemonSet of the infra cluster running each image as an init container.
+package prewarm
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/resources"
+)
+
+const (
+	// DefaultHelperImage is the default image of the pods pulling the images.
+	DefaultHelperImage = "docker.io/library/busybox:1.36-musl"
+
+	appLabel = "app"
+	appName  = "capk-image-prewarm"
+
+	// The containerDisk images have no shell: their init containers run the busybox binary copied from the helper
+	// image into a shared volume.
+	binVolume = "bin"
+	binDir    = "/prewarm"
+	busybox   = "/bin/busybox"
+)
+
+// Name returns the name of the DaemonSet pulling the images of a cluster.
+func Name(cluster *clusterv1.Cluster) string {
+	return cluster.Name + "-image-prewarm"
+}
+
+// Images returns the images to pull for the cluster: the ones of its spec, and the containerDisk images of its
+// KubevirtMachines and of the KubevirtMachineTemplates of its MachineDeployments, which the machines created on
+// scale events boot.
+func Images(ctx *context.ClusterContext, reader client.Reader) ([]string, error) {
+	images := map[string]bool{}
+	for _, image := range ctx.KubevirtCluster.Spec.ImagePrewarming.Images {
+		images[image] = true
+	}
+	addContainerDisks := func(template *infrav1.VirtualMachineTemplateSpec) {
+		if template.Spec.Template == nil {
+			return
+		}
+		for _, volume := range template.Spec.Template.Spec.Volumes {
+			if volume.ContainerDisk != nil && volume.ContainerDisk.Image != "" {
+				images[volume.ContainerDisk.Image] = true
+			}
+		}
+	}
+
+	kubevirtMachines := &infrav1.KubevirtMachineList{}
+	if err := reader.List(ctx, kubevirtMachines, client.InNamespace(ctx.Cluster.Namespace),
+		client.MatchingLabels{clusterv1.ClusterNameLabel: ctx.Cluster.Name}); err != nil {
+		return nil, errors.Wrap(err, "failed to list KubevirtMachines")
+	}
+	for i := range kubevirtMachines.Items {
+		addContainerDisks(&kubevirtMachines.Items[i].Spec.VirtualMachineTemplate)
+	}
+
+	machineDeployments := &clusterv1.MachineDeploymentList{}
+	if err := reader.List(ctx, machineDeployments, client.InNamespace(ctx.Cluster.Namespace)); err != nil {
+		return nil, errors.Wrap(err, "failed to list MachineDeployments")
+	}
+	for _, machineDeployment := range machineDeployments.Items {
+		infrastructureRef := machineDeployment.Spec.Template.Spec.InfrastructureRef
+		if machineDeployment.Spec.ClusterName != ctx.Cluster.Name || infrastructureRef.Kind != "KubevirtMachineTemplate" {
+			continue
+		}
+		template := &infrav1.KubevirtMachineTemplate{}
+		if err := reader.Get(ctx, client.ObjectKey{Namespace: machineDeployment.Namespace, Name: infrastructureRef.Name}, template); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to fetch KubevirtMachineTemplate %s", infrastructureRef.Name)
+		}
+		addContainerDisks(&template.Spec.Template.Spec.VirtualMachineTemplate)
+	}
+
+	sorted := make([]string, 0, len(images))
+	for image := range images {
+		sorted = append(sorted, image)
+	}
+	sort.Strings(sorted)
+	return sorted, nil
+}
+
+// Reconcile creates or updates the DaemonSet pulling the images in the infra namespace of the cluster, and returns
+// it, so that its status tells the progress of the pull.
+func Reconcile(ctx *context.ClusterContext, infraClient client.Client, infraNamespace string, images []string) (*appsv1.DaemonSet, error) {
+	labels := map[string]string{
+		clusterv1.ClusterNameLabel: ctx.Cluster.Name,
+		appLabel:                   appName,
+	}
+
+	template := podTemplate(ctx.KubevirtCluster.Spec.ImagePrewarming, images, labels)
+	templateHash, err := resources.TemplateHash(template)
+	if err != nil {
+		return nil, err
+	}
+	daemonSet := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Namespace: infraNamespace, Name: Name(ctx.Cluster)}}
+	if err := resources.CreateOrUpdate(ctx, infraClient, infraClient, "DaemonSet", daemonSet, func() {
+		daemonSet.SetLabels(labels)
+		if daemonSet.Spec.Selector == nil {
+			daemonSet.Spec.Selector = &metav1.LabelSelector{MatchLabels: labels}
+		}
+		// The pods only hold the images: replace them all at once
+		maxUnavailable := intstr.FromString("100%")
+		daemonSet.Spec.UpdateStrategy = appsv1.DaemonSetUpdateStrategy{
+			Type:          appsv1.RollingUpdateDaemonSetStrategyType,
+			RollingUpdate: &appsv1.RollingUpdateDaemonSet{MaxUnavailable: &maxUnavailable},
+		}
+		resources.SetPodTemplate(daemonSet, &daemonSet.Spec.Template, template, templateHash)
+	}); err != nil {
+		return nil, err
+	}
+	return daemonSet, nil
+}
+
+// IsReady returns true once the pods of the current template of the DaemonSet run on all the nodes, that is once
+// the images are pulled on all of them, along with the number of such nodes and the number of nodes. A DaemonSet
+// not observed by its controller yet is not ready.
+func IsReady(daemonSet *appsv1.DaemonSet) (bool, int32, int32) {
+	status := daemonSet.Status
+	if status.ObservedGeneration == 0 || status.ObservedGeneration < daemonSet.Generation {
+		return false, 0, status.DesiredNumberScheduled
+	}
+	ready := status.UpdatedNumberScheduled
+	if status.NumberReady < ready {
+		ready = status.NumberReady
+	}
+	return ready == status.DesiredNumberScheduled, ready, status.DesiredNumberScheduled
+}
+
+// Delete deletes the DaemonSet pulling the images of the cluster.
+func Delete(ctx *context.ClusterContext, infraClient client.Client, infraNamespace string) error {
+	daemonSet := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Namespace: infraNamespace, Name: Name(ctx.Cluster)}}
+	if err := infraClient.Delete(ctx, daemonSet); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete DaemonSet %s", daemonSet.Name)
+	}
+	return nil
+}
+
+func podTemplate(spec *infrav1.ImagePrewarmingSpec, images []string, labels map[string]string) corev1.PodTemplateSpec {
+	helperImage := spec.HelperImage
+	if helperImage == "" {
+		helperImage = DefaultHelperImage
+	}
+	requests := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("1m"),
+			corev1.ResourceMemory: resource.MustParse("8Mi"),
+		},
+	}
+	binMount := []corev1.VolumeMount{{Name: binVolume, MountPath: binDir}}
+
+	initContainers := []corev1.Container{{
+		Name:         "install",
+		Image:        helperImage,
+		Command:      []string{busybox, "cp", busybox, binDir + "/busybox"},
+		Resources:    requests,
+		VolumeMounts: binMount,
+	}}
+	for i, image := range images {
+		initContainers = append(initContainers, corev1.Container{
+			Name:            fmt.Sprintf("image-%d", i),
+			Image:           image,
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Command:         []string{binDir + "/busybox", "true"},
+			Resources:       requests,
+			VolumeMounts:    binMount,
+		})
+	}
+
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: labels},
+		Spec: corev1.PodSpec{
+			InitContainers: initContainers,
+			Containers: []corev1.Container{{
+				Name:      "pause",
+				Image:     helperImage,
+				Command:   []string{busybox, "sleep", "2147483647"},
+				Resources: requests,
+			}},
+			Volumes: []corev1.Volume{{
+				Name:         binVolume,
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			}},
+			NodeSelector:                  spec.NodeSelector,
+			TerminationGracePeriodSeconds: ptr.To[int64](0),
+		},
+	}
+}
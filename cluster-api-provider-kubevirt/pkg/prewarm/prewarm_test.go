@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prewarm_test
+
+import (
+	gocontext "context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/prewarm"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+func withContainerDisk(template *infrav1.VirtualMachineTemplateSpec, image string) {
+	template.Spec.Template = &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+		Spec: kubevirtv1.VirtualMachineInstanceSpec{
+			Volumes: []kubevirtv1.Volume{
+				{Name: "cloudinit", VolumeSource: kubevirtv1.VolumeSource{CloudInitNoCloud: &kubevirtv1.CloudInitNoCloudSource{}}},
+				{Name: "root", VolumeSource: kubevirtv1.VolumeSource{ContainerDisk: &kubevirtv1.ContainerDiskSource{Image: image}}},
+			},
+		},
+	}
+}
+
+var _ = Describe("Image prewarming", func() {
+	var (
+		kubevirtCluster *infrav1.KubevirtCluster
+		ctx             *context.ClusterContext
+	)
+
+	BeforeEach(func() {
+		kubevirtCluster = testing.NewKubevirtCluster("test-cluster", "test-kubevirt-cluster")
+		kubevirtCluster.Spec.ImagePrewarming = &infrav1.ImagePrewarmingSpec{
+			Images:       []string{"quay.io/containerdisks/fedora:40"},
+			NodeSelector: map[string]string{"node-role.kubernetes.io/worker": ""},
+		}
+		ctx = &context.ClusterContext{
+			Logger:          ctrl.LoggerFrom(gocontext.TODO()).WithName("test"),
+			Context:         gocontext.TODO(),
+			Cluster:         testing.NewCluster("test-cluster", kubevirtCluster),
+			KubevirtCluster: kubevirtCluster,
+		}
+	})
+
+	It("should pull the containerDisk images of the machines and of the templates of the MachineDeployments", func() {
+		kubevirtMachine := testing.NewKubevirtMachine("test-kubevirt-machine", "test-machine")
+		kubevirtMachine.Labels = map[string]string{clusterv1.ClusterNameLabel: "test-cluster"}
+		withContainerDisk(&kubevirtMachine.Spec.VirtualMachineTemplate, "quay.io/containerdisks/ubuntu:22.04")
+
+		otherMachine := testing.NewKubevirtMachine("other-kubevirt-machine", "other-machine")
+		otherMachine.Labels = map[string]string{clusterv1.ClusterNameLabel: "other-cluster"}
+		withContainerDisk(&otherMachine.Spec.VirtualMachineTemplate, "quay.io/containerdisks/centos:9")
+
+		template := &infrav1.KubevirtMachineTemplate{ObjectMeta: metav1.ObjectMeta{Name: "workers"}}
+		withContainerDisk(&template.Spec.Template.Spec.VirtualMachineTemplate, "quay.io/containerdisks/ubuntu:24.04")
+		machineDeployment := &clusterv1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "workers"},
+			Spec: clusterv1.MachineDeploymentSpec{
+				ClusterName: "test-cluster",
+				Template: clusterv1.MachineTemplateSpec{Spec: clusterv1.MachineSpec{
+					ClusterName:       "test-cluster",
+					InfrastructureRef: corev1.ObjectReference{Kind: "KubevirtMachineTemplate", Name: "workers"},
+				}},
+			},
+		}
+
+		reader := fake.NewClientBuilder().WithScheme(testing.SetupScheme()).
+			WithObjects(kubevirtMachine, otherMachine, template, machineDeployment).Build()
+		images, err := prewarm.Images(ctx, reader)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(images).To(Equal([]string{
+			"quay.io/containerdisks/fedora:40",
+			"quay.io/containerdisks/ubuntu:22.04",
+			"quay.io/containerdisks/ubuntu:24.04",
+		}))
+	})
+
+	It("should run every image as an init container on the selected infra nodes", func() {
+		infraClient := fake.NewClientBuilder().WithScheme(testing.SetupScheme()).Build()
+		images := []string{"quay.io/containerdisks/fedora:40", "quay.io/containerdisks/ubuntu:22.04"}
+
+		_, err := prewarm.Reconcile(ctx, infraClient, "infra", images)
+		Expect(err).ToNot(HaveOccurred())
+
+		daemonSet := &appsv1.DaemonSet{}
+		Expect(infraClient.Get(ctx, client.ObjectKey{Namespace: "infra", Name: "test-cluster-image-prewarm"}, daemonSet)).To(Succeed())
+		podSpec := daemonSet.Spec.Template.Spec
+		Expect(podSpec.NodeSelector).To(HaveKey("node-role.kubernetes.io/worker"))
+		Expect(podSpec.InitContainers).To(HaveLen(3))
+		Expect(podSpec.InitContainers[0].Image).To(Equal(prewarm.DefaultHelperImage))
+		Expect(podSpec.InitContainers[1].Image).To(Equal(images[0]))
+		Expect(podSpec.InitContainers[1].Command).To(Equal([]string{"/prewarm/busybox", "true"}))
+		Expect(podSpec.InitContainers[2].Image).To(Equal(images[1]))
+		Expect(podSpec.Containers).To(ConsistOf(HaveField("Image", prewarm.DefaultHelperImage)))
+
+		By("pulling a new image")
+		_, err = prewarm.Reconcile(ctx, infraClient, "infra", images[:1])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(infraClient.Get(ctx, client.ObjectKeyFromObject(daemonSet), daemonSet)).To(Succeed())
+		Expect(daemonSet.Spec.Template.Spec.InitContainers).To(HaveLen(2))
+
+		Expect(prewarm.Delete(ctx, infraClient, "infra")).To(Succeed())
+		Expect(apierrors.IsNotFound(infraClient.Get(ctx, client.ObjectKeyFromObject(daemonSet), daemonSet))).To(BeTrue())
+	})
+
+	DescribeTable("should be ready once the current pods run on all the nodes", func(status appsv1.DaemonSetStatus, ready bool) {
+		daemonSet := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Generation: 2}, Status: status}
+		isReady, _, _ := prewarm.IsReady(daemonSet)
+		Expect(isReady).To(Equal(ready))
+	},
+		Entry("all pulled", appsv1.DaemonSetStatus{ObservedGeneration: 2, DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberReady: 3}, true),
+		Entry("pulling", appsv1.DaemonSetStatus{ObservedGeneration: 2, DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberReady: 2}, false),
+		Entry("updating", appsv1.DaemonSetStatus{ObservedGeneration: 2, DesiredNumberScheduled: 3, UpdatedNumberScheduled: 1, NumberReady: 3}, false),
+		Entry("not observed", appsv1.DaemonSetStatus{ObservedGeneration: 1, DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberReady: 3}, false),
+		Entry("just created", appsv1.DaemonSetStatus{}, false),
+	)
+})
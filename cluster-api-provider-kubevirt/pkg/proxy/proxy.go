@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package proxy renders the HTTP proxy settings of a KubevirtCluster, so that the nodes of the workload cluster and
+// the controller use the same proxy and the same exceptions.
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+const defaultServiceDomain = "cluster.local"
+
+// NoProxy returns the comma-separated list of the destinations of the cluster reached without the proxy: the
+// loopback, the pod and service CIDRs, the service domain, the control plane endpoint, and the additional
+// exceptions of the proxy spec.
+func NoProxy(cluster *clusterv1.Cluster, kubevirtCluster *infrav1.KubevirtCluster) string {
+	noProxy := []string{"localhost", "127.0.0.1"}
+
+	serviceDomain := defaultServiceDomain
+	if network := cluster.Spec.ClusterNetwork; network != nil {
+		if network.Pods != nil {
+			noProxy = append(noProxy, network.Pods.CIDRBlocks...)
+		}
+		if network.Services != nil {
+			noProxy = append(noProxy, network.Services.CIDRBlocks...)
+		}
+		if network.ServiceDomain != "" {
+			serviceDomain = network.ServiceDomain
+		}
+	}
+	noProxy = append(noProxy, ".svc", "."+serviceDomain)
+
+	if host := kubevirtCluster.Spec.ControlPlaneEndpoint.Host; host != "" {
+		noProxy = append(noProxy, host)
+	}
+	if spec := kubevirtCluster.Spec.Proxy; spec != nil {
+		noProxy = append(noProxy, spec.NoProxy...)
+	}
+
+	seen := map[string]bool{}
+	deduplicated := noProxy[:0]
+	for _, destination := range noProxy {
+		if !seen[destination] {
+			seen[destination] = true
+			deduplicated = append(deduplicated, destination)
+		}
+	}
+	return strings.Join(deduplicated, ",")
+}
+
+// Environment returns the proxy environment variables of the cluster, as "NAME=value" pairs, or nil if the cluster
+// has no proxy.
+func Environment(cluster *clusterv1.Cluster, kubevirtCluster *infrav1.KubevirtCluster) []string {
+	spec := kubevirtCluster.Spec.Proxy
+	if spec == nil {
+		return nil
+	}
+
+	var env []string
+	if spec.HTTPProxy != "" {
+		env = append(env, fmt.Sprintf("HTTP_PROXY=%s", spec.HTTPProxy))
+	}
+	if spec.HTTPSProxy != "" {
+		env = append(env, fmt.Sprintf("HTTPS_PROXY=%s", spec.HTTPSProxy))
+	}
+	return append(env, fmt.Sprintf("NO_PROXY=%s", NoProxy(cluster, kubevirtCluster)))
+}
+
+// Func returns the function selecting the proxy of the requests sent to the cluster, or nil if the cluster has
+// no proxy.
+func Func(cluster *clusterv1.Cluster, kubevirtCluster *infrav1.KubevirtCluster) func(*http.Request) (*url.URL, error) {
+	spec := kubevirtCluster.Spec.Proxy
+	if spec == nil {
+		return nil
+	}
+
+	proxyFunc := (&httpproxy.Config{
+		HTTPProxy:  spec.HTTPProxy,
+		HTTPSProxy: spec.HTTPSProxy,
+		NoProxy:    NoProxy(cluster, kubevirtCluster),
+	}).ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+}
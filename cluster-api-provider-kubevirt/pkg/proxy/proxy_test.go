@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+var _ = Describe("Proxy", func() {
+	var (
+		cluster         *clusterv1.Cluster
+		kubevirtCluster *infrav1.KubevirtCluster
+	)
+
+	BeforeEach(func() {
+		cluster = &clusterv1.Cluster{
+			Spec: clusterv1.ClusterSpec{
+				ClusterNetwork: &clusterv1.ClusterNetwork{
+					Pods:     &clusterv1.NetworkRanges{CIDRBlocks: []string{"10.243.0.0/16"}},
+					Services: &clusterv1.NetworkRanges{CIDRBlocks: []string{"10.95.0.0/16"}},
+				},
+			},
+		}
+		kubevirtCluster = &infrav1.KubevirtCluster{
+			Spec: infrav1.KubevirtClusterSpec{
+				ControlPlaneEndpoint: infrav1.APIEndpoint{Host: "api.example.com", Port: 6443},
+				Proxy: &infrav1.ProxySpec{
+					HTTPProxy:  "http://proxy.example.com:3128",
+					HTTPSProxy: "http://proxy.example.com:3129",
+					NoProxy:    []string{"registry.example.com", "10.243.0.0/16"},
+				},
+			},
+		}
+	})
+
+	It("should compute the destinations reached without the proxy", func() {
+		Expect(NoProxy(cluster, kubevirtCluster)).To(Equal(
+			"localhost,127.0.0.1,10.243.0.0/16,10.95.0.0/16,.svc,.cluster.local,api.example.com,registry.example.com"))
+	})
+
+	It("should use the service domain of the cluster", func() {
+		cluster.Spec.ClusterNetwork = &clusterv1.ClusterNetwork{ServiceDomain: "tenant.local"}
+		kubevirtCluster.Spec.Proxy.NoProxy = nil
+		Expect(NoProxy(cluster, kubevirtCluster)).To(Equal("localhost,127.0.0.1,.svc,.tenant.local,api.example.com"))
+	})
+
+	It("should render the proxy environment", func() {
+		Expect(Environment(cluster, kubevirtCluster)).To(Equal([]string{
+			"HTTP_PROXY=http://proxy.example.com:3128",
+			"HTTPS_PROXY=http://proxy.example.com:3129",
+			"NO_PROXY=" + NoProxy(cluster, kubevirtCluster),
+		}))
+	})
+
+	It("should proxy the requests to the destinations not excluded", func() {
+		proxyFunc := Func(cluster, kubevirtCluster)
+
+		request, err := http.NewRequest(http.MethodGet, "https://other.example.com/healthz", nil)
+		Expect(err).ToNot(HaveOccurred())
+		proxyURL, err := proxyFunc(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(proxyURL.String()).To(Equal("http://proxy.example.com:3129"))
+
+		request, err = http.NewRequest(http.MethodGet, "https://api.example.com:6443/healthz", nil)
+		Expect(err).ToNot(HaveOccurred())
+		proxyURL, err = proxyFunc(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(proxyURL).To(BeNil())
+	})
+
+	It("should not render anything without proxy", func() {
+		kubevirtCluster.Spec.Proxy = nil
+		Expect(Environment(cluster, kubevirtCluster)).To(BeNil())
+		Expect(Func(cluster, kubevirtCluster)).To(BeNil())
+	})
+})
@@ -0,0 +1,36 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/workloadclient"
+)
+
+// WorkloadKubeconfigVolumeSource returns the volume mounting the kubeconfig of the workload cluster of a
+// KubevirtCluster as the file named after the key of the kubeconfig secrets of Cluster API, whichever secret holds it.
+func WorkloadKubeconfigVolumeSource(clusterName string, kubevirtCluster *infrav1.KubevirtCluster) *corev1.SecretVolumeSource {
+	if source := kubevirtCluster.Spec.WorkloadKubeconfig; source != nil && source.SecretRef != nil {
+		return &corev1.SecretVolumeSource{
+			SecretName: source.SecretRef.Name,
+			Items:      []corev1.KeyToPath{{Key: source.SecretRef.Key, Path: workloadclient.KubeconfigSecretKey}},
+		}
+	}
+	return &corev1.SecretVolumeSource{SecretName: workloadclient.KubeconfigSecretName(clusterName)}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+// InfraName returns the name of an object of the infra cluster prefixed with the naming prefix of the cluster.
+func InfraName(kubevirtCluster *infrav1.KubevirtCluster, name string) string {
+	if kubevirtCluster == nil {
+		return name
+	}
+	return kubevirtCluster.Spec.NamingPrefix + name
+}
+
+// PropagateMetadata sets the labels and annotations of the Cluster selected by the propagation policy of the
+// KubevirtCluster on an object of the infra cluster, and returns whether they changed. The maps are copied rather
+// than modified, as objects often share them with their template. The keys no longer propagated are left as is.
+func PropagateMetadata(object metav1.Object, cluster *clusterv1.Cluster, kubevirtCluster *infrav1.KubevirtCluster) bool {
+	if cluster == nil || kubevirtCluster == nil || kubevirtCluster.Spec.MetadataPropagation == nil {
+		return false
+	}
+	spec := kubevirtCluster.Spec.MetadataPropagation
+
+	labels, labelsChanged := propagate(object.GetLabels(), cluster.Labels, spec.Labels)
+	annotations, annotationsChanged := propagate(object.GetAnnotations(), cluster.Annotations, spec.Annotations)
+	if labelsChanged {
+		object.SetLabels(labels)
+	}
+	if annotationsChanged {
+		object.SetAnnotations(annotations)
+	}
+	return labelsChanged || annotationsChanged
+}
+
+// propagate returns a copy of the target map with the entries of the source matching the keys, and whether it
+// differs from the target.
+func propagate(target, source map[string]string, keys []string) (map[string]string, bool) {
+	var propagated map[string]string
+	for key, value := range source {
+		if !matchesKey(key, keys) {
+			continue
+		}
+		if current, found := target[key]; found && current == value {
+			continue
+		}
+		if propagated == nil {
+			propagated = copyMap(target)
+		}
+		propagated[key] = value
+	}
+	return propagated, propagated != nil
+}
+
+// reservedDomains are the domains of the labels and annotations managed by Cluster API, KubeVirt and the provider,
+// which are never propagated.
+var reservedDomains = []string{"cluster.x-k8s.io", "kubevirt.io"}
+
+// matchesKey returns whether the key is one of the keys, or has one of the keys ending with "/" as prefix, and is
+// not in a reserved domain or one of its subdomains.
+func matchesKey(key string, keys []string) bool {
+	if domain, _, found := strings.Cut(key, "/"); found {
+		for _, reserved := range reservedDomains {
+			if domain == reserved || strings.HasSuffix(domain, "."+reserved) {
+				return false
+			}
+		}
+	}
+	for _, k := range keys {
+		if key == k || (strings.HasSuffix(k, "/") && strings.HasPrefix(key, k)) {
+			return true
+		}
+	}
+	return false
+}
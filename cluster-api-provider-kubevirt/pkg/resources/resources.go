@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package resources creates and updates the Kubernetes objects the controllers deploy for the clusters.
+package resources
+
+import (
+	gocontext "context"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// TemplateHashAnnotation records the hash of the pod template of a workload, so that the workload is only updated
+// when the pod template changes rather than on every defaulting difference.
+const TemplateHashAnnotation = "capk.cluster.x-k8s.io/template-hash"
+
+// CreateOrUpdate creates the object, or updates it when the mutation changes it. The existing object is read with
+// the reader, so that it does not need to be cached.
+func CreateOrUpdate(ctx gocontext.Context, reader client.Reader, c client.Client, kind string, object client.Object, mutate func()) error {
+	if err := reader.Get(ctx, client.ObjectKeyFromObject(object), object); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to get %s %s", kind, object.GetName())
+		}
+		mutate()
+		if err := c.Create(ctx, object); err != nil {
+			return errors.Wrapf(err, "failed to create %s %s", kind, object.GetName())
+		}
+		return nil
+	}
+
+	existing := object.DeepCopyObject()
+	mutate()
+	if apiequality.Semantic.DeepEqual(existing, object) {
+		return nil
+	}
+	if err := c.Update(ctx, object); err != nil {
+		return errors.Wrapf(err, "failed to update %s %s", kind, object.GetName())
+	}
+	return nil
+}
+
+// SetPodTemplate sets the pod template of a workload unless the template hash annotation of the workload shows it
+// is already set, and records its hash in the annotation.
+func SetPodTemplate(object client.Object, podTemplate *corev1.PodTemplateSpec, template corev1.PodTemplateSpec, templateHash string) {
+	annotations := object.GetAnnotations()
+	if annotations[TemplateHashAnnotation] == templateHash {
+		return
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[TemplateHashAnnotation] = templateHash
+	object.SetAnnotations(annotations)
+	*podTemplate = template
+}
+
+// TemplateHash returns the hash of a pod template.
+func TemplateHash(template corev1.PodTemplateSpec) (string, error) {
+	data, err := yaml.Marshal(template)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to hash the pod template")
+	}
+	h := fnv.New32a()
+	_, _ = h.Write(data)
+	return fmt.Sprintf("%08x", h.Sum32()), nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+const (
+	// IstioInjectKey excludes a pod from the sidecar injection of Istio, as a label and, for the versions of Istio
+	// before the label, as an annotation.
+	IstioInjectKey = "sidecar.istio.io/inject"
+
+	// LinkerdInjectAnnotation excludes a pod from the proxy injection of Linkerd.
+	LinkerdInjectAnnotation = "linkerd.io/inject"
+
+	// LinkerdOpaquePortsAnnotation marks the ports of a Service whose traffic Linkerd forwards as plain TCP.
+	LinkerdOpaquePortsAnnotation = "config.linkerd.io/opaque-ports"
+)
+
+// SetPodServiceMesh sets the labels and annotations excluding a pod from the service mesh. The maps are copied
+// rather than modified, as pod templates often share them with their workload.
+func SetPodServiceMesh(objectMeta *metav1.ObjectMeta, spec *infrav1.ServiceMeshSpec) {
+	if spec == nil {
+		return
+	}
+
+	labels, annotations := copyMap(objectMeta.Labels), copyMap(objectMeta.Annotations)
+	switch spec.Type {
+	case infrav1.IstioServiceMesh:
+		labels[IstioInjectKey] = "false"
+		annotations[IstioInjectKey] = "false"
+	case infrav1.LinkerdServiceMesh:
+		annotations[LinkerdInjectAnnotation] = "disabled"
+	}
+	for key, value := range spec.PodAnnotations {
+		annotations[key] = value
+	}
+	objectMeta.Labels, objectMeta.Annotations = labels, annotations
+}
+
+// SetServiceServiceMesh sets the annotations making the service mesh forward the traffic of a Service as is. The
+// annotations are copied rather than modified, as Services often share them with their template.
+func SetServiceServiceMesh(service *corev1.Service, spec *infrav1.ServiceMeshSpec) {
+	if spec == nil {
+		return
+	}
+
+	annotations := copyMap(service.Annotations)
+	if spec.Type == infrav1.LinkerdServiceMesh && len(service.Spec.Ports) > 0 {
+		ports := make([]int, 0, len(service.Spec.Ports))
+		for _, port := range service.Spec.Ports {
+			ports = append(ports, int(port.Port))
+		}
+		sort.Ints(ports)
+		values := make([]string, 0, len(ports))
+		for _, port := range ports {
+			values = append(values, strconv.Itoa(port))
+		}
+		annotations[LinkerdOpaquePortsAnnotation] = strings.Join(values, ",")
+	}
+	for key, value := range spec.ServiceAnnotations {
+		annotations[key] = value
+	}
+	service.Annotations = annotations
+}
+
+func copyMap(m map[string]string) map[string]string {
+	copied := make(map[string]string, len(m))
+	for key, value := range m {
+		copied[key] = value
+	}
+	return copied
+}
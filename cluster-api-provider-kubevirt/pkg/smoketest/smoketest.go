@@ -0,0 +1,189 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package smoketest verifies a workload cluster by running a small workload in it: a web server behind a
+// Service, reached by a Job through the cluster DNS.
+package smoketest
+
+import (
+	gocontext "context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+const (
+	// Namespace is the namespace of the workload cluster the test runs in.
+	Namespace = "capk-smoke-test"
+
+	name               = "capk-smoke-test"
+	loadBalancerName   = "capk-smoke-test-lb"
+	defaultServerImage = "nginx:stable"
+	defaultClientImage = "busybox:stable"
+)
+
+// Status is the progress of the smoke test.
+type Status struct {
+	// Completed is true once the test passed or failed.
+	Completed bool
+	// Failure describes why the test failed, it is empty if the test passed.
+	Failure string
+}
+
+// Start creates the test workload in the workload cluster, unless it already exists.
+func Start(ctx gocontext.Context, c client.Client, spec *infrav1.SmokeTestSpec) error {
+	for _, obj := range objects(spec) {
+		if err := c.Create(ctx, obj); err != nil && !apierrors.IsAlreadyExists(err) {
+			return errors.Wrapf(err, "failed to create smoke test %T %s", obj, obj.GetName())
+		}
+	}
+	return nil
+}
+
+// Check returns the status of the test.
+func Check(ctx gocontext.Context, c client.Client, spec *infrav1.SmokeTestSpec) (Status, error) {
+	job := &batchv1.Job{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: Namespace, Name: name}, job); err != nil {
+		return Status{}, errors.Wrap(err, "failed to fetch smoke test job")
+	}
+
+	complete := false
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobFailed:
+			return Status{Completed: true, Failure: fmt.Sprintf("smoke test job failed: %s", condition.Message)}, nil
+		case batchv1.JobComplete:
+			complete = true
+		}
+	}
+	if !complete {
+		return Status{}, nil
+	}
+
+	if spec.LoadBalancer {
+		service := &corev1.Service{}
+		if err := c.Get(ctx, client.ObjectKey{Namespace: Namespace, Name: loadBalancerName}, service); err != nil {
+			return Status{}, errors.Wrap(err, "failed to fetch smoke test load balancer")
+		}
+		if len(service.Status.LoadBalancer.Ingress) == 0 {
+			return Status{}, nil
+		}
+	}
+
+	return Status{Completed: true}, nil
+}
+
+// Cleanup deletes the test workload.
+func Cleanup(ctx gocontext.Context, c client.Client) error {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: Namespace}}
+	if err := c.Delete(ctx, namespace); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed to delete smoke test namespace")
+	}
+	return nil
+}
+
+func objects(spec *infrav1.SmokeTestSpec) []client.Object {
+	serverImage := spec.ServerImage
+	if serverImage == "" {
+		serverImage = defaultServerImage
+	}
+	clientImage := spec.ClientImage
+	if clientImage == "" {
+		clientImage = defaultClientImage
+	}
+
+	labels := map[string]string{"app": name}
+	// The test may run before any worker node joined the cluster.
+	tolerations := []corev1.Toleration{{
+		Key:      "node-role.kubernetes.io/control-plane",
+		Operator: corev1.TolerationOpExists,
+		Effect:   corev1.TaintEffectNoSchedule,
+	}}
+	servicePorts := []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromInt32(80)}}
+
+	objs := []client.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: Namespace}},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: Namespace, Name: name},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: ptr.To[int32](1),
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{
+							Name:  "server",
+							Image: serverImage,
+							Ports: []corev1.ContainerPort{{ContainerPort: 80}},
+						}},
+						Tolerations: tolerations,
+					},
+				},
+			},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: Namespace, Name: name},
+			Spec: corev1.ServiceSpec{
+				Selector: labels,
+				Ports:    servicePorts,
+			},
+		},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Namespace: Namespace, Name: name},
+			Spec: batchv1.JobSpec{
+				BackoffLimit: ptr.To[int32](5),
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						RestartPolicy: corev1.RestartPolicyNever,
+						Containers: []corev1.Container{{
+							Name:  "client",
+							Image: clientImage,
+							Command: []string{"sh", "-c", fmt.Sprintf(
+								"nslookup kubernetes.default && nslookup %[1]s && wget -q -O /dev/null -T 10 http://%[1]s", name)},
+						}},
+						Tolerations: tolerations,
+					},
+				},
+			},
+		},
+	}
+
+	if spec.LoadBalancer {
+		objs = append(objs, &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: Namespace, Name: loadBalancerName},
+			Spec: corev1.ServiceSpec{
+				Type:     corev1.ServiceTypeLoadBalancer,
+				Selector: labels,
+				Ports:    servicePorts,
+			},
+		})
+	}
+
+	return objs
+}
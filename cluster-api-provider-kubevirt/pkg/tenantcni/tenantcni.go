@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tenantcni finds how the Services of a workload cluster are implemented, by kube-proxy or by a
+// replacement in its CNI, and checks the configuration of the replacement against the cluster.
+package tenantcni
+
+import (
+	gocontext "context"
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+const (
+	// KubeProxyName is the name of the kube-proxy DaemonSet kubeadm deploys in the kube-system namespace.
+	KubeProxyName = "kube-proxy"
+
+	// CiliumConfigName is the name of the ConfigMap holding the configuration of Cilium in the kube-system
+	// namespace.
+	CiliumConfigName = "cilium-config"
+
+	ciliumKubeProxyReplacementKey = "kube-proxy-replacement"
+	ciliumServiceHostKey          = "k8s-service-host"
+	ciliumServicePortKey          = "k8s-service-port"
+)
+
+// Config is the implementation of the Services of a workload cluster.
+type Config struct {
+	// KubeProxyReplacement is true when the Services are implemented by the CNI rather than kube-proxy.
+	KubeProxyReplacement bool
+	// Cilium is true when the CNI is Cilium.
+	Cilium bool
+	// ServiceHost and ServicePort are the address Cilium reaches the API server at, without the Service of the
+	// API server.
+	ServiceHost string
+	ServicePort string
+}
+
+// Detect returns the implementation of the Services of the workload cluster. Its Services are implemented by a
+// kube-proxy replacement when the mode is Enabled, when Cilium is configured to replace kube-proxy, or when kubeadm
+// did not deploy kube-proxy.
+func Detect(ctx gocontext.Context, c client.Client, mode infrav1.KubeProxyReplacementMode) (*Config, error) {
+	config := &Config{KubeProxyReplacement: mode == infrav1.EnabledKubeProxyReplacement}
+
+	ciliumConfig := &corev1.ConfigMap{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceSystem, Name: CiliumConfigName}, ciliumConfig); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, errors.Wrap(err, "failed to get the configuration of Cilium")
+		}
+	} else {
+		config.Cilium = true
+		config.ServiceHost = ciliumConfig.Data[ciliumServiceHostKey]
+		config.ServicePort = ciliumConfig.Data[ciliumServicePortKey]
+		// Older versions of Cilium replace kube-proxy in strict mode only
+		switch ciliumConfig.Data[ciliumKubeProxyReplacementKey] {
+		case "true", "strict":
+			config.KubeProxyReplacement = true
+		}
+	}
+
+	if !config.KubeProxyReplacement {
+		kubeProxy := &appsv1.DaemonSet{}
+		if err := c.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceSystem, Name: KubeProxyName}, kubeProxy); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, errors.Wrap(err, "failed to get the kube-proxy DaemonSet")
+			}
+			config.KubeProxyReplacement = true
+		}
+	}
+
+	return config, nil
+}
+
+// Validate checks that the kube-proxy replacement of the workload cluster reaches the API server through the
+// control plane endpoint of the cluster, and returns why not. Without kube-proxy, the Service of the API server is
+// not implemented before the CNI starts, so Cilium must reach the API server directly; any other address than the
+// control plane endpoint is lost once the machine behind it is replaced.
+func Validate(config *Config, kubevirtCluster *infrav1.KubevirtCluster) string {
+	if !config.KubeProxyReplacement || !config.Cilium {
+		return ""
+	}
+	if config.ServiceHost == "" {
+		return fmt.Sprintf("Cilium replaces kube-proxy without %s, it cannot reach the API server", ciliumServiceHostKey)
+	}
+
+	endpoint := kubevirtCluster.Spec.ControlPlaneEndpoint
+	hosts := map[string]int{endpoint.Host: endpoint.Port}
+	if external := kubevirtCluster.Spec.ExternalControlPlaneEndpoint; external != nil && external.Host != "" {
+		port := external.Port
+		if port == 0 {
+			port = 6443
+		}
+		hosts[external.Host] = int(port)
+	}
+	if port, found := hosts[config.ServiceHost]; found && config.ServiceHost != "" && config.ServicePort == strconv.Itoa(port) {
+		return ""
+	}
+	return fmt.Sprintf("Cilium reaches the API server at %s:%s rather than the control plane endpoint %s:%d",
+		config.ServiceHost, config.ServicePort, endpoint.Host, endpoint.Port)
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tenantnetwork
+
+import (
+	gocontext "context"
+	"fmt"
+	"net/netip"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+// Reserved is a network the pod and service CIDRs of the clusters must not overlap, and who it belongs to.
+type Reserved struct {
+	Prefix netip.Prefix
+	Owner  string
+}
+
+// ParsePrefixes parses the given CIDRs.
+func ParsePrefixes(cidrs []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid CIDR %q", cidr)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// Overlaps returns a message for each pod or service CIDR of the cluster overlapping another CIDR of the cluster or
+// a reserved network.
+func Overlaps(cluster *clusterv1.Cluster, reserved []Reserved) ([]string, error) {
+	var pods, services []string
+	if network := cluster.Spec.ClusterNetwork; network != nil {
+		if network.Pods != nil {
+			pods = network.Pods.CIDRBlocks
+		}
+		if network.Services != nil {
+			services = network.Services.CIDRBlocks
+		}
+	}
+
+	type clusterCIDR struct {
+		prefix netip.Prefix
+		name   string
+	}
+	var cidrs []clusterCIDR
+	for _, blocks := range []struct {
+		name  string
+		cidrs []string
+	}{{"pod", pods}, {"service", services}} {
+		prefixes, err := ParsePrefixes(blocks.cidrs)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s CIDR of cluster %s/%s", blocks.name, cluster.Namespace, cluster.Name)
+		}
+		for _, prefix := range prefixes {
+			cidrs = append(cidrs, clusterCIDR{prefix: prefix, name: fmt.Sprintf("%s CIDR %s", blocks.name, prefix)})
+		}
+	}
+
+	var overlaps []string
+	for i, cidr := range cidrs {
+		for _, other := range cidrs[i+1:] {
+			if cidr.prefix.Overlaps(other.prefix) {
+				overlaps = append(overlaps, fmt.Sprintf("the %s overlaps the %s", cidr.name, other.name))
+			}
+		}
+		for _, r := range reserved {
+			if cidr.prefix.Overlaps(r.Prefix) {
+				overlaps = append(overlaps, fmt.Sprintf("the %s overlaps %s %s", cidr.name, r.Owner, r.Prefix))
+			}
+		}
+	}
+	return overlaps, nil
+}
+
+// Allocations returns the subnets declared in the network objects of the kind of the spec, but the one of the
+// cluster in the given namespace, e.g. the subnets of the other tenants. The objects are listed in all the
+// namespaces, or only in the given one when the client may not list them cluster-wide.
+func Allocations(ctx gocontext.Context, reader client.Reader, spec infrav1.TenantNetworkSpec, cluster *clusterv1.Cluster, namespace string) ([]Reserved, error) {
+	networks := &unstructured.UnstructuredList{}
+	networks.SetAPIVersion(spec.APIVersion)
+	networks.SetKind(spec.Kind + "List")
+	if err := reader.List(ctx, networks); err != nil {
+		if !apierrors.IsForbidden(err) {
+			return nil, errors.Wrapf(err, "failed to list the tenant networks %s", spec.Kind)
+		}
+		if err := reader.List(ctx, networks, client.InNamespace(namespace)); err != nil {
+			return nil, errors.Wrapf(err, "failed to list the tenant networks %s", spec.Kind)
+		}
+	}
+
+	var allocations []Reserved
+	for _, network := range networks.Items {
+		if network.GetNamespace() == namespace && network.GetName() == Name(cluster) {
+			continue
+		}
+		cidr, _, _ := unstructured.NestedString(network.Object, "spec", "cidr")
+		subnet, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			continue
+		}
+		allocations = append(allocations, Reserved{
+			Prefix: subnet.Masked(),
+			Owner:  fmt.Sprintf("the subnet of %s %s/%s", spec.Kind, network.GetNamespace(), network.GetName()),
+		})
+	}
+	return allocations, nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tenantnetwork_test
+
+import (
+	gocontext "context"
+	"net/netip"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/tenantnetwork"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("Network overlaps", func() {
+	var cluster *clusterv1.Cluster
+
+	BeforeEach(func() {
+		cluster = testing.NewCluster("test-cluster", nil)
+		cluster.Namespace = "default"
+		cluster.Spec.ClusterNetwork = &clusterv1.ClusterNetwork{
+			Pods:     &clusterv1.NetworkRanges{CIDRBlocks: []string{"10.244.0.0/16"}},
+			Services: &clusterv1.NetworkRanges{CIDRBlocks: []string{"10.96.0.0/12"}},
+		}
+	})
+
+	It("should accept the networks not overlapping", func() {
+		overlaps, err := tenantnetwork.Overlaps(cluster, []tenantnetwork.Reserved{
+			{Prefix: netip.MustParsePrefix("192.168.0.0/16"), Owner: "the management network"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(overlaps).To(BeEmpty())
+	})
+
+	It("should report the CIDRs overlapping a reserved network", func() {
+		overlaps, err := tenantnetwork.Overlaps(cluster, []tenantnetwork.Reserved{
+			{Prefix: netip.MustParsePrefix("10.244.128.0/17"), Owner: "the management network"},
+			{Prefix: netip.MustParsePrefix("10.100.0.0/24"), Owner: "the subnet of TenantNetwork default/other-net"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(overlaps).To(ConsistOf(
+			"the pod CIDR 10.244.0.0/16 overlaps the management network 10.244.128.0/17",
+			"the service CIDR 10.96.0.0/12 overlaps the subnet of TenantNetwork default/other-net 10.100.0.0/24",
+		))
+	})
+
+	It("should report the pod CIDRs overlapping the service CIDRs", func() {
+		cluster.Spec.ClusterNetwork.Services.CIDRBlocks = []string{"10.244.10.0/24"}
+		overlaps, err := tenantnetwork.Overlaps(cluster, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(overlaps).To(ConsistOf("the pod CIDR 10.244.0.0/16 overlaps the service CIDR 10.244.10.0/24"))
+	})
+
+	It("should fail on an invalid CIDR", func() {
+		cluster.Spec.ClusterNetwork.Pods.CIDRBlocks = []string{"10.244.0.0"}
+		_, err := tenantnetwork.Overlaps(cluster, nil)
+		Expect(err).To(MatchError(ContainSubstring("invalid pod CIDR of cluster default/test-cluster")))
+	})
+
+	It("should return the subnets of the network objects of the other clusters", func() {
+		newNetwork := func(namespace, name, cidr string) *unstructured.Unstructured {
+			network := &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{"cidr": cidr}}}
+			network.SetAPIVersion("sdn.example.com/v1")
+			network.SetKind("TenantNetwork")
+			network.SetNamespace(namespace)
+			network.SetName(name)
+			return network
+		}
+		infraClient := fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(
+			newNetwork("default", "test-cluster-net", "10.128.0.0/24"),
+			newNetwork("default", "other-net", "10.128.1.0/24"),
+			newNetwork("tenant-b", "test-cluster-net", "10.128.2.0/24"),
+		).Build()
+
+		allocations, err := tenantnetwork.Allocations(gocontext.TODO(), infraClient, infrav1.TenantNetworkSpec{APIVersion: "sdn.example.com/v1", Kind: "TenantNetwork"}, cluster, "default")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(allocations).To(ConsistOf(
+			tenantnetwork.Reserved{Prefix: netip.MustParsePrefix("10.128.1.0/24"), Owner: "the subnet of TenantNetwork default/other-net"},
+			tenantnetwork.Reserved{Prefix: netip.MustParsePrefix("10.128.2.0/24"), Owner: "the subnet of TenantNetwork tenant-b/test-cluster-net"},
+		))
+	})
+})
@@ -0,0 +1,274 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tenantnetwork allocates the subnets of the clusters from a supernet, and declares them in the infra SDN.
+package tenantnetwork
+
+import (
+	gocontext "context"
+	"net/netip"
+	"sync"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+// DefaultPrefixLength is the default prefix length of the subnets allocated to the clusters.
+const DefaultPrefixLength = 24
+
+// Allocator allocates to each cluster a subnet of a supernet not overlapping the subnets of the other clusters.
+type Allocator struct {
+	supernet     netip.Prefix
+	prefixLength int
+
+	lock sync.Mutex
+	// allocated holds the subnets allocated by this allocator, by cluster, as they may not be in the status of the
+	// clusters of the cache yet.
+	allocated map[client.ObjectKey]netip.Prefix
+}
+
+// NewAllocator returns an allocator of the subnets of the given prefix length from the supernet.
+func NewAllocator(supernet string, prefixLength int) (*Allocator, error) {
+	prefix, err := netip.ParsePrefix(supernet)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid tenant supernet %q", supernet)
+	}
+	if prefix.Addr().Is4In6() {
+		return nil, errors.Errorf("invalid tenant supernet %q: use the IPv4 form of IPv4-mapped addresses", supernet)
+	}
+	if prefixLength < prefix.Bits() || prefixLength > prefix.Addr().BitLen() {
+		return nil, errors.Errorf("invalid tenant subnet prefix length %d for supernet %s", prefixLength, supernet)
+	}
+
+	return &Allocator{
+		supernet:     prefix.Masked(),
+		prefixLength: prefixLength,
+		allocated:    map[client.ObjectKey]netip.Prefix{},
+	}, nil
+}
+
+// Supernet returns the supernet the subnets are allocated from.
+func (a *Allocator) Supernet() netip.Prefix {
+	return a.supernet
+}
+
+// Allocate returns the subnet of the cluster, allocating the first free subnet of the supernet if it has none yet.
+// The subnets of the other clusters are read from their status.
+func (a *Allocator) Allocate(ctx gocontext.Context, reader client.Reader, kc *infrav1.KubevirtCluster) (netip.Prefix, error) {
+	if kc.Status.TenantSubnet != "" {
+		subnet, err := netip.ParsePrefix(kc.Status.TenantSubnet)
+		if err != nil {
+			return netip.Prefix{}, errors.Wrapf(err, "invalid tenant subnet %q", kc.Status.TenantSubnet)
+		}
+		return subnet, nil
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	key := client.ObjectKeyFromObject(kc)
+	if subnet, ok := a.allocated[key]; ok {
+		return subnet, nil
+	}
+
+	clusters := &infrav1.KubevirtClusterList{}
+	if err := reader.List(ctx, clusters); err != nil {
+		return netip.Prefix{}, errors.Wrap(err, "failed to list the clusters")
+	}
+	var used []netip.Prefix
+	for _, subnet := range a.allocated {
+		used = append(used, subnet)
+	}
+	for _, cluster := range clusters.Items {
+		if cluster.Status.TenantSubnet == "" || client.ObjectKeyFromObject(&cluster) == key {
+			continue
+		}
+		subnet, err := netip.ParsePrefix(cluster.Status.TenantSubnet)
+		if err != nil {
+			continue
+		}
+		used = append(used, subnet)
+	}
+
+	for subnet, ok := netip.PrefixFrom(a.supernet.Addr(), a.prefixLength), true; ok; subnet, ok = a.next(subnet) {
+		u, ok := overlapping(subnet, used)
+		if !ok {
+			a.allocated[key] = subnet
+			return subnet, nil
+		}
+		// Skip all the subnets of a larger used subnet at once, rather than one by one
+		if u.Bits() < a.prefixLength {
+			subnet = netip.PrefixFrom(lastAddr(u), a.prefixLength).Masked()
+		}
+	}
+	return netip.Prefix{}, errors.Errorf("no subnet left in tenant supernet %s", a.supernet)
+}
+
+// Release forgets the subnet allocated to a deleted cluster, so that it can be allocated again.
+func (a *Allocator) Release(kc *infrav1.KubevirtCluster) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	delete(a.allocated, client.ObjectKeyFromObject(kc))
+}
+
+// next returns the subnet following the given one, and false if it is the last one of the supernet.
+func (a *Allocator) next(subnet netip.Prefix) (netip.Prefix, bool) {
+	addr := subnet.Addr().AsSlice()
+	bit := a.prefixLength - 1
+	if bit < 0 {
+		return netip.Prefix{}, false
+	}
+	i, carry := bit/8, byte(1)<<(7-bit%8)
+	for ; i >= 0 && carry != 0; i-- {
+		sum := addr[i] + carry
+		if sum < addr[i] {
+			carry = 1
+		} else {
+			carry = 0
+		}
+		addr[i] = sum
+	}
+	if carry != 0 {
+		return netip.Prefix{}, false
+	}
+
+	nextAddr, _ := netip.AddrFromSlice(addr)
+	if !a.supernet.Contains(nextAddr) {
+		return netip.Prefix{}, false
+	}
+	return netip.PrefixFrom(nextAddr, a.prefixLength), true
+}
+
+// overlapping returns the first used subnet overlapping the given one, if any.
+func overlapping(subnet netip.Prefix, used []netip.Prefix) (netip.Prefix, bool) {
+	for _, u := range used {
+		if subnet.Overlaps(u) {
+			return u, true
+		}
+	}
+	return netip.Prefix{}, false
+}
+
+// lastAddr returns the last address of the prefix.
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	addr := prefix.Addr().AsSlice()
+	for bit := prefix.Bits(); bit < len(addr)*8; bit++ {
+		addr[bit/8] |= 1 << (7 - bit%8)
+	}
+	last, _ := netip.AddrFromSlice(addr)
+	return last
+}
+
+// Network declares the subnet of a cluster with an object of a network API of the infra cluster.
+type Network struct {
+	name           string
+	spec           infrav1.TenantNetworkSpec
+	infraClient    client.Client
+	infraNamespace string
+}
+
+// NewNetwork returns the network of the cluster, declared with an object in the given namespace of the infra cluster.
+func NewNetwork(ctx *context.ClusterContext, infraClient client.Client, namespace string) (*Network, error) {
+	spec := ctx.KubevirtCluster.Spec.TenantNetwork
+	if spec == nil || spec.APIVersion == "" || spec.Kind == "" {
+		return nil, errors.New("the apiVersion and the kind of the tenant network must be set")
+	}
+
+	return &Network{
+		name:           Name(ctx.Cluster),
+		spec:           *spec,
+		infraClient:    infraClient,
+		infraNamespace: namespace,
+	}, nil
+}
+
+// Name returns the name of the network object of the cluster.
+func Name(cluster *clusterv1.Cluster) string {
+	return cluster.Name + "-net"
+}
+
+// Reconcile creates the network object of the subnet if it does not exist, and updates its spec if it changed.
+func (n *Network) Reconcile(ctx *context.ClusterContext, subnet netip.Prefix) error {
+	spec := map[string]interface{}{}
+	if len(n.spec.Spec.Raw) > 0 {
+		if err := utiljson.Unmarshal(n.spec.Spec.Raw, &spec); err != nil {
+			return errors.Wrap(err, "failed to parse the spec of the tenant network")
+		}
+	}
+	spec["cidr"] = subnet.String()
+
+	network := n.newObject()
+	if err := n.infraClient.Get(ctx, client.ObjectKeyFromObject(network), network); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to get tenant network %s", n.spec.Kind)
+		}
+		network.Object["spec"] = spec
+		network.SetLabels(map[string]string{clusterv1.ClusterNameLabel: ctx.Cluster.Name})
+		if err := n.infraClient.Create(ctx, network); err != nil {
+			return errors.Wrapf(err, "failed to create tenant network %s", n.spec.Kind)
+		}
+		return nil
+	}
+
+	// Keep the fields defaulted by the network API
+	existing, _ := network.Object["spec"].(map[string]interface{})
+	merged := map[string]interface{}{}
+	for k, v := range existing {
+		merged[k] = v
+	}
+	for k, v := range spec {
+		merged[k] = v
+	}
+	if equality.Semantic.DeepEqual(existing, merged) {
+		return nil
+	}
+	network.Object["spec"] = merged
+	if err := n.infraClient.Update(ctx, network); err != nil {
+		return errors.Wrapf(err, "failed to update tenant network %s", n.spec.Kind)
+	}
+	return nil
+}
+
+// Delete deletes the network object of the cluster.
+func (n *Network) Delete(ctx *context.ClusterContext) error {
+	if err := n.infraClient.Delete(ctx, n.newObject()); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete tenant network %s", n.spec.Kind)
+	}
+	return nil
+}
+
+// Object returns the kind and the name of the network object of the cluster.
+func (n *Network) Object() (string, string) {
+	return n.spec.Kind, n.name
+}
+
+func (n *Network) newObject() *unstructured.Unstructured {
+	network := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	network.SetAPIVersion(n.spec.APIVersion)
+	network.SetKind(n.spec.Kind)
+	network.SetNamespace(n.infraNamespace)
+	network.SetName(n.name)
+	return network
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tenantnetwork_test
+
+import (
+	gocontext "context"
+	"net/netip"
+	"testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/tenantnetwork"
+	testutil "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+// FuzzAllocator checks that the subnets allocated from any supernet are aligned subnets of the supernet, of the
+// requested prefix length, and overlap neither each other nor the subnets of the other clusters, valid or not.
+func FuzzAllocator(f *testing.F) {
+	for _, seed := range []struct {
+		supernet     string
+		prefixLength int
+		used         string
+	}{
+		{"10.128.0.0/16", 24, "10.128.0.0/24"},
+		{"10.128.0.0/16", 24, "10.128.0.0/17"},
+		{"10.128.0.5/30", 31, "10.128.0.4/31"},
+		{"10.128.0.0/31", 32, "10.128.0.0/32"},
+		{"10.128.0.1/32", 32, ""},
+		{"255.255.255.0/24", 25, "255.255.255.0/25"},
+		{"0.0.0.0/0", 0, ""},
+		{"0.0.0.0/0", 1, "128.0.0.0/1"},
+		{"fd00::/48", 64, "fd00::/64"},
+		{"fd00::/127", 128, "fd00::1/128"},
+		{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:ff00/120", 124, ""},
+		{"10.0.0.0/8", 32, "10.0.0.0/9"},
+		{"fd00::/16", 128, "fd00::/17"},
+		{"::ffff:10.0.0.0/104", 112, "10.0.0.0/16"},
+		{"10.128.0.0", 24, "10.128.0.0/33"},
+		{"10.128.0.0/16", 15, "not-a-cidr"},
+		{"fd00::/48", 129, "fd00::/129"},
+		{"10.128.0.0/16%eth0", 24, ""},
+	} {
+		f.Add(seed.supernet, seed.prefixLength, seed.used)
+	}
+
+	f.Fuzz(func(t *testing.T, supernet string, prefixLength int, used string) {
+		allocator, err := tenantnetwork.NewAllocator(supernet, prefixLength)
+		if err != nil {
+			return
+		}
+		prefix := netip.MustParsePrefix(supernet).Masked()
+
+		usedSubnet, usedErr := netip.ParsePrefix(used)
+		reader := fake.NewClientBuilder().WithScheme(testutil.SetupScheme()).WithObjects(newKubevirtCluster("used", used)).Build()
+
+		var allocated []netip.Prefix
+		for _, name := range []string{"a", "b"} {
+			subnet, err := allocator.Allocate(gocontext.TODO(), reader, newKubevirtCluster(name, ""))
+			if err != nil {
+				continue
+			}
+			if subnet.Bits() != prefixLength || subnet != subnet.Masked() {
+				t.Fatalf("allocated subnet %s is not an aligned /%d", subnet, prefixLength)
+			}
+			if !prefix.Contains(subnet.Addr()) || subnet.Bits() < prefix.Bits() {
+				t.Fatalf("allocated subnet %s is not in supernet %s", subnet, prefix)
+			}
+			if usedErr == nil && subnet.Overlaps(usedSubnet) {
+				t.Fatalf("allocated subnet %s overlaps the subnet %s of another cluster", subnet, usedSubnet)
+			}
+			for _, other := range allocated {
+				if subnet.Overlaps(other) {
+					t.Fatalf("allocated subnet %s overlaps the subnet %s allocated before", subnet, other)
+				}
+			}
+			allocated = append(allocated, subnet)
+		}
+	})
+}
+
+func newKubevirtCluster(name, subnet string) *infrav1.KubevirtCluster {
+	kc := testutil.NewKubevirtCluster(name, name)
+	kc.Status.TenantSubnet = subnet
+	return kc
+}
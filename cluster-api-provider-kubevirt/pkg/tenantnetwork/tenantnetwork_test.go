@@ -0,0 +1,163 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tenantnetwork_test
+
+import (
+	gocontext "context"
+	"net/netip"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/tenantnetwork"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("Subnet allocator", func() {
+	newCluster := func(name, subnet string) *infrav1.KubevirtCluster {
+		kc := testing.NewKubevirtCluster(name, name)
+		kc.Status.TenantSubnet = subnet
+		return kc
+	}
+
+	It("should allocate the first subnet not used by another cluster", func() {
+		allocator, err := tenantnetwork.NewAllocator("10.128.0.0/16", 24)
+		Expect(err).NotTo(HaveOccurred())
+		reader := fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(
+			newCluster("a", "10.128.0.0/24"),
+			newCluster("b", "10.128.1.0/25"),
+			newCluster("c", "10.128.3.0/24"),
+		).Build()
+
+		subnet, err := allocator.Allocate(gocontext.TODO(), reader, newCluster("d", ""))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(subnet).To(Equal(netip.MustParsePrefix("10.128.2.0/24")))
+
+		By("not allocating the same subnet to another cluster before the status is updated")
+		subnet, err = allocator.Allocate(gocontext.TODO(), reader, newCluster("e", ""))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(subnet).To(Equal(netip.MustParsePrefix("10.128.4.0/24")))
+
+		By("returning the same subnet to the same cluster")
+		subnet, err = allocator.Allocate(gocontext.TODO(), reader, newCluster("d", ""))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(subnet).To(Equal(netip.MustParsePrefix("10.128.2.0/24")))
+	})
+
+	It("should keep the subnet of the status of the cluster", func() {
+		allocator, err := tenantnetwork.NewAllocator("10.128.0.0/16", 24)
+		Expect(err).NotTo(HaveOccurred())
+		reader := fake.NewClientBuilder().WithScheme(testing.SetupScheme()).Build()
+
+		subnet, err := allocator.Allocate(gocontext.TODO(), reader, newCluster("a", "10.128.42.0/24"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(subnet).To(Equal(netip.MustParsePrefix("10.128.42.0/24")))
+	})
+
+	It("should fail when the supernet is exhausted, until a subnet is released", func() {
+		allocator, err := tenantnetwork.NewAllocator("10.128.0.0/23", 24)
+		Expect(err).NotTo(HaveOccurred())
+		reader := fake.NewClientBuilder().WithScheme(testing.SetupScheme()).Build()
+
+		_, err = allocator.Allocate(gocontext.TODO(), reader, newCluster("a", ""))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = allocator.Allocate(gocontext.TODO(), reader, newCluster("b", ""))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = allocator.Allocate(gocontext.TODO(), reader, newCluster("c", ""))
+		Expect(err).To(MatchError(ContainSubstring("no subnet left in tenant supernet 10.128.0.0/23")))
+
+		allocator.Release(newCluster("a", ""))
+		subnet, err := allocator.Allocate(gocontext.TODO(), reader, newCluster("c", ""))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(subnet).To(Equal(netip.MustParsePrefix("10.128.0.0/24")))
+	})
+
+	DescribeTable("should validate the supernet and the prefix length",
+		func(supernet string, prefixLength int, valid bool) {
+			_, err := tenantnetwork.NewAllocator(supernet, prefixLength)
+			Expect(err == nil).To(Equal(valid))
+		},
+		Entry("IPv4", "10.128.0.0/14", 24, true),
+		Entry("IPv6", "fd00::/48", 64, true),
+		Entry("prefix length shorter than the supernet", "10.128.0.0/16", 8, false),
+		Entry("prefix length longer than the addresses", "10.128.0.0/16", 33, false),
+		Entry("invalid supernet", "10.128.0.0", 24, false),
+	)
+})
+
+var _ = Describe("Tenant network", func() {
+	var (
+		infraClient client.Client
+		ctx         *context.ClusterContext
+	)
+
+	getNetwork := func() *unstructured.Unstructured {
+		network := &unstructured.Unstructured{}
+		network.SetAPIVersion("sdn.example.com/v1")
+		network.SetKind("TenantNetwork")
+		Expect(infraClient.Get(ctx, client.ObjectKey{Namespace: "test-namespace", Name: "test-cluster-net"}, network)).To(Succeed())
+		return network
+	}
+
+	BeforeEach(func() {
+		kubevirtCluster := testing.NewKubevirtCluster("test-cluster", "test-kubevirt-cluster")
+		kubevirtCluster.Spec.TenantNetwork = &infrav1.TenantNetworkSpec{
+			APIVersion: "sdn.example.com/v1",
+			Kind:       "TenantNetwork",
+			Spec:       runtime.RawExtension{Raw: []byte(`{"vlan":42}`)},
+		}
+		ctx = &context.ClusterContext{
+			Logger:          ctrl.LoggerFrom(gocontext.TODO()).WithName("test"),
+			Context:         gocontext.TODO(),
+			Cluster:         testing.NewCluster("test-cluster", kubevirtCluster),
+			KubevirtCluster: kubevirtCluster,
+		}
+		infraClient = fake.NewClientBuilder().WithScheme(testing.SetupScheme()).Build()
+	})
+
+	It("should create, update and delete the network object of the subnet", func() {
+		network, err := tenantnetwork.NewNetwork(ctx, infraClient, "test-namespace")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(network.Reconcile(ctx, netip.MustParsePrefix("10.128.2.0/24"))).To(Succeed())
+		object := getNetwork()
+		Expect(object.Object["spec"]).To(Equal(map[string]interface{}{"vlan": int64(42), "cidr": "10.128.2.0/24"}))
+		Expect(object.GetLabels()).To(HaveKeyWithValue("cluster.x-k8s.io/cluster-name", "test-cluster"))
+
+		By("keeping the fields defaulted by the network API")
+		Expect(unstructured.SetNestedField(object.Object, "overlay", "spec", "mode")).To(Succeed())
+		Expect(infraClient.Update(ctx, object)).To(Succeed())
+		Expect(network.Reconcile(ctx, netip.MustParsePrefix("10.128.3.0/24"))).To(Succeed())
+		Expect(getNetwork().Object["spec"]).To(Equal(map[string]interface{}{"vlan": int64(42), "cidr": "10.128.3.0/24", "mode": "overlay"}))
+
+		Expect(network.Delete(ctx)).To(Succeed())
+		Expect(network.Delete(ctx)).To(Succeed())
+	})
+
+	It("should fail without the kind of the network object", func() {
+		ctx.KubevirtCluster.Spec.TenantNetwork.Kind = ""
+		_, err := tenantnetwork.NewNetwork(ctx, infraClient, "test-namespace")
+		Expect(err).To(MatchError(ContainSubstring("the apiVersion and the kind of the tenant network must be set")))
+	})
+})
@@ -2,11 +2,19 @@ package testing
 
 import (
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	kubevirtv1 "kubevirt.io/api/core/v1"
+	exportv1 "kubevirt.io/api/export/v1alpha1"
+	migrationsv1alpha1 "kubevirt.io/api/migrations/v1alpha1"
+	snapshotv1 "kubevirt.io/api/snapshot/v1alpha1"
 	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 
@@ -193,10 +201,18 @@ func SetupScheme() *runtime.Scheme {
 		clusterv1.AddToScheme,
 		infrav1.AddToScheme,
 		kubevirtv1.AddToScheme,
+		migrationsv1alpha1.AddToScheme,
 		cdiv1.AddToScheme,
+		snapshotv1.AddToScheme,
+		exportv1.AddToScheme,
 		corev1.AddToScheme,
 		appsv1.AddToScheme,
+		batchv1.AddToScheme,
 		rbacv1.AddToScheme,
+		coordinationv1.AddToScheme,
+		storagev1.AddToScheme,
+		policyv1.AddToScheme,
+		discoveryv1.AddToScheme,
 	} {
 		if err := f(s); err != nil {
 			panic(err)
@@ -0,0 +1,240 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testing
+
+import (
+	gocontext "context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sclient "k8s.io/client-go/kubernetes"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+// MachineFixture holds the objects of a KubevirtMachine of a running cluster in the management cluster, and the
+// objects of its VM in the infra cluster and of its Node in the workload cluster, to reconcile the machine against
+// fake clients.
+type MachineFixture struct {
+	Cluster         *clusterv1.Cluster
+	KubevirtCluster *infrav1.KubevirtCluster
+	Machine         *clusterv1.Machine
+	KubevirtMachine *infrav1.KubevirtMachine
+	BootstrapSecret *corev1.Secret
+	SSHKeysSecret   *corev1.Secret
+
+	// InfraObjects are the objects of the infra cluster, e.g. the VM and the VMI of the machine.
+	InfraObjects []client.Object
+	// WorkloadObjects are the objects of the workload cluster, e.g. the Node of the machine.
+	WorkloadObjects []client.Object
+}
+
+// NewMachineFixture instantiates the objects of a worker machine of a cluster whose infrastructure is ready and whose
+// control plane is initialized, all in the same namespace. The infra and workload clusters are empty.
+func NewMachineFixture(namespace, clusterName, machineName string) *MachineFixture {
+	kubevirtCluster := NewKubevirtCluster(clusterName, clusterName)
+	kubevirtCluster.TypeMeta = metav1.TypeMeta{Kind: "KubevirtCluster", APIVersion: infrav1.GroupVersion.String()}
+	kubevirtCluster.Namespace = namespace
+	sshKeysSecretName := clusterName + "-ssh-keys"
+	kubevirtCluster.Spec.SshKeys = infrav1.SSHKeys{DataSecretName: &sshKeysSecretName}
+
+	cluster := NewCluster(clusterName, kubevirtCluster)
+	cluster.Namespace = namespace
+	cluster.Status.InfrastructureReady = true
+	conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
+
+	kubevirtMachine := NewKubevirtMachine(machineName, machineName)
+	kubevirtMachine.Namespace = namespace
+	machine := NewMachine(clusterName, machineName, kubevirtMachine)
+	machine.Namespace = namespace
+
+	return &MachineFixture{
+		Cluster:         cluster,
+		KubevirtCluster: kubevirtCluster,
+		Machine:         machine,
+		KubevirtMachine: kubevirtMachine,
+		BootstrapSecret: &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: *machine.Spec.Bootstrap.DataSecretName, Namespace: namespace},
+			Data:       map[string][]byte{"value": []byte("shell-script")},
+		},
+		SSHKeysSecret: &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: sshKeysSecretName, Namespace: namespace},
+			Data: map[string][]byte{
+				"pub": []byte("sha-rsa 1234"),
+				"key": []byte("sha-rsa 5678"),
+			},
+		},
+	}
+}
+
+// ManagementObjects returns the objects of the management cluster.
+func (f *MachineFixture) ManagementObjects() []client.Object {
+	return []client.Object{f.Cluster, f.KubevirtCluster, f.Machine, f.KubevirtMachine, f.BootstrapSecret, f.SSHKeysSecret}
+}
+
+// WithVirtualMachine adds a VM created for the cluster, and its VMI unless nil, to the infra cluster.
+func (f *MachineFixture) WithVirtualMachine(vmi *kubevirtv1.VirtualMachineInstance) *MachineFixture {
+	withVMI := vmi != nil
+	if !withVMI {
+		vmi = NewVirtualMachineInstance(f.KubevirtMachine)
+	}
+	vm := NewVirtualMachine(vmi)
+	vm.Labels = map[string]string{clusterv1.ClusterNameLabel: f.Cluster.Name}
+	f.InfraObjects = append(f.InfraObjects, vm)
+	if withVMI {
+		f.InfraObjects = append(f.InfraObjects, vmi)
+	}
+	return f
+}
+
+// WithNode adds the Node of the machine to the workload cluster.
+func (f *MachineFixture) WithNode(node *corev1.Node) *MachineFixture {
+	f.WorkloadObjects = append(f.WorkloadObjects, node)
+	return f
+}
+
+// NewManagementClient returns a fake client of the management cluster holding its objects.
+func (f *MachineFixture) NewManagementClient() client.Client {
+	objects := f.ManagementObjects()
+	return fake.NewClientBuilder().WithScheme(SetupScheme()).WithObjects(objects...).WithStatusSubresource(objects...).Build()
+}
+
+// NewInfraCluster returns a fake infra cluster holding the objects of the infra cluster, in the namespace of the
+// KubevirtMachine.
+func (f *MachineFixture) NewInfraCluster() *FakeInfraCluster {
+	return &FakeInfraCluster{
+		Client:    fake.NewClientBuilder().WithScheme(SetupScheme()).WithObjects(f.InfraObjects...).WithStatusSubresource(f.InfraObjects...).Build(),
+		Namespace: f.KubevirtMachine.Namespace,
+	}
+}
+
+// NewWorkloadCluster returns a fake workload cluster holding the objects of the workload cluster.
+func (f *MachineFixture) NewWorkloadCluster() *FakeWorkloadCluster {
+	runtimeObjects := make([]runtime.Object, 0, len(f.WorkloadObjects))
+	for _, object := range f.WorkloadObjects {
+		runtimeObjects = append(runtimeObjects, object.DeepCopyObject())
+	}
+	return &FakeWorkloadCluster{
+		// Unlike the API server, the fake client does not ignore the namespace of the keys of cluster-scoped objects
+		Client: fake.NewClientBuilder().WithScheme(SetupScheme()).WithObjects(f.WorkloadObjects...).WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx gocontext.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				if _, ok := obj.(*corev1.Node); ok {
+					key.Namespace = ""
+				}
+				return c.Get(ctx, key, obj, opts...)
+			},
+		}).Build(),
+		K8sClient: k8sfake.NewSimpleClientset(runtimeObjects...),
+	}
+}
+
+// NewReadyVirtualMachineInstance instantiates a running VirtualMachineInstance, ready, live migratable and with an IP
+// address.
+func NewReadyVirtualMachineInstance(kubevirtMachine *infrav1.KubevirtMachine) *kubevirtv1.VirtualMachineInstance {
+	vmi := NewVirtualMachineInstance(kubevirtMachine)
+	vmi.Status.Phase = kubevirtv1.Running
+	vmi.Status.Conditions = []kubevirtv1.VirtualMachineInstanceCondition{
+		{
+			Type:   kubevirtv1.VirtualMachineInstanceReady,
+			Status: corev1.ConditionTrue,
+		},
+		{
+			Type:   kubevirtv1.VirtualMachineInstanceIsMigratable,
+			Status: corev1.ConditionTrue,
+		},
+	}
+	return vmi
+}
+
+// NewNode instantiates the Node of a KubevirtMachine in the workload cluster.
+func NewNode(kubevirtMachine *infrav1.KubevirtMachine) *corev1.Node {
+	return &corev1.Node{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Node",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: kubevirtMachine.Name,
+		},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{
+				{
+					Type:   corev1.NodeReady,
+					Status: corev1.ConditionTrue,
+				},
+			},
+		},
+	}
+}
+
+// FakeInfraCluster is an infracluster.InfraCluster returning a given client of the infra cluster.
+type FakeInfraCluster struct {
+	Client    client.Client
+	Namespace string
+	// RestConfig is returned by GenerateInfraClusterRestConfig, which fails when it is nil.
+	RestConfig *rest.Config
+}
+
+// GenerateInfraClusterClient returns the client of the fake infra cluster.
+func (c *FakeInfraCluster) GenerateInfraClusterClient(_ *corev1.ObjectReference, _ string, _ gocontext.Context) (client.Client, string, error) {
+	return c.Client, c.Namespace, nil
+}
+
+// GenerateInfraClusterRestConfig returns the REST config of the fake infra cluster.
+func (c *FakeInfraCluster) GenerateInfraClusterRestConfig(_ *corev1.ObjectReference, _ string, _ gocontext.Context) (*rest.Config, string, error) {
+	if c.RestConfig == nil {
+		return nil, "", errors.New("the fake infra cluster has no REST config")
+	}
+	return c.RestConfig, c.Namespace, nil
+}
+
+// FakeWorkloadCluster is a workloadcluster.WorkloadCluster returning given clients of the workload cluster.
+type FakeWorkloadCluster struct {
+	Client    client.Client
+	K8sClient k8sclient.Interface
+}
+
+// GenerateWorkloadClusterClient returns the client of the fake workload cluster.
+func (c *FakeWorkloadCluster) GenerateWorkloadClusterClient(_ *context.MachineContext) (client.Client, error) {
+	return c.Client, nil
+}
+
+// GenerateWorkloadClusterK8sClient returns the clientset of the fake workload cluster.
+func (c *FakeWorkloadCluster) GenerateWorkloadClusterK8sClient(_ *context.MachineContext) (k8sclient.Interface, error) {
+	return c.K8sClient, nil
+}
+
+// GenerateClusterClient returns the client of the fake workload cluster.
+func (c *FakeWorkloadCluster) GenerateClusterClient(_ *context.ClusterContext) (client.Client, error) {
+	return c.Client, nil
+}
+
+// GenerateClusterK8sClient returns the clientset of the fake workload cluster.
+func (c *FakeWorkloadCluster) GenerateClusterK8sClient(_ *context.ClusterContext) (k8sclient.Interface, error) {
+	return c.K8sClient, nil
+}
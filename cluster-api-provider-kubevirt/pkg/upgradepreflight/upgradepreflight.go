@@ -0,0 +1,196 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package upgradepreflight checks that a cluster can go through an upgrade of its control plane: the etcd disks of
+// the control plane VMs have room for the migration of the etcd data, KubeVirt is recent enough in the infra cluster,
+// the VMs evicted with live migration can be migrated, and the PodDisruptionBudgets of the workload cluster do not
+// block the drain of the replaced Nodes.
+package upgradepreflight
+
+import (
+	gocontext "context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/rest"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/guestagent"
+)
+
+const (
+	// EtcdFreeSpaceCheck checks the space available on the etcd disk of the control plane VMs.
+	EtcdFreeSpaceCheck = "EtcdFreeSpace"
+
+	// KubeVirtVersionCheck checks the version of KubeVirt of the infra cluster.
+	KubeVirtVersionCheck = "KubeVirtVersion"
+
+	// LiveMigrationCheck checks that the control plane VMs evicted with live migration can be migrated.
+	LiveMigrationCheck = "LiveMigration"
+
+	// PodDisruptionBudgetsCheck checks that no PodDisruptionBudget of the workload cluster blocks the drains.
+	PodDisruptionBudgetsCheck = "PodDisruptionBudgets"
+)
+
+// EtcdFreeSpaceCommand prints the bytes available on the filesystem of the etcd data directory of the guest.
+var EtcdFreeSpaceCommand = []string{"df", "--output=avail", "--block-size=1", "/var/lib/etcd"}
+
+// Passed returns true when all the checks passed.
+func Passed(checks []infrav1.UpgradePreflightCheck) bool {
+	for _, check := range checks {
+		if !check.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// Failures returns a message describing the checks that did not pass.
+func Failures(checks []infrav1.UpgradePreflightCheck) string {
+	var failures []string
+	for _, check := range checks {
+		if !check.Passed {
+			failures = append(failures, fmt.Sprintf("%s: %s", check.Name, check.Message))
+		}
+	}
+	return strings.Join(failures, "; ")
+}
+
+// EtcdFreeSpace checks that the etcd disk of each of the VMs has at least the given space available. The VMs whose
+// guest agent cannot run the check are reported in the message, without failing it.
+func EtcdFreeSpace(ctx gocontext.Context, runner guestagent.Runner, config *rest.Config, vms []types.NamespacedName, minFreeSpace resource.Quantity) infrav1.UpgradePreflightCheck {
+	var failures, unknown []string
+	for _, vm := range vms {
+		result, err := runner.Run(ctx, config, vm.Namespace, vm.Name, EtcdFreeSpaceCommand)
+		if err != nil || result.ExitCode != 0 {
+			unknown = append(unknown, vm.Name)
+			continue
+		}
+		available, err := parseAvailable(result.Stdout)
+		if err != nil {
+			unknown = append(unknown, vm.Name)
+			continue
+		}
+		if available < minFreeSpace.Value() {
+			failures = append(failures, fmt.Sprintf("%s has %s available for etcd", vm.Name, resource.NewQuantity(available, resource.BinarySI)))
+		}
+	}
+
+	if len(failures) > 0 {
+		return infrav1.UpgradePreflightCheck{Name: EtcdFreeSpaceCheck,
+			Message: fmt.Sprintf("%s, %s required", strings.Join(failures, ", "), minFreeSpace.String())}
+	}
+	check := infrav1.UpgradePreflightCheck{Name: EtcdFreeSpaceCheck, Passed: true}
+	if len(unknown) > 0 {
+		check.Message = fmt.Sprintf("the guest agent of %s could not report the free space", strings.Join(unknown, ", "))
+	}
+	return check
+}
+
+// parseAvailable parses the output of EtcdFreeSpaceCommand: a header, then the available bytes.
+func parseAvailable(output string) (int64, error) {
+	lines := strings.Fields(output)
+	if len(lines) == 0 {
+		return 0, errors.New("empty output")
+	}
+	return strconv.ParseInt(lines[len(lines)-1], 10, 64)
+}
+
+// KubeVirtVersion checks that the infra cluster runs at least the given version of KubeVirt. The check passes when
+// the version of the infra cluster is unknown.
+func KubeVirtVersion(infra *infrav1.InfraStatus, minVersion string) infrav1.UpgradePreflightCheck {
+	check := infrav1.UpgradePreflightCheck{Name: KubeVirtVersionCheck}
+	minimum, err := version.ParseGeneric(minVersion)
+	if err != nil {
+		check.Message = fmt.Sprintf("invalid minimum KubeVirt version %q", minVersion)
+		return check
+	}
+
+	if infra == nil || infra.KubeVirtVersion == "" {
+		check.Passed = true
+		check.Message = "the KubeVirt version of the infra cluster is unknown"
+		return check
+	}
+	current, err := version.ParseGeneric(infra.KubeVirtVersion)
+	if err != nil {
+		check.Passed = true
+		check.Message = fmt.Sprintf("the KubeVirt version %q of the infra cluster cannot be parsed", infra.KubeVirtVersion)
+		return check
+	}
+
+	if !current.AtLeast(minimum) {
+		check.Message = fmt.Sprintf("the infra cluster runs KubeVirt %s, %s or later required", infra.KubeVirtVersion, minVersion)
+		return check
+	}
+	check.Passed = true
+	return check
+}
+
+// LiveMigration checks that the VMs of the machines evicted with live migration can be live migrated, as reported
+// by their VMLiveMigratable condition. Machines not reporting the condition yet are not checked.
+func LiveMigration(kubevirtMachines []infrav1.KubevirtMachine) infrav1.UpgradePreflightCheck {
+	var failures []string
+	for i := range kubevirtMachines {
+		kubevirtMachine := &kubevirtMachines[i]
+		template := kubevirtMachine.Spec.VirtualMachineTemplate.Spec.Template
+		if template == nil || template.Spec.EvictionStrategy == nil || *template.Spec.EvictionStrategy != kubevirtv1.EvictionStrategyLiveMigrate {
+			continue
+		}
+		if conditions.IsFalse(kubevirtMachine, infrav1.VMLiveMigratableCondition) {
+			failures = append(failures, fmt.Sprintf("%s is not live migratable: %s", kubevirtMachine.Name,
+				conditions.GetMessage(kubevirtMachine, infrav1.VMLiveMigratableCondition)))
+		}
+	}
+
+	if len(failures) > 0 {
+		sort.Strings(failures)
+		return infrav1.UpgradePreflightCheck{Name: LiveMigrationCheck, Message: strings.Join(failures, ", ")}
+	}
+	return infrav1.UpgradePreflightCheck{Name: LiveMigrationCheck, Passed: true}
+}
+
+// PodDisruptionBudgets checks that no PodDisruptionBudget of the workload cluster covering pods forbids any
+// disruption, which would block the drain of the Nodes replaced by the upgrade.
+func PodDisruptionBudgets(ctx gocontext.Context, c client.Client) (infrav1.UpgradePreflightCheck, error) {
+	pdbs := &policyv1.PodDisruptionBudgetList{}
+	if err := c.List(ctx, pdbs); err != nil {
+		return infrav1.UpgradePreflightCheck{}, errors.Wrap(err, "failed to list the PodDisruptionBudgets of the workload cluster")
+	}
+
+	var blocking []string
+	for _, pdb := range pdbs.Items {
+		if pdb.Status.ExpectedPods > 0 && pdb.Status.DisruptionsAllowed == 0 {
+			blocking = append(blocking, pdb.Namespace+"/"+pdb.Name)
+		}
+	}
+
+	if len(blocking) > 0 {
+		sort.Strings(blocking)
+		return infrav1.UpgradePreflightCheck{Name: PodDisruptionBudgetsCheck,
+			Message: fmt.Sprintf("%s allow no disruption", strings.Join(blocking, ", "))}, nil
+	}
+	return infrav1.UpgradePreflightCheck{Name: PodDisruptionBudgetsCheck, Passed: true}, nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgradepreflight_test
+
+import (
+	gocontext "context"
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/guestagent"
+	guestagentmock "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/guestagent/mock"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/upgradepreflight"
+)
+
+var _ = Describe("Upgrade preflight checks", func() {
+	Context("etcd free space", func() {
+		var runner *guestagentmock.MockRunner
+
+		BeforeEach(func() {
+			runner = guestagentmock.NewMockRunner(gomock.NewController(GinkgoT()))
+		})
+
+		vm := func(name string) types.NamespacedName {
+			return types.NamespacedName{Namespace: "default", Name: name}
+		}
+
+		It("should fail when an etcd disk has less space available than required", func() {
+			runner.EXPECT().Run(gomock.Any(), gomock.Any(), "default", "cp-a", upgradepreflight.EtcdFreeSpaceCommand).
+				Return(&guestagent.Result{Stdout: "   Avail\n5368709120\n"}, nil)
+			runner.EXPECT().Run(gomock.Any(), gomock.Any(), "default", "cp-b", upgradepreflight.EtcdFreeSpaceCommand).
+				Return(&guestagent.Result{Stdout: "   Avail\n536870912\n"}, nil)
+
+			check := upgradepreflight.EtcdFreeSpace(gocontext.Background(), runner, &rest.Config{}, []types.NamespacedName{vm("cp-a"), vm("cp-b")}, resource.MustParse("2Gi"))
+			Expect(check).To(Equal(infrav1.UpgradePreflightCheck{
+				Name:    upgradepreflight.EtcdFreeSpaceCheck,
+				Message: "cp-b has 512Mi available for etcd, 2Gi required",
+			}))
+		})
+
+		It("should not fail for the VMs whose guest agent cannot report the free space", func() {
+			runner.EXPECT().Run(gomock.Any(), gomock.Any(), "default", "cp-a", upgradepreflight.EtcdFreeSpaceCommand).
+				Return(nil, errors.New("guest agent not connected"))
+
+			check := upgradepreflight.EtcdFreeSpace(gocontext.Background(), runner, &rest.Config{}, []types.NamespacedName{vm("cp-a")}, resource.MustParse("2Gi"))
+			Expect(check.Passed).To(BeTrue())
+			Expect(check.Message).To(Equal("the guest agent of cp-a could not report the free space"))
+		})
+	})
+
+	Context("KubeVirt version", func() {
+		It("should require the minimum version of KubeVirt", func() {
+			check := upgradepreflight.KubeVirtVersion(&infrav1.InfraStatus{KubeVirtVersion: "v0.59.2"}, "v1.0.0")
+			Expect(check.Passed).To(BeFalse())
+			Expect(check.Message).To(Equal("the infra cluster runs KubeVirt v0.59.2, v1.0.0 or later required"))
+
+			Expect(upgradepreflight.KubeVirtVersion(&infrav1.InfraStatus{KubeVirtVersion: "v1.2.1"}, "v1.0.0").Passed).To(BeTrue())
+		})
+
+		It("should pass when the version of KubeVirt is unknown", func() {
+			Expect(upgradepreflight.KubeVirtVersion(nil, "v1.0.0").Passed).To(BeTrue())
+			Expect(upgradepreflight.KubeVirtVersion(&infrav1.InfraStatus{}, "v1.0.0").Passed).To(BeTrue())
+		})
+	})
+
+	Context("live migration", func() {
+		It("should only check the machines evicted with live migration", func() {
+			migrated := testing.NewKubevirtMachine("cp-a", "cp-a")
+			migrated.Spec.VirtualMachineTemplate.Spec.Template.Spec.EvictionStrategy = ptr.To(kubevirtv1.EvictionStrategyLiveMigrate)
+			conditions.MarkFalse(migrated, infrav1.VMLiveMigratableCondition, "DisksNotLiveMigratable", clusterv1.ConditionSeverityInfo, "disk root is not shared")
+			restarted := testing.NewKubevirtMachine("cp-b", "cp-b")
+			conditions.MarkFalse(restarted, infrav1.VMLiveMigratableCondition, "DisksNotLiveMigratable", clusterv1.ConditionSeverityInfo, "disk root is not shared")
+
+			Expect(upgradepreflight.LiveMigration([]infrav1.KubevirtMachine{*restarted})).To(Equal(infrav1.UpgradePreflightCheck{
+				Name: upgradepreflight.LiveMigrationCheck, Passed: true,
+			}))
+			Expect(upgradepreflight.LiveMigration([]infrav1.KubevirtMachine{*migrated, *restarted})).To(Equal(infrav1.UpgradePreflightCheck{
+				Name:    upgradepreflight.LiveMigrationCheck,
+				Message: "cp-a is not live migratable: disk root is not shared",
+			}))
+		})
+	})
+})
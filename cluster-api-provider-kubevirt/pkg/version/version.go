@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package version reports the build of the provider, stamped with -ldflags by hack/version.sh.
+package version
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	gitVersion   string // semantic version, output of $(git describe)
+	gitCommit    string // sha1 from git, output of $(git rev-parse HEAD)
+	gitTreeState string // state of git tree, either "clean" or "dirty"
+	buildDate    string // build date in ISO8601 format, output of $(date -u +'%Y-%m-%dT%H:%M:%SZ')
+)
+
+// Info describes the build of the running provider.
+type Info struct {
+	GitVersion   string `json:"gitVersion,omitempty"`
+	GitCommit    string `json:"gitCommit,omitempty"`
+	GitTreeState string `json:"gitTreeState,omitempty"`
+	BuildDate    string `json:"buildDate,omitempty"`
+	GoVersion    string `json:"goVersion,omitempty"`
+	Platform     string `json:"platform,omitempty"`
+}
+
+// Get returns the build of the running provider. The commit falls back to the VCS information recorded by the Go
+// toolchain for binaries built without hack/version.sh, and the version to "devel".
+func Get() Info {
+	info := Info{
+		GitVersion:   gitVersion,
+		GitCommit:    gitCommit,
+		GitTreeState: gitTreeState,
+		BuildDate:    buildDate,
+		GoVersion:    runtime.Version(),
+		Platform:     fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+	}
+	if info.GitCommit == "" {
+		if buildInfo, ok := debug.ReadBuildInfo(); ok {
+			for _, setting := range buildInfo.Settings {
+				switch setting.Key {
+				case "vcs.revision":
+					info.GitCommit = setting.Value
+				case "vcs.modified":
+					if setting.Value == "true" {
+						info.GitTreeState = "dirty"
+					} else {
+						info.GitTreeState = "clean"
+					}
+				}
+			}
+		}
+	}
+	if info.GitVersion == "" {
+		info.GitVersion = "devel"
+	}
+	return info
+}
+
+// String returns the version and the commit of the build, e.g. "v0.9.0 (3f2a1c...)".
+func (i Info) String() string {
+	if i.GitCommit == "" {
+		return i.GitVersion
+	}
+	return fmt.Sprintf("%s (%s)", i.GitVersion, i.GitCommit)
+}
+
+var buildInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "capk_build_info",
+	Help: "Build of the provider, always 1.",
+}, []string{"git_version", "git_commit", "git_tree_state", "build_date", "go_version"})
+
+func init() {
+	info := Get()
+	buildInfo.WithLabelValues(info.GitVersion, info.GitCommit, info.GitTreeState, info.BuildDate, info.GoVersion).Set(1)
+	ctrlmetrics.Registry.MustRegister(buildInfo)
+}
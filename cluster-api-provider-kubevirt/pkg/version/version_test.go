@@ -0,0 +1,48 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Version", func() {
+	AfterEach(func() {
+		gitVersion, gitCommit, gitTreeState, buildDate = "", "", "", ""
+	})
+
+	It("should report the stamped build", func() {
+		gitVersion, gitCommit, gitTreeState, buildDate = "v0.9.0", "3f2a1c", "clean", "2024-05-02T10:00:00Z"
+
+		info := Get()
+		Expect(info.GitVersion).To(Equal("v0.9.0"))
+		Expect(info.GitCommit).To(Equal("3f2a1c"))
+		Expect(info.GitTreeState).To(Equal("clean"))
+		Expect(info.BuildDate).To(Equal("2024-05-02T10:00:00Z"))
+		Expect(info.GoVersion).ToNot(BeEmpty())
+		Expect(info.String()).To(Equal("v0.9.0 (3f2a1c)"))
+	})
+
+	It("should report a build that was not stamped as devel", func() {
+		Expect(Get().GitVersion).To(Equal("devel"))
+	})
+
+	It("should omit the unknown commit", func() {
+		Expect(Info{GitVersion: "devel"}.String()).To(Equal("devel"))
+	})
+})
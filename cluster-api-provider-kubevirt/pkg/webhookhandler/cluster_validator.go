@@ -0,0 +1,311 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhookhandler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"sort"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/hibernation"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/infracluster"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/tenantnetwork"
+)
+
+const kubevirtClusterValidationPath = "/validate-infrastructure-cluster-x-k8s-io-v1alpha1-kubevirtcluster"
+
+// NetworkOptions are the networks the pod and service CIDRs of the new clusters must not overlap, and the node ports
+// their services may request in the infra clusters.
+type NetworkOptions struct {
+	// ManagementNetworks are the networks of the management and infra clusters.
+	ManagementNetworks []netip.Prefix
+	// SubnetAllocator allocates the node subnets of the clusters requesting a tenant network, if enabled.
+	SubnetAllocator *tenantnetwork.Allocator
+	// NodePortRange is the node port range of the infra clusters, if known.
+	NodePortRange *utilnet.PortRange
+}
+
+// SetupKubevirtClusterWebhookWithManager registers the webhook rejecting the KubevirtClusters whose workload cluster
+// networks overlap the networks of the management cluster or of the other tenants, or whose services request node
+// ports of the infra cluster that are out of range or already used.
+func SetupKubevirtClusterWebhookWithManager(mgr ctrl.Manager, infraCluster infracluster.InfraCluster, opts NetworkOptions) error {
+	whHandler := &kubevirtClusterHandler{
+		decoder:      admission.NewDecoder(mgr.GetScheme()),
+		reader:       mgr.GetAPIReader(),
+		infraCluster: infraCluster,
+		opts:         opts,
+	}
+
+	mgr.GetWebhookServer().Register(kubevirtClusterValidationPath, &webhook.Admission{Handler: whHandler})
+
+	return nil
+}
+
+type kubevirtClusterHandler struct {
+	decoder      admission.Decoder
+	reader       client.Reader
+	infraCluster infracluster.InfraCluster
+	opts         NetworkOptions
+}
+
+// Handle checks the hibernation schedules of a new or updated KubevirtCluster. For a new KubevirtCluster, it checks
+// as well the node ports it requests and the pod and service CIDRs of its Cluster, before any VM is created for it.
+// The KubevirtClusters whose Cluster does not exist yet are allowed with a warning.
+func (wh *kubevirtClusterHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return admission.Allowed("")
+	}
+	kc := &v1alpha1.KubevirtCluster{}
+	if err := wh.decoder.Decode(req, kc); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if kc.Namespace == "" {
+		kc.Namespace = req.Namespace
+	}
+
+	if _, err := hibernation.NewSchedule(kc.Spec.HibernationSchedules); err != nil {
+		return admission.Denied(fmt.Sprintf("the hibernation schedules of KubevirtCluster %s are invalid: %v", kc.Name, err))
+	}
+	if req.Operation != admissionv1.Create {
+		return admission.Allowed("")
+	}
+
+	cluster, err := wh.getCluster(ctx, kc)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	conflicts, warning, err := wh.nodePortConflicts(ctx, cluster, kc)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if len(conflicts) > 0 {
+		return admission.Denied(fmt.Sprintf("the node ports requested by KubevirtCluster %s conflict: %s", kc.Name, strings.Join(conflicts, "; ")))
+	}
+	var warnings []string
+	if warning != "" {
+		warnings = append(warnings, warning)
+	}
+	if cluster == nil {
+		warnings = append(warnings, fmt.Sprintf("the Cluster of KubevirtCluster %s was not found, its networks were not checked for overlaps", kc.Name))
+		return admission.Allowed("").WithWarnings(warnings...)
+	}
+
+	reserved, warning := wh.reservedNetworks(ctx, cluster, kc)
+	overlaps, err := tenantnetwork.Overlaps(cluster, reserved)
+	if err != nil {
+		return admission.Denied(err.Error())
+	}
+	if len(overlaps) > 0 {
+		return admission.Denied(fmt.Sprintf("the networks of cluster %s/%s overlap: %s", cluster.Namespace, cluster.Name, strings.Join(overlaps, "; ")))
+	}
+	if warning != "" {
+		warnings = append(warnings, warning)
+	}
+	return admission.Allowed("").WithWarnings(warnings...)
+}
+
+// getCluster returns the Cluster of the KubevirtCluster, found from its cluster name label, its owner reference, or
+// the infrastructure reference of the Clusters of its namespace, or nil if none exists yet.
+func (wh *kubevirtClusterHandler) getCluster(ctx context.Context, kc *v1alpha1.KubevirtCluster) (*clusterv1.Cluster, error) {
+	name := kc.Labels[clusterv1.ClusterNameLabel]
+	for _, ref := range kc.OwnerReferences {
+		if name == "" && ref.Kind == "Cluster" && strings.HasPrefix(ref.APIVersion, clusterv1.GroupVersion.Group+"/") {
+			name = ref.Name
+		}
+	}
+	if name != "" {
+		cluster := &clusterv1.Cluster{}
+		if err := wh.reader.Get(ctx, client.ObjectKey{Namespace: kc.Namespace, Name: name}, cluster); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return cluster, nil
+	}
+
+	clusters := &clusterv1.ClusterList{}
+	if err := wh.reader.List(ctx, clusters, client.InNamespace(kc.Namespace)); err != nil {
+		return nil, err
+	}
+	for i, cluster := range clusters.Items {
+		if ref := cluster.Spec.InfrastructureRef; ref != nil && ref.Kind == "KubevirtCluster" && ref.Name == kc.Name {
+			return &clusters.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// reservedNetworks returns the networks the pod and service CIDRs of the cluster must not overlap: the management
+// networks, the tenant supernet its node subnet is allocated from, and the subnets of the other tenants in the infra
+// SDN. The subnets of the other tenants are skipped, with a warning, when the infra cluster cannot be reached.
+func (wh *kubevirtClusterHandler) reservedNetworks(ctx context.Context, cluster *clusterv1.Cluster, kc *v1alpha1.KubevirtCluster) ([]tenantnetwork.Reserved, string) {
+	var reserved []tenantnetwork.Reserved
+	for _, prefix := range wh.opts.ManagementNetworks {
+		reserved = append(reserved, tenantnetwork.Reserved{Prefix: prefix, Owner: "the management network"})
+	}
+	spec := kc.Spec.TenantNetwork
+	if spec == nil {
+		return reserved, ""
+	}
+	if wh.opts.SubnetAllocator != nil {
+		reserved = append(reserved, tenantnetwork.Reserved{
+			Prefix: wh.opts.SubnetAllocator.Supernet(),
+			Owner:  "the tenant supernet the node subnet is allocated from",
+		})
+	}
+
+	infraClusterClient, infraClusterNamespace, err := wh.infraCluster.GenerateInfraClusterClient(kc.Spec.InfraClusterSecretRef, kc.Namespace, ctx)
+	if err == nil {
+		var allocations []tenantnetwork.Reserved
+		if allocations, err = tenantnetwork.Allocations(ctx, infraClusterClient, *spec, cluster, infraClusterNamespace); err == nil {
+			return append(reserved, allocations...), ""
+		}
+	}
+	return reserved, fmt.Sprintf("the subnets of the other tenants were not checked for overlaps: %v", err)
+}
+
+// nodePortRequest is a node port requested by a service publishing a control plane endpoint of a cluster.
+type nodePortRequest struct {
+	port    int32
+	service string
+}
+
+// requestedNodePorts returns the node ports requested by the services publishing the control plane endpoints of the
+// cluster.
+func requestedNodePorts(kc *v1alpha1.KubevirtCluster) []nodePortRequest {
+	var requests []nodePortRequest
+	request := func(template v1alpha1.ControlPlaneServiceTemplate, defaultType corev1.ServiceType, service string) {
+		serviceType := template.Spec.Type
+		if serviceType == "" {
+			serviceType = defaultType
+		}
+		if template.Spec.NodePort != 0 && (serviceType == corev1.ServiceTypeNodePort || serviceType == corev1.ServiceTypeLoadBalancer) {
+			requests = append(requests, nodePortRequest{port: template.Spec.NodePort, service: service})
+		}
+	}
+	if publisher := kc.Spec.ControlPlaneEndpointPublisher; publisher == nil || publisher.Type == "" || publisher.Type == v1alpha1.ServiceEndpointPublisher {
+		request(kc.Spec.ControlPlaneServiceTemplate, corev1.ServiceTypeClusterIP, "the control plane service")
+	}
+	if external := kc.Spec.ExternalControlPlaneEndpoint; external != nil && external.Host == "" {
+		request(external.ServiceTemplate, corev1.ServiceTypeLoadBalancer, "the external control plane endpoint service")
+	}
+	return requests
+}
+
+// nodePortConflicts returns the node ports requested by the cluster that are out of the node port range of the
+// infra cluster, requested twice, requested by another KubevirtCluster of the same infra cluster, or used by a
+// service of the infra cluster other than the ones of the cluster, e.g. when it is moved to another management
+// cluster. The services of the infra cluster are skipped, with a warning, when it cannot be reached.
+func (wh *kubevirtClusterHandler) nodePortConflicts(ctx context.Context, cluster *clusterv1.Cluster, kc *v1alpha1.KubevirtCluster) ([]string, string, error) {
+	requests := requestedNodePorts(kc)
+	if len(requests) == 0 {
+		return nil, "", nil
+	}
+
+	var conflicts []string
+	requested := map[int32]string{}
+	for _, request := range requests {
+		if service, ok := requested[request.port]; ok {
+			conflicts = append(conflicts, fmt.Sprintf("node port %d is requested by both %s and %s", request.port, service, request.service))
+		}
+		requested[request.port] = request.service
+		if wh.opts.NodePortRange != nil && !wh.opts.NodePortRange.Contains(int(request.port)) {
+			conflicts = append(conflicts, fmt.Sprintf("node port %d of %s is out of the node port range %s of the infra cluster", request.port, request.service, wh.opts.NodePortRange))
+		}
+	}
+
+	kubevirtClusters := &v1alpha1.KubevirtClusterList{}
+	if err := wh.reader.List(ctx, kubevirtClusters); err != nil {
+		return nil, "", err
+	}
+	for i := range kubevirtClusters.Items {
+		other := &kubevirtClusters.Items[i]
+		if other.Namespace == kc.Namespace && other.Name == kc.Name || infraClusterKey(other) != infraClusterKey(kc) {
+			continue
+		}
+		for _, request := range requestedNodePorts(other) {
+			if _, ok := requested[request.port]; ok {
+				conflicts = append(conflicts, fmt.Sprintf("node port %d is already requested by %s of KubevirtCluster %s/%s", request.port, request.service, other.Namespace, other.Name))
+			}
+		}
+	}
+
+	clusterName := ""
+	if cluster != nil {
+		clusterName = cluster.Name
+	}
+	warning := ""
+	services, err := wh.infraServices(ctx, kc)
+	if err != nil {
+		warning = fmt.Sprintf("the requested node ports were not checked against the services of the infra cluster: %v", err)
+	}
+	for _, service := range services {
+		if clusterName != "" && service.Labels[clusterv1.ClusterNameLabel] == clusterName {
+			continue
+		}
+		for _, servicePort := range service.Spec.Ports {
+			if _, ok := requested[servicePort.NodePort]; ok {
+				conflicts = append(conflicts, fmt.Sprintf("node port %d is already used by service %s/%s of the infra cluster", servicePort.NodePort, service.Namespace, service.Name))
+			}
+		}
+	}
+	sort.Strings(conflicts)
+	return conflicts, warning, nil
+}
+
+// infraServices returns the services of all the namespaces of the infra cluster of the KubevirtCluster.
+func (wh *kubevirtClusterHandler) infraServices(ctx context.Context, kc *v1alpha1.KubevirtCluster) ([]corev1.Service, error) {
+	infraClusterClient, _, err := wh.infraCluster.GenerateInfraClusterClient(kc.Spec.InfraClusterSecretRef, kc.Namespace, ctx)
+	if err != nil {
+		return nil, err
+	}
+	services := &corev1.ServiceList{}
+	if err := infraClusterClient.List(ctx, services); err != nil {
+		return nil, err
+	}
+	return services.Items, nil
+}
+
+// infraClusterKey returns the namespace and the name of the kubeconfig secret of the infra cluster of the
+// KubevirtCluster, or an empty string when it runs in the management cluster.
+func infraClusterKey(kc *v1alpha1.KubevirtCluster) string {
+	ref := kc.Spec.InfraClusterSecretRef
+	if ref == nil {
+		return ""
+	}
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = kc.Namespace
+	}
+	return namespace + "/" + ref.Name
+}
@@ -0,0 +1,274 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhookhandler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/netip"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	infraclustermock "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/infracluster/mock"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/tenantnetwork"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("KubevirtCluster Validation - reject the overlapping networks", func() {
+	var (
+		kubevirtCluster  *v1alpha1.KubevirtCluster
+		cluster          *clusterv1.Cluster
+		infraClusterMock *infraclustermock.MockInfraCluster
+		infraObjects     []client.Object
+		opts             NetworkOptions
+	)
+
+	BeforeEach(func() {
+		kubevirtCluster = &v1alpha1.KubevirtCluster{
+			TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: "KubevirtCluster"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-kubevirt-cluster"},
+		}
+		cluster = &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-cluster"},
+			Spec: clusterv1.ClusterSpec{
+				ClusterNetwork: &clusterv1.ClusterNetwork{
+					Pods:     &clusterv1.NetworkRanges{CIDRBlocks: []string{"10.244.0.0/16"}},
+					Services: &clusterv1.NetworkRanges{CIDRBlocks: []string{"10.96.0.0/12"}},
+				},
+				InfrastructureRef: &corev1.ObjectReference{Kind: "KubevirtCluster", Name: "test-kubevirt-cluster"},
+			},
+		}
+		infraClusterMock = infraclustermock.NewMockInfraCluster(gomock.NewController(GinkgoT()))
+		infraObjects = nil
+		opts = NetworkOptions{ManagementNetworks: []netip.Prefix{netip.MustParsePrefix("192.168.0.0/16")}}
+	})
+
+	handle := func(objects ...client.Object) admission.Response {
+		s := testing.SetupScheme()
+		wh := &kubevirtClusterHandler{
+			decoder:      admission.NewDecoder(s),
+			reader:       fake.NewClientBuilder().WithScheme(s).WithObjects(objects...).Build(),
+			infraCluster: infraClusterMock,
+			opts:         opts,
+		}
+		infraClient := fake.NewClientBuilder().WithScheme(s).WithObjects(infraObjects...).Build()
+		infraClusterMock.EXPECT().GenerateInfraClusterClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(infraClient, "default", nil).AnyTimes()
+
+		raw, err := json.Marshal(kubevirtCluster)
+		Expect(err).NotTo(HaveOccurred())
+		return wh.Handle(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				Namespace: "default",
+				UID:       "test-uid",
+				Object:    runtime.RawExtension{Raw: raw},
+			},
+		})
+	}
+
+	It("should allow the clusters whose networks do not overlap", func() {
+		res := handle(cluster)
+		Expect(res.Allowed).To(BeTrue())
+		Expect(res.Warnings).To(BeEmpty())
+	})
+
+	It("should reject the clusters overlapping the management network", func() {
+		cluster.Spec.ClusterNetwork.Pods.CIDRBlocks = []string{"192.168.128.0/17"}
+		res := handle(cluster)
+		Expect(res.Allowed).To(BeFalse())
+		Expect(res.Result.Code).To(Equal(int32(http.StatusForbidden)))
+		Expect(res.Result.Message).To(Equal("the networks of cluster default/test-cluster overlap: the pod CIDR 192.168.128.0/17 overlaps the management network 192.168.0.0/16"))
+	})
+
+	It("should find the Cluster from the cluster name label", func() {
+		cluster.Spec.InfrastructureRef = nil
+		cluster.Spec.ClusterNetwork.Services.CIDRBlocks = []string{"192.168.0.0/24"}
+		kubevirtCluster.Labels = map[string]string{clusterv1.ClusterNameLabel: "test-cluster"}
+		res := handle(cluster)
+		Expect(res.Allowed).To(BeFalse())
+		Expect(res.Result.Message).To(ContainSubstring("the service CIDR 192.168.0.0/24 overlaps the management network 192.168.0.0/16"))
+	})
+
+	It("should reject the clusters overlapping the subnets of the other tenants or the tenant supernet", func() {
+		allocator, err := tenantnetwork.NewAllocator("10.128.0.0/14", 24)
+		Expect(err).NotTo(HaveOccurred())
+		opts.SubnetAllocator = allocator
+		kubevirtCluster.Spec.TenantNetwork = &v1alpha1.TenantNetworkSpec{APIVersion: "sdn.example.com/v1", Kind: "TenantNetwork"}
+		otherNetwork := &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{"cidr": "10.244.3.0/24"}}}
+		otherNetwork.SetAPIVersion("sdn.example.com/v1")
+		otherNetwork.SetKind("TenantNetwork")
+		otherNetwork.SetNamespace("tenant-b")
+		otherNetwork.SetName("other-cluster-net")
+		infraObjects = []client.Object{otherNetwork}
+		cluster.Spec.ClusterNetwork.Services.CIDRBlocks = []string{"10.130.0.0/16"}
+
+		res := handle(cluster)
+		Expect(res.Allowed).To(BeFalse())
+		Expect(res.Result.Message).To(ContainSubstring("the pod CIDR 10.244.0.0/16 overlaps the subnet of TenantNetwork tenant-b/other-cluster-net 10.244.3.0/24"))
+		Expect(res.Result.Message).To(ContainSubstring("the service CIDR 10.130.0.0/16 overlaps the tenant supernet the node subnet is allocated from 10.128.0.0/14"))
+	})
+
+	It("should allow the clusters whose Cluster does not exist yet with a warning", func() {
+		res := handle()
+		Expect(res.Allowed).To(BeTrue())
+		Expect(res.Warnings).To(ConsistOf(ContainSubstring("its networks were not checked for overlaps")))
+	})
+
+	Context("with requested node ports", func() {
+		newService := func(namespace, name, clusterName string, nodePort int32) *corev1.Service {
+			return &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: map[string]string{clusterv1.ClusterNameLabel: clusterName}},
+				Spec: corev1.ServiceSpec{
+					Type:  corev1.ServiceTypeNodePort,
+					Ports: []corev1.ServicePort{{Port: 6443, NodePort: nodePort}},
+				},
+			}
+		}
+
+		BeforeEach(func() {
+			opts.NodePortRange = &utilnet.PortRange{Base: 30000, Size: 2768}
+			kubevirtCluster.Spec.ControlPlaneServiceTemplate.Spec = v1alpha1.ServiceSpecTemplate{Type: corev1.ServiceTypeNodePort, NodePort: 30443}
+		})
+
+		It("should allow the free node ports", func() {
+			infraObjects = []client.Object{newService("tenant-b", "other-cluster-lb", "other-cluster", 30444)}
+			res := handle(cluster)
+			Expect(res.Allowed).To(BeTrue())
+			Expect(res.Warnings).To(BeEmpty())
+		})
+
+		It("should reject the node ports used by the services of the infra cluster or requested by another cluster", func() {
+			infraObjects = []client.Object{newService("tenant-b", "other-cluster-lb", "other-cluster", 30443)}
+			kubevirtCluster.Spec.ExternalControlPlaneEndpoint = &v1alpha1.ExternalControlPlaneEndpointSpec{
+				ServiceTemplate: v1alpha1.ControlPlaneServiceTemplate{Spec: v1alpha1.ServiceSpecTemplate{NodePort: 30500}},
+			}
+			otherKubevirtCluster := &v1alpha1.KubevirtCluster{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "tenant-c", Name: "pending-cluster"},
+			}
+			otherKubevirtCluster.Spec.ControlPlaneServiceTemplate.Spec = v1alpha1.ServiceSpecTemplate{Type: corev1.ServiceTypeLoadBalancer, NodePort: 30500}
+
+			res := handle(cluster, otherKubevirtCluster)
+			Expect(res.Allowed).To(BeFalse())
+			Expect(res.Result.Code).To(Equal(int32(http.StatusForbidden)))
+			Expect(res.Result.Message).To(Equal("the node ports requested by KubevirtCluster test-kubevirt-cluster conflict: " +
+				"node port 30443 is already used by service tenant-b/other-cluster-lb of the infra cluster; " +
+				"node port 30500 is already requested by the control plane service of KubevirtCluster tenant-c/pending-cluster"))
+		})
+
+		It("should ignore the clusters of other infra clusters", func() {
+			otherKubevirtCluster := &v1alpha1.KubevirtCluster{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "tenant-c", Name: "remote-cluster"},
+			}
+			otherKubevirtCluster.Spec.InfraClusterSecretRef = &corev1.ObjectReference{Name: "remote-infra-kubeconfig"}
+			otherKubevirtCluster.Spec.ControlPlaneServiceTemplate.Spec = v1alpha1.ServiceSpecTemplate{Type: corev1.ServiceTypeNodePort, NodePort: 30443}
+
+			res := handle(cluster, otherKubevirtCluster)
+			Expect(res.Allowed).To(BeTrue())
+		})
+
+		It("should reject the node ports out of the node port range, or requested twice", func() {
+			kubevirtCluster.Spec.ControlPlaneServiceTemplate.Spec.NodePort = 8443
+			kubevirtCluster.Spec.ExternalControlPlaneEndpoint = &v1alpha1.ExternalControlPlaneEndpointSpec{
+				ServiceTemplate: v1alpha1.ControlPlaneServiceTemplate{Spec: v1alpha1.ServiceSpecTemplate{NodePort: 8443}},
+			}
+
+			res := handle(cluster)
+			Expect(res.Allowed).To(BeFalse())
+			Expect(res.Result.Message).To(ContainSubstring("node port 8443 of the control plane service is out of the node port range 30000-32767 of the infra cluster"))
+			Expect(res.Result.Message).To(ContainSubstring("node port 8443 is requested by both the control plane service and the external control plane endpoint service"))
+		})
+
+		It("should allow the node ports of the services of the cluster, e.g. when it is moved", func() {
+			infraObjects = []client.Object{newService("default", "test-cluster-lb", "test-cluster", 30443)}
+			res := handle(cluster)
+			Expect(res.Allowed).To(BeTrue())
+		})
+
+		It("should ignore the node ports of the ClusterIP services", func() {
+			kubevirtCluster.Spec.ControlPlaneServiceTemplate.Spec.Type = corev1.ServiceTypeClusterIP
+			kubevirtCluster.Spec.ControlPlaneServiceTemplate.Spec.NodePort = 8443
+			res := handle(cluster)
+			Expect(res.Allowed).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("KubevirtCluster Validation - reject the invalid hibernation schedules", func() {
+	var kubevirtCluster *v1alpha1.KubevirtCluster
+
+	BeforeEach(func() {
+		kubevirtCluster = &v1alpha1.KubevirtCluster{
+			TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: "KubevirtCluster"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-kubevirt-cluster"},
+			Spec: v1alpha1.KubevirtClusterSpec{
+				HibernationSchedules: []v1alpha1.HibernationSchedule{{Hibernate: "0 20 * * 1-5", Resume: "0 8 * * 1-5", TimeZone: "Europe/Paris"}},
+			},
+		}
+	})
+
+	handle := func(operation admissionv1.Operation) admission.Response {
+		s := testing.SetupScheme()
+		wh := &kubevirtClusterHandler{
+			decoder: admission.NewDecoder(s),
+			reader:  fake.NewClientBuilder().WithScheme(s).Build(),
+		}
+
+		raw, err := json.Marshal(kubevirtCluster)
+		Expect(err).NotTo(HaveOccurred())
+		return wh.Handle(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: operation,
+				Namespace: "default",
+				UID:       "test-uid",
+				Object:    runtime.RawExtension{Raw: raw},
+			},
+		})
+	}
+
+	It("should allow the valid schedules", func() {
+		Expect(handle(admissionv1.Create).Allowed).To(BeTrue())
+		Expect(handle(admissionv1.Update).Allowed).To(BeTrue())
+	})
+
+	DescribeTable("should reject the invalid schedules on create and update", func(schedule v1alpha1.HibernationSchedule, expected string) {
+		kubevirtCluster.Spec.HibernationSchedules = append(kubevirtCluster.Spec.HibernationSchedules, schedule)
+		for _, operation := range []admissionv1.Operation{admissionv1.Create, admissionv1.Update} {
+			res := handle(operation)
+			Expect(res.Allowed).To(BeFalse())
+			Expect(res.Result.Code).To(Equal(int32(http.StatusForbidden)))
+			Expect(res.Result.Message).To(HavePrefix("the hibernation schedules of KubevirtCluster test-kubevirt-cluster are invalid: " + expected))
+		}
+	},
+		Entry("hibernate expression", v1alpha1.HibernationSchedule{Hibernate: "at night", Resume: "0 8 * * *"}, "invalid hibernate expression in hibernation schedule 1"),
+		Entry("resume expression", v1alpha1.HibernationSchedule{Hibernate: "0 20 * * *", Resume: "0 25 * * *"}, "invalid resume expression in hibernation schedule 1"),
+		Entry("time zone", v1alpha1.HibernationSchedule{Hibernate: "0 20 * * *", Resume: "0 8 * * *", TimeZone: "Mars/Olympus"}, "invalid time zone in hibernation schedule 1"),
+	)
+})
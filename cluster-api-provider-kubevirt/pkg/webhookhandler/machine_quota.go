@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhookhandler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
+)
+
+const kubevirtMachineValidationPath = "/validate-infrastructure-cluster-x-k8s-io-v1alpha1-kubevirtmachine"
+
+// SetupKubevirtMachineQuotaWebhookWithManager registers the webhook rejecting the KubevirtMachines that would exceed
+// the quota of their cluster.
+func SetupKubevirtMachineQuotaWebhookWithManager(mgr ctrl.Manager) error {
+	whHandler := &kubevirtMachineQuotaHandler{
+		decoder: admission.NewDecoder(mgr.GetScheme()),
+		reader:  mgr.GetAPIReader(),
+	}
+
+	mgr.GetWebhookServer().Register(kubevirtMachineValidationPath, &webhook.Admission{Handler: whHandler})
+
+	return nil
+}
+
+type kubevirtMachineQuotaHandler struct {
+	decoder admission.Decoder
+	reader  client.Reader
+}
+
+// Handle checks a new KubevirtMachine against the quota of the KubevirtCluster of its cluster, on top of the
+// KubevirtMachines of the cluster not being deleted. The KubevirtMachines whose cluster is not known yet are allowed.
+func (wh *kubevirtMachineQuotaHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create {
+		return admission.Allowed("")
+	}
+	km := &v1alpha1.KubevirtMachine{}
+	if err := wh.decoder.Decode(req, km); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if km.Namespace == "" {
+		km.Namespace = req.Namespace
+	}
+
+	kc, err := wh.getKubevirtCluster(ctx, km)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if kc == nil || kc.Spec.Quota == nil {
+		return admission.Allowed("")
+	}
+
+	kubevirtMachines := &v1alpha1.KubevirtMachineList{}
+	if err := wh.reader.List(ctx, kubevirtMachines, client.InNamespace(km.Namespace), client.MatchingLabels{clusterv1.ClusterNameLabel: km.Labels[clusterv1.ClusterNameLabel]}); err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	var counted []v1alpha1.KubevirtMachine
+	for _, kubevirtMachine := range kubevirtMachines.Items {
+		if kubevirtMachine.Name != km.Name && kubevirtMachine.DeletionTimestamp.IsZero() {
+			counted = append(counted, kubevirtMachine)
+		}
+	}
+
+	if message := kubevirt.QuotaExceeded(kc.Spec.Quota, counted, km); message != "" {
+		return admission.Denied(fmt.Sprintf("KubevirtMachine %s exceeds the quota of KubevirtCluster %s: %s", km.Name, kc.Name, message))
+	}
+	return admission.Allowed("")
+}
+
+// getKubevirtCluster returns the KubevirtCluster of the cluster of the KubevirtMachine, found from its cluster name
+// label, or nil if the label is not set or the cluster does not exist yet.
+func (wh *kubevirtMachineQuotaHandler) getKubevirtCluster(ctx context.Context, km *v1alpha1.KubevirtMachine) (*v1alpha1.KubevirtCluster, error) {
+	name := km.Labels[clusterv1.ClusterNameLabel]
+	if name == "" {
+		return nil, nil
+	}
+
+	cluster := &clusterv1.Cluster{}
+	if err := wh.reader.Get(ctx, client.ObjectKey{Namespace: km.Namespace, Name: name}, cluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	ref := cluster.Spec.InfrastructureRef
+	if ref == nil || ref.Kind != "KubevirtCluster" {
+		return nil, nil
+	}
+
+	kc := &v1alpha1.KubevirtCluster{}
+	if err := wh.reader.Get(ctx, client.ObjectKey{Namespace: km.Namespace, Name: ref.Name}, kc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return kc, nil
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhookhandler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("KubevirtMachine Validation - reject the machines exceeding the quota of their cluster", func() {
+	var (
+		kubevirtCluster *v1alpha1.KubevirtCluster
+		cluster         *clusterv1.Cluster
+	)
+
+	newMachine := func(name string) *v1alpha1.KubevirtMachine {
+		return &v1alpha1.KubevirtMachine{
+			TypeMeta: metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: "KubevirtMachine"},
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      name,
+				Labels:    map[string]string{clusterv1.ClusterNameLabel: "test-cluster"},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		kubevirtCluster = &v1alpha1.KubevirtCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-kubevirt-cluster"},
+			Spec:       v1alpha1.KubevirtClusterSpec{Quota: &v1alpha1.ClusterQuota{MaxMachines: ptr.To[int32](2)}},
+		}
+		cluster = &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-cluster"},
+			Spec: clusterv1.ClusterSpec{
+				InfrastructureRef: &corev1.ObjectReference{Kind: "KubevirtCluster", Name: "test-kubevirt-cluster"},
+			},
+		}
+	})
+
+	handle := func(kubevirtMachine *v1alpha1.KubevirtMachine, objects ...client.Object) admission.Response {
+		s := testing.SetupScheme()
+		wh := &kubevirtMachineQuotaHandler{
+			decoder: admission.NewDecoder(s),
+			reader:  fake.NewClientBuilder().WithScheme(s).WithObjects(objects...).Build(),
+		}
+
+		raw, err := json.Marshal(kubevirtMachine)
+		Expect(err).NotTo(HaveOccurred())
+		return wh.Handle(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				Namespace: "default",
+				UID:       "test-uid",
+				Object:    runtime.RawExtension{Raw: raw},
+			},
+		})
+	}
+
+	It("should allow the machines within the quota", func() {
+		res := handle(newMachine("machine-2"), kubevirtCluster, cluster, newMachine("machine-1"))
+		Expect(res.Allowed).To(BeTrue())
+	})
+
+	It("should reject the machines exceeding the quota", func() {
+		res := handle(newMachine("machine-3"), kubevirtCluster, cluster, newMachine("machine-1"), newMachine("machine-2"))
+		Expect(res.Allowed).To(BeFalse())
+		Expect(res.Result.Code).To(Equal(int32(http.StatusForbidden)))
+		Expect(res.Result.Message).To(Equal("KubevirtMachine machine-3 exceeds the quota of KubevirtCluster test-kubevirt-cluster: the cluster would have 3 machines, more than its quota of 2"))
+	})
+
+	It("should not count the machines being deleted", func() {
+		deleted := newMachine("machine-2")
+		deleted.Finalizers = []string{v1alpha1.MachineFinalizer}
+		deleted.DeletionTimestamp = ptr.To(metav1.Now())
+		res := handle(newMachine("machine-3"), kubevirtCluster, cluster, newMachine("machine-1"), deleted)
+		Expect(res.Allowed).To(BeTrue())
+	})
+
+	It("should allow the machines of the clusters without quota or not created yet", func() {
+		kubevirtCluster.Spec.Quota = nil
+		res := handle(newMachine("machine-3"), kubevirtCluster, cluster, newMachine("machine-1"), newMachine("machine-2"))
+		Expect(res.Allowed).To(BeTrue())
+
+		res = handle(newMachine("machine-3"), newMachine("machine-1"), newMachine("machine-2"))
+		Expect(res.Allowed).To(BeTrue())
+	})
+})
@@ -0,0 +1,224 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhookhandler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+)
+
+const (
+	kubevirtClusterDefaultingPath = "/mutate-infrastructure-cluster-x-k8s-io-v1alpha1-kubevirtcluster"
+	kubevirtMachineDefaultingPath = "/mutate-infrastructure-cluster-x-k8s-io-v1alpha1-kubevirtmachine"
+)
+
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=kubevirtproviderconfigs,verbs=get
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get
+
+// SetupProviderDefaultsWebhookWithManager registers the webhooks merging the defaults of the KubevirtProviderConfig
+// into the new KubevirtClusters and KubevirtMachines.
+func SetupProviderDefaultsWebhookWithManager(mgr ctrl.Manager) error {
+	whHandler := &providerDefaultsHandler{
+		decoder: admission.NewDecoder(mgr.GetScheme()),
+		reader:  mgr.GetAPIReader(),
+	}
+
+	srv := mgr.GetWebhookServer()
+	srv.Register(kubevirtClusterDefaultingPath, &webhook.Admission{Handler: whHandler})
+	srv.Register(kubevirtMachineDefaultingPath, &webhook.Admission{Handler: whHandler})
+
+	return nil
+}
+
+type providerDefaultsHandler struct {
+	decoder admission.Decoder
+	reader  client.Reader
+}
+
+// Handle applies the defaults of the namespace of a new KubevirtCluster or KubevirtMachine to the fields it does
+// not set. The objects are allowed unchanged when there is no KubevirtProviderConfig.
+func (wh *providerDefaultsHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create {
+		return admission.Allowed("")
+	}
+
+	var obj client.Object
+	switch req.Kind.Kind {
+	case "KubevirtCluster":
+		obj = &v1alpha1.KubevirtCluster{}
+	case "KubevirtMachine":
+		obj = &v1alpha1.KubevirtMachine{}
+	default:
+		return admission.Allowed("")
+	}
+	if err := wh.decoder.Decode(req, obj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	defaults, found, err := wh.defaults(ctx, req.Namespace)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if !found {
+		return admission.Allowed("")
+	}
+
+	switch obj := obj.(type) {
+	case *v1alpha1.KubevirtCluster:
+		applyClusterDefaults(obj, defaults)
+	case *v1alpha1.KubevirtMachine:
+		applyMachineDefaults(obj, defaults)
+	}
+	marshaled, err := json.Marshal(obj)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
+
+// defaults returns the defaults of the namespace: the ones of the KubevirtProviderConfig, replaced field by field by
+// the ones of the overrides selecting the namespace, in order. It returns false when there is no
+// KubevirtProviderConfig.
+func (wh *providerDefaultsHandler) defaults(ctx context.Context, namespace string) (v1alpha1.ProviderDefaults, bool, error) {
+	config := &v1alpha1.KubevirtProviderConfig{}
+	if err := wh.reader.Get(ctx, client.ObjectKey{Name: v1alpha1.ProviderConfigName}, config); err != nil {
+		if apierrors.IsNotFound(err) {
+			return v1alpha1.ProviderDefaults{}, false, nil
+		}
+		return v1alpha1.ProviderDefaults{}, false, err
+	}
+
+	defaults := config.Spec.Defaults
+	if len(config.Spec.Overrides) == 0 {
+		return defaults, true, nil
+	}
+	ns := &corev1.Namespace{}
+	if err := wh.reader.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil {
+		return v1alpha1.ProviderDefaults{}, false, err
+	}
+	for _, override := range config.Spec.Overrides {
+		selector, err := metav1.LabelSelectorAsSelector(&override.NamespaceSelector)
+		if err != nil {
+			return v1alpha1.ProviderDefaults{}, false, err
+		}
+		if !selector.Matches(labels.Set(ns.Labels)) {
+			continue
+		}
+		if override.Defaults.StorageClassName != nil {
+			defaults.StorageClassName = override.Defaults.StorageClassName
+		}
+		if override.Defaults.Offload != nil {
+			defaults.Offload = override.Defaults.Offload
+		}
+		if override.Defaults.Placement != nil {
+			defaults.Placement = override.Defaults.Placement
+		}
+		if override.Defaults.RegistryMirrors != nil {
+			defaults.RegistryMirrors = override.Defaults.RegistryMirrors
+		}
+		if override.Defaults.Addons != nil {
+			defaults.Addons = override.Defaults.Addons
+		}
+	}
+	return defaults, true, nil
+}
+
+// applyClusterDefaults sets the registry mirrors and the addons of the cluster it does not configure.
+func applyClusterDefaults(kc *v1alpha1.KubevirtCluster, defaults v1alpha1.ProviderDefaults) {
+	if len(kc.Spec.RegistryMirrors) == 0 && len(defaults.RegistryMirrors) > 0 {
+		for _, mirror := range defaults.RegistryMirrors {
+			kc.Spec.RegistryMirrors = append(kc.Spec.RegistryMirrors, *mirror.DeepCopy())
+		}
+	}
+	if addons := defaults.Addons; addons != nil {
+		if kc.Spec.CloudControllerManager == nil && addons.CloudControllerManager != nil {
+			kc.Spec.CloudControllerManager = addons.CloudControllerManager.DeepCopy()
+		}
+		if kc.Spec.CSIDriver == nil && addons.CSIDriver != nil {
+			kc.Spec.CSIDriver = addons.CSIDriver.DeepCopy()
+		}
+	}
+}
+
+// applyMachineDefaults sets the storage class of the DataVolumeTemplates, the I/O offload and the placement of the
+// VM of the machine it does not set.
+func applyMachineDefaults(machine *v1alpha1.KubevirtMachine, defaults v1alpha1.ProviderDefaults) {
+	vm := &machine.Spec.VirtualMachineTemplate.Spec
+
+	if name := defaults.StorageClassName; name != nil {
+		for i := range vm.DataVolumeTemplates {
+			spec := &vm.DataVolumeTemplates[i].Spec
+			switch {
+			case spec.Storage != nil:
+				if spec.Storage.StorageClassName == nil {
+					spec.Storage.StorageClassName = ptr.To(*name)
+				}
+			case spec.PVC != nil:
+				if spec.PVC.StorageClassName == nil {
+					spec.PVC.StorageClassName = ptr.To(*name)
+				}
+			}
+		}
+	}
+
+	if offload := defaults.Offload; offload != nil {
+		if machine.Spec.IOThreadsPolicy == nil && offload.IOThreadsPolicy != nil {
+			machine.Spec.IOThreadsPolicy = ptr.To(*offload.IOThreadsPolicy)
+		}
+		tuned := map[string]bool{}
+		for _, tuning := range machine.Spec.Disks {
+			tuned[tuning.Name] = true
+		}
+		for _, tuning := range offload.Disks {
+			if !tuned[tuning.Name] {
+				machine.Spec.Disks = append(machine.Spec.Disks, *tuning.DeepCopy())
+			}
+		}
+	}
+
+	if placement := defaults.Placement; placement != nil && vm.Template != nil {
+		spec := &vm.Template.Spec
+		if len(spec.NodeSelector) == 0 && len(placement.NodeSelector) > 0 {
+			spec.NodeSelector = map[string]string{}
+			for key, value := range placement.NodeSelector {
+				spec.NodeSelector[key] = value
+			}
+		}
+		if spec.Affinity == nil && placement.Affinity != nil {
+			spec.Affinity = placement.Affinity.DeepCopy()
+		}
+		if len(spec.Tolerations) == 0 && len(placement.Tolerations) > 0 {
+			for _, toleration := range placement.Tolerations {
+				spec.Tolerations = append(spec.Tolerations, *toleration.DeepCopy())
+			}
+		}
+	}
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhookhandler
+
+import (
+	"context"
+	"encoding/json"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+)
+
+var _ = Describe("Provider defaults - merge the defaults of the organization", func() {
+	var (
+		providerConfig *v1alpha1.KubevirtProviderConfig
+		namespace      *corev1.Namespace
+	)
+
+	BeforeEach(func() {
+		providerConfig = &v1alpha1.KubevirtProviderConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: v1alpha1.ProviderConfigName},
+			Spec: v1alpha1.KubevirtProviderConfigSpec{
+				Defaults: v1alpha1.ProviderDefaults{
+					StorageClassName: ptr.To("standard-encrypted"),
+					Offload: &v1alpha1.OffloadProfile{
+						IOThreadsPolicy: ptr.To(kubevirtv1.IOThreadsPolicyShared),
+						Disks:           []v1alpha1.DiskTuning{{Name: "rootdisk", Cache: kubevirtv1.CacheNone}},
+					},
+					Placement: &v1alpha1.PlacementPolicy{
+						NodeSelector: map[string]string{"node-role.example.com/tenants": ""},
+						Tolerations:  []corev1.Toleration{{Key: "tenants", Operator: corev1.TolerationOpExists}},
+					},
+					RegistryMirrors: []v1alpha1.RegistryMirror{{Registry: "docker.io", Endpoints: []string{"https://mirror.example.com"}}},
+					Addons: &v1alpha1.AddonSet{
+						CloudControllerManager: &v1alpha1.CloudControllerManagerSpec{},
+					},
+				},
+			},
+		}
+		namespace = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default", Labels: map[string]string{"tier": "gold"}}}
+	})
+
+	// handle admits the object and decodes it, patched, into patched.
+	handle := func(kind string, obj, patched client.Object, objects ...client.Object) admission.Response {
+		s := testing.SetupScheme()
+		wh := &providerDefaultsHandler{
+			decoder: admission.NewDecoder(s),
+			reader:  fake.NewClientBuilder().WithScheme(s).WithObjects(objects...).Build(),
+		}
+
+		raw, err := json.Marshal(obj)
+		Expect(err).NotTo(HaveOccurred())
+		res := wh.Handle(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				Kind:      metav1.GroupVersionKind{Group: v1alpha1.GroupVersion.Group, Version: v1alpha1.GroupVersion.Version, Kind: kind},
+				Namespace: "default",
+				UID:       "test-uid",
+				Object:    runtime.RawExtension{Raw: raw},
+			},
+		})
+		Expect(res.Allowed).To(BeTrue())
+
+		patch, err := json.Marshal(res.Patches)
+		Expect(err).NotTo(HaveOccurred())
+		decoded, err := jsonpatch.DecodePatch(patch)
+		Expect(err).NotTo(HaveOccurred())
+		patchedRaw, err := decoded.Apply(raw)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(json.Unmarshal(patchedRaw, patched)).To(Succeed())
+		return res
+	}
+
+	newKubevirtMachine := func() *v1alpha1.KubevirtMachine {
+		return &v1alpha1.KubevirtMachine{
+			TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: "KubevirtMachine"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-machine"},
+			Spec: v1alpha1.KubevirtMachineSpec{
+				VirtualMachineTemplate: v1alpha1.VirtualMachineTemplateSpec{
+					Spec: kubevirtv1.VirtualMachineSpec{
+						DataVolumeTemplates: []kubevirtv1.DataVolumeTemplateSpec{
+							{ObjectMeta: metav1.ObjectMeta{Name: "rootdisk"}, Spec: cdiv1.DataVolumeSpec{Storage: &cdiv1.StorageSpec{}}},
+							{ObjectMeta: metav1.ObjectMeta{Name: "datadisk"}, Spec: cdiv1.DataVolumeSpec{PVC: &corev1.PersistentVolumeClaimSpec{StorageClassName: ptr.To("fast")}}},
+						},
+						Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+							Spec: kubevirtv1.VirtualMachineInstanceSpec{
+								NodeSelector: map[string]string{"kubernetes.io/arch": "amd64"},
+							},
+						},
+					},
+				},
+				Disks: []v1alpha1.DiskTuning{{Name: "datadisk", Cache: kubevirtv1.CacheWriteBack}},
+			},
+		}
+	}
+
+	It("should merge the defaults into the fields the KubevirtMachine does not set", func() {
+		machine := &v1alpha1.KubevirtMachine{}
+		handle("KubevirtMachine", newKubevirtMachine(), machine, providerConfig, namespace)
+
+		vm := machine.Spec.VirtualMachineTemplate.Spec
+		Expect(vm.DataVolumeTemplates[0].Spec.Storage.StorageClassName).To(Equal(ptr.To("standard-encrypted")))
+		Expect(vm.DataVolumeTemplates[1].Spec.PVC.StorageClassName).To(Equal(ptr.To("fast")))
+		Expect(machine.Spec.IOThreadsPolicy).To(Equal(ptr.To(kubevirtv1.IOThreadsPolicyShared)))
+		Expect(machine.Spec.Disks).To(Equal([]v1alpha1.DiskTuning{
+			{Name: "datadisk", Cache: kubevirtv1.CacheWriteBack},
+			{Name: "rootdisk", Cache: kubevirtv1.CacheNone},
+		}))
+		Expect(vm.Template.Spec.NodeSelector).To(Equal(map[string]string{"kubernetes.io/arch": "amd64"}))
+		Expect(vm.Template.Spec.Tolerations).To(Equal(providerConfig.Spec.Defaults.Placement.Tolerations))
+	})
+
+	It("should merge the defaults into the fields the KubevirtCluster does not set", func() {
+		kubevirtCluster := &v1alpha1.KubevirtCluster{
+			TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: "KubevirtCluster"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-kubevirt-cluster"},
+			Spec: v1alpha1.KubevirtClusterSpec{
+				CSIDriver: &v1alpha1.CSIDriverSpec{StorageClasses: []v1alpha1.StorageClassMapping{{Name: "standard", InfraStorageClassName: "ceph"}}},
+			},
+		}
+		providerConfig.Spec.Defaults.Addons.CSIDriver = &v1alpha1.CSIDriverSpec{Image: "registry.example.com/csi-driver:v1"}
+
+		patched := &v1alpha1.KubevirtCluster{}
+		handle("KubevirtCluster", kubevirtCluster, patched, providerConfig, namespace)
+
+		Expect(patched.Spec.RegistryMirrors).To(Equal(providerConfig.Spec.Defaults.RegistryMirrors))
+		Expect(patched.Spec.CloudControllerManager).To(Equal(&v1alpha1.CloudControllerManagerSpec{}))
+		Expect(patched.Spec.CSIDriver).To(Equal(kubevirtCluster.Spec.CSIDriver))
+	})
+
+	It("should apply the overrides selecting the namespace in order", func() {
+		providerConfig.Spec.Overrides = []v1alpha1.ProviderConfigOverride{
+			{
+				NamespaceSelector: metav1.LabelSelector{MatchLabels: map[string]string{"tier": "gold"}},
+				Defaults:          v1alpha1.ProviderDefaults{StorageClassName: ptr.To("gold")},
+			},
+			{
+				NamespaceSelector: metav1.LabelSelector{MatchLabels: map[string]string{"tier": "silver"}},
+				Defaults:          v1alpha1.ProviderDefaults{StorageClassName: ptr.To("silver")},
+			},
+			{
+				NamespaceSelector: metav1.LabelSelector{MatchLabels: map[string]string{"tier": "gold"}},
+				Defaults:          v1alpha1.ProviderDefaults{Placement: &v1alpha1.PlacementPolicy{}},
+			},
+		}
+
+		machine := &v1alpha1.KubevirtMachine{}
+		handle("KubevirtMachine", newKubevirtMachine(), machine, providerConfig, namespace)
+
+		Expect(machine.Spec.VirtualMachineTemplate.Spec.DataVolumeTemplates[0].Spec.Storage.StorageClassName).To(Equal(ptr.To("gold")))
+		Expect(machine.Spec.VirtualMachineTemplate.Spec.Template.Spec.Tolerations).To(BeEmpty())
+		Expect(machine.Spec.IOThreadsPolicy).To(Equal(ptr.To(kubevirtv1.IOThreadsPolicyShared)))
+	})
+
+	It("should allow the objects unchanged without KubevirtProviderConfig", func() {
+		machine := &v1alpha1.KubevirtMachine{}
+		res := handle("KubevirtMachine", newKubevirtMachine(), machine, namespace)
+		Expect(res.Patches).To(BeEmpty())
+		Expect(machine.Spec).To(Equal(newKubevirtMachine().Spec))
+	})
+})
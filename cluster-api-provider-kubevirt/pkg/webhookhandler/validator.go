@@ -29,6 +29,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/kubevirt"
 )
 
 const (
@@ -65,6 +66,7 @@ func (wh *kubevirtMachineTemplateHandler) Handle(_ context.Context, req admissio
 			return admission.Errored(http.StatusBadRequest, err)
 		}
 
+		err = wh.validateCreate(kvTmplt)
 	case admissionv1.Update:
 		oldKVTmplt := &v1alpha1.KubevirtMachineTemplate{}
 		// Server Side Apply implementation in ClusterClass and managed topologies requires to dry-run changes on templates.
@@ -99,8 +101,24 @@ func (wh *kubevirtMachineTemplateHandler) Handle(_ context.Context, req admissio
 	return admission.Allowed("")
 }
 
+func (wh *kubevirtMachineTemplateHandler) validateCreate(requested *v1alpha1.KubevirtMachineTemplate) error {
+	if message := kubevirt.ArchitectureConflicts(&requested.Spec.Template.Spec); message != "" {
+		return errors.New(message)
+	}
+
+	return nil
+}
+
 func (wh *kubevirtMachineTemplateHandler) validateUpdate(old *v1alpha1.KubevirtMachineTemplate, requested *v1alpha1.KubevirtMachineTemplate) error {
-	if !reflect.DeepEqual(old.Spec, requested.Spec) {
+	oldSpec, requestedSpec := old.Spec.DeepCopy(), requested.Spec.DeepCopy()
+
+	// The size of the VMs may change on templates allowing in-place resize
+	if requested.Annotations[v1alpha1.InPlaceResizeAnnotation] == "true" {
+		kubevirt.SetCompute(&oldSpec.Template.Spec.VirtualMachineTemplate.Spec, kubevirt.Compute{})
+		kubevirt.SetCompute(&requestedSpec.Template.Spec.VirtualMachineTemplate.Spec, kubevirt.Compute{})
+	}
+
+	if !reflect.DeepEqual(oldSpec, requestedSpec) {
 		return errors.New(immutableWarning)
 	}
 
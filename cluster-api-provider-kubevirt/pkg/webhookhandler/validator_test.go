@@ -30,6 +30,7 @@ import (
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/utils/pointer"
 	"k8s.io/utils/ptr"
+	kubevirtv1 "kubevirt.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
@@ -71,6 +72,33 @@ var _ = Describe("Template Validation - ensure immutability in update request",
 					},
 				},
 			}, errors.New(immutableWarning)),
+			Entry("should return error if the size of the VMs changes", &v1alpha1.KubevirtMachineTemplate{
+				Spec: v1alpha1.KubevirtMachineTemplateSpec{
+					Template: v1alpha1.KubevirtMachineTemplateResource{
+						Spec: v1alpha1.KubevirtMachineSpec{VirtualMachineTemplate: newVMTemplateWithCPU(4)},
+					},
+				},
+			}, errors.New(immutableWarning)),
+			Entry("should not return error if the size of the VMs changes on a template allowing in-place resize", &v1alpha1.KubevirtMachineTemplate{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{v1alpha1.InPlaceResizeAnnotation: "true"},
+				},
+				Spec: v1alpha1.KubevirtMachineTemplateSpec{
+					Template: v1alpha1.KubevirtMachineTemplateResource{
+						Spec: v1alpha1.KubevirtMachineSpec{VirtualMachineTemplate: newVMTemplateWithCPU(4)},
+					},
+				},
+			}, nil),
+			Entry("should return error if there is another change on a template allowing in-place resize", &v1alpha1.KubevirtMachineTemplate{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{v1alpha1.InPlaceResizeAnnotation: "true"},
+				},
+				Spec: v1alpha1.KubevirtMachineTemplateSpec{
+					Template: v1alpha1.KubevirtMachineTemplateResource{
+						Spec: v1alpha1.KubevirtMachineSpec{ProviderID: pointer.String("testing")},
+					},
+				},
+			}, errors.New(immutableWarning)),
 		)
 	})
 
@@ -90,7 +118,7 @@ var _ = Describe("Template Validation - ensure immutability in update request",
 			ctx = context.Background()
 		})
 
-		It("should always return OK for create request", func() {
+		It("should return OK for create request of a valid template", func() {
 			newTemplate := &v1alpha1.KubevirtMachineTemplate{
 				Spec: v1alpha1.KubevirtMachineTemplateSpec{
 					Template: v1alpha1.KubevirtMachineTemplateResource{
@@ -106,6 +134,23 @@ var _ = Describe("Template Validation - ensure immutability in update request",
 			Expect(res.Result.Code).To(Equal(int32(http.StatusOK)))
 		})
 
+		It("should return error for create request, if the VM template conflicts with the architecture", func() {
+			newTemplate := &v1alpha1.KubevirtMachineTemplate{
+				Spec: v1alpha1.KubevirtMachineTemplateSpec{
+					Template: v1alpha1.KubevirtMachineTemplateResource{
+						Spec: v1alpha1.KubevirtMachineSpec{Architecture: "arm64", MachineType: "q35"},
+					},
+				},
+			}
+
+			req := newRequest(admissionv1.Create, newTemplate, nil, v1alpha1Codec)
+
+			res := wh.Handle(ctx, req)
+			Expect(res.Allowed).To(BeFalse())
+			Expect(res.Result.Code).To(Equal(int32(http.StatusForbidden)))
+			Expect(res.Result.Message).To(Equal("architecture arm64 conflicts with the VM template: machine type q35 is not a virt machine type"))
+		})
+
 		It("should always return OK for delete request", func() {
 			oldTemplate := &v1alpha1.KubevirtMachineTemplate{
 				Spec: v1alpha1.KubevirtMachineTemplateSpec{
@@ -162,6 +207,18 @@ var _ = Describe("Template Validation - ensure immutability in update request",
 	})
 })
 
+func newVMTemplateWithCPU(cores uint32) v1alpha1.VirtualMachineTemplateSpec {
+	return v1alpha1.VirtualMachineTemplateSpec{
+		Spec: kubevirtv1.VirtualMachineSpec{
+			Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+				Spec: kubevirtv1.VirtualMachineInstanceSpec{
+					Domain: kubevirtv1.DomainSpec{CPU: &kubevirtv1.CPU{Cores: cores}},
+				},
+			},
+		},
+	}
+}
+
 func newRequest(operation admissionv1.Operation, oldObj, newObj *v1alpha1.KubevirtMachineTemplate, encoder runtime.Encoder) admission.Request {
 	req := admission.Request{
 		AdmissionRequest: admissionv1.AdmissionRequest{
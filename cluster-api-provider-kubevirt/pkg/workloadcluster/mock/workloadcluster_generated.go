@@ -37,6 +37,36 @@ func (m *MockWorkloadCluster) EXPECT() *MockWorkloadClusterMockRecorder {
 	return m.recorder
 }
 
+// GenerateClusterClient mocks base method.
+func (m *MockWorkloadCluster) GenerateClusterClient(ctx *context.ClusterContext) (client.Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateClusterClient", ctx)
+	ret0, _ := ret[0].(client.Client)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateClusterClient indicates an expected call of GenerateClusterClient.
+func (mr *MockWorkloadClusterMockRecorder) GenerateClusterClient(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateClusterClient", reflect.TypeOf((*MockWorkloadCluster)(nil).GenerateClusterClient), ctx)
+}
+
+// GenerateClusterK8sClient mocks base method.
+func (m *MockWorkloadCluster) GenerateClusterK8sClient(ctx *context.ClusterContext) (kubernetes.Interface, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateClusterK8sClient", ctx)
+	ret0, _ := ret[0].(kubernetes.Interface)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateClusterK8sClient indicates an expected call of GenerateClusterK8sClient.
+func (mr *MockWorkloadClusterMockRecorder) GenerateClusterK8sClient(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateClusterK8sClient", reflect.TypeOf((*MockWorkloadCluster)(nil).GenerateClusterK8sClient), ctx)
+}
+
 // GenerateWorkloadClusterClient mocks base method.
 func (m *MockWorkloadCluster) GenerateWorkloadClusterClient(ctx *context.MachineContext) (client.Client, error) {
 	m.ctrl.T.Helper()
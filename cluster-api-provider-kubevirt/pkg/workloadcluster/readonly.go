@@ -0,0 +1,57 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadcluster
+
+import (
+	"net/http"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NewReadOnly creates a WorkloadCluster whose clients perform no writes to the workload clusters: their creations,
+// updates, patches, deletions and evictions are sent as server-side dry-runs, which the workload clusters validate
+// and admit without persisting them.
+func NewReadOnly(client client.Client) WorkloadCluster {
+	return &workloadCluster{
+		Client:   client,
+		readOnly: true,
+	}
+}
+
+// dryRunRoundTripper adds the dry-run parameter to the write requests, whichever client sends them.
+type dryRunRoundTripper struct {
+	delegate http.RoundTripper
+}
+
+func newDryRunRoundTripper(delegate http.RoundTripper) http.RoundTripper {
+	return &dryRunRoundTripper{delegate: delegate}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (d *dryRunRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return d.delegate.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	query := req.URL.Query()
+	query.Set("dryRun", metav1.DryRunAll)
+	req.URL.RawQuery = query.Encode()
+	return d.delegate.RoundTrip(req)
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadcluster
+
+import (
+	gocontext "context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	k8sclient "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+)
+
+const (
+	// DefaultHealthCheckInterval is the interval between two probes of the apiserver of a watched workload cluster.
+	DefaultHealthCheckInterval = 10 * time.Second
+	// DefaultHealthCheckFailureThreshold is the number of consecutive failed probes after which the watches of a
+	// workload cluster are stopped.
+	DefaultHealthCheckFailureThreshold = 3
+
+	healthCheckTimeout = 5 * time.Second
+)
+
+// WatcherOptions configures a Watcher.
+type WatcherOptions struct {
+	// HealthCheckInterval defaults to DefaultHealthCheckInterval.
+	HealthCheckInterval time.Duration
+	// HealthCheckFailureThreshold defaults to DefaultHealthCheckFailureThreshold.
+	HealthCheckFailureThreshold int
+}
+
+// WatchInput describes a watch started on a workload cluster.
+type WatchInput struct {
+	// Name identifies the watch; a watch is only started once per workload cluster.
+	Name string
+	// Controller is the controller the events are sent to.
+	Controller controller.Controller
+	// Kind is the type of the watched objects.
+	Kind client.Object
+	// EventHandler enqueues the requests for the events of the watched objects.
+	EventHandler handler.EventHandler
+	// Predicates filter the events of the watched objects.
+	Predicates []predicate.Predicate
+}
+
+// Watcher runs informers against the apiservers of workload clusters, so that controllers of the management cluster
+// can watch objects of the workload clusters. The informers of a workload cluster are stopped as soon as its
+// apiserver stops answering; the next call to Watch starts them again.
+type Watcher struct {
+	workloadCluster *workloadCluster
+	options         WatcherOptions
+
+	lock     sync.Mutex
+	clusters map[client.ObjectKey]*clusterCache
+}
+
+// clusterCache holds the informers of a workload cluster.
+type clusterCache struct {
+	cache.Cache
+	cancel  gocontext.CancelFunc
+	watches sets.Set[string]
+}
+
+// NewWatcher returns a Watcher reading the kubeconfigs of the workload clusters with the given client.
+func NewWatcher(c client.Client, options WatcherOptions) *Watcher {
+	if options.HealthCheckInterval <= 0 {
+		options.HealthCheckInterval = DefaultHealthCheckInterval
+	}
+	if options.HealthCheckFailureThreshold <= 0 {
+		options.HealthCheckFailureThreshold = DefaultHealthCheckFailureThreshold
+	}
+
+	return &Watcher{
+		workloadCluster: &workloadCluster{Client: c},
+		options:         options,
+		clusters:        map[client.ObjectKey]*clusterCache{},
+	}
+}
+
+// Watch starts the given watch on the workload cluster of a KubevirtCluster, unless it is already running.
+func (w *Watcher) Watch(ctx *context.ClusterContext, input WatchInput) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	key := client.ObjectKeyFromObject(ctx.Cluster)
+	cc, found := w.clusters[key]
+	if !found {
+		var err error
+		if cc, err = w.startClusterCache(ctx, key); err != nil {
+			return err
+		}
+		w.clusters[key] = cc
+	}
+
+	if cc.watches.Has(input.Name) {
+		return nil
+	}
+	if err := input.Controller.Watch(source.Kind(cc.Cache, input.Kind, input.EventHandler, input.Predicates...)); err != nil {
+		return errors.Wrapf(err, "failed to start watch %s on workload cluster %s", input.Name, key)
+	}
+	cc.watches.Insert(input.Name)
+
+	return nil
+}
+
+// startClusterCache starts the informers cache of a workload cluster, and the health check stopping it.
+func (w *Watcher) startClusterCache(ctx *context.ClusterContext, key client.ObjectKey) (*clusterCache, error) {
+	restConfig, err := w.workloadCluster.restConfigForWorkloadCluster(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := cache.New(restConfig, cache.Options{Scheme: w.workloadCluster.Scheme()})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create cache for workload cluster")
+	}
+	k8sClient, err := k8sclient.NewForConfig(restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create health check client for workload cluster")
+	}
+
+	// The cache must outlive the reconciliation starting it
+	cacheCtx, cancel := gocontext.WithCancel(gocontext.Background())
+	cacheCtx = log.IntoContext(cacheCtx, log.FromContext(ctx).WithValues("workloadCluster", key.String()))
+	cc := &clusterCache{Cache: c, cancel: cancel, watches: sets.New[string]()}
+
+	go func() {
+		if err := c.Start(cacheCtx); err != nil {
+			log.FromContext(cacheCtx).Error(err, "Workload cluster cache stopped")
+		}
+	}()
+	go w.healthCheck(cacheCtx, key, cc, k8sClient.Discovery().RESTClient())
+
+	return cc, nil
+}
+
+// healthCheck probes the apiserver of a workload cluster until its cache is stopped, and stops the cache once the
+// apiserver failed to answer too many times in a row.
+func (w *Watcher) healthCheck(ctx gocontext.Context, key client.ObjectKey, cc *clusterCache, healthClient rest.Interface) {
+	ticker := time.NewTicker(w.options.HealthCheckInterval)
+	defer ticker.Stop()
+
+	failures := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		probeCtx, cancel := gocontext.WithTimeout(ctx, healthCheckTimeout)
+		err := healthClient.Get().AbsPath("/healthz").Do(probeCtx).Error()
+		cancel()
+		if err == nil {
+			failures = 0
+			continue
+		}
+
+		failures++
+		if failures >= w.options.HealthCheckFailureThreshold {
+			log.FromContext(ctx).Info("Workload cluster apiserver is not answering, stopping its watches", "error", err.Error())
+			w.stop(key, cc)
+			return
+		}
+	}
+}
+
+// stop stops the cache of a workload cluster and forgets its watches.
+func (w *Watcher) stop(key client.ObjectKey, cc *clusterCache) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	cc.cancel()
+	if w.clusters[key] == cc {
+		delete(w.clusters, key)
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadcluster_test
+
+import (
+	gocontext "context"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/workloadcluster"
+)
+
+// fakeController counts the sources it is asked to watch, without starting them.
+type fakeController struct {
+	controller.Controller
+	watches int32
+}
+
+func (c *fakeController) Watch(_ source.Source) error {
+	atomic.AddInt32(&c.watches, 1)
+	return nil
+}
+
+var _ = Describe("Workload cluster watcher", func() {
+	var (
+		server         *httptest.Server
+		clusterContext *context.ClusterContext
+		ctrl           *fakeController
+		watcher        *workloadcluster.Watcher
+	)
+
+	BeforeEach(func() {
+		var namespaceRequests int32
+		server = newFakeAPIServer(&namespaceRequests)
+		DeferCleanup(server.Close)
+
+		kubevirtCluster := testing.NewKubevirtCluster("test-cluster", "test-kubevirt-cluster")
+		cluster := testing.NewCluster("test-cluster", kubevirtCluster)
+		clusterContext = &context.ClusterContext{
+			Context:         gocontext.Background(),
+			Cluster:         cluster,
+			KubevirtCluster: kubevirtCluster,
+		}
+		ctrl = &fakeController{}
+
+		fakeClient := fake.NewClientBuilder().WithScheme(testing.SetupScheme()).
+			WithObjects(newKubeconfigSecret(cluster.Name, server.URL)).Build()
+		watcher = workloadcluster.NewWatcher(fakeClient, workloadcluster.WatcherOptions{
+			HealthCheckInterval:         10 * time.Millisecond,
+			HealthCheckFailureThreshold: 2,
+		})
+	})
+
+	watchNodes := func(name string) error {
+		return watcher.Watch(clusterContext, workloadcluster.WatchInput{
+			Name:         name,
+			Controller:   ctrl,
+			Kind:         &corev1.Node{},
+			EventHandler: &handler.EnqueueRequestForObject{},
+		})
+	}
+
+	It("should start each watch once", func() {
+		Expect(watchNodes("nodes")).To(Succeed())
+		Expect(watchNodes("nodes")).To(Succeed())
+		Expect(atomic.LoadInt32(&ctrl.watches)).To(Equal(int32(1)))
+
+		Expect(watchNodes("other-nodes")).To(Succeed())
+		Expect(atomic.LoadInt32(&ctrl.watches)).To(Equal(int32(2)))
+
+		// the apiserver stays healthy
+		Consistently(func() int32 {
+			Expect(watchNodes("nodes")).To(Succeed())
+			return atomic.LoadInt32(&ctrl.watches)
+		}, 100*time.Millisecond, 10*time.Millisecond).Should(Equal(int32(2)))
+	})
+
+	It("should start the watches again once the apiserver stopped answering", func() {
+		Expect(watchNodes("nodes")).To(Succeed())
+		server.Close()
+
+		Eventually(func() int32 {
+			Expect(watchNodes("nodes")).To(Succeed())
+			return atomic.LoadInt32(&ctrl.watches)
+		}).Should(Equal(int32(2)))
+	})
+
+	It("should fail without the kubeconfig secret", func() {
+		clusterContext.Cluster.Name = "other-cluster"
+		Expect(watchNodes("nodes")).ToNot(Succeed())
+		Expect(atomic.LoadInt32(&ctrl.watches)).To(BeZero())
+	})
+})
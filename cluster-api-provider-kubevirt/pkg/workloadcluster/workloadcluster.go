@@ -3,17 +3,25 @@ package workloadcluster
 import (
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	k8sclient "k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/faultinjection"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/proxy"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/workloadclient"
 )
 
 //go:generate mockgen -source=./workloadcluster.go -destination=./mock/workloadcluster_generated.go -package=mock
 type WorkloadCluster interface {
 	GenerateWorkloadClusterClient(ctx *context.MachineContext) (client.Client, error)
 	GenerateWorkloadClusterK8sClient(ctx *context.MachineContext) (k8sclient.Interface, error)
+	GenerateClusterClient(ctx *context.ClusterContext) (client.Client, error)
+	GenerateClusterK8sClient(ctx *context.ClusterContext) (k8sclient.Interface, error)
 }
 
 func New(client client.Client) WorkloadCluster {
@@ -25,20 +33,20 @@ func New(client client.Client) WorkloadCluster {
 // KubevirtMachineReconciler is struct provides workloadCluster access info
 type workloadCluster struct {
 	client.Client
+	// readOnly sends the writes to the workload clusters as server-side dry-runs.
+	readOnly bool
 }
 
 // GenerateWorkloadClusterClient creates a client for workload cluster.
 func (w *workloadCluster) GenerateWorkloadClusterClient(ctx *context.MachineContext) (client.Client, error) {
-	// get workload cluster kubeconfig
-	kubeConfig, err := w.getKubeconfigForWorkloadCluster(ctx)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to get kubeconfig for workload cluster")
-	}
+	return w.GenerateClusterClient(ctx.ClusterContext())
+}
 
-	// generate REST config
-	restConfig, err := clientcmd.RESTConfigFromKubeConfig([]byte(kubeConfig))
+// GenerateClusterClient creates a client for the workload cluster of a KubevirtCluster.
+func (w *workloadCluster) GenerateClusterClient(ctx *context.ClusterContext) (client.Client, error) {
+	restConfig, err := w.restConfigForWorkloadCluster(ctx)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create REST config")
+		return nil, err
 	}
 
 	// create the client
@@ -52,16 +60,14 @@ func (w *workloadCluster) GenerateWorkloadClusterClient(ctx *context.MachineCont
 
 // GenerateWorkloadClusterK8sClient creates a kubernetes client for workload cluster.
 func (w *workloadCluster) GenerateWorkloadClusterK8sClient(ctx *context.MachineContext) (k8sclient.Interface, error) {
-	// get workload cluster kubeconfig
-	kubeConfig, err := w.getKubeconfigForWorkloadCluster(ctx)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to get kubeconfig for workload cluster")
-	}
+	return w.GenerateClusterK8sClient(ctx.ClusterContext())
+}
 
-	// generate REST config
-	restConfig, err := clientcmd.RESTConfigFromKubeConfig([]byte(kubeConfig))
+// GenerateClusterK8sClient creates a kubernetes client for the workload cluster of a KubevirtCluster.
+func (w *workloadCluster) GenerateClusterK8sClient(ctx *context.ClusterContext) (k8sclient.Interface, error) {
+	restConfig, err := w.restConfigForWorkloadCluster(ctx)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create REST config")
+		return nil, err
 	}
 
 	// create the client
@@ -73,20 +79,82 @@ func (w *workloadCluster) GenerateWorkloadClusterK8sClient(ctx *context.MachineC
 	return workloadClusterClient, nil
 }
 
-// getKubeconfigForWorkloadCluster fetches kubeconfig for workload cluster from the corresponding secret.
-func (w *workloadCluster) getKubeconfigForWorkloadCluster(ctx *context.MachineContext) (string, error) {
-	// workload cluster kubeconfig can be found in a secret with suffix "-kubeconfig"
-	kubeconfigSecret := &corev1.Secret{}
-	kubeconfigSecretKey := client.ObjectKey{Namespace: ctx.KubevirtCluster.Namespace, Name: ctx.Cluster.Name + "-kubeconfig"}
-	if err := w.Client.Get(ctx, kubeconfigSecretKey, kubeconfigSecret); err != nil {
-		return "", errors.Wrapf(err, "failed to fetch kubeconfig for workload cluster")
+// restConfigForWorkloadCluster generates the REST config of the workload cluster of a KubevirtCluster.
+func (w *workloadCluster) restConfigForWorkloadCluster(ctx *context.ClusterContext) (*rest.Config, error) {
+	// get workload cluster kubeconfig
+	kubeConfig, err := Kubeconfig(ctx, w.Client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get kubeconfig for workload cluster")
 	}
 
-	// read kubeconfig
-	value, ok := kubeconfigSecret.Data["value"]
+	// generate REST config
+	return workloadclient.RESTConfigFromKubeconfig(kubeConfig, func(restConfig *rest.Config) {
+		restConfig.Proxy = proxy.Func(ctx.Cluster, ctx.KubevirtCluster)
+		if faultinjection.Injected(ctx.KubevirtCluster, faultinjection.APIServerUnreachable) {
+			restConfig.Dial = faultinjection.UnreachableDial
+		}
+		if source := ctx.KubevirtCluster.Spec.WorkloadKubeconfig; source != nil && source.Exec != nil {
+			setExecProvider(restConfig, source.Exec)
+		}
+		if w.readOnly {
+			restConfig.Wrap(newDryRunRoundTripper)
+		}
+	})
+}
+
+// Kubeconfig fetches kubeconfig for workload cluster from the corresponding secret, or from the secret key the
+// KubevirtCluster references.
+func Kubeconfig(ctx *context.ClusterContext, c client.Client) ([]byte, error) {
+	cluster := client.ObjectKey{Namespace: ctx.KubevirtCluster.Namespace, Name: ctx.Cluster.Name}
+	var secretRef *corev1.SecretKeySelector
+	if source := ctx.KubevirtCluster.Spec.WorkloadKubeconfig; source != nil {
+		secretRef = source.SecretRef
+	}
+
+	if faultinjection.Injected(ctx.KubevirtCluster, faultinjection.KubeconfigSecretMissing) {
+		secretName := workloadclient.KubeconfigSecretName(cluster.Name)
+		if secretRef != nil {
+			secretName = secretRef.Name
+		}
+		err := apierrors.NewNotFound(corev1.Resource("secrets"), secretName)
+		return nil, errors.Wrapf(err, "failed to fetch kubeconfig for workload cluster")
+	}
+	if secretRef == nil {
+		return workloadclient.Kubeconfig(ctx, c, cluster)
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: secretRef.Name}, secret); err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch kubeconfig for workload cluster")
+	}
+	value, ok := secret.Data[secretRef.Key]
 	if !ok {
-		return "", errors.New("error retrieving kubeconfig data: secret value key is missing")
+		return nil, errors.Errorf("error retrieving kubeconfig data: key %s is missing from secret %s", secretRef.Key, secretRef.Name)
 	}
+	return value, nil
+}
 
-	return string(value), nil
+// setExecProvider authenticates with the exec credential plugin rather than the credentials of the kubeconfig.
+func setExecProvider(restConfig *rest.Config, plugin *infrav1.ExecCredentialPlugin) {
+	apiVersion := plugin.APIVersion
+	if apiVersion == "" {
+		apiVersion = "client.authentication.k8s.io/v1"
+	}
+	env := make([]clientcmdapi.ExecEnvVar, 0, len(plugin.Env))
+	for _, envVar := range plugin.Env {
+		env = append(env, clientcmdapi.ExecEnvVar{Name: envVar.Name, Value: envVar.Value})
+	}
+
+	restConfig.ExecProvider = &clientcmdapi.ExecConfig{
+		Command:         plugin.Command,
+		Args:            plugin.Args,
+		Env:             env,
+		APIVersion:      apiVersion,
+		InteractiveMode: clientcmdapi.NeverExecInteractiveMode,
+	}
+	restConfig.AuthProvider = nil
+	restConfig.BearerToken, restConfig.BearerTokenFile = "", ""
+	restConfig.Username, restConfig.Password = "", ""
+	restConfig.CertData, restConfig.CertFile = nil, ""
+	restConfig.KeyData, restConfig.KeyFile = nil, ""
 }
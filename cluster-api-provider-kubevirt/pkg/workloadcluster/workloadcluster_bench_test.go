@@ -0,0 +1,210 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadcluster_test
+
+import (
+	gocontext "context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	testutil "sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/workloadcluster"
+)
+
+// The scale of the management clusters the benchmarks simulate.
+const (
+	benchmarkClusters           = 500
+	benchmarkMachinesPerCluster = 10
+)
+
+// newBenchmarkFleet returns the workload clusters of a management cluster holding the kubeconfig secrets of
+// benchmarkClusters clusters, and the contexts of their machines. Each cluster has its own CA, as the real ones do,
+// so that the transports of their clients are not shared.
+func newBenchmarkFleet(b *testing.B) (workloadcluster.WorkloadCluster, []*context.MachineContext) {
+	b.Helper()
+
+	var (
+		objects  []client.Object
+		machines []*context.MachineContext
+	)
+	for i := 0; i < benchmarkClusters; i++ {
+		clusterName := fmt.Sprintf("cluster-%03d", i)
+		kubevirtCluster := testutil.NewKubevirtCluster(clusterName, "kubevirt-"+clusterName)
+		cluster := testutil.NewCluster(clusterName, kubevirtCluster)
+		objects = append(objects, newBenchmarkKubeconfigSecret(b, cluster.Name, kubevirtCluster.Namespace))
+
+		for j := 0; j < benchmarkMachinesPerCluster; j++ {
+			machines = append(machines, &context.MachineContext{
+				Context:         gocontext.Background(),
+				Cluster:         cluster,
+				KubevirtCluster: kubevirtCluster,
+			})
+		}
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(testutil.SetupScheme()).WithObjects(objects...).Build()
+	return workloadcluster.New(fakeClient), machines
+}
+
+// newBenchmarkKubeconfigSecret returns the kubeconfig secret of a cluster, trusting a CA of its own.
+func newBenchmarkKubeconfigSecret(b *testing.B, clusterName, namespace string) *corev1.Secret {
+	b.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		b.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: clusterName},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	config := clientcmdapi.NewConfig()
+	config.Clusters[clusterName] = &clientcmdapi.Cluster{
+		Server:                   fmt.Sprintf("https://%s.invalid:6443", clusterName),
+		CertificateAuthorityData: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+	config.AuthInfos[clusterName] = &clientcmdapi.AuthInfo{Token: "token"}
+	config.Contexts[clusterName] = &clientcmdapi.Context{Cluster: clusterName, AuthInfo: clusterName}
+	config.CurrentContext = clusterName
+
+	value, err := clientcmd.Write(*config)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: clusterName + "-kubeconfig", Namespace: namespace},
+		Data:       map[string][]byte{"value": value},
+	}
+}
+
+// benchmarkGenerators are the client generations of the reconcilers, each run for every machine of the fleet.
+var benchmarkGenerators = map[string]func(workloadcluster.WorkloadCluster, *context.MachineContext) (interface{}, error){
+	"client": func(w workloadcluster.WorkloadCluster, ctx *context.MachineContext) (interface{}, error) {
+		return w.GenerateWorkloadClusterClient(ctx)
+	},
+	"k8s-client": func(w workloadcluster.WorkloadCluster, ctx *context.MachineContext) (interface{}, error) {
+		return w.GenerateWorkloadClusterK8sClient(ctx)
+	},
+}
+
+// BenchmarkGenerateWorkloadClusterClient measures the latency and the allocations of the generation of a client
+// for the workload cluster of a machine, as done on every reconciliation of the machine.
+func BenchmarkGenerateWorkloadClusterClient(b *testing.B) {
+	w, machines := newBenchmarkFleet(b)
+
+	for name, generate := range benchmarkGenerators {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := generate(w, machines[i%len(machines)]); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkGenerateWorkloadClusterClientParallel measures the same generation with all the machines reconciled
+// concurrently, to expose the contention on the management client and on the transport cache of client-go.
+func BenchmarkGenerateWorkloadClusterClientParallel(b *testing.B) {
+	w, machines := newBenchmarkFleet(b)
+
+	for name, generate := range benchmarkGenerators {
+		b.Run(name, func(b *testing.B) {
+			var next int64
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					i := atomic.AddInt64(&next, 1)
+					if _, err := generate(w, machines[i%int64(len(machines))]); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkWorkloadClusterClientsMemory measures the heap retained by a client per machine of the fleet, as when
+// the reconcilers keep them, and reports it per client and per cluster. The schemes, REST mappers and transports
+// duplicated between the clients of a same cluster show up here.
+func BenchmarkWorkloadClusterClientsMemory(b *testing.B) {
+	w, machines := newBenchmarkFleet(b)
+
+	for name, generate := range benchmarkGenerators {
+		b.Run(name, func(b *testing.B) {
+			var retained uint64
+			for i := 0; i < b.N; i++ {
+				before := heapInUse()
+				clients := make([]interface{}, 0, len(machines))
+				for _, machine := range machines {
+					c, err := generate(w, machine)
+					if err != nil {
+						b.Fatal(err)
+					}
+					clients = append(clients, c)
+				}
+				if after := heapInUse(); after > before {
+					retained += after - before
+				}
+				runtime.KeepAlive(clients)
+			}
+			perRun := float64(retained) / float64(b.N)
+			b.ReportMetric(perRun/float64(len(machines)), "B/client")
+			b.ReportMetric(perRun/benchmarkClusters, "B/cluster")
+		})
+	}
+}
+
+// heapInUse returns the heap in use once the garbage is collected.
+func heapInUse() uint64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapInuse
+}
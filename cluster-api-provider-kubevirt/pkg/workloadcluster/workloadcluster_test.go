@@ -0,0 +1,344 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadcluster_test
+
+import (
+	gocontext "context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-kubevirt/api/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/context"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/faultinjection"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/testing"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/pkg/workloadcluster"
+	"sigs.k8s.io/cluster-api-provider-kubevirt/workloadclient"
+)
+
+// newFakeAPIServer serves the health check, the discovery of the core group and the "default" namespace, and counts
+// the requests for the namespace.
+func newFakeAPIServer(namespaceRequests *int32) *httptest.Server {
+	responses := map[string]interface{}{
+		"/healthz": "ok",
+		"/api": &metav1.APIVersions{
+			TypeMeta: metav1.TypeMeta{Kind: "APIVersions"},
+			Versions: []string{"v1"},
+		},
+		"/apis": &metav1.APIGroupList{
+			TypeMeta: metav1.TypeMeta{Kind: "APIGroupList", APIVersion: "v1"},
+		},
+		"/api/v1": &metav1.APIResourceList{
+			TypeMeta:     metav1.TypeMeta{Kind: "APIResourceList"},
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "namespaces", Kind: "Namespace", Verbs: metav1.Verbs{"get", "list"}},
+			},
+		},
+		"/api/v1/namespaces/default": &corev1.Namespace{
+			TypeMeta:   metav1.TypeMeta{Kind: "Namespace", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		},
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, found := responses[r.URL.Path]
+		if !found {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Path == "/api/v1/namespaces/default" {
+			atomic.AddInt32(namespaceRequests, 1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+}
+
+func newKubeconfigSecret(clusterName, server string) *corev1.Secret {
+	config := clientcmdapi.NewConfig()
+	config.Clusters[clusterName] = &clientcmdapi.Cluster{Server: server}
+	config.AuthInfos[clusterName] = &clientcmdapi.AuthInfo{Token: "token"}
+	config.Contexts[clusterName] = &clientcmdapi.Context{Cluster: clusterName, AuthInfo: clusterName}
+	config.CurrentContext = clusterName
+
+	value, err := clientcmd.Write(*config)
+	Expect(err).ToNot(HaveOccurred())
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: clusterName + "-kubeconfig"},
+		Data:       map[string][]byte{"value": value},
+	}
+}
+
+var _ = Describe("Workload cluster clients", func() {
+	var (
+		server            *httptest.Server
+		namespaceRequests int32
+		machineContext    *context.MachineContext
+		objects           []client.Object
+	)
+
+	BeforeEach(func() {
+		namespaceRequests = 0
+		server = newFakeAPIServer(&namespaceRequests)
+		DeferCleanup(server.Close)
+
+		kubevirtCluster := testing.NewKubevirtCluster("test-cluster", "test-kubevirt-cluster")
+		cluster := testing.NewCluster("test-cluster", kubevirtCluster)
+		machineContext = &context.MachineContext{
+			Context:         gocontext.Background(),
+			Cluster:         cluster,
+			KubevirtCluster: kubevirtCluster,
+		}
+		objects = []client.Object{newKubeconfigSecret(cluster.Name, server.URL)}
+	})
+
+	newWorkloadCluster := func() workloadcluster.WorkloadCluster {
+		fakeClient := fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(objects...).Build()
+		return workloadcluster.New(fakeClient)
+	}
+
+	It("should generate a client for the workload cluster", func() {
+		workloadClusterClient, err := newWorkloadCluster().GenerateWorkloadClusterClient(machineContext)
+		Expect(err).ToNot(HaveOccurred())
+
+		namespace := &corev1.Namespace{}
+		Expect(workloadClusterClient.Get(machineContext, client.ObjectKey{Name: "default"}, namespace)).To(Succeed())
+		Expect(namespace.Name).To(Equal("default"))
+		Expect(atomic.LoadInt32(&namespaceRequests)).To(Equal(int32(1)))
+	})
+
+	It("should generate a kubernetes client for the workload cluster", func() {
+		workloadClusterClient, err := newWorkloadCluster().GenerateWorkloadClusterK8sClient(machineContext)
+		Expect(err).ToNot(HaveOccurred())
+
+		namespace, err := workloadClusterClient.CoreV1().Namespaces().Get(machineContext, "default", metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(namespace.Name).To(Equal("default"))
+		Expect(atomic.LoadInt32(&namespaceRequests)).To(Equal(int32(1)))
+	})
+
+	It("should fail without the kubeconfig secret", func() {
+		objects = nil
+
+		_, err := newWorkloadCluster().GenerateWorkloadClusterClient(machineContext)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should fail when the kubeconfig secret has no value", func() {
+		delete(objects[0].(*corev1.Secret).Data, "value")
+
+		_, err := newWorkloadCluster().GenerateWorkloadClusterClient(machineContext)
+		Expect(err).To(MatchError(ContainSubstring("secret value key is missing")))
+	})
+
+	It("should reach the workload cluster through the proxy of the cluster", func() {
+		objects = []client.Object{newKubeconfigSecret(machineContext.Cluster.Name, "http://workload-cluster.invalid")}
+		machineContext.KubevirtCluster.Spec.Proxy = &infrav1.ProxySpec{HTTPProxy: server.URL}
+
+		workloadClusterClient, err := newWorkloadCluster().GenerateWorkloadClusterK8sClient(machineContext)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = workloadClusterClient.CoreV1().Namespaces().Get(machineContext, "default", metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(atomic.LoadInt32(&namespaceRequests)).To(Equal(int32(1)))
+	})
+
+	Context("with a workload kubeconfig override", func() {
+		BeforeEach(func() {
+			secret := newKubeconfigSecret(machineContext.Cluster.Name, server.URL)
+			secret.Name = "external-kubeconfig"
+			secret.Data = map[string][]byte{"admin.conf": secret.Data["value"]}
+			objects = []client.Object{secret}
+			machineContext.KubevirtCluster.Spec.WorkloadKubeconfig = &infrav1.WorkloadKubeconfigSource{
+				SecretRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "external-kubeconfig"},
+					Key:                  "admin.conf",
+				},
+			}
+		})
+
+		It("should read the kubeconfig from the referenced secret key", func() {
+			workloadClusterClient, err := newWorkloadCluster().GenerateWorkloadClusterClient(machineContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			namespace := &corev1.Namespace{}
+			Expect(workloadClusterClient.Get(machineContext, client.ObjectKey{Name: "default"}, namespace)).To(Succeed())
+			Expect(atomic.LoadInt32(&namespaceRequests)).To(Equal(int32(1)))
+		})
+
+		It("should fail when the referenced secret has no such key", func() {
+			machineContext.KubevirtCluster.Spec.WorkloadKubeconfig.SecretRef.Key = "value"
+
+			_, err := newWorkloadCluster().GenerateWorkloadClusterClient(machineContext)
+			Expect(err).To(MatchError(ContainSubstring("key value is missing from secret external-kubeconfig")))
+		})
+
+		It("should authenticate with the exec credential plugin", func() {
+			pluginDir := GinkgoT().TempDir()
+			plugin := "#!/bin/sh\n" + `echo "{\"apiVersion\":\"$API_VERSION\",\"kind\":\"ExecCredential\",\"status\":{\"token\":\"$1\"}}"` + "\n"
+			Expect(os.WriteFile(filepath.Join(pluginDir, "get-token"), []byte(plugin), 0o755)).To(Succeed())
+			workloadclient.SetCredentialPluginDir(pluginDir)
+			DeferCleanup(workloadclient.SetCredentialPluginDir, "")
+			machineContext.KubevirtCluster.Spec.WorkloadKubeconfig.Exec = &infrav1.ExecCredentialPlugin{
+				Command: "/usr/local/bin/get-token",
+				Args:    []string{"exec-token"},
+				Env:     []infrav1.ExecEnvVar{{Name: "API_VERSION", Value: "client.authentication.k8s.io/v1"}},
+			}
+
+			workloadClusterClient, err := newWorkloadCluster().GenerateWorkloadClusterK8sClient(machineContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = workloadClusterClient.CoreV1().Namespaces().Get(machineContext, "default", metav1.GetOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(atomic.LoadInt32(&namespaceRequests)).To(Equal(int32(1)))
+		})
+
+		It("should not reach the workload cluster when the exec credential plugin fails", func() {
+			pluginDir := GinkgoT().TempDir()
+			Expect(os.WriteFile(filepath.Join(pluginDir, "get-token"), []byte("#!/bin/sh\nexit 1\n"), 0o755)).To(Succeed())
+			workloadclient.SetCredentialPluginDir(pluginDir)
+			DeferCleanup(workloadclient.SetCredentialPluginDir, "")
+			machineContext.KubevirtCluster.Spec.WorkloadKubeconfig.Exec = &infrav1.ExecCredentialPlugin{Command: "get-token"}
+
+			workloadClusterClient, err := newWorkloadCluster().GenerateWorkloadClusterK8sClient(machineContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = workloadClusterClient.CoreV1().Namespaces().Get(machineContext, "default", metav1.GetOptions{})
+			Expect(err).To(HaveOccurred())
+			Expect(atomic.LoadInt32(&namespaceRequests)).To(BeZero())
+		})
+
+		It("should refuse the exec credential plugin without credential plugin directory", func() {
+			machineContext.KubevirtCluster.Spec.WorkloadKubeconfig.Exec = &infrav1.ExecCredentialPlugin{Command: "sh"}
+
+			_, err := newWorkloadCluster().GenerateWorkloadClusterK8sClient(machineContext)
+			Expect(err).To(MatchError(ContainSubstring("no credential plugin directory is configured")))
+		})
+	})
+
+	Context("with failure injection", func() {
+		BeforeEach(func() {
+			faultinjection.SetEnabled(true)
+			DeferCleanup(faultinjection.SetEnabled, false)
+		})
+
+		It("should simulate a missing kubeconfig secret", func() {
+			machineContext.KubevirtCluster.Annotations = map[string]string{
+				infrav1.InjectFailuresAnnotation: string(faultinjection.KubeconfigSecretMissing),
+			}
+
+			_, err := newWorkloadCluster().GenerateWorkloadClusterClient(machineContext)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should simulate an unreachable apiserver", func() {
+			machineContext.KubevirtCluster.Annotations = map[string]string{
+				infrav1.InjectFailuresAnnotation: string(faultinjection.APIServerUnreachable),
+			}
+
+			workloadClusterClient, err := newWorkloadCluster().GenerateWorkloadClusterK8sClient(machineContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = workloadClusterClient.CoreV1().Namespaces().Get(machineContext, "default", metav1.GetOptions{})
+			Expect(err).To(MatchError(ContainSubstring(string(faultinjection.APIServerUnreachable))))
+			Expect(atomic.LoadInt32(&namespaceRequests)).To(BeZero())
+		})
+	})
+
+	Context("in read-only mode", func() {
+		var (
+			lock   sync.Mutex
+			writes []string
+		)
+
+		BeforeEach(func() {
+			writes = nil
+			handler := server.Config.Handler
+			server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet {
+					lock.Lock()
+					writes = append(writes, r.Method+" "+r.URL.Query().Get("dryRun"))
+					lock.Unlock()
+				}
+				handler.ServeHTTP(w, r)
+			})
+		})
+
+		getWrites := func() []string {
+			lock.Lock()
+			defer lock.Unlock()
+			return writes
+		}
+
+		newReadOnlyWorkloadCluster := func() workloadcluster.WorkloadCluster {
+			fakeClient := fake.NewClientBuilder().WithScheme(testing.SetupScheme()).WithObjects(objects...).Build()
+			return workloadcluster.NewReadOnly(fakeClient)
+		}
+
+		It("should send the writes of the client as dry-runs", func() {
+			workloadClusterClient, err := newReadOnlyWorkloadCluster().GenerateWorkloadClusterClient(machineContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			namespace := &corev1.Namespace{}
+			Expect(workloadClusterClient.Get(machineContext, client.ObjectKey{Name: "default"}, namespace)).To(Succeed())
+			namespace.Labels = map[string]string{"updated": "true"}
+			Expect(workloadClusterClient.Update(machineContext, namespace)).To(Succeed())
+			Expect(workloadClusterClient.Delete(machineContext, namespace)).To(Succeed())
+
+			Expect(getWrites()).To(Equal([]string{"PUT All", "DELETE All"}))
+		})
+
+		It("should send the writes of the kubernetes client as dry-runs", func() {
+			workloadClusterClient, err := newReadOnlyWorkloadCluster().GenerateWorkloadClusterK8sClient(machineContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			namespace, err := workloadClusterClient.CoreV1().Namespaces().Get(machineContext, "default", metav1.GetOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			_, err = workloadClusterClient.CoreV1().Namespaces().Patch(machineContext, namespace.Name, types.MergePatchType, []byte(`{}`), metav1.PatchOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(workloadClusterClient.CoreV1().Namespaces().Delete(machineContext, namespace.Name, metav1.DeleteOptions{})).To(Succeed())
+
+			Expect(getWrites()).To(Equal([]string{"PATCH All", "DELETE All"}))
+		})
+
+		It("should send the writes as-is otherwise", func() {
+			workloadClusterClient, err := newWorkloadCluster().GenerateWorkloadClusterK8sClient(machineContext)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(workloadClusterClient.CoreV1().Namespaces().Delete(machineContext, "default", metav1.DeleteOptions{})).To(Succeed())
+
+			Expect(getWrites()).To(Equal([]string{"DELETE "}))
+		})
+	})
+})
@@ -0,0 +1,59 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadclient
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/rest"
+
+	// The oidc auth provider refreshes the ID tokens of the kubeconfigs of the clusters federated to an identity
+	// provider in process, without any plugin binary.
+	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
+)
+
+var credentialPluginDir atomic.Value
+
+// SetCredentialPluginDir sets the directory of the exec credential plugins the workload kubeconfigs may run, e.g. a
+// volume mounted in the controller pod. The kubeconfigs are written by the users of the workload clusters, so their
+// plugins only run from that directory; they are refused while it is unset.
+func SetCredentialPluginDir(dir string) {
+	credentialPluginDir.Store(dir)
+}
+
+// resolveExecPlugin points the exec credential plugin of a REST config at its binary in the credential plugin
+// directory, whatever the path of the command of the kubeconfig.
+func resolveExecPlugin(restConfig *rest.Config) error {
+	if restConfig.ExecProvider == nil {
+		return nil
+	}
+
+	dir, _ := credentialPluginDir.Load().(string)
+	if dir == "" {
+		return errors.Errorf("exec credential plugin %s refused: no credential plugin directory is configured", restConfig.ExecProvider.Command)
+	}
+	command := filepath.Join(dir, filepath.Base(restConfig.ExecProvider.Command))
+	info, err := os.Stat(command)
+	if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0o111 == 0 {
+		return errors.Errorf("exec credential plugin %s is not installed in the credential plugin directory %s", restConfig.ExecProvider.Command, dir)
+	}
+	restConfig.ExecProvider.Command = command
+	return nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package workloadclient generates clients for the workload clusters of Cluster API from the kubeconfig secrets of
+// the management cluster. It only depends on client-go and controller-runtime, so that addon controllers can reuse
+// it without depending on the provider.
+package workloadclient
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sclient "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// KubeconfigSecretKey is the key of the kubeconfig in the kubeconfig secret of a cluster.
+const KubeconfigSecretKey = "value"
+
+// Option customizes the REST config of a workload cluster, e.g. to tunnel its connections through a proxy.
+type Option func(*rest.Config)
+
+// KubeconfigSecretName returns the name of the secret Cluster API stores the kubeconfig of a cluster in.
+func KubeconfigSecretName(clusterName string) string {
+	return clusterName + "-kubeconfig"
+}
+
+// Kubeconfig fetches the kubeconfig of the workload cluster of the given namespace and name.
+func Kubeconfig(ctx context.Context, reader client.Reader, cluster client.ObjectKey) ([]byte, error) {
+	kubeconfigSecret := &corev1.Secret{}
+	kubeconfigSecretKey := client.ObjectKey{Namespace: cluster.Namespace, Name: KubeconfigSecretName(cluster.Name)}
+	if err := reader.Get(ctx, kubeconfigSecretKey, kubeconfigSecret); err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch kubeconfig for workload cluster")
+	}
+
+	value, ok := kubeconfigSecret.Data[KubeconfigSecretKey]
+	if !ok {
+		return nil, errors.New("error retrieving kubeconfig data: secret value key is missing")
+	}
+	return value, nil
+}
+
+// RESTConfig generates the REST config of a workload cluster from its kubeconfig, customized by the options.
+func RESTConfig(ctx context.Context, reader client.Reader, cluster client.ObjectKey, opts ...Option) (*rest.Config, error) {
+	kubeconfig, err := Kubeconfig(ctx, reader, cluster)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get kubeconfig for workload cluster")
+	}
+	return RESTConfigFromKubeconfig(kubeconfig, opts...)
+}
+
+// RESTConfigFromKubeconfig generates the REST config of a workload cluster from a kubeconfig already fetched,
+// customized by the options. Its exec credential plugin, if any, must be installed in the credential plugin
+// directory.
+func RESTConfigFromKubeconfig(kubeconfig []byte, opts ...Option) (*rest.Config, error) {
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create REST config")
+	}
+	for _, opt := range opts {
+		opt(restConfig)
+	}
+	if err := resolveExecPlugin(restConfig); err != nil {
+		return nil, err
+	}
+	return restConfig, nil
+}
+
+// NewClient creates a controller-runtime client for a workload cluster, decoding the objects with the scheme.
+func NewClient(ctx context.Context, reader client.Reader, cluster client.ObjectKey, scheme *runtime.Scheme, opts ...Option) (client.Client, error) {
+	restConfig, err := RESTConfig(ctx, reader, cluster, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	workloadClusterClient, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create workload cluster client")
+	}
+	return workloadClusterClient, nil
+}
+
+// NewK8sClient creates a kubernetes clientset for a workload cluster.
+func NewK8sClient(ctx context.Context, reader client.Reader, cluster client.ObjectKey, opts ...Option) (k8sclient.Interface, error) {
+	restConfig, err := RESTConfig(ctx, reader, cluster, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	workloadClusterClient, err := k8sclient.NewForConfig(restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create workload cluster client")
+	}
+	return workloadClusterClient, nil
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadclient_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/cluster-api-provider-kubevirt/workloadclient"
+)
+
+var _ = Describe("Workload cluster clients", func() {
+	cluster := client.ObjectKey{Namespace: "capi", Name: "test-cluster"}
+
+	newReader := func(data map[string][]byte) client.Reader {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "capi", Name: "test-cluster-kubeconfig"},
+			Data:       data,
+		}
+		return fake.NewClientBuilder().WithObjects(secret).Build()
+	}
+
+	kubeconfig := func() []byte {
+		config := clientcmdapi.NewConfig()
+		config.Clusters["workload"] = &clientcmdapi.Cluster{Server: "https://workload:6443"}
+		config.AuthInfos["admin"] = &clientcmdapi.AuthInfo{Token: "token"}
+		config.Contexts["admin@workload"] = &clientcmdapi.Context{Cluster: "workload", AuthInfo: "admin"}
+		config.CurrentContext = "admin@workload"
+		data, err := clientcmd.Write(*config)
+		Expect(err).ToNot(HaveOccurred())
+		return data
+	}
+
+	It("should generate the REST config of the workload cluster from its kubeconfig secret", func() {
+		reader := newReader(map[string][]byte{workloadclient.KubeconfigSecretKey: kubeconfig()})
+
+		restConfig, err := workloadclient.RESTConfig(context.TODO(), reader, cluster, func(config *rest.Config) {
+			config.Timeout = time.Minute
+		})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(restConfig.Host).To(Equal("https://workload:6443"))
+		Expect(restConfig.BearerToken).To(Equal("token"))
+		Expect(restConfig.Timeout).To(Equal(time.Minute))
+	})
+
+	It("should create the clients of the workload cluster", func() {
+		reader := newReader(map[string][]byte{workloadclient.KubeconfigSecretKey: kubeconfig()})
+
+		_, err := workloadclient.NewClient(context.TODO(), reader, cluster, runtime.NewScheme())
+		Expect(err).ToNot(HaveOccurred())
+		_, err = workloadclient.NewK8sClient(context.TODO(), reader, cluster)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should fail without kubeconfig secret", func() {
+		reader := fake.NewClientBuilder().Build()
+
+		_, err := workloadclient.NewClient(context.TODO(), reader, cluster, runtime.NewScheme())
+
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should fail when the kubeconfig secret has no kubeconfig", func() {
+		reader := newReader(map[string][]byte{"other": kubeconfig()})
+
+		_, err := workloadclient.RESTConfig(context.TODO(), reader, cluster)
+
+		Expect(err).To(MatchError(ContainSubstring("secret value key is missing")))
+	})
+
+	Context("with exec credential plugins", func() {
+		execKubeconfig := func(command string) []byte {
+			config := clientcmdapi.NewConfig()
+			config.Clusters["workload"] = &clientcmdapi.Cluster{Server: "https://workload:6443"}
+			config.AuthInfos["sso"] = &clientcmdapi.AuthInfo{Exec: &clientcmdapi.ExecConfig{
+				Command:         command,
+				APIVersion:      "client.authentication.k8s.io/v1",
+				InteractiveMode: clientcmdapi.NeverExecInteractiveMode,
+			}}
+			config.Contexts["sso@workload"] = &clientcmdapi.Context{Cluster: "workload", AuthInfo: "sso"}
+			config.CurrentContext = "sso@workload"
+			data, err := clientcmd.Write(*config)
+			Expect(err).ToNot(HaveOccurred())
+			return data
+		}
+
+		var pluginDir string
+
+		BeforeEach(func() {
+			pluginDir = GinkgoT().TempDir()
+			Expect(os.WriteFile(filepath.Join(pluginDir, "get-token"), []byte("#!/bin/sh\n"), 0o755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(pluginDir, "not-executable"), []byte("#!/bin/sh\n"), 0o644)).To(Succeed())
+			DeferCleanup(workloadclient.SetCredentialPluginDir, "")
+		})
+
+		It("should run the plugin from the credential plugin directory", func() {
+			workloadclient.SetCredentialPluginDir(pluginDir)
+
+			restConfig, err := workloadclient.RESTConfigFromKubeconfig(execKubeconfig("/usr/local/bin/get-token"))
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(restConfig.ExecProvider.Command).To(Equal(filepath.Join(pluginDir, "get-token")))
+		})
+
+		It("should refuse the plugins without credential plugin directory", func() {
+			_, err := workloadclient.RESTConfigFromKubeconfig(execKubeconfig("get-token"))
+
+			Expect(err).To(MatchError(ContainSubstring("no credential plugin directory is configured")))
+		})
+
+		It("should refuse the plugins not installed in the credential plugin directory", func() {
+			workloadclient.SetCredentialPluginDir(pluginDir)
+
+			for _, command := range []string{"sh", "../get-token/..", "not-executable"} {
+				_, err := workloadclient.RESTConfigFromKubeconfig(execKubeconfig(command))
+				Expect(err).To(MatchError(ContainSubstring("is not installed in the credential plugin directory")), command)
+			}
+		})
+	})
+
+	It("should refresh the ID token of an oidc auth provider", func() {
+		idToken := func(expiry time.Time) string {
+			encode := base64.RawURLEncoding.EncodeToString
+			return encode([]byte(`{"alg":"none"}`)) + "." + encode([]byte(fmt.Sprintf(`{"exp":%d}`, expiry.Unix()))) + ".signature"
+		}
+		freshToken := idToken(time.Now().Add(time.Hour))
+
+		var issuerURL string
+		issuer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/.well-known/openid-configuration":
+				Expect(json.NewEncoder(w).Encode(map[string]string{"token_endpoint": issuerURL + "/token"})).To(Succeed())
+			case "/token":
+				Expect(r.ParseForm()).To(Succeed())
+				Expect(r.PostForm.Get("refresh_token")).To(Equal("refresh-token"))
+				Expect(json.NewEncoder(w).Encode(map[string]interface{}{
+					"access_token": "access-token", "token_type": "Bearer", "expires_in": 3600, "id_token": freshToken,
+				})).To(Succeed())
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		DeferCleanup(issuer.Close)
+		issuerURL = issuer.URL
+
+		var authorization string
+		// client-go only authenticates to the API servers served over TLS
+		apiServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get("Authorization")
+			w.WriteHeader(http.StatusOK)
+		}))
+		DeferCleanup(apiServer.Close)
+
+		config := clientcmdapi.NewConfig()
+		config.Clusters["workload"] = &clientcmdapi.Cluster{Server: apiServer.URL, InsecureSkipTLSVerify: true}
+		config.AuthInfos["sso"] = &clientcmdapi.AuthInfo{AuthProvider: &clientcmdapi.AuthProviderConfig{
+			Name: "oidc",
+			Config: map[string]string{
+				"idp-issuer-url": issuer.URL,
+				"client-id":      "workload",
+				"id-token":       idToken(time.Now().Add(-time.Hour)),
+				"refresh-token":  "refresh-token",
+			},
+		}}
+		config.Contexts["sso@workload"] = &clientcmdapi.Context{Cluster: "workload", AuthInfo: "sso"}
+		config.CurrentContext = "sso@workload"
+		data, err := clientcmd.Write(*config)
+		Expect(err).ToNot(HaveOccurred())
+
+		restConfig, err := workloadclient.RESTConfigFromKubeconfig(data)
+		Expect(err).ToNot(HaveOccurred())
+		httpClient, err := rest.HTTPClientFor(restConfig)
+		Expect(err).ToNot(HaveOccurred())
+		resp, err := httpClient.Get(apiServer.URL + "/healthz")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+		Expect(authorization).To(Equal("Bearer " + freshToken))
+	})
+})